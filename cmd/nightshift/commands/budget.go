@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/marcus/nightshift/internal/db"
 	"github.com/marcus/nightshift/internal/providers"
 	"github.com/marcus/nightshift/internal/snapshots"
+	"github.com/marcus/nightshift/internal/state"
 	"github.com/marcus/nightshift/internal/trends"
 )
 
@@ -29,6 +32,7 @@ Shows spending across all providers or a specific provider.`,
 
 func init() {
 	budgetCmd.Flags().StringP("provider", "p", "", "Show specific provider status (claude, codex, copilot)")
+	addFreshFlag(budgetCmd)
 	rootCmd.AddCommand(budgetCmd)
 }
 
@@ -68,6 +72,14 @@ func runBudget(filterProvider string) error {
 		}
 	}
 
+	accounting := providers.TokenAccounting(cfg.GetTokenAccounting())
+	if claude != nil {
+		claude.SetTokenAccounting(accounting)
+	}
+	if codex != nil {
+		codex.SetTokenAccounting(accounting)
+	}
+
 	if cfg.Providers.Copilot.Enabled {
 		dataPath := cfg.ExpandedProviderPath("copilot")
 		if dataPath != "" {
@@ -80,7 +92,8 @@ func runBudget(filterProvider string) error {
 	// Create budget manager
 	cal := calibrator.New(database, cfg)
 	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
-	mgr := budget.NewManagerFromProviders(cfg, claude, codex, copilot, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+	mgr := budget.NewManagerFromProviders(cfg, claude, codex, copilot, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budget.WithUsageCache(newUsageCache(cfg)), budgetReservations(database))
+	defer func() { _ = mgr.SaveCache() }()
 
 	providerList, err := resolveProviderList(cfg, filterProvider)
 	if err != nil {
@@ -101,6 +114,12 @@ func runBudget(filterProvider string) error {
 	fmt.Println("================================")
 	fmt.Println()
 
+	if st, err := state.New(database); err == nil {
+		if reservations, err := st.ActiveReservations(strings.ToLower(strings.TrimSpace(filterProvider)), time.Now()); err == nil {
+			printBudgetReservations(os.Stdout, reservations, time.Now())
+		}
+	}
+
 	// Print status for each provider
 	snapCollector := snapshots.NewCollector(database, nil, nil, nil, nil, weekStartDayFromConfig(cfg))
 	for _, provName := range providerList {
@@ -114,6 +133,15 @@ func runBudget(filterProvider string) error {
 	return nil
 }
 
+// formatAllowanceMode shows the allowance window, noting when auto mode
+// picked it as the binding one.
+func formatAllowanceMode(result *budget.AllowanceResult) string {
+	if result.BindingWindow != "" {
+		return fmt.Sprintf("%s (auto: %s window binds)", result.Mode, result.BindingWindow)
+	}
+	return result.Mode
+}
+
 func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName string, source budget.BudgetSource, snapCollector *snapshots.Collector, codex *providers.Codex) error {
 	result, err := mgr.CalculateAllowance(provName)
 	if err != nil {
@@ -154,7 +182,7 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 		usedTokens := int64(float64(dailyBudget) * result.UsedPercent / 100)
 		remaining := dailyBudget - usedTokens
 
-		fmt.Printf("  Mode:         %s\n", result.Mode)
+		fmt.Printf("  Mode:         %s\n", formatAllowanceMode(result))
 		fmt.Printf("  Weekly:       %s tokens%s\n", formatTokens64(weeklyBudget), formatBudgetMeta(estimate))
 		fmt.Printf("  Daily:        %s tokens\n", formatTokens64(dailyBudget))
 
@@ -178,6 +206,9 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 		if provName == "codex" && codex != nil {
 			printCodexBreakdown(codex)
 		}
+		if provName == "codex" {
+			printTokenRatio(mgr)
+		}
 
 		if remaining <= 0 {
 			// Over budget — skip the equation, just show zero available
@@ -186,23 +217,26 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 			if result.PredictedUsage > 0 {
 				fmt.Printf("  Daytime:      %s tokens reserved\n", formatTokens64(result.PredictedUsage))
 			}
+			if result.HeldTokens > 0 {
+				fmt.Printf("  Held:         %s tokens (budget reserve)\n", formatTokens64(result.HeldTokens))
+			}
 			fmt.Printf("  Reserve:      %s tokens\n", formatTokens64(result.ReserveAmount))
 
 			// Nightshift equation: remaining * maxPercent% = preReserve - reserve [- daytime] = available
 			preReserve := remaining * int64(maxPercent) / 100
 			reserve := dailyBudget * int64(reservePercent) / 100
 			if result.PredictedUsage > 0 {
-				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve − %s daytime = %s available\n",
+				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve − %s daytime%s = %s available\n",
 					formatTokens64(remaining), maxPercent, formatTokens64(preReserve),
 					formatTokens64(reserve), formatTokens64(result.PredictedUsage),
-					formatTokens64(result.Allowance))
-				fmt.Printf("  Tonight:      %s remaining × %d%% max = %s − %s reserve = %s if daytime stays flat\n",
+					heldTerm(result), formatTokens64(result.Allowance))
+				fmt.Printf("  Tonight:      %s remaining × %d%% max = %s − %s reserve%s = %s if daytime stays flat\n",
 					formatTokens64(remaining), maxPercent, formatTokens64(preReserve),
-					formatTokens64(reserve), formatTokens64(result.AllowanceNoDaytime))
+					formatTokens64(reserve), heldTerm(result), formatTokens64(result.AllowanceNoDaytime))
 			} else {
-				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve = %s available\n",
+				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve%s = %s available\n",
 					formatTokens64(remaining), maxPercent, formatTokens64(preReserve),
-					formatTokens64(reserve), formatTokens64(result.Allowance))
+					formatTokens64(reserve), heldTerm(result), formatTokens64(result.Allowance))
 			}
 		}
 	} else {
@@ -210,7 +244,7 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 		usedTokens := int64(float64(weeklyBudget) * result.UsedPercent / 100)
 		remaining := weeklyBudget - usedTokens
 
-		fmt.Printf("  Mode:         %s\n", result.Mode)
+		fmt.Printf("  Mode:         %s\n", formatAllowanceMode(result))
 		fmt.Printf("  Weekly:       %s tokens%s\n", formatTokens64(weeklyBudget), formatBudgetMeta(estimate))
 
 		// Used with low-data warning
@@ -234,6 +268,9 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 		if provName == "codex" && codex != nil {
 			printCodexBreakdown(codex)
 		}
+		if provName == "codex" {
+			printTokenRatio(mgr)
+		}
 
 		if remaining <= 0 {
 			// Over budget — skip the equation, just show zero available
@@ -242,10 +279,20 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 			if result.PredictedUsage > 0 {
 				fmt.Printf("  Daytime:      %s tokens reserved\n", formatTokens64(result.PredictedUsage))
 			}
+			if result.HeldTokens > 0 {
+				fmt.Printf("  Held:         %s tokens (budget reserve)\n", formatTokens64(result.HeldTokens))
+			}
 
+			if result.Pacing != "" && result.Pacing != config.PacingGreedy {
+				fmt.Printf("  Pacing:       %s (%.0f%% of the remaining week tonight)\n", result.Pacing, result.PacingShare*100)
+			}
 			if result.Multiplier > 1.0 {
 				fmt.Printf("  Multiplier:   %.1fx (end-of-week)\n", result.Multiplier)
 			}
+			if result.DailyShareCap > 0 {
+				fmt.Printf("  Share cap:    %s tokens (max_daily_share %.0f%% of the week)\n",
+					formatTokens64(result.DailyShareCap), cfg.Budget.MaxDailyShare*100)
+			}
 
 			fmt.Printf("  Reserve:      %s tokens\n", formatTokens64(result.ReserveAmount))
 
@@ -255,20 +302,28 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 				days = 1
 			}
 			perDay := remaining / int64(days)
+			if result.PacingShare > 0 {
+				perDay = int64(float64(remaining) * result.PacingShare)
+			}
 			preReserve := perDay * int64(maxPercent) / 100
+			maxTerm := fmt.Sprintf("%d%% max", maxPercent)
+			if result.DailyShareCap > 0 {
+				preReserve = result.DailyShareCap
+				maxTerm += ", capped"
+			}
 			reserve := result.ReserveAmount
 			if result.PredictedUsage > 0 {
-				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve − %s daytime = %s available\n",
-					formatTokens64(perDay), maxPercent, formatTokens64(preReserve),
+				fmt.Printf("  Nightshift:   %s remaining × %s = %s − %s reserve − %s daytime%s = %s available\n",
+					formatTokens64(perDay), maxTerm, formatTokens64(preReserve),
 					formatTokens64(reserve), formatTokens64(result.PredictedUsage),
-					formatTokens64(result.Allowance))
-				fmt.Printf("  Tonight:      %s remaining × %d%% max = %s − %s reserve = %s if daytime stays flat\n",
-					formatTokens64(perDay), maxPercent, formatTokens64(preReserve),
-					formatTokens64(reserve), formatTokens64(result.AllowanceNoDaytime))
+					heldTerm(result), formatTokens64(result.Allowance))
+				fmt.Printf("  Tonight:      %s remaining × %s = %s − %s reserve%s = %s if daytime stays flat\n",
+					formatTokens64(perDay), maxTerm, formatTokens64(preReserve),
+					formatTokens64(reserve), heldTerm(result), formatTokens64(result.AllowanceNoDaytime))
 			} else {
-				fmt.Printf("  Nightshift:   %s remaining × %d%% max = %s − %s reserve = %s available\n",
-					formatTokens64(perDay), maxPercent, formatTokens64(preReserve),
-					formatTokens64(reserve), formatTokens64(result.Allowance))
+				fmt.Printf("  Nightshift:   %s remaining × %s = %s − %s reserve%s = %s available\n",
+					formatTokens64(perDay), maxTerm, formatTokens64(preReserve),
+					formatTokens64(reserve), heldTerm(result), formatTokens64(result.Allowance))
 			}
 		}
 	}
@@ -296,6 +351,15 @@ func printProviderBudget(mgr *budget.Manager, cfg *config.Config, provName strin
 	return nil
 }
 
+// heldTerm is the budget-reserve term of the allowance equation, or "" when
+// nothing is held back.
+func heldTerm(result *budget.AllowanceResult) string {
+	if result.HeldTokens <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" − %s held", formatTokens64(result.HeldTokens))
+}
+
 // printTokenAccountingNote adds a brief note about how tokens are counted.
 func printTokenAccountingNote(provider string, estimate budget.BudgetEstimate) {
 	if estimate.Source != "calibrated" && estimate.Source != "scraped" {
@@ -348,6 +412,14 @@ func formatResetLine(sessionReset, weeklyReset string) string {
 	return strings.Join(parts, " · ")
 }
 
+// printTokenRatio shows the calibrated Codex/Claude token multiplier, if
+// the daemon has computed one.
+func printTokenRatio(mgr *budget.Manager) {
+	if ratio, ok, err := mgr.TokenRatio(); err == nil && ok {
+		fmt.Printf("  Token ratio:  %.2fx Claude tokens for the same work (calibrated)\n", ratio)
+	}
+}
+
 // printCodexBreakdown shows rate limit and local token data side by side.
 func printCodexBreakdown(codex *providers.Codex) {
 	bd := codex.GetUsageBreakdown()
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/state"
+)
+
+var budgetReserveCmd = &cobra.Command{
+	Use:   "reserve",
+	Short: "Hold back tokens from nightshift until a given time",
+	Long: `Record a temporary reservation that is subtracted from a provider's
+nightshift allowance until it expires, e.g. to leave headroom tonight for a
+big interactive session tomorrow. It applies on top of budget.reserve_percent
+and does not change the config.
+
+--until takes a time (YYYY-MM-DD, YYYY-MM-DD HH:MM, RFC3339, or tomorrow)
+or a duration from now (e.g. 18h). Reservations add up and are shown in
+nightshift budget. Use --clear to remove them (all providers, or just
+--provider).
+
+Examples:
+  nightshift budget reserve --provider claude --tokens 200000 --until tomorrow
+  nightshift budget reserve --provider codex --tokens 50000 --until 12h
+  nightshift budget reserve --clear`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, _ := cmd.Flags().GetString("provider")
+		tokens, _ := cmd.Flags().GetInt64("tokens")
+		until, _ := cmd.Flags().GetString("until")
+		clear, _ := cmd.Flags().GetBool("clear")
+		provider = strings.ToLower(strings.TrimSpace(provider))
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		database, err := db.Open(cfg.ExpandedDBPath())
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+
+		st, err := state.New(database)
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+
+		if clear {
+			if cmd.Flags().Changed("tokens") || cmd.Flags().Changed("until") {
+				return fmt.Errorf("--clear cannot be combined with --tokens or --until")
+			}
+			return runBudgetReserveClear(cmd.OutOrStdout(), st, provider)
+		}
+		if provider == "" {
+			return fmt.Errorf("--provider is required")
+		}
+		if _, err := resolveProviderList(cfg, provider); err != nil {
+			return err
+		}
+		if tokens <= 0 {
+			return fmt.Errorf("--tokens must be > 0")
+		}
+		if until == "" {
+			return fmt.Errorf("--until is required")
+		}
+		now := time.Now()
+		expires, err := parseReserveUntil(until, now)
+		if err != nil {
+			return err
+		}
+		return runBudgetReserve(cmd.OutOrStdout(), st, provider, tokens, expires, now)
+	},
+}
+
+func init() {
+	budgetReserveCmd.Flags().StringP("provider", "p", "", "Provider to hold tokens back from (claude, codex, copilot)")
+	budgetReserveCmd.Flags().Int64("tokens", 0, "Tokens to hold back")
+	budgetReserveCmd.Flags().String("until", "", "When the reservation expires: a time (YYYY-MM-DD HH:MM, RFC3339, tomorrow) or a duration from now (18h)")
+	budgetReserveCmd.Flags().Bool("clear", false, "Remove reservations (all providers, or only --provider)")
+	budgetCmd.AddCommand(budgetReserveCmd)
+}
+
+// parseReserveUntil accepts a duration from now or an absolute time in the
+// local zone. The result must be in the future.
+func parseReserveUntil(value string, now time.Time) (time.Time, error) {
+	var until time.Time
+	if d, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+		until = now.Add(d)
+	} else if until, err = parseTimeInput(value, time.Local); err != nil {
+		return time.Time{}, fmt.Errorf("--until: %w", err)
+	}
+	if !until.After(now) {
+		return time.Time{}, fmt.Errorf("--until %s is not in the future", until.Format("2006-01-02 15:04"))
+	}
+	return until, nil
+}
+
+func runBudgetReserve(w io.Writer, st *state.State, provider string, tokens int64, until, now time.Time) error {
+	if _, err := st.AddReservation(provider, tokens, until); err != nil {
+		return err
+	}
+	held, err := st.ReservedTokens(provider, now)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "Reserved %s tokens from %s until %s (in %s).\n",
+		formatTokens64(tokens), provider, until.Format("2006-01-02 15:04"), formatDuration(until.Sub(now)))
+	if held != tokens {
+		_, _ = fmt.Fprintf(w, "%s now has %s tokens held back in total.\n", provider, formatTokens64(held))
+	}
+	return nil
+}
+
+func runBudgetReserveClear(w io.Writer, st *state.State, provider string) error {
+	n, err := st.ClearReservations(provider)
+	if err != nil {
+		return err
+	}
+	scope := "all providers"
+	if provider != "" {
+		scope = provider
+	}
+	if n == 0 {
+		_, _ = fmt.Fprintf(w, "No reservations to clear for %s.\n", scope)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "Cleared %d reservation(s) for %s.\n", n, scope)
+	return nil
+}
+
+// printBudgetReservations lists active reservations for the budget status
+// view; nothing is printed when there are none.
+func printBudgetReservations(w io.Writer, reservations []state.BudgetReservation, now time.Time) {
+	if len(reservations) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Reservations (budget reserve):")
+	for _, r := range reservations {
+		_, _ = fmt.Fprintf(w, "  %-8s %s tokens until %s (in %s)\n",
+			r.Provider, formatTokens64(r.Tokens), r.Until.Format("2006-01-02 15:04"), formatDuration(r.Until.Sub(now)))
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+// budgetReservations returns an option that subtracts active budget
+// reservations from allowances, for commands without a state handle.
+func budgetReservations(database *db.DB) budget.Option {
+	st, err := state.New(database)
+	if err != nil {
+		logging.Component("budget").Warnf("budget reservations ignored: init state: %v", err)
+		return budget.WithReservations(nil)
+	}
+	return budget.WithReservations(st)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReserveUntil(t *testing.T) {
+	now := time.Date(2026, 3, 4, 20, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{value: "18h", want: now.Add(18 * time.Hour)},
+		{value: "2026-03-05 18:00", want: time.Date(2026, 3, 5, 18, 0, 0, 0, time.Local)},
+		{value: "2026-03-04 08:00", wantErr: "not in the future"},
+		{value: "-1h", wantErr: "not in the future"},
+		{value: "soon", wantErr: "--until"},
+	}
+	for _, tt := range tests {
+		got, err := parseReserveUntil(tt.value, now)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseReserveUntil(%q) err = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseReserveUntil(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestRunBudgetReserve(t *testing.T) {
+	st := newTestRunState(t)
+	now := time.Now()
+
+	var buf bytes.Buffer
+	if err := runBudgetReserve(&buf, st, "claude", 200_000, now.Add(18*time.Hour), now); err != nil {
+		t.Fatalf("runBudgetReserve: %v", err)
+	}
+	if err := runBudgetReserve(&buf, st, "claude", 50_000, now.Add(time.Hour), now); err != nil {
+		t.Fatalf("runBudgetReserve: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "Reserved 200.0K tokens from claude") || !strings.Contains(got, "250.0K tokens held back in total") {
+		t.Errorf("output = %q", got)
+	}
+
+	active, err := st.ActiveReservations("", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	printBudgetReservations(&buf, active, now)
+	if got := buf.String(); !strings.Contains(got, "Reservations (budget reserve):") || strings.Count(got, "claude") != 2 {
+		t.Errorf("reservations = %q", got)
+	}
+
+	buf.Reset()
+	if err := runBudgetReserveClear(&buf, st, ""); err != nil {
+		t.Fatalf("runBudgetReserveClear: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Cleared 2 reservation(s) for all providers.") {
+		t.Errorf("clear output = %q", buf.String())
+	}
+	buf.Reset()
+	printBudgetReservations(&buf, nil, now)
+	if buf.Len() != 0 {
+		t.Errorf("empty reservations printed %q", buf.String())
+	}
+}
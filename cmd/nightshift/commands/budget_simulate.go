@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/calibrator"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/tasks"
+	"github.com/marcus/nightshift/internal/trends"
+)
+
+var budgetSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Preview allowances with different budget settings",
+	Long: `Recompute per-provider allowances against current usage as if the
+given budget settings were configured, and estimate how many more
+medium-cost tasks would fit per run. Config is not modified.
+
+Examples:
+  nightshift budget simulate --max-percent 90
+  nightshift budget simulate --max-percent 90 --weekly-tokens 900000
+  nightshift budget simulate --weekly-tokens 900000 --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var o budgetOverrides
+		if cmd.Flags().Changed("max-percent") {
+			v, _ := cmd.Flags().GetInt("max-percent")
+			o.maxPercent = &v
+		}
+		if cmd.Flags().Changed("weekly-tokens") {
+			v, _ := cmd.Flags().GetInt("weekly-tokens")
+			o.weeklyTokens = &v
+		}
+		if o.maxPercent == nil && o.weeklyTokens == nil {
+			return fmt.Errorf("set at least one of --max-percent or --weekly-tokens")
+		}
+		provider, _ := cmd.Flags().GetString("provider")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		return runBudgetSimulate(o, provider, asJSON)
+	},
+}
+
+func init() {
+	budgetSimulateCmd.Flags().Int("max-percent", 0, "Simulated budget.max_percent")
+	budgetSimulateCmd.Flags().Int("weekly-tokens", 0, "Simulated weekly token budget for every provider (replaces per_provider and calibration)")
+	budgetSimulateCmd.Flags().StringP("provider", "p", "", "Simulate a specific provider (claude, codex, copilot)")
+	budgetSimulateCmd.Flags().Bool("json", false, "Output as JSON")
+	budgetCmd.AddCommand(budgetSimulateCmd)
+}
+
+// budgetOverrides holds the what-if values; nil fields keep the config value.
+type budgetOverrides struct {
+	maxPercent   *int
+	weeklyTokens *int
+}
+
+// simulatedConfig returns a copy of cfg with o applied. The budget maps are
+// cloned so the original config is never mutated.
+func simulatedConfig(cfg *config.Config, o budgetOverrides) *config.Config {
+	sim := *cfg
+	sim.Budget.PerProvider = maps.Clone(cfg.Budget.PerProvider)
+	if o.maxPercent != nil {
+		sim.Budget.MaxPercent = *o.maxPercent
+	}
+	if o.weeklyTokens != nil {
+		sim.Budget.WeeklyTokens = *o.weeklyTokens
+		sim.Budget.PerProvider = nil
+	}
+	return &sim
+}
+
+// budgetSimRow compares one provider's current and simulated allowance.
+type budgetSimRow struct {
+	Provider           string  `json:"provider"`
+	UsedPercent        float64 `json:"used_percent"`
+	CurrentAllowance   int64   `json:"current_allowance"`
+	SimulatedAllowance int64   `json:"simulated_allowance"`
+	CurrentTasks       int64   `json:"current_medium_tasks"`
+	SimulatedTasks     int64   `json:"simulated_medium_tasks"`
+	AdditionalTasks    int64   `json:"additional_medium_tasks"`
+	Error              string  `json:"error,omitempty"`
+}
+
+// mediumTaskTokens is the midpoint of the medium cost tier, used to turn an
+// allowance into a rough task count.
+func mediumTaskTokens() int64 {
+	lo, hi := tasks.CostMedium.TokenRange()
+	return int64(lo+hi) / 2
+}
+
+// simulateAllowances computes allowances from both managers for each provider.
+func simulateAllowances(current, simulated *budget.Manager, names []string) []budgetSimRow {
+	per := mediumTaskTokens()
+	rows := make([]budgetSimRow, 0, len(names))
+	for _, name := range names {
+		row := budgetSimRow{Provider: name}
+		cur, err := current.CalculateAllowance(name)
+		if err == nil {
+			var sim *budget.AllowanceResult
+			sim, err = simulated.CalculateAllowance(name)
+			if err == nil {
+				row.UsedPercent = sim.UsedPercent
+				row.CurrentAllowance = cur.Allowance
+				row.SimulatedAllowance = sim.Allowance
+				row.CurrentTasks = cur.Allowance / per
+				row.SimulatedTasks = sim.Allowance / per
+				row.AdditionalTasks = row.SimulatedTasks - row.CurrentTasks
+			}
+		}
+		if err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func runBudgetSimulate(o budgetOverrides, filterProvider string, asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	sim := simulatedConfig(cfg, o)
+	if err := config.Validate(sim); err != nil {
+		return fmt.Errorf("simulated config: %w", err)
+	}
+
+	names, err := resolveProviderList(cfg, filterProvider)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No providers enabled.")
+		return nil
+	}
+
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	claude := newClaudeProvider(cfg)
+	codex := newCodexProvider(cfg)
+	copilot := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
+
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+	current := budget.NewManagerFromProviders(cfg, claude, codex, copilot,
+		budget.WithBudgetSource(calibrator.New(database, cfg)), budget.WithTrendAnalyzer(trend))
+	simOpts := []budget.Option{budget.WithTrendAnalyzer(trend)}
+	if o.weeklyTokens == nil {
+		// An explicit weekly budget replaces calibration, as it would if
+		// calibration had no samples yet.
+		simOpts = append(simOpts, budget.WithBudgetSource(calibrator.New(database, sim)))
+	}
+	simulated := budget.NewManagerFromProviders(sim, claude, codex, copilot, simOpts...)
+
+	rows := simulateAllowances(current, simulated, names)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	printBudgetSimulation(os.Stdout, cfg, o, rows)
+	return nil
+}
+
+func printBudgetSimulation(w io.Writer, cfg *config.Config, o budgetOverrides, rows []budgetSimRow) {
+	_, _ = fmt.Fprintln(w, "Budget Simulation (config not modified)")
+	_, _ = fmt.Fprintln(w, "=======================================")
+	if o.maxPercent != nil {
+		_, _ = fmt.Fprintf(w, "max_percent:   %d%% -> %d%%\n", cfg.Budget.MaxPercent, *o.maxPercent)
+	}
+	if o.weeklyTokens != nil {
+		_, _ = fmt.Fprintf(w, "weekly_tokens: %s (all providers)\n", formatTokens64(int64(*o.weeklyTokens)))
+	}
+	_, _ = fmt.Fprintln(w)
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "Provider\tUsed\tAllowance\tSimulated\tMedium tasks")
+	for _, row := range rows {
+		if row.Error != "" {
+			_, _ = fmt.Fprintf(writer, "%s\terror: %s\t\t\t\n", row.Provider, row.Error)
+			continue
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%.1f%%\t%s\t%s\t%d -> %d (%+d)\n",
+			row.Provider, row.UsedPercent,
+			formatTokens64(row.CurrentAllowance), formatTokens64(row.SimulatedAllowance),
+			row.CurrentTasks, row.SimulatedTasks, row.AdditionalTasks)
+	}
+	_ = writer.Flush()
+	_, _ = fmt.Fprintf(w, "\nMedium tasks assume ~%s tokens each, per run.\n", formatTokens64(mediumTaskTokens()))
+}
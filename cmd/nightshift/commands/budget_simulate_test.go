@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestSimulatedConfig_DoesNotMutate(t *testing.T) {
+	cfg := &config.Config{Budget: config.BudgetConfig{
+		MaxPercent:   75,
+		WeeklyTokens: 700000,
+		PerProvider:  map[string]int{"claude": 500000},
+	}}
+	pct, weekly := 90, 900000
+
+	sim := simulatedConfig(cfg, budgetOverrides{maxPercent: &pct, weeklyTokens: &weekly})
+
+	if sim.Budget.MaxPercent != 90 || sim.Budget.WeeklyTokens != 900000 {
+		t.Errorf("sim budget = %+v, want max 90, weekly 900000", sim.Budget)
+	}
+	if sim.GetProviderBudget("claude") != 900000 {
+		t.Errorf("sim claude budget = %d, want 900000 (per_provider replaced)", sim.GetProviderBudget("claude"))
+	}
+	if cfg.Budget.MaxPercent != 75 || cfg.Budget.WeeklyTokens != 700000 || cfg.Budget.PerProvider["claude"] != 500000 {
+		t.Errorf("original config mutated: %+v", cfg.Budget)
+	}
+}
+
+func TestSimulateAllowances(t *testing.T) {
+	cfg := &config.Config{Budget: config.BudgetConfig{
+		Mode:         "daily",
+		MaxPercent:   50,
+		WeeklyTokens: 1400000,
+	}}
+	pct := 100
+	sim := simulatedConfig(cfg, budgetOverrides{maxPercent: &pct})
+
+	claude := &mockUsage{name: "claude", pct: 0}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	current := budget.NewManager(cfg, claude, codex, copilot)
+	simulated := budget.NewManager(sim, claude, codex, copilot)
+
+	rows := simulateAllowances(current, simulated, []string{"claude", "bogus"})
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	row := rows[0]
+	if row.Error != "" {
+		t.Fatalf("claude error: %s", row.Error)
+	}
+	// Daily budget 200K: 50% -> 100K (1 medium task), 100% -> 200K (2 tasks).
+	if row.CurrentAllowance != 100000 || row.SimulatedAllowance != 200000 {
+		t.Errorf("allowance = %d -> %d, want 100000 -> 200000", row.CurrentAllowance, row.SimulatedAllowance)
+	}
+	if row.CurrentTasks != 1 || row.SimulatedTasks != 2 || row.AdditionalTasks != 1 {
+		t.Errorf("tasks = %d -> %d (+%d), want 1 -> 2 (+1)", row.CurrentTasks, row.SimulatedTasks, row.AdditionalTasks)
+	}
+	if rows[1].Error == "" {
+		t.Error("expected error for unknown provider")
+	}
+
+	var buf strings.Builder
+	printBudgetSimulation(&buf, cfg, budgetOverrides{maxPercent: &pct}, rows)
+	for _, want := range []string{"max_percent:   50% -> 100%", "1 -> 2 (+1)", "bogus"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\n%s", want, buf.String())
+		}
+	}
+}
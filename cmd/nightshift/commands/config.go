@@ -1,15 +1,19 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 
 	"github.com/marcus/nightshift/internal/config"
 )
@@ -34,10 +38,13 @@ var configGetCmd = &cobra.Command{
 Examples:
   nightshift config get budget.max_percent
   nightshift config get providers.claude.enabled
-  nightshift config get logging.level`,
+  nightshift config get logging.level
+  nightshift config get providers --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runConfigGet(args[0])
+		asJSON, _ := cmd.Flags().GetBool("json")
+		strict, _ := cmd.Flags().GetBool("strict")
+		return runConfigGet(args[0], asJSON, strict)
 	},
 }
 
@@ -49,14 +56,20 @@ var configSetCmd = &cobra.Command{
 Writes to the project config if it exists, otherwise to global config.
 Use --global to always write to global config.
 
+Values for known keys are parsed by field type (lists are comma-separated)
+and the result is validated before anything is written. Prints the old and
+new value. Use --strict to reject keys that don't map to a config field.
+
 Examples:
   nightshift config set budget.max_percent 15
   nightshift config set logging.level debug
-  nightshift config set providers.claude.enabled false`,
+  nightshift config set providers.claude.enabled false
+  nightshift config set providers.preference codex,claude`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		global, _ := cmd.Flags().GetBool("global")
-		return runConfigSet(args[0], args[1], global)
+		strict, _ := cmd.Flags().GetBool("strict")
+		return runConfigSet(args[0], args[1], global, strict)
 	},
 }
 
@@ -65,17 +78,46 @@ var configValidateCmd = &cobra.Command{
 	Short: "Validate configuration file",
 	Long: `Validate the current configuration.
 
-Checks both global and project configs for errors.`,
+Checks both global and project configs for errors.
+
+Unknown keys are silently ignored when loading, so a typo such as
+dangerouslyskippermissions leaves the setting at its default. Use --strict
+to report any key that doesn't map to a config field as an error.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		strict, _ := cmd.Flags().GetBool("strict")
+		return runConfigValidate(strict)
+	},
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show settings that differ from defaults",
+	Long: `Show only the configuration values that differ from built-in defaults.
+
+Loads the effective config (global + project + environment) and compares
+each field against the defaults. Useful for sharing a minimal config or
+auditing drift.
+
+Examples:
+  nightshift config diff
+  nightshift config diff --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runConfigValidate()
+		asJSON, _ := cmd.Flags().GetBool("json")
+		return runConfigDiff(asJSON)
 	},
 }
 
 func init() {
+	configGetCmd.Flags().Bool("json", false, "Output value as JSON")
+	configGetCmd.Flags().Bool("strict", false, "Reject keys that don't map to a config field")
 	configSetCmd.Flags().BoolP("global", "g", false, "Write to global config instead of project config")
+	configSetCmd.Flags().Bool("strict", false, "Reject keys that don't map to a config field")
+	configValidateCmd.Flags().Bool("strict", false, "Treat unknown config keys as errors")
+	configDiffCmd.Flags().Bool("json", false, "Output overrides as JSON")
 	configCmd.AddCommand(configGetCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configDiffCmd)
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -114,8 +156,14 @@ func runConfigShow() error {
 	return nil
 }
 
-// runConfigGet retrieves a specific config value by key path.
-func runConfigGet(key string) error {
+// runConfigGet retrieves a specific config value by key path. With strict,
+// keys that don't map to a config field are rejected.
+func runConfigGet(key string, asJSON, strict bool) error {
+	field, known := lookupConfigField(key)
+	if !known && strict {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+
 	v := viper.New()
 
 	// Load configs into viper
@@ -124,10 +172,23 @@ func runConfigGet(key string) error {
 	}
 
 	value := v.Get(key)
+	if value == nil && known {
+		// Not set in any file; report the built-in default.
+		value = field.Interface()
+	}
 	if value == nil {
 		return fmt.Errorf("key not found: %s", key)
 	}
 
+	if asJSON {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding json: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
 	// Format output based on type
 	switch val := value.(type) {
 	case map[string]interface{}:
@@ -141,8 +202,16 @@ func runConfigGet(key string) error {
 	return nil
 }
 
-// runConfigSet sets a config value and writes it back.
-func runConfigSet(key, value string, useGlobal bool) error {
+// runConfigSet sets a config value and writes it back. Values for known keys
+// are parsed according to the field type and the resulting file is validated
+// before it is written. With strict, unknown keys are rejected.
+func runConfigSet(key, value string, useGlobal, strict bool) error {
+	key = strings.ToLower(key)
+	field, known := lookupConfigField(key)
+	if !known && strict {
+		return fmt.Errorf("unknown config key: %s", key)
+	}
+
 	// Determine which config file to write to
 	var configPath string
 	if useGlobal {
@@ -156,12 +225,6 @@ func runConfigSet(key, value string, useGlobal bool) error {
 		}
 	}
 
-	// Ensure directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
-
 	// Load existing config or create new viper instance
 	v := viper.New()
 	v.SetConfigFile(configPath)
@@ -175,9 +238,36 @@ func runConfigSet(key, value string, useGlobal bool) error {
 	}
 
 	// Parse and set the value
-	parsedValue := parseValue(value)
+	var parsedValue any
+	if known {
+		var err error
+		if parsedValue, err = parseTypedValue(field.Type(), value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	} else {
+		parsedValue = parseValue(value)
+	}
+	oldValue := v.Get(key)
 	v.Set(key, parsedValue)
 
+	// Validate the file as it would load on top of the defaults
+	cfg, err := config.Defaults()
+	if err != nil {
+		return fmt.Errorf("loading defaults: %w", err)
+	}
+	if err := v.Unmarshal(cfg); err != nil {
+		return fmt.Errorf("parsing: %w", err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	// Ensure directory exists
+	configDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
 	// Write back to file
 	if err := v.WriteConfig(); err != nil {
 		// If file doesn't exist, use SafeWriteConfig
@@ -190,18 +280,18 @@ func runConfigSet(key, value string, useGlobal bool) error {
 		}
 	}
 
-	fmt.Printf("Set %s = %v in %s\n", key, parsedValue, configPath)
-
-	// Validate the new config
-	if _, err := config.Load(); err != nil {
-		fmt.Printf("Warning: config validation failed: %v\n", err)
+	old := "(unset)"
+	if oldValue != nil {
+		old = fmt.Sprint(oldValue)
 	}
+	fmt.Printf("%s: %s → %v (%s)\n", key, old, parsedValue, configPath)
 
 	return nil
 }
 
-// runConfigValidate validates the configuration files.
-func runConfigValidate() error {
+// runConfigValidate validates the configuration files. With strict, keys
+// that don't map to a config field are reported as errors.
+func runConfigValidate(strict bool) error {
 	fmt.Println("Validating configuration...")
 	fmt.Println()
 
@@ -213,7 +303,7 @@ func runConfigValidate() error {
 	// Validate global config if it exists
 	if fileExists(globalPath) {
 		fmt.Printf("Global config: %s\n", globalPath)
-		if err := validateConfigFile(globalPath); err != nil {
+		if err := validateConfigFile(globalPath, strict); err != nil {
 			fmt.Printf("  Error: %v\n", err)
 			hasErrors = true
 		} else {
@@ -225,7 +315,7 @@ func runConfigValidate() error {
 	// Validate project config if it exists
 	if fileExists(projectPath) {
 		fmt.Printf("Project config: %s\n", projectPath)
-		if err := validateConfigFile(projectPath); err != nil {
+		if err := validateConfigFile(projectPath, strict); err != nil {
 			fmt.Printf("  Error: %v\n", err)
 			hasErrors = true
 		} else {
@@ -258,6 +348,153 @@ func runConfigValidate() error {
 	return nil
 }
 
+// configOverride is a single config field whose value differs from its default.
+type configOverride struct {
+	Key     string
+	Value   any
+	Default any
+}
+
+// runConfigDiff prints config values that differ from the built-in defaults.
+func runConfigDiff(asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	defaults, err := config.Defaults()
+	if err != nil {
+		return err
+	}
+
+	overrides := diffConfig(cfg, defaults)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(nestOverrides(overrides))
+	}
+
+	if len(overrides) == 0 {
+		fmt.Println("No settings differ from defaults.")
+		return nil
+	}
+	for _, o := range overrides {
+		fmt.Printf("%s: %s (default: %s)\n", o.Key, formatConfigValue(o.Value), formatConfigValue(o.Default))
+	}
+	return nil
+}
+
+// diffConfig walks both configs by mapstructure key and returns the leaf
+// fields whose values differ. Slices and maps are compared as whole values.
+func diffConfig(cfg, defaults *config.Config) []configOverride {
+	var out []configOverride
+	diffValues("", reflect.ValueOf(cfg).Elem(), reflect.ValueOf(defaults).Elem(), &out)
+	return out
+}
+
+func diffValues(prefix string, cur, def reflect.Value, out *[]configOverride) {
+	t := cur.Type()
+	for i := 0; i < cur.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" {
+			tag = strings.ToLower(field.Name)
+		}
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		cv, dv := cur.Field(i), def.Field(i)
+		if cv.Kind() == reflect.Struct {
+			diffValues(key, cv, dv, out)
+			continue
+		}
+		if cv.Kind() == reflect.Ptr && !cv.IsNil() && !dv.IsNil() && cv.Elem().Kind() == reflect.Struct {
+			diffValues(key, cv.Elem(), dv.Elem(), out)
+			continue
+		}
+		if isZero(cv) && isZero(dv) {
+			continue
+		}
+		if reflect.DeepEqual(cv.Interface(), dv.Interface()) {
+			continue
+		}
+		*out = append(*out, configOverride{Key: key, Value: derefValue(cv), Default: derefValue(dv)})
+	}
+}
+
+// derefValue returns the underlying value of v, unwrapping non-nil pointers
+// and converting structs (e.g. project entries) into maps keyed by their
+// mapstructure tags so output matches the config file layout.
+func derefValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return derefValue(v.Elem())
+	case reflect.Struct:
+		m := map[string]any{}
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if isZero(v.Field(i)) {
+				continue
+			}
+			tag := t.Field(i).Tag.Get("mapstructure")
+			if tag == "" {
+				tag = strings.ToLower(t.Field(i).Name)
+			}
+			m[tag] = derefValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return v.Interface()
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = derefValue(v.Index(i))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
+
+// nestOverrides converts dotted keys into a nested map for JSON output.
+func nestOverrides(overrides []configOverride) map[string]any {
+	root := map[string]any{}
+	for _, o := range overrides {
+		parts := strings.Split(o.Key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[part] = child
+			}
+			node = child
+		}
+		node[parts[len(parts)-1]] = o.Value
+	}
+	return root
+}
+
+func formatConfigValue(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 {
+		return "<unset>"
+	}
+	if s, ok := v.(string); ok && s == "" {
+		return `""`
+	}
+	return fmt.Sprintf("%v", v)
+}
+
 // Helper functions
 
 func findProjectConfigPath() string {
@@ -299,7 +536,17 @@ func loadViperConfig(v *viper.Viper) error {
 	return nil
 }
 
-func validateConfigFile(path string) error {
+func validateConfigFile(path string, strict bool) error {
+	if strict {
+		unknown, err := unknownConfigKeysInFile(path)
+		if err != nil {
+			return err
+		}
+		if len(unknown) > 0 {
+			return fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+		}
+	}
+
 	v := viper.New()
 	v.SetConfigFile(expandPath(path))
 	v.SetConfigType("yaml")
@@ -316,6 +563,164 @@ func validateConfigFile(path string) error {
 	return config.Validate(&cfg)
 }
 
+// unknownConfigKeysInFile reads the raw YAML at path and returns the dotted
+// keys that don't map to a config field.
+func unknownConfigKeysInFile(path string) ([]string, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing: %w", err)
+	}
+	return unknownConfigKeys(raw), nil
+}
+
+// unknownConfigKeys walks raw alongside the config structs by mapstructure
+// key and returns the dotted paths of keys with no matching field. Keys are
+// matched case-insensitively, as viper does. List entries are checked
+// against their element struct; map-typed fields accept any key.
+func unknownConfigKeys(raw map[string]any) []string {
+	var out []string
+	collectUnknownKeys("", raw, reflect.TypeOf(config.Config{}), &out)
+	sort.Strings(out)
+	return out
+}
+
+func collectUnknownKeys(prefix string, raw any, t reflect.Type, out *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("mapstructure")
+			if tag == "" {
+				tag = strings.ToLower(field.Name)
+			}
+			fields[tag] = field.Type
+		}
+		for key, val := range m {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			ft, ok := fields[strings.ToLower(key)]
+			if !ok {
+				*out = append(*out, path)
+				continue
+			}
+			collectUnknownKeys(path, val, ft, out)
+		}
+	case reflect.Slice:
+		list, ok := raw.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range list {
+			collectUnknownKeys(fmt.Sprintf("%s[%d]", prefix, i), item, t.Elem(), out)
+		}
+	}
+}
+
+// lookupConfigField resolves a dotted mapstructure key against the default
+// config and returns the matching field. Keys under map-typed fields resolve
+// to the map's element (its zero value when absent). List entries can't be
+// addressed.
+func lookupConfigField(key string) (reflect.Value, bool) {
+	cfg, err := config.Defaults()
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	cur := reflect.ValueOf(cfg).Elem()
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		switch cur.Kind() {
+		case reflect.Struct:
+			t := cur.Type()
+			found := false
+			for i := 0; i < t.NumField(); i++ {
+				tag := t.Field(i).Tag.Get("mapstructure")
+				if tag == "" {
+					tag = strings.ToLower(t.Field(i).Name)
+				}
+				if tag == part {
+					cur = cur.Field(i)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return reflect.Value{}, false
+			}
+		case reflect.Map:
+			if cur.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, false
+			}
+			elem := cur.MapIndex(reflect.ValueOf(part).Convert(cur.Type().Key()))
+			if !elem.IsValid() {
+				elem = reflect.Zero(cur.Type().Elem())
+			}
+			cur = elem
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return cur, true
+}
+
+// parseTypedValue parses value for a field of type t. Lists are
+// comma-separated; structs and lists of structs must be edited in the file.
+func parseTypedValue(t reflect.Type, value string) (any, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", value)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("expected a duration, got %q", value)
+			}
+			return d.String(), nil
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", value)
+		}
+		return i, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return f, nil
+	case reflect.String:
+		return value, nil
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			break
+		}
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("cannot set a %s from the command line; edit the config file", t.Kind())
+}
+
 func parseValue(value string) interface{} {
 	// Try to parse as bool
 	if value == "true" {
@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestDiffConfig(t *testing.T) {
+	defaults, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+	cfg, err := config.Defaults()
+	if err != nil {
+		t.Fatalf("Defaults: %v", err)
+	}
+
+	if got := diffConfig(cfg, defaults); len(got) != 0 {
+		t.Fatalf("expected no overrides for defaults, got %v", got)
+	}
+
+	cfg.Budget.MaxPercent = 50
+	cfg.Logging.Level = "debug"
+	cfg.Projects = []config.ProjectConfig{{Path: "~/code/app"}}
+
+	got := diffConfig(cfg, defaults)
+	want := map[string]any{
+		"budget.max_percent": 50,
+		"logging.level":      "debug",
+	}
+	keys := map[string]bool{}
+	for _, o := range got {
+		keys[o.Key] = true
+		if w, ok := want[o.Key]; ok && o.Value != w {
+			t.Errorf("%s = %v, want %v", o.Key, o.Value, w)
+		}
+	}
+	for key := range want {
+		if !keys[key] {
+			t.Errorf("missing override %s", key)
+		}
+	}
+	if !keys["projects"] {
+		t.Error("missing override projects")
+	}
+	if len(got) != 3 {
+		t.Errorf("overrides = %d, want 3: %v", len(got), got)
+	}
+
+	nested := nestOverrides(got)
+	budget, ok := nested["budget"].(map[string]any)
+	if !ok || budget["max_percent"] != 50 {
+		t.Errorf("nested budget = %v, want max_percent=50", nested["budget"])
+	}
+}
+
+func TestUnknownConfigKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "all known",
+			yaml: `
+budget:
+  max_percent: 50
+  per_provider:
+    claude: 40
+providers:
+  claude:
+    enabled: true
+    dangerously_skip_permissions: true
+schedule:
+  window:
+    start: "22:00"
+projects:
+  - path: ~/code/app
+tasks:
+  priorities:
+    lint-fix: 3
+`,
+		},
+		{
+			name: "typos at every level",
+			yaml: `
+budgett:
+  max_percent: 50
+providers:
+  claude:
+    dangerouslyskippermissions: true
+projects:
+  - path: ~/code/app
+    prio: 2
+`,
+			want: []string{"budgett", "projects[0].prio", "providers.claude.dangerouslyskippermissions"},
+		},
+		{
+			name: "case insensitive",
+			yaml: "Logging:\n  Level: debug\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := unknownConfigKeysInFile(path)
+			if err != nil {
+				t.Fatalf("unknownConfigKeysInFile: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("unknown keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTypedValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{key: "budget.max_percent", value: "15", want: 15},
+		{key: "budget.max_percent", value: "lots", wantErr: true},
+		{key: "providers.claude.enabled", value: "false", want: false},
+		{key: "providers.claude.enabled", value: "nope", wantErr: true},
+		{key: "logging.level", value: "debug", want: "debug"},
+		{key: "providers.preference", value: "codex, claude", want: []string{"codex", "claude"}},
+		{key: "tasks.priorities.lint-fix", value: "3", want: 3},
+		{key: "providers.claude", value: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			field, ok := lookupConfigField(tt.key)
+			if !ok {
+				t.Fatalf("lookupConfigField(%q) not found", tt.key)
+			}
+			got, err := parseTypedValue(field.Type(), tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := lookupConfigField("budget.max_precent"); ok {
+		t.Error("expected typo'd key to be unknown")
+	}
+}
+
+func TestRunConfigSet(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+
+	if err := runConfigSet("budget.max_percent", "15", true, true); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := runConfigSet("budget.max_percent", "150", true, true); err == nil {
+		t.Error("expected out-of-range value to be rejected")
+	}
+	if err := runConfigSet("budget.max_precent", "15", true, true); err == nil {
+		t.Error("expected unknown key to be rejected with strict")
+	}
+
+	data, err := os.ReadFile(config.GlobalConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "max_percent: 15") {
+		t.Errorf("config file = %q, want max_percent: 15", data)
+	}
+}
@@ -3,12 +3,13 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -59,16 +60,23 @@ var daemonStopCmd = &cobra.Command{
 var daemonStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check daemon status",
-	Long:  `Check if the nightshift daemon is running and show status information.`,
-	RunE:  runDaemonStatus,
+	Long: `Check if the nightshift daemon is running and show status information.
+
+Use --next N to list the next N scheduled runs in the window's timezone,
+whether or not the daemon is running.`,
+	RunE: runDaemonStatus,
 }
 
-var daemonForegroundFlag bool
+var (
+	daemonForegroundFlag bool
+	daemonStatusNext     int
+)
 
 func init() {
 	daemonStartCmd.Flags().BoolVarP(&daemonForegroundFlag, "foreground", "f", false, "Run in foreground (don't daemonize)")
 	daemonCmd.AddCommand(daemonStartCmd)
 	daemonCmd.AddCommand(daemonStopCmd)
+	daemonStatusCmd.Flags().IntVar(&daemonStatusNext, "next", 0, "List the next N scheduled run times")
 	daemonCmd.AddCommand(daemonStatusCmd)
 	rootCmd.AddCommand(daemonCmd)
 }
@@ -173,16 +181,16 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 }
 
 func runDaemonLoop(cfg *config.Config) error {
-	// Augment PATH so provider CLIs are discoverable when launched
-	// from launchd/systemd/cron which have a minimal PATH.
-	ensurePATH()
-
 	// Initialize logging
 	if err := initLogging(cfg); err != nil {
 		return fmt.Errorf("init logging: %w", err)
 	}
 	log := logging.Component("daemon")
 
+	// Augment PATH so provider CLIs are discoverable when launched
+	// from launchd/systemd/cron which have a minimal PATH.
+	ensurePATH(cfg.ExpandedExtraPathDirs(), log)
+
 	// Write PID file
 	if err := writePidFile(); err != nil {
 		return fmt.Errorf("write pid file: %w", err)
@@ -201,13 +209,10 @@ func runDaemonLoop(cfg *config.Config) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		sig := <-sigCh
-		log.Infof("received signal %v, shutting down", sig)
-		cancel()
-	}()
+	// First signal drains (let the in-flight run finish its current task),
+	// second signal or grace expiry force-cancels
+	shutdown := newGracefulShutdown(cancel, cfg.GetShutdownGrace(), log)
+	shutdown.listen(ctx)
 
 	// Initialize scheduler from config
 	sched, err := scheduler.NewFromConfig(&cfg.Schedule)
@@ -215,11 +220,25 @@ func runDaemonLoop(cfg *config.Config) error {
 		return fmt.Errorf("init scheduler: %w", err)
 	}
 
-	// Add the main run job
+	heartbeat := newHeartbeatWriter(heartbeatFilePath(), sched, log)
+
+	// Add the main run job. runMu is held for the duration of a run so
+	// shutdown can wait for it to drain.
+	var runMu sync.RWMutex
 	sched.AddJob(func(jobCtx context.Context) error {
-		return runScheduledTasks(jobCtx, cfg, database, log)
+		runMu.RLock()
+		defer runMu.RUnlock()
+		if shutdown.Draining() {
+			log.Info("draining: skipping scheduled run")
+			return nil
+		}
+		start := time.Now()
+		err := runScheduledTasks(jobCtx, cfg, database, shutdown, log)
+		heartbeat.recordRun(start, err)
+		return err
 	})
 
+	heartbeat.start(ctx)
 	startSnapshotLoop(ctx, cfg, database, log)
 	startSnapshotPruneLoop(ctx, cfg, database, log)
 
@@ -232,21 +251,53 @@ func runDaemonLoop(cfg *config.Config) error {
 		"next_run": sched.NextRun().Format(time.RFC3339),
 	})
 
-	// Wait for context cancellation
-	<-ctx.Done()
+	var statusSrv *statusServer
+	if addr := cfg.DaemonHTTPAddr(); addr != "" {
+		statusSrv = newStatusServer(cfg, database, sched, shutdown, log)
+		if err := statusSrv.start(addr); err != nil {
+			log.Errorf("%v", err)
+			statusSrv = nil
+		}
+	}
+
+	// Wait for a shutdown signal, then for any in-flight run to drain
+	select {
+	case <-ctx.Done():
+	case <-shutdown.Done():
+		idle := make(chan struct{})
+		go func() {
+			runMu.Lock()
+			runMu.Unlock()
+			close(idle)
+		}()
+		select {
+		case <-idle:
+			log.Info("drain complete")
+		case <-ctx.Done():
+		}
+		cancel()
+	}
 
 	// Stop scheduler gracefully
 	if err := sched.Stop(); err != nil && err != scheduler.ErrNotRunning {
 		log.Errorf("stopping scheduler: %v", err)
 	}
+	statusSrv.stop()
 
 	log.Info("daemon stopped")
 	return nil
 }
 
 // runScheduledTasks executes the scheduled nightshift tasks.
-func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB, log *logging.Logger) error {
+func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB, shutdown *gracefulShutdown, log *logging.Logger) error {
 	log.Info("scheduled run starting")
+	if err := waitForStartupDelay(ctx, cfg, shutdown, log); err != nil {
+		return err
+	}
+	if shutdown.Draining() {
+		log.Info("draining, skipping scheduled run")
+		return nil
+	}
 	start := time.Now()
 
 	// Initialize state manager
@@ -263,16 +314,18 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 	}
 
 	// Initialize providers
-	claudeProvider := providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude"))
-	codexProvider := providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex"))
+	claudeProvider := newClaudeProvider(cfg)
+	codexProvider := newCodexProvider(cfg)
 	copilotProvider := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
 
 	// Initialize budget manager
 	cal := calibrator.New(database, cfg)
 	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
-	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budget.WithReservations(st))
+	meters := newTokenMeters(claudeProvider, codexProvider)
 
-	report := newRunReport(time.Now(), calculateRunBudgetStart(cfg, budgetMgr, log))
+	report := newRunReport(cfg, time.Now(), calculateRunBudgetStart(cfg, budgetMgr, log))
+	report.warnReportsDir(log)
 
 	// Resolve projects
 	projects, err := resolveProjects(cfg, "")
@@ -288,8 +341,17 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 
 	// Create task selector
 	selector := tasks.NewSelector(cfg, st)
+	if cfg.Tasks.SinceReport {
+		applyReportedRuns(selector, reportsDir(cfg), projects, log)
+	}
+	if cats := applyWindowCategories(selector, cfg, time.Now()); len(cats) > 0 {
+		log.Infof("schedule window limits tasks to: %s", strings.Join(cats, ", "))
+	}
+	if day, theme := applyScheduleTheme(selector, cfg, time.Now(), log); len(theme) > 0 {
+		log.Infof("%s theme limits tasks to: %s", day, strings.Join(theme, ", "))
+	}
 
-	var tasksRun, tasksCompleted, tasksFailed int
+	var tasksRun, tasksCompleted, tasksPartial, tasksFailed int
 
 	// Process each project
 	for _, projectPath := range projects {
@@ -299,9 +361,13 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 			return ctx.Err()
 		default:
 		}
+		if shutdown.Draining() {
+			log.Infof("draining: not starting project %s", filepath.Base(projectPath))
+			break
+		}
 
-		// Skip if already processed today
-		if st.WasProcessedToday(projectPath) {
+		// Skip if already processed within the window
+		if st.WasProcessedSince(projectPath, cfg.ProcessedSince(time.Now())) {
 			log.Debugf("skip %s (processed today)", projectPath)
 			continue
 		}
@@ -319,25 +385,43 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 			break
 		}
 
-		orch := orchestrator.New(
-			orchestrator.WithAgent(choice.agent),
-			orchestrator.WithConfig(orchestrator.Config{
-				MaxIterations: 3,
-				AgentTimeout:  30 * time.Minute,
-			}),
-			orchestrator.WithLogger(logging.Component("orchestrator")),
-		)
+		// One orchestrator per provider the project's tasks run on
+		orchs := make(map[string]*orchestrator.Orchestrator)
+		orchFor := func(choice *providerChoice) *orchestrator.Orchestrator {
+			if orch := orchs[choice.name]; orch != nil {
+				return orch
+			}
+			orch := orchestrator.New(
+				orchestrator.WithAgent(choice.agent),
+				orchestrator.WithConfig(orchestrator.Config{
+					MaxIterations: 3,
+					AgentTimeout:  30 * time.Minute,
+					AgentOutput:   orchestrator.AgentOutputMode(cfg.GetAgentOutput()),
+				}),
+				orchestrator.WithLogger(logging.Component("orchestrator")),
+				orchestrator.WithTokenMeter(meters[choice.name]),
+				orchestrator.WithEventLog(report.eventLogDir()),
+			)
+			orchs[choice.name] = orch
+			return orch
+		}
+		projectChoice := choice
 
 		// Select tasks
-		selectedTasks := selector.SelectTopN(allowance.Allowance, projectPath, 5)
+		ex := selector.ExplainTopN(allowance.Allowance, projectPath, 5)
+		selectedTasks := ex.Selected
 		if len(selectedTasks) == 0 {
+			skipReason := "no tasks available within budget"
+			if ex.NoneAboveMin() {
+				skipReason = "no task above min score"
+			}
 			if report != nil {
 				report.addTask(reporting.TaskResult{
 					Project:    projectPath,
 					TaskType:   "",
 					Title:      "No tasks selected",
 					Status:     "skipped",
-					SkipReason: "no tasks available within budget",
+					SkipReason: skipReason,
 				})
 			}
 			continue
@@ -356,12 +440,49 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 		projectTokensUsed := 0
 		projectCompleted := 0
 		projectFailed := 0
-		for _, scoredTask := range selectedTasks {
+		projectTimeout := cfg.GetProjectTimeout()
+		for i, scoredTask := range selectedTasks {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			default:
 			}
+			if projectTimedOut(projectStart, projectTimeout) {
+				remaining := selectedTasks[i:]
+				log.Warnf("project %s: timeout %s reached, skipping %d task(s)", filepath.Base(projectPath), projectTimeout, len(remaining))
+				for _, rest := range remaining {
+					report.addTask(projectTimeoutResult(projectPath, rest))
+				}
+				break
+			}
+			choice := projectChoice
+			if hasTierPreference(cfg, scoredTask.Definition.CostTier) {
+				tierChoice, err := selectTaskProvider(cfg, budgetMgr, log, false, scoredTask.Definition, selector.ReservedTokens(scoredTask.Definition), nil)
+				if err != nil {
+					log.Infof("task %s: no provider for cost tier %s: %v", scoredTask.Definition.Type, scoredTask.Definition.CostTier.Name(), err)
+					report.addTask(reporting.TaskResult{
+						Project:    projectPath,
+						TaskType:   string(scoredTask.Definition.Type),
+						Title:      scoredTask.Definition.Name,
+						Status:     "skipped",
+						SkipReason: fmt.Sprintf("no provider for cost tier %s: %v", scoredTask.Definition.CostTier.Name(), err),
+					})
+					continue
+				}
+				choice = tierChoice
+			}
+			orch := orchFor(choice)
+			if err := waitForBudgetReset(ctx, cfg, budgetMgr, choice.name, shutdown, log); err != nil {
+				return err
+			}
+			if shutdown.Draining() {
+				log.Infof("draining: not starting task %s", scoredTask.Definition.Type)
+				break
+			}
+			if reason := overdraftReason(budgetMgr, choice.name, selector.ReservedTokens(scoredTask.Definition), log); reason != "" {
+				report.addTask(overdraftResult(projectPath, scoredTask, reason))
+				continue
+			}
 
 			tasksRun++
 			projectTaskTypes = append(projectTaskTypes, string(scoredTask.Definition.Type))
@@ -378,11 +499,47 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 			// Mark as assigned
 			st.MarkAssigned(taskInstance.ID, projectPath, string(scoredTask.Definition.Type))
 
+			workBranch, err := cfg.WorkBranchName(projectPath, string(scoredTask.Definition.Type), choice.name, projectStart)
+			if err != nil {
+				log.Warnf("branch template: %v", err)
+			}
+			orch.SetRunMetadata(&orchestrator.RunMetadata{
+				Provider:   choice.name,
+				Model:      cfg.GetTaskModel(string(scoredTask.Definition.Type), choice.name),
+				TaskType:   string(scoredTask.Definition.Type),
+				TaskScore:  scoredTask.Score,
+				CostTier:   scoredTask.Definition.CostTier.String(),
+				RunStart:   projectStart,
+				WorkBranch: workBranch,
+				PRDraft:    cfg.Orchestrator.PR.Draft,
+				PRBase:     cfg.Orchestrator.PR.TargetBranch,
+				Forge:      cfg.GetForge(),
+				DenyPaths:  cfg.DenyPathsFor(projectPath),
+				ReadOnly:   cfg.ReadOnlyProject(projectPath),
+			})
+			orch.SetTokenCap(taskTokenCap(cfg, scoredTask.Definition, 0))
+
 			// Execute via orchestrator
+			sample := meters.start(choice.name)
+			taskStart := time.Now()
 			result, err := orch.RunTask(ctx, taskInstance, projectPath)
 
 			// Clear assignment
 			st.ClearAssigned(taskInstance.ID)
+			recordTaskResult(ctx, st, projectPath, scoredTask.Definition.Type, result, err)
+
+			// Charge what the provider actually counted; without a reading,
+			// completed and partial tasks fall back to their estimate and
+			// failed ones to 0.
+			_, maxTok := scoredTask.Definition.EstimatedTokens()
+			estimate := 0
+			if err == nil && (result.Status == orchestrator.StatusCompleted || result.Status == orchestrator.StatusPartial) {
+				estimate = maxTok
+			}
+			tokensUsed, measured := sample.used(estimate)
+			projectTokensUsed += tokensUsed
+			log.Infof("task %s used %d tokens (measured=%t)", taskInstance.ID, tokensUsed, measured)
+			recordTaskRun(st, projectPath, scoredTask.Definition.Type, choice.name, taskStart, tokensUsed, result, err)
 
 			if err != nil {
 				tasksFailed++
@@ -394,8 +551,12 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 						TaskType:   string(scoredTask.Definition.Type),
 						Title:      scoredTask.Definition.Name,
 						Status:     "failed",
-						TokensUsed: 0,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 				continue
@@ -412,8 +573,6 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 					"iterations": result.Iterations,
 					"duration":   result.Duration.String(),
 				})
-				_, maxTok := scoredTask.Definition.EstimatedTokens()
-				projectTokensUsed += maxTok
 				if report != nil {
 					report.addTask(reporting.TaskResult{
 						Project:    projectPath,
@@ -422,8 +581,34 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 						Status:     "completed",
 						OutputType: result.OutputType,
 						OutputRef:  result.OutputRef,
-						TokensUsed: maxTok,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
+					})
+				}
+			case orchestrator.StatusPartial:
+				tasksPartial++
+				// The PR/MR is open for review, so let the cooldown apply
+				st.RecordTaskRun(projectPath, string(scoredTask.Definition.Type))
+				log.Warnf("task %s partially completed (%s %s): %s", taskInstance.ID, result.OutputType, result.OutputRef, result.Error)
+				if report != nil {
+					report.addTask(reporting.TaskResult{
+						Project:    projectPath,
+						TaskType:   string(scoredTask.Definition.Type),
+						Title:      scoredTask.Definition.Name,
+						Status:     "partial",
+						OutputType: result.OutputType,
+						OutputRef:  result.OutputRef,
+						SkipReason: result.Error,
+						TokensUsed: tokensUsed,
+						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 			case orchestrator.StatusAbandoned:
@@ -437,7 +622,12 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 						Title:      scoredTask.Definition.Name,
 						Status:     "failed",
 						SkipReason: result.Error,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 			default:
@@ -446,12 +636,18 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 				log.Errorf("task %s failed: %s", taskInstance.ID, result.Error)
 				if report != nil {
 					report.addTask(reporting.TaskResult{
-						Project:    projectPath,
-						TaskType:   string(scoredTask.Definition.Type),
-						Title:      scoredTask.Definition.Name,
-						Status:     "failed",
-						SkipReason: result.Error,
-						Duration:   result.Duration,
+						Project:     projectPath,
+						TaskType:    string(scoredTask.Definition.Type),
+						Title:       scoredTask.Definition.Name,
+						Status:      "failed",
+						SkipReason:  result.Error,
+						TokensUsed:  tokensUsed,
+						Duration:    result.Duration,
+						Branch:      result.Branch,
+						Commits:     reportCommits(result.Commits),
+						EventLog:    result.EventLog,
+						Provider:    choice.name,
+						DeniedPaths: result.DeniedPaths,
 					})
 				}
 			}
@@ -483,6 +679,7 @@ func runScheduledTasks(ctx context.Context, cfg *config.Config, database *db.DB,
 		"duration":  duration.String(),
 		"tasks_run": tasksRun,
 		"completed": tasksCompleted,
+		"partial":   tasksPartial,
 		"failed":    tasksFailed,
 		"projects":  len(projects),
 	})
@@ -516,15 +713,23 @@ func startSnapshotLoop(ctx context.Context, cfg *config.Config, database *db.DB,
 	}
 
 	go func() {
-		takeSnapshot(ctx, cfg, database, log)
-		ticker := time.NewTicker(interval)
+		pacer := newSnapshotPacer(interval)
+		ticker := time.NewTicker(pacer.checkInterval())
 		defer ticker.Stop()
 		for {
+			now := time.Now()
+			if ok, missed := pacer.due(now); ok {
+				if missed > 0 {
+					log.Infof("snapshot catch-up: %d interval(s) missed since %s", missed, pacer.last.Format(time.RFC3339))
+				}
+				takeSnapshot(ctx, cfg, database, log)
+				recalibrate(cfg, database, log, now)
+				pacer.taken(now)
+			}
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				takeSnapshot(ctx, cfg, database, log)
 			}
 		}
 	}()
@@ -553,12 +758,13 @@ func takeSnapshot(ctx context.Context, cfg *config.Config, database *db.DB, log
 
 	collector := snapshots.NewCollector(
 		database,
-		providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude")),
-		providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex")),
+		newClaudeProvider(cfg),
+		newCodexProvider(cfg),
 		providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot")),
 		scraper,
 		weekStartDayFromConfig(cfg),
 	)
+	collector.SetUsageTimeout(cfg.GetUsageTimeout())
 
 	if cfg.Providers.Claude.Enabled {
 		snapshot, err := collector.TakeSnapshot(ctx, "claude")
@@ -583,6 +789,34 @@ func takeSnapshot(ctx context.Context, cfg *config.Config, database *db.DB, log
 	}
 }
 
+// recalibrate refreshes the stored Codex/Claude token multiplier from the
+// recent sessions of both providers.
+func recalibrate(cfg *config.Config, database *db.DB, log *logging.Logger, now time.Time) {
+	if !cfg.Providers.Claude.Enabled || !cfg.Providers.Codex.Enabled {
+		return
+	}
+	update, err := calibrator.New(database, cfg).RecalibrateRatio(now)
+	if err != nil {
+		log.Warnf("token ratio: %v", err)
+		return
+	}
+	if !update.Updated {
+		log.Debugf("token ratio: kept (%d codex, %d claude sessions; %d each needed)",
+			update.Current.CodexSessions, update.Current.ClaudeSessions, calibrator.MinRatioSessions)
+		return
+	}
+	if !update.Significant {
+		return
+	}
+	if update.Previous == 0 {
+		log.Infof("token ratio: codex %.2fx claude (%d codex, %d claude sessions)",
+			update.Current.Ratio, update.Current.CodexSessions, update.Current.ClaudeSessions)
+		return
+	}
+	log.Infof("token ratio: codex %.2fx -> %.2fx claude (%d codex, %d claude sessions)",
+		update.Previous, update.Current.Ratio, update.Current.CodexSessions, update.Current.ClaudeSessions)
+}
+
 func pruneSnapshots(ctx context.Context, cfg *config.Config, database *db.DB, log *logging.Logger) {
 	collector := snapshots.NewCollector(database, nil, nil, nil, nil, weekStartDayFromConfig(cfg))
 	deleted, err := collector.Prune(cfg.Budget.SnapshotRetentionDays)
@@ -630,10 +864,14 @@ func runDaemonStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("sending SIGTERM: %w", err)
 	}
 
-	fmt.Printf("stopping daemon (pid %d)...\n", pid)
+	fmt.Printf("stopping daemon (pid %d), waiting for current task to finish...\n", pid)
 
-	// Wait for process to exit (with timeout)
-	timeout := time.After(10 * time.Second)
+	// Wait for process to exit; allow the drain grace period before SIGKILL
+	wait := 10 * time.Second
+	if cfg, err := config.Load(); err == nil {
+		wait += cfg.GetShutdownGrace()
+	}
+	timeout := time.After(wait)
 	tick := time.NewTicker(100 * time.Millisecond)
 	defer tick.Stop()
 
@@ -656,23 +894,27 @@ func runDaemonStop(cmd *cobra.Command, args []string) error {
 }
 
 func runDaemonStatus(cmd *cobra.Command, args []string) error {
+	if daemonStatusNext < 0 {
+		return fmt.Errorf("--next must be >= 0")
+	}
+
 	running, pid := isDaemonRunning()
 
-	if !running {
+	if running {
+		fmt.Printf("Status: running\n")
+		fmt.Printf("PID: %d\n", pid)
+	} else {
 		fmt.Println("Status: not running")
-		return nil
+		if daemonStatusNext == 0 {
+			return nil
+		}
 	}
 
-	fmt.Printf("Status: running\n")
-	fmt.Printf("PID: %d\n", pid)
-
-	// Try to load config and show next run time
+	// Try to load config and show the schedule
 	cfg, err := config.Load()
 	if err == nil && (cfg.Schedule.Cron != "" || cfg.Schedule.Interval != "") {
 		sched, err := scheduler.NewFromConfig(&cfg.Schedule)
 		if err == nil {
-			// We need to start the scheduler briefly to calculate next run
-			// Instead, just show the schedule config
 			if cfg.Schedule.Cron != "" {
 				fmt.Printf("Schedule: cron %s\n", cfg.Schedule.Cron)
 			} else if cfg.Schedule.Interval != "" {
@@ -685,12 +927,41 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 				}
 				fmt.Println()
 			}
-			_ = sched // satisfy compiler
+			if daemonStatusNext > 0 {
+				runs, err := sched.NextRuns(daemonStatusNext)
+				if err != nil {
+					return fmt.Errorf("compute next runs: %w", err)
+				}
+				printNextRuns(os.Stdout, runs, daemonStatusNext, time.Now())
+			}
+		} else if daemonStatusNext > 0 {
+			return fmt.Errorf("schedule config: %w", err)
 		}
+	} else if daemonStatusNext > 0 {
+		fmt.Println("Next runs: no schedule configured")
 	}
 
-	// Show PID file path for reference
-	fmt.Printf("PID file: %s\n", pidFilePath())
+	if running {
+		// Show PID file path for reference
+		fmt.Printf("PID file: %s\n", pidFilePath())
+	}
 
 	return nil
 }
+
+// printNextRuns lists upcoming run times with the time remaining until each.
+// want is the number requested; fewer runs means the rest fall outside the
+// window or beyond the search horizon.
+func printNextRuns(w io.Writer, runs []time.Time, want int, now time.Time) {
+	if len(runs) == 0 {
+		_, _ = fmt.Fprintln(w, "Next runs: none (no scheduled time falls inside the window)")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Upcoming runs (%s):\n", runs[0].Location())
+	for i, run := range runs {
+		_, _ = fmt.Fprintf(w, "  %2d. %s  (in %s)\n", i+1, run.Format("Mon 2006-01-02 15:04 MST"), formatDuration(run.Sub(now)))
+	}
+	if len(runs) < want {
+		_, _ = fmt.Fprintf(w, "  (only %d of %d runs fall inside the window within a year)\n", len(runs), want)
+	}
+}
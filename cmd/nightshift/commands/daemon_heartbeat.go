@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/scheduler"
+)
+
+const (
+	heartbeatFileName = "heartbeat.json"
+	heartbeatInterval = time.Minute
+)
+
+// daemonHeartbeat is written periodically by the daemon so external
+// watchdogs (and `status --daemon`) can tell it is alive.
+type daemonHeartbeat struct {
+	PID           int       `json:"pid"`
+	LastTick      time.Time `json:"last_tick"`
+	LastRun       time.Time `json:"last_run"`
+	LastRunStatus string    `json:"last_run_status,omitempty"` // success | failed
+	LastRunError  string    `json:"last_run_error,omitempty"`
+	NextRun       time.Time `json:"next_run"`
+}
+
+// heartbeatFilePath returns the path to the daemon heartbeat file.
+func heartbeatFilePath() string {
+	return filepath.Join(filepath.Dir(pidFilePath()), heartbeatFileName)
+}
+
+// writeHeartbeat atomically writes hb to path.
+func writeHeartbeat(path string, hb daemonHeartbeat) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating heartbeat dir: %w", err)
+	}
+	payload, err := json.MarshalIndent(hb, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding heartbeat: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0644); err != nil {
+		return fmt.Errorf("writing heartbeat: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing heartbeat: %w", err)
+	}
+	return nil
+}
+
+// readHeartbeat reads the heartbeat file at path.
+func readHeartbeat(path string) (*daemonHeartbeat, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hb daemonHeartbeat
+	if err := json.Unmarshal(payload, &hb); err != nil {
+		return nil, fmt.Errorf("decoding heartbeat: %w", err)
+	}
+	return &hb, nil
+}
+
+// heartbeatWriter tracks daemon liveness and run outcomes and persists them.
+type heartbeatWriter struct {
+	mu    sync.Mutex
+	path  string
+	sched *scheduler.Scheduler
+	log   *logging.Logger
+	hb    daemonHeartbeat
+}
+
+func newHeartbeatWriter(path string, sched *scheduler.Scheduler, log *logging.Logger) *heartbeatWriter {
+	return &heartbeatWriter{
+		path:  path,
+		sched: sched,
+		log:   log,
+		hb:    daemonHeartbeat{PID: os.Getpid()},
+	}
+}
+
+// tick refreshes last-tick and next-run and writes the file.
+func (w *heartbeatWriter) tick() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hb.LastTick = time.Now()
+	if runs, err := w.sched.NextRuns(1); err == nil && len(runs) > 0 {
+		w.hb.NextRun = runs[0]
+	}
+	if err := writeHeartbeat(w.path, w.hb); err != nil {
+		w.log.Warnf("heartbeat: %v", err)
+	}
+}
+
+// recordRun stores the outcome of a scheduled run and writes the file.
+func (w *heartbeatWriter) recordRun(at time.Time, runErr error) {
+	w.mu.Lock()
+	w.hb.LastRun = at
+	w.hb.LastRunStatus = "success"
+	w.hb.LastRunError = ""
+	if runErr != nil {
+		w.hb.LastRunStatus = "failed"
+		w.hb.LastRunError = runErr.Error()
+	}
+	w.mu.Unlock()
+	w.tick()
+}
+
+// start writes a heartbeat immediately and then every heartbeatInterval
+// until ctx is cancelled.
+func (w *heartbeatWriter) start(ctx context.Context) {
+	w.tick()
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.tick()
+			}
+		}
+	}()
+}
+
+// heartbeatStaleAfter returns how old a heartbeat may be before it is
+// considered stale: the schedule interval, or the gap between the next two
+// cron runs. Never less than twice the heartbeat interval.
+func heartbeatStaleAfter(cfg *config.Config) time.Duration {
+	staleAfter := 24 * time.Hour
+	if cfg != nil {
+		if d, err := time.ParseDuration(cfg.Schedule.Interval); err == nil && d > 0 {
+			staleAfter = d
+		} else if sched, err := scheduler.NewFromConfig(&cfg.Schedule); err == nil {
+			if runs, err := sched.NextRuns(2); err == nil && len(runs) == 2 {
+				staleAfter = runs[1].Sub(runs[0])
+			}
+		}
+	}
+	if staleAfter < 2*heartbeatInterval {
+		staleAfter = 2 * heartbeatInterval
+	}
+	return staleAfter
+}
+
+// showDaemonStatus prints daemon liveness from the pid file and heartbeat.
+func showDaemonStatus(cfg *config.Config, now time.Time) error {
+	running, pid := isDaemonRunning()
+	if running {
+		fmt.Printf("Daemon:    running (pid %d)\n", pid)
+	} else {
+		fmt.Println("Daemon:    not running")
+	}
+
+	hb, err := readHeartbeat(heartbeatFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("Heartbeat: none recorded")
+			return nil
+		}
+		return err
+	}
+
+	age := now.Sub(hb.LastTick)
+	staleAfter := heartbeatStaleAfter(cfg)
+	tickLine := fmt.Sprintf("%s (%s ago)", hb.LastTick.Format("2006-01-02 15:04:05"), formatDuration(age))
+	if age > staleAfter {
+		tickLine += fmt.Sprintf(" STALE (> %s)", staleAfter)
+	}
+	fmt.Printf("Last tick: %s\n", tickLine)
+
+	if hb.LastRun.IsZero() {
+		fmt.Println("Last run:  never")
+	} else {
+		status := formatStatus(hb.LastRunStatus)
+		if hb.LastRunError != "" {
+			status += ": " + hb.LastRunError
+		}
+		fmt.Printf("Last run:  %s (%s)\n", hb.LastRun.Format("2006-01-02 15:04"), status)
+	}
+	if !hb.NextRun.IsZero() {
+		fmt.Printf("Next run:  %s\n", hb.NextRun.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
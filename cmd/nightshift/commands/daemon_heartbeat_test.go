@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/scheduler"
+)
+
+func TestHeartbeatWriter_RecordRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), heartbeatFileName)
+	sched, err := scheduler.NewFromConfig(&config.ScheduleConfig{Interval: "1h"})
+	if err != nil {
+		t.Fatalf("NewFromConfig: %v", err)
+	}
+	w := newHeartbeatWriter(path, sched, logging.Component("test"))
+
+	runAt := time.Now().Add(-time.Minute)
+	w.recordRun(runAt, errors.New("boom"))
+
+	hb, err := readHeartbeat(path)
+	if err != nil {
+		t.Fatalf("readHeartbeat: %v", err)
+	}
+	if hb.LastTick.IsZero() {
+		t.Error("LastTick not set")
+	}
+	if !hb.LastRun.Equal(runAt) {
+		t.Errorf("LastRun = %v, want %v", hb.LastRun, runAt)
+	}
+	if hb.LastRunStatus != "failed" || hb.LastRunError != "boom" {
+		t.Errorf("status = %q/%q, want failed/boom", hb.LastRunStatus, hb.LastRunError)
+	}
+	if !hb.NextRun.After(hb.LastTick) {
+		t.Errorf("NextRun = %v, want after LastTick %v", hb.NextRun, hb.LastTick)
+	}
+
+	w.recordRun(time.Now(), nil)
+	hb, _ = readHeartbeat(path)
+	if hb.LastRunStatus != "success" || hb.LastRunError != "" {
+		t.Errorf("status = %q/%q, want success with no error", hb.LastRunStatus, hb.LastRunError)
+	}
+}
+
+func TestHeartbeatStaleAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want time.Duration
+	}{
+		{name: "nil config", cfg: nil, want: 24 * time.Hour},
+		{name: "interval", cfg: &config.Config{Schedule: config.ScheduleConfig{Interval: "6h"}}, want: 6 * time.Hour},
+		{name: "hourly cron", cfg: &config.Config{Schedule: config.ScheduleConfig{Cron: "0 * * * *"}}, want: time.Hour},
+		{name: "floor", cfg: &config.Config{Schedule: config.ScheduleConfig{Interval: "10s"}}, want: 2 * heartbeatInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := heartbeatStaleAfter(tt.cfg); got != tt.want {
+				t.Errorf("heartbeatStaleAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
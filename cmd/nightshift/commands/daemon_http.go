@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/scheduler"
+)
+
+const (
+	// statusServerShutdownTimeout bounds how long in-flight requests may
+	// take once the daemon stops.
+	statusServerShutdownTimeout = 5 * time.Second
+	// statusServerMaxNext caps /next?n=.
+	statusServerMaxNext = 100
+)
+
+// statusServer serves the daemon's health and status over HTTP when
+// daemon.http_addr is set.
+type statusServer struct {
+	cfg      *config.Config
+	database *db.DB
+	sched    *scheduler.Scheduler
+	shutdown *gracefulShutdown
+	log      *logging.Logger
+	now      func() time.Time
+
+	srv *http.Server
+	ln  net.Listener
+}
+
+func newStatusServer(cfg *config.Config, database *db.DB, sched *scheduler.Scheduler, shutdown *gracefulShutdown, log *logging.Logger) *statusServer {
+	return &statusServer{
+		cfg:      cfg,
+		database: database,
+		sched:    sched,
+		shutdown: shutdown,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// handler routes /healthz, /status and /next.
+func (s *statusServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /next", s.handleNext)
+	return mux
+}
+
+// start listens on addr and serves in the background until stop is called.
+func (s *statusServer) start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("status server: %w", err)
+	}
+	s.ln = ln
+	s.srv = &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Errorf("status server: %v", err)
+		}
+	}()
+	s.log.Infof("status server listening on http://%s", ln.Addr())
+	return nil
+}
+
+// addr returns the address the server is listening on.
+func (s *statusServer) addr() string {
+	if s.ln == nil {
+		return ""
+	}
+	return s.ln.Addr().String()
+}
+
+// stop shuts the server down, letting in-flight requests finish for up to
+// statusServerShutdownTimeout. It is a no-op if the server never started.
+func (s *statusServer) stop() {
+	if s == nil || s.srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), statusServerShutdownTimeout)
+	defer cancel()
+	if err := s.srv.Shutdown(ctx); err != nil {
+		s.log.Warnf("status server shutdown: %v", err)
+	}
+}
+
+// handleHealthz reports ok while the daemon is running, and 503 once it
+// has started draining.
+func (s *statusServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if s.shutdown.Draining() {
+		writeStatusResponse(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		return
+	}
+	writeStatusResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleStatus serves the status --json document. ?last=N sets how many
+// recent runs are included (default 5).
+func (s *statusServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	last, err := queryInt(r, "last", 5, 0, 1000)
+	if err != nil {
+		writeStatusResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	out, err := collectStatus(s.cfg, s.database, last, s.now())
+	if err != nil {
+		s.log.Warnf("status server: %v", err)
+		writeStatusResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeStatusResponse(w, http.StatusOK, out)
+}
+
+// handleNext lists upcoming scheduled run times. ?n=N sets how many
+// (default 5, at most statusServerMaxNext).
+func (s *statusServer) handleNext(w http.ResponseWriter, r *http.Request) {
+	n, err := queryInt(r, "n", 5, 1, statusServerMaxNext)
+	if err != nil {
+		writeStatusResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	runs, err := s.sched.NextRuns(n)
+	if err != nil {
+		writeStatusResponse(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []time.Time{}
+	}
+	writeStatusResponse(w, http.StatusOK, struct {
+		GeneratedAt time.Time   `json:"generated_at"`
+		NextRuns    []time.Time `json:"next_runs"`
+	}{s.now(), runs})
+}
+
+// queryInt reads an integer query parameter within [lo, hi], or def when
+// it is absent.
+func queryInt(r *http.Request, name string, def, lo, hi int) (int, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be a number from %d to %d", name, lo, hi)
+	}
+	return n, nil
+}
+
+func writeStatusResponse(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
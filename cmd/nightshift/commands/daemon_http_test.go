@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/scheduler"
+)
+
+func TestStatusServer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := &config.Config{
+		Budget:   config.BudgetConfig{DBPath: filepath.Join(home, "nightshift.db")},
+		Schedule: config.ScheduleConfig{Interval: "1h"},
+		Daemon:   config.DaemonConfig{HTTPAddr: "127.0.0.1:0"},
+	}
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = database.Close() }()
+	sched, err := scheduler.NewFromConfig(&cfg.Schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shutdown := newGracefulShutdown(func() {}, time.Minute, logging.Component("test"))
+	srv := newStatusServer(cfg, database, sched, shutdown, logging.Component("test"))
+	if err := srv.start(cfg.DaemonHTTPAddr()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer srv.stop()
+	base := "http://" + srv.addr()
+
+	get := func(path string, wantCode int, v any) {
+		t.Helper()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != wantCode {
+			t.Fatalf("GET %s = %d, want %d", path, resp.StatusCode, wantCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("GET %s Content-Type = %q", path, ct)
+		}
+		if v != nil {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("GET %s: decode: %v", path, err)
+			}
+		}
+	}
+
+	var health map[string]string
+	get("/healthz", http.StatusOK, &health)
+	if health["status"] != "ok" {
+		t.Errorf("/healthz = %v, want ok", health)
+	}
+
+	var status statusJSON
+	get("/status?last=2", http.StatusOK, &status)
+	if status.SchemaVersion != statusJSONSchemaVersion || !status.Schedule.Configured || status.Schedule.Interval != "1h" {
+		t.Errorf("/status = %+v, want the status --json document", status)
+	}
+	get("/status?last=x", http.StatusBadRequest, nil)
+
+	var next struct {
+		NextRuns []time.Time `json:"next_runs"`
+	}
+	get("/next?n=3", http.StatusOK, &next)
+	if len(next.NextRuns) != 3 || !next.NextRuns[1].After(next.NextRuns[0]) {
+		t.Errorf("/next = %v, want 3 increasing times", next.NextRuns)
+	}
+	get("/next?n=0", http.StatusBadRequest, nil)
+
+	resp, err := http.Post(base+"/healthz", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /healthz = %d, want 405", resp.StatusCode)
+	}
+
+	shutdown.startDrain(nil)
+	get("/healthz", http.StatusServiceUnavailable, &health)
+	if health["status"] != "draining" {
+		t.Errorf("/healthz while draining = %v", health)
+	}
+
+	srv.stop()
+	if _, err := http.Get(base + "/healthz"); err == nil {
+		t.Error("server still answering after stop")
+	}
+}
@@ -0,0 +1,45 @@
+package commands
+
+import "time"
+
+// snapshotCheckInterval caps how often the snapshot loop compares the wall
+// clock against the last snapshot, bounding how late a post-sleep catch-up
+// can be.
+const snapshotCheckInterval = time.Minute
+
+// snapshotPacer decides when the daemon's next usage snapshot is due. Go
+// timers run on the monotonic clock, which stops while the machine sleeps,
+// so a plain ticker resumes a full interval after wake. The pacer compares
+// wall-clock time instead and flags the gap.
+type snapshotPacer struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func newSnapshotPacer(interval time.Duration) *snapshotPacer {
+	return &snapshotPacer{interval: interval}
+}
+
+// due reports whether a snapshot should be taken at now. missed counts the
+// whole intervals skipped since the last snapshot; it is non-zero only after
+// a gap such as sleep.
+func (p *snapshotPacer) due(now time.Time) (ok bool, missed int) {
+	if p.last.IsZero() {
+		return true, 0
+	}
+	gap := now.Round(0).Sub(p.last.Round(0))
+	if gap < p.interval {
+		return false, 0
+	}
+	return true, int(gap/p.interval) - 1
+}
+
+// taken records a snapshot at now.
+func (p *snapshotPacer) taken(now time.Time) {
+	p.last = now.Round(0)
+}
+
+// checkInterval returns how often the loop should call due.
+func (p *snapshotPacer) checkInterval() time.Duration {
+	return min(p.interval, snapshotCheckInterval)
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotPacer_ClockJump(t *testing.T) {
+	p := newSnapshotPacer(30 * time.Minute)
+	t0 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name       string
+		at         time.Time
+		wantDue    bool
+		wantMissed int
+	}{
+		{name: "first check", at: t0, wantDue: true},
+		{name: "mid interval", at: t0.Add(10 * time.Minute), wantDue: false},
+		{name: "on schedule", at: t0.Add(30 * time.Minute), wantDue: true},
+		// Laptop sleeps for two hours: the wall clock jumps on wake
+		{name: "wake after sleep", at: t0.Add(30*time.Minute + 2*time.Hour + time.Minute), wantDue: true, wantMissed: 3},
+		{name: "back to normal", at: t0.Add(30*time.Minute + 2*time.Hour + 2*time.Minute), wantDue: false},
+	}
+	for _, step := range steps {
+		ok, missed := p.due(step.at)
+		if ok != step.wantDue || missed != step.wantMissed {
+			t.Fatalf("%s: due() = (%v, %d), want (%v, %d)", step.name, ok, missed, step.wantDue, step.wantMissed)
+		}
+		if ok {
+			p.taken(step.at)
+		}
+	}
+}
+
+func TestSnapshotPacer_WallClockIgnoresMonotonic(t *testing.T) {
+	p := newSnapshotPacer(30 * time.Minute)
+	start := time.Now()
+	p.taken(start)
+
+	// A wall-clock time an hour later with no monotonic reading, as seen
+	// after suspend when the monotonic clock did not advance
+	woke := start.Round(0).Add(time.Hour)
+	if ok, missed := p.due(woke); !ok || missed != 1 {
+		t.Errorf("due() = (%v, %d), want (true, 1)", ok, missed)
+	}
+
+	if got := p.checkInterval(); got != snapshotCheckInterval {
+		t.Errorf("checkInterval() = %v, want %v", got, snapshotCheckInterval)
+	}
+	if got := newSnapshotPacer(20 * time.Second).checkInterval(); got != 20*time.Second {
+		t.Errorf("checkInterval() = %v, want 20s", got)
+	}
+}
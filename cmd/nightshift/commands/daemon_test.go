@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintNextRuns(t *testing.T) {
+	loc := time.FixedZone("MST", -7*3600)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, loc)
+	runs := []time.Time{
+		time.Date(2026, 3, 2, 22, 0, 0, 0, loc),
+		time.Date(2026, 3, 3, 22, 0, 0, 0, loc),
+	}
+
+	tests := []struct {
+		name    string
+		runs    []time.Time
+		want    int
+		expects []string
+		rejects []string
+	}{
+		{
+			name:    "all runs",
+			runs:    runs,
+			want:    2,
+			expects: []string{"Upcoming runs (MST):", "1. Mon 2026-03-02 22:00 MST", "2. Tue 2026-03-03 22:00 MST", "(in 10h"},
+			rejects: []string{"only"},
+		},
+		{
+			name:    "short of request",
+			runs:    runs[:1],
+			want:    3,
+			expects: []string{"only 1 of 3 runs"},
+		},
+		{
+			name:    "none",
+			runs:    nil,
+			want:    3,
+			expects: []string{"Next runs: none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printNextRuns(&buf, tt.runs, tt.want, now)
+			out := buf.String()
+			for _, e := range tt.expects {
+				if !strings.Contains(out, e) {
+					t.Errorf("output missing %q:\n%s", e, out)
+				}
+			}
+			for _, r := range tt.rejects {
+				if strings.Contains(out, r) {
+					t.Errorf("output unexpectedly contains %q:\n%s", r, out)
+				}
+			}
+		})
+	}
+}
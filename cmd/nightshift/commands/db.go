@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/db"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Inspect and migrate the state database",
+	Long: `Inspect and migrate the SQLite database that holds run state, snapshots
+and budget reservations.
+
+Every command that opens the database applies pending migrations on its
+own. These commands show and control that step explicitly, e.g. after an
+upgrade or when a migration failed and needs to be run again.`,
+}
+
+var dbInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show schema version, path, size and row counts",
+	Long: `Show the database path and size, the schema version against the latest
+this build knows, applied and pending migrations, and the row count of
+every table. Nothing is migrated or changed.
+
+Examples:
+  nightshift db info
+  nightshift db info --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		database, err := openDBUnmigrated(cmd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = database.Close() }()
+		return runDBInfo(os.Stdout, database, asJSON)
+	},
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply pending schema migrations. Running it on an up-to-date database
+does nothing. A migration that fails is rolled back, so running the command
+again retries it.
+
+Before migrating, the database is copied to --backup-path (default:
+<db>.v<version>-<time>.bak next to it). Use --backup=false to skip the copy.
+
+Examples:
+  nightshift db migrate
+  nightshift db migrate --backup-path ~/nightshift-before-upgrade.db
+  nightshift db migrate --backup=false`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backup, _ := cmd.Flags().GetBool("backup")
+		backupPath, _ := cmd.Flags().GetString("backup-path")
+		if backupPath != "" && !backup {
+			return fmt.Errorf("--backup-path requires --backup")
+		}
+		database, err := openDBUnmigrated(cmd)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = database.Close() }()
+		return runDBMigrate(os.Stdout, database, backup, backupPath, time.Now())
+	},
+}
+
+func init() {
+	dbCmd.PersistentFlags().String("db", "", "Database path (uses config if not set)")
+
+	dbInfoCmd.Flags().Bool("json", false, "Output as JSON")
+	dbCmd.AddCommand(dbInfoCmd)
+
+	dbMigrateCmd.Flags().Bool("backup", true, "Copy the database before migrating")
+	dbMigrateCmd.Flags().String("backup-path", "", "Where to write the backup (default: <db>.v<version>-<time>.bak)")
+	dbCmd.AddCommand(dbMigrateCmd)
+
+	rootCmd.AddCommand(dbCmd)
+}
+
+// openDBUnmigrated opens the --db path, or the configured one, without
+// migrating it. A missing file is an error rather than a new database.
+func openDBUnmigrated(cmd *cobra.Command) (*db.DB, error) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	if dbPath == "" {
+		cfg, err := loadConfig("")
+		if err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		dbPath = cfg.ExpandedDBPath()
+	}
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no database at %s (it is created on first use)", dbPath)
+	}
+	database, err := db.OpenUnmigrated(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening db: %w", err)
+	}
+	return database, nil
+}
+
+// runDBInfo prints the database's schema and table summary.
+func runDBInfo(w io.Writer, database *db.DB, asJSON bool) error {
+	info, err := database.Info()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	_, _ = fmt.Fprintf(w, "Path:    %s\n", info.Path)
+	_, _ = fmt.Fprintf(w, "Size:    %s\n", formatBytes(info.Size))
+	switch {
+	case len(info.Pending) > 0:
+		_, _ = fmt.Fprintf(w, "Schema:  v%d (latest v%d, %d pending; run nightshift db migrate)\n", info.Version, info.Latest, len(info.Pending))
+	case info.Version > info.Latest:
+		_, _ = fmt.Fprintf(w, "Schema:  v%d (newer than this build's v%d; upgrade nightshift)\n", info.Version, info.Latest)
+	default:
+		_, _ = fmt.Fprintf(w, "Schema:  v%d (up to date)\n", info.Version)
+	}
+
+	if len(info.Applied) > 0 {
+		_, _ = fmt.Fprintln(w, "\nApplied:")
+		for _, m := range info.Applied {
+			at := "unknown"
+			if !m.AppliedAt.IsZero() {
+				at = m.AppliedAt.Local().Format("2006-01-02 15:04")
+			}
+			_, _ = fmt.Fprintf(w, "  v%-3d %s  %s\n", m.Version, at, m.Description)
+		}
+	}
+	if len(info.Pending) > 0 {
+		_, _ = fmt.Fprintln(w, "\nPending:")
+		for _, m := range info.Pending {
+			_, _ = fmt.Fprintf(w, "  v%-3d %s\n", m.Version, m.Description)
+		}
+	}
+
+	_, _ = fmt.Fprintln(w, "\nTables:")
+	width := 0
+	for _, tc := range info.Tables {
+		width = max(width, len(tc.Name))
+	}
+	for _, tc := range info.Tables {
+		_, _ = fmt.Fprintf(w, "  %-*s  %d\n", width, tc.Name, tc.Rows)
+	}
+	return nil
+}
+
+// runDBMigrate applies pending migrations, copying the database first when
+// backup is set and there is something to apply.
+func runDBMigrate(w io.Writer, database *db.DB, backup bool, backupPath string, now time.Time) error {
+	info, err := database.Info()
+	if err != nil {
+		return err
+	}
+	if len(info.Pending) == 0 {
+		_, _ = fmt.Fprintf(w, "Schema is up to date (v%d).\n", info.Version)
+		return nil
+	}
+
+	if backup {
+		if backupPath == "" {
+			backupPath = fmt.Sprintf("%s.v%d-%s.bak", database.Path(), info.Version, now.Format("20060102-150405"))
+		}
+		if err := database.Backup(backupPath); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "Backup:  %s\n", backupPath)
+	}
+
+	applied, err := database.Migrate()
+	for _, m := range applied {
+		_, _ = fmt.Fprintf(w, "Applied: v%d %s\n", m.Version, m.Description)
+	}
+	if err != nil {
+		if backup {
+			return fmt.Errorf("%w (backup at %s)", err, backupPath)
+		}
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "Schema is now v%d.\n", applied[len(applied)-1].Version)
+	return nil
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/db"
+)
+
+func TestRunDBMigrate(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "nightshift.db")
+	database, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+	latest := db.LatestVersion()
+	now := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := runDBMigrate(&buf, database, true, "", now); err != nil {
+		t.Fatalf("runDBMigrate: %v", err)
+	}
+	if want := fmt.Sprintf("Schema is up to date (v%d).", latest); !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+	if matches, _ := filepath.Glob(dbPath + ".v*.bak"); len(matches) != 0 {
+		t.Errorf("up-to-date migrate wrote a backup: %v", matches)
+	}
+
+	// Roll the last migration back, as if an older build made the file.
+	if _, err := database.SQL().Exec(`DROP TABLE task_results; DELETE FROM schema_version WHERE version = ?`, latest); err != nil {
+		t.Fatalf("roll back: %v", err)
+	}
+
+	buf.Reset()
+	if err := runDBInfo(&buf, database, false); err != nil {
+		t.Fatalf("runDBInfo: %v", err)
+	}
+	for _, want := range []string{
+		"Path:    " + dbPath,
+		fmt.Sprintf("Schema:  v%d (latest v%d, 1 pending; run nightshift db migrate)", latest-1, latest),
+		"Pending:",
+		"schema_version",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("info output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := runDBMigrate(&buf, database, true, "", now); err != nil {
+		t.Fatalf("runDBMigrate: %v", err)
+	}
+	backup := fmt.Sprintf("%s.v%d-20260301-093000.bak", dbPath, latest-1)
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("backup not written: %v", err)
+	}
+	for _, want := range []string{
+		"Backup:  " + backup,
+		fmt.Sprintf("Applied: v%d ", latest),
+		fmt.Sprintf("Schema is now v%d.", latest),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("migrate output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
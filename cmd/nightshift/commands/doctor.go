@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -12,11 +13,14 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/marcus/nightshift/internal/agents"
 	"github.com/marcus/nightshift/internal/budget"
 	"github.com/marcus/nightshift/internal/calibrator"
 	"github.com/marcus/nightshift/internal/config"
 	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/logging"
 	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/reporting"
 	"github.com/marcus/nightshift/internal/scheduler"
 	"github.com/marcus/nightshift/internal/snapshots"
 	"github.com/marcus/nightshift/internal/state"
@@ -51,9 +55,6 @@ func init() {
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
-	// Augment PATH the same way 'run' does so CLI checks are accurate.
-	ensurePATH()
-
 	results := make([]checkResult, 0)
 	hasFail := false
 
@@ -72,6 +73,11 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	}
 	add("config", statusOK, "loaded")
 
+	// Augment PATH the same way 'run' does so CLI checks are accurate.
+	if missing := ensurePATH(cfg.ExpandedExtraPathDirs(), logging.Component("doctor")); len(missing) > 0 {
+		add("path", statusWarn, "extra_path_dirs not found: "+strings.Join(missing, ", "))
+	}
+
 	database, err := db.Open(cfg.ExpandedDBPath())
 	if err != nil {
 		add("db", statusFail, err.Error())
@@ -79,7 +85,11 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("db open failed")
 	}
 	defer func() { _ = database.Close() }()
-	add("db", statusOK, cfg.ExpandedDBPath())
+	if version, err := db.CurrentVersion(database.SQL()); err == nil {
+		add("db", statusOK, fmt.Sprintf("%s (schema v%d)", cfg.ExpandedDBPath(), version))
+	} else {
+		add("db", statusOK, cfg.ExpandedDBPath())
+	}
 
 	if _, err := state.New(database); err != nil {
 		add("state", statusFail, err.Error())
@@ -92,8 +102,10 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	checkDaemon(add)
 
 	checkCLIs(cfg, add)
+	checkSandboxDocker(cfg, add)
 	claudeProvider, codexProvider, copilotProvider := checkProviders(cfg, add)
 	checkBudget(cfg, database, claudeProvider, codexProvider, copilotProvider, add)
+	checkBudgetTuning(cfg, database, add)
 	checkSnapshots(cfg, database, add)
 	checkTmux(cfg, add)
 
@@ -106,6 +118,18 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 }
 
 func checkSchedule(cfg *config.Config, add func(string, checkStatus, string)) {
+	checkScheduleAt(cfg, time.Now(), time.Local, add)
+}
+
+// checkScheduleAt checks the schedule as of now on a machine in the local
+// time zone: that the window parses and ever opens, whether its time zone
+// differs from the machine's, and when the next run fires in both zones.
+func checkScheduleAt(cfg *config.Config, now time.Time, local *time.Location, add func(string, checkStatus, string)) {
+	window, ok := checkWindow(cfg.Schedule.Window, now, local, add)
+	if !ok {
+		return
+	}
+
 	sched, err := scheduler.NewFromConfig(&cfg.Schedule)
 	if err != nil {
 		if errors.Is(err, scheduler.ErrNoSchedule) {
@@ -115,12 +139,83 @@ func checkSchedule(cfg *config.Config, add func(string, checkStatus, string)) {
 		add("schedule", statusFail, err.Error())
 		return
 	}
-	nextRuns, err := sched.NextRuns(1)
-	if err != nil || len(nextRuns) == 0 {
+	nextRuns, err := sched.NextRunsFrom(now, 1)
+	if err != nil {
 		add("schedule", statusWarn, "unable to compute next run")
 		return
 	}
-	add("schedule", statusOK, fmt.Sprintf("next run %s", nextRuns[0].Format("2006-01-02 15:04")))
+	if len(nextRuns) == 0 {
+		// Only a cron that never lands inside the window gets here
+		add("schedule", statusFail, fmt.Sprintf("cron %q never fires inside the window %s, so every night is skipped", cfg.Schedule.Cron, window))
+		return
+	}
+	add("schedule", statusOK, "next run "+formatFireTime(nextRuns[0], window, local))
+}
+
+// checkWindow reports on schedule.window and returns it parsed, or nil when
+// none is set. ok is false when the window is unusable and the schedule
+// check should stop there.
+func checkWindow(cfg *config.WindowConfig, now time.Time, local *time.Location, add func(string, checkStatus, string)) (window *scheduler.Window, ok bool) {
+	if cfg == nil {
+		return nil, true
+	}
+	sched := scheduler.New()
+	if err := sched.SetWindow(cfg); err != nil {
+		add("window", statusFail, err.Error())
+		return nil, false
+	}
+	window = sched.Window()
+	if window.Start == window.End {
+		add("window", statusFail, fmt.Sprintf("%s never opens: start equals end (for an overnight window use e.g. 22:00-06:00)", window))
+		return window, false
+	}
+
+	span := "same day"
+	if window.Start.Minutes() > window.End.Minutes() {
+		span = "overnight"
+	}
+	add("window", statusOK, fmt.Sprintf("%s, %s, open %s", window, span, formatDuration(window.Length())))
+
+	// Compare offsets at the next opening, so zones that only share an
+	// offset part of the year are still caught around DST changes
+	opens := window.NextStart(now)
+	windowOffset := zoneLabel(opens, window.Location)
+	localOffset := zoneLabel(opens, local)
+	_, windowSecs := opens.In(window.Location).Zone()
+	_, localSecs := opens.In(local).Zone()
+	if windowSecs == localSecs {
+		add("timezone", statusOK, fmt.Sprintf("window and system both on %s", localOffset))
+		return window, true
+	}
+	closes := window.NextEnd(opens)
+	add("timezone", statusWarn, fmt.Sprintf("window is in %s but the system is on %s: %s-%s there is %s-%s here",
+		windowOffset, localOffset, window.Start, window.End,
+		opens.In(local).Format("15:04"), closes.In(local).Format("15:04")))
+	return window, true
+}
+
+// formatFireTime shows t in the window's time zone and, when the system's
+// offset differs, in local time too.
+func formatFireTime(t time.Time, window *scheduler.Window, local *time.Location) string {
+	if window == nil {
+		return t.In(local).Format("2006-01-02 15:04 MST")
+	}
+	inWindow := t.In(window.Location)
+	inLocal := t.In(local)
+	_, windowSecs := inWindow.Zone()
+	_, localSecs := inLocal.Zone()
+	if windowSecs == localSecs {
+		return inWindow.Format("2006-01-02 15:04 MST")
+	}
+	return fmt.Sprintf("%s (%s local)", inWindow.Format("2006-01-02 15:04 MST"), inLocal.Format("2006-01-02 15:04 MST"))
+}
+
+// zoneLabel names loc with its abbreviation and UTC offset at t, e.g.
+// "America/Denver (MDT, UTC-06:00)".
+func zoneLabel(t time.Time, loc *time.Location) string {
+	in := t.In(loc)
+	abbr, _ := in.Zone()
+	return fmt.Sprintf("%s (%s, UTC%s)", loc, abbr, in.Format("-07:00"))
 }
 
 func checkService(add func(string, checkStatus, string)) {
@@ -180,21 +275,73 @@ func checkDaemon(add func(string, checkStatus, string)) {
 
 func checkCLIs(cfg *config.Config, add func(string, checkStatus, string)) {
 	if cfg.Providers.Claude.Enabled {
-		if path, err := exec.LookPath("claude"); err != nil {
-			add("claude.cli", statusFail, "claude not found in PATH")
+		binary := providerBinary(cfg, "claude")
+		if err := cfg.CheckProviderCommand("claude"); err != nil {
+			add("claude.cli", statusFail, err.Error())
+		} else if sandboxed(cfg) {
+			add("claude.cli", statusOK, binary+" (in sandbox image)")
+			checkAuth(newClaudeAgentFromConfig(cfg), add)
+		} else if path, err := exec.LookPath(binary); err != nil {
+			add("claude.cli", statusFail, binary+" not found in PATH")
 		} else {
 			add("claude.cli", statusOK, path)
+			checkAuth(newClaudeAgentFromConfig(cfg), add)
 		}
 	}
 	if cfg.Providers.Codex.Enabled {
-		if path, err := exec.LookPath("codex"); err != nil {
-			add("codex.cli", statusFail, "codex not found in PATH")
+		binary := providerBinary(cfg, "codex")
+		if err := cfg.CheckProviderCommand("codex"); err != nil {
+			add("codex.cli", statusFail, err.Error())
+		} else if sandboxed(cfg) {
+			add("codex.cli", statusOK, binary+" (in sandbox image)")
+			checkAuth(newCodexAgentFromConfig(cfg), add)
+		} else if path, err := exec.LookPath(binary); err != nil {
+			add("codex.cli", statusFail, binary+" not found in PATH")
 		} else {
 			add("codex.cli", statusOK, path)
+			checkAuth(newCodexAgentFromConfig(cfg), add)
+		}
+	}
+	// Copilot falls back between copilot and gh, so only a configured
+	// command is checked
+	if cfg.Providers.Copilot.Enabled && cfg.ProviderCommand("copilot") != "" {
+		if err := cfg.CheckProviderCommand("copilot"); err != nil {
+			add("copilot.cli", statusFail, err.Error())
+		} else {
+			add("copilot.cli", statusOK, providerBinary(cfg, "copilot"))
 		}
 	}
 }
 
+// checkSandboxDocker reports whether the docker CLI sandbox.type needs is
+// installed. Nothing is reported when the sandbox is off.
+func checkSandboxDocker(cfg *config.Config, add func(string, checkStatus, string)) {
+	r := sandboxRunner(cfg)
+	if r == nil {
+		return
+	}
+	if path, err := exec.LookPath(r.Binary); err != nil {
+		add("sandbox", statusFail, "sandbox.type is docker but docker was not found in PATH")
+	} else {
+		add("sandbox", statusOK, fmt.Sprintf("docker (%s), image %s", path, r.Image))
+	}
+}
+
+// checkAuth reports the agent's login probe. The probe is best-effort, so an
+// unknown result (e.g. credentials kept in the macOS keychain) isn't flagged.
+func checkAuth(agent agents.Agent, add func(string, checkStatus, string)) {
+	name := agent.Name() + ".auth"
+	auth := agents.CheckAuth(context.Background(), agent)
+	switch auth.State {
+	case agents.AuthOK:
+		add(name, statusOK, auth.Detail)
+	case agents.AuthExpired:
+		add(name, statusFail, fmt.Sprintf("%s (%s)", auth.Reason(), auth.Detail))
+	default:
+		add(name, statusOK, "unverified: "+auth.Detail)
+	}
+}
+
 func checkProviders(cfg *config.Config, add func(string, checkStatus, string)) (*providers.Claude, *providers.Codex, *providers.Copilot) {
 	var claudeProvider *providers.Claude
 	var codexProvider *providers.Codex
@@ -204,6 +351,10 @@ func checkProviders(cfg *config.Config, add func(string, checkStatus, string)) (
 	if mode == "" {
 		mode = config.DefaultBudgetMode
 	}
+	if mode == "auto" {
+		// Auto gates on both windows; the weekly figure is the broader check.
+		mode = "weekly"
+	}
 
 	if cfg.Providers.Claude.Enabled {
 		path := cfg.ExpandedProviderPath("claude")
@@ -212,7 +363,7 @@ func checkProviders(cfg *config.Config, add func(string, checkStatus, string)) (
 		} else {
 			add("claude.data_path", statusOK, path)
 		}
-		claudeProvider = providers.NewClaudeWithPath(path)
+		claudeProvider = newClaudeProvider(cfg)
 		if usage, err := claudeProvider.GetWeeklyUsage(); err == nil {
 			add("claude.weekly_tokens", statusOK, fmt.Sprintf("%d tokens", usage))
 		}
@@ -230,7 +381,7 @@ func checkProviders(cfg *config.Config, add func(string, checkStatus, string)) (
 		} else {
 			add("codex.data_path", statusOK, path)
 		}
-		codexProvider = providers.NewCodexWithPath(path)
+		codexProvider = newCodexProvider(cfg)
 		if pct, err := codexProvider.GetUsedPercent(mode, int64(cfg.GetProviderBudget("codex"))); err != nil {
 			add("codex.usage", statusFail, err.Error())
 		} else {
@@ -277,6 +428,100 @@ func checkBudget(cfg *config.Config, database *db.DB, claudeProvider *providers.
 			add("budget.codex", statusOK, fmt.Sprintf("%.1f%% used, %d tokens available", allowance.UsedPercent, allowance.Allowance))
 		}
 	}
+
+	checkDataAge(cfg, budgetMgr, add)
+}
+
+// checkDataAge warns when a provider's usage data is older than the budget
+// window, so its used percent shouldn't be trusted.
+func checkDataAge(cfg *config.Config, budgetMgr *budget.Manager, add func(string, checkStatus, string)) {
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		if !providerEnabled(cfg, name) {
+			continue
+		}
+		age, err := budgetMgr.DataAge(name)
+		switch {
+		case err != nil:
+			add(name+".data_age", statusWarn, err.Error())
+		case age.ModTime.IsZero():
+			continue
+		case age.Stale:
+			add(name+".data_age", statusWarn, "data age: "+formatDataAge(age)+"; used percent may not reflect current usage")
+		default:
+			add(name+".data_age", statusOK, "data age: "+formatDataAge(age))
+		}
+	}
+}
+
+// budgetTuningGap is how far (as a fraction) the configured weekly budget may
+// drift from what snapshots observe before doctor suggests changing it.
+const budgetTuningGap = 0.25
+
+// checkBudgetTuning compares each provider's configured weekly budget with
+// the snapshot-inferred capacity and the observed burn rate. Advisory only:
+// it never changes the config.
+func checkBudgetTuning(cfg *config.Config, database *db.DB, add func(string, checkStatus, string)) {
+	if strings.ToLower(cfg.Budget.BillingMode) == "api" {
+		return
+	}
+	cal := calibrator.New(database, cfg)
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+
+	for _, provider := range []string{"claude", "codex"} {
+		if (provider == "claude" && !cfg.Providers.Claude.Enabled) || (provider == "codex" && !cfg.Providers.Codex.Enabled) {
+			continue
+		}
+		var capacity int64
+		var confidence string
+		if result, err := cal.Calibrate(provider); err == nil && result.SampleCount > 0 {
+			capacity, confidence = result.InferredBudget, result.Confidence
+		}
+		var weeklyBurn int64
+		if profile, err := trend.BuildProfile(provider, 0); err == nil {
+			weeklyBurn = int64(profile.DailyTotal * 7)
+		}
+		key := "budget.weekly_tokens"
+		if _, ok := cfg.Budget.PerProvider[provider]; ok {
+			key = "budget.per_provider." + provider
+		}
+		status, detail := budgetTuningAdvice(int64(cfg.GetProviderBudget(provider)), capacity, confidence, weeklyBurn, key)
+		if detail != "" {
+			add("budget.tuning."+provider, status, detail)
+		}
+	}
+}
+
+// budgetTuningAdvice returns a suggestion when configured is more than
+// budgetTuningGap away from the inferred capacity, or below the observed
+// weekly burn. Low-confidence capacity estimates are not acted on. An empty
+// detail means there is nothing to compare against.
+func budgetTuningAdvice(configured, capacity int64, confidence string, weeklyBurn int64, key string) (checkStatus, string) {
+	numbers := fmt.Sprintf("configured %s, observed capacity %s (%s confidence), weekly burn %s",
+		reporting.NumberGrouped.Tokens(int(configured)),
+		reporting.NumberGrouped.Tokens(int(capacity)), confidence,
+		reporting.NumberGrouped.Tokens(int(weeklyBurn)))
+	usable := capacity > 0 && (confidence == "medium" || confidence == "high")
+
+	switch {
+	case configured <= 0:
+		return statusOK, ""
+	case usable:
+		gap := float64(capacity-configured) / float64(capacity)
+		switch {
+		case gap >= budgetTuningGap:
+			return statusWarn, fmt.Sprintf("your configured weekly budget is %.0f%% below observed capacity; consider raising %s to ~%s (%s)",
+				gap*100, key, reporting.NumberGrouped.Tokens(int(capacity)), numbers)
+		case -gap >= budgetTuningGap:
+			return statusWarn, fmt.Sprintf("your configured weekly budget is %.0f%% above observed capacity; consider lowering %s to ~%s (%s)",
+				-gap*100, key, reporting.NumberGrouped.Tokens(int(capacity)), numbers)
+		}
+		return statusOK, fmt.Sprintf("within %.0f%% of observed capacity (%s)", budgetTuningGap*100, numbers)
+	case weeklyBurn > 0 && float64(weeklyBurn) >= float64(configured)*(1+budgetTuningGap):
+		over := float64(weeklyBurn-configured) / float64(configured)
+		return statusWarn, fmt.Sprintf("observed weekly burn is %.0f%% above your configured weekly budget; consider raising %s (configured %s, weekly burn %s)",
+			over*100, key, reporting.NumberGrouped.Tokens(int(configured)), reporting.NumberGrouped.Tokens(int(weeklyBurn)))
+	}
+	return statusOK, ""
 }
 
 func checkSnapshots(cfg *config.Config, database *db.DB, add func(string, checkStatus, string)) {
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestBudgetTuningAdvice(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured int64
+		capacity   int64
+		confidence string
+		burn       int64
+		wantStatus checkStatus
+		want       string // substring; empty = no detail
+	}{
+		{name: "no data", configured: 700000, wantStatus: statusOK},
+		{name: "in line", configured: 700000, capacity: 750000, confidence: "high", wantStatus: statusOK, want: "within 25% of observed capacity"},
+		{name: "too low", configured: 420000, capacity: 700000, confidence: "high", burn: 350000, wantStatus: statusWarn,
+			want: "40% below observed capacity; consider raising budget.weekly_tokens to ~700,000 (configured 420,000, observed capacity 700,000 (high confidence), weekly burn 350,000)"},
+		{name: "too high", configured: 1000000, capacity: 700000, confidence: "medium", wantStatus: statusWarn, want: "43% above observed capacity; consider lowering"},
+		{name: "low confidence ignored", configured: 420000, capacity: 700000, confidence: "low", wantStatus: statusOK},
+		{name: "burn exceeds config", configured: 400000, burn: 600000, wantStatus: statusWarn, want: "observed weekly burn is 50% above"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, detail := budgetTuningAdvice(tt.configured, tt.capacity, tt.confidence, tt.burn, "budget.weekly_tokens")
+			if status != tt.wantStatus {
+				t.Errorf("status = %s, want %s (%s)", status, tt.wantStatus, detail)
+			}
+			if tt.want == "" && detail != "" {
+				t.Errorf("detail = %q, want none", detail)
+			}
+			if !strings.Contains(detail, tt.want) {
+				t.Errorf("detail = %q, want it to contain %q", detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckScheduleAt(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	// Monday 2024-01-15 12:00 MST
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, denver)
+
+	tests := []struct {
+		name     string
+		schedule config.ScheduleConfig
+		local    *time.Location
+		want     []string // "name STATUS: detail substring", in order
+	}{
+		{
+			name:     "overnight window in local zone",
+			schedule: config.ScheduleConfig{Cron: "0 23 * * *", Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "America/Denver"}},
+			local:    denver,
+			want: []string{
+				"window OK: 22:00-06:00 America/Denver, overnight, open 8h 0m",
+				"timezone OK: window and system both on America/Denver (MST, UTC-07:00)",
+				"schedule OK: next run 2024-01-15 23:00 MST",
+			},
+		},
+		{
+			name:     "window in another zone",
+			schedule: config.ScheduleConfig{Cron: "0 23 * * *", Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "America/Denver"}},
+			local:    time.UTC,
+			want: []string{
+				"window OK: overnight",
+				"timezone WARN: window is in America/Denver (MST, UTC-07:00) but the system is on UTC (UTC, UTC+00:00): 22:00-06:00 there is 05:00-13:00 here",
+				"schedule OK: next run 2024-01-15 23:00 MST (2024-01-16 06:00 UTC local)",
+			},
+		},
+		{
+			name:     "same day window",
+			schedule: config.ScheduleConfig{Interval: "1h", Window: &config.WindowConfig{Start: "01:00", End: "05:30", Timezone: "America/Denver"}},
+			local:    denver,
+			want:     []string{"window OK: same day, open 4h 30m", "timezone OK", "schedule OK: next run 2024-01-16 01:00 MST"},
+		},
+		{
+			name:     "start equals end never opens",
+			schedule: config.ScheduleConfig{Cron: "0 23 * * *", Window: &config.WindowConfig{Start: "22:00", End: "22:00", Timezone: "America/Denver"}},
+			local:    denver,
+			want:     []string{"window FAIL: 22:00-22:00 America/Denver never opens: start equals end"},
+		},
+		{
+			name:     "unparseable window",
+			schedule: config.ScheduleConfig{Cron: "0 23 * * *", Window: &config.WindowConfig{Start: "25:00", End: "06:00"}},
+			local:    denver,
+			want:     []string{"window FAIL: invalid"},
+		},
+		{
+			name:     "cron outside window",
+			schedule: config.ScheduleConfig{Cron: "0 12 * * *", Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "America/Denver"}},
+			local:    denver,
+			want:     []string{"window OK", "timezone OK", `schedule FAIL: cron "0 12 * * *" never fires inside the window`},
+		},
+		{
+			// Without a window cron runs in the process's zone
+			name:     "no window",
+			schedule: config.ScheduleConfig{Cron: "0 23 * * *"},
+			local:    denver,
+			want:     []string{"schedule OK: next run 2024-01-1"},
+		},
+		{
+			name:     "no schedule",
+			schedule: config.ScheduleConfig{},
+			local:    denver,
+			want:     []string{"schedule WARN: no schedule configured"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			add := func(name string, status checkStatus, detail string) {
+				got = append(got, fmt.Sprintf("%s %s: %s", name, status, detail))
+			}
+			checkScheduleAt(&config.Config{Schedule: tt.schedule}, now.In(tt.local), tt.local, add)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checks = %q, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				prefix, substr, _ := strings.Cut(want, ": ")
+				if !strings.HasPrefix(got[i], prefix+":") || !strings.Contains(got[i], substr) {
+					t.Errorf("check %d = %q, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
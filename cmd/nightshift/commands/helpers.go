@@ -1,33 +1,51 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 
 	"github.com/marcus/nightshift/internal/agents"
 	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
 )
 
 // agentByName creates an agent for the given provider name.
-// Returns an error if the provider is unknown or its CLI is not in PATH.
+// Returns an error if the provider is unknown, its CLI is not in PATH, or
+// its login has expired. Inside a docker sandbox the CLI is trusted to be
+// in the image.
 func agentByName(cfg *config.Config, provider string) (agents.Agent, error) {
+	a, err := installedAgentByName(cfg, provider)
+	if err != nil {
+		return nil, err
+	}
+	if auth := agents.CheckAuth(context.Background(), a); auth.Expired() {
+		return nil, fmt.Errorf("%s: %s", a.Name(), auth.Reason())
+	}
+	return a, nil
+}
+
+func installedAgentByName(cfg *config.Config, provider string) (agents.Agent, error) {
+	if err := checkSandbox(cfg); err != nil {
+		return nil, err
+	}
 	switch strings.ToLower(provider) {
 	case "claude":
 		a := newClaudeAgentFromConfig(cfg)
-		if !a.Available() {
+		if !sandboxed(cfg) && !a.Available() {
 			return nil, fmt.Errorf("claude CLI not found in PATH")
 		}
 		return a, nil
 	case "codex":
 		a := newCodexAgentFromConfig(cfg)
-		if !a.Available() {
+		if !sandboxed(cfg) && !a.Available() {
 			return nil, fmt.Errorf("codex CLI not found in PATH")
 		}
 		return a, nil
 	case "copilot":
 		a := newCopilotAgentFromConfig(cfg)
-		if !a.Available() {
+		if !sandboxed(cfg) && !a.Available() {
 			return nil, fmt.Errorf("copilot CLI not found in PATH (install via 'gh' or standalone)")
 		}
 		return a, nil
@@ -36,43 +54,213 @@ func agentByName(cfg *config.Config, provider string) (agents.Agent, error) {
 	}
 }
 
+// newAgentFromConfig creates an agent for the given provider name without
+// checking that its CLI is installed.
+func newAgentFromConfig(cfg *config.Config, provider string) (agents.Agent, error) {
+	switch strings.ToLower(provider) {
+	case "claude":
+		return newClaudeAgentFromConfig(cfg), nil
+	case "codex":
+		return newCodexAgentFromConfig(cfg), nil
+	case "copilot":
+		return newCopilotAgentFromConfig(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s (supported: claude, codex, copilot)", provider)
+	}
+}
+
+// agentEnv holds the --env KEY=VALUE overrides for this invocation. run and
+// task run set it before any agent is created.
+var agentEnv []string
+
+// parseEnvFlags validates --env entries. Keys must be shell-style names;
+// values may be empty and may contain "=".
+func parseEnvFlags(values []string) ([]string, error) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		key, _, ok := strings.Cut(v, "=")
+		if !ok || !validEnvKey(key) {
+			// Don't echo the entry: it may hold a secret.
+			return nil, fmt.Errorf("--env: expected KEY=VALUE, got entry with key %q", key)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func validEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// envKeys returns the keys of KEY=VALUE entries so overrides can be logged
+// without their values.
+func envKeys(env []string) []string {
+	keys := make([]string, 0, len(env))
+	for _, e := range env {
+		key, _, _ := strings.Cut(e, "=")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sandboxed reports whether provider CLIs run inside the sandbox image, in
+// which case they aren't looked up on the host.
+func sandboxed(cfg *config.Config) bool {
+	return cfg != nil && cfg.SandboxEnabled()
+}
+
+// sandboxRunner returns the runner agents use when sandbox.type is docker,
+// or nil to run provider CLIs directly on the host.
+func sandboxRunner(cfg *config.Config) *agents.DockerRunner {
+	if !sandboxed(cfg) {
+		return nil
+	}
+	return agents.NewDockerRunner(cfg.Sandbox.Image, cfg.SandboxMounts())
+}
+
+// checkSandbox returns an error when sandbox.type is docker but the docker
+// CLI is missing, so runs fail up front instead of on every task.
+func checkSandbox(cfg *config.Config) error {
+	if r := sandboxRunner(cfg); r != nil && !r.Available() {
+		return fmt.Errorf("sandbox.type is docker but docker was not found in PATH")
+	}
+	return nil
+}
+
 func newClaudeAgentFromConfig(cfg *config.Config) *agents.ClaudeAgent {
 	if cfg == nil {
-		return agents.NewClaudeAgent()
+		return agents.NewClaudeAgent(agents.WithEnv(agentEnv))
 	}
-	return agents.NewClaudeAgent(
+	opts := []agents.ClaudeOption{
+		agents.WithBinaryPath(providerBinary(cfg, "claude")),
+		agents.WithBinaryArgs(cfg.ProviderArgs("claude")),
 		agents.WithDangerouslySkipPermissions(cfg.Providers.Claude.DangerouslySkipPermissions),
-	)
+		agents.WithExtraArgs(cfg.Providers.Claude.ExtraArgs),
+		agents.WithEnv(agentEnv),
+	}
+	if r := sandboxRunner(cfg); r != nil {
+		opts = append(opts, agents.WithRunner(r))
+	}
+	a := agents.NewClaudeAgent(opts...)
+	warnExtraArgCollisions("claude", a.ExtraArgCollisions())
+	return a
 }
 
 func newCodexAgentFromConfig(cfg *config.Config) *agents.CodexAgent {
 	if cfg == nil {
-		return agents.NewCodexAgent()
+		return agents.NewCodexAgent(agents.WithCodexEnv(agentEnv))
 	}
-	return agents.NewCodexAgent(
+	opts := []agents.CodexOption{
+		agents.WithCodexBinaryPath(providerBinary(cfg, "codex")),
+		agents.WithCodexBinaryArgs(cfg.ProviderArgs("codex")),
 		agents.WithDangerouslyBypassApprovalsAndSandbox(cfg.Providers.Codex.DangerouslyBypassApprovalsAndSandbox),
-	)
+		agents.WithCodexExtraArgs(cfg.Providers.Codex.ExtraArgs),
+		agents.WithCodexEnv(agentEnv),
+	}
+	if r := sandboxRunner(cfg); r != nil {
+		opts = append(opts, agents.WithCodexRunner(r))
+	}
+	a := agents.NewCodexAgent(opts...)
+	warnExtraArgCollisions("codex", a.ExtraArgCollisions())
+	return a
 }
 
 func newCopilotAgentFromConfig(cfg *config.Config) *agents.CopilotAgent {
 	if cfg == nil {
-		return agents.NewCopilotAgent()
-	}
-
-	// Auto-detect: prefer standalone copilot, fallback to gh
-	binaryPath := "gh"
-	if _, err := exec.LookPath("copilot"); err == nil {
-		binaryPath = "copilot"
+		return agents.NewCopilotAgent(agents.WithCopilotEnv(agentEnv))
 	}
 
 	// Copilot uses DangerouslySkipPermissions for --allow-all-tools flag
 	// Note: The agent already uses --no-ask-user for autonomous mode
 	opts := []agents.CopilotOption{
-		agents.WithCopilotBinaryPath(binaryPath),
+		agents.WithCopilotBinaryPath(providerBinary(cfg, "copilot")),
+		agents.WithCopilotBinaryArgs(cfg.ProviderArgs("copilot")),
+		agents.WithCopilotExtraArgs(cfg.Providers.Copilot.ExtraArgs),
+		agents.WithCopilotEnv(agentEnv),
+	}
+	if r := sandboxRunner(cfg); r != nil {
+		opts = append(opts, agents.WithCopilotRunner(r))
 	}
 	if cfg.Providers.Copilot.DangerouslySkipPermissions {
 		// When enabled, this should pass --allow-all-tools
 		// Currently handled via config, future: add agent option
 	}
-	return agents.NewCopilotAgent(opts...)
+	a := agents.NewCopilotAgent(opts...)
+	warnExtraArgCollisions("copilot", a.ExtraArgCollisions())
+	return a
+}
+
+// providerBinary returns the CLI that runs a provider: its configured
+// command, or the provider's default binary.
+func providerBinary(cfg *config.Config, provider string) string {
+	if command := cfg.ProviderCommand(provider); command != "" {
+		return command
+	}
+	if provider == "copilot" {
+		return copilotBinary()
+	}
+	return provider
+}
+
+// copilotBinary returns the CLI used to run Copilot: the standalone
+// copilot binary when installed, otherwise gh.
+func copilotBinary() string {
+	if _, err := exec.LookPath("copilot"); err == nil {
+		return "copilot"
+	}
+	return "gh"
+}
+
+// warnExtraArgCollisions logs extra_args that override flags Nightshift
+// already passes. The args are still sent; the CLI decides which wins.
+func warnExtraArgCollisions(provider string, collisions []string) {
+	if len(collisions) == 0 {
+		return
+	}
+	logging.Component("agents").Warnf("providers.%s.extra_args re-specifies managed flag(s): %s", provider, strings.Join(collisions, " "))
+}
+
+// agentCommandLine returns the shell-quoted command line agent would run for
+// opts, or "" if the agent cannot report it.
+func agentCommandLine(agent agents.Agent, opts agents.ExecuteOptions) string {
+	builder, ok := agent.(agents.CommandBuilder)
+	if !ok {
+		return ""
+	}
+	name, args := builder.Command(opts)
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(name))
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote single-quotes s unless it is made only of shell-safe characters.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@,+%", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -124,19 +124,31 @@ schedule:
     start: "22:00"
     end: "06:00"
     timezone: "America/Denver"   # Your timezone
+    # categories: [analysis, map]  # Only run these task categories in the window
+    # startup_delay: 20m         # Hold tasks back for the window's first 20 minutes
+  processed_window: 20h          # Skip projects processed this recently ("calendar" = same day)
+  # themes:                      # Optional: per-weekday focus (categories or task types)
+  #   monday: [docs-backfill, map]
+  #   tuesday: [test-gap, analysis]
 
 # Budget configuration
 #
 # How budget modes work:
 # - daily: Each night uses up to max_percent of that day's budget (weekly/7)
 # - weekly: Each night uses up to max_percent of the REMAINING weekly budget
+# - auto: Computes both and uses whichever allows less
 #
 budget:
-  mode: daily                    # daily | weekly
+  mode: daily                    # daily | weekly | auto
   max_percent: 75                # Max % of budget per run (default: 75)
   aggressive_end_of_week: false  # Weekly mode: ramp up in last 2 days
   reserve_percent: 5             # Always keep this % in reserve
   weekly_tokens: 700000          # Fallback weekly budget
+  # max_wait_for_reset: 45m      # Sleep for a provider reset mid-run (0 = never)
+  # token_accounting: billable   # billable | raw (include cached input)
+  # pooling: per-provider        # per-provider | pooled (share weekly_tokens across providers)
+  # max_daily_share: 0.3         # Weekly mode: cap one night at 30% of the week (0 = no cap)
+  # pacing: greedy               # Weekly mode: greedy | even | front-loaded
   # per_provider:                # Optional per-provider overrides
   #   claude: 700000
   #   codex: 500000
@@ -146,10 +158,21 @@ providers:
   preference:
     - claude
     - codex
+  # fallback: "off"              # Don't switch providers when the first is unavailable
+  # overflow: [codex]            # Only used once every preferred provider is out of budget
+  # strategy: least-used         # preference (default) | least-used | most-budget
+  # tier_preference:             # Provider order per task cost tier
+  #   low: [copilot]
+  #   very-high: [claude]
+  # extra_path_dirs: ["~/.asdf/shims"]  # Extra bin dirs searched for provider CLIs
+  # status_cache_ttl: 3m         # Reuse usage scans across status/preview/run ("0" = off)
+  # usage_timeout: 30s           # Skip a provider whose usage read hangs ("0" = no limit)
   claude:
     enabled: true
     data_path: "~/.claude"       # Path to Claude Code data directory
     dangerously_skip_permissions: true
+    # extra_args: ["--add-dir", "~/code/shared"]  # Passed verbatim to the CLI
+    # command: /opt/bin/claude-wrapper  # Run the CLI through a wrapper
   codex:
     enabled: true
     data_path: "~/.codex"        # Path to Codex data directory
@@ -166,6 +189,14 @@ projects:
   # - path: ~/code/library
   #   priority: 2
   #   config: .nightshift.yaml   # Per-project override file
+  #   read_only: true            # Only run tasks that don't change code
+
+# Discover git repos directly under these directories as projects
+# (skip one with a .nightshiftignore file; preview with 'nightshift projects scan')
+# scan_roots:
+#   - ~/code
+
+# manage_gitignore: false        # Don't let setup add .nightshift-plan to project .gitignores
 
 # Task configuration
 tasks:
@@ -180,6 +211,8 @@ tasks:
     security: 2
     docs: 3
   disabled: []                   # Explicitly disabled tasks
+  # since_report: true             # Also cool down tasks completed in retained run reports (shared reports dir)
+  # recent_failure_window: 48h     # Skip a task on a project for this long after it failed there
   # custom:                        # User-defined custom tasks
   #   - type: my-review
   #     name: "My Code Review"
@@ -191,6 +224,11 @@ tasks:
   #     risk_level: low            # low | medium | high
   #     interval: "48h"            # Min time between runs
 
+# Task scoring
+# scoring:
+#   balance_categories: true     # Spread multi-task runs across categories
+#   min_score: 3                 # Skip tasks scoring below this (0 = off)
+
 # Integration points
 integrations:
   claude_md: true                # Read claude.md for project context
@@ -207,12 +245,43 @@ logging:
   level: info                    # debug | info | warn | error
   path: ~/.local/share/nightshift/logs
   format: json                   # json | text
+  agent_output: summary          # none | summary | full (agent stdout in the log)
 
 # Reporting configuration
 reporting:
   morning_summary: true          # Generate morning summary
   # email: user@example.com      # Optional email notification
   # slack_webhook: https://...   # Optional Slack notification
+  # retention_days: 30           # Prune run reports older than N days
+  # dir: ~/Sync/nightshift-reports # Run reports directory (default ~/.local/share/nightshift/reports)
+  # number_format: compact       # Report token counts: compact (1.2m) | grouped (1,234,567) | raw
+
+# Orchestrator configuration
+# orchestrator:
+#   pr:
+#     draft: true                # Open PRs as drafts
+#     target_branch: nightly     # PR base branch (default: agent default)
+#   shutdown_grace: 10m          # Let the current task finish after SIGTERM
+#   project_timeout: 45m         # Skip a project's remaining tasks after this long (0 = no cap)
+#   forge: github                # github | gitlab | gitea
+#   branch_template: "nightshift/{{.TaskType}}/{{.Date}}-{{.Time}}"  # Feature branch name per task
+
+# Daemon configuration
+# daemon:
+#   http_addr: ":8787"           # Serve /healthz, /status and /next (binds 127.0.0.1 without a host)
+
+# Safety configuration
+# safety:
+#   deny_paths:                  # Fail tasks that change these globs
+#     - migrations/
+#     - vendor/**
+
+# Run provider CLIs in a container with only the project mounted
+# sandbox:
+#   type: docker                 # none | docker
+#   image: my-agents:latest      # Image with the provider CLIs installed
+#   mounts:                      # Extra host:container[:ro] bind mounts
+#     - ~/.claude:/home/agent/.claude
 `
 }
 
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/marcus/nightshift/internal/config"
 	"github.com/spf13/cobra"
@@ -217,7 +218,7 @@ func installSystemd(binaryPath string, cfg *config.Config) error {
 	timerPath := filepath.Join(systemdDir, systemdTimerName)
 
 	// Generate service and timer content
-	service := generateSystemdService(binaryPath)
+	service := generateSystemdService(binaryPath, cfg.GetShutdownGrace())
 	timer := generateSystemdTimer(cfg)
 
 	// Stop and disable existing service if present
@@ -254,8 +255,10 @@ func installSystemd(binaryPath string, cfg *config.Config) error {
 	return nil
 }
 
-// generateSystemdService creates the systemd service unit content
-func generateSystemdService(binaryPath string) string {
+// generateSystemdService creates the systemd service unit content.
+// TimeoutStopSec leaves room for the shutdown grace period so a restart
+// lets the current task finish before systemd sends SIGKILL.
+func generateSystemdService(binaryPath string, shutdownGrace time.Duration) string {
 	return fmt.Sprintf(`[Unit]
 Description=Nightshift AI-powered code maintenance
 Documentation=https://github.com/marcus/nightshift
@@ -263,12 +266,14 @@ Documentation=https://github.com/marcus/nightshift
 [Service]
 Type=oneshot
 ExecStart=%s run
+TimeoutStopSec=%d
+KillMode=mixed
 StandardOutput=journal
 StandardError=journal
 
 [Install]
 WantedBy=default.target
-`, binaryPath)
+`, binaryPath, int((shutdownGrace + 30*time.Second).Seconds()))
 }
 
 // generateSystemdTimer creates the systemd timer unit content
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/marcus/nightshift/internal/agents"
 	"github.com/marcus/nightshift/internal/budget"
 	"github.com/marcus/nightshift/internal/calibrator"
 	"github.com/marcus/nightshift/internal/config"
@@ -41,6 +42,8 @@ func init() {
 	previewCmd.Flags().Bool("explain", false, "Show budget and task-filter explanations")
 	previewCmd.Flags().Bool("plain", false, "Disable gum pager output")
 	previewCmd.Flags().Bool("json", false, "Output JSON (includes full prompts)")
+	addFreshFlag(previewCmd)
+	previewCmd.Flags().Bool("dump-prompt", false, "Show full prompts and the exact agent command line for each task")
 	rootCmd.AddCommand(previewCmd)
 }
 
@@ -53,6 +56,7 @@ func runPreview(cmd *cobra.Command, args []string) error {
 	explain, _ := cmd.Flags().GetBool("explain")
 	plainOutput, _ := cmd.Flags().GetBool("plain")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	dumpPrompt, _ := cmd.Flags().GetBool("dump-prompt")
 
 	sources, err := detectPreviewConfigSources(projectPath)
 	if err != nil {
@@ -90,12 +94,16 @@ func runPreview(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if dumpPrompt {
+		addPreviewCommands(cfg, result)
+	}
+
 	if jsonOutput {
 		return writePreviewJSON(cmd.OutOrStdout(), result)
 	}
 
 	text := renderPreviewText(result, previewTextOptions{
-		LongPrompt: longPrompt,
+		LongPrompt: longPrompt || dumpPrompt,
 		Explain:    explain,
 	})
 	return writePreviewText(cmd.OutOrStdout(), text, previewPagerOptions{
@@ -141,6 +149,7 @@ func detectPreviewConfigSources(projectPath string) (*previewConfigSources, erro
 
 type previewResult struct {
 	GeneratedAt    time.Time
+	Schedule       config.ScheduleConfig
 	Provider       string
 	TaskFilter     string
 	BudgetMode     string
@@ -191,6 +200,7 @@ type previewTask struct {
 	Prompt          string
 	PromptFile      string
 	PromptFileError string
+	Command         string // agent command line, set by --dump-prompt
 }
 
 type previewDiagnostics struct {
@@ -254,12 +264,13 @@ func buildPreviewResult(cfg *config.Config, database *db.DB, projects []string,
 		return nil, fmt.Errorf("compute next runs: %w", err)
 	}
 
-	claudeProvider := providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude"))
-	codexProvider := providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex"))
+	claudeProvider := newClaudeProvider(cfg)
+	codexProvider := newCodexProvider(cfg)
 	copilotProvider := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
 	cal := calibrator.New(database, cfg)
 	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
-	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budget.WithReservations(st), budget.WithUsageCache(newUsageCache(cfg)))
+	defer func() { _ = budgetMgr.SaveCache() }()
 
 	selector := tasks.NewSelector(cfg, st)
 	orch := orchestrator.New()
@@ -290,6 +301,7 @@ func buildPreviewResult(cfg *config.Config, database *db.DB, projects []string,
 
 	result := &previewResult{
 		GeneratedAt:    time.Now(),
+		Schedule:       cfg.Schedule,
 		Provider:       provider,
 		TaskFilter:     taskFilter,
 		BudgetMode:     mode,
@@ -307,7 +319,7 @@ func buildPreviewResult(cfg *config.Config, database *db.DB, projects []string,
 		for _, project := range projects {
 			projectResult := previewProject{Path: project}
 
-			if taskFilter == "" && st.WasProcessedToday(project) {
+			if taskFilter == "" && st.WasProcessedSince(project, cfg.ProcessedSince(time.Now())) {
 				projectResult.Status = previewProjectSkipped
 				projectResult.Detail = "already processed today"
 				run.Projects = append(run.Projects, projectResult)
@@ -559,6 +571,28 @@ func computePreviewDiagnostics(cfg *config.Config, selector *tasks.Selector, pro
 	return diagnostics
 }
 
+// addPreviewCommands fills in the agent command line that would carry each
+// ready task's plan prompt.
+func addPreviewCommands(cfg *config.Config, result *previewResult) {
+	agent, err := newAgentFromConfig(cfg, result.Provider)
+	if err != nil {
+		return
+	}
+	for ri := range result.Runs {
+		for pi := range result.Runs[ri].Projects {
+			project := &result.Runs[ri].Projects[pi]
+			for ti := range project.Tasks {
+				task := &project.Tasks[ti]
+				task.Command = agentCommandLine(agent, agents.ExecuteOptions{
+					Prompt:  task.Prompt,
+					WorkDir: project.Path,
+					Model:   cfg.GetTaskModel(task.Type, result.Provider),
+				})
+			}
+		}
+	}
+}
+
 func previewProvider(cfg *config.Config) (string, error) {
 	if cfg.Providers.Claude.Enabled {
 		return "claude", nil
@@ -152,6 +152,11 @@ func renderPreviewText(result *previewResult, opts previewTextOptions) string {
 				} else if task.PromptFile != "" {
 					fmt.Fprintf(b, "       Prompt file: %s\n", task.PromptFile)
 				}
+				if task.Command != "" {
+					b.WriteString("       Command:\n")
+					b.WriteString(indentLines(task.Command, "       "))
+					b.WriteString("\n")
+				}
 				b.WriteString("\n")
 			}
 		}
@@ -268,7 +273,7 @@ func renderBudgetText(b *strings.Builder, allowance *budget.AllowanceResult, ind
 		formatTokens64(allowance.PredictedUsage))
 	if allowance.Mode == "weekly" {
 		b.WriteString(indent)
-		fmt.Fprintf(b, "Budget window: %d day(s) remaining, multiplier %.2f\n", allowance.RemainingDays, allowance.Multiplier)
+		fmt.Fprintf(b, "Budget window: %d day(s) remaining, multiplier %.2f, pacing %s\n", allowance.RemainingDays, allowance.Multiplier, allowance.Pacing)
 	}
 	b.WriteString(indent)
 	fmt.Fprintf(b, "Budget source: %s (confidence=%s, samples=%d)\n",
@@ -427,6 +432,8 @@ func runGumPager(w io.Writer, gumPath, text string) error {
 
 type previewJSON struct {
 	GeneratedAt     string                      `json:"generated_at"`
+	Schedule        previewJSONSchedule         `json:"schedule"`
+	NextRun         string                      `json:"next_run,omitempty"`
 	Provider        string                      `json:"provider"`
 	TaskFilter      string                      `json:"task_filter,omitempty"`
 	EnabledTasks    []string                    `json:"enabled_tasks,omitempty"`
@@ -437,6 +444,21 @@ type previewJSON struct {
 	Notes           []string                    `json:"notes,omitempty"`
 }
 
+type previewJSONSchedule struct {
+	Cron            string              `json:"cron,omitempty"`
+	Interval        string              `json:"interval,omitempty"`
+	Window          *previewJSONWindow  `json:"window,omitempty"`
+	ProcessedWindow string              `json:"processed_window,omitempty"`
+	Themes          map[string][]string `json:"themes,omitempty"`
+}
+
+type previewJSONWindow struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Timezone   string   `json:"timezone,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+}
+
 type previewJSONBudgetConfig struct {
 	Mode           string `json:"mode"`
 	MaxPercent     int    `json:"max_percent"`
@@ -509,6 +531,7 @@ type previewJSONTask struct {
 	Prompt          string  `json:"prompt"`
 	PromptFile      string  `json:"prompt_file,omitempty"`
 	PromptFileError string  `json:"prompt_file_error,omitempty"`
+	Command         string  `json:"command,omitempty"`
 }
 
 func writePreviewJSON(w io.Writer, result *previewResult) error {
@@ -582,6 +605,7 @@ func buildPreviewJSON(result *previewResult) previewJSON {
 					Prompt:          task.Prompt,
 					PromptFile:      task.PromptFile,
 					PromptFileError: task.PromptFileError,
+					Command:         task.Command,
 				})
 			}
 
@@ -602,8 +626,19 @@ func buildPreviewJSON(result *previewResult) previewJSON {
 		})
 	}
 
+	schedule := previewJSONSchedule{
+		Cron:            result.Schedule.Cron,
+		Interval:        result.Schedule.Interval,
+		ProcessedWindow: result.Schedule.ProcessedWindow,
+		Themes:          result.Schedule.Themes,
+	}
+	if w := result.Schedule.Window; w != nil {
+		schedule.Window = &previewJSONWindow{Start: w.Start, End: w.End, Timezone: w.Timezone, Categories: w.Categories}
+	}
+
 	payload := previewJSON{
 		GeneratedAt:  result.GeneratedAt.Format(time.RFC3339),
+		Schedule:     schedule,
 		Provider:     result.Provider,
 		TaskFilter:   result.TaskFilter,
 		EnabledTasks: result.EnabledTasks,
@@ -616,6 +651,9 @@ func buildPreviewJSON(result *previewResult) previewJSON {
 		ProviderBudgets: budgets,
 		Runs:            runs,
 	}
+	if len(runs) > 0 {
+		payload.NextRun = runs[0].RunAt
+	}
 	if result.Note != "" {
 		payload.Notes = []string{result.Note}
 	}
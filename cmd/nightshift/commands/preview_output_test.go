@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestWritePreviewJSON(t *testing.T) {
+	runAt := time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC)
+	result := &previewResult{
+		GeneratedAt: runAt.Add(-3 * time.Hour),
+		Schedule: config.ScheduleConfig{
+			Cron:   "0 2 * * *",
+			Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC", Categories: []string{"analysis"}},
+			Themes: map[string][]string{"monday": {"map"}},
+		},
+		Provider:   "claude",
+		BudgetMode: "daily",
+		MaxPercent: 75,
+		Providers:  []providerBudgetSummary{{name: "claude", allowance: &budget.AllowanceResult{Allowance: 90_000, UsedPercent: 12.5}}},
+		Runs: []previewRun{
+			{Index: 1, RunAt: runAt, Projects: []previewProject{{
+				Path:   "/code/app",
+				Status: previewProjectReady,
+				Budget: &budget.AllowanceResult{Allowance: 90_000, Mode: "daily"},
+				Tasks:  []previewTask{{Index: 1, Type: "lint-fix", Name: "Linter Fixes", Score: 4.5, CostTier: "Low", MinTokens: 10_000, MaxTokens: 50_000}},
+			}}},
+			{Index: 2, RunAt: runAt.Add(24 * time.Hour)},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writePreviewJSON(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if got["next_run"] != "2026-03-05T02:00:00Z" {
+		t.Errorf("next_run = %v, want the first run", got["next_run"])
+	}
+	schedule, _ := got["schedule"].(map[string]any)
+	window, _ := schedule["window"].(map[string]any)
+	if schedule["cron"] != "0 2 * * *" || window["start"] != "22:00" || schedule["themes"] == nil {
+		t.Errorf("schedule = %v", schedule)
+	}
+	if got["provider"] != "claude" {
+		t.Errorf("provider = %v", got["provider"])
+	}
+	budgets, _ := got["provider_budgets"].([]any)
+	if len(budgets) != 1 || budgets[0].(map[string]any)["allowance"] != float64(90_000) {
+		t.Errorf("provider_budgets = %v", budgets)
+	}
+	runs, _ := got["runs"].([]any)
+	if len(runs) != 2 {
+		t.Fatalf("runs = %v, want 2", runs)
+	}
+	project := runs[0].(map[string]any)["projects"].([]any)[0].(map[string]any)
+	task := project["tasks"].([]any)[0].(map[string]any)
+	if task["type"] != "lint-fix" || task["score"] != 4.5 || task["max_tokens"] != float64(50_000) {
+		t.Errorf("task = %v", task)
+	}
+}
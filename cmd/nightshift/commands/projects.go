@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/projects"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Inspect project discovery",
+}
+
+var projectsScanCmd = &cobra.Command{
+	Use:   "scan [DIR...]",
+	Short: "Preview repos discovered under a parent directory",
+	Long: `List the immediate subdirectories of DIR that contain a .git folder,
+as scan_roots would discover them at run time. With no DIR, scans the
+configured scan_roots.
+
+A repo is skipped when it contains a .nightshiftignore file, or when its
+name matches a line (name or glob) in DIR/.nightshiftignore.
+
+Examples:
+  nightshift projects scan ~/code
+  nightshift projects scan`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		roots := args
+		if len(roots) == 0 {
+			cfg, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			if len(cfg.ScanRoots) == 0 {
+				fmt.Println("No scan_roots configured. Pass a directory to preview.")
+				return nil
+			}
+			roots = cfg.ScanRoots
+		}
+		return runProjectsScan(os.Stdout, roots)
+	},
+}
+
+func init() {
+	projectsCmd.AddCommand(projectsScanCmd)
+	rootCmd.AddCommand(projectsCmd)
+}
+
+func runProjectsScan(w io.Writer, roots []string) error {
+	for i, root := range roots {
+		found, ignored, err := projects.ScanRoot(root)
+		if err != nil {
+			return fmt.Errorf("scanning %s: %w", root, err)
+		}
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, "%s: %d project(s)\n", root, len(found))
+		for _, path := range found {
+			_, _ = fmt.Fprintf(w, "  %s\n", path)
+		}
+		for _, path := range ignored {
+			_, _ = fmt.Fprintf(w, "  %s (ignored)\n", path)
+		}
+	}
+	return nil
+}
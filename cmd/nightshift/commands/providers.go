@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/calibrator"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/snapshots"
+	"github.com/marcus/nightshift/internal/trends"
+)
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Inspect configured providers",
+}
+
+var providersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List providers with current usage",
+	Long: `List configured providers with used percent, reset time, the age of
+the usage data behind the percent, and the latest stored usage snapshot.
+Data older than the budget window (a day, or a week in weekly mode) is
+marked stale: the used percent no longer reflects current usage.
+
+Use --refresh after heavy interactive use to re-read provider usage and
+store a fresh snapshot now instead of waiting for the daemon's next one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		return runProvidersList(cmd.Context(), refresh)
+	},
+}
+
+func init() {
+	providersListCmd.Flags().Bool("refresh", false, "Re-read usage and store a fresh snapshot for each enabled provider")
+	providersCmd.AddCommand(providersListCmd)
+	rootCmd.AddCommand(providersCmd)
+}
+
+// newClaudeProvider builds the Claude provider for cfg's data path and
+// token accounting.
+func newClaudeProvider(cfg *config.Config) *providers.Claude {
+	claude := providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude"))
+	claude.SetTokenAccounting(providers.TokenAccounting(cfg.GetTokenAccounting()))
+	return claude
+}
+
+// newCodexProvider builds the Codex provider for cfg's data path and token
+// accounting.
+func newCodexProvider(cfg *config.Config) *providers.Codex {
+	codex := providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex"))
+	codex.SetTokenAccounting(providers.TokenAccounting(cfg.GetTokenAccounting()))
+	return codex
+}
+
+func runProvidersList(ctx context.Context, refresh bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	claude := newClaudeProvider(cfg)
+	codex := newCodexProvider(cfg)
+	copilot := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
+	collector := newSnapshotCollector(cfg, database, claude, codex, copilot)
+
+	enabled, err := resolveProviderList(cfg, "")
+	if err != nil {
+		return err
+	}
+
+	if refresh {
+		if len(enabled) == 0 {
+			fmt.Println("No providers enabled.")
+			return nil
+		}
+		if cfg.Providers.Codex.Enabled {
+			if _, err := codex.RefreshRateLimits(); err != nil {
+				fmt.Printf("codex: refreshing rate limits: %v\n", err)
+			}
+		}
+		fmt.Println("Refreshed snapshots:")
+		for _, line := range takeProviderSnapshots(ctx, collector, enabled) {
+			fmt.Printf("  %s\n", line)
+		}
+		fmt.Println()
+	}
+
+	cal := calibrator.New(database, cfg)
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+	mgr := budget.NewManagerFromProviders(cfg, claude, codex, copilot, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+
+	var rows []providerListRow
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		row := providerListRow{name: name, enabled: providerEnabled(cfg, name)}
+		if row.enabled {
+			row.used, row.usedErr = mgr.GetUsedPercent(name)
+			row.dataAge, _ = mgr.DataAge(name)
+			if reset, err := mgr.GetResetTime(name); err == nil {
+				row.reset = reset
+			}
+			if latest, err := collector.GetLatest(name, 1); err == nil && len(latest) > 0 {
+				row.lastSnapshot = latest[0].Timestamp
+			}
+		}
+		rows = append(rows, row)
+	}
+	printProviderList(os.Stdout, rows, time.Now())
+	return nil
+}
+
+// providerEnabled reports whether the named provider is enabled in cfg.
+func providerEnabled(cfg *config.Config, name string) bool {
+	switch name {
+	case "claude":
+		return cfg.Providers.Claude.Enabled
+	case "codex":
+		return cfg.Providers.Codex.Enabled
+	case "copilot":
+		return cfg.Providers.Copilot.Enabled
+	}
+	return false
+}
+
+// providerListRow is one line of `providers list` output.
+type providerListRow struct {
+	name         string
+	enabled      bool
+	used         float64
+	usedErr      error
+	reset        time.Time
+	dataAge      budget.DataAge
+	lastSnapshot time.Time
+}
+
+func printProviderList(w io.Writer, rows []providerListRow, now time.Time) {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "Provider\tEnabled\tUsed\tData age\tResets\tLast snapshot")
+	for _, row := range rows {
+		if !row.enabled {
+			_, _ = fmt.Fprintf(writer, "%s\tno\t-\t-\t-\t-\n", row.name)
+			continue
+		}
+		used := fmt.Sprintf("%.1f%%", row.used)
+		if row.usedErr != nil {
+			used = "error: " + row.usedErr.Error()
+		}
+		reset := "-"
+		if !row.reset.IsZero() {
+			reset = row.reset.Format("Jan 02 15:04")
+		}
+		last := "never"
+		if !row.lastSnapshot.IsZero() {
+			last = fmt.Sprintf("%s ago", formatDuration(now.Sub(row.lastSnapshot)))
+		}
+		_, _ = fmt.Fprintf(writer, "%s\tyes\t%s\t%s\t%s\t%s\n", row.name, used, formatDataAge(row.dataAge), reset, last)
+	}
+	_ = writer.Flush()
+	for _, row := range rows {
+		if row.enabled && row.dataAge.Stale {
+			_, _ = fmt.Fprintf(w, "\nWarning: %s\n", staleDataWarning(row.name, row.dataAge))
+		}
+	}
+}
+
+// formatDataAge renders a provider's usage data age compactly, e.g. "3d
+// (stale)", or "-" when the provider has no data.
+func formatDataAge(a budget.DataAge) string {
+	if a.ModTime.IsZero() {
+		return "-"
+	}
+	var s string
+	switch {
+	case a.Age >= 24*time.Hour:
+		s = fmt.Sprintf("%dd", int(a.Age.Hours()/24))
+	case a.Age >= time.Hour:
+		s = fmt.Sprintf("%dh", int(a.Age.Hours()))
+	default:
+		s = fmt.Sprintf("%dm", int(a.Age.Minutes()))
+	}
+	if a.Stale {
+		s += " (stale)"
+	}
+	return s
+}
+
+// staleDataWarning explains why a stale provider's used percent shouldn't
+// be trusted.
+func staleDataWarning(provider string, a budget.DataAge) string {
+	return fmt.Sprintf("%s usage data is %s old (budget window: %s); its used percent may not reflect current usage",
+		provider, strings.TrimSuffix(formatDataAge(a), " (stale)"), formatDataAge(budget.DataAge{ModTime: a.ModTime, Age: a.Window}))
+}
+
+// newSnapshotCollector builds a collector for the given providers, scraping
+// via tmux when calibration is enabled for subscription billing.
+func newSnapshotCollector(cfg *config.Config, database *db.DB, claude *providers.Claude, codex *providers.Codex, copilot *providers.Copilot) *snapshots.Collector {
+	scraper := snapshots.UsageScraper(nil)
+	if cfg.Budget.CalibrateEnabled && strings.ToLower(cfg.Budget.BillingMode) != "api" {
+		scraper = tmuxScraper{}
+	}
+	collector := snapshots.NewCollector(database, claude, codex, copilot, scraper, weekStartDayFromConfig(cfg))
+	collector.SetUsageTimeout(cfg.GetUsageTimeout())
+	return collector
+}
+
+// takeProviderSnapshots stores a snapshot for each named provider and returns
+// one summary line per provider.
+func takeProviderSnapshots(ctx context.Context, collector *snapshots.Collector, names []string) []string {
+	var lines []string
+	for _, name := range names {
+		snapshot, err := collector.TakeSnapshot(ctx, name)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%s: error: %v", name, err))
+			continue
+		}
+		lines = append(lines, formatSnapshotLine(snapshot))
+	}
+	return lines
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/budget"
+)
+
+func TestPrintProviderList(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	rows := []providerListRow{
+		{name: "claude", enabled: true, used: 42.5, reset: now.Add(3 * time.Hour), lastSnapshot: now.Add(-90 * time.Second),
+			dataAge: budget.DataAge{ModTime: now.Add(-2 * time.Hour), Age: 2 * time.Hour, Window: 24 * time.Hour}},
+		{name: "codex", enabled: true, usedErr: errors.New("no session data"),
+			dataAge: budget.DataAge{ModTime: now.Add(-72 * time.Hour), Age: 72 * time.Hour, Window: 24 * time.Hour, Stale: true}},
+		{name: "copilot"},
+	}
+
+	var buf bytes.Buffer
+	printProviderList(&buf, rows, now)
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d lines, want 6:\n%s", len(lines), buf.String())
+	}
+
+	tests := []struct {
+		line int
+		want []string
+	}{
+		{0, []string{"Data age"}},
+		{1, []string{"claude", "yes", "42.5%", "2h", "Mar 04 15:00", "1m 30s ago"}},
+		{2, []string{"codex", "yes", "error: no session data", "3d (stale)", "never"}},
+		{3, []string{"copilot", "no"}},
+		{5, []string{"Warning: codex usage data is 3d old (budget window: 1d)"}},
+	}
+	for _, tt := range tests {
+		for _, want := range tt.want {
+			if !strings.Contains(lines[tt.line], want) {
+				t.Errorf("line %d = %q, want it to contain %q", tt.line, lines[tt.line], want)
+			}
+		}
+	}
+}
+
+func TestBudgetWeekStart(t *testing.T) {
+	wed := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC) // Wednesday
+	tests := []struct {
+		day  time.Weekday
+		want time.Time
+	}{
+		{time.Monday, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{time.Sunday, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Wednesday, time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := budgetWeekStart(wed, tt.day); !got.Equal(tt.want) {
+			t.Errorf("budgetWeekStart(%s) = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestPrintProvidersUsage(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	start := budgetWeekStart(now, time.Monday)
+	scraped := 41.0
+	usage := providersUsage{
+		WeekStart: start,
+		WeekEnd:   start.AddDate(0, 0, 7),
+		Providers: []providerUsageJSON{
+			{Name: "claude", Enabled: true, UsedPercent: 50, WeeklyTokens: 1_200_000, Snapshots: 14, LastScrapedPercent: &scraped},
+			{Name: "codex", Enabled: true, UsedPercent: 95, WeeklyTokens: 300_000, ResetAt: now.Add(3*time.Hour + 20*time.Minute)},
+			{Name: "copilot"},
+		},
+	}
+
+	var chart bytes.Buffer
+	printProvidersUsageChart(&chart, usage, now)
+	out := chart.String()
+	for _, want := range []string{
+		"Week of Mar 02 (ends in 108h 0m)",
+		strings.Repeat("█", 15) + strings.Repeat("░", 15),
+		"50.0%", "1.2M tokens", "resets in 108h 0m",
+		"14 snapshot(s) this week, last scraped 41.0%",
+		"95.0%", "resets in 3h 20m",
+		"disabled",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("chart missing %q\n%s", want, out)
+		}
+	}
+
+	var table bytes.Buffer
+	printProvidersUsageTable(&table, usage, now)
+	lines := strings.Split(strings.TrimRight(table.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("table has %d lines, want 4:\n%s", len(lines), table.String())
+	}
+	if !strings.Contains(lines[2], "95.0%") || !strings.Contains(lines[2], "resets in 3h 20m") {
+		t.Errorf("codex row = %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "disabled") {
+		t.Errorf("copilot row = %q", lines[3])
+	}
+}
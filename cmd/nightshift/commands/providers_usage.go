@@ -0,0 +1,221 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/calibrator"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/trends"
+)
+
+const usageChartWidth = 30
+
+var providersUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show this budget week's usage per provider",
+	Long: `Show each enabled provider's used percent, tokens consumed this budget
+week, and time until its next reset.
+
+Use --chart for horizontal bars, --json for structured output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chart, _ := cmd.Flags().GetBool("chart")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		if noColor || os.Getenv("NO_COLOR") != "" {
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
+		return runProvidersUsage(os.Stdout, chart, asJSON)
+	},
+}
+
+func init() {
+	providersUsageCmd.Flags().Bool("chart", false, "Render usage as horizontal bars")
+	providersUsageCmd.Flags().Bool("json", false, "Output as JSON")
+	providersUsageCmd.Flags().Bool("no-color", false, "Disable ANSI colors")
+	providersCmd.AddCommand(providersUsageCmd)
+}
+
+// providersUsage is the providers usage report for one budget week.
+type providersUsage struct {
+	WeekStart time.Time           `json:"week_start"`
+	WeekEnd   time.Time           `json:"week_end"`
+	Providers []providerUsageJSON `json:"providers"`
+}
+
+type providerUsageJSON struct {
+	Name               string    `json:"name"`
+	Enabled            bool      `json:"enabled"`
+	UsedPercent        float64   `json:"used_percent"`
+	WeeklyTokens       int64     `json:"weekly_tokens"`
+	ResetAt            time.Time `json:"reset_at,omitzero"`
+	Snapshots          int       `json:"snapshots"`                      // stored this week
+	LastScrapedPercent *float64  `json:"last_scraped_percent,omitempty"` // from the latest scraped snapshot
+	Error              string    `json:"error,omitempty"`
+}
+
+func runProvidersUsage(w io.Writer, chart, asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	claude := newClaudeProvider(cfg)
+	codex := newCodexProvider(cfg)
+	copilot := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
+	collector := newSnapshotCollector(cfg, database, claude, codex, copilot)
+	cal := calibrator.New(database, cfg)
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+	mgr := budget.NewManagerFromProviders(cfg, claude, codex, copilot, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+
+	now := time.Now()
+	start := budgetWeekStart(now, weekStartDayFromConfig(cfg))
+	usage := providersUsage{WeekStart: start, WeekEnd: start.AddDate(0, 0, 7)}
+
+	weeklyTokens := map[string]func() (int64, error){
+		"claude":  claude.GetWeeklyUsage,
+		"codex":   codex.GetWeeklyTokens,
+		"copilot": copilot.GetWeeklyTokens,
+	}
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		p := providerUsageJSON{Name: name, Enabled: providerEnabled(cfg, name)}
+		if !p.Enabled {
+			usage.Providers = append(usage.Providers, p)
+			continue
+		}
+		var errs []string
+		if p.UsedPercent, err = mgr.GetUsedPercent(name); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if p.WeeklyTokens, err = weeklyTokens[name](); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if reset, err := mgr.GetResetTime(name); err == nil {
+			p.ResetAt = reset
+		}
+		if snaps, err := collector.GetSinceWeekStart(name); err == nil {
+			p.Snapshots = len(snaps)
+			for i := len(snaps) - 1; i >= 0; i-- {
+				if snaps[i].ScrapedPct != nil {
+					p.LastScrapedPercent = snaps[i].ScrapedPct
+					break
+				}
+			}
+		}
+		p.Error = strings.Join(errs, "; ")
+		usage.Providers = append(usage.Providers, p)
+	}
+
+	switch {
+	case asJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(usage)
+	case chart:
+		printProvidersUsageChart(w, usage, now)
+	default:
+		printProvidersUsageTable(w, usage, now)
+	}
+	return nil
+}
+
+// budgetWeekStart returns midnight on the most recent weekStartDay.
+func budgetWeekStart(now time.Time, weekStartDay time.Weekday) time.Time {
+	offset := (int(now.Weekday()) - int(weekStartDay) + 7) % 7
+	day := now.AddDate(0, 0, -offset)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// usageResetIn describes the time until p resets, falling back to the end of
+// the budget week for providers that do not report a reset.
+func usageResetIn(p providerUsageJSON, weekEnd, now time.Time) string {
+	reset := p.ResetAt
+	if reset.IsZero() || !reset.After(now) {
+		reset = weekEnd
+	}
+	return "resets in " + formatDuration(reset.Sub(now))
+}
+
+func printProvidersUsageTable(w io.Writer, usage providersUsage, now time.Time) {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "Provider\tUsed\tTokens this week\tReset\tSnapshots")
+	for _, p := range usage.Providers {
+		switch {
+		case !p.Enabled:
+			_, _ = fmt.Fprintf(writer, "%s\tdisabled\t-\t-\t-\n", p.Name)
+		case p.Error != "":
+			_, _ = fmt.Fprintf(writer, "%s\terror: %s\t-\t-\t-\n", p.Name, p.Error)
+		default:
+			_, _ = fmt.Fprintf(writer, "%s\t%.1f%%\t%s\t%s\t%d\n", p.Name, p.UsedPercent, formatTokens64(p.WeeklyTokens), usageResetIn(p, usage.WeekEnd, now), p.Snapshots)
+		}
+	}
+	_ = writer.Flush()
+}
+
+func printProvidersUsageChart(w io.Writer, usage providersUsage, now time.Time) {
+	styles := newReportStyles()
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render("Provider usage"))
+	b.WriteString("\n")
+	b.WriteString(styles.Subtitle.Render(fmt.Sprintf("Week of %s (ends in %s)", usage.WeekStart.Format("Jan 02"), formatDuration(usage.WeekEnd.Sub(now)))))
+	b.WriteString("\n\n")
+
+	for _, p := range usage.Providers {
+		name := styles.Label.Render(fmt.Sprintf("%-8s", p.Name))
+		switch {
+		case !p.Enabled:
+			b.WriteString(name + " " + styles.Muted.Render("disabled") + "\n")
+			continue
+		case p.Error != "":
+			b.WriteString(name + " " + styles.Error.Render("error: "+p.Error) + "\n")
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s  %s  %s\n",
+			name,
+			usageBar(styles, p.UsedPercent, usageChartWidth),
+			styles.Value.Render(fmt.Sprintf("%5.1f%%", p.UsedPercent)),
+			styles.Value.Render(formatTokens64(p.WeeklyTokens)+" tokens"),
+			styles.Muted.Render(usageResetIn(p, usage.WeekEnd, now)),
+		))
+		detail := fmt.Sprintf("%d snapshot(s) this week", p.Snapshots)
+		if p.LastScrapedPercent != nil {
+			detail += fmt.Sprintf(", last scraped %.1f%%", *p.LastScrapedPercent)
+		}
+		b.WriteString(strings.Repeat(" ", 9) + styles.Muted.Render(detail) + "\n")
+	}
+	_, _ = fmt.Fprint(w, b.String())
+}
+
+// usageBar renders percent as a bar of width cells, colored by how close the
+// provider is to its limit.
+func usageBar(styles reportStyles, percent float64, width int) string {
+	fill := max(0, min(percent, 100))
+	filled := int(fill*float64(width)/100 + 0.5)
+	style := styles.OK
+	switch {
+	case percent >= 90:
+		style = styles.Error
+	case percent >= 70:
+		style = styles.Warn
+	}
+	return style.Render(strings.Repeat("█", filled)) + styles.Muted.Render(strings.Repeat("░", width-filled))
+}
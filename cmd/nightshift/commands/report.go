@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	netmail "net/mail"
 	"os"
 	"path/filepath"
 	"sort"
@@ -23,10 +26,17 @@ type reportOptions struct {
 	runs       int
 	since      string
 	until      string
+	label      string // only runs stamped with run --label
 	format     string
 	noColor    bool
 	showPaths  bool
+	annotate   bool // list each task's branch and commits
 	maxItems   int
+	numbers    reporting.NumberFormat // token count style for fancy views
+	redactor   *reportRedactor        // set by --redact; nil leaves output as-is
+	// regressionDelta is the success-rate drop (0-1) --highlight-regressions
+	// flags; 0 leaves the check off.
+	regressionDelta float64
 }
 
 type reportRange struct {
@@ -46,7 +56,66 @@ var reportCmd = &cobra.Command{
 	Short: "Show what nightshift did",
 	Long: `View structured reports from recent nightshift runs.
 
-By default, shows a polished overview of what happened during the last night.`,
+By default, shows a polished overview of what happened during the last night.
+
+Use --fail-on to gate CI jobs: after rendering, the command exits non-zero
+if any failed tasks exist (failures), remaining budget dropped below 20% of
+the starting budget (low-budget), or no runs were found in range (no-runs).
+
+Token counts print compactly (1.2m) by default. Use --number-format grouped
+or raw, or set reporting.number_format, for 1,234,567 or 1234567. JSON
+output always carries plain integers.
+
+Use --redact before attaching a report to an issue: project paths become
+their basenames, the home directory becomes ~, credentials are stripped
+from URLs, and common token formats are masked, in every format.
+
+Use --open to open the report files behind the runs shown (--report raw
+opens them in $EDITOR when set), and --open-prs to open every PR created in
+the range in the browser. Without a terminal, the paths and URLs are
+printed instead.
+
+Use --highlight-regressions to flag task types that usually succeed but
+started failing: each type's success rate in the range is compared with its
+baseline over all older retained reports, and types that dropped by more
+than --regression-delta (default 0.25, i.e. 25 points) are listed.
+
+Use --label to show only runs tagged with run --label NAME.
+
+Use --annotate to list, under each task, the branch it worked on and the
+commits it created (short SHA and subject), so work that didn't end in a
+PR can still be traced. Commits are recorded from local branches that
+were created or moved during the task; tasks run outside a git repository,
+or before this was recorded, show none. JSON output always includes them.
+
+Use --format junit to hand the runs to a CI test-result viewer: each run
+is a testsuite and each task a testcase (task type as classname, title as
+name). Failed and abandoned tasks are failures and skipped tasks are
+skipped, with the skip reason as the message.
+
+Use --email-to ADDR to mail the rendered report (in any --format) instead
+of printing it, e.g. from a morning cron job. It uses the same SMTP
+settings as the morning summary email: NIGHTSHIFT_SMTP_HOST, _PORT
+(default 587), _USER, _PASS and _FROM. Colors are always off in the email.
+The command exits non-zero if the email can't be sent.
+
+Use --report events TASK to replay the orchestrator events (phases,
+iterations, log messages) recorded for the newest run of a task type, e.g.
+to see why a daemon run abandoned it. Every retained report is searched
+unless --period, --since or --until is given.
+
+Examples:
+  nightshift report --report events lint-fix
+  nightshift report --period last-7d --highlight-regressions
+  nightshift report --period all --label experiment
+  nightshift report --open-prs
+  nightshift report --report tasks --annotate
+  nightshift report --format json --redact
+  nightshift report --period last-24h --format junit > nightshift.xml
+  nightshift report --period last-night --format markdown --email-to me@example.com
+  nightshift report --fail-on failures
+  nightshift report --period last-24h --fail-on failures,low-budget --fail-on no-runs`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opts := reportOptions{}
 		opts.reportType, _ = cmd.Flags().GetString("report")
@@ -54,16 +123,48 @@ By default, shows a polished overview of what happened during the last night.`,
 		opts.runs, _ = cmd.Flags().GetInt("runs")
 		opts.since, _ = cmd.Flags().GetString("since")
 		opts.until, _ = cmd.Flags().GetString("until")
+		opts.label, _ = cmd.Flags().GetString("label")
 		opts.format, _ = cmd.Flags().GetString("format")
 		opts.noColor, _ = cmd.Flags().GetBool("no-color")
 		opts.showPaths, _ = cmd.Flags().GetBool("paths")
+		opts.annotate, _ = cmd.Flags().GetBool("annotate")
 		opts.maxItems, _ = cmd.Flags().GetInt("max-items")
+		if highlight, _ := cmd.Flags().GetBool("highlight-regressions"); highlight {
+			opts.regressionDelta, _ = cmd.Flags().GetFloat64("regression-delta")
+			if opts.regressionDelta <= 0 || opts.regressionDelta > 1 {
+				return fmt.Errorf("--regression-delta must be between 0 and 1")
+			}
+		}
+		failOnValues, _ := cmd.Flags().GetStringSlice("fail-on")
+		failOn, err := parseFailOn(failOnValues)
+		if err != nil {
+			return err
+		}
 
-		if opts.noColor || opts.format == "plain" {
+		// --email-to sends the rendered report instead of printing it
+		emailTo, _ := cmd.Flags().GetString("email-to")
+		var out io.Writer = os.Stdout
+		var mail bytes.Buffer
+		var mailTo *netmail.Address
+		if emailTo != "" {
+			if mailTo, err = netmail.ParseAddress(emailTo); err != nil {
+				return fmt.Errorf("--email-to: invalid address %q", emailTo)
+			}
+			out = &mail
+		}
+
+		if opts.noColor || opts.format == "plain" || emailTo != "" {
 			lipgloss.SetColorProfile(termenv.Ascii)
 		}
 
 		cfg, _ := config.Load()
+		numberFormat, _ := cmd.Flags().GetString("number-format")
+		if !cmd.Flags().Changed("number-format") && cfg != nil {
+			numberFormat = cfg.GetNumberFormat()
+		}
+		if opts.numbers, err = reporting.ParseNumberFormat(numberFormat); err != nil {
+			return fmt.Errorf("--number-format: %w", err)
+		}
 
 		now := time.Now()
 		rng, err := resolveReportRange(opts, cfg, now)
@@ -71,54 +172,181 @@ By default, shows a polished overview of what happened during the last night.`,
 			return err
 		}
 
-		runs, err := loadRunReports(reporting.DefaultReportsDir())
+		runs, err := loadRunReports(reportsDir(cfg))
 		if err != nil {
 			return err
 		}
 
+		if strings.EqualFold(opts.reportType, "events") {
+			if len(args) != 1 {
+				return fmt.Errorf("--report events needs a task type, e.g. nightshift report --report events lint-fix")
+			}
+			if emailTo != "" {
+				return fmt.Errorf("--email-to does not apply to --report events")
+			}
+			if !cmd.Flags().Changed("period") && opts.since == "" && opts.until == "" {
+				rng = reportRange{}
+			}
+			if !cmd.Flags().Changed("runs") {
+				opts.runs = 0
+			}
+			return renderReportEvents(os.Stdout, filterReportRuns(runs, rng, opts), args[0], opts.format)
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("unexpected argument %q (only --report events takes a task)", args[0])
+		}
+
 		periodExplicit := cmd.Flags().Changed("period")
 		filtered := filterReportRuns(runs, rng, opts)
 		if len(filtered) == 0 && !periodExplicit && opts.since == "" && opts.until == "" {
 			// Default period returned nothing — fall back to most recent run(s)
 			fallbackRange := reportRange{label: "Last run"}
-			filtered = filterReportRuns(runs, fallbackRange, reportOptions{period: "last-run", runs: opts.runs})
+			filtered = filterReportRuns(runs, fallbackRange, reportOptions{period: "last-run", runs: opts.runs, label: opts.label})
 			if len(filtered) > 0 {
 				rng = fallbackRange
 			}
 		}
 		if len(filtered) == 0 {
-			fmt.Println("No run reports found for the selected period.")
+			_, _ = fmt.Fprintln(out, "No run reports found for the selected period.")
 			if rng.label != "" {
-				fmt.Printf("Period: %s\n", rng.label)
+				_, _ = fmt.Fprintf(out, "Period: %s\n", rng.label)
+			}
+		} else {
+			unredacted := filtered
+			if redact, _ := cmd.Flags().GetBool("redact"); redact {
+				opts.redactor = newReportRedactor(filtered)
+				filtered = opts.redactor.runs(filtered)
+			}
+			var regressions []taskRegression
+			if opts.regressionDelta > 0 {
+				regressions = findRegressions(filtered, runs, opts.regressionDelta)
+			}
+			if err := renderReport(out, filtered, rng, regressions, opts); err != nil {
+				return err
 			}
-			return nil
-		}
 
-		if opts.format == "json" {
-			return renderReportJSON(filtered, rng)
+			// Keep JSON on stdout parseable when paths are printed.
+			var openOut io.Writer = os.Stdout
+			if opts.format == "json" || opts.format == "junit" {
+				openOut = os.Stderr
+			}
+			if open, _ := cmd.Flags().GetBool("open"); open {
+				if err := openReportFiles(openOut, unredacted, opts.reportType, isInteractive()); err != nil {
+					return err
+				}
+			}
+			if openPRs, _ := cmd.Flags().GetBool("open-prs"); openPRs {
+				if err := openReportPRs(openOut, unredacted, isInteractive()); err != nil {
+					return err
+				}
+			}
 		}
 
-		if opts.format == "markdown" {
-			return renderReportMarkdown(filtered)
+		if emailTo != "" {
+			if err := reporting.SendEmail(mailTo, reportEmailSubject(rng, now), reportContentType(opts.format), mail.String()); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--email-to: %w", err)
+			}
+			fmt.Printf("Report emailed to %s\n", emailTo)
 		}
 
-		return renderReportFancy(filtered, rng, opts)
+		if err := checkFailOn(failOn, filtered, opts.numbers); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
 	},
 }
 
 func init() {
-	reportCmd.Flags().StringP("report", "r", "overview", "Report type: overview | tasks | projects | budget | raw")
+	reportCmd.Flags().StringP("report", "r", "overview", "Report type: overview | tasks | projects | budget | raw | events TASK")
 	reportCmd.Flags().StringP("period", "p", "last-night", "Time period: last-night | last-run | last-24h | last-7d | today | yesterday | all")
 	reportCmd.Flags().IntP("runs", "n", 3, "Max runs to include (0 = all)")
 	reportCmd.Flags().String("since", "", "Start time (YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC3339)")
 	reportCmd.Flags().String("until", "", "End time (YYYY-MM-DD, YYYY-MM-DD HH:MM, or RFC3339)")
-	reportCmd.Flags().String("format", "fancy", "Output format: fancy | plain | markdown | json")
+	reportCmd.Flags().String("label", "", "Only include runs tagged with run --label NAME")
+	reportCmd.Flags().String("format", "fancy", "Output format: fancy | plain | markdown | json | junit")
 	reportCmd.Flags().Bool("no-color", false, "Disable ANSI colors")
 	reportCmd.Flags().Bool("paths", false, "Include report/log file paths")
+	reportCmd.Flags().Bool("annotate", false, "List the branch and commits each task produced")
 	reportCmd.Flags().Int("max-items", 5, "Max highlights per run")
+	reportCmd.Flags().String("number-format", "", "Token counts: compact (1.2m) | grouped (1,234,567) | raw (1234567) (default: reporting.number_format)")
+	reportCmd.Flags().Bool("redact", false, "Hide project paths and mask credentials so the report is safe to share")
+	reportCmd.Flags().Bool("open", false, "Open the run report files (raw: in $EDITOR); prints paths when not a TTY")
+	reportCmd.Flags().Bool("open-prs", false, "Open every PR created in the range in the browser; prints URLs when not a TTY")
+	reportCmd.Flags().Bool("highlight-regressions", false, "Flag task types whose success rate in the range fell below their baseline over all retained reports")
+	reportCmd.Flags().Float64("regression-delta", defaultRegressionDelta, "Success-rate drop (0-1) that --highlight-regressions flags")
+	reportCmd.Flags().String("email-to", "", "Email the rendered report to this address via the NIGHTSHIFT_SMTP_* settings instead of printing it")
+	reportCmd.Flags().StringSlice("fail-on", nil, "Exit non-zero after rendering if: failures | low-budget | no-runs (repeatable)")
+
+	reportPruneCmd.Flags().Int("days", 0, "Delete reports older than N days (default: reporting.retention_days)")
+	reportPruneCmd.Flags().Bool("dry-run", false, "Show what would be deleted without removing files")
+	reportCmd.AddCommand(reportPruneCmd)
+
 	rootCmd.AddCommand(reportCmd)
 }
 
+var reportPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old run reports",
+	Long: `Delete run-*.json and run-*.md reports older than the retention window.
+
+Age is taken from the timestamp in the report filename, not file mtime.
+Defaults to reporting.retention_days from config; --days overrides it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if !cmd.Flags().Changed("days") {
+			days = cfg.Reporting.RetentionDays
+			if days == 0 {
+				return fmt.Errorf("no retention configured: pass --days or set reporting.retention_days")
+			}
+		}
+		if days <= 0 {
+			return fmt.Errorf("--days must be > 0")
+		}
+
+		return runReportPrune(reportsDir(cfg), days, dryRun, time.Now())
+	},
+}
+
+func runReportPrune(dir string, days int, dryRun bool, now time.Time) error {
+	cutoff := now.AddDate(0, 0, -days)
+	res, err := reporting.PruneReports(dir, cutoff, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, path := range res.Files {
+			fmt.Printf("  %s\n", path)
+		}
+		fmt.Printf("[dry-run] Would delete %d report file(s), %s\n", len(res.Files), formatBytes(res.Bytes))
+		return nil
+	}
+	fmt.Printf("Deleted %d report file(s) older than %d days, freed %s\n", len(res.Files), days, formatBytes(res.Bytes))
+	return nil
+}
+
+// formatBytes renders a byte count using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func resolveReportRange(opts reportOptions, cfg *config.Config, now time.Time) (reportRange, error) {
 	loc := now.Location()
 	if cfg != nil && cfg.Schedule.Window != nil && cfg.Schedule.Window.Timezone != "" {
@@ -308,8 +536,7 @@ func loadRunReports(dir string) ([]reportRun, error) {
 }
 
 func parseRunTimestamp(base string) (time.Time, error) {
-	ts := strings.TrimPrefix(base, "run-")
-	return time.ParseInLocation("2006-01-02-150405", ts, time.Local)
+	return reporting.ParseRunTimestamp(base)
 }
 
 func filterReportRuns(runs []reportRun, rng reportRange, opts reportOptions) []reportRun {
@@ -322,6 +549,9 @@ func filterReportRuns(runs []reportRun, rng reportRange, opts reportOptions) []r
 		if run.results == nil {
 			continue
 		}
+		if opts.label != "" && run.results.Label != opts.label {
+			continue
+		}
 		if rng.start.IsZero() && rng.end.IsZero() {
 			filtered = append(filtered, run)
 			continue
@@ -360,39 +590,164 @@ func filterReportRuns(runs []reportRun, rng reportRange, opts reportOptions) []r
 	return filtered
 }
 
-func renderReportJSON(runs []reportRun, rng reportRange) error {
+// renderReport writes runs to w in opts.format, with the regressions
+// section when --highlight-regressions is on.
+func renderReport(w io.Writer, runs []reportRun, rng reportRange, regressions []taskRegression, opts reportOptions) error {
+	switch opts.format {
+	case "json":
+		return renderReportJSON(w, runs, rng, regressions)
+	case "junit":
+		return renderReportJUnit(w, runs)
+	case "markdown":
+		if err := renderReportMarkdown(w, runs, opts.annotate); err != nil {
+			return err
+		}
+		if opts.regressionDelta > 0 {
+			_, _ = fmt.Fprint(w, "\n---\n\n"+renderRegressionsMarkdown(regressions, opts.regressionDelta))
+		}
+	default:
+		if err := renderReportFancy(w, runs, rng, opts); err != nil {
+			return err
+		}
+		if opts.regressionDelta > 0 {
+			_, _ = fmt.Fprint(w, "\n"+renderRegressions(newReportStyles(), regressions, opts.regressionDelta))
+		}
+	}
+	return nil
+}
+
+// reportEmailSubject is the subject line for report --email-to.
+func reportEmailSubject(rng reportRange, now time.Time) string {
+	if rng.label != "" {
+		return fmt.Sprintf("Nightshift Report - %s (%s)", rng.label, now.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("Nightshift Report - %s", now.Format("2006-01-02"))
+}
+
+// reportContentType is the MIME type report --email-to sends for format.
+func reportContentType(format string) string {
+	switch format {
+	case "json":
+		return "application/json"
+	case "junit":
+		return "application/xml"
+	case "markdown":
+		return "text/markdown"
+	}
+	return "text/plain"
+}
+
+func renderReportJSON(w io.Writer, runs []reportRun, rng reportRange, regressions []taskRegression) error {
 	type payload struct {
-		Range string                  `json:"range"`
-		Runs  []*reporting.RunResults `json:"runs"`
+		Range       string                  `json:"range"`
+		Runs        []*reporting.RunResults `json:"runs"`
+		Regressions []taskRegression        `json:"regressions,omitempty"`
 	}
 	results := make([]*reporting.RunResults, 0, len(runs))
 	for _, run := range runs {
 		results = append(results, run.results)
 	}
-	out := payload{Range: rng.label, Runs: results}
-	enc := json.NewEncoder(os.Stdout)
+	out := payload{Range: rng.label, Runs: results, Regressions: regressions}
+	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(out)
 }
 
-func renderReportMarkdown(runs []reportRun) error {
+func renderReportMarkdown(w io.Writer, runs []reportRun, annotate bool) error {
 	for i, run := range runs {
 		if run.results == nil {
 			continue
 		}
 		if i > 0 {
-			fmt.Print("\n---\n\n")
+			_, _ = fmt.Fprint(w, "\n---\n\n")
 		}
 		content, err := reporting.RenderRunReport(run.results, run.results.LogPath)
 		if err != nil {
 			return err
 		}
-		fmt.Print(content)
+		_, _ = fmt.Fprint(w, content)
+		if annotate {
+			_, _ = fmt.Fprint(w, renderCommitsMarkdown(run.results))
+		}
 	}
 	return nil
 }
 
-func renderReportFancy(runs []reportRun, rng reportRange, opts reportOptions) error {
+// renderCommitsMarkdown is the report --annotate section for one run: each
+// task's branch and the commits it created.
+func renderCommitsMarkdown(results *reporting.RunResults) string {
+	var b strings.Builder
+	b.WriteString("\n## Commits\n")
+	found := false
+	for _, task := range results.Tasks {
+		lines := taskAnnotationLines(task)
+		if len(lines) == 0 {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&b, "- %s: %s (%s)\n", projectLabel(task.Project), task.Title, task.TaskType)
+		for _, line := range lines {
+			if commit, ok := strings.CutPrefix(line, "  "); ok {
+				fmt.Fprintf(&b, "    - %s\n", commit)
+				continue
+			}
+			fmt.Fprintf(&b, "  - %s\n", line)
+		}
+	}
+	if !found {
+		b.WriteString("No commits recorded.\n")
+	}
+	return b.String()
+}
+
+// taskAnnotationLines describes the branch and commits a task produced for
+// report --annotate: one line per branch, then one per commit, indented.
+// Finished tasks without git data get a single "none recorded" line;
+// skipped and failed ones without data get nothing.
+func taskAnnotationLines(task reporting.TaskResult) []string {
+	if len(task.Commits) == 0 {
+		switch {
+		case task.Branch != "":
+			return []string{"branch: " + task.Branch + " (no commits recorded)"}
+		case task.Status == "completed" || task.Status == "partial":
+			return []string{"commits: none recorded"}
+		}
+		return nil
+	}
+
+	var branches []string
+	byBranch := make(map[string][]reporting.TaskCommit)
+	for _, c := range task.Commits {
+		if _, ok := byBranch[c.Branch]; !ok {
+			branches = append(branches, c.Branch)
+		}
+		byBranch[c.Branch] = append(byBranch[c.Branch], c)
+	}
+
+	var lines []string
+	for _, branch := range branches {
+		commits := byBranch[branch]
+		noun := "commits"
+		if len(commits) == 1 {
+			noun = "commit"
+		}
+		lines = append(lines, fmt.Sprintf("branch: %s · %d %s", branch, len(commits), noun))
+		for _, c := range commits {
+			lines = append(lines, strings.TrimRight("  "+shortSHA(c.SHA)+" "+c.Subject, " "))
+		}
+	}
+	return lines
+}
+
+// shortSHA abbreviates a commit hash to seven characters.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func renderReportFancy(w io.Writer, runs []reportRun, rng reportRange, opts reportOptions) error {
 	styles := newReportStyles()
 	var b strings.Builder
 
@@ -409,11 +764,11 @@ func renderReportFancy(runs []reportRun, rng reportRange, opts reportOptions) er
 	case "overview":
 		b.WriteString(renderReportOverview(styles, runs, opts))
 	case "tasks":
-		b.WriteString(renderReportTasks(styles, runs))
+		b.WriteString(renderReportTasks(styles, runs, opts))
 	case "projects":
 		b.WriteString(renderReportProjects(styles, runs))
 	case "budget":
-		b.WriteString(renderReportBudget(styles, runs))
+		b.WriteString(renderReportBudget(styles, runs, opts.numbers))
 	case "raw":
 		for _, run := range runs {
 			if run.reportPath == "" {
@@ -423,14 +778,14 @@ func renderReportFancy(runs []reportRun, rng reportRange, opts reportOptions) er
 			if err != nil {
 				return err
 			}
-			b.WriteString(string(payload))
+			b.WriteString(opts.redactor.text(string(payload)))
 			b.WriteString("\n")
 		}
 	default:
 		return fmt.Errorf("unknown report type %q", opts.reportType)
 	}
 
-	fmt.Print(b.String())
+	_, _ = fmt.Fprint(w, b.String())
 	return nil
 }
 
@@ -445,6 +800,7 @@ type reportStyles struct {
 	OK       lipgloss.Style
 	Warn     lipgloss.Style
 	Error    lipgloss.Style
+	Partial  lipgloss.Style
 	Card     lipgloss.Style
 	Pill     lipgloss.Style
 }
@@ -461,6 +817,7 @@ func newReportStyles() reportStyles {
 		OK:       lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
 		Warn:     lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
 		Error:    lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		Partial:  lipgloss.NewStyle().Foreground(lipgloss.Color("141")),
 		Card: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			Padding(0, 1).
@@ -477,6 +834,7 @@ type runSummary struct {
 	End             time.Time
 	Duration        time.Duration
 	Completed       int
+	Partial         int
 	Failed          int
 	Skipped         int
 	TokensUsed      int
@@ -512,6 +870,8 @@ func summarizeRun(results *reporting.RunResults) runSummary {
 		switch task.Status {
 		case "completed":
 			summary.Completed++
+		case "partial":
+			summary.Partial++
 		case "failed":
 			summary.Failed++
 			summary.Failures = append(summary.Failures, formatTaskDetail(task))
@@ -542,9 +902,13 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 
 	agg := aggregateRuns(runs)
 
-	// Tasks line: "Tasks: N completed · X% success" with failed/skipped only when > 0
-	total := agg.completed + agg.failed + agg.skipped
+	// Tasks line: "Tasks: N completed · X% success" with partial/failed/skipped
+	// only when > 0. Partials count as half a success.
+	total := agg.completed + agg.partial + agg.failed + agg.skipped
 	tasksLine := fmt.Sprintf("%s %d completed", styles.Label.Render("Tasks:"), agg.completed)
+	if agg.partial > 0 {
+		tasksLine += fmt.Sprintf(" · %s", styles.Partial.Render(fmt.Sprintf("%d partial", agg.partial)))
+	}
 	if agg.failed > 0 {
 		tasksLine += fmt.Sprintf(" · %s", styles.Error.Render(fmt.Sprintf("%d failed", agg.failed)))
 	}
@@ -552,7 +916,7 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 		tasksLine += fmt.Sprintf(" · %s", styles.Warn.Render(fmt.Sprintf("%d skipped", agg.skipped)))
 	}
 	if total > 0 {
-		rate := float64(agg.completed) / float64(total) * 100
+		rate := reporting.SuccessRate(agg.completed, agg.partial, total)
 		tasksLine += fmt.Sprintf(" · %.0f%% success", rate)
 	}
 
@@ -574,8 +938,8 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 	if agg.hasBudget {
 		summaryLines = append(summaryLines, fmt.Sprintf("%s %s used / %s start",
 			styles.Label.Render("Budget:"),
-			formatTokensCompact(agg.tokensUsed),
-			formatTokensCompact(agg.budgetStart),
+			opts.numbers.Tokens(agg.tokensUsed),
+			opts.numbers.Tokens(agg.budgetStart),
 		))
 	}
 	if agg.prCount > 0 {
@@ -600,22 +964,30 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 		}
 		summary := summarizeRun(run.results)
 		header := fmt.Sprintf("Run %d · %s", i+1, formatRunWindow(summary))
+		if run.results.Label != "" {
+			header += fmt.Sprintf(" [%s]", run.results.Label)
+		}
 		b.WriteString(styles.Section.Render(header))
 		b.WriteString("\n")
 
-		runLines := []string{
-			fmt.Sprintf("%s %d completed, %d failed, %d skipped",
-				styles.Label.Render("Tasks:"), summary.Completed, summary.Failed, summary.Skipped),
+		tasksLine := fmt.Sprintf("%s %d completed, %d failed, %d skipped",
+			styles.Label.Render("Tasks:"), summary.Completed, summary.Failed, summary.Skipped)
+		if summary.Partial > 0 {
+			tasksLine = fmt.Sprintf("%s %d completed, %s, %d failed, %d skipped",
+				styles.Label.Render("Tasks:"), summary.Completed,
+				styles.Partial.Render(fmt.Sprintf("%d partial", summary.Partial)),
+				summary.Failed, summary.Skipped)
 		}
+		runLines := []string{tasksLine}
 		if summary.BudgetStart > 0 {
 			runLines = append(runLines, fmt.Sprintf("%s %s used / %s start (%s remaining)",
 				styles.Label.Render("Budget:"),
-				formatTokensCompact(summary.TokensUsed),
-				formatTokensCompact(summary.BudgetStart),
-				formatTokensCompact(summary.BudgetRemaining),
+				opts.numbers.Tokens(summary.TokensUsed),
+				opts.numbers.Tokens(summary.BudgetStart),
+				opts.numbers.Tokens(summary.BudgetRemaining),
 			))
 		} else if summary.TokensUsed > 0 {
-			runLines = append(runLines, fmt.Sprintf("%s %s", styles.Label.Render("Tokens:"), formatTokensCompact(summary.TokensUsed)))
+			runLines = append(runLines, fmt.Sprintf("%s %s", styles.Label.Render("Tokens:"), opts.numbers.Tokens(summary.TokensUsed)))
 		}
 
 		if len(summary.Projects) > 0 {
@@ -625,20 +997,17 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 		b.WriteString(styles.Card.Render(strings.Join(runLines, "\n")))
 		b.WriteString("\n")
 
-		// Build task list grouped by status: completed, failed, skipped
+		// Build task list grouped by status: completed, partial, failed, skipped
 		var ordered []reporting.TaskResult
-		for _, t := range summary.Tasks {
-			if t.Status == "completed" {
-				ordered = append(ordered, t)
-			}
-		}
-		for _, t := range summary.Tasks {
-			if t.Status == "failed" {
-				ordered = append(ordered, t)
+		for _, status := range []string{"completed", "partial", "failed"} {
+			for _, t := range summary.Tasks {
+				if t.Status == status {
+					ordered = append(ordered, t)
+				}
 			}
 		}
 		for _, t := range summary.Tasks {
-			if t.Status != "completed" && t.Status != "failed" {
+			if t.Status != "completed" && t.Status != "partial" && t.Status != "failed" {
 				ordered = append(ordered, t)
 			}
 		}
@@ -655,6 +1024,8 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 			switch task.Status {
 			case "completed":
 				icon = styles.OK.Render("\u2713")
+			case "partial":
+				icon = styles.Partial.Render("\u25d0")
 			case "failed":
 				icon = styles.Error.Render("\u2717")
 			default:
@@ -670,7 +1041,7 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 				line += fmt.Sprintf("  %s", formatDuration(task.Duration))
 			}
 			if task.TokensUsed > 0 {
-				line += fmt.Sprintf("  %s", styles.Muted.Render(formatTokensCompact(task.TokensUsed)+" tok"))
+				line += fmt.Sprintf("  %s", styles.Muted.Render(opts.numbers.Tokens(task.TokensUsed)+" tok"))
 			}
 			if task.OutputRef != "" {
 				line += fmt.Sprintf("  %s", formatOutputRef(styles, task))
@@ -680,6 +1051,11 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 			}
 			b.WriteString(line)
 			b.WriteString("\n")
+			if opts.annotate {
+				for _, note := range taskAnnotationLines(task) {
+					b.WriteString("      " + styles.Muted.Render(note) + "\n")
+				}
+			}
 		}
 		if shown < len(ordered) {
 			b.WriteString(styles.Muted.Render(fmt.Sprintf("  ...and %d more", len(ordered)-shown)))
@@ -688,7 +1064,7 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 
 		if opts.showPaths {
 			if run.reportPath != "" {
-				b.WriteString(styles.Muted.Render(fmt.Sprintf("Report file: %s", run.reportPath)))
+				b.WriteString(styles.Muted.Render(fmt.Sprintf("Report file: %s", opts.redactor.text(run.reportPath))))
 				b.WriteString("\n")
 			}
 			if run.results.LogPath != "" {
@@ -704,62 +1080,87 @@ func renderReportOverview(styles reportStyles, runs []reportRun, opts reportOpti
 
 	// What's Next section
 	b.WriteString("\n")
-	b.WriteString(renderWhatsNext(styles, runs))
+	b.WriteString(renderWhatsNext(styles, runs, opts.numbers))
 
 	return b.String()
 }
 
-// renderWhatsNext generates context-aware action items based on run results.
-func renderWhatsNext(styles reportStyles, runs []reportRun) string {
-	var b strings.Builder
-	var items []string
+// reportSignals are the outcome signals behind What's Next and --fail-on.
+type reportSignals struct {
+	totalTasks      int
+	completed       int
+	partial         int
+	actionable      []reporting.TaskResult // PRs to review, partial and failed tasks, in run order
+	failed          int
+	budgetStart     int
+	budgetRemaining int
+}
 
-	totalCompleted := 0
-	totalTasks := 0
-	var totalBudgetStart, totalBudgetRemaining int
+// budgetLow reports whether remaining budget fell below 20% of the start.
+func (s reportSignals) budgetLow() bool {
+	return s.budgetStart > 0 && s.budgetRemaining < s.budgetStart/5
+}
 
+func collectReportSignals(runs []reportRun) reportSignals {
+	var s reportSignals
 	for _, run := range runs {
 		if run.results == nil {
 			continue
 		}
 		for _, task := range run.results.Tasks {
-			totalTasks++
-			if task.Status == "completed" {
-				totalCompleted++
+			s.totalTasks++
+			switch {
+			case task.Status == "completed":
+				s.completed++
+			case task.Status == "partial":
+				s.partial++
+			case task.Status == "failed":
+				s.failed++
 			}
-
-			// PRs to review
-			if strings.EqualFold(task.OutputType, "pr") && task.OutputRef != "" {
-				ref := task.OutputRef
-				if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
-					ref = termenv.Hyperlink(ref, ref)
-				}
-				items = append(items, styles.Accent.Render(fmt.Sprintf("\u2192 Review PR: %s", ref)))
+			if (reporting.IsReviewRequest(task.OutputType) && task.OutputRef != "") || task.Status == "partial" || task.Status == "failed" {
+				s.actionable = append(s.actionable, task)
 			}
+		}
+		s.budgetStart += run.results.StartBudget
+		s.budgetRemaining += run.results.RemainingBudget
+	}
+	return s
+}
 
-			// Failed tasks
-			if task.Status == "failed" {
-				project := projectLabel(task.Project)
-				detail := task.Title
-				if project != "" {
-					detail += " (" + project + ")"
-				}
-				items = append(items, styles.Error.Render(fmt.Sprintf("\u2192 Investigate failed: %s", detail)))
+// renderWhatsNext generates context-aware action items based on run results.
+func renderWhatsNext(styles reportStyles, runs []reportRun, numbers reporting.NumberFormat) string {
+	var b strings.Builder
+	var items []string
+
+	signals := collectReportSignals(runs)
+	for _, task := range signals.actionable {
+		// PRs and MRs to review
+		if reporting.IsReviewRequest(task.OutputType) && task.OutputRef != "" {
+			ref := task.OutputRef
+			if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+				ref = termenv.Hyperlink(ref, ref)
 			}
+			items = append(items, styles.Accent.Render(fmt.Sprintf("\u2192 Review %s: %s", reporting.ReviewRequestLabel(task.OutputType), ref)))
 		}
 
-		totalBudgetStart += run.results.StartBudget
-		totalBudgetRemaining += run.results.RemainingBudget
+		project := projectLabel(task.Project)
+		detail := task.Title
+		if project != "" {
+			detail += " (" + project + ")"
+		}
+		switch task.Status {
+		case "partial":
+			items = append(items, styles.Partial.Render(fmt.Sprintf("\u2192 Finish partial: %s", detail)))
+		case "failed":
+			items = append(items, styles.Error.Render(fmt.Sprintf("\u2192 Investigate failed: %s", detail)))
+		}
 	}
 
 	// Budget warning: remaining < 20% of start
-	if totalBudgetStart > 0 {
-		threshold := totalBudgetStart / 5
-		if totalBudgetRemaining < threshold {
-			items = append(items, styles.Warn.Render(fmt.Sprintf("\u2192 Budget low: %s remaining of %s start",
-				formatTokensCompact(totalBudgetRemaining),
-				formatTokensCompact(totalBudgetStart))))
-		}
+	if signals.budgetLow() {
+		items = append(items, styles.Warn.Render(fmt.Sprintf("\u2192 Budget low: %s remaining of %s start",
+			numbers.Tokens(signals.budgetRemaining),
+			numbers.Tokens(signals.budgetStart))))
 	}
 
 	b.WriteString(styles.Section.Render("What's Next"))
@@ -767,10 +1168,10 @@ func renderWhatsNext(styles reportStyles, runs []reportRun) string {
 
 	if len(items) == 0 {
 		taskWord := "tasks"
-		if totalCompleted == 1 {
+		if signals.completed == 1 {
 			taskWord = "task"
 		}
-		b.WriteString(styles.OK.Render(fmt.Sprintf("  \u2713 All %d %s completed successfully", totalCompleted, taskWord)))
+		b.WriteString(styles.OK.Render(fmt.Sprintf("  \u2713 All %d %s completed successfully", signals.completed, taskWord)))
 		b.WriteString("\n")
 	} else {
 		for _, item := range items {
@@ -781,7 +1182,52 @@ func renderWhatsNext(styles reportStyles, runs []reportRun) string {
 	return b.String()
 }
 
-func renderReportTasks(styles reportStyles, runs []reportRun) string {
+// Conditions accepted by report --fail-on.
+const (
+	failOnFailures  = "failures"
+	failOnLowBudget = "low-budget"
+	failOnNoRuns    = "no-runs"
+)
+
+// parseFailOn validates --fail-on values.
+func parseFailOn(values []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		switch v {
+		case "":
+			continue
+		case failOnFailures, failOnLowBudget, failOnNoRuns:
+			out[v] = true
+		default:
+			return nil, fmt.Errorf("unknown --fail-on condition %q (use failures, low-budget, or no-runs)", v)
+		}
+	}
+	return out, nil
+}
+
+// checkFailOn returns an error naming every requested condition that the
+// runs trip, or nil when none do.
+func checkFailOn(failOn map[string]bool, runs []reportRun, numbers reporting.NumberFormat) error {
+	var reasons []string
+	if failOn[failOnNoRuns] && len(runs) == 0 {
+		reasons = append(reasons, "no runs found")
+	}
+	signals := collectReportSignals(runs)
+	if failOn[failOnFailures] && signals.failed > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d failed task(s)", signals.failed))
+	}
+	if failOn[failOnLowBudget] && signals.budgetLow() {
+		reasons = append(reasons, fmt.Sprintf("budget low (%s remaining of %s)",
+			numbers.Tokens(signals.budgetRemaining), numbers.Tokens(signals.budgetStart)))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("--fail-on: %s", strings.Join(reasons, "; "))
+}
+
+func renderReportTasks(styles reportStyles, runs []reportRun, opts reportOptions) string {
 	var b strings.Builder
 	for i, run := range runs {
 		if run.results == nil {
@@ -799,8 +1245,11 @@ func renderReportTasks(styles reportStyles, runs []reportRun) string {
 			if project != "" {
 				line += fmt.Sprintf(" · %s", project)
 			}
+			if task.Provider != "" {
+				line += fmt.Sprintf(" · via %s", task.Provider)
+			}
 			if task.TokensUsed > 0 {
-				line += fmt.Sprintf(" · %s tokens", formatTokensCompact(task.TokensUsed))
+				line += fmt.Sprintf(" · %s tokens", opts.numbers.Tokens(task.TokensUsed))
 			}
 			if task.Duration > 0 {
 				line += fmt.Sprintf(" · %s", formatDuration(task.Duration))
@@ -809,6 +1258,19 @@ func renderReportTasks(styles reportStyles, runs []reportRun) string {
 				line += fmt.Sprintf(" · %s", task.SkipReason)
 			}
 			b.WriteString("  " + line + "\n")
+			if opts.annotate {
+				for _, note := range taskAnnotationLines(task) {
+					b.WriteString("    " + styles.Muted.Render(note) + "\n")
+				}
+			} else if task.Branch != "" {
+				b.WriteString("    " + styles.Muted.Render("branch: "+task.Branch) + "\n")
+			}
+			for _, artifact := range task.Artifacts {
+				b.WriteString("    " + styles.Muted.Render("artifact: "+artifact) + "\n")
+			}
+			if task.EventLog != "" && task.Status != "completed" {
+				b.WriteString("    " + styles.Muted.Render("events: "+task.EventLog) + "\n")
+			}
 		}
 
 		if i < len(runs)-1 {
@@ -821,6 +1283,7 @@ func renderReportTasks(styles reportStyles, runs []reportRun) string {
 func renderReportProjects(styles reportStyles, runs []reportRun) string {
 	projectTotals := make(map[string]struct {
 		completed int
+		partial   int
 		failed    int
 		skipped   int
 	})
@@ -838,6 +1301,8 @@ func renderReportProjects(styles reportStyles, runs []reportRun) string {
 			switch task.Status {
 			case "completed":
 				entry.completed++
+			case "partial":
+				entry.partial++
 			case "failed":
 				entry.failed++
 			case "skipped":
@@ -850,6 +1315,7 @@ func renderReportProjects(styles reportStyles, runs []reportRun) string {
 	type projectRow struct {
 		name      string
 		completed int
+		partial   int
 		failed    int
 		skipped   int
 		total     int
@@ -859,9 +1325,10 @@ func renderReportProjects(styles reportStyles, runs []reportRun) string {
 		rows = append(rows, projectRow{
 			name:      name,
 			completed: entry.completed,
+			partial:   entry.partial,
 			failed:    entry.failed,
 			skipped:   entry.skipped,
-			total:     entry.completed + entry.failed + entry.skipped,
+			total:     entry.completed + entry.partial + entry.failed + entry.skipped,
 		})
 	}
 
@@ -877,12 +1344,18 @@ func renderReportProjects(styles reportStyles, runs []reportRun) string {
 			styles.Accent.Render(row.name),
 			row.total, row.completed, row.failed, row.skipped,
 		)
+		if row.partial > 0 {
+			line = fmt.Sprintf("%s %d total (%d completed, %s, %d failed, %d skipped)",
+				styles.Accent.Render(row.name),
+				row.total, row.completed, styles.Partial.Render(fmt.Sprintf("%d partial", row.partial)), row.failed, row.skipped,
+			)
+		}
 		b.WriteString("  " + line + "\n")
 	}
 	return b.String()
 }
 
-func renderReportBudget(styles reportStyles, runs []reportRun) string {
+func renderReportBudget(styles reportStyles, runs []reportRun, numbers reporting.NumberFormat) string {
 	var b strings.Builder
 	b.WriteString(styles.Section.Render("Budget"))
 	b.WriteString("\n")
@@ -898,16 +1371,23 @@ func renderReportBudget(styles reportStyles, runs []reportRun) string {
 		if summary.BudgetStart > 0 {
 			b.WriteString(fmt.Sprintf("  %s %s used / %s start (%s remaining)\n",
 				styles.Label.Render("Budget:"),
-				formatTokensCompact(summary.TokensUsed),
-				formatTokensCompact(summary.BudgetStart),
-				formatTokensCompact(summary.BudgetRemaining),
+				numbers.Tokens(summary.TokensUsed),
+				numbers.Tokens(summary.BudgetStart),
+				numbers.Tokens(summary.BudgetRemaining),
 			))
 		} else if summary.TokensUsed > 0 {
-			b.WriteString(fmt.Sprintf("  %s %s\n", styles.Label.Render("Tokens:"), formatTokensCompact(summary.TokensUsed)))
+			b.WriteString(fmt.Sprintf("  %s %s\n", styles.Label.Render("Tokens:"), numbers.Tokens(summary.TokensUsed)))
 		} else {
 			b.WriteString("  No budget data recorded\n")
 		}
 
+		for _, snap := range run.results.ProviderSnapshots {
+			b.WriteString(fmt.Sprintf("  %s %s\n",
+				styles.Label.Render(snap.Provider+":"),
+				reporting.FormatProviderSnapshot(snap),
+			))
+		}
+
 		if i < len(runs)-1 {
 			b.WriteString("\n")
 		}
@@ -917,6 +1397,7 @@ func renderReportBudget(styles reportStyles, runs []reportRun) string {
 
 type aggregateSummary struct {
 	completed     int
+	partial       int
 	failed        int
 	skipped       int
 	tokensUsed    int
@@ -940,6 +1421,7 @@ func aggregateRuns(runs []reportRun) aggregateSummary {
 		}
 		summary := summarizeRun(run.results)
 		agg.completed += summary.Completed
+		agg.partial += summary.Partial
 		agg.failed += summary.Failed
 		agg.skipped += summary.Skipped
 		agg.tokensUsed += summary.TokensUsed
@@ -949,7 +1431,7 @@ func aggregateRuns(runs []reportRun) aggregateSummary {
 			agg.hasBudget = true
 		}
 		for _, task := range run.results.Tasks {
-			if strings.EqualFold(task.OutputType, "pr") && task.OutputRef != "" {
+			if reporting.IsReviewRequest(task.OutputType) && task.OutputRef != "" {
 				agg.prCount++
 			}
 		}
@@ -999,6 +1481,8 @@ func formatTaskStatus(styles reportStyles, status string) string {
 	switch status {
 	case "completed":
 		return styles.OK.Render("OK")
+	case "partial":
+		return styles.Partial.Render("PART")
 	case "failed":
 		return styles.Error.Render("FAIL")
 	case "skipped":
@@ -1035,7 +1519,7 @@ func formatProjectSummary(projects map[string]int) string {
 }
 
 // formatOutputRef formats a task's OutputRef for display in the report.
-// PR-type outputs get bold accent styling and a "-> PR:" prefix.
+// PR and MR outputs get bold accent styling and a "-> PR:" or "-> MR:" prefix.
 // Other typed outputs show "-> {Type}: {Ref}".
 // Untyped outputs show "-> {Ref}".
 func formatOutputRef(styles reportStyles, task reporting.TaskResult) string {
@@ -1045,16 +1529,15 @@ func formatOutputRef(styles reportStyles, task reporting.TaskResult) string {
 
 	ref := task.OutputRef
 	outputType := strings.TrimSpace(task.OutputType)
-	isPR := strings.EqualFold(outputType, "pr")
 
-	// For PR links, use bold accent and wrap as terminal hyperlink if it's a URL
-	if isPR {
+	// For PR/MR links, use bold accent and wrap as terminal hyperlink if it's a URL
+	if reporting.IsReviewRequest(outputType) {
 		display := ref
 		if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
 			display = termenv.Hyperlink(ref, ref)
 		}
 		prStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("81"))
-		return prStyle.Render("\u2192 PR: " + display)
+		return prStyle.Render("\u2192 " + reporting.ReviewRequestLabel(outputType) + ": " + display)
 	}
 
 	// Typed non-PR output
@@ -1067,25 +1550,15 @@ func formatOutputRef(styles reportStyles, task reporting.TaskResult) string {
 }
 
 func formatTaskDetail(task reporting.TaskResult) string {
+	if len(task.DeniedPaths) > 0 {
+		return fmt.Sprintf("%s (denied paths: %s)", task.Title, strings.Join(task.DeniedPaths, ", "))
+	}
 	if task.SkipReason != "" {
 		return fmt.Sprintf("%s (%s)", task.Title, task.SkipReason)
 	}
 	return task.Title
 }
 
-func formatTokensCompact(tokens int) string {
-	switch {
-	case tokens >= 1_000_000:
-		return fmt.Sprintf("%.1fm", float64(tokens)/1_000_000)
-	case tokens >= 10_000:
-		return fmt.Sprintf("%.0fk", float64(tokens)/1_000)
-	case tokens >= 1_000:
-		return fmt.Sprintf("%.1fk", float64(tokens)/1_000)
-	default:
-		return fmt.Sprintf("%d", tokens)
-	}
-}
-
 func formatTimeShort(t time.Time) string {
 	if t.IsZero() {
 		return "unknown"
@@ -1093,7 +1566,33 @@ func formatTimeShort(t time.Time) string {
 	return t.Format("2006-01-02 15:04")
 }
 
+// parseRunReportMarkdown parses a markdown run report. Summary fields come
+// from the YAML front-matter when present; older reports without it fall
+// back to scraping the summary lines. Tasks are always read from the body.
 func parseRunReportMarkdown(content string) (*reporting.RunResults, error) {
+	meta, body, err := reporting.SplitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+	results := parseRunReportBody(body)
+	if meta != nil {
+		results.StartTime = meta.Start
+		results.Date = meta.Start
+		results.EndTime = meta.End
+		results.StartBudget = meta.StartBudget
+		results.UsedBudget = meta.UsedBudget
+		results.RemainingBudget = meta.RemainingBudget
+		results.PRTargetBranch = meta.PRTargetBranch
+		results.Label = meta.Label
+		results.LogPath = meta.LogPath
+		results.Notes = meta.Notes
+	}
+	return results, nil
+}
+
+// parseRunReportBody scrapes summary lines and task sections from the
+// markdown body of a run report.
+func parseRunReportBody(content string) *reporting.RunResults {
 	results := &reporting.RunResults{
 		Tasks: []reporting.TaskResult{},
 	}
@@ -1126,6 +1625,18 @@ func parseRunReportMarkdown(content string) (*reporting.RunResults, error) {
 			parseBudgetLine(results, budget)
 			continue
 		}
+		if strings.HasPrefix(line, "- Note: ") {
+			results.Notes = append(results.Notes, strings.TrimPrefix(line, "- Note: "))
+			continue
+		}
+		if strings.HasPrefix(line, "- PR target: ") {
+			results.PRTargetBranch = strings.TrimPrefix(line, "- PR target: ")
+			continue
+		}
+		if strings.HasPrefix(line, "- Label: ") {
+			results.Label = strings.TrimPrefix(line, "- Label: ")
+			continue
+		}
 		if strings.HasPrefix(line, "- Logs: ") {
 			results.LogPath = strings.TrimPrefix(line, "- Logs: ")
 			continue
@@ -1134,6 +1645,8 @@ func parseRunReportMarkdown(content string) (*reporting.RunResults, error) {
 			switch strings.TrimPrefix(line, "## ") {
 			case "Tasks Completed":
 				section = "completed"
+			case "Tasks Partially Completed":
+				section = "partial"
 			case "Tasks Failed":
 				section = "failed"
 			case "Tasks Skipped":
@@ -1148,11 +1661,11 @@ func parseRunReportMarkdown(content string) (*reporting.RunResults, error) {
 			results.Tasks = append(results.Tasks, task)
 		}
 	}
-	return results, nil
+	return results
 }
 
 func parseBudgetLine(results *reporting.RunResults, budget string) {
-	parts := strings.Split(budget, ",")
+	parts := strings.Split(budget, ", ")
 	if len(parts) < 3 {
 		return
 	}
@@ -1188,8 +1701,20 @@ func parseTaskLine(line string, status string) reporting.TaskResult {
 			task.TokensUsed = parseTokenString(strings.TrimSuffix(part, " tokens"))
 		case strings.HasPrefix(part, "output: "):
 			task.OutputRef = strings.TrimPrefix(part, "output: ")
+		case strings.HasPrefix(part, "denied paths: "):
+			task.DeniedPaths = strings.Split(strings.TrimPrefix(part, "denied paths: "), ", ")
+		case strings.HasPrefix(part, "provider: "):
+			task.Provider = strings.TrimPrefix(part, "provider: ")
+		case strings.HasPrefix(part, "branch: "):
+			task.Branch = strings.TrimPrefix(part, "branch: ")
+		case strings.HasPrefix(part, "artifact: "):
+			task.Artifacts = append(task.Artifacts, strings.TrimPrefix(part, "artifact: "))
+		case strings.HasPrefix(part, "events: "):
+			task.EventLog = strings.TrimPrefix(part, "events: ")
 		case strings.HasPrefix(part, "Skip reason: "):
 			task.SkipReason = strings.TrimPrefix(part, "Skip reason: ")
+		case strings.HasPrefix(part, "Reason: "):
+			task.SkipReason = strings.TrimPrefix(part, "Reason: ")
 		default:
 			if d, err := parseDurationShort(part); err == nil {
 				task.Duration = d
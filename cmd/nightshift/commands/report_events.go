@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+// findEventLogTask returns the newest task in runs (newest first) whose type
+// or title matches name and that recorded an event log.
+func findEventLogTask(runs []reportRun, name string) (reportRun, reporting.TaskResult, error) {
+	matched := false
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		for i := len(run.results.Tasks) - 1; i >= 0; i-- {
+			task := run.results.Tasks[i]
+			if task.TaskType != name && !strings.EqualFold(task.Title, name) {
+				continue
+			}
+			matched = true
+			if task.EventLog != "" {
+				return run, task, nil
+			}
+		}
+	}
+	if matched {
+		return reportRun{}, reporting.TaskResult{}, fmt.Errorf("no event log recorded for task %q", name)
+	}
+	return reportRun{}, reporting.TaskResult{}, fmt.Errorf("task %q not found in run reports", name)
+}
+
+// renderReportEvents replays the event log of the newest run of task name.
+func renderReportEvents(w io.Writer, runs []reportRun, name, format string) error {
+	run, task, err := findEventLogTask(runs, name)
+	if err != nil {
+		return err
+	}
+	records, err := orchestrator.ReadEventLog(task.EventLog)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	_, _ = fmt.Fprintf(w, "Events: %s (%s)\n", task.TaskType, task.Project)
+	_, _ = fmt.Fprintf(w, "Run:    %s, %s\n", formatTimeShort(run.results.StartTime), task.Status)
+	_, _ = fmt.Fprintf(w, "Log:    %s\n\n", task.EventLog)
+	for _, rec := range records {
+		_, _ = fmt.Fprintf(w, "%s  %s\n", rec.Time.Local().Format("15:04:05"), describeEvent(rec))
+	}
+	return nil
+}
+
+// describeEvent renders one event log record as a replay line.
+func describeEvent(rec orchestrator.EventRecord) string {
+	var line string
+	switch rec.Type {
+	case "task_start":
+		line = "task start: " + rec.TaskTitle
+	case "phase_start":
+		line = string(rec.Phase) + " start"
+	case "phase_end":
+		if rec.Error != "" {
+			line = fmt.Sprintf("%s failed after %s: %s", rec.Phase, formatDuration(rec.Duration), rec.Error)
+		} else {
+			line = fmt.Sprintf("%s done in %s", rec.Phase, formatDuration(rec.Duration))
+		}
+	case "iteration_start":
+		line = fmt.Sprintf("iteration %d/%d", rec.Iteration, rec.MaxIter)
+	case "log":
+		line = rec.Level + ": " + rec.Message
+		if len(rec.Fields) > 0 {
+			keys := make([]string, 0, len(rec.Fields))
+			for k := range rec.Fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				line += fmt.Sprintf(" %s=%v", k, rec.Fields[k])
+			}
+		}
+	case "task_end":
+		line = fmt.Sprintf("task %s in %s", rec.Status, formatDuration(rec.Duration))
+		if rec.Error != "" {
+			line += ": " + rec.Error
+		}
+	default:
+		line = rec.Type
+		if rec.Message != "" {
+			line += ": " + rec.Message
+		}
+	}
+	return line
+}
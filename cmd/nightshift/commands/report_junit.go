@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+// JUnit XML, as read by CI test-result viewers: one testsuite per run and
+// one testcase per task.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// renderReportJUnit writes runs as JUnit XML. Failed and abandoned tasks
+// are failures and skipped tasks are skipped, both with the skip reason as
+// the message; completed and partial tasks pass.
+func renderReportJUnit(w io.Writer, runs []reportRun) error {
+	out := junitTestSuites{Name: "nightshift"}
+	var total time.Duration
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		suite := junitSuite(run.results)
+		out.Suites = append(out.Suites, suite)
+		out.Tests += suite.Tests
+		out.Failures += suite.Failures
+		out.Skipped += suite.Skipped
+		total += summarizeRun(run.results).Duration
+	}
+	out.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("encoding junit: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuite maps one run to a testsuite.
+func junitSuite(results *reporting.RunResults) junitTestSuite {
+	summary := summarizeRun(results)
+	suite := junitTestSuite{
+		Name:    "nightshift run",
+		Tests:   len(summary.Tasks),
+		Skipped: summary.Skipped,
+		Time:    junitSeconds(summary.Duration),
+	}
+	if !summary.Start.IsZero() {
+		suite.Name = "nightshift run " + summary.Start.Format("2006-01-02 15:04")
+		suite.Timestamp = summary.Start.Format("2006-01-02T15:04:05")
+	}
+	if results.Label != "" {
+		suite.Properties = append(suite.Properties, junitProperty{Name: "label", Value: results.Label})
+	}
+	suite.Properties = append(suite.Properties,
+		junitProperty{Name: "tokens_used", Value: fmt.Sprint(summary.TokensUsed)},
+		junitProperty{Name: "budget_remaining", Value: fmt.Sprint(summary.BudgetRemaining)},
+	)
+
+	for _, task := range summary.Tasks {
+		tc := junitTestCase{
+			ClassName: task.TaskType,
+			Name:      task.Title,
+			Time:      junitSeconds(task.Duration),
+		}
+		if tc.Name == "" {
+			tc.Name = task.TaskType
+		}
+		switch task.Status {
+		case "failed", "abandoned":
+			tc.Failure = &junitMessage{Message: task.SkipReason, Type: task.Status}
+			suite.Failures++
+		case "skipped":
+			tc.Skipped = &junitMessage{Message: task.SkipReason}
+		}
+		tc.SystemOut = junitTaskOutput(task)
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return suite
+}
+
+// junitTaskOutput lists the task's project, status and output as
+// system-out lines.
+func junitTaskOutput(task reporting.TaskResult) string {
+	var lines []string
+	if task.Project != "" {
+		lines = append(lines, "project: "+task.Project)
+	}
+	lines = append(lines, "status: "+task.Status)
+	if task.Provider != "" {
+		lines = append(lines, "provider: "+task.Provider)
+	}
+	if task.OutputRef != "" {
+		lines = append(lines, strings.TrimSpace("output: "+task.OutputType+" "+task.OutputRef))
+	}
+	if task.Status == "partial" && task.SkipReason != "" {
+		lines = append(lines, "note: "+task.SkipReason)
+	}
+	if task.TokensUsed > 0 {
+		lines = append(lines, fmt.Sprintf("tokens: %d", task.TokensUsed))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// junitSeconds formats d as JUnit's decimal seconds.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
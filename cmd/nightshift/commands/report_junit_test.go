@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+func TestRenderReportJUnit(t *testing.T) {
+	start := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+	runs := []reportRun{{results: &reporting.RunResults{
+		StartTime: start,
+		EndTime:   start.Add(90 * time.Minute),
+		Label:     "nightly",
+		Tasks: []reporting.TaskResult{
+			{Project: "/src/api", TaskType: "lint-fix", Title: "Linter Fixes", Status: "completed", OutputType: "PR", OutputRef: "#12", Duration: 10 * time.Minute},
+			{Project: "/src/api", TaskType: "docs-backfill", Title: "Docs Backfill", Status: "failed", SkipReason: "agent timed out", Duration: 30 * time.Second},
+			{Project: "/src/web", TaskType: "test-gap", Title: "Test Gap", Status: "abandoned", SkipReason: "max iterations (3) reached"},
+			{Project: "/src/web", TaskType: "dead-code", Title: "Dead Code", Status: "skipped", SkipReason: "insufficient budget"},
+			{Project: "/src/web", TaskType: "todo-sweep", Status: "partial", SkipReason: "review not approved"},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	if err := renderReportJUnit(&buf, runs); err != nil {
+		t.Fatalf("renderReportJUnit() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("output missing XML header:\n%s", buf.String())
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if got.Tests != 5 || got.Failures != 2 || got.Skipped != 1 || got.Time != "5400.000" {
+		t.Errorf("testsuites = tests %d, failures %d, skipped %d, time %s; want 5, 2, 1, 5400.000",
+			got.Tests, got.Failures, got.Skipped, got.Time)
+	}
+	if len(got.Suites) != 1 {
+		t.Fatalf("got %d testsuites, want 1", len(got.Suites))
+	}
+	suite := got.Suites[0]
+	if suite.Name != "nightshift run 2026-03-04 02:00" || suite.Timestamp != "2026-03-04T02:00:00" {
+		t.Errorf("testsuite name/timestamp = %q/%q", suite.Name, suite.Timestamp)
+	}
+	if len(suite.Properties) == 0 || suite.Properties[0] != (junitProperty{Name: "label", Value: "nightly"}) {
+		t.Errorf("testsuite properties = %+v, want label first", suite.Properties)
+	}
+
+	cases := suite.Cases
+	if len(cases) != 5 {
+		t.Fatalf("got %d testcases, want 5", len(cases))
+	}
+	if cases[0].ClassName != "lint-fix" || cases[0].Name != "Linter Fixes" || cases[0].Time != "600.000" || cases[0].Failure != nil {
+		t.Errorf("completed testcase = %+v", cases[0])
+	}
+	if !strings.Contains(cases[0].SystemOut, "output: PR #12") {
+		t.Errorf("completed testcase system-out = %q, want the PR", cases[0].SystemOut)
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Message != "agent timed out" {
+		t.Errorf("failed testcase failure = %+v", cases[1].Failure)
+	}
+	if cases[2].Failure == nil || cases[2].Failure.Type != "abandoned" {
+		t.Errorf("abandoned testcase failure = %+v", cases[2].Failure)
+	}
+	if cases[3].Skipped == nil || cases[3].Skipped.Message != "insufficient budget" {
+		t.Errorf("skipped testcase = %+v", cases[3].Skipped)
+	}
+	if cases[4].Name != "todo-sweep" || cases[4].Failure != nil || cases[4].Skipped != nil {
+		t.Errorf("partial testcase = %+v, want a passing case named after its type", cases[4])
+	}
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+// startOpener launches a detached opener process. Override in tests.
+var startOpener = func(name string, args ...string) error {
+	return exec.Command(name, args...).Start()
+}
+
+// runEditor runs $EDITOR attached to the terminal. Override in tests.
+var runEditor = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// openerCommand returns the command that opens target with the OS default
+// application.
+func openerCommand(goos, target string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{target}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", target}
+	default:
+		return "xdg-open", []string{target}
+	}
+}
+
+// reportFilePaths returns the markdown report files behind runs, newest
+// first, skipping runs loaded without one.
+func reportFilePaths(runs []reportRun) []string {
+	var paths []string
+	for _, run := range runs {
+		if run.reportPath != "" {
+			paths = append(paths, run.reportPath)
+		}
+	}
+	return paths
+}
+
+// reportPRURLs returns the distinct PR/MR URLs created in runs. Reports
+// parsed from markdown carry no output type, so any http(s) ref counts.
+func reportPRURLs(runs []reportRun) []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		for _, task := range run.results.Tasks {
+			ref := task.OutputRef
+			if !strings.HasPrefix(ref, "https://") && !strings.HasPrefix(ref, "http://") {
+				continue
+			}
+			if task.OutputType != "" && !reporting.IsReviewRequest(task.OutputType) {
+				continue
+			}
+			if !seen[ref] {
+				seen[ref] = true
+				urls = append(urls, ref)
+			}
+		}
+	}
+	return urls
+}
+
+// openReportFiles opens the report files for --open. The raw report goes
+// to $EDITOR when set, since it is the file itself; other report types use
+// the OS opener. Without a terminal the paths are printed instead.
+func openReportFiles(w io.Writer, runs []reportRun, reportType string, interactive bool) error {
+	paths := reportFilePaths(runs)
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintln(w, "No report files to open.")
+		return nil
+	}
+	if !interactive {
+		for _, path := range paths {
+			_, _ = fmt.Fprintln(w, path)
+		}
+		return nil
+	}
+	if editor := strings.Fields(os.Getenv("EDITOR")); reportType == "raw" && len(editor) > 0 {
+		if err := runEditor(editor[0], append(editor[1:], paths...)...); err != nil {
+			return fmt.Errorf("opening report in $EDITOR: %w", err)
+		}
+		return nil
+	}
+	return openTargets(paths)
+}
+
+// openReportPRs opens every PR URL in runs for --open-prs, or prints them
+// without a terminal.
+func openReportPRs(w io.Writer, runs []reportRun, interactive bool) error {
+	urls := reportPRURLs(runs)
+	if len(urls) == 0 {
+		_, _ = fmt.Fprintln(w, "No PRs to open.")
+		return nil
+	}
+	if !interactive {
+		for _, url := range urls {
+			_, _ = fmt.Fprintln(w, url)
+		}
+		return nil
+	}
+	return openTargets(urls)
+}
+
+func openTargets(targets []string) error {
+	for _, target := range targets {
+		name, args := openerCommand(runtime.GOOS, target)
+		if err := startOpener(name, args...); err != nil {
+			return fmt.Errorf("opening %s with %s: %w", target, name, err)
+		}
+	}
+	return nil
+}
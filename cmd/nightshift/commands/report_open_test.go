@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+func TestOpenerCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+	}{
+		{"linux", "xdg-open"},
+		{"freebsd", "xdg-open"},
+		{"darwin", "open"},
+		{"windows", "rundll32"},
+	}
+	for _, tt := range tests {
+		name, args := openerCommand(tt.goos, "/tmp/r.md")
+		if name != tt.wantName || args[len(args)-1] != "/tmp/r.md" {
+			t.Errorf("openerCommand(%q) = %s %v", tt.goos, name, args)
+		}
+	}
+}
+
+func openTestRuns() []reportRun {
+	return []reportRun{
+		{reportPath: "/reports/run-2.md", results: &reporting.RunResults{Tasks: []reporting.TaskResult{
+			{OutputType: "PR", OutputRef: "https://github.com/o/r/pull/2"},
+			{OutputType: "Report", OutputRef: "https://example.com/report"},
+			{OutputRef: "https://gitlab.com/o/r/-/merge_requests/3"},
+			{OutputType: "PR", OutputRef: "42"},
+		}}},
+		{results: &reporting.RunResults{Tasks: []reporting.TaskResult{
+			{OutputType: "PR", OutputRef: "https://github.com/o/r/pull/2"},
+		}}},
+		{reportPath: "/reports/run-1.md"},
+	}
+}
+
+func TestReportPRURLs(t *testing.T) {
+	got := strings.Join(reportPRURLs(openTestRuns()), ",")
+	want := "https://github.com/o/r/pull/2,https://gitlab.com/o/r/-/merge_requests/3"
+	if got != want {
+		t.Errorf("reportPRURLs = %q, want %q", got, want)
+	}
+}
+
+func TestOpenReport(t *testing.T) {
+	var opened, edited []string
+	origOpener, origEditor := startOpener, runEditor
+	t.Cleanup(func() { startOpener, runEditor = origOpener, origEditor })
+	startOpener = func(name string, args ...string) error {
+		opened = append(opened, args[len(args)-1])
+		return nil
+	}
+	runEditor = func(name string, args ...string) error {
+		edited = append(append(edited, name), args...)
+		return nil
+	}
+	runs := openTestRuns()
+
+	t.Run("non-TTY prints", func(t *testing.T) {
+		opened = nil
+		var out bytes.Buffer
+		if err := openReportFiles(&out, runs, "overview", false); err != nil {
+			t.Fatal(err)
+		}
+		if err := openReportPRs(&out, runs, false); err != nil {
+			t.Fatal(err)
+		}
+		want := "/reports/run-2.md\n/reports/run-1.md\nhttps://github.com/o/r/pull/2\nhttps://gitlab.com/o/r/-/merge_requests/3\n"
+		if out.String() != want {
+			t.Errorf("output = %q, want %q", out.String(), want)
+		}
+		if len(opened) != 0 {
+			t.Errorf("opened %v without a TTY", opened)
+		}
+	})
+
+	t.Run("TTY opens", func(t *testing.T) {
+		opened = nil
+		t.Setenv("EDITOR", "vim -R")
+		var out bytes.Buffer
+		if err := openReportFiles(&out, runs, "overview", true); err != nil {
+			t.Fatal(err)
+		}
+		if err := openReportPRs(&out, runs, true); err != nil {
+			t.Fatal(err)
+		}
+		if len(opened) != 4 || out.Len() != 0 {
+			t.Errorf("opened = %v, output = %q", opened, out.String())
+		}
+	})
+
+	t.Run("raw uses EDITOR", func(t *testing.T) {
+		opened, edited = nil, nil
+		t.Setenv("EDITOR", "vim -R")
+		if err := openReportFiles(&bytes.Buffer{}, runs, "raw", true); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(edited, " "); got != "vim -R /reports/run-2.md /reports/run-1.md" || len(opened) != 0 {
+			t.Errorf("edited = %q, opened = %v", got, opened)
+		}
+	})
+
+	t.Run("nothing to open", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := openReportPRs(&out, []reportRun{{}}, true); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "No PRs") {
+			t.Errorf("output = %q", out.String())
+		}
+	})
+}
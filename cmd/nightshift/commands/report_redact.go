@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+const redactedMarker = "[REDACTED]"
+
+// secretPatterns match common credential formats that may appear in skip
+// reasons, notes, or output refs.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-(?:ant-)?[A-Za-z0-9_-]{16,}`),       // Anthropic / OpenAI API keys
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{20,}`),           // GitHub tokens
+	regexp.MustCompile(`github_pat_[A-Za-z0-9_]{20,}`),         // GitHub fine-grained tokens
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9-]{10,}`),         // Slack tokens
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),                     // AWS access key IDs
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/-]{8,}=*`), // Authorization headers
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s)"'<>]+`)
+
+// credentialParams are query-string keys dropped from URLs.
+var credentialParams = []string{"token", "key", "secret", "password", "passwd", "auth", "sig", "signature", "credential", "session"}
+
+// reportRedactor scrubs project paths and credentials from reports so they
+// can be attached to issues. A nil redactor leaves text unchanged.
+type reportRedactor struct {
+	home     string
+	projects []string // absolute project paths, longest first
+}
+
+// newReportRedactor collects the project paths to hide from runs.
+func newReportRedactor(runs []reportRun) *reportRedactor {
+	r := &reportRedactor{}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" {
+		r.home = home
+	}
+	seen := map[string]bool{}
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		for _, task := range run.results.Tasks {
+			if filepath.IsAbs(task.Project) && !seen[task.Project] {
+				seen[task.Project] = true
+				r.projects = append(r.projects, task.Project)
+			}
+		}
+	}
+	// Replace nested paths before their parents.
+	sort.Slice(r.projects, func(i, j int) bool { return len(r.projects[i]) > len(r.projects[j]) })
+	return r
+}
+
+// text redacts a free-form string: project paths become basenames, the home
+// directory becomes ~, URL credentials are stripped, and tokens are masked.
+func (r *reportRedactor) text(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, project := range r.projects {
+		s = strings.ReplaceAll(s, project, filepath.Base(project))
+	}
+	if r.home != "" {
+		s = strings.ReplaceAll(s, r.home, "~")
+	}
+	s = urlPattern.ReplaceAllStringFunc(s, redactURL)
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllString(s, redactedMarker)
+	}
+	return s
+}
+
+// redactURL drops userinfo and credential-like query parameters.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	changed := false
+	if u.User != nil {
+		u.User = nil
+		changed = true
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for key := range q {
+			lower := strings.ToLower(key)
+			for _, param := range credentialParams {
+				if strings.Contains(lower, param) {
+					q.Del(key)
+					changed = true
+					break
+				}
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	if !changed {
+		return raw
+	}
+	return u.String()
+}
+
+// runs returns redacted copies of runs; the originals are not modified.
+func (r *reportRedactor) runs(runs []reportRun) []reportRun {
+	out := make([]reportRun, 0, len(runs))
+	for _, run := range runs {
+		if run.results != nil {
+			run.results = r.results(run.results)
+		}
+		out = append(out, run)
+	}
+	return out
+}
+
+func (r *reportRedactor) results(in *reporting.RunResults) *reporting.RunResults {
+	res := *in
+	res.LogPath = r.text(in.LogPath)
+	res.Tasks = make([]reporting.TaskResult, len(in.Tasks))
+	for i, task := range in.Tasks {
+		if filepath.IsAbs(task.Project) {
+			task.Project = filepath.Base(task.Project)
+		}
+		task.Title = r.text(task.Title)
+		task.OutputRef = r.text(task.OutputRef)
+		task.SkipReason = r.text(task.SkipReason)
+		if len(task.Artifacts) > 0 {
+			artifacts := make([]string, len(task.Artifacts))
+			for j, artifact := range task.Artifacts {
+				artifacts[j] = r.text(artifact)
+			}
+			task.Artifacts = artifacts
+		}
+		task.EventLog = r.text(task.EventLog)
+		if len(task.Commits) > 0 {
+			commits := make([]reporting.TaskCommit, len(task.Commits))
+			for j, c := range task.Commits {
+				c.Subject = r.text(c.Subject)
+				commits[j] = c
+			}
+			task.Commits = commits
+		}
+		res.Tasks[i] = task
+	}
+	res.Notes = make([]string, len(in.Notes))
+	for i, note := range in.Notes {
+		res.Notes[i] = r.text(note)
+	}
+	res.ProviderSnapshots = make([]reporting.ProviderSnapshot, len(in.ProviderSnapshots))
+	for i, snap := range in.ProviderSnapshots {
+		snap.Error = r.text(snap.Error)
+		res.ProviderSnapshots[i] = snap
+	}
+	return &res
+}
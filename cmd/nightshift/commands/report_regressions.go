@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+// defaultRegressionDelta is how far (0-1) a task type's success rate must
+// fall below its baseline before --highlight-regressions flags it.
+const defaultRegressionDelta = 0.25
+
+// minBaselineAttempts keeps task types with too little history from being
+// flagged on a single unlucky run.
+const minBaselineAttempts = 3
+
+// taskRegression is a task type whose success rate in the selected range
+// dropped below its trailing baseline.
+type taskRegression struct {
+	TaskType         string  `json:"task_type"`
+	Rate             float64 `json:"success_rate"`
+	Completed        int     `json:"completed"`
+	Attempts         int     `json:"attempts"`
+	BaselineRate     float64 `json:"baseline_success_rate"`
+	BaselineAttempts int     `json:"baseline_attempts"`
+}
+
+// successTally counts attempted (non-skipped) tasks and their outcomes.
+type successTally struct {
+	attempts  int
+	completed int
+	partial   int
+}
+
+// rate is the success rate (0-1); partials count as half a success, as in
+// stats.
+func (t successTally) rate() float64 {
+	return reporting.SuccessRate(t.completed, t.partial, t.attempts) / 100
+}
+
+// tallyTaskSuccess groups the runs' attempted tasks by type.
+func tallyTaskSuccess(runs []reportRun) map[string]successTally {
+	out := map[string]successTally{}
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		for _, task := range run.results.Tasks {
+			if task.TaskType == "" || task.Status == "skipped" {
+				continue
+			}
+			t := out[task.TaskType]
+			t.attempts++
+			switch task.Status {
+			case "completed":
+				t.completed++
+			case "partial":
+				t.partial++
+			}
+			out[task.TaskType] = t
+		}
+	}
+	return out
+}
+
+// runStart returns when a run began, falling back to its end time.
+func runStart(run reportRun) time.Time {
+	if run.results == nil {
+		return time.Time{}
+	}
+	if !run.results.StartTime.IsZero() {
+		return run.results.StartTime
+	}
+	return run.results.EndTime
+}
+
+// findRegressions compares each task type's success rate in current against
+// the trailing baseline: every retained run older than the oldest current
+// run. Types whose rate dropped by more than delta are returned, worst first.
+func findRegressions(current, all []reportRun, delta float64) []taskRegression {
+	var oldest time.Time
+	for _, run := range current {
+		if start := runStart(run); !start.IsZero() && (oldest.IsZero() || start.Before(oldest)) {
+			oldest = start
+		}
+	}
+	var baselineRuns []reportRun
+	for _, run := range all {
+		if start := runStart(run); !start.IsZero() && start.Before(oldest) {
+			baselineRuns = append(baselineRuns, run)
+		}
+	}
+
+	baseline := tallyTaskSuccess(baselineRuns)
+	var out []taskRegression
+	for taskType, cur := range tallyTaskSuccess(current) {
+		base, ok := baseline[taskType]
+		if !ok || base.attempts < minBaselineAttempts {
+			continue
+		}
+		if base.rate()-cur.rate() > delta {
+			out = append(out, taskRegression{
+				TaskType:         taskType,
+				Rate:             cur.rate(),
+				Completed:        cur.completed,
+				Attempts:         cur.attempts,
+				BaselineRate:     base.rate(),
+				BaselineAttempts: base.attempts,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		di, dj := out[i].BaselineRate-out[i].Rate, out[j].BaselineRate-out[j].Rate
+		if di != dj {
+			return di > dj
+		}
+		return out[i].TaskType < out[j].TaskType
+	})
+	return out
+}
+
+// renderRegressions formats the regressions section for fancy and plain
+// output.
+func renderRegressions(styles reportStyles, regressions []taskRegression, delta float64) string {
+	var b strings.Builder
+	b.WriteString(styles.Section.Render("Regressions"))
+	b.WriteString("\n")
+	if len(regressions) == 0 {
+		b.WriteString("  " + styles.Muted.Render(fmt.Sprintf("No task type dropped more than %.0f points below its baseline.", delta*100)) + "\n")
+		return b.String()
+	}
+	for _, r := range regressions {
+		b.WriteString(fmt.Sprintf("  %s %s: %s success (%d/%d) vs %s baseline over %d attempts\n",
+			styles.Error.Render("▼"), r.TaskType,
+			formatRate(r.Rate), r.Completed, r.Attempts,
+			formatRate(r.BaselineRate), r.BaselineAttempts))
+	}
+	return b.String()
+}
+
+// renderRegressionsMarkdown formats the regressions section for markdown
+// output.
+func renderRegressionsMarkdown(regressions []taskRegression, delta float64) string {
+	var b strings.Builder
+	b.WriteString("## Regressions\n\n")
+	if len(regressions) == 0 {
+		b.WriteString(fmt.Sprintf("No task type dropped more than %.0f points below its baseline.\n", delta*100))
+		return b.String()
+	}
+	for _, r := range regressions {
+		b.WriteString(fmt.Sprintf("- **%s**: %s success (%d/%d) vs %s baseline over %d attempts\n",
+			r.TaskType, formatRate(r.Rate), r.Completed, r.Attempts, formatRate(r.BaselineRate), r.BaselineAttempts))
+	}
+	return b.String()
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/reporting"
+)
+
+func TestFindRegressions(t *testing.T) {
+	now := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC)
+	run := func(daysAgo int, statuses map[string][]string) reportRun {
+		results := &reporting.RunResults{StartTime: now.AddDate(0, 0, -daysAgo)}
+		for taskType, list := range statuses {
+			for _, status := range list {
+				results.Tasks = append(results.Tasks, reporting.TaskResult{TaskType: taskType, Status: status})
+			}
+		}
+		return reportRun{results: results}
+	}
+
+	current := []reportRun{
+		run(0, map[string][]string{"lint-fix": {"failed"}, "docs-backfill": {"completed"}}),
+		run(1, map[string][]string{"lint-fix": {"failed", "skipped"}, "bug-finder": {"failed"}}),
+	}
+	baseline := []reportRun{
+		run(5, map[string][]string{"lint-fix": {"completed", "completed"}, "docs-backfill": {"completed"}}),
+		run(6, map[string][]string{"lint-fix": {"completed", "failed"}, "docs-backfill": {"failed", "completed"}}),
+		run(7, map[string][]string{"bug-finder": {"completed", "completed"}}), // too few attempts
+	}
+	all := append(append([]reportRun{}, current...), baseline...)
+
+	got := findRegressions(current, all, defaultRegressionDelta)
+	if len(got) != 1 {
+		t.Fatalf("regressions = %+v, want only lint-fix", got)
+	}
+	r := got[0]
+	if r.TaskType != "lint-fix" || r.Attempts != 2 || r.Completed != 0 || r.BaselineAttempts != 4 || r.BaselineRate != 0.75 {
+		t.Errorf("regression = %+v", r)
+	}
+
+	// A looser threshold hides the drop.
+	if got := findRegressions(current, all, 0.8); len(got) != 0 {
+		t.Errorf("delta 0.8: regressions = %+v, want none", got)
+	}
+	// Runs newer than the range are never part of the baseline.
+	if got := findRegressions(baseline, all, defaultRegressionDelta); len(got) != 0 {
+		t.Errorf("oldest range: regressions = %+v, want none", got)
+	}
+}
+
+func TestRenderRegressions(t *testing.T) {
+	styles := newReportStyles()
+	out := renderRegressions(styles, []taskRegression{
+		{TaskType: "lint-fix", Rate: 0, Completed: 0, Attempts: 3, BaselineRate: 0.9, BaselineAttempts: 10},
+	}, defaultRegressionDelta)
+	if !strings.Contains(out, "lint-fix: 0% success (0/3) vs 90% baseline over 10 attempts") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if out := renderRegressions(styles, nil, defaultRegressionDelta); !strings.Contains(out, "more than 25 points") {
+		t.Errorf("empty output:\n%s", out)
+	}
+}
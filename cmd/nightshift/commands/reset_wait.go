@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/scheduler"
+)
+
+// resetWaitSlack is added to a provider's reset time so usage has rolled
+// over before the run resumes.
+const resetWaitSlack = time.Minute
+
+// resetWait returns how long to sleep for a provider's budget to reset.
+// ok is false when the reset time is unknown or past, further away than
+// maxWait, or would land after windowEnd (zero windowEnd = no window).
+func resetWait(now, reset time.Time, maxWait time.Duration, windowEnd time.Time) (time.Duration, bool) {
+	if maxWait <= 0 || reset.IsZero() || !reset.After(now) {
+		return 0, false
+	}
+	wait := reset.Sub(now) + resetWaitSlack
+	if wait > maxWait {
+		return 0, false
+	}
+	if !windowEnd.IsZero() && now.Add(wait).After(windowEnd) {
+		return 0, false
+	}
+	return wait, true
+}
+
+// scheduleWindowEnd returns when the configured schedule window closes, or
+// zero if no window is configured or now is outside it.
+func scheduleWindowEnd(cfg *config.Config, now time.Time) time.Time {
+	w := scheduleWindow(cfg)
+	if w == nil || !w.Contains(now) {
+		return time.Time{}
+	}
+	return w.NextEnd(now)
+}
+
+// scheduleWindow parses the configured schedule window, or returns nil if
+// none is configured or it is invalid.
+func scheduleWindow(cfg *config.Config) *scheduler.Window {
+	if cfg == nil || cfg.Schedule.Window == nil {
+		return nil
+	}
+	sched := scheduler.New()
+	if err := sched.SetWindow(cfg.Schedule.Window); err != nil {
+		return nil
+	}
+	return sched.Window()
+}
+
+// waitForBudgetReset sleeps until provider's budget resets when it is
+// exhausted and the reset falls within budget.max_wait_for_reset and the
+// schedule window. It returns early if draining starts and returns the
+// context error if ctx is cancelled while waiting.
+func waitForBudgetReset(ctx context.Context, cfg *config.Config, budgetMgr *budget.Manager, provider string, shutdown *gracefulShutdown, log *logging.Logger) error {
+	maxWait := cfg.GetMaxWaitForReset()
+	if maxWait <= 0 || budgetMgr == nil {
+		return nil
+	}
+	allowance, err := budgetMgr.CalculateAllowance(provider)
+	if err != nil || allowance.Allowance > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	reset, err := budgetMgr.GetResetTime(provider)
+	if err != nil {
+		log.Warnf("provider %s: budget exhausted, reset time unknown: %v", provider, err)
+		return nil
+	}
+	wait, ok := resetWait(now, reset, maxWait, scheduleWindowEnd(cfg, now))
+	if !ok {
+		log.Infof("provider %s: budget exhausted, not waiting for reset at %s (max_wait_for_reset %s, or past schedule window)",
+			provider, reset.Format("15:04"), maxWait)
+		return nil
+	}
+
+	log.Infof("provider %s: budget exhausted (%.1f%% used), waiting %s for reset at %s",
+		provider, allowance.UsedPercent, wait.Round(time.Second), reset.Format("15:04"))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-shutdown.Done():
+		log.Infof("provider %s: draining, abandoning reset wait", provider)
+	case <-timer.C:
+		log.Infof("provider %s: budget window reset, resuming", provider)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestResetWait(t *testing.T) {
+	now := time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		reset     time.Time
+		maxWait   time.Duration
+		windowEnd time.Time
+		want      time.Duration
+		wantOK    bool
+	}{
+		{
+			name:    "within max wait",
+			reset:   now.Add(40 * time.Minute),
+			maxWait: time.Hour,
+			want:    40*time.Minute + resetWaitSlack,
+			wantOK:  true,
+		},
+		{
+			name:    "disabled",
+			reset:   now.Add(10 * time.Minute),
+			maxWait: 0,
+		},
+		{
+			name:    "beyond max wait",
+			reset:   now.Add(2 * time.Hour),
+			maxWait: time.Hour,
+		},
+		{
+			name:    "unknown reset",
+			maxWait: time.Hour,
+		},
+		{
+			name:    "reset already passed",
+			reset:   now.Add(-time.Minute),
+			maxWait: time.Hour,
+		},
+		{
+			name:      "would sleep past window end",
+			reset:     now.Add(50 * time.Minute),
+			maxWait:   2 * time.Hour,
+			windowEnd: now.Add(30 * time.Minute),
+		},
+		{
+			name:      "finishes before window end",
+			reset:     now.Add(20 * time.Minute),
+			maxWait:   2 * time.Hour,
+			windowEnd: now.Add(time.Hour),
+			want:      20*time.Minute + resetWaitSlack,
+			wantOK:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resetWait(now, tt.reset, tt.maxWait, tt.windowEnd)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("resetWait() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScheduleWindowEnd(t *testing.T) {
+	cfg := &config.Config{Schedule: config.ScheduleConfig{
+		Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC"},
+	}}
+
+	inside := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC)
+	if got := scheduleWindowEnd(cfg, inside); !got.Equal(want) {
+		t.Errorf("inside window: got %v, want %v", got, want)
+	}
+
+	outside := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	if got := scheduleWindowEnd(cfg, outside); !got.IsZero() {
+		t.Errorf("outside window: got %v, want zero", got)
+	}
+
+	if got := scheduleWindowEnd(&config.Config{}, inside); !got.IsZero() {
+		t.Errorf("no window: got %v, want zero", got)
+	}
+}
@@ -3,15 +3,17 @@ package commands
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -22,6 +24,7 @@ import (
 	"github.com/marcus/nightshift/internal/db"
 	"github.com/marcus/nightshift/internal/logging"
 	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/projects"
 	"github.com/marcus/nightshift/internal/providers"
 	"github.com/marcus/nightshift/internal/reporting"
 	"github.com/marcus/nightshift/internal/state"
@@ -74,90 +77,231 @@ selected provider, budget status, projects, and tasks. In interactive
 terminals a confirmation prompt is shown; use --yes to skip it. In
 non-TTY environments (cron, daemon, CI) confirmation is auto-skipped.
 
+When a dangerously_* provider flag is on and a project resolves to a
+sensitive location ($HOME, /, /tmp), an extra confirmation is required
+even with --yes; without a terminal the run is refused. Use --force to
+skip it.
+
 Use --dry-run to display the preflight summary and exit without
-executing anything.
+executing anything. Use --validate to go one step further: send a trivial
+no-op prompt through each selected provider's real agent path (binary,
+auth, flags), report pass/fail per provider, and exit without running
+tasks.
 
 Flags:
   --max-projects N   Limit how many projects are processed (default 1).
                      Ignored when --project is set.
+  --projects-from F  Process the projects listed in file F (one path per
+                     line, # comments) instead of the configured ones.
   --max-tasks N      Limit how many tasks run per project (default 1).
                      Ignored when --task is set.
+  --fill-window      Instead of --max-tasks, pack tasks by their cost tier's
+                     estimated duration into the time left in
+                     schedule.window. Requires the window to be open.
+  --task / -t        Run specific task(s) in the given order, bypassing scoring.
+                     Accepts a comma-separated list or repeated flags; later
+                     tasks are skipped if budget runs out.
   --random-task      Pick a random task from eligible tasks (exactly 1).
                      Mutually exclusive with --task.
+  --respect-startup-delay
+                     When run inside the schedule window's startup_delay,
+                     wait until it passes before starting tasks (manual runs
+                     ignore the delay otherwise).
+  --since-report     Also apply task cooldowns from completions recorded in
+                     the retained run reports, e.g. a reports dir shared by
+                     several machines (default: tasks.since_report).
+  --seed N           Seed the random task picker so --random-task picks are
+                     reproducible (testing aid; default is time-seeded).
   --ignore-budget    Bypass budget checks (use with caution).
+  --provider-fallback off
+                     Only consider the first enabled provider in preference
+                     order; skip the run instead of switching providers.
   --yes / -y         Skip the confirmation prompt.
+  --warn-unsafe      List the dangerously_* flags active for the selected
+                     provider in the preflight (default on).
+  --force            Skip the extra confirmation required when an unsafe
+                     flag is on and a project resolves to $HOME, / or /tmp.
   --dry-run          Show preflight summary and exit without executing.
+  --verbose / -v     With --dry-run, list every task's score components
+                     (base + staleness + context + source) and the filter
+                     verdict that kept or dropped it.
+  --validate         Send a no-op prompt through each selected provider,
+                     report pass/fail, and exit without running tasks.
+  --interactive-plan Uncheck planned tasks in a checklist, then run the
+                     trimmed plan (Enter runs, q cancels). Replaces the
+                     confirmation prompt; runs the full plan without a TTY.
+  --format           Preflight output: fancy, plain or json (json requires
+                     --dry-run; skip reasons carry stable codes).
   --branch / -b      Base branch for new feature branches (defaults to current branch).
+  --label NAME       Tag the run's report and history records with NAME, so
+                     report --label and state history --label can filter
+                     on it (letters, digits, '.', '_' and '-').
 
 Examples:
   nightshift run                              # Interactive: preflight + prompt
   nightshift run --yes                        # Skip confirmation
   nightshift run --dry-run                    # Preview only, no execution
+  nightshift run --validate                   # Prove each provider works tonight
+  nightshift run --max-tasks 3 --interactive-plan  # Pick from the plan
+  nightshift run --dry-run --format json      # Machine-readable preflight
+  nightshift run --dry-run -v                 # Per-task scoring breakdown
   nightshift run --max-projects 3             # Process up to 3 projects
+  nightshift run --projects-from repos.txt --max-projects 10  # Curated batch
   nightshift run --max-tasks 3                # Up to 3 tasks per project
   nightshift run --random-task                # Pick a random eligible task
+  nightshift run --random-task --seed 42      # Reproducible random pick
+  nightshift run --min-score 3                # Skip low-scoring tasks
+  nightshift run --max-failures 3             # Stop after 3 failed tasks
+  nightshift run --project-timeout 45m        # Cap time spent per project
   nightshift run --ignore-budget              # Run even if budget exhausted
+  nightshift run --provider-fallback off      # First preferred provider or nothing
+  nightshift run --env ANTHROPIC_BASE_URL=https://staging.example.com  # One-off agent env
   nightshift run -p ./my-project -t lint-fix  # Specific project + task
-  nightshift run --branch develop             # Use develop as base branch`,
+  nightshift run -t lint-fix,docs-backfill    # Multiple tasks, in order
+  nightshift run --branch develop             # Use develop as base branch
+  nightshift run --label experiment           # Tag the run for report --label`,
 	RunE: runRun,
 }
 
 func init() {
 	runCmd.Flags().Bool("dry-run", false, "Simulate execution without making changes")
+	addFreshFlag(runCmd)
+	runCmd.Flags().Bool("validate", false, "Send a no-op prompt through each selected provider, report pass/fail, and exit without running tasks")
 	runCmd.Flags().StringP("project", "p", "", "Path to project directory")
-	runCmd.Flags().StringP("task", "t", "", "Run specific task by name")
+	runCmd.Flags().String("projects-from", "", "File of project paths to process instead of the configured projects (one per line, # comments)")
+	runCmd.Flags().StringSliceP("task", "t", nil, "Run specific task(s) by name, in order (comma-separated or repeatable)")
 	runCmd.Flags().Int("max-projects", 1, "Max projects to process per run (ignored when --project is set)")
 	runCmd.Flags().Int("max-tasks", 1, "Max tasks to run per project (ignored when --task is set)")
 	runCmd.Flags().Bool("ignore-budget", false, "Bypass budget checks (use with caution)")
 	runCmd.Flags().BoolP("yes", "y", false, "Skip confirmation prompt")
+	runCmd.Flags().Bool("warn-unsafe", true, "List active dangerously_* provider flags in the preflight summary")
+	runCmd.Flags().Bool("force", false, "Run with unsafe provider flags in a sensitive project path without confirming")
+	runCmd.Flags().Bool("interactive-plan", false, "Uncheck planned tasks in a checklist before running (full plan when not a TTY)")
 	runCmd.Flags().Bool("random-task", false, "Pick a random task from eligible tasks")
+	runCmd.Flags().Bool("fill-window", false, "Pack tasks by estimated duration into the time left in schedule.window (overrides --max-tasks)")
+	runCmd.Flags().Bool("respect-startup-delay", false, "Wait out schedule.window.startup_delay when run inside the window's first minutes")
+	runCmd.Flags().Bool("since-report", false, "Apply task cooldowns from completions in the retained run reports too (default: tasks.since_report)")
+	runCmd.Flags().Uint64("seed", 0, "Seed for --random-task selection (reproducible picks; default time-seeded)")
 	runCmd.Flags().StringP("branch", "b", "", "Base branch for new feature branches (defaults to current branch)")
+	runCmd.Flags().String("label", "", "Tag this run's report and history records (filter with report/state history --label)")
 	runCmd.Flags().Bool("no-color", false, "Disable colored output")
+	runCmd.Flags().Bool("explain", false, "Show how tasks were selected (score threshold, category balancing)")
+	runCmd.Flags().BoolP("verbose", "v", false, "With --dry-run, show every task's score components and filter verdict")
+	runCmd.Flags().Int("max-failures", 0, "Stop starting new tasks once this many have failed across projects (0 = unlimited)")
+	runCmd.Flags().Duration("project-timeout", 0, "Stop starting new tasks for a project once it has run this long (overrides orchestrator.project_timeout; 0 = no cap)")
+	runCmd.Flags().String("format", "", "Preflight output format: fancy, plain, json (default: fancy on a terminal, plain otherwise)")
+	runCmd.Flags().Float64("min-score", 0, "Skip tasks scoring below this (overrides scoring.min_score)")
+	runCmd.Flags().Int64("max-tokens-per-task", 0, "Reserve and allow at most this many tokens per task, below its cost tier max (0 = tier max)")
+	runCmd.Flags().StringArray("env", nil, "Set KEY=VALUE in the provider CLI's environment for this run (repeatable)")
+	runCmd.Flags().String("provider-fallback", "", "on | off: whether to switch providers when the first in preference order is unavailable (overrides providers.fallback)")
 	rootCmd.AddCommand(runCmd)
 }
 
 func runRun(cmd *cobra.Command, args []string) error {
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	validate, _ := cmd.Flags().GetBool("validate")
 	projectPath, _ := cmd.Flags().GetString("project")
-	taskFilter, _ := cmd.Flags().GetString("task")
+	taskFilters, err := parseTaskFilters(cmd)
+	if err != nil {
+		return err
+	}
 	maxProjects, _ := cmd.Flags().GetInt("max-projects")
 	maxTasks, _ := cmd.Flags().GetInt("max-tasks")
+	fill, _ := cmd.Flags().GetBool("fill-window")
 	ignoreBudget, _ := cmd.Flags().GetBool("ignore-budget")
 	yes, _ := cmd.Flags().GetBool("yes")
+	interactivePlan, _ := cmd.Flags().GetBool("interactive-plan")
+	warnUnsafe, _ := cmd.Flags().GetBool("warn-unsafe")
+	force, _ := cmd.Flags().GetBool("force")
 	randomTask, _ := cmd.Flags().GetBool("random-task")
+	respectStartupDelay, _ := cmd.Flags().GetBool("respect-startup-delay")
+	explain, _ := cmd.Flags().GetBool("explain")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+	minScore, _ := cmd.Flags().GetFloat64("min-score")
+	maxFailures, _ := cmd.Flags().GetInt("max-failures")
+	maxTokensPerTask, _ := cmd.Flags().GetInt64("max-tokens-per-task")
+	seed, _ := cmd.Flags().GetUint64("seed")
+	format, _ := cmd.Flags().GetString("format")
+	seeded := cmd.Flags().Changed("seed")
 
 	branch, _ := cmd.Flags().GetString("branch")
+	label, _ := cmd.Flags().GetString("label")
+	projectsFrom, _ := cmd.Flags().GetString("projects-from")
 
-	if randomTask && taskFilter != "" {
+	if projectPath != "" && projectsFrom != "" {
+		return fmt.Errorf("--project and --projects-from are mutually exclusive")
+	}
+	if interactivePlan && dryRun {
+		return fmt.Errorf("--interactive-plan and --dry-run are mutually exclusive")
+	}
+	if validate && (dryRun || interactivePlan) {
+		return fmt.Errorf("--validate cannot be combined with --dry-run or --interactive-plan")
+	}
+	if randomTask && len(taskFilters) > 0 {
 		return fmt.Errorf("--random-task and --task are mutually exclusive")
 	}
+	if fill && (randomTask || len(taskFilters) > 0) {
+		return fmt.Errorf("--fill-window cannot be combined with --task or --random-task")
+	}
+	if minScore < 0 {
+		return fmt.Errorf("--min-score must be >= 0")
+	}
+	if maxFailures < 0 {
+		return fmt.Errorf("--max-failures must be >= 0")
+	}
+	if maxTokensPerTask < 0 {
+		return fmt.Errorf("--max-tokens-per-task must be >= 0")
+	}
+	if label != "" && !runLabelRe.MatchString(label) {
+		return fmt.Errorf("--label %q: use letters, digits, '.', '_' or '-'", label)
+	}
+	projectTimeout, _ := cmd.Flags().GetDuration("project-timeout")
+	if projectTimeout < 0 {
+		return fmt.Errorf("--project-timeout must be >= 0")
+	}
+	envFlags, _ := cmd.Flags().GetStringArray("env")
+	if agentEnv, err = parseEnvFlags(envFlags); err != nil {
+		return err
+	}
+	switch format {
+	case "", "fancy", "plain":
+	case "json":
+		if !dryRun {
+			return fmt.Errorf("--format json requires --dry-run")
+		}
+	default:
+		return fmt.Errorf("unknown format: %s (use fancy, plain, or json)", format)
+	}
 
 	noColor, _ := cmd.Flags().GetBool("no-color")
 	if noColor || os.Getenv("NO_COLOR") != "" {
 		lipgloss.SetColorProfile(termenv.Ascii)
 	}
 
-	// Augment PATH so provider CLIs are discoverable when launched
-	// from launchd/systemd/cron which have a minimal PATH.
-	ensurePATH()
-
-	// Set up context with signal handling
+	// Set up context; signal handling is installed once config is loaded
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		fmt.Println("\ninterrupt received, shutting down...")
-		cancel()
-	}()
-
 	// Load configuration
 	cfg, err := loadConfig(projectPath)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	if cmd.Flags().Changed("min-score") {
+		cfg.Scoring.MinScore = minScore
+	}
+	if cmd.Flags().Changed("since-report") {
+		cfg.Tasks.SinceReport, _ = cmd.Flags().GetBool("since-report")
+	}
+	if !cmd.Flags().Changed("project-timeout") {
+		projectTimeout = cfg.GetProjectTimeout()
+	}
+	if cmd.Flags().Changed("provider-fallback") {
+		cfg.Providers.Fallback, _ = cmd.Flags().GetString("provider-fallback")
+		if err := config.Validate(cfg); err != nil {
+			return fmt.Errorf("--provider-fallback: %w", err)
+		}
+	}
 
 	// Initialize logging
 	if err := initLogging(cfg); err != nil {
@@ -165,6 +309,46 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 	log := logging.Component("run")
 	log.Info("starting nightshift run")
+	if len(agentEnv) > 0 {
+		log.Infof("agent env overrides: %s", strings.Join(envKeys(agentEnv), ", "))
+	}
+
+	// Augment PATH so provider CLIs are discoverable when launched
+	// from launchd/systemd/cron which have a minimal PATH.
+	ensurePATH(cfg.ExpandedExtraPathDirs(), log)
+
+	// First signal drains (finish current task), second force-cancels
+	shutdown := newGracefulShutdown(cancel, cfg.GetShutdownGrace(), log)
+	shutdown.listen(ctx)
+	go func() {
+		select {
+		case <-shutdown.Done():
+			fmt.Println("\ninterrupt received, finishing current task (interrupt again to force)...")
+		case <-ctx.Done():
+		}
+	}()
+
+	if respectStartupDelay && !dryRun && !validate {
+		if wait := startupDelayWait(cfg, time.Now()); wait > 0 && format != "json" {
+			fmt.Printf("schedule window startup delay: waiting %s before starting tasks\n", wait.Round(time.Second))
+		}
+		if err := waitForStartupDelay(ctx, cfg, shutdown, log); err != nil {
+			return err
+		}
+		if shutdown.Draining() {
+			return nil
+		}
+	}
+
+	var fillWindow time.Duration
+	if fill {
+		now := time.Now()
+		end := scheduleWindowEnd(cfg, now)
+		if end.IsZero() {
+			return fmt.Errorf("--fill-window requires an open schedule.window")
+		}
+		fillWindow = end.Sub(now)
+	}
 
 	// Initialize state manager
 	database, err := db.Open(cfg.ExpandedDBPath())
@@ -185,17 +369,23 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize providers
-	claudeProvider := providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude"))
-	codexProvider := providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex"))
+	claudeProvider := newClaudeProvider(cfg)
+	codexProvider := newCodexProvider(cfg)
 	copilotProvider := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
 
 	// Initialize budget manager
 	cal := calibrator.New(database, cfg)
 	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
-	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend))
+	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budget.WithReservations(st), budget.WithUsageCache(newUsageCache(cfg)))
+	defer func() { _ = budgetMgr.SaveCache() }()
 
 	// Determine projects to run
-	projects, err := resolveProjects(cfg, projectPath)
+	var projects []string
+	if projectsFrom != "" {
+		projects, err = readProjectsFile(projectsFrom)
+	} else {
+		projects, err = resolveProjects(cfg, projectPath)
+	}
 	if err != nil {
 		return fmt.Errorf("resolve projects: %w", err)
 	}
@@ -225,49 +415,109 @@ func runRun(cmd *cobra.Command, args []string) error {
 
 	// Create task selector
 	selector := tasks.NewSelector(cfg, st)
+	selector.SetMaxTokensPerTask(maxTokensPerTask)
+	if seeded {
+		selector.SetSeed(seed)
+		log.Infof("random task seed: %d", seed)
+	}
 
 	// Run execution
-	if ignoreBudget {
+	if ignoreBudget && format != "json" {
 		fmt.Println("WARNING: --ignore-budget is set, budget checks will be bypassed")
 		log.Warn("--ignore-budget active, bypassing budget checks")
 	}
 
 	params := executeRunParams{
-		cfg:          cfg,
-		budgetMgr:    budgetMgr,
-		selector:     selector,
-		st:           st,
-		projects:     projects,
-		taskFilter:   taskFilter,
-		maxTasks:     maxTasks,
-		randomTask:   randomTask,
-		ignoreBudget: ignoreBudget,
-		dryRun:       dryRun,
-		yes:          yes,
-		branch:       branch,
-		log:          log,
-	}
-	if !dryRun {
-		params.report = newRunReport(time.Now(), calculateRunBudgetStart(cfg, budgetMgr, log))
+		cfg:              cfg,
+		budgetMgr:        budgetMgr,
+		selector:         selector,
+		st:               st,
+		projects:         projects,
+		taskFilters:      taskFilters,
+		maxTasks:         maxTasks,
+		fillWindow:       fillWindow,
+		maxFailures:      maxFailures,
+		maxTokensPerTask: maxTokensPerTask,
+		projectTimeout:   projectTimeout,
+		randomTask:       randomTask,
+		ignoreBudget:     ignoreBudget,
+		dryRun:           dryRun,
+		validate:         validate,
+		format:           format,
+		explain:          explain,
+		verbose:          verbose && dryRun,
+		yes:              yes,
+		interactivePlan:  interactivePlan,
+		warnUnsafe:       warnUnsafe,
+		force:            force,
+		branch:           branch,
+		label:            label,
+		meters:           newTokenMeters(claudeProvider, codexProvider),
+		shutdown:         shutdown,
+		log:              log,
+	}
+	if !dryRun && !validate {
+		params.report = newRunReport(cfg, time.Now(), calculateRunBudgetStart(cfg, budgetMgr, log))
+		params.report.results.PRTargetBranch = cfg.Orchestrator.PR.TargetBranch
+		params.report.results.Label = label
+		params.report.warnReportsDir(log)
 	}
 	return executeRun(ctx, params)
 }
 
+// runLabelRe limits run --label to names that read cleanly in reports and
+// filters.
+var runLabelRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// parseTaskFilters reads --task values, trimming blanks and dropping duplicates
+// while preserving order.
+func parseTaskFilters(cmd *cobra.Command) ([]string, error) {
+	raw, err := cmd.Flags().GetStringSlice("task")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, name := range raw {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out, nil
+}
+
 type executeRunParams struct {
-	cfg          *config.Config
-	budgetMgr    *budget.Manager
-	selector     *tasks.Selector
-	st           *state.State
-	projects     []string
-	taskFilter   string
-	maxTasks     int
-	randomTask   bool
-	ignoreBudget bool
-	dryRun       bool
-	yes          bool
-	branch       string
-	report       *runReport
-	log          *logging.Logger
+	cfg              *config.Config
+	budgetMgr        *budget.Manager
+	selector         *tasks.Selector
+	st               *state.State
+	projects         []string
+	taskFilters      []string
+	maxTasks         int
+	fillWindow       time.Duration // --fill-window: schedule window time left to pack tasks into; 0 = use maxTasks
+	maxFailures      int           // stop starting tasks after this many failures; 0 = unlimited
+	maxTokensPerTask int64         // --max-tokens-per-task: per-task reservation and cap; 0 = tier max
+	projectTimeout   time.Duration // stop starting a project's tasks after this long; 0 = no cap
+	randomTask       bool
+	ignoreBudget     bool
+	dryRun           bool
+	validate         bool   // probe each selected provider with a no-op prompt, then exit
+	format           string // preflight display: "", fancy, plain, json
+	explain          bool
+	verbose          bool // --dry-run --verbose: per-task score breakdown in the preflight
+	yes              bool
+	interactivePlan  bool // uncheck planned tasks in a checklist before running
+	warnUnsafe       bool // list active unsafe provider flags in the preflight
+	force            bool // skip the unsafe-flags-in-sensitive-path confirmation
+	branch           string
+	label            string // run --label, stamped on the report and history records
+	report           *runReport
+	meters           tokenMeters // per-provider token counters; nil = charge estimates
+	shutdown         *gracefulShutdown
+	log              *logging.Logger
 }
 
 // providerChoice holds a selected provider's agent and name.
@@ -275,91 +525,261 @@ type providerChoice struct {
 	agent     agents.Agent
 	name      string
 	allowance *budget.AllowanceResult
+	strategy  string   // providers.strategy that picked it
+	ranking   []string // eligible providers in strategy order, for --explain
 }
 
-// selectProvider picks the best available provider with budget remaining.
-// Order is determined by providers.preference (default: claude, codex).
-// When ignoreBudget is true, budget-exhausted providers are still selected.
-func selectProvider(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger, ignoreBudget bool) (*providerChoice, error) {
-	type candidate struct {
-		name      string
-		binary    string
-		makeAgent func() agents.Agent
-	}
+// providerCandidate is an enabled provider that selectProvider may pick.
+type providerCandidate struct {
+	name      string
+	binary    string
+	makeAgent func() agents.Agent
+}
 
-	var candidates []candidate
-	for _, name := range providerPreference(cfg) {
+// providerCandidates returns the enabled providers among names, in order.
+func providerCandidates(cfg *config.Config, names []string) []providerCandidate {
+	var candidates []providerCandidate
+	for _, name := range names {
 		switch name {
 		case "claude":
 			if cfg.Providers.Claude.Enabled {
-				candidates = append(candidates, candidate{
+				candidates = append(candidates, providerCandidate{
 					name:      "claude",
-					binary:    "claude",
+					binary:    providerBinary(cfg, "claude"),
 					makeAgent: func() agents.Agent { return newClaudeAgentFromConfig(cfg) },
 				})
 			}
 		case "codex":
 			if cfg.Providers.Codex.Enabled {
-				candidates = append(candidates, candidate{
+				candidates = append(candidates, providerCandidate{
 					name:      "codex",
-					binary:    "codex",
+					binary:    providerBinary(cfg, "codex"),
 					makeAgent: func() agents.Agent { return newCodexAgentFromConfig(cfg) },
 				})
 			}
+		case "copilot":
+			if cfg.Providers.Copilot.Enabled {
+				candidates = append(candidates, providerCandidate{
+					name:      "copilot",
+					binary:    providerBinary(cfg, "copilot"),
+					makeAgent: func() agents.Agent { return newCopilotAgentFromConfig(cfg) },
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// selectProvider picks the best available provider with budget remaining.
+// Order is determined by providers.preference (default: claude, codex, copilot).
+// Providers listed in providers.overflow are left out of that order and only
+// tried once every preferred provider is budget-exhausted.
+// When ignoreBudget is true, budget-exhausted providers are still selected.
+func selectProvider(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger, ignoreBudget bool) (*providerChoice, error) {
+	return selectProviderFrom(cfg, budgetMgr, log, ignoreBudget, providerPreference(cfg), 0, nil)
+}
+
+// selectTaskProvider picks the provider for one task: like selectProvider,
+// but in the order providers.tier_preference gives for the task's cost tier,
+// and passing over providers whose allowance can't cover the task's
+// reserved tokens. held maps provider names to tokens already reserved by
+// tasks assigned to them; it may be nil.
+func selectTaskProvider(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger, ignoreBudget bool, def tasks.TaskDefinition, reserved int64, held map[string]int64) (*providerChoice, error) {
+	return selectProviderFrom(cfg, budgetMgr, log, ignoreBudget, tierPreference(cfg, def.CostTier), reserved, held)
+}
+
+// selectProviderFrom is selectProvider over the given preference order. A
+// positive need also skips providers whose allowance, less the tokens held
+// for them, is below need, unless ignoreBudget is set.
+func selectProviderFrom(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger, ignoreBudget bool, preference []string, need int64, held map[string]int64) (*providerChoice, error) {
+	overflowNames := overflowProviders(cfg)
+	var primaryNames []string
+	for _, name := range preference {
+		if !slices.Contains(overflowNames, name) {
+			primaryNames = append(primaryNames, name)
 		}
 	}
+	candidates := providerCandidates(cfg, primaryNames)
+	overflow := providerCandidates(cfg, overflowNames)
 
-	if len(candidates) == 0 {
+	if len(candidates) == 0 && len(overflow) == 0 {
 		return nil, fmt.Errorf("no providers enabled in config")
 	}
+	if err := checkSandbox(cfg); err != nil {
+		return nil, err
+	}
+	primaryOnly := !cfg.ProviderFallbackEnabled()
+	if primaryOnly && len(candidates) > 0 {
+		candidates = candidates[:1]
+	}
 
-	var notInPath, budgetExhausted []string
-	for _, c := range candidates {
-		if _, err := exec.LookPath(c.binary); err != nil {
-			log.Infof("provider %s: CLI not in PATH, skipping", c.name)
-			notInPath = append(notInPath, c.name)
-			continue
-		}
-		allowance, err := budgetMgr.CalculateAllowance(c.name)
-		if err != nil {
-			log.Warnf("provider %s: budget error: %v", c.name, err)
-			continue
-		}
-		if allowance.Allowance <= 0 {
-			log.Infof("provider %s: budget exhausted (%.1f%% used)", c.name, allowance.UsedPercent)
-			if ignoreBudget {
+	strategy := cfg.GetProviderStrategy()
+	var notInPath, budgetExhausted, tooSmall, authExpired []string
+	try := func(candidates []providerCandidate) *providerChoice {
+		var eligible []*providerChoice
+		for _, c := range candidates {
+			if err := cfg.CheckProviderCommand(c.name); err != nil {
+				log.Warnf("provider %s: %v, skipping", c.name, err)
+				notInPath = append(notInPath, c.name)
+				continue
+			}
+			if !sandboxed(cfg) {
+				if _, err := exec.LookPath(c.binary); err != nil {
+					log.Infof("provider %s: CLI not in PATH, skipping", c.name)
+					notInPath = append(notInPath, c.name)
+					continue
+				}
+			}
+			agent := c.makeAgent()
+			if auth := agents.CheckAuth(context.Background(), agent); auth.Expired() {
+				log.Warnf("provider %s: %s (%s), skipping", c.name, auth.Reason(), auth.Detail)
+				authExpired = append(authExpired, c.name+": "+auth.Reason())
+				continue
+			}
+			allowance, err := budgetMgr.CalculateAllowance(c.name)
+			if err != nil {
+				log.Warnf("provider %s: budget error: %v", c.name, err)
+				continue
+			}
+			choice := &providerChoice{
+				agent:     agent,
+				name:      c.name,
+				allowance: allowance,
+				strategy:  strategy,
+			}
+			if allowance.Allowance <= 0 {
+				log.Infof("provider %s: budget exhausted (%.1f%% used)", c.name, allowance.UsedPercent)
+				if !ignoreBudget {
+					budgetExhausted = append(budgetExhausted, fmt.Sprintf("%s (%.0f%% used)", c.name, allowance.UsedPercent))
+					continue
+				}
 				log.Warnf("provider %s: ignoring exhausted budget per --ignore-budget", c.name)
-				return &providerChoice{
-					agent:     c.makeAgent(),
-					name:      c.name,
-					allowance: allowance,
-				}, nil
+			} else if left := allowance.Allowance - held[c.name]; need > 0 && left < need && !ignoreBudget {
+				log.Infof("provider %s: allowance %d (%d held) too small for %d reserved tokens", c.name, allowance.Allowance, held[c.name], need)
+				tooSmall = append(tooSmall, fmt.Sprintf("%s (%s left, %s needed)", c.name, formatTokens64(max(left, 0)), formatTokens64(need)))
+				continue
 			}
-			budgetExhausted = append(budgetExhausted, fmt.Sprintf("%s (%.0f%% used)", c.name, allowance.UsedPercent))
-			continue
+			if strategy == config.StrategyPreference {
+				return choice
+			}
+			eligible = append(eligible, choice)
+		}
+		if len(eligible) == 0 {
+			return nil
 		}
-		return &providerChoice{
-			agent:     c.makeAgent(),
-			name:      c.name,
-			allowance: allowance,
-		}, nil
+		rankProviders(eligible, strategy)
+		ranking := make([]string, len(eligible))
+		for i, choice := range eligible {
+			ranking[i] = describeProviderRank(choice, strategy)
+		}
+		log.Infof("provider strategy %s: %s", strategy, strings.Join(ranking, ", "))
+		eligible[0].ranking = ranking
+		return eligible[0]
+	}
+
+	if choice := try(candidates); choice != nil {
+		return choice, nil
+	}
+	// Overflow only when every preferred provider ran out of budget. With
+	// --ignore-budget an exhausted provider is returned above, so this is
+	// never reached while one is usable.
+	if len(overflow) > 0 && len(budgetExhausted)+len(tooSmall) == len(candidates) {
+		if len(candidates) > 0 {
+			log.Warnf("all preferred providers budget-exhausted (%s); switching to overflow providers: %s",
+				strings.Join(budgetExhausted, ", "), strings.Join(overflowNames, ", "))
+		}
+		if choice := try(overflow); choice != nil {
+			log.Infof("provider %s: selected as overflow", choice.name)
+			return choice, nil
+		}
+	}
+
+	var err error
+	switch {
+	case len(notInPath) > 0 && len(budgetExhausted) == 0:
+		err = fmt.Errorf("CLI not in PATH: %s", strings.Join(notInPath, ", "))
+	case len(budgetExhausted) > 0 && len(notInPath) == 0:
+		err = fmt.Errorf("%w: %s", errBudgetExhausted, strings.Join(budgetExhausted, ", "))
+	case len(budgetExhausted) > 0 && len(notInPath) > 0:
+		err = fmt.Errorf("%w: %s; CLI not in PATH: %s",
+			errBudgetExhausted, strings.Join(budgetExhausted, ", "), strings.Join(notInPath, ", "))
+	case len(authExpired) == 0 && len(tooSmall) == 0:
+		err = fmt.Errorf("no providers available")
+	}
+	if len(tooSmall) > 0 {
+		smallErr := fmt.Errorf("%w: %s", errInsufficientBudget, strings.Join(tooSmall, ", "))
+		if err == nil {
+			err = smallErr
+		} else {
+			err = fmt.Errorf("%w; %w", smallErr, err)
+		}
+	}
+	if len(authExpired) > 0 {
+		authErr := &authExpiredError{reasons: authExpired}
+		if err == nil {
+			err = authErr
+		} else {
+			err = fmt.Errorf("%w; %w", err, authErr)
+		}
+	}
+	if primaryOnly && len(candidates) > 0 {
+		return nil, fmt.Errorf("primary provider %s unavailable, fallback disabled: %w", candidates[0].name, err)
+	}
+	return nil, err
+}
+
+// rankProviders orders eligible providers for providers.strategy:
+// least-used by ascending used percent, most-budget by descending
+// allowance. Ties keep preference order.
+func rankProviders(choices []*providerChoice, strategy string) {
+	sort.SliceStable(choices, func(i, j int) bool {
+		a, b := choices[i].allowance, choices[j].allowance
+		switch strategy {
+		case config.StrategyLeastUsed:
+			return a.UsedPercent < b.UsedPercent
+		case config.StrategyMostBudget:
+			return a.Allowance > b.Allowance
+		}
+		return false
+	})
+}
+
+// describeProviderRank renders a ranked provider with the value its
+// strategy sorted on.
+func describeProviderRank(choice *providerChoice, strategy string) string {
+	if strategy == config.StrategyMostBudget {
+		return fmt.Sprintf("%s %s left", choice.name, formatTokens64(choice.allowance.Allowance))
 	}
+	return fmt.Sprintf("%s %.1f%% used", choice.name, choice.allowance.UsedPercent)
+}
 
-	if len(notInPath) > 0 && len(budgetExhausted) == 0 {
-		return nil, fmt.Errorf("CLI not in PATH: %s", strings.Join(notInPath, ", "))
+// providerExplainNote describes how the project's provider was chosen, for
+// --explain.
+func providerExplainNote(choice *providerChoice) string {
+	if len(choice.ranking) == 0 {
+		return fmt.Sprintf("provider %s: strategy %s (first in preference order with budget)", choice.name, config.StrategyPreference)
 	}
-	if len(budgetExhausted) > 0 && len(notInPath) == 0 {
-		return nil, fmt.Errorf("budget exhausted: %s", strings.Join(budgetExhausted, ", "))
+	return fmt.Sprintf("provider %s: strategy %s (%s)", choice.name, choice.strategy, strings.Join(choice.ranking, ", "))
+}
+
+// overflowProviders returns providers.overflow normalized: lowercased,
+// known names only, without duplicates.
+func overflowProviders(cfg *config.Config) []string {
+	if cfg == nil {
+		return nil
 	}
-	if len(budgetExhausted) > 0 && len(notInPath) > 0 {
-		return nil, fmt.Errorf("budget exhausted: %s; CLI not in PATH: %s",
-			strings.Join(budgetExhausted, ", "), strings.Join(notInPath, ", "))
+	var out []string
+	for _, pref := range cfg.Providers.Overflow {
+		name := strings.ToLower(strings.TrimSpace(pref))
+		if (name == "claude" || name == "codex" || name == "copilot") && !slices.Contains(out, name) {
+			out = append(out, name)
+		}
 	}
-	return nil, fmt.Errorf("no providers available")
+	return out
 }
 
 func providerPreference(cfg *config.Config) []string {
-	defaults := []string{"claude", "codex"}
+	defaults := []string{"claude", "codex", "copilot"}
 	if cfg == nil || len(cfg.Providers.Preference) == 0 {
 		return defaults
 	}
@@ -371,7 +791,7 @@ func providerPreference(cfg *config.Config) []string {
 		if name == "" || seen[name] {
 			continue
 		}
-		if name != "claude" && name != "codex" {
+		if name != "claude" && name != "codex" && name != "copilot" {
 			continue
 		}
 		seen[name] = true
@@ -383,40 +803,169 @@ func providerPreference(cfg *config.Config) []string {
 	return out
 }
 
+// providers returns the project's provider followed by the other providers
+// its tasks run on, without duplicates.
+func (pp preflightProject) providers() []*providerChoice {
+	var out []*providerChoice
+	seen := map[string]bool{}
+	add := func(choice *providerChoice) {
+		if choice != nil && !seen[choice.name] {
+			seen[choice.name] = true
+			out = append(out, choice)
+		}
+	}
+	add(pp.provider)
+	for _, st := range pp.tasks {
+		add(pp.taskProviders[st.Definition.Type])
+	}
+	return out
+}
+
+// hasTierPreference reports whether providers.tier_preference sets an
+// order for tier.
+func hasTierPreference(cfg *config.Config, tier tasks.CostTier) bool {
+	return cfg != nil && len(tierPreferenceNames(cfg, tier)) > 0
+}
+
+// tierPreference returns the provider order for tasks of tier: the
+// providers.tier_preference list, then the rest of providers.preference.
+// Tiers without a list use providers.preference as is.
+func tierPreference(cfg *config.Config, tier tasks.CostTier) []string {
+	global := providerPreference(cfg)
+	out := tierPreferenceNames(cfg, tier)
+	if len(out) == 0 {
+		return global
+	}
+	for _, name := range global {
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// tierPreferenceNames returns the normalized providers.tier_preference
+// list for tier, or nil.
+func tierPreferenceNames(cfg *config.Config, tier tasks.CostTier) []string {
+	if cfg == nil || tier.Name() == "" {
+		return nil
+	}
+	var prefs []string
+	for key, list := range cfg.Providers.TierPreference {
+		if strings.EqualFold(key, tier.Name()) {
+			prefs = list
+			break
+		}
+	}
+	var out []string
+	for _, pref := range prefs {
+		name := strings.ToLower(strings.TrimSpace(pref))
+		if (name == "claude" || name == "codex" || name == "copilot") && !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
 // preflightProject holds the planned tasks for a single project.
 type preflightProject struct {
 	path       string
+	readOnly   bool // projects[].read_only: only non-mutating tasks
 	tasks      []tasks.ScoredTask
 	provider   *providerChoice
-	skipReason string // non-empty if project was skipped
+	skipReason string                    // non-empty if project was skipped
+	skipCode   SkipCode                  // machine-readable form of skipReason
+	models     map[tasks.TaskType]string // per-task model overrides for each task's provider
+	explain    []string                  // selection notes shown with --explain
+	scoring    []tasks.TaskVerdict       // per-task breakdown shown with --dry-run --verbose
+	// taskProviders holds providers.tier_preference picks that differ from
+	// provider; other tasks run on provider.
+	taskProviders map[tasks.TaskType]*providerChoice
+}
+
+// label returns the project's name for preflight output, marking
+// read-only projects.
+func (pp preflightProject) label() string {
+	if pp.readOnly {
+		return filepath.Base(pp.path) + " (read-only)"
+	}
+	return filepath.Base(pp.path)
+}
+
+// providerFor returns the provider taskType runs on.
+func (pp preflightProject) providerFor(taskType tasks.TaskType) *providerChoice {
+	if choice := pp.taskProviders[taskType]; choice != nil {
+		return choice
+	}
+	return pp.provider
 }
 
 // preflightPlan collects all planned work before execution.
 type preflightPlan struct {
-	projects     []preflightProject
-	skipReasons  []string // global skip reasons (e.g., no provider)
-	ignoreBudget bool
-	branch       string // base branch for feature branches
+	projects         []preflightProject
+	skipReasons      []SkipReason // all skip reasons, project-level and run-wide (e.g., no provider)
+	ignoreBudget     bool
+	branch           string                  // base branch for feature branches
+	categories       []string                // schedule window category limit; empty = all
+	themeDay         string                  // weekday whose schedule theme applies; empty = no theme
+	maxTokensPerTask int64                   // --max-tokens-per-task; 0 = cost tier max
+	fillWindow       time.Duration           // --fill-window: schedule window time left; 0 = off
+	planned          time.Duration           // estimated duration of the tasks packed by --fill-window
+	durations        tasks.DurationEstimates // --fill-window: per-tier medians from past run reports
+	unmetered        []string                // planned providers that can't count tokens, so the cap is reserve-only
+	theme            []string                // active schedule theme entries (categories and task types)
+	warnUnsafe       bool                    // list active unsafe provider flags in the summary
+	unsafeFlags      []string                // "provider: --flag" for each selected provider
+	sensitivePaths   []string                // scanned projects in sensitive locations; set only with unsafe flags
 }
 
 // buildPreflight performs the planning phase: resolve provider, select tasks
 // per project, but does NOT execute anything.
 func buildPreflight(p executeRunParams) (*preflightPlan, error) {
 	plan := &preflightPlan{
-		ignoreBudget: p.ignoreBudget,
-		branch:       p.branch,
+		ignoreBudget:     p.ignoreBudget,
+		branch:           p.branch,
+		warnUnsafe:       p.warnUnsafe,
+		maxTokensPerTask: p.maxTokensPerTask,
+		fillWindow:       p.fillWindow,
+	}
+	plan.categories = applyWindowCategories(p.selector, p.cfg, time.Now())
+	plan.themeDay, plan.theme = applyScheduleTheme(p.selector, p.cfg, time.Now(), logging.Component("run"))
+	if p.cfg.Tasks.SinceReport {
+		applyReportedRuns(p.selector, reportsDir(p.cfg), p.projects, p.log)
+	}
+	if p.fillWindow > 0 {
+		durations, err := reportedDurations(reportsDir(p.cfg))
+		if err != nil {
+			p.log.Warnf("fill-window: %v; using default duration estimates", err)
+		}
+		plan.durations = durations
+	}
+
+	// Resolve task filters up front so an unknown name fails before any work
+	filterDefs := make([]tasks.TaskDefinition, 0, len(p.taskFilters))
+	for _, name := range p.taskFilters {
+		def, err := tasks.GetDefinition(tasks.TaskType(name))
+		if err != nil {
+			return nil, fmt.Errorf("unknown task type: %s", name)
+		}
+		filterDefs = append(filterDefs, def)
 	}
 
 	for _, projectPath := range p.projects {
-		// Skip if already processed today (unless task filter specified)
-		if p.taskFilter == "" && p.st.WasProcessedToday(projectPath) {
+		// Skip if already processed within the window (unless task filter specified)
+		if len(p.taskFilters) == 0 && p.st.WasProcessedSince(projectPath, p.cfg.ProcessedSince(time.Now())) {
 			p.log.Infof("skip %s (processed today)", projectPath)
-			reason := fmt.Sprintf("%s: already processed today", filepath.Base(projectPath))
 			plan.projects = append(plan.projects, preflightProject{
 				path:       projectPath,
 				skipReason: "already processed today",
+				skipCode:   SkipProcessedToday,
+			})
+			plan.skipReasons = append(plan.skipReasons, SkipReason{
+				Code:    SkipProcessedToday,
+				Project: projectPath,
+				Message: "already processed today",
 			})
-			plan.skipReasons = append(plan.skipReasons, reason)
 			continue
 		}
 
@@ -424,23 +973,47 @@ func buildPreflight(p executeRunParams) (*preflightPlan, error) {
 		choice, err := selectProvider(p.cfg, p.budgetMgr, p.log, p.ignoreBudget)
 		if err != nil {
 			p.log.Infof("no provider available: %v", err)
-			plan.skipReasons = append(plan.skipReasons, fmt.Sprintf("no provider: %v", err))
+			plan.skipReasons = append(plan.skipReasons, SkipReason{
+				Code:    providerSkipCode(err),
+				Message: fmt.Sprintf("no provider: %v", err),
+			})
 			break
 		}
 
 		// Select tasks
+		readOnly := p.cfg.ReadOnlyProject(projectPath)
 		var selectedTasks []tasks.ScoredTask
+		var explainNotes []string
+		var scoring []tasks.TaskVerdict
+		var noneAboveMin bool
+		var windowFull bool
+		var readOnlyRefused int
 
-		if p.taskFilter != "" {
-			def, err := tasks.GetDefinition(tasks.TaskType(p.taskFilter))
-			if err != nil {
-				return nil, fmt.Errorf("unknown task type: %s", p.taskFilter)
+		if len(p.taskFilters) > 0 {
+			taskBudget := choice.allowance.Allowance
+			if p.ignoreBudget {
+				taskBudget = math.MaxInt64
+			}
+			defs := p.selector.FilterReadOnly(filterDefs, projectPath)
+			for _, def := range filterDefs {
+				if readOnly && !def.ReadOnly() {
+					readOnlyRefused++
+					plan.skipReasons = append(plan.skipReasons, SkipReason{
+						Code:    SkipReadOnly,
+						Project: projectPath,
+						Message: fmt.Sprintf("%s skipped (changes code on a read-only project)", def.Type),
+					})
+				}
+			}
+			var budgetSkipped []string
+			selectedTasks, budgetSkipped = selectFilteredTasks(p.selector, defs, projectPath, taskBudget)
+			for _, name := range budgetSkipped {
+				plan.skipReasons = append(plan.skipReasons, SkipReason{
+					Code:    SkipInsufficientBudget,
+					Project: projectPath,
+					Message: fmt.Sprintf("%s skipped (insufficient budget)", name),
+				})
 			}
-			selectedTasks = []tasks.ScoredTask{{
-				Definition: def,
-				Score:      p.selector.ScoreTask(def.Type, projectPath),
-				Project:    projectPath,
-			}}
 		} else if p.randomTask {
 			taskBudget := choice.allowance.Allowance
 			if p.ignoreBudget {
@@ -454,39 +1027,404 @@ func buildPreflight(p executeRunParams) (*preflightPlan, error) {
 			if n <= 0 {
 				n = 1
 			}
+			if p.fillWindow > 0 {
+				n = len(tasks.AllDefinitions())
+			}
 			taskBudget := choice.allowance.Allowance
 			if p.ignoreBudget {
 				taskBudget = math.MaxInt64
 			}
-			selectedTasks = p.selector.SelectTopN(taskBudget, projectPath, n)
+			ex := p.selector.ExplainTopN(taskBudget, projectPath, n)
+			selectedTasks = ex.Selected
+			noneAboveMin = ex.NoneAboveMin()
+			if p.fillWindow > 0 {
+				selectedTasks = packWindow(selectedTasks, p.fillWindow-plan.planned, plan.durations)
+				windowFull = len(selectedTasks) == 0 && len(ex.Selected) > 0
+			}
+			if p.explain {
+				explainNotes = explainTopN(ex)
+			}
+			if p.verbose {
+				scoring = p.selector.ExplainTasks(taskBudget, projectPath, n)
+			}
 		}
 
 		pp := preflightProject{
 			path:     projectPath,
-			tasks:    selectedTasks,
+			readOnly: readOnly,
 			provider: choice,
 		}
+		var tierNotes []string
+		selectedTasks, tierNotes = resolveTaskProviders(p, &pp, selectedTasks, plan)
+
+		models, modelNotes := projectTaskModels(p.cfg, pp, selectedTasks)
+		if p.explain {
+			explainNotes = append([]string{providerExplainNote(choice)}, explainNotes...)
+			explainNotes = append(explainNotes, tierNotes...)
+			explainNotes = append(explainNotes, modelNotes...)
+		}
+		pp.tasks = selectedTasks
+		for _, st := range selectedTasks {
+			plan.planned += plan.estimatedDuration(st.Definition)
+		}
+		pp.explain = explainNotes
+		pp.scoring = scoring
+		pp.models = models
 
 		if len(selectedTasks) == 0 {
 			skipReason := "no tasks available within budget"
-			allEnabled := p.selector.FilterEnabled(tasks.AllDefinitions())
+			skipCode := SkipNoTasks
+			allEnabled := p.selector.FilterReadOnly(p.selector.FilterEnabled(tasks.AllDefinitions()), projectPath)
 			inBudget := p.selector.FilterByBudget(allEnabled, choice.allowance.Allowance)
 			unassigned := p.selector.FilterUnassigned(inBudget, projectPath)
 			afterCooldown := p.selector.FilterByCooldown(unassigned, projectPath)
 			cooledDown := len(unassigned) - len(afterCooldown)
+			recentlyFailed := len(afterCooldown) - len(p.selector.FilterByRecentFailure(afterCooldown, projectPath))
 			if cooledDown > 0 {
 				skipReason = fmt.Sprintf("%d task(s) on cooldown", cooledDown)
+				skipCode = SkipCooldown
+			}
+			if recentlyFailed > 0 && len(afterCooldown) == recentlyFailed {
+				skipReason = "recently failed"
+				skipCode = SkipRecentlyFailed
+			}
+			if readOnly && (len(allEnabled) == 0 || readOnlyRefused > 0) {
+				skipReason = "read-only project: no enabled task leaves code untouched"
+				if readOnlyRefused > 0 {
+					skipReason = "read-only project: requested tasks change code"
+				}
+				skipCode = SkipReadOnly
+			}
+			if noneAboveMin {
+				skipReason = "no task above min score"
+				skipCode = SkipBelowMinScore
+			}
+			if windowFull {
+				skipReason = "no task fits the time left in the schedule window"
+				skipCode = SkipWindowFull
 			}
 			pp.skipReason = skipReason
-			plan.skipReasons = append(plan.skipReasons, fmt.Sprintf("%s: %s", filepath.Base(projectPath), skipReason))
+			pp.skipCode = skipCode
+			plan.skipReasons = append(plan.skipReasons, SkipReason{Code: skipCode, Project: projectPath, Message: skipReason})
 		}
 
 		plan.projects = append(plan.projects, pp)
+		if p.maxTokensPerTask > 0 {
+			for _, st := range pp.tasks {
+				name := pp.providerFor(st.Definition.Type).name
+				if p.meters[name] == nil && !slices.Contains(plan.unmetered, name) {
+					plan.unmetered = append(plan.unmetered, name)
+				}
+			}
+		}
 	}
 
+	collectUnsafe(plan, p.cfg, p.projects)
 	return plan, nil
 }
 
+// packWindow keeps ranked tasks, best first, while their estimated durations
+// fit in left. A task that would overrun it is passed over for a shorter one
+// further down.
+func packWindow(ranked []tasks.ScoredTask, left time.Duration, durations tasks.DurationEstimates) []tasks.ScoredTask {
+	var packed []tasks.ScoredTask
+	for _, st := range ranked {
+		d, _ := durations.For(st.Definition)
+		if d > left {
+			continue
+		}
+		packed = append(packed, st)
+		left -= d
+	}
+	return packed
+}
+
+// estimatedDuration returns def's --fill-window duration estimate.
+func (plan *preflightPlan) estimatedDuration(def tasks.TaskDefinition) time.Duration {
+	d, _ := plan.durations.For(def)
+	return d
+}
+
+// fillNote describes the --fill-window schedule for the preflight header.
+func (plan *preflightPlan) fillNote() string {
+	return fmt.Sprintf("~%s planned of %s left in the schedule window (%s)",
+		formatCooldownDuration(plan.planned), formatCooldownDuration(plan.fillWindow), plan.estimateSource())
+}
+
+// estimateSource says where the planned tasks' duration estimates come
+// from: medians of past run reports, or the cost tier defaults.
+func (plan *preflightPlan) estimateSource() string {
+	var measured, defaults []string
+	seen := make(map[tasks.CostTier]bool)
+	for _, pp := range plan.projects {
+		for _, st := range pp.tasks {
+			tier := st.Definition.CostTier
+			if seen[tier] {
+				continue
+			}
+			seen[tier] = true
+			if _, ok := plan.durations.For(st.Definition); ok {
+				measured = append(measured, tier.Name())
+			} else {
+				defaults = append(defaults, tier.Name())
+			}
+		}
+	}
+	switch {
+	case len(measured) == 0:
+		return "estimates: cost tier defaults"
+	case len(defaults) == 0:
+		return "estimates: medians from past run reports"
+	}
+	return fmt.Sprintf("estimates: medians from past run reports for %s, cost tier defaults for %s",
+		strings.Join(measured, ", "), strings.Join(defaults, ", "))
+}
+
+// fillSuffix returns a task's estimated duration and start offset for the
+// --fill-window schedule, advancing elapsed. Empty when the flag is off.
+func (plan *preflightPlan) fillSuffix(def tasks.TaskDefinition, elapsed *time.Duration) string {
+	if plan.fillWindow <= 0 {
+		return ""
+	}
+	d := plan.estimatedDuration(def)
+	start := "0m"
+	if *elapsed > 0 {
+		start = formatCooldownDuration(*elapsed)
+	}
+	suffix := fmt.Sprintf(", est. ~%s at +%s", formatCooldownDuration(d), start)
+	*elapsed += d
+	return suffix
+}
+
+// resolveTaskProviders picks a provider for each selected task whose cost
+// tier has a providers.tier_preference order, recording picks that differ
+// from the project's provider in pp. Each task's reservation is held
+// against the provider it runs on, so a tier's provider must fit the task on
+// top of those already assigned to it. A task no provider can take is dropped
+// with a skip reason. Returns the tasks that remain and --explain notes.
+func resolveTaskProviders(p executeRunParams, pp *preflightProject, selected []tasks.ScoredTask, plan *preflightPlan) ([]tasks.ScoredTask, []string) {
+	var kept []tasks.ScoredTask
+	var notes []string
+	held := make(map[string]int64)
+	for _, st := range selected {
+		tier := st.Definition.CostTier
+		need := p.selector.ReservedTokens(st.Definition)
+		if !hasTierPreference(p.cfg, tier) {
+			// Already selected against the project provider's allowance
+			held[pp.provider.name] += need
+			kept = append(kept, st)
+			continue
+		}
+		choice, err := selectTaskProvider(p.cfg, p.budgetMgr, p.log, p.ignoreBudget, st.Definition, need, held)
+		if err != nil {
+			p.log.Infof("task %s: no provider for cost tier %s: %v", st.Definition.Type, tier.Name(), err)
+			plan.skipReasons = append(plan.skipReasons, SkipReason{
+				Code:    providerSkipCode(err),
+				Project: pp.path,
+				Message: fmt.Sprintf("%s skipped (no provider for cost tier %s: %v)", st.Definition.Type, tier.Name(), err),
+			})
+			continue
+		}
+		held[choice.name] += need
+		kept = append(kept, st)
+		notes = append(notes, fmt.Sprintf("provider %s for %s (tier_preference %s: %s)",
+			choice.name, st.Definition.Type, tier.Name(), strings.Join(tierPreferenceNames(p.cfg, tier), ", ")))
+		if choice.name == pp.provider.name {
+			continue
+		}
+		if pp.taskProviders == nil {
+			pp.taskProviders = make(map[tasks.TaskType]*providerChoice)
+		}
+		pp.taskProviders[st.Definition.Type] = choice
+	}
+	return kept, notes
+}
+
+// projectTaskModels resolves tasks.models overrides for each selected task
+// on the provider it runs on.
+func projectTaskModels(cfg *config.Config, pp preflightProject, selected []tasks.ScoredTask) (map[tasks.TaskType]string, []string) {
+	var models map[tasks.TaskType]string
+	var notes []string
+	for _, st := range selected {
+		m, n := taskModels(cfg, pp.providerFor(st.Definition.Type).name, []tasks.ScoredTask{st})
+		for taskType, model := range m {
+			if models == nil {
+				models = make(map[tasks.TaskType]string)
+			}
+			models[taskType] = model
+		}
+		notes = append(notes, n...)
+	}
+	return models, notes
+}
+
+// taskModels resolves tasks.models overrides for the selected tasks on
+// provider. Notes describe overrides the provider can't use.
+func taskModels(cfg *config.Config, provider string, selected []tasks.ScoredTask) (map[tasks.TaskType]string, []string) {
+	var models map[tasks.TaskType]string
+	var notes []string
+	for _, st := range selected {
+		taskType := string(st.Definition.Type)
+		if model := cfg.GetTaskModel(taskType, provider); model != "" {
+			if models == nil {
+				models = make(map[tasks.TaskType]string)
+			}
+			models[st.Definition.Type] = model
+		} else if want := cfg.Tasks.Models[taskType]; want != "" {
+			notes = append(notes, fmt.Sprintf("model %s for %s not supported by %s, using provider default", want, taskType, provider))
+		}
+	}
+	return models, notes
+}
+
+// providerSuffix names the provider for a preflight task line when
+// providers.tier_preference moved the task off the project's provider.
+func providerSuffix(pp preflightProject, taskType tasks.TaskType) string {
+	if choice := pp.taskProviders[taskType]; choice != nil {
+		return ", provider=" + choice.name
+	}
+	return ""
+}
+
+// modelSuffix formats the model override for a preflight task line.
+func modelSuffix(models map[tasks.TaskType]string, taskType tasks.TaskType) string {
+	if model := models[taskType]; model != "" {
+		return ", model=" + model
+	}
+	return ""
+}
+
+// recordTaskResult stores a finished task's outcome for
+// tasks.recent_failure_window. Tasks cut short by shutdown aren't recorded.
+func recordTaskResult(ctx context.Context, st *state.State, projectPath string, taskType tasks.TaskType, result *orchestrator.TaskResult, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	st.RecordTaskResult(projectPath, string(taskType), taskStatus(result, err))
+}
+
+// recordTaskRun adds a finished task attempt, including ones cut short by
+// shutdown, to the ledger status --today reads.
+func recordTaskRun(st *state.State, projectPath string, taskType tasks.TaskType, provider string, start time.Time, tokensUsed int, result *orchestrator.TaskResult, err error) {
+	st.AddTaskRun(state.TaskRun{
+		Project:    projectPath,
+		TaskType:   string(taskType),
+		Provider:   provider,
+		Status:     taskStatus(result, err),
+		TokensUsed: tokensUsed,
+		StartTime:  start,
+		EndTime:    time.Now(),
+	})
+}
+
+// taskStatus is a finished task's orchestrator outcome: failed when it
+// returned an error.
+func taskStatus(result *orchestrator.TaskResult, err error) string {
+	if err == nil && result != nil {
+		return string(result.Status)
+	}
+	return string(orchestrator.StatusFailed)
+}
+
+// explainTopN describes the score threshold and category balancing for
+// --explain output.
+func explainTopN(ex tasks.TopNExplanation) []string {
+	var notes []string
+	if ex.MinScore > 0 {
+		notes = append(notes, fmt.Sprintf("min score: %.1f", ex.MinScore))
+		for _, st := range ex.BelowMin {
+			notes = append(notes, fmt.Sprintf("below min %s (score=%.1f)", st.Definition.Type, st.Score))
+		}
+	} else {
+		notes = append(notes, "min score: off")
+	}
+	if !ex.Balanced {
+		return append(notes, "category balancing: off (top by score)")
+	}
+	notes = append(notes, "category balancing: on (round-robin by category)")
+	for _, st := range ex.Selected {
+		notes = append(notes, fmt.Sprintf("picked %s [%s]", st.Definition.Type, categoryShort(st.Definition.Category)))
+	}
+	for _, st := range ex.Displaced {
+		notes = append(notes, fmt.Sprintf("displaced %s [%s] (score=%.1f)", st.Definition.Type, categoryShort(st.Definition.Category), st.Score))
+	}
+	return notes
+}
+
+// selectFilteredTasks returns the explicitly requested tasks in order,
+// bypassing scoring. The first task always runs (matching single --task
+// behavior); later tasks are skipped once their cumulative reservation (see
+// Selector.ReservedTokens) exceeds budget. Returns the selected tasks and the names of skipped ones.
+func selectFilteredTasks(selector *tasks.Selector, defs []tasks.TaskDefinition, projectPath string, budget int64) ([]tasks.ScoredTask, []string) {
+	var selected []tasks.ScoredTask
+	var skipped []string
+	var committed int64
+	for i, def := range defs {
+		reserve := selector.ReservedTokens(def)
+		if i > 0 && committed+reserve > budget {
+			skipped = append(skipped, string(def.Type))
+			continue
+		}
+		committed += reserve
+		selected = append(selected, tasks.ScoredTask{
+			Definition: def,
+			Score:      selector.ScoreTask(def.Type, projectPath),
+			Project:    projectPath,
+		})
+	}
+	return selected, skipped
+}
+
+// applyWindowCategories limits selector to schedule.window.categories while
+// the window is open and returns the active category names. Explicit --task
+// filters bypass the selector and are not limited.
+func applyWindowCategories(selector *tasks.Selector, cfg *config.Config, now time.Time) []string {
+	selector.SetCategories(nil)
+	if cfg.Schedule.Window == nil || len(cfg.Schedule.Window.Categories) == 0 || scheduleWindowEnd(cfg, now).IsZero() {
+		return nil
+	}
+	var cats []tasks.TaskCategory
+	for _, name := range cfg.Schedule.Window.Categories {
+		if cat, err := parseCategoryFilter(name); err == nil {
+			cats = append(cats, cat)
+		}
+	}
+	selector.SetCategories(cats)
+	return cfg.Schedule.Window.Categories
+}
+
+// applyScheduleTheme limits selector to today's schedule.themes entry and
+// returns the weekday and its entries, or "" and nil when today has no
+// theme. Entries that are neither a category nor a known task type are
+// logged and ignored. Like window categories, explicit --task filters are
+// not limited.
+func applyScheduleTheme(selector *tasks.Selector, cfg *config.Config, now time.Time, log *logging.Logger) (string, []string) {
+	selector.SetTheme(nil, nil)
+	day, entries := cfg.ThemeFor(now)
+	if len(entries) == 0 {
+		return "", nil
+	}
+	var cats []tasks.TaskCategory
+	var types []tasks.TaskType
+	var active []string
+	for _, entry := range entries {
+		if cat, err := parseCategoryFilter(entry); err == nil {
+			cats = append(cats, cat)
+		} else if _, err := tasks.GetDefinition(tasks.TaskType(entry)); err == nil {
+			types = append(types, tasks.TaskType(entry))
+		} else {
+			log.Warnf("schedule.themes.%s: ignoring unknown task %q", day, entry)
+			continue
+		}
+		active = append(active, entry)
+	}
+	if len(active) == 0 {
+		return "", nil
+	}
+	selector.SetTheme(cats, types)
+	return day, active
+}
+
 // displayPreflight renders the preflight summary to the given writer.
 func displayPreflight(w io.Writer, plan *preflightPlan) {
 	_, _ = fmt.Fprintf(w, "\n=== Preflight Summary ===\n")
@@ -495,6 +1433,18 @@ func displayPreflight(w io.Writer, plan *preflightPlan) {
 	if plan.branch != "" {
 		_, _ = fmt.Fprintf(w, "Branch: %s\n", plan.branch)
 	}
+	if len(plan.categories) > 0 {
+		_, _ = fmt.Fprintf(w, "Categories: %s (schedule window)\n", strings.Join(plan.categories, ", "))
+	}
+	if len(plan.theme) > 0 {
+		_, _ = fmt.Fprintf(w, "Theme: %s (%s)\n", strings.Join(plan.theme, ", "), plan.themeDay)
+	}
+	if plan.maxTokensPerTask > 0 {
+		_, _ = fmt.Fprintf(w, "Max tokens/task: %s (%s)\n", formatK(int(plan.maxTokensPerTask)), plan.maxTokensNote())
+	}
+	if plan.fillWindow > 0 {
+		_, _ = fmt.Fprintf(w, "Fill window: %s\n", plan.fillNote())
+	}
 
 	// Show provider info from first project that has one
 	for _, pp := range plan.projects {
@@ -516,16 +1466,25 @@ func displayPreflight(w io.Writer, plan *preflightPlan) {
 	_, _ = fmt.Fprintf(w, "\nProjects (%d of %d):\n", active, len(plan.projects))
 
 	idx := 0
+	var elapsed time.Duration
 	for _, pp := range plan.projects {
 		if pp.skipReason != "" || len(pp.tasks) == 0 {
 			continue
 		}
 		idx++
-		_, _ = fmt.Fprintf(w, "  %d. %s\n", idx, filepath.Base(pp.path))
+		_, _ = fmt.Fprintf(w, "  %d. %s\n", idx, pp.label())
 		for _, st := range pp.tasks {
 			minTok, maxTok := st.Definition.EstimatedTokens()
-			_, _ = fmt.Fprintf(w, "     - %s (score=%.1f, cost=%s, ~%dk-%dk tokens)\n",
-				st.Definition.Name, st.Score, st.Definition.CostTier, minTok/1000, maxTok/1000)
+			_, _ = fmt.Fprintf(w, "     - %s (score=%.1f, cost=%s, ~%dk-%dk tokens%s%s%s)\n",
+				st.Definition.Name, st.Score, st.Definition.CostTier, minTok/1000, maxTok/1000,
+				plan.fillSuffix(st.Definition, &elapsed),
+				providerSuffix(pp, st.Definition.Type), modelSuffix(pp.models, st.Definition.Type))
+		}
+		for _, note := range pp.explain {
+			_, _ = fmt.Fprintf(w, "     > %s\n", note)
+		}
+		for _, line := range scoringLines(pp, plan.maxTokensPerTask) {
+			_, _ = fmt.Fprintf(w, "     %s\n", line)
 		}
 	}
 
@@ -539,14 +1498,22 @@ func displayPreflight(w io.Writer, plan *preflightPlan) {
 	if len(skipped) > 0 {
 		_, _ = fmt.Fprintf(w, "\nSkipped:\n")
 		for _, pp := range skipped {
-			_, _ = fmt.Fprintf(w, "  - %s: %s\n", filepath.Base(pp.path), pp.skipReason)
+			_, _ = fmt.Fprintf(w, "  - %s: %s\n", pp.label(), pp.skipReason)
+			for _, note := range pp.explain {
+				_, _ = fmt.Fprintf(w, "    > %s\n", note)
+			}
+			for _, line := range scoringLines(pp, plan.maxTokensPerTask) {
+				_, _ = fmt.Fprintf(w, "    %s\n", line)
+			}
 		}
 	}
 
 	// Warnings
-	if plan.ignoreBudget {
+	if warnings := plan.warnings(); len(warnings) > 0 {
 		_, _ = fmt.Fprintf(w, "\nWarnings:\n")
-		_, _ = fmt.Fprintf(w, "  - --ignore-budget is set: budget limits bypassed\n")
+		for _, warning := range warnings {
+			_, _ = fmt.Fprintf(w, "  - %s\n", warning)
+		}
 	}
 
 	_, _ = fmt.Fprintln(w)
@@ -555,6 +1522,10 @@ func displayPreflight(w io.Writer, plan *preflightPlan) {
 func executeRun(ctx context.Context, p executeRunParams) error {
 	start := time.Now()
 
+	if p.report != nil {
+		p.report.results.ProviderSnapshots = captureProviderSnapshots(p.cfg, p.budgetMgr, p.log)
+	}
+
 	// Build preflight plan
 	plan, err := buildPreflight(p)
 	if err != nil {
@@ -562,9 +1533,12 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 	}
 
 	// Display preflight summary
-	if isInteractive() {
+	switch {
+	case p.format == "json":
+		return displayPreflightJSON(os.Stdout, plan)
+	case p.format == "fancy", p.format == "" && isInteractive():
 		displayPreflightColored(plan)
-	} else {
+	default:
 		displayPreflight(os.Stdout, plan)
 	}
 
@@ -574,8 +1548,13 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 		return nil
 	}
 
-	// Confirm before proceeding
-	proceed, err := confirmRun(p)
+	// Validate: prove each selected provider answers, then exit
+	if p.validate {
+		return validateProviders(ctx, os.Stdout, plan)
+	}
+
+	// Unsafe flags in a sensitive path need an explicit yes, even with --yes
+	proceed, err := confirmUnsafeRun(p, plan, os.Stdin, os.Stdout)
 	if err != nil {
 		return err
 	}
@@ -584,10 +1563,53 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 		return nil
 	}
 
+	// Confirm before proceeding; the checklist doubles as confirmation
+	if p.interactivePlan {
+		plan, err = pickPlanTasks(plan, p.log)
+		if err != nil {
+			return err
+		}
+		if plan == nil {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	} else {
+		proceed, err := confirmRun(p)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
 	// Execute based on the plan
-	var tasksRun, tasksCompleted, tasksFailed int
+	var tasksRun, tasksCompleted, tasksPartial, tasksFailed int
 	var skipReasons []string
-	skipReasons = append(skipReasons, plan.skipReasons...)
+	for _, reason := range plan.skipReasons {
+		skipReasons = append(skipReasons, reason.String())
+	}
+
+	// stopForFailures reports whether --max-failures has been reached,
+	// noting it once in the output and report.
+	aborted := false
+	stopForFailures := func() bool {
+		if aborted {
+			return true
+		}
+		if !maxFailuresReached(tasksFailed, p.maxFailures) {
+			return false
+		}
+		aborted = true
+		note := fmt.Sprintf("stopped early: %d task(s) failed (--max-failures %d)", tasksFailed, p.maxFailures)
+		p.log.Warn(note)
+		fmt.Printf("\n%s\n", note)
+		if p.report != nil {
+			p.report.addNote(note)
+		}
+		return true
+	}
 
 	for _, pp := range plan.projects {
 		select {
@@ -596,6 +1618,13 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 			return ctx.Err()
 		default:
 		}
+		if p.shutdown.Draining() {
+			p.log.Infof("draining: not starting project %s", filepath.Base(pp.path))
+			break
+		}
+		if stopForFailures() {
+			break
+		}
 
 		if pp.skipReason != "" {
 			if pp.skipReason == "already processed today" {
@@ -639,25 +1668,30 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 			}
 		}
 
-		// Create orchestrator with the selected agent
-		var renderer *liveRenderer
-		if isInteractive() {
-			renderer = newLiveRenderer()
-			defer renderer.cleanup()
-		}
+		// Create an orchestrator per provider the project's tasks run on
+		renderer := newEventRenderer(isInteractive())
+		defer renderer.cleanup()
 
-		orchOpts := []orchestrator.Option{
-			orchestrator.WithAgent(choice.agent),
-			orchestrator.WithConfig(orchestrator.Config{
-				MaxIterations: 3,
-				AgentTimeout:  30 * time.Minute,
-			}),
-			orchestrator.WithLogger(logging.Component("orchestrator")),
-		}
-		if renderer != nil {
-			orchOpts = append(orchOpts, orchestrator.WithEventHandler(renderer.HandleEvent))
+		orchs := make(map[string]*orchestrator.Orchestrator)
+		orchFor := func(choice *providerChoice) *orchestrator.Orchestrator {
+			if orch := orchs[choice.name]; orch != nil {
+				return orch
+			}
+			orch := orchestrator.New(
+				orchestrator.WithAgent(choice.agent),
+				orchestrator.WithConfig(orchestrator.Config{
+					MaxIterations: 3,
+					AgentTimeout:  30 * time.Minute,
+					AgentOutput:   orchestrator.AgentOutputMode(p.cfg.GetAgentOutput()),
+				}),
+				orchestrator.WithLogger(logging.Component("orchestrator")),
+				orchestrator.WithTokenMeter(p.meters[choice.name]),
+				orchestrator.WithEventLog(p.report.eventLogDir()),
+				orchestrator.WithEventHandler(renderer.HandleEvent),
+			)
+			orchs[choice.name] = orch
+			return orch
 		}
-		orch := orchestrator.New(orchOpts...)
 
 		projectStart := time.Now()
 		projectTaskTypes := make([]string, 0, len(pp.tasks))
@@ -666,12 +1700,48 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 		projectFailed := 0
 
 		// Execute each selected task
-		for _, scoredTask := range pp.tasks {
+		for i, scoredTask := range pp.tasks {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
 			default:
 			}
+			choice := pp.providerFor(scoredTask.Definition.Type)
+			orch := orchFor(choice)
+			if projectTimedOut(projectStart, p.projectTimeout) {
+				remaining := pp.tasks[i:]
+				p.log.Warnf("project %s: timeout %s reached, skipping %d task(s)", filepath.Base(projectPath), p.projectTimeout, len(remaining))
+				fmt.Printf("\nProject timeout (%s) reached: skipping %d remaining task(s)\n", p.projectTimeout, len(remaining))
+				if p.report != nil {
+					for _, rest := range remaining {
+						p.report.addTask(projectTimeoutResult(projectPath, rest))
+					}
+				}
+				break
+			}
+			if !p.ignoreBudget {
+				if err := waitForBudgetReset(ctx, p.cfg, p.budgetMgr, choice.name, p.shutdown, p.log); err != nil {
+					return err
+				}
+			}
+			if p.shutdown.Draining() {
+				p.log.Infof("draining: not starting task %s", scoredTask.Definition.Type)
+				break
+			}
+			if stopForFailures() {
+				break
+			}
+			if !p.ignoreBudget {
+				if reason := overdraftReason(p.budgetMgr, choice.name, p.selector.ReservedTokens(scoredTask.Definition), p.log); reason != "" {
+					if !isInteractive() {
+						fmt.Printf("\n--- Skipping: %s (%s) ---\n", scoredTask.Definition.Name, reason)
+					}
+					if p.report != nil {
+						p.report.addTask(overdraftResult(projectPath, scoredTask, reason))
+					}
+					continue
+				}
+			}
 
 			tasksRun++
 			if !isInteractive() {
@@ -691,21 +1761,49 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 			// Mark as assigned
 			p.st.MarkAssigned(taskInstance.ID, projectPath, string(scoredTask.Definition.Type))
 
+			workBranch, err := p.cfg.WorkBranchName(projectPath, string(scoredTask.Definition.Type), choice.name, projectStart)
+			if err != nil {
+				p.log.Warnf("branch template: %v", err)
+			}
+
 			// Inject run metadata for PR traceability
 			orch.SetRunMetadata(&orchestrator.RunMetadata{
-				Provider:  choice.name,
-				TaskType:  string(scoredTask.Definition.Type),
-				TaskScore: scoredTask.Score,
-				CostTier:  scoredTask.Definition.CostTier.String(),
-				RunStart:  projectStart,
-				Branch:    p.branch,
+				Provider:   choice.name,
+				Model:      p.cfg.GetTaskModel(string(scoredTask.Definition.Type), choice.name),
+				TaskType:   string(scoredTask.Definition.Type),
+				TaskScore:  scoredTask.Score,
+				CostTier:   scoredTask.Definition.CostTier.String(),
+				RunStart:   projectStart,
+				Branch:     p.branch,
+				WorkBranch: workBranch,
+				PRDraft:    p.cfg.Orchestrator.PR.Draft,
+				PRBase:     p.cfg.Orchestrator.PR.TargetBranch,
+				Forge:      p.cfg.GetForge(),
+				DenyPaths:  p.cfg.DenyPathsFor(projectPath),
+				ReadOnly:   pp.readOnly,
 			})
+			orch.SetTokenCap(taskTokenCap(p.cfg, scoredTask.Definition, p.maxTokensPerTask))
 
 			// Execute via orchestrator
+			sample := p.meters.start(choice.name)
+			taskStart := time.Now()
 			result, err := orch.RunTask(ctx, taskInstance, projectPath)
 
 			// Clear assignment
 			p.st.ClearAssigned(taskInstance.ID)
+			recordTaskResult(ctx, p.st, projectPath, scoredTask.Definition.Type, result, err)
+
+			// Charge what the provider actually counted; without a reading,
+			// completed and partial tasks fall back to their estimate and
+			// failed ones to 0.
+			estimate := 0
+			if err == nil && (result.Status == orchestrator.StatusCompleted || result.Status == orchestrator.StatusPartial) {
+				estimate = int(p.selector.ReservedTokens(scoredTask.Definition))
+			}
+			tokensUsed, measured := sample.used(estimate)
+			projectTokensUsed += tokensUsed
+			p.log.Infof("task %s used %d tokens (measured=%t)", taskInstance.ID, tokensUsed, measured)
+			recordTaskRun(p.st, projectPath, scoredTask.Definition.Type, choice.name, taskStart, tokensUsed, result, err)
 
 			if err != nil {
 				tasksFailed++
@@ -720,8 +1818,12 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 						TaskType:   string(scoredTask.Definition.Type),
 						Title:      scoredTask.Definition.Name,
 						Status:     "failed",
-						TokensUsed: 0,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 				continue
@@ -736,8 +1838,6 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 					fmt.Printf("  COMPLETED in %d iteration(s) (%s)\n", result.Iterations, result.Duration)
 				}
 				p.st.RecordTaskRun(projectPath, string(scoredTask.Definition.Type))
-				_, maxTok := scoredTask.Definition.EstimatedTokens()
-				projectTokensUsed += maxTok
 				if p.report != nil {
 					p.report.addTask(reporting.TaskResult{
 						Project:    projectPath,
@@ -746,8 +1846,36 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 						Status:     "completed",
 						OutputType: result.OutputType,
 						OutputRef:  result.OutputRef,
-						TokensUsed: maxTok,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
+					})
+				}
+			case orchestrator.StatusPartial:
+				tasksPartial++
+				if !isInteractive() {
+					fmt.Printf("  PARTIAL after %d iteration(s), %s %s: %s\n", result.Iterations, result.OutputType, result.OutputRef, result.Error)
+				}
+				// The PR/MR is open for review, so let the cooldown apply
+				p.st.RecordTaskRun(projectPath, string(scoredTask.Definition.Type))
+				if p.report != nil {
+					p.report.addTask(reporting.TaskResult{
+						Project:    projectPath,
+						TaskType:   string(scoredTask.Definition.Type),
+						Title:      scoredTask.Definition.Name,
+						Status:     "partial",
+						OutputType: result.OutputType,
+						OutputRef:  result.OutputRef,
+						SkipReason: result.Error,
+						TokensUsed: tokensUsed,
+						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 			case orchestrator.StatusAbandoned:
@@ -763,7 +1891,12 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 						Title:      scoredTask.Definition.Name,
 						Status:     "failed",
 						SkipReason: result.Error,
+						TokensUsed: tokensUsed,
 						Duration:   result.Duration,
+						Branch:     result.Branch,
+						Commits:    reportCommits(result.Commits),
+						EventLog:   result.EventLog,
+						Provider:   choice.name,
 					})
 				}
 			default:
@@ -774,12 +1907,18 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 				}
 				if p.report != nil {
 					p.report.addTask(reporting.TaskResult{
-						Project:    projectPath,
-						TaskType:   string(scoredTask.Definition.Type),
-						Title:      scoredTask.Definition.Name,
-						Status:     "failed",
-						SkipReason: result.Error,
-						Duration:   result.Duration,
+						Project:     projectPath,
+						TaskType:    string(scoredTask.Definition.Type),
+						Title:       scoredTask.Definition.Name,
+						Status:      "failed",
+						SkipReason:  result.Error,
+						TokensUsed:  tokensUsed,
+						Duration:    result.Duration,
+						Branch:      result.Branch,
+						Commits:     reportCommits(result.Commits),
+						EventLog:    result.EventLog,
+						Provider:    choice.name,
+						DeniedPaths: result.DeniedPaths,
 					})
 				}
 			}
@@ -803,17 +1942,21 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 			TokensUsed: projectTokensUsed,
 			Status:     projectStatus,
 			Branch:     p.branch,
+			Label:      p.label,
 		})
 	}
 
 	// Summary
 	duration := time.Since(start)
 	if isInteractive() {
-		displayRunSummaryColored(duration, tasksRun, tasksCompleted, tasksFailed, skipReasons)
+		displayRunSummaryColored(duration, tasksRun, tasksCompleted, tasksPartial, tasksFailed, skipReasons, p.report.summaryNote())
 	} else {
 		fmt.Printf("\n=== Run Complete ===\n")
 		fmt.Printf("Duration: %s\n", duration.Round(time.Second))
-		fmt.Printf("Tasks: %d run, %d completed, %d failed\n", tasksRun, tasksCompleted, tasksFailed)
+		fmt.Printf("Tasks: %s\n", formatTaskCounts(tasksRun, tasksCompleted, tasksPartial, tasksFailed))
+		if note := p.report.summaryNote(); note != "" {
+			fmt.Printf("Report: %s\n", note)
+		}
 
 		if tasksRun == 0 && len(skipReasons) > 0 {
 			fmt.Println("\nNothing ran because:")
@@ -827,6 +1970,7 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 		"duration":  duration.String(),
 		"tasks_run": tasksRun,
 		"completed": tasksCompleted,
+		"partial":   tasksPartial,
 		"failed":    tasksFailed,
 		"projects":  len(p.projects),
 	})
@@ -838,6 +1982,61 @@ func executeRun(ctx context.Context, p executeRunParams) error {
 	return nil
 }
 
+// maxFailuresReached reports whether failed has hit the --max-failures limit.
+// A limit of 0 means unlimited.
+func maxFailuresReached(failed, limit int) bool {
+	return limit > 0 && failed >= limit
+}
+
+// projectTimedOut reports whether a project started at start has used up
+// its --project-timeout. A limit of 0 means no cap.
+func projectTimedOut(start time.Time, limit time.Duration) bool {
+	return limit > 0 && time.Since(start) >= limit
+}
+
+// projectTimeoutResult is the report entry for a task skipped because its
+// project ran out of time.
+func projectTimeoutResult(projectPath string, task tasks.ScoredTask) reporting.TaskResult {
+	return reporting.TaskResult{
+		Project:    projectPath,
+		TaskType:   string(task.Definition.Type),
+		Title:      task.Definition.Name,
+		Status:     "skipped",
+		SkipReason: "project timeout",
+	}
+}
+
+// overdraftReason returns why task reserving tokens on provider would
+// overdraw the weekly budget, or "" if it may start. Usage that can't be
+// read doesn't block the task; selection already checked the allowance.
+func overdraftReason(budgetMgr *budget.Manager, provider string, tokens int64, log *logging.Logger) string {
+	if budgetMgr == nil {
+		return ""
+	}
+	err := budgetMgr.CheckOverdraft(provider, tokens)
+	if err == nil {
+		return ""
+	}
+	if !errors.Is(err, budget.ErrOverdraft) {
+		log.Warnf("overdraft check: %v", err)
+		return ""
+	}
+	log.Warnf("not starting task: %v", err)
+	return "weekly budget overdraft"
+}
+
+// overdraftResult is the report entry for a task the overdraft guard held
+// back.
+func overdraftResult(projectPath string, task tasks.ScoredTask, reason string) reporting.TaskResult {
+	return reporting.TaskResult{
+		Project:    projectPath,
+		TaskType:   string(task.Definition.Type),
+		Title:      task.Definition.Name,
+		Status:     "skipped",
+		SkipReason: reason,
+	}
+}
+
 // loadConfig loads configuration from the appropriate paths.
 func loadConfig(projectPath string) (*config.Config, error) {
 	if projectPath == "" {
@@ -869,16 +2068,31 @@ func resolveProjects(cfg *config.Config, projectPath string) ([]string, error) {
 		return []string{abs}, nil
 	}
 
-	// Use projects from config
-	if len(cfg.Projects) > 0 {
-		var projects []string
+	// Use projects from config, then repos discovered under scan_roots
+	if len(cfg.Projects) > 0 || len(cfg.ScanRoots) > 0 {
+		var paths []string
+		seen := make(map[string]bool)
+		add := func(path string) {
+			key := path
+			if abs, err := filepath.Abs(path); err == nil {
+				key = abs
+			}
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			paths = append(paths, path)
+		}
 		for _, p := range cfg.Projects {
 			path := expandPath(p.Path)
 			if _, err := os.Stat(path); err == nil {
-				projects = append(projects, path)
+				add(path)
 			}
 		}
-		return projects, nil
+		for _, path := range projects.ScanRoots(cfg.ScanRoots) {
+			add(path)
+		}
+		return paths, nil
 	}
 
 	// Default to current directory
@@ -889,6 +2103,37 @@ func resolveProjects(cfg *config.Config, projectPath string) ([]string, error) {
 	return []string{cwd}, nil
 }
 
+// readProjectsFile reads newline-delimited project paths from path, skipping
+// blank lines and # comments. Paths are ~-expanded, made absolute and
+// deduplicated; a path that doesn't exist is an error.
+func readProjectsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, err
+	}
+	var projects []string
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		abs, err := filepath.Abs(expandPath(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid project path: %w", path, i+1, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("%s:%d: project path does not exist: %s", path, i+1, abs)
+		}
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		projects = append(projects, abs)
+	}
+	return projects, nil
+}
+
 // expandPath expands ~ to home directory.
 func expandPath(path string) string {
 	if len(path) > 0 && path[0] == '~' {
@@ -898,23 +2143,23 @@ func expandPath(path string) string {
 	return path
 }
 
-// ensurePATH augments the current PATH with common bin directories so that
-// provider CLIs (claude, codex) are discoverable even when nightshift is
-// launched from launchd, systemd, or cron which provide a minimal PATH.
-func ensurePATH() {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return
-	}
-
-	extra := []string{
-		filepath.Join(home, ".local", "bin"),
-		filepath.Join(home, "go", "bin"),
-		filepath.Join(home, ".cargo", "bin"),
-		filepath.Join(home, ".npm-global", "bin"),
-		"/usr/local/bin",
-		"/opt/homebrew/bin",
+// ensurePATH appends well-known bin directories, plus the configured
+// providers.extra_path_dirs, to PATH so that provider CLIs (claude, codex) are
+// discoverable even when nightshift is launched from launchd, systemd, or cron
+// which provide a minimal PATH. It logs which configured dirs were added and
+// returns those that don't exist.
+func ensurePATH(configured []string, log *logging.Logger) (missing []string) {
+	// Configured dirs come first so they win over the built-in defaults.
+	extra := slices.Clone(configured)
+	if home, err := os.UserHomeDir(); err == nil {
+		extra = append(extra,
+			filepath.Join(home, ".local", "bin"),
+			filepath.Join(home, "go", "bin"),
+			filepath.Join(home, ".cargo", "bin"),
+			filepath.Join(home, ".npm-global", "bin"),
+		)
 	}
+	extra = append(extra, "/usr/local/bin", "/opt/homebrew/bin")
 
 	// Also include $GOPATH/bin if set
 	if gopath := os.Getenv("GOPATH"); gopath != "" {
@@ -928,12 +2173,20 @@ func ensurePATH() {
 	}
 
 	var added []string
-	for _, dir := range extra {
+	for i, dir := range extra {
+		isConfigured := i < len(configured)
 		if existing[dir] {
 			continue
 		}
+		existing[dir] = true
 		if info, err := os.Stat(dir); err == nil && info.IsDir() {
 			added = append(added, dir)
+			if isConfigured {
+				log.Infof("PATH: added %s", dir)
+			}
+		} else if isConfigured {
+			log.Warnf("providers.extra_path_dirs: %s does not exist, skipping", dir)
+			missing = append(missing, dir)
 		}
 	}
 
@@ -941,4 +2194,5 @@ func ensurePATH() {
 		newPath := current + string(os.PathListSeparator) + strings.Join(added, string(os.PathListSeparator))
 		_ = os.Setenv("PATH", newPath)
 	}
+	return missing
 }
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -25,6 +27,7 @@ type runStyles struct {
 	Error   lipgloss.Style
 	Success lipgloss.Style
 	Accent  lipgloss.Style
+	Partial lipgloss.Style
 }
 
 func newRunStyles() runStyles {
@@ -38,11 +41,13 @@ func newRunStyles() runStyles {
 		Error:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196")),
 		Success: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("42")),
 		Accent:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("81")),
+		Partial: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("141")),
 	}
 }
 
 // asyncSpinner renders a braille spinner on the current line using \r.
 type asyncSpinner struct {
+	out     io.Writer
 	mu      sync.Mutex
 	label   string
 	running bool
@@ -52,8 +57,8 @@ type asyncSpinner struct {
 
 var spinnerFrames = []string{"\u280b", "\u2819", "\u2839", "\u2838", "\u283c", "\u2834", "\u2826", "\u2827", "\u2807", "\u280f"}
 
-func newAsyncSpinner() *asyncSpinner {
-	return &asyncSpinner{}
+func newAsyncSpinner(w io.Writer) *asyncSpinner {
+	return &asyncSpinner{out: w}
 }
 
 func (s *asyncSpinner) start(label string) {
@@ -81,14 +86,14 @@ func (s *asyncSpinner) run() {
 			s.mu.Lock()
 			clearLen := len(s.label) + 4
 			s.mu.Unlock()
-			fmt.Printf("\r%s\r", strings.Repeat(" ", clearLen))
+			fmt.Fprintf(s.out, "\r%s\r", strings.Repeat(" ", clearLen))
 			return
 		case <-ticker.C:
 			s.mu.Lock()
 			label := s.label
 			s.mu.Unlock()
 			frame := spinnerFrames[idx%len(spinnerFrames)]
-			fmt.Printf("\r  %s %s", frame, label)
+			fmt.Fprintf(s.out, "\r  %s %s", frame, label)
 			idx++
 		}
 	}
@@ -106,18 +111,56 @@ func (s *asyncSpinner) stop() {
 	<-s.doneCh
 }
 
+// eventRenderer consumes orchestrator events during a run. Implementations
+// must be safe to call from several orchestrators at once.
+type eventRenderer interface {
+	HandleEvent(e orchestrator.Event)
+	cleanup()
+}
+
+// newEventRenderer returns the live renderer on a TTY and a plain renderer
+// otherwise.
+func newEventRenderer(interactive bool) eventRenderer {
+	if interactive {
+		return newLiveRenderer()
+	}
+	return plainRenderer{}
+}
+
+// plainRenderer is used when stdout isn't a terminal. The run loop already
+// prints one plain line per task, so events add nothing.
+type plainRenderer struct{}
+
+func (plainRenderer) HandleEvent(orchestrator.Event) {}
+func (plainRenderer) cleanup()                       {}
+
 // liveRenderer handles orchestrator events and renders colored output.
-// Events are emitted synchronously from a single goroutine, so no mutex
-// is needed. The spinner has its own synchronization.
+// HandleEvent may be called from several orchestrators at once; a mutex
+// serializes rendering so output never interleaves mid-line. With one task
+// in flight it draws the familiar layout with a spinner. Once a second task
+// starts, every line is prefixed with its task pane label and the spinner
+// is dropped until all tasks finish.
 type liveRenderer struct {
 	styles  runStyles
+	out     io.Writer
 	spinner *asyncSpinner
+
+	mu        sync.Mutex
+	active    map[string]string // task ID -> pane label
+	prefixed  bool
+	openPhase bool // a phase label was printed without its newline
 }
 
 func newLiveRenderer() *liveRenderer {
+	return newLiveRendererTo(os.Stdout)
+}
+
+func newLiveRendererTo(w io.Writer) *liveRenderer {
 	return &liveRenderer{
 		styles:  newRunStyles(),
-		spinner: newAsyncSpinner(),
+		out:     w,
+		spinner: newAsyncSpinner(w),
+		active:  map[string]string{},
 	}
 }
 
@@ -127,62 +170,155 @@ func (r *liveRenderer) cleanup() {
 }
 
 // HandleEvent processes an orchestrator event and renders it to the terminal.
-// Called synchronously from the orchestrator goroutine — no concurrent access.
 func (r *liveRenderer) HandleEvent(e orchestrator.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.render(e)
+}
+
+func (r *liveRenderer) render(e orchestrator.Event) {
+	if e.Type == orchestrator.EventTaskStart {
+		r.active[e.TaskID] = taskPaneLabel(e.TaskID, e.TaskTitle)
+		if len(r.active) > 1 && !r.prefixed {
+			r.prefixed = true
+			r.spinner.stop()
+			if r.openPhase {
+				fmt.Fprintln(r.out)
+				r.openPhase = false
+			}
+		}
+	}
+	if r.prefixed {
+		r.renderPrefixed(e)
+	} else {
+		r.renderSingle(e)
+	}
+	if e.Type == orchestrator.EventTaskEnd {
+		delete(r.active, e.TaskID)
+		if len(r.active) == 0 {
+			r.prefixed = false
+		}
+	}
+}
+
+// renderSingle draws events for a lone task stream.
+func (r *liveRenderer) renderSingle(e orchestrator.Event) {
 	switch e.Type {
 	case orchestrator.EventTaskStart:
-		fmt.Printf("\n%s %s\n", r.styles.Accent.Render(">>>"), r.styles.Title.Render(e.TaskTitle))
+		fmt.Fprintf(r.out, "\n%s %s\n", r.styles.Accent.Render(">>>"), r.styles.Title.Render(e.TaskTitle))
 
 	case orchestrator.EventPhaseStart:
 		r.spinner.stop()
 		label := phaseLabel(e.Phase)
-		fmt.Printf("  %s ", r.styles.Phase.Render(label))
+		fmt.Fprintf(r.out, "  %s ", r.styles.Phase.Render(label))
+		r.openPhase = true
 		r.spinner.start(label)
 
 	case orchestrator.EventPhaseEnd:
 		r.spinner.stop()
-		label := phaseLabel(e.Phase)
-		elapsed := e.Duration.Round(time.Millisecond)
-		fmt.Printf("  %s %s\n", r.styles.Phase.Render(label), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
+		r.openPhase = false
+		fmt.Fprintf(r.out, "  %s\n", r.phaseEndText(e))
 
 	case orchestrator.EventIterationStart:
-		if e.Iteration > 1 {
-			fmt.Printf("  %s\n", r.styles.Warn.Render(fmt.Sprintf("Iteration %d/%d", e.Iteration, e.MaxIter)))
-		} else {
-			fmt.Printf("  %s\n", r.styles.Label.Render(fmt.Sprintf("Iteration %d/%d", e.Iteration, e.MaxIter)))
-		}
+		fmt.Fprintf(r.out, "  %s\n", r.iterationText(e))
 
 	case orchestrator.EventTaskEnd:
 		r.spinner.stop()
-		elapsed := e.Duration.Round(time.Second)
-		switch e.Status {
-		case orchestrator.StatusCompleted:
-			fmt.Printf("  %s %s\n", r.styles.Success.Render("COMPLETED"), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
-		case orchestrator.StatusFailed:
-			msg := "FAILED"
-			if e.Error != "" {
-				msg = fmt.Sprintf("FAILED: %s", e.Error)
-			}
-			fmt.Printf("  %s %s\n", r.styles.Error.Render(msg), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
-		case orchestrator.StatusAbandoned:
-			msg := "ABANDONED"
-			if e.Error != "" {
-				msg = fmt.Sprintf("ABANDONED: %s", e.Error)
-			}
-			fmt.Printf("  %s %s\n", r.styles.Warn.Render(msg), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
-		default:
-			fmt.Printf("  %s %s\n", r.styles.Label.Render(string(e.Status)), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
+		r.openPhase = false
+		fmt.Fprintf(r.out, "  %s\n", r.taskEndText(e))
+
+	case orchestrator.EventLog:
+		if text := r.logText(e); text != "" {
+			fmt.Fprintf(r.out, "  %s\n", text)
 		}
+	}
+}
+
+// renderPrefixed draws one self-contained line per event, labelled with
+// the task's pane, so concurrent streams stay readable.
+func (r *liveRenderer) renderPrefixed(e orchestrator.Event) {
+	label, ok := r.active[e.TaskID]
+	if !ok {
+		label = taskPaneLabel(e.TaskID, e.TaskTitle)
+	}
+	prefix := r.styles.Accent.Render("[" + label + "]")
 
+	var text string
+	switch e.Type {
+	case orchestrator.EventTaskStart:
+		text = r.styles.Title.Render(e.TaskTitle)
+	case orchestrator.EventPhaseStart:
+		text = r.styles.Phase.Render(phaseLabel(e.Phase)) + r.styles.Muted.Render("...")
+	case orchestrator.EventPhaseEnd:
+		text = r.phaseEndText(e)
+	case orchestrator.EventIterationStart:
+		text = r.iterationText(e)
+	case orchestrator.EventTaskEnd:
+		text = r.taskEndText(e)
 	case orchestrator.EventLog:
-		// Only surface warn/error to terminal
-		switch e.Level {
-		case "warn":
-			fmt.Printf("  %s %s\n", r.styles.Warn.Render("WARN"), e.Message)
-		case "error":
-			fmt.Printf("  %s %s\n", r.styles.Error.Render("ERROR"), e.Message)
+		text = r.logText(e)
+	}
+	if text != "" {
+		fmt.Fprintf(r.out, "%s %s\n", prefix, text)
+	}
+}
+
+func (r *liveRenderer) phaseEndText(e orchestrator.Event) string {
+	elapsed := e.Duration.Round(time.Millisecond)
+	return fmt.Sprintf("%s %s", r.styles.Phase.Render(phaseLabel(e.Phase)), r.styles.Muted.Render(fmt.Sprintf("(%s)", elapsed)))
+}
+
+func (r *liveRenderer) iterationText(e orchestrator.Event) string {
+	text := fmt.Sprintf("Iteration %d/%d", e.Iteration, e.MaxIter)
+	if e.Iteration > 1 {
+		return r.styles.Warn.Render(text)
+	}
+	return r.styles.Label.Render(text)
+}
+
+func (r *liveRenderer) taskEndText(e orchestrator.Event) string {
+	elapsed := r.styles.Muted.Render(fmt.Sprintf("(%s)", e.Duration.Round(time.Second)))
+	withError := func(status string) string {
+		if e.Error != "" {
+			return fmt.Sprintf("%s: %s", status, e.Error)
 		}
+		return status
+	}
+	switch e.Status {
+	case orchestrator.StatusCompleted:
+		return fmt.Sprintf("%s %s", r.styles.Success.Render("COMPLETED"), elapsed)
+	case orchestrator.StatusFailed:
+		return fmt.Sprintf("%s %s", r.styles.Error.Render(withError("FAILED")), elapsed)
+	case orchestrator.StatusPartial:
+		return fmt.Sprintf("%s %s", r.styles.Partial.Render(withError("PARTIAL")), elapsed)
+	case orchestrator.StatusAbandoned:
+		return fmt.Sprintf("%s %s", r.styles.Warn.Render(withError("ABANDONED")), elapsed)
+	default:
+		return fmt.Sprintf("%s %s", r.styles.Label.Render(string(e.Status)), elapsed)
+	}
+}
+
+// logText surfaces only warn/error log events.
+func (r *liveRenderer) logText(e orchestrator.Event) string {
+	switch e.Level {
+	case "warn":
+		return fmt.Sprintf("%s %s", r.styles.Warn.Render("WARN"), e.Message)
+	case "error":
+		return fmt.Sprintf("%s %s", r.styles.Error.Render("ERROR"), e.Message)
+	}
+	return ""
+}
+
+// taskPaneLabel names a task's pane. Run task IDs are "<type>:<project>",
+// which becomes "<project>/<type>"; anything else falls back to the title.
+func taskPaneLabel(id, title string) string {
+	if taskType, project, ok := strings.Cut(id, ":"); ok && project != "" {
+		return filepath.Base(project) + "/" + taskType
+	}
+	if title != "" {
+		return title
 	}
+	return id
 }
 
 func phaseLabel(phase orchestrator.TaskStatus) string {
@@ -213,6 +349,29 @@ func displayPreflightColored(plan *preflightPlan) {
 			s.Label.Render("Branch:"),
 			s.Value.Render(plan.branch))
 	}
+	if len(plan.categories) > 0 {
+		fmt.Printf("  %s %s %s\n",
+			s.Label.Render("Categories:"),
+			s.Value.Render(strings.Join(plan.categories, ", ")),
+			s.Muted.Render("(schedule window)"))
+	}
+	if len(plan.theme) > 0 {
+		fmt.Printf("  %s %s %s\n",
+			s.Label.Render("Theme:"),
+			s.Value.Render(strings.Join(plan.theme, ", ")),
+			s.Muted.Render("("+plan.themeDay+")"))
+	}
+	if plan.maxTokensPerTask > 0 {
+		fmt.Printf("  %s %s %s\n",
+			s.Label.Render("Max tokens/task:"),
+			s.Value.Render(formatK(int(plan.maxTokensPerTask))),
+			s.Muted.Render("("+plan.maxTokensNote()+")"))
+	}
+	if plan.fillWindow > 0 {
+		fmt.Printf("  %s %s\n",
+			s.Label.Render("Fill window:"),
+			s.Value.Render(plan.fillNote()))
+	}
 
 	// Show provider info from first project that has one
 	for _, pp := range plan.projects {
@@ -238,18 +397,27 @@ func displayPreflightColored(plan *preflightPlan) {
 	fmt.Printf("\n  %s\n", s.Phase.Render(fmt.Sprintf("Projects (%d of %d)", active, len(plan.projects))))
 
 	idx := 0
+	var elapsed time.Duration
 	for _, pp := range plan.projects {
 		if pp.skipReason != "" || len(pp.tasks) == 0 {
 			continue
 		}
 		idx++
-		fmt.Printf("  %s %s\n", s.Accent.Render(fmt.Sprintf("%d.", idx)), s.Value.Render(filepath.Base(pp.path)))
+		fmt.Printf("  %s %s\n", s.Accent.Render(fmt.Sprintf("%d.", idx)), s.Value.Render(pp.label()))
 		for _, st := range pp.tasks {
 			minTok, maxTok := st.Definition.EstimatedTokens()
 			fmt.Printf("     %s %s %s\n",
 				s.Accent.Render("\u25cf"),
 				s.Value.Render(st.Definition.Name),
-				s.Muted.Render(fmt.Sprintf("(score=%.1f, cost=%s, ~%dk-%dk tokens)", st.Score, st.Definition.CostTier, minTok/1000, maxTok/1000)))
+				s.Muted.Render(fmt.Sprintf("(score=%.1f, cost=%s, ~%dk-%dk tokens%s%s%s)", st.Score, st.Definition.CostTier, minTok/1000, maxTok/1000,
+					plan.fillSuffix(st.Definition, &elapsed),
+					providerSuffix(pp, st.Definition.Type), modelSuffix(pp.models, st.Definition.Type))))
+		}
+		for _, note := range pp.explain {
+			fmt.Printf("     %s\n", s.Muted.Render("> "+note))
+		}
+		for _, line := range scoringLines(pp, plan.maxTokensPerTask) {
+			fmt.Printf("     %s\n", s.Muted.Render(line))
 		}
 	}
 
@@ -263,14 +431,22 @@ func displayPreflightColored(plan *preflightPlan) {
 	if len(skipped) > 0 {
 		fmt.Printf("\n  %s\n", s.Warn.Render("Skipped:"))
 		for _, pp := range skipped {
-			fmt.Printf("    %s %s: %s\n", s.Warn.Render("\u25cf"), s.Label.Render(filepath.Base(pp.path)), s.Muted.Render(pp.skipReason))
+			fmt.Printf("    %s %s: %s\n", s.Warn.Render("\u25cf"), s.Label.Render(pp.label()), s.Muted.Render(pp.skipReason))
+			for _, note := range pp.explain {
+				fmt.Printf("      %s\n", s.Muted.Render("> "+note))
+			}
+			for _, line := range scoringLines(pp, plan.maxTokensPerTask) {
+				fmt.Printf("      %s\n", s.Muted.Render(line))
+			}
 		}
 	}
 
 	// Warnings
-	if plan.ignoreBudget {
+	if warnings := plan.warnings(); len(warnings) > 0 {
 		fmt.Printf("\n  %s\n", s.Warn.Render("Warnings:"))
-		fmt.Printf("    %s %s\n", s.Warn.Render("\u25cf"), s.Warn.Render("--ignore-budget is set: budget limits bypassed"))
+		for _, warning := range warnings {
+			fmt.Printf("    %s %s\n", s.Warn.Render("\u25cf"), s.Warn.Render(warning))
+		}
 	}
 
 	fmt.Println(s.Muted.Render(hr))
@@ -278,7 +454,8 @@ func displayPreflightColored(plan *preflightPlan) {
 }
 
 // displayRunSummaryColored renders the final run summary with colors.
-func displayRunSummaryColored(duration time.Duration, tasksRun, tasksCompleted, tasksFailed int, skipReasons []string) {
+// reportNote, when set, says where a fallback run report went.
+func displayRunSummaryColored(duration time.Duration, tasksRun, tasksCompleted, tasksPartial, tasksFailed int, skipReasons []string, reportNote string) {
 	s := newRunStyles()
 	hr := strings.Repeat("\u2500", 40)
 
@@ -290,11 +467,14 @@ func displayRunSummaryColored(duration time.Duration, tasksRun, tasksCompleted,
 	statusStyle := s.Success
 	if tasksFailed > 0 && tasksCompleted == 0 {
 		statusStyle = s.Error
-	} else if tasksFailed > 0 {
+	} else if tasksFailed > 0 || tasksPartial > 0 {
 		statusStyle = s.Warn
 	}
 	fmt.Printf("  %s %s\n", s.Label.Render("Tasks:"),
-		statusStyle.Render(fmt.Sprintf("%d run, %d completed, %d failed", tasksRun, tasksCompleted, tasksFailed)))
+		statusStyle.Render(formatTaskCounts(tasksRun, tasksCompleted, tasksPartial, tasksFailed)))
+	if reportNote != "" {
+		fmt.Printf("  %s %s\n", s.Label.Render("Report:"), s.Warn.Render(reportNote))
+	}
 
 	if tasksRun == 0 && len(skipReasons) > 0 {
 		fmt.Printf("\n  %s\n", s.Warn.Render("Nothing ran because:"))
@@ -305,6 +485,15 @@ func displayRunSummaryColored(duration time.Duration, tasksRun, tasksCompleted,
 	fmt.Println()
 }
 
+// formatTaskCounts renders "N run, N completed, N failed", naming partial
+// tasks only when there are some.
+func formatTaskCounts(run, completed, partial, failed int) string {
+	if partial > 0 {
+		return fmt.Sprintf("%d run, %d completed, %d partial, %d failed", run, completed, partial, failed)
+	}
+	return fmt.Sprintf("%d run, %d completed, %d failed", run, completed, failed)
+}
+
 // displayProjectHeaderColored renders the per-project header with colors.
 func displayProjectHeaderColored(projectPath, providerName string, allowance *budget.AllowanceResult, taskCount int, scoredTasks []tasks.ScoredTask) {
 	s := newRunStyles()
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/orchestrator"
+)
+
+// lockedBuffer is a bytes.Buffer safe for the spinner goroutine and the
+// renderer to share.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLiveRenderer_ConcurrentStreams(t *testing.T) {
+	out := &lockedBuffer{}
+	r := newLiveRendererTo(out)
+	defer r.cleanup()
+
+	const workers = 4
+	var started, done sync.WaitGroup
+	started.Add(workers)
+	done.Add(workers)
+	for i := range workers {
+		go func() {
+			defer done.Done()
+			id := fmt.Sprintf("lint-fix:/code/proj%d", i)
+			r.HandleEvent(orchestrator.Event{Type: orchestrator.EventTaskStart, TaskID: id, TaskTitle: "Lint Fix"})
+			started.Done()
+			started.Wait() // every stream is in flight before any phase runs
+			for _, phase := range []orchestrator.TaskStatus{orchestrator.StatusPlanning, orchestrator.StatusExecuting, orchestrator.StatusReviewing} {
+				r.HandleEvent(orchestrator.Event{Type: orchestrator.EventPhaseStart, TaskID: id, Phase: phase})
+				r.HandleEvent(orchestrator.Event{Type: orchestrator.EventLog, TaskID: id, Level: "warn", Message: "slow agent"})
+				r.HandleEvent(orchestrator.Event{Type: orchestrator.EventPhaseEnd, TaskID: id, Phase: phase})
+			}
+			r.HandleEvent(orchestrator.Event{Type: orchestrator.EventTaskEnd, TaskID: id, Status: orchestrator.StatusCompleted})
+		}()
+	}
+	done.Wait()
+	r.cleanup()
+
+	completed := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.Contains(line, "\r") {
+			t.Errorf("spinner output in prefixed mode: %q", line)
+		}
+		if !strings.HasPrefix(line, "[proj") && !strings.HasPrefix(line, ">>>") && strings.TrimSpace(line) != "" {
+			t.Errorf("line without pane prefix: %q", line)
+		}
+		if strings.Count(line, "[proj") > 1 {
+			t.Errorf("interleaved line: %q", line)
+		}
+		if strings.Contains(line, "COMPLETED") {
+			completed[line[:strings.Index(line, "]")+1]] = true
+		}
+	}
+	if len(completed) != workers {
+		t.Errorf("completed panes = %v, want %d", completed, workers)
+	}
+	if len(r.active) != 0 || r.prefixed {
+		t.Errorf("renderer state not reset: active=%v prefixed=%v", r.active, r.prefixed)
+	}
+}
+
+func TestLiveRenderer_SingleStream(t *testing.T) {
+	out := &lockedBuffer{}
+	r := newLiveRendererTo(out)
+	id := "docs-backfill:/code/app"
+	r.HandleEvent(orchestrator.Event{Type: orchestrator.EventTaskStart, TaskID: id, TaskTitle: "Docs Backfill"})
+	r.HandleEvent(orchestrator.Event{Type: orchestrator.EventIterationStart, TaskID: id, Iteration: 1, MaxIter: 3})
+	r.HandleEvent(orchestrator.Event{Type: orchestrator.EventTaskEnd, TaskID: id, Status: orchestrator.StatusFailed, Error: "boom"})
+	r.cleanup()
+
+	got := out.String()
+	for _, want := range []string{">>> Docs Backfill", "  Iteration 1/3", "  FAILED: boom"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "[app/") {
+		t.Errorf("single stream should not be prefixed:\n%s", got)
+	}
+}
+
+func TestTaskPaneLabel(t *testing.T) {
+	tests := []struct {
+		id, title, want string
+	}{
+		{"lint-fix:/code/myapp", "Lint Fix", "myapp/lint-fix"},
+		{"adhoc-1", "update the CHANGELOG", "update the CHANGELOG"},
+		{"adhoc-1", "", "adhoc-1"},
+	}
+	for _, tt := range tests {
+		if got := taskPaneLabel(tt.id, tt.title); got != tt.want {
+			t.Errorf("taskPaneLabel(%q, %q) = %q, want %q", tt.id, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestNewEventRenderer(t *testing.T) {
+	if _, ok := newEventRenderer(false).(plainRenderer); !ok {
+		t.Error("non-interactive runs should use the plain renderer")
+	}
+	r := newEventRenderer(true)
+	defer r.cleanup()
+	if _, ok := r.(*liveRenderer); !ok {
+		t.Error("interactive runs should use the live renderer")
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/marcus/nightshift/internal/logging"
+)
+
+// planItem is one planned task in the --interactive-plan checklist.
+type planItem struct {
+	project  int // index into preflightPlan.projects
+	task     int // index into preflightProject.tasks
+	selected bool
+}
+
+// planPickerModel lets the user uncheck planned tasks before a run.
+type planPickerModel struct {
+	plan      *preflightPlan
+	items     []planItem
+	cursor    int
+	confirmed bool
+}
+
+func newPlanPickerModel(plan *preflightPlan) *planPickerModel {
+	m := &planPickerModel{plan: plan}
+	for pi, pp := range plan.projects {
+		if pp.skipReason != "" {
+			continue
+		}
+		for ti := range pp.tasks {
+			m.items = append(m.items, planItem{project: pi, task: ti, selected: true})
+		}
+	}
+	return m
+}
+
+func (m *planPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *planPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "esc", "q":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case " ":
+		if len(m.items) > 0 {
+			m.items[m.cursor].selected = !m.items[m.cursor].selected
+		}
+	case "enter":
+		m.confirmed = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *planPickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(styleAccent.Render("Planned tasks"))
+	b.WriteString("\n")
+	b.WriteString("Space to toggle, ↑/↓ to move.\n")
+	lastProject := -1
+	for i, item := range m.items {
+		if item.project != lastProject {
+			lastProject = item.project
+			b.WriteString("\n")
+			b.WriteString(styleHeader.Render(filepath.Base(m.plan.projects[item.project].path)))
+			b.WriteString("\n")
+		}
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		check := " "
+		if item.selected {
+			check = "x"
+		}
+		def := m.plan.projects[item.project].tasks[item.task].Definition
+		b.WriteString(fmt.Sprintf(" %s [%s] %-22s %s\n", cursor, check, def.Type, def.Name))
+	}
+	if len(m.items) == 0 {
+		b.WriteString("\n")
+		b.WriteString(styleWarn.Render("No tasks planned."))
+		b.WriteString("\n")
+	}
+	b.WriteString("\nPress Enter to run, q to cancel.\n")
+	return b.String()
+}
+
+// trimmedPlan returns a copy of the plan keeping only checked tasks. Projects
+// left with no tasks are dropped; skipped projects are kept so their reasons
+// still reach the report.
+func (m *planPickerModel) trimmedPlan() *preflightPlan {
+	keep := make(map[[2]int]bool, len(m.items))
+	for _, item := range m.items {
+		if item.selected {
+			keep[[2]int{item.project, item.task}] = true
+		}
+	}
+	out := *m.plan
+	out.projects = nil
+	for pi, pp := range m.plan.projects {
+		if pp.skipReason != "" {
+			out.projects = append(out.projects, pp)
+			continue
+		}
+		trimmed := pp
+		trimmed.tasks = nil
+		for ti, st := range pp.tasks {
+			if keep[[2]int{pi, ti}] {
+				trimmed.tasks = append(trimmed.tasks, st)
+			}
+		}
+		if len(trimmed.tasks) > 0 {
+			out.projects = append(out.projects, trimmed)
+		}
+	}
+	return &out
+}
+
+// pickPlanTasks shows the --interactive-plan checklist and returns the plan
+// to execute, or nil if the user cancelled. Without a terminal it returns the
+// full plan unchanged.
+func pickPlanTasks(plan *preflightPlan, log *logging.Logger) (*preflightPlan, error) {
+	if !isInteractive() {
+		log.Info("non-TTY: --interactive-plan ignored, running full plan")
+		return plan, nil
+	}
+	final, err := tea.NewProgram(newPlanPickerModel(plan)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("interactive plan: %w", err)
+	}
+	m := final.(*planPickerModel)
+	if !m.confirmed {
+		return nil, nil
+	}
+	return m.trimmedPlan(), nil
+}
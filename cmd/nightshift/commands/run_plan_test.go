@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+func planPickerTestPlan() *preflightPlan {
+	task := func(tt tasks.TaskType) tasks.ScoredTask {
+		return tasks.ScoredTask{Definition: tasks.TaskDefinition{Type: tt, Name: string(tt)}}
+	}
+	return &preflightPlan{
+		projects: []preflightProject{
+			{path: "/code/a", tasks: []tasks.ScoredTask{task("lint-fix"), task("docs-backfill")}},
+			{path: "/code/b", skipReason: "already processed today", skipCode: SkipProcessedToday},
+			{path: "/code/c", tasks: []tasks.ScoredTask{task("dead-code")}},
+		},
+		branch: "main",
+	}
+}
+
+func pressKeys(m *planPickerModel, keys ...string) {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m.Update(msg)
+	}
+}
+
+func TestPlanPicker_TrimmedPlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		keys         []string
+		wantProjects map[string]int // path -> task count
+		confirmed    bool
+	}{
+		{
+			name:         "confirm full plan",
+			keys:         []string{"enter"},
+			wantProjects: map[string]int{"/code/a": 2, "/code/b": 0, "/code/c": 1},
+			confirmed:    true,
+		},
+		{
+			name:         "uncheck one task",
+			keys:         []string{"down", " ", "enter"},
+			wantProjects: map[string]int{"/code/a": 1, "/code/b": 0, "/code/c": 1},
+			confirmed:    true,
+		},
+		{
+			name:         "unchecking every task drops the project",
+			keys:         []string{"down", "down", " ", "enter"},
+			wantProjects: map[string]int{"/code/a": 2, "/code/b": 0},
+			confirmed:    true,
+		},
+		{
+			name:      "cancel",
+			keys:      []string{" ", "q"},
+			confirmed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newPlanPickerModel(planPickerTestPlan())
+			if len(m.items) != 3 {
+				t.Fatalf("items = %d, want 3 (skipped projects excluded)", len(m.items))
+			}
+			pressKeys(m, tt.keys...)
+			if m.confirmed != tt.confirmed {
+				t.Fatalf("confirmed = %t, want %t", m.confirmed, tt.confirmed)
+			}
+			if !tt.confirmed {
+				return
+			}
+			got := m.trimmedPlan()
+			if got.branch != "main" {
+				t.Errorf("branch = %q, want main", got.branch)
+			}
+			if len(got.projects) != len(tt.wantProjects) {
+				t.Fatalf("projects = %d, want %d", len(got.projects), len(tt.wantProjects))
+			}
+			for _, pp := range got.projects {
+				want, ok := tt.wantProjects[pp.path]
+				if !ok {
+					t.Errorf("unexpected project %s", pp.path)
+					continue
+				}
+				if len(pp.tasks) != want {
+					t.Errorf("%s: tasks = %d, want %d", pp.path, len(pp.tasks), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPickPlanTasks_NonTTYKeepsFullPlan(t *testing.T) {
+	orig := isInteractive
+	defer func() { isInteractive = orig }()
+	isInteractive = func() bool { return false }
+
+	plan := planPickerTestPlan()
+	got, err := pickPlanTasks(plan, logging.Component("test"))
+	if err != nil {
+		t.Fatalf("pickPlanTasks: %v", err)
+	}
+	if got != plan {
+		t.Fatal("expected the full plan unchanged in non-TTY context")
+	}
+}
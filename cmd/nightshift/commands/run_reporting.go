@@ -2,22 +2,52 @@ package commands
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/marcus/nightshift/internal/budget"
 	"github.com/marcus/nightshift/internal/config"
 	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/orchestrator"
 	"github.com/marcus/nightshift/internal/reporting"
+	"github.com/marcus/nightshift/internal/tasks"
 )
 
+// reportsDir is where run reports are saved: reporting.dir, or the default
+// reports dir. Override in tests.
+var reportsDir = func(cfg *config.Config) string {
+	if cfg != nil {
+		if dir := cfg.ExpandedReportsDir(); dir != "" {
+			return dir
+		}
+	}
+	return reporting.DefaultReportsDir()
+}
+
+// fallbackReportsDir is used when reportsDir isn't writable. Override in
+// tests.
+var fallbackReportsDir = func() string {
+	return filepath.Join(os.TempDir(), "nightshift-reports")
+}
+
 type runReport struct {
 	results    *reporting.RunResults
 	usedBudget int
+	// dir is where finalize saves the report; "" prints it to stdout.
+	dir string
+	// dirProblem explains why dir isn't the default reports dir; "" when it is.
+	dirProblem string
+	// projectIDs caches projectIdentity by project path.
+	projectIDs map[string]string
 }
 
-func newRunReport(start time.Time, startBudget int) *runReport {
-	return &runReport{
+func newRunReport(cfg *config.Config, start time.Time, startBudget int) *runReport {
+	r := &runReport{
 		results: &reporting.RunResults{
 			Date:            start,
 			StartTime:       start,
@@ -27,13 +57,226 @@ func newRunReport(start time.Time, startBudget int) *runReport {
 			Tasks:           []reporting.TaskResult{},
 		},
 	}
+	r.dir, r.dirProblem = resolveReportsDir(reportsDir(cfg), fallbackReportsDir())
+	return r
+}
+
+// resolveReportsDir returns dir when it can be written, otherwise fallback,
+// otherwise "" (print to stdout), with a note explaining any fallback.
+func resolveReportsDir(dir, fallback string) (string, string) {
+	err := checkDirWritable(dir)
+	if err == nil {
+		return dir, ""
+	}
+	problem := fmt.Sprintf("reports dir %s is not writable (%v)", dir, err)
+	if fallback != "" && checkDirWritable(fallback) == nil {
+		return fallback, problem
+	}
+	return "", problem
+}
+
+// checkDirWritable creates dir if needed and proves a file can be written
+// in it.
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// warnReportsDir reports a reports-dir fallback at run start.
+func (r *runReport) warnReportsDir(log *logging.Logger) {
+	if r == nil || r.dirProblem == "" {
+		return
+	}
+	msg := r.dirProblem + "; " + r.destination()
+	fmt.Println("WARNING: " + msg)
+	log.Warn(msg)
+}
+
+// destination describes where a fallback report goes.
+func (r *runReport) destination() string {
+	if r.dir == "" {
+		return "run report will be printed to stdout"
+	}
+	return "saving run reports to " + r.dir
+}
+
+// summaryNote is the run summary line for a reports-dir fallback, or "".
+func (r *runReport) summaryNote() string {
+	if r == nil || r.dirProblem == "" {
+		return ""
+	}
+	if r.dir == "" {
+		return "printed below (reports dir not writable)"
+	}
+	return "saved to " + r.dir + " (reports dir not writable)"
+}
+
+// applyReportedRuns feeds the completed and partial tasks in the run reports
+// under dir to the selector's cooldowns (tasks.since_report), so machines
+// sharing a reports dir skip work another one already did. Reported tasks
+// are matched to projects by identity rather than path, since each machine
+// may keep its checkout elsewhere. Each task counts from its run's end time.
+func applyReportedRuns(selector *tasks.Selector, dir string, projects []string, log *logging.Logger) {
+	runs, err := loadRunReports(dir)
+	if err != nil {
+		log.Warnf("since_report: %v", err)
+		return
+	}
+	ids := make(map[string]string, len(projects))
+	for _, project := range projects {
+		ids[project] = projectIdentity(project)
+	}
+	recorded := 0
+	for _, run := range runs {
+		at := run.results.EndTime
+		if at.IsZero() {
+			at = run.results.StartTime
+		}
+		if at.IsZero() {
+			continue
+		}
+		for _, task := range run.results.Tasks {
+			if task.TaskType == "" || task.Project == "" || (task.Status != "completed" && task.Status != "partial") {
+				continue
+			}
+			for _, project := range projects {
+				if reportedProjectMatches(task, project, ids[project]) {
+					selector.RecordReportedRun(task.TaskType, project, at)
+					recorded++
+				}
+			}
+		}
+	}
+	log.Infof("since_report: %d task completion(s) from %d report(s) in %s", recorded, len(runs), dir)
+}
+
+// minDurationSamples is the fewest reported tasks a cost tier needs before
+// their median replaces the tier's default duration estimate.
+const minDurationSamples = 3
+
+// reportedDurations returns the median duration per cost tier of the
+// completed and partial tasks in the run reports in dir, for tiers with at
+// least minDurationSamples of them.
+func reportedDurations(dir string) (tasks.DurationEstimates, error) {
+	runs, err := loadRunReports(dir)
+	if err != nil {
+		return nil, err
+	}
+	samples := make(map[tasks.CostTier][]time.Duration)
+	for _, run := range runs {
+		for _, task := range run.results.Tasks {
+			if task.Duration <= 0 || (task.Status != "completed" && task.Status != "partial") {
+				continue
+			}
+			def, err := tasks.GetDefinition(tasks.TaskType(task.TaskType))
+			if err != nil {
+				continue
+			}
+			samples[def.CostTier] = append(samples[def.CostTier], task.Duration)
+		}
+	}
+	estimates := make(tasks.DurationEstimates)
+	for tier, durations := range samples {
+		if len(durations) < minDurationSamples {
+			continue
+		}
+		slices.Sort(durations)
+		mid := len(durations) / 2
+		estimates[tier] = durations[mid]
+		if len(durations)%2 == 0 {
+			estimates[tier] = (durations[mid-1] + durations[mid]) / 2
+		}
+	}
+	return estimates, nil
+}
+
+// projectIdentity identifies a project across machines: its origin remote
+// URL as host/path (so SSH and HTTPS clones agree), or else the name of its
+// directory.
+func projectIdentity(project string) string {
+	out, err := exec.Command("git", "-C", project, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return filepath.Base(project)
+	}
+	remote := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(string(out)), "/"), ".git")
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		remote = u.Host + u.Path
+	} else if host, path, ok := strings.Cut(remote, ":"); ok && !strings.Contains(host, "/") {
+		// scp-like: user@host:owner/repo
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		remote = host + "/" + strings.TrimPrefix(path, "/")
+	}
+	if remote == "" {
+		return filepath.Base(project)
+	}
+	return strings.ToLower(remote)
+}
+
+// reportedProjectMatches reports whether a reported task was for project,
+// whose identity is id. Reports without a project ID (written before it was
+// recorded) match on the directory name.
+func reportedProjectMatches(task reporting.TaskResult, project, id string) bool {
+	switch {
+	case task.Project == project:
+		return true
+	case task.ProjectID != "":
+		return task.ProjectID == id
+	default:
+		return filepath.Base(task.Project) == filepath.Base(project)
+	}
+}
+
+// eventLogDir is where per-task orchestrator event logs are written, next
+// to the run reports; "" when reports are printed to stdout.
+func (r *runReport) eventLogDir() string {
+	if r == nil || r.dir == "" {
+		return ""
+	}
+	return filepath.Join(r.dir, "events")
 }
 
 func (r *runReport) addTask(task reporting.TaskResult) {
+	if task.ProjectID == "" && task.Project != "" {
+		if r.projectIDs == nil {
+			r.projectIDs = make(map[string]string)
+		}
+		id, ok := r.projectIDs[task.Project]
+		if !ok {
+			id = projectIdentity(task.Project)
+			r.projectIDs[task.Project] = id
+		}
+		task.ProjectID = id
+	}
 	r.results.Tasks = append(r.results.Tasks, task)
 	r.usedBudget += task.TokensUsed
 }
 
+// addNote records a run-level note shown in the report summary.
+func (r *runReport) addNote(note string) {
+	r.results.Notes = append(r.results.Notes, note)
+}
+
+// reportCommits converts the commits a task created for the run report.
+func reportCommits(commits []orchestrator.Commit) []reporting.TaskCommit {
+	if len(commits) == 0 {
+		return nil
+	}
+	out := make([]reporting.TaskCommit, len(commits))
+	for i, c := range commits {
+		out[i] = reporting.TaskCommit{SHA: c.SHA, Branch: c.Branch, Subject: c.Subject}
+	}
+	return out
+}
+
 func (r *runReport) finalize(cfg *config.Config, log *logging.Logger) {
 	if r == nil || r.results == nil || cfg == nil {
 		return
@@ -67,19 +310,84 @@ func (r *runReport) finalize(cfg *config.Config, log *logging.Logger) {
 		}
 	}
 
-	reportPath := reporting.DefaultRunReportPath(r.results.EndTime)
-	if err := reporting.SaveRunReport(r.results, reportPath, r.results.LogPath); err != nil {
-		log.Warnf("run report save: %v", err)
-	} else {
-		log.Infof("run report saved: %s", reportPath)
+	if r.dir != "" {
+		reportPath := filepath.Join(r.dir, filepath.Base(reporting.DefaultRunReportPath(r.results.EndTime)))
+		if err := reporting.SaveRunReport(r.results, reportPath, r.results.LogPath); err != nil {
+			log.Warnf("run report save: %v", err)
+		} else {
+			log.Infof("run report saved: %s", reportPath)
+		}
+
+		resultsPath := filepath.Join(r.dir, filepath.Base(reporting.DefaultRunResultsPath(r.results.EndTime)))
+		if err := reporting.SaveRunResults(r.results, resultsPath); err != nil {
+			log.Warnf("run results save: %v", err)
+			// Results are the one record of the run; don't lose them.
+			r.dumpResults(log)
+		} else {
+			log.Infof("run results saved: %s", resultsPath)
+		}
+		return
+	}
+	r.dumpResults(log)
+}
+
+// dumpResults prints the markdown run report to stdout when it can't be
+// saved anywhere.
+func (r *runReport) dumpResults(log *logging.Logger) {
+	content, err := reporting.RenderRunReport(r.results, r.results.LogPath)
+	if err != nil {
+		log.Warnf("run report render: %v", err)
+		return
 	}
+	fmt.Print("\n" + content)
+	log.Warn("run report printed to stdout: no writable reports dir")
+}
 
-	resultsPath := reporting.DefaultRunResultsPath(r.results.EndTime)
-	if err := reporting.SaveRunResults(r.results, resultsPath); err != nil {
-		log.Warnf("run results save: %v", err)
-	} else {
-		log.Infof("run results saved: %s", resultsPath)
+// captureProviderSnapshots records used-percent and reset time for each
+// candidate provider so reports can explain skipped or short runs.
+func captureProviderSnapshots(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger) []reporting.ProviderSnapshot {
+	if cfg == nil || budgetMgr == nil {
+		return nil
+	}
+	var snaps []reporting.ProviderSnapshot
+	names := providerPreference(cfg)
+	for _, name := range overflowProviders(cfg) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		switch name {
+		case "claude":
+			if !cfg.Providers.Claude.Enabled {
+				continue
+			}
+		case "codex":
+			if !cfg.Providers.Codex.Enabled {
+				continue
+			}
+		case "copilot":
+			if !cfg.Providers.Copilot.Enabled {
+				continue
+			}
+		}
+		snap := reporting.ProviderSnapshot{Provider: name}
+		used, err := budgetMgr.GetUsedPercent(name)
+		if err != nil {
+			snap.Error = err.Error()
+			if log != nil {
+				log.Warnf("snapshot %s: %v", name, err)
+			}
+		}
+		snap.UsedPercent = used
+		if reset, err := budgetMgr.GetResetTime(name); err == nil {
+			snap.ResetTime = reset
+		} else if log != nil {
+			log.Warnf("snapshot %s reset: %v", name, err)
+		}
+		snaps = append(snaps, snap)
 	}
+	return snaps
 }
 
 func calculateRunBudgetStart(cfg *config.Config, budgetMgr *budget.Manager, log *logging.Logger) int {
@@ -101,5 +409,12 @@ func calculateRunBudgetStart(cfg *config.Config, budgetMgr *budget.Manager, log
 			log.Warnf("budget codex: %v", err)
 		}
 	}
+	if cfg.Providers.Copilot.Enabled {
+		if allowance, err := budgetMgr.CalculateAllowance("copilot"); err == nil {
+			total += int(allowance.Allowance)
+		} else if log != nil {
+			log.Warnf("budget copilot: %v", err)
+		}
+	}
 	return total
 }
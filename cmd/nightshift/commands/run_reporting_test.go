@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/reporting"
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// unwritableDirs returns reports dirs that can't be written: one under a
+// read-only directory (skipped when running as root) and one under a file.
+func unwritableDirs(t *testing.T) map[string]string {
+	t.Helper()
+	base := t.TempDir()
+	dirs := map[string]string{}
+
+	readOnly := filepath.Join(base, "ro")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(readOnly, 0755) })
+	if checkDirWritable(filepath.Join(readOnly, "reports")) != nil {
+		dirs["read-only dir"] = filepath.Join(readOnly, "reports")
+	}
+
+	file := filepath.Join(base, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dirs["under a file"] = filepath.Join(file, "reports")
+	return dirs
+}
+
+func TestResolveReportsDir(t *testing.T) {
+	writable := t.TempDir()
+	if dir, problem := resolveReportsDir(writable, ""); dir != writable || problem != "" {
+		t.Errorf("writable: got (%q, %q)", dir, problem)
+	}
+
+	for name, bad := range unwritableDirs(t) {
+		t.Run(name, func(t *testing.T) {
+			fallback := filepath.Join(t.TempDir(), "fallback")
+			dir, problem := resolveReportsDir(bad, fallback)
+			if dir != fallback {
+				t.Errorf("dir = %q, want fallback %q", dir, fallback)
+			}
+			if !strings.Contains(problem, bad) {
+				t.Errorf("problem = %q, want it to name %s", problem, bad)
+			}
+
+			dir, problem = resolveReportsDir(bad, bad)
+			if dir != "" || problem == "" {
+				t.Errorf("no writable dir: got (%q, %q), want stdout", dir, problem)
+			}
+		})
+	}
+}
+
+func TestRunReportFinalize_UnwritableReportsDir(t *testing.T) {
+	origDir, origFallback := reportsDir, fallbackReportsDir
+	defer func() { reportsDir, fallbackReportsDir = origDir, origFallback }()
+
+	for name, bad := range unwritableDirs(t) {
+		t.Run(name, func(t *testing.T) {
+			reportsDir = func(*config.Config) string { return bad }
+			fallback := filepath.Join(t.TempDir(), "fallback")
+			fallbackReportsDir = func() string { return fallback }
+
+			report := newRunReport(&config.Config{}, time.Now(), 1000)
+			report.addTask(reporting.TaskResult{Project: "/p", TaskType: "lint-fix", Title: "Lint Fix", Status: "completed"})
+			report.finalize(&config.Config{}, logging.Component("test"))
+
+			files, _ := filepath.Glob(filepath.Join(fallback, "run-*"))
+			if len(files) != 2 {
+				t.Errorf("fallback files = %v, want .md and .json", files)
+			}
+			if note := report.summaryNote(); !strings.Contains(note, fallback) {
+				t.Errorf("summaryNote = %q, want fallback path", note)
+			}
+
+			// Nothing writable: the report is printed instead of lost.
+			fallbackReportsDir = func() string { return bad }
+			report = newRunReport(&config.Config{}, time.Now(), 1000)
+			report.addTask(reporting.TaskResult{Project: "/p", TaskType: "lint-fix", Title: "Lint Fix", Status: "completed"})
+			output := captureStdout(t, func() {
+				report.finalize(&config.Config{}, logging.Component("test"))
+			})
+			if !strings.Contains(output, "Lint Fix") {
+				t.Errorf("stdout dump missing task:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestProjectIdentity(t *testing.T) {
+	withRemote := func(remote string) string {
+		dir := filepath.Join(t.TempDir(), "app")
+		for _, args := range [][]string{{"init", "-q", dir}, {"-C", dir, "remote", "add", "origin", remote}} {
+			if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		return dir
+	}
+
+	const want = "github.com/acme/app"
+	for _, remote := range []string{
+		"git@github.com:acme/app.git",
+		"https://github.com/acme/app.git",
+		"ssh://git@GitHub.com/acme/app",
+	} {
+		if got := projectIdentity(withRemote(remote)); got != want {
+			t.Errorf("projectIdentity(%s) = %q, want %q", remote, got, want)
+		}
+	}
+
+	noRemote := filepath.Join(t.TempDir(), "scratch")
+	if err := os.Mkdir(noRemote, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectIdentity(noRemote); got != "scratch" {
+		t.Errorf("projectIdentity without a remote = %q, want the dir name", got)
+	}
+}
+
+func TestReportsDir(t *testing.T) {
+	if got := reportsDir(&config.Config{}); got != reporting.DefaultReportsDir() {
+		t.Errorf("default reportsDir = %q, want %q", got, reporting.DefaultReportsDir())
+	}
+	cfg := &config.Config{Reporting: config.ReportingConfig{Dir: "~/sync/nightshift"}}
+	home, _ := os.UserHomeDir()
+	if got, want := reportsDir(cfg), filepath.Join(home, "sync", "nightshift"); got != want {
+		t.Errorf("reportsDir with reporting.dir = %q, want %q", got, want)
+	}
+}
+
+func TestReportedDurations(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := reportedDurations(dir); err != nil || len(got) != 0 {
+		t.Fatalf("reportedDurations with no reports = %v, %v; want none", got, err)
+	}
+
+	task := func(taskType tasks.TaskType, status string, d time.Duration) reporting.TaskResult {
+		return reporting.TaskResult{Project: "/src/app", TaskType: string(taskType), Status: status, Duration: d}
+	}
+	runs := [][]reporting.TaskResult{
+		{
+			task(tasks.TaskLintFix, "completed", 4*time.Minute),
+			task(tasks.TaskDocsBackfill, "partial", 8*time.Minute),
+			task(tasks.TaskDocsBackfill, "failed", time.Hour),
+			task(tasks.TaskBugFinder, "completed", 30*time.Minute),
+		},
+		{
+			task(tasks.TaskLintFix, "completed", 6*time.Minute),
+			task(tasks.TaskLintFix, "completed", 20*time.Minute),
+			task(tasks.TaskLintFix, "skipped", 0),
+			task(tasks.TaskBugFinder, "completed", 40*time.Minute),
+			task("no-such-task", "completed", time.Minute),
+		},
+	}
+	end := time.Now().Add(-time.Hour)
+	for i, results := range runs {
+		at := end.Add(time.Duration(i) * time.Minute)
+		path := filepath.Join(dir, "run-"+at.Format("2006-01-02-150405")+".json")
+		if err := reporting.SaveRunResults(&reporting.RunResults{StartTime: at, EndTime: at, Tasks: results}, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := reportedDurations(dir)
+	if err != nil {
+		t.Fatalf("reportedDurations: %v", err)
+	}
+	// Low: 4m, 6m, 8m and 20m; the failed run and the skip don't count.
+	if got[tasks.CostLow] != 7*time.Minute {
+		t.Errorf("low median = %s, want 7m", got[tasks.CostLow])
+	}
+	// High has only two samples, so it keeps the default.
+	if d, ok := got[tasks.CostHigh]; ok {
+		t.Errorf("high = %s, want no estimate below %d samples", d, minDurationSamples)
+	}
+}
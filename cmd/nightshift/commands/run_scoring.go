@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// scoringHeader introduces the run --dry-run --verbose breakdown.
+const scoringHeader = "Scoring (score = base + staleness + context + source):"
+
+// preflightScoreJSON is one task's breakdown in run --dry-run --verbose
+// --format json output.
+type preflightScoreJSON struct {
+	Type         string  `json:"type"`
+	Score        float64 `json:"score"`
+	Base         float64 `json:"base"`
+	Staleness    float64 `json:"staleness"`
+	Context      float64 `json:"context"`
+	Source       float64 `json:"source"`
+	Verdict      string  `json:"verdict"`
+	CooldownLeft string  `json:"cooldown_left,omitempty"`
+}
+
+// scoringJSON converts a project's breakdown for preflight JSON output.
+func scoringJSON(scoring []tasks.TaskVerdict) []preflightScoreJSON {
+	var out []preflightScoreJSON
+	for _, tv := range scoring {
+		sj := preflightScoreJSON{
+			Type:      string(tv.Definition.Type),
+			Score:     tv.Scores.Total(),
+			Base:      tv.Scores.Base,
+			Staleness: tv.Scores.Staleness,
+			Context:   tv.Scores.Context,
+			Source:    tv.Scores.Source,
+			Verdict:   string(tv.Verdict),
+		}
+		if tv.CooldownLeft > 0 {
+			sj.CooldownLeft = formatCooldownDuration(tv.CooldownLeft)
+		}
+		out = append(out, sj)
+	}
+	return out
+}
+
+// scoringLines renders a project's breakdown, one line per task the
+// enabled/category/theme filters kept, followed by a count of the tasks
+// they dropped. Returns nil when there is no breakdown.
+func scoringLines(pp preflightProject, maxTokensPerTask int64) []string {
+	if len(pp.scoring) == 0 {
+		return nil
+	}
+	width := 0
+	for _, tv := range pp.scoring {
+		if scoringShown(tv.Verdict) {
+			width = max(width, len(tv.Definition.Type))
+		}
+	}
+
+	lines := []string{scoringHeader}
+	dropped := make(map[tasks.Verdict]int)
+	for _, tv := range pp.scoring {
+		if !scoringShown(tv.Verdict) {
+			dropped[tv.Verdict]++
+			continue
+		}
+		b := tv.Scores
+		lines = append(lines, fmt.Sprintf("  %-*s %5.1f = %.1f + %.1f + %.1f + %.1f  %s",
+			width, tv.Definition.Type, b.Total(), b.Base, b.Staleness, b.Context, b.Source,
+			scoringVerdict(pp, tv, maxTokensPerTask)))
+	}
+	if len(dropped) > 0 {
+		var parts []string
+		total := 0
+		for v, count := range dropped {
+			parts = append(parts, fmt.Sprintf("%d %s", count, v))
+			total += count
+		}
+		sort.Strings(parts)
+		lines = append(lines, fmt.Sprintf("  %d not considered: %s", total, strings.Join(parts, ", ")))
+	}
+	return lines
+}
+
+// scoringShown reports whether a verdict gets its own breakdown line.
+// Tasks dropped by configuration (disabled, outside the window's categories
+// or the day's theme, or mutating on a read-only project) are only counted.
+func scoringShown(v tasks.Verdict) bool {
+	switch v {
+	case tasks.VerdictDisabled, tasks.VerdictCategory, tasks.VerdictTheme, tasks.VerdictReadOnly:
+		return false
+	}
+	return true
+}
+
+// scoringVerdict describes a breakdown line's verdict, with the remaining
+// cooldown or the budget shortfall where relevant.
+func scoringVerdict(pp preflightProject, tv tasks.TaskVerdict, maxTokensPerTask int64) string {
+	switch tv.Verdict {
+	case tasks.VerdictCooldown:
+		if tv.CooldownLeft > 0 {
+			return fmt.Sprintf("cooldown (%s left)", formatCooldownDuration(tv.CooldownLeft))
+		}
+	case tasks.VerdictBudget:
+		if pp.provider != nil && pp.provider.allowance != nil {
+			return fmt.Sprintf("budget (reserves %s, %s left)",
+				formatK(int(tv.Definition.CappedMaxTokens(maxTokensPerTask))), formatK(int(pp.provider.allowance.Allowance)))
+		}
+	}
+	return string(tv.Verdict)
+}
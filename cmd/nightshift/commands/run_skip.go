@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// SkipCode identifies why preflight skipped a project or task. Codes are
+// stable so scripts consuming `run --dry-run --format json` can match on
+// them; the message is for humans and may change.
+type SkipCode string
+
+const (
+	SkipProcessedToday     SkipCode = "processed_today"     // project already ran today
+	SkipNoProvider         SkipCode = "no_provider"         // no provider CLI available
+	SkipBudgetExhausted    SkipCode = "budget_exhausted"    // every available provider is out of budget
+	SkipAuthExpired        SkipCode = "auth_expired"        // provider CLIs are installed but logged out
+	SkipInsufficientBudget SkipCode = "insufficient_budget" // a requested task doesn't fit the remaining budget
+	SkipReadOnly           SkipCode = "read_only"           // a requested task, or every enabled task, changes code on a read-only project
+	SkipCooldown           SkipCode = "cooldown"            // eligible tasks are all on cooldown
+	SkipRecentlyFailed     SkipCode = "recently_failed"     // remaining tasks failed here within tasks.recent_failure_window
+	SkipBelowMinScore      SkipCode = "below_min_score"     // no task reached scoring.min_score
+	SkipWindowFull         SkipCode = "window_full"         // no eligible task fits the time left for run --fill-window
+	SkipNoTasks            SkipCode = "no_tasks"            // nothing eligible within budget
+)
+
+// errBudgetExhausted is wrapped by selectProvider when at least one provider
+// was skipped for lack of budget.
+var errBudgetExhausted = errors.New("budget exhausted")
+
+// errInsufficientBudget is wrapped by selectTaskProvider when at least one
+// provider had budget left, but less than the task reserves.
+var errInsufficientBudget = errors.New("insufficient budget")
+
+// errAuthExpired matches selectProvider errors where at least one provider
+// was skipped because its CLI login expired.
+var errAuthExpired = errors.New("auth expired")
+
+// authExpiredError lists the providers skipped for an expired login, each
+// with the command that fixes it.
+type authExpiredError struct {
+	reasons []string
+}
+
+func (e *authExpiredError) Error() string { return strings.Join(e.reasons, "; ") }
+
+func (e *authExpiredError) Is(target error) bool { return target == errAuthExpired }
+
+// SkipReason records a preflight skip with a stable code and a human message.
+type SkipReason struct {
+	Code    SkipCode `json:"code"`
+	Project string   `json:"project,omitempty"` // empty for run-wide reasons
+	Message string   `json:"message"`
+}
+
+// String returns the human-readable form shown in plain and fancy output.
+func (r SkipReason) String() string {
+	if r.Project == "" {
+		return r.Message
+	}
+	return filepath.Base(r.Project) + ": " + r.Message
+}
+
+// providerSkipCode classifies a selectProvider error.
+func providerSkipCode(err error) SkipCode {
+	if errors.Is(err, errInsufficientBudget) {
+		return SkipInsufficientBudget
+	}
+	if errors.Is(err, errBudgetExhausted) {
+		return SkipBudgetExhausted
+	}
+	if errors.Is(err, errAuthExpired) {
+		return SkipAuthExpired
+	}
+	return SkipNoProvider
+}
+
+// preflightJSON is the --format json rendering of a preflight plan.
+type preflightJSON struct {
+	Branch           string                 `json:"branch,omitempty"`
+	Categories       []string               `json:"categories,omitempty"`
+	ThemeDay         string                 `json:"theme_day,omitempty"`
+	Theme            []string               `json:"theme,omitempty"`
+	MaxTokensPerTask int64                  `json:"max_tokens_per_task,omitempty"`
+	FillWindow       *fillWindowJSON        `json:"fill_window,omitempty"`
+	IgnoreBudget     bool                   `json:"ignore_budget,omitempty"`
+	Projects         []preflightProjectJSON `json:"projects"`
+	SkipReasons      []SkipReason           `json:"skip_reasons"`
+}
+
+// fillWindowJSON is the run --fill-window schedule: time left in the window
+// and the estimated time the planned tasks take.
+type fillWindowJSON struct {
+	LeftMinutes    int `json:"left_minutes"`
+	PlannedMinutes int `json:"planned_minutes"`
+}
+
+type preflightProjectJSON struct {
+	Path      string               `json:"path"`
+	ReadOnly  bool                 `json:"read_only,omitempty"`
+	Provider  string               `json:"provider,omitempty"`
+	Allowance int64                `json:"allowance,omitempty"`
+	Tasks     []preflightTaskJSON  `json:"tasks,omitempty"`
+	Skip      *SkipReason          `json:"skip,omitempty"`
+	Explain   []string             `json:"explain,omitempty"`
+	Scoring   []preflightScoreJSON `json:"scoring,omitempty"` // run --dry-run --verbose
+}
+
+type preflightTaskJSON struct {
+	Type          string  `json:"type"`
+	Name          string  `json:"name"`
+	Score         float64 `json:"score"`
+	CostTier      string  `json:"cost_tier"`
+	Model         string  `json:"model,omitempty"`
+	Provider      string  `json:"provider,omitempty"` // provider the task runs on
+	MinTokens     int     `json:"min_tokens"`
+	MaxTokens     int     `json:"max_tokens"`
+	Minutes       int     `json:"estimated_minutes,omitempty"`        // run --fill-window only
+	MinutesSource string  `json:"estimated_minutes_source,omitempty"` // "reports" (median of past runs) or "default" (cost tier)
+}
+
+// displayPreflightJSON writes the plan as indented JSON.
+func displayPreflightJSON(w io.Writer, plan *preflightPlan) error {
+	out := preflightJSON{
+		Branch:           plan.branch,
+		Categories:       plan.categories,
+		ThemeDay:         plan.themeDay,
+		Theme:            plan.theme,
+		MaxTokensPerTask: plan.maxTokensPerTask,
+		IgnoreBudget:     plan.ignoreBudget,
+		Projects:         make([]preflightProjectJSON, 0, len(plan.projects)),
+		SkipReasons:      plan.skipReasons,
+	}
+	if plan.fillWindow > 0 {
+		out.FillWindow = &fillWindowJSON{
+			LeftMinutes:    int(plan.fillWindow.Minutes()),
+			PlannedMinutes: int(plan.planned.Minutes()),
+		}
+	}
+	if out.SkipReasons == nil {
+		out.SkipReasons = []SkipReason{}
+	}
+	for _, pp := range plan.projects {
+		pj := preflightProjectJSON{Path: pp.path, ReadOnly: pp.readOnly, Explain: pp.explain, Scoring: scoringJSON(pp.scoring)}
+		if pp.provider != nil {
+			pj.Provider = pp.provider.name
+			if pp.provider.allowance != nil {
+				pj.Allowance = pp.provider.allowance.Allowance
+			}
+		}
+		if pp.skipReason != "" {
+			pj.Skip = &SkipReason{Code: pp.skipCode, Project: pp.path, Message: pp.skipReason}
+		}
+		for _, st := range pp.tasks {
+			minTok, maxTok := st.Definition.EstimatedTokens()
+			tj := preflightTaskJSON{
+				Type:      string(st.Definition.Type),
+				Name:      st.Definition.Name,
+				Score:     st.Score,
+				CostTier:  st.Definition.CostTier.String(),
+				Model:     pp.models[st.Definition.Type],
+				MinTokens: minTok,
+				MaxTokens: maxTok,
+			}
+			if plan.fillWindow > 0 {
+				d, measured := plan.durations.For(st.Definition)
+				tj.Minutes = int(d.Minutes())
+				tj.MinutesSource = "default"
+				if measured {
+					tj.MinutesSource = "reports"
+				}
+			}
+			if choice := pp.providerFor(st.Definition.Type); choice != nil {
+				tj.Provider = choice.name
+			}
+			pj.Tasks = append(pj.Tasks, tj)
+		}
+		out.Projects = append(out.Projects, pj)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
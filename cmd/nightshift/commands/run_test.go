@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,10 +13,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/agents"
 	"github.com/marcus/nightshift/internal/budget"
 	"github.com/marcus/nightshift/internal/config"
 	"github.com/marcus/nightshift/internal/db"
 	"github.com/marcus/nightshift/internal/logging"
+	"github.com/marcus/nightshift/internal/reporting"
 	"github.com/marcus/nightshift/internal/state"
 	"github.com/marcus/nightshift/internal/tasks"
 )
@@ -115,6 +122,220 @@ func TestSelectProvider_FallbackOnBudget(t *testing.T) {
 	}
 }
 
+func TestSelectProvider_Overflow(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name         string
+		preference   []string
+		claudePct    float64
+		codexPct     float64
+		claudeInPath bool
+		ignoreBudget bool
+		fallback     string
+		want         string // "" = error
+	}{
+		{name: "primary has budget", claudePct: 10, codexPct: 0, claudeInPath: true, want: "claude"},
+		{name: "primary exhausted", claudePct: 100, codexPct: 0, claudeInPath: true, want: "codex"},
+		{name: "overflow ignores preference order", preference: []string{"codex", "claude"}, claudePct: 10, codexPct: 0, claudeInPath: true, want: "claude"},
+		{name: "fallback off still overflows", claudePct: 100, codexPct: 0, claudeInPath: true, fallback: "off", want: "codex"},
+		{name: "both exhausted", claudePct: 100, codexPct: 100, claudeInPath: true},
+		{name: "primary not installed", claudePct: 0, codexPct: 0, claudeInPath: false},
+		{name: "ignore budget stays on primary", claudePct: 100, codexPct: 0, claudeInPath: true, ignoreBudget: true, want: "claude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.claudeInPath {
+				dir := t.TempDir()
+				makeExecutable(t, dir, "codex")
+				t.Setenv("PATH", dir)
+			}
+			cfg := &config.Config{
+				Providers: config.ProvidersConfig{
+					Preference: tt.preference,
+					Overflow:   []string{"codex"},
+					Fallback:   tt.fallback,
+					Claude:     config.ProviderConfig{Enabled: true},
+					Codex:      config.ProviderConfig{Enabled: true},
+				},
+				Budget: config.BudgetConfig{Mode: "daily", MaxPercent: 75, WeeklyTokens: 700000},
+			}
+			claude := &mockUsage{name: "claude", pct: tt.claudePct}
+			codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: tt.codexPct}}
+			copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+			budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+			choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), tt.ignoreBudget)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("selectProvider = %s, want error", choice.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectProvider error: %v", err)
+			}
+			if choice.name != tt.want {
+				t.Errorf("provider = %s, want %s", choice.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectProvider_Strategy(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// claude: more used but a much larger budget; codex: less used.
+	tests := []struct {
+		strategy    string
+		codexPct    float64
+		want        string
+		wantRanking string
+	}{
+		{strategy: "", codexPct: 10, want: "claude"},
+		{strategy: "preference", codexPct: 10, want: "claude"},
+		{strategy: "least-used", codexPct: 10, want: "codex", wantRanking: "codex 10.0% used, claude 40.0% used"},
+		{strategy: "most-budget", codexPct: 10, want: "claude", wantRanking: "claude "},
+		{strategy: "least-used", codexPct: 100, want: "claude", wantRanking: "claude 40.0% used"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy+fmt.Sprintf("/codex-%.0f", tt.codexPct), func(t *testing.T) {
+			cfg := &config.Config{
+				Providers: config.ProvidersConfig{
+					Preference: []string{"claude", "codex"},
+					Strategy:   tt.strategy,
+					Claude:     config.ProviderConfig{Enabled: true},
+					Codex:      config.ProviderConfig{Enabled: true},
+				},
+				Budget: config.BudgetConfig{
+					Mode:         "daily",
+					MaxPercent:   75,
+					WeeklyTokens: 700000,
+					PerProvider:  map[string]int{"claude": 7000000, "codex": 700000},
+				},
+			}
+			claude := &mockUsage{name: "claude", pct: 40}
+			codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: tt.codexPct}}
+			copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+			budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+			choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+			if err != nil {
+				t.Fatalf("selectProvider error: %v", err)
+			}
+			if choice.name != tt.want {
+				t.Errorf("provider = %s, want %s", choice.name, tt.want)
+			}
+			ranking := strings.Join(choice.ranking, ", ")
+			if !strings.HasPrefix(ranking, tt.wantRanking) || (tt.wantRanking == "") != (ranking == "") {
+				t.Errorf("ranking = %q, want prefix %q", ranking, tt.wantRanking)
+			}
+			note := providerExplainNote(choice)
+			if !strings.HasPrefix(note, "provider "+tt.want+": strategy "+cfg.GetProviderStrategy()) {
+				t.Errorf("explain note = %q", note)
+			}
+		})
+	}
+}
+
+func TestSelectProvider_CopilotPreferred(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "copilot")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Preference: []string{"copilot", "claude"},
+			Claude:     config.ProviderConfig{Enabled: true},
+			Copilot:    config.ProviderConfig{Enabled: true},
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+	}
+
+	claude := &mockUsage{name: "claude", pct: 0}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err != nil {
+		t.Fatalf("selectProvider error: %v", err)
+	}
+	if choice.name != "copilot" {
+		t.Fatalf("provider = %s, want copilot", choice.name)
+	}
+	if choice.agent.Name() != "copilot" {
+		t.Fatalf("agent = %s, want copilot", choice.agent.Name())
+	}
+}
+
+func TestSelectProvider_FallbackToCopilot(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	makeExecutable(t, tmp, "copilot")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// No explicit preference: default order ends with copilot
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Claude:  config.ProviderConfig{Enabled: true},
+			Codex:   config.ProviderConfig{Enabled: true},
+			Copilot: config.ProviderConfig{Enabled: true},
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+	}
+
+	claude := &mockUsage{name: "claude", pct: 100}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 100}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err != nil {
+		t.Fatalf("selectProvider error: %v", err)
+	}
+	if choice.name != "copilot" {
+		t.Fatalf("provider = %s, want copilot", choice.name)
+	}
+}
+
+func TestProviderPreference(t *testing.T) {
+	tests := []struct {
+		name string
+		pref []string
+		want []string
+	}{
+		{name: "default", pref: nil, want: []string{"claude", "codex", "copilot"}},
+		{name: "copilot first", pref: []string{"Copilot", "claude"}, want: []string{"copilot", "claude"}},
+		{name: "unknown and duplicates dropped", pref: []string{"copilot", "gemini", "copilot"}, want: []string{"copilot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Providers: config.ProvidersConfig{Preference: tt.pref}}
+			got := providerPreference(cfg)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("providerPreference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSelectProvider_NoProvidersEnabled(t *testing.T) {
 	cfg := &config.Config{
 		Providers: config.ProvidersConfig{
@@ -167,6 +388,51 @@ func TestSelectProvider_AllBudgetExhausted(t *testing.T) {
 	}
 }
 
+func TestSelectProvider_FallbackOff(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Claude:     config.ProviderConfig{Enabled: true},
+			Codex:      config.ProviderConfig{Enabled: true},
+			Preference: []string{"codex", "claude"},
+			Fallback:   "off",
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+	}
+	claude := &mockUsage{name: "claude", pct: 0}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 100}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	_, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err == nil {
+		t.Fatal("expected error with fallback off, got a provider")
+	}
+	if got := err.Error(); !strings.Contains(got, "primary provider codex unavailable, fallback disabled") {
+		t.Errorf("error = %q, want primary provider unavailable", got)
+	}
+	if code := providerSkipCode(err); code != SkipBudgetExhausted {
+		t.Errorf("skip code = %s, want %s", code, SkipBudgetExhausted)
+	}
+
+	cfg.Providers.Fallback = "on"
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err != nil {
+		t.Fatalf("selectProvider with fallback on: %v", err)
+	}
+	if choice.name != "claude" {
+		t.Errorf("provider = %s, want claude", choice.name)
+	}
+}
+
 func TestSelectProvider_CLINotInPath(t *testing.T) {
 	// Empty PATH so no CLIs are found
 	t.Setenv("PATH", t.TempDir())
@@ -196,6 +462,39 @@ func TestSelectProvider_CLINotInPath(t *testing.T) {
 	}
 }
 
+func TestSelectProvider_SandboxSkipsHostCLI(t *testing.T) {
+	// Only docker is on the host; the provider CLIs live in the image
+	bin := t.TempDir()
+	makeExecutable(t, bin, "docker")
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Claude: config.ProviderConfig{Enabled: true},
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+		Sandbox: config.SandboxConfig{Type: config.SandboxDocker, Image: "agents:latest"},
+	}
+	claude := &mockUsage{name: "claude", pct: 0}
+	budgetMgr := budget.NewManager(cfg, claude, nil, nil)
+
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err != nil {
+		t.Fatalf("selectProvider: %v", err)
+	}
+	if choice.name != "claude" {
+		t.Errorf("provider = %q, want claude", choice.name)
+	}
+	if _, err := installedAgentByName(cfg, "claude"); err != nil {
+		t.Errorf("installedAgentByName: %v", err)
+	}
+}
+
 func makeExecutable(t *testing.T, dir, name string) {
 	t.Helper()
 	path := filepath.Join(dir, name)
@@ -394,15 +693,15 @@ func TestMaxTasks_IgnoredWhenTaskSet(t *testing.T) {
 
 	// When taskFilter is set, maxTasks is ignored - only the specified task runs
 	params := executeRunParams{
-		cfg:        cfg,
-		budgetMgr:  budgetMgr,
-		selector:   selector,
-		st:         st,
-		projects:   []string{project},
-		taskFilter: "lint-fix",
-		maxTasks:   5, // should be ignored
-		dryRun:     true,
-		log:        logging.Component("test"),
+		cfg:         cfg,
+		budgetMgr:   budgetMgr,
+		selector:    selector,
+		st:          st,
+		projects:    []string{project},
+		taskFilters: []string{"lint-fix"},
+		maxTasks:    5, // should be ignored
+		dryRun:      true,
+		log:         logging.Component("test"),
 	}
 
 	err := executeRun(context.Background(), params)
@@ -465,80 +764,610 @@ func TestSelectProvider_IgnoreBudget_PopulatesAllowance(t *testing.T) {
 			WeeklyTokens: 700000,
 		},
 	}
-	claude := &mockUsage{name: "claude", pct: 100}
-	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
-	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
-	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+	claude := &mockUsage{name: "claude", pct: 100}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), true)
+	if err != nil {
+		t.Fatalf("selectProvider error: %v", err)
+	}
+	if choice.allowance == nil {
+		t.Fatal("allowance should be populated even when ignoring budget")
+	}
+	if choice.allowance.UsedPercent != 100 {
+		t.Fatalf("UsedPercent = %.1f, want 100", choice.allowance.UsedPercent)
+	}
+}
+
+func TestSelectProvider_IgnoreBudget_False_StillRejectsBudget(t *testing.T) {
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Claude: config.ProviderConfig{Enabled: true},
+			Codex:  config.ProviderConfig{Enabled: true},
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+	}
+	claude := &mockUsage{name: "claude", pct: 100}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 100}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	_, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err == nil {
+		t.Fatal("expected error with ignoreBudget=false and exhausted budget")
+	}
+	if !strings.Contains(err.Error(), "budget exhausted") {
+		t.Fatalf("error = %q, want it to contain 'budget exhausted'", err.Error())
+	}
+}
+
+// --- Preflight tests ---
+
+// newPreflightParams creates a standard executeRunParams for preflight testing.
+func newPreflightParams(t *testing.T, projects []string) executeRunParams {
+	t.Helper()
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	makeExecutable(t, tmp, "codex")
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	st := newTestRunState(t)
+	cfg := newTestRunConfig()
+	selector := tasks.NewSelector(cfg, st)
+	claude := &mockUsage{name: "claude", pct: 0}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+	return executeRunParams{
+		cfg:       cfg,
+		budgetMgr: budgetMgr,
+		selector:  selector,
+		st:        st,
+		projects:  projects,
+		maxTasks:  1,
+		dryRun:    true,
+		log:       logging.Component("test"),
+	}
+}
+
+func TestBuildPreflight_WindowCategories(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.maxTasks = 5
+	params.ignoreBudget = true
+	now := time.Now()
+	params.cfg.Schedule.Window = &config.WindowConfig{
+		Start:      now.Add(-time.Hour).Format("15:04"),
+		End:        now.Add(time.Hour).Format("15:04"),
+		Categories: []string{"analysis"},
+	}
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.categories) != 1 || plan.categories[0] != "analysis" {
+		t.Errorf("plan.categories = %v, want [analysis]", plan.categories)
+	}
+	if len(plan.projects) != 1 || len(plan.projects[0].tasks) == 0 {
+		t.Fatalf("expected analysis tasks to be planned, got %+v", plan.projects)
+	}
+	for _, st := range plan.projects[0].tasks {
+		if st.Definition.Category != tasks.CategoryAnalysis {
+			t.Errorf("planned %s (%s) outside window categories", st.Definition.Type, st.Definition.Category)
+		}
+	}
+
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if !strings.Contains(buf.String(), "Categories: analysis (schedule window)") {
+		t.Errorf("preflight missing category line:\n%s", buf.String())
+	}
+
+	// Outside the window the limit does not apply.
+	params.cfg.Schedule.Window.Start = now.Add(2 * time.Hour).Format("15:04")
+	params.cfg.Schedule.Window.End = now.Add(3 * time.Hour).Format("15:04")
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.categories) != 0 {
+		t.Errorf("plan.categories outside window = %v, want none", plan.categories)
+	}
+}
+
+func TestBuildPreflight_FillWindow(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.ignoreBudget = true
+	params.fillWindow = 50 * time.Minute
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.projects) != 1 || len(plan.projects[0].tasks) < 2 {
+		t.Fatalf("expected --fill-window to override max-tasks 1, got %+v", plan.projects)
+	}
+	var sum time.Duration
+	for _, st := range plan.projects[0].tasks {
+		sum += st.Definition.EstimatedDuration()
+	}
+	if sum != plan.planned || sum > params.fillWindow {
+		t.Errorf("planned %s (tasks sum to %s), want at most %s", plan.planned, sum, params.fillWindow)
+	}
+	if left := params.fillWindow - sum; left >= tasks.CostLow.EstimatedDuration() {
+		t.Errorf("%s left unfilled, want less than one low-cost task", left)
+	}
+
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	out := buf.String()
+	first := plan.projects[0].tasks[0].Definition
+	for _, want := range []string{
+		"Fill window: ~" + formatCooldownDuration(sum) + " planned of 50m left in the schedule window (estimates: cost tier defaults)",
+		"est. ~" + formatCooldownDuration(first.EstimatedDuration()) + " at +0m",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("preflight missing %q:\n%s", want, out)
+		}
+	}
+
+	// Once the window is used up, later projects are skipped.
+	second := t.TempDir()
+	params = newPreflightParams(t, []string{project, second})
+	params.ignoreBudget = true
+	params.fillWindow = tasks.CostLow.EstimatedDuration()
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.projects) != 2 || len(plan.projects[0].tasks) != 1 {
+		t.Fatalf("expected one task for the first project, got %+v", plan.projects)
+	}
+	if got := plan.projects[1].skipCode; got != SkipWindowFull {
+		t.Errorf("second project skip code = %q, want %q", got, SkipWindowFull)
+	}
+
+	// Past run reports replace the default: low-cost tasks that took 2m
+	// fit five to the 10m a single one was assumed to take.
+	dir := t.TempDir()
+	orig := reportsDir
+	reportsDir = func(*config.Config) string { return dir }
+	t.Cleanup(func() { reportsDir = orig })
+	end := time.Now().Add(-time.Hour)
+	var reported []reporting.TaskResult
+	for range minDurationSamples {
+		reported = append(reported, reporting.TaskResult{Project: project, TaskType: string(tasks.TaskLintFix), Status: "completed", Duration: 2 * time.Minute})
+	}
+	if err := reporting.SaveRunResults(&reporting.RunResults{StartTime: end, EndTime: end, Tasks: reported}, filepath.Join(dir, "run-"+end.Format("2006-01-02-150405")+".json")); err != nil {
+		t.Fatal(err)
+	}
+	params = newPreflightParams(t, []string{project})
+	params.ignoreBudget = true
+	params.fillWindow = tasks.CostLow.EstimatedDuration()
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if n := len(plan.projects[0].tasks); n < 2 || plan.planned != time.Duration(n)*2*time.Minute {
+		t.Fatalf("planned %d task(s) in %s, want several at the reported 2m each", n, plan.planned)
+	}
+	buf.Reset()
+	displayPreflight(&buf, plan)
+	if want := "(estimates: medians from past run reports)"; !strings.Contains(buf.String(), want) {
+		t.Errorf("preflight missing %q:\n%s", want, buf.String())
+	}
+	buf.Reset()
+	if err := displayPreflightJSON(&buf, plan); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"estimated_minutes_source": "reports"`) {
+		t.Errorf("preflight JSON missing the reports source:\n%s", buf.String())
+	}
+}
+
+func TestBuildPreflight_ReadOnly(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.maxTasks = 5
+	params.ignoreBudget = true
+	params.cfg.Projects = []config.ProjectConfig{{Path: project, ReadOnly: true}}
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.projects) != 1 || len(plan.projects[0].tasks) == 0 {
+		t.Fatalf("expected read-only tasks to be planned, got %+v", plan.projects)
+	}
+	for _, st := range plan.projects[0].tasks {
+		if !st.Definition.ReadOnly() {
+			t.Errorf("planned %s (%s) on a read-only project", st.Definition.Type, st.Definition.Category)
+		}
+	}
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if !strings.Contains(buf.String(), filepath.Base(project)+" (read-only)") {
+		t.Errorf("preflight missing read-only marker:\n%s", buf.String())
+	}
+
+	// Requested tasks that change code are skipped with a reason.
+	params.taskFilters = []string{"lint-fix", "doc-drift"}
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if pp := plan.projects[0]; len(pp.tasks) != 1 || pp.tasks[0].Definition.Type != tasks.TaskDocDrift {
+		t.Fatalf("tasks = %+v, want only doc-drift", pp.tasks)
+	}
+	if len(plan.skipReasons) != 1 || plan.skipReasons[0].Code != SkipReadOnly {
+		t.Fatalf("skipReasons = %+v, want one %s", plan.skipReasons, SkipReadOnly)
+	}
+
+	params.taskFilters = []string{"lint-fix"}
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if pp := plan.projects[0]; pp.skipCode != SkipReadOnly {
+		t.Errorf("skipCode = %q (%s), want %s", pp.skipCode, pp.skipReason, SkipReadOnly)
+	}
+}
+
+func TestBuildPreflight_Verbose(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.cfg.Tasks.Enabled = []string{"lint-fix", "docs-backfill"}
+	params.cfg.Tasks.Priorities = map[string]int{"lint-fix": 5}
+	params.st.RecordTaskRun(project, "docs-backfill")
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if strings.Contains(buf.String(), "Scoring") {
+		t.Errorf("default dry-run shows the scoring breakdown:\n%s", buf.String())
+	}
+
+	params.verbose = true
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	buf.Reset()
+	displayPreflight(&buf, plan)
+	out := buf.String()
+	for _, want := range []string{
+		scoringHeader,
+		"lint-fix        8.0 = 5.0 + 3.0 + 0.0 + 0.0  selected",
+		"docs-backfill   0.0 = 0.0 + 0.0 + 0.0 + 0.0  cooldown (",
+		" not considered: ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("verbose preflight missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := displayPreflightJSON(&buf, plan); err != nil {
+		t.Fatalf("displayPreflightJSON: %v", err)
+	}
+	var got preflightJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	scoring := got.Projects[0].Scoring
+	if len(scoring) != len(tasks.AllDefinitions()) {
+		t.Fatalf("JSON scoring has %d tasks, want all %d", len(scoring), len(tasks.AllDefinitions()))
+	}
+	if first := scoring[0]; first.Type != "lint-fix" || first.Verdict != "selected" || first.Base != 5 || first.Score != 8 {
+		t.Errorf("JSON scoring[0] = %+v, want selected lint-fix scoring 5 + 3", first)
+	}
+}
+
+func TestBuildPreflight_Theme(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.maxTasks = 5
+	params.ignoreBudget = true
+	today := strings.ToLower(time.Now().Weekday().String())
+	params.cfg.Schedule.Themes = map[string][]string{today: {"analysis", "docs-backfill", "no-such-task"}}
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if plan.themeDay != today || strings.Join(plan.theme, ",") != "analysis,docs-backfill" {
+		t.Errorf("theme = %q %v, want %s [analysis docs-backfill]", plan.themeDay, plan.theme, today)
+	}
+	if len(plan.projects) != 1 || len(plan.projects[0].tasks) == 0 {
+		t.Fatalf("expected themed tasks to be planned, got %+v", plan.projects)
+	}
+	for _, st := range plan.projects[0].tasks {
+		if st.Definition.Category != tasks.CategoryAnalysis && st.Definition.Type != tasks.TaskDocsBackfill {
+			t.Errorf("planned %s (%s) outside today's theme", st.Definition.Type, st.Definition.Category)
+		}
+	}
+
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if want := "Theme: analysis, docs-backfill (" + today + ")"; !strings.Contains(buf.String(), want) {
+		t.Errorf("preflight missing %q:\n%s", want, buf.String())
+	}
+
+	// A day without a theme falls back to every task.
+	params.cfg.Schedule.Themes = map[string][]string{"someday": {"analysis"}}
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if plan.theme != nil {
+		t.Errorf("theme on unthemed day = %v, want none", plan.theme)
+	}
+}
+
+func TestTierPreference(t *testing.T) {
+	cfg := &config.Config{Providers: config.ProvidersConfig{
+		Preference: []string{"claude", "codex", "copilot"},
+		TierPreference: map[string][]string{
+			"low":       {"Copilot"},
+			"very-high": {"claude", "codex"},
+			"medium":    {},
+		},
+	}}
+	tests := []struct {
+		tier tasks.CostTier
+		want string
+		has  bool
+	}{
+		{tasks.CostLow, "copilot,claude,codex", true},
+		{tasks.CostMedium, "claude,codex,copilot", false},
+		{tasks.CostHigh, "claude,codex,copilot", false},
+		{tasks.CostVeryHigh, "claude,codex,copilot", true},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tierPreference(cfg, tt.tier), ","); got != tt.want {
+			t.Errorf("tierPreference(%s) = %s, want %s", tt.tier.Name(), got, tt.want)
+		}
+		if got := hasTierPreference(cfg, tt.tier); got != tt.has {
+			t.Errorf("hasTierPreference(%s) = %v, want %v", tt.tier.Name(), got, tt.has)
+		}
+	}
+}
+
+func TestBuildPreflight_TierPreference(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.maxTasks = 100
+	params.ignoreBudget = true
+	params.cfg.Providers.TierPreference = map[string][]string{"low": {"codex"}}
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if len(plan.projects) != 1 || len(plan.projects[0].tasks) == 0 {
+		t.Fatalf("expected tasks to be planned, got %+v", plan.projects)
+	}
+	pp := plan.projects[0]
+	if pp.provider.name != "claude" {
+		t.Fatalf("project provider = %s, want claude", pp.provider.name)
+	}
+	var sawLow, sawOther bool
+	for _, st := range pp.tasks {
+		want := "claude"
+		if st.Definition.CostTier == tasks.CostLow {
+			want = "codex"
+			sawLow = true
+		} else {
+			sawOther = true
+		}
+		if got := pp.providerFor(st.Definition.Type).name; got != want {
+			t.Errorf("%s (%s) runs on %s, want %s", st.Definition.Type, st.Definition.CostTier.Name(), got, want)
+		}
+	}
+	if !sawLow || !sawOther {
+		t.Fatalf("plan needs low and other tier tasks, got %d tasks", len(pp.tasks))
+	}
+
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if !strings.Contains(buf.String(), ", provider=codex") {
+		t.Errorf("preflight missing per-task provider:\n%s", buf.String())
+	}
+
+	// With fallback off, a low-tier task whose first provider is missing
+	// is skipped instead of moving to another provider.
+	claudeOnly := t.TempDir()
+	makeExecutable(t, claudeOnly, "claude")
+	t.Setenv("PATH", claudeOnly)
+	params.cfg.Providers.Fallback = "off"
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	for _, st := range plan.projects[0].tasks {
+		if st.Definition.CostTier == tasks.CostLow {
+			t.Errorf("low-tier task %s planned without its tier provider", st.Definition.Type)
+		}
+	}
+	found := false
+	for _, r := range plan.skipReasons {
+		if strings.Contains(r.Message, "no provider for cost tier low") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("skip reasons = %+v, want a cost tier skip", plan.skipReasons)
+	}
+}
+
+func TestBuildPreflight_TierPreferenceBudget(t *testing.T) {
+	// Claude has 75k of daily allowance, codex only 37.5k: less than a
+	// low-tier task reserves (50k).
+	newParams := func(t *testing.T, order []string) executeRunParams {
+		params := newPreflightParams(t, []string{t.TempDir()})
+		params.maxTasks = 100
+		params.taskFilters = []string{"lint-fix"}
+		params.cfg.Providers.TierPreference = map[string][]string{"low": order}
+		params.budgetMgr = budget.NewManager(params.cfg,
+			&mockUsage{name: "claude", pct: 0},
+			&mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 50}},
+			nil)
+		return params
+	}
+
+	t.Run("next provider in tier order", func(t *testing.T) {
+		plan, err := buildPreflight(newParams(t, []string{"codex", "claude"}))
+		if err != nil {
+			t.Fatalf("buildPreflight: %v", err)
+		}
+		pp := plan.projects[0]
+		if len(pp.tasks) != 1 {
+			t.Fatalf("planned %d tasks, want lint-fix", len(pp.tasks))
+		}
+		if got := pp.providerFor(pp.tasks[0].Definition.Type).name; got != "claude" {
+			t.Errorf("lint-fix runs on %s, want claude (codex can't cover its reservation)", got)
+		}
+	})
+
+	t.Run("no provider fits", func(t *testing.T) {
+		// With fallback off only the tier's first provider is tried
+		params := newParams(t, []string{"codex"})
+		params.cfg.Providers.Fallback = "off"
+		plan, err := buildPreflight(params)
+		if err != nil {
+			t.Fatalf("buildPreflight: %v", err)
+		}
+		if got := len(plan.projects[0].tasks); got != 0 {
+			t.Errorf("planned %d tasks, want lint-fix skipped", got)
+		}
+		found := false
+		for _, r := range plan.skipReasons {
+			if r.Code == SkipInsufficientBudget && strings.Contains(r.Message, "codex (37.5K left, 50.0K needed)") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("skip reasons = %+v, want insufficient_budget for codex", plan.skipReasons)
+		}
+	})
+
+	t.Run("reservations add up per provider", func(t *testing.T) {
+		// Doubled budget: claude has 150k, codex 75k. Codex fits one
+		// low-tier task, so the second moves on to claude.
+		params := newParams(t, []string{"codex", "claude"})
+		params.taskFilters = []string{"lint-fix", "docs-backfill"}
+		params.cfg.Budget.WeeklyTokens *= 2
+		plan, err := buildPreflight(params)
+		if err != nil {
+			t.Fatalf("buildPreflight: %v", err)
+		}
+		pp := plan.projects[0]
+		if len(pp.tasks) != 2 {
+			t.Fatalf("planned %d tasks, want lint-fix and docs-backfill", len(pp.tasks))
+		}
+		got := map[string]string{}
+		for _, st := range pp.tasks {
+			got[string(st.Definition.Type)] = pp.providerFor(st.Definition.Type).name
+		}
+		if got["lint-fix"] != "codex" || got["docs-backfill"] != "claude" {
+			t.Errorf("providers = %v, want lint-fix on codex and docs-backfill on claude", got)
+		}
+	})
+
+	t.Run("ignore budget", func(t *testing.T) {
+		params := newParams(t, []string{"codex"})
+		params.ignoreBudget = true
+		plan, err := buildPreflight(params)
+		if err != nil {
+			t.Fatalf("buildPreflight: %v", err)
+		}
+		pp := plan.projects[0]
+		if len(pp.tasks) != 1 || pp.providerFor(pp.tasks[0].Definition.Type).name != "codex" {
+			t.Errorf("with --ignore-budget lint-fix should run on codex, got %d tasks", len(pp.tasks))
+		}
+	})
+}
+
+func TestBuildPreflight_SinceReport(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.maxTasks = 100
+	params.ignoreBudget = true
+
+	dir := t.TempDir()
+	orig := reportsDir
+	reportsDir = func(*config.Config) string { return dir }
+	t.Cleanup(func() { reportsDir = orig })
+	end := time.Now().Add(-time.Hour)
+	results := &reporting.RunResults{StartTime: end.Add(-time.Hour), EndTime: end, Tasks: []reporting.TaskResult{
+		{Project: project, TaskType: string(tasks.TaskLintFix), Status: "completed"},
+		{Project: project, TaskType: string(tasks.TaskDocsBackfill), Status: "failed"},
+	}}
+	if err := reporting.SaveRunResults(results, filepath.Join(dir, "run-"+end.Format("2006-01-02-150405")+".json")); err != nil {
+		t.Fatal(err)
+	}
+
+	planned := func() map[tasks.TaskType]bool {
+		plan, err := buildPreflight(params)
+		if err != nil {
+			t.Fatalf("buildPreflight: %v", err)
+		}
+		got := make(map[tasks.TaskType]bool)
+		for _, pp := range plan.projects {
+			for _, st := range pp.tasks {
+				got[st.Definition.Type] = true
+			}
+		}
+		return got
+	}
 
-	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), true)
-	if err != nil {
-		t.Fatalf("selectProvider error: %v", err)
+	if !planned()[tasks.TaskLintFix] {
+		t.Fatal("lint-fix not planned without since_report")
 	}
-	if choice.allowance == nil {
-		t.Fatal("allowance should be populated even when ignoring budget")
+	params.cfg.Tasks.SinceReport = true
+	got := planned()
+	if got[tasks.TaskLintFix] {
+		t.Error("lint-fix planned although a report shows it completed an hour ago")
 	}
-	if choice.allowance.UsedPercent != 100 {
-		t.Fatalf("UsedPercent = %.1f, want 100", choice.allowance.UsedPercent)
+	if !got[tasks.TaskDocsBackfill] {
+		t.Error("docs-backfill not planned; failed reported tasks should not start a cooldown")
 	}
-}
-
-func TestSelectProvider_IgnoreBudget_False_StillRejectsBudget(t *testing.T) {
-	tmp := t.TempDir()
-	makeExecutable(t, tmp, "claude")
-	makeExecutable(t, tmp, "codex")
-	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
-
-	cfg := &config.Config{
-		Providers: config.ProvidersConfig{
-			Claude: config.ProviderConfig{Enabled: true},
-			Codex:  config.ProviderConfig{Enabled: true},
-		},
-		Budget: config.BudgetConfig{
-			Mode:         "daily",
-			MaxPercent:   75,
-			WeeklyTokens: 700000,
-		},
+	if onCooldown, _, _ := params.selector.IsOnCooldown(tasks.TaskLintFix, project); !onCooldown {
+		t.Error("IsOnCooldown(lint-fix) = false, want true")
 	}
-	claude := &mockUsage{name: "claude", pct: 100}
-	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 100}}
-	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
-	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
 
-	_, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
-	if err == nil {
-		t.Fatal("expected error with ignoreBudget=false and exhausted budget")
+	// Another machine keeps its checkout elsewhere; reports match on the
+	// project's identity, which without a remote is the directory name.
+	elsewhere := filepath.Join("/home/other", filepath.Base(project))
+	results = &reporting.RunResults{StartTime: end.Add(-time.Hour), EndTime: end, Tasks: []reporting.TaskResult{
+		{Project: elsewhere, ProjectID: filepath.Base(project), TaskType: string(tasks.TaskDocsBackfill), Status: "completed"},
+		{Project: elsewhere, ProjectID: "github.com/acme/" + filepath.Base(project), TaskType: string(tasks.TaskBugFinder), Status: "completed"},
+	}}
+	if err := reporting.SaveRunResults(results, filepath.Join(dir, "run-"+end.Add(time.Second).Format("2006-01-02-150405")+".json")); err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(err.Error(), "budget exhausted") {
-		t.Fatalf("error = %q, want it to contain 'budget exhausted'", err.Error())
+	got = planned()
+	if got[tasks.TaskDocsBackfill] {
+		t.Error("docs-backfill planned although another machine's report shows it completed")
 	}
-}
-
-// --- Preflight tests ---
-
-// newPreflightParams creates a standard executeRunParams for preflight testing.
-func newPreflightParams(t *testing.T, projects []string) executeRunParams {
-	t.Helper()
-	tmp := t.TempDir()
-	makeExecutable(t, tmp, "claude")
-	makeExecutable(t, tmp, "codex")
-	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
-
-	st := newTestRunState(t)
-	cfg := newTestRunConfig()
-	selector := tasks.NewSelector(cfg, st)
-	claude := &mockUsage{name: "claude", pct: 0}
-	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
-	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
-	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
-	return executeRunParams{
-		cfg:       cfg,
-		budgetMgr: budgetMgr,
-		selector:  selector,
-		st:        st,
-		projects:  projects,
-		maxTasks:  1,
-		dryRun:    true,
-		log:       logging.Component("test"),
+	if !got[tasks.TaskBugFinder] {
+		t.Error("bug-finder not planned; a report for a different project identity should not match")
 	}
 }
 
@@ -589,11 +1418,75 @@ func TestBuildPreflight_SkippedProject(t *testing.T) {
 	if pp.skipReason != "already processed today" {
 		t.Fatalf("skipReason = %q, want 'already processed today'", pp.skipReason)
 	}
+	if pp.skipCode != SkipProcessedToday {
+		t.Fatalf("skipCode = %q, want %q", pp.skipCode, SkipProcessedToday)
+	}
 	if len(plan.skipReasons) == 0 {
 		t.Fatal("expected skip reasons to be populated")
 	}
-	if !strings.Contains(plan.skipReasons[0], "already processed today") {
-		t.Fatalf("skipReasons[0] = %q, want to contain 'already processed today'", plan.skipReasons[0])
+	reason := plan.skipReasons[0]
+	if reason.Code != SkipProcessedToday || reason.Project != project {
+		t.Fatalf("skipReasons[0] = %+v, want code %q for %s", reason, SkipProcessedToday, project)
+	}
+	if !strings.Contains(reason.String(), "already processed today") {
+		t.Fatalf("skipReasons[0] = %q, want to contain 'already processed today'", reason.String())
+	}
+}
+
+func TestDisplayPreflightJSON_SkipCodes(t *testing.T) {
+	plan := &preflightPlan{
+		projects: []preflightProject{
+			{
+				path:       "/home/user/skipped-proj",
+				skipReason: "already processed today",
+				skipCode:   SkipProcessedToday,
+			},
+		},
+		skipReasons: []SkipReason{
+			{Code: SkipProcessedToday, Project: "/home/user/skipped-proj", Message: "already processed today"},
+			{Code: SkipBudgetExhausted, Message: "no provider: budget exhausted: claude"},
+		},
+	}
+
+	var buf strings.Builder
+	if err := displayPreflightJSON(&buf, plan); err != nil {
+		t.Fatalf("displayPreflightJSON: %v", err)
+	}
+
+	var got preflightJSON
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if len(got.Projects) != 1 || got.Projects[0].Skip == nil {
+		t.Fatalf("projects = %+v, want 1 skipped project", got.Projects)
+	}
+	if got.Projects[0].Skip.Code != SkipProcessedToday {
+		t.Errorf("project skip code = %q, want %q", got.Projects[0].Skip.Code, SkipProcessedToday)
+	}
+	if len(got.SkipReasons) != 2 {
+		t.Fatalf("skip_reasons = %d, want 2", len(got.SkipReasons))
+	}
+	if got.SkipReasons[1].Code != SkipBudgetExhausted {
+		t.Errorf("skip_reasons[1].code = %q, want %q", got.SkipReasons[1].Code, SkipBudgetExhausted)
+	}
+}
+
+func TestProviderSkipCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want SkipCode
+	}{
+		{"budget", fmt.Errorf("%w: claude", errBudgetExhausted), SkipBudgetExhausted},
+		{"mixed", fmt.Errorf("%w: claude; CLI not in PATH: codex", errBudgetExhausted), SkipBudgetExhausted},
+		{"not in path", errors.New("no provider CLIs found in PATH"), SkipNoProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providerSkipCode(tt.err); got != tt.want {
+				t.Errorf("providerSkipCode() = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -795,7 +1688,7 @@ func TestDisplayPreflight_SkippedSection(t *testing.T) {
 func TestBuildPreflight_TaskFilter(t *testing.T) {
 	project := t.TempDir()
 	params := newPreflightParams(t, []string{project})
-	params.taskFilter = "lint-fix"
+	params.taskFilters = []string{"lint-fix"}
 
 	plan, err := buildPreflight(params)
 	if err != nil {
@@ -816,7 +1709,7 @@ func TestBuildPreflight_TaskFilter(t *testing.T) {
 func TestBuildPreflight_InvalidTaskFilter(t *testing.T) {
 	project := t.TempDir()
 	params := newPreflightParams(t, []string{project})
-	params.taskFilter = "nonexistent-task"
+	params.taskFilters = []string{"lint-fix", "nonexistent-task"}
 
 	_, err := buildPreflight(params)
 	if err == nil {
@@ -827,6 +1720,80 @@ func TestBuildPreflight_InvalidTaskFilter(t *testing.T) {
 	}
 }
 
+func TestBuildPreflight_MultipleTaskFilters(t *testing.T) {
+	project := t.TempDir()
+	params := newPreflightParams(t, []string{project})
+	params.taskFilters = []string{"docs-backfill", "lint-fix"}
+	params.ignoreBudget = true // test config budget is too small for both
+
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	pp := plan.projects[0]
+	if len(pp.tasks) != 2 {
+		t.Fatalf("tasks = %d, want 2", len(pp.tasks))
+	}
+	if pp.tasks[0].Definition.Type != "docs-backfill" || pp.tasks[1].Definition.Type != "lint-fix" {
+		t.Fatalf("task order = [%s %s], want [docs-backfill lint-fix]",
+			pp.tasks[0].Definition.Type, pp.tasks[1].Definition.Type)
+	}
+}
+
+func TestSelectFilteredTasks_BudgetCutoff(t *testing.T) {
+	st := newTestRunState(t)
+	selector := tasks.NewSelector(newTestRunConfig(), st)
+
+	lint, _ := tasks.GetDefinition("lint-fix")
+	docs, _ := tasks.GetDefinition("docs-backfill")
+	_, lintMax := lint.EstimatedTokens()
+
+	// Budget covers only the first task; the second must be skipped.
+	selected, skipped := selectFilteredTasks(selector, []tasks.TaskDefinition{lint, docs}, "/proj", int64(lintMax))
+	if len(selected) != 1 || selected[0].Definition.Type != "lint-fix" {
+		t.Fatalf("selected = %v, want [lint-fix]", selected)
+	}
+	if len(skipped) != 1 || skipped[0] != "docs-backfill" {
+		t.Fatalf("skipped = %v, want [docs-backfill]", skipped)
+	}
+
+	// First task always runs, even when over budget.
+	selected, _ = selectFilteredTasks(selector, []tasks.TaskDefinition{lint}, "/proj", 0)
+	if len(selected) != 1 {
+		t.Fatalf("selected = %d, want 1 (first task always runs)", len(selected))
+	}
+}
+
+func TestParseTaskFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "single", args: []string{"--task", "lint-fix"}, want: []string{"lint-fix"}},
+		{name: "comma", args: []string{"--task", "lint-fix,docs-backfill"}, want: []string{"lint-fix", "docs-backfill"}},
+		{name: "repeated", args: []string{"-t", "docs-backfill", "-t", "lint-fix"}, want: []string{"docs-backfill", "lint-fix"}},
+		{name: "dedupe and trim", args: []string{"--task", "lint-fix, lint-fix,,"}, want: []string{"lint-fix"}},
+		{name: "none", args: nil, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "run"}
+			cmd.Flags().StringSliceP("task", "t", nil, "")
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := parseTaskFilters(cmd)
+			if err != nil {
+				t.Fatalf("parseTaskFilters: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // --- Confirmation prompt tests ---
 
 func TestConfirmRun_YesFlagSkipsPrompt(t *testing.T) {
@@ -1251,3 +2218,400 @@ func TestDisplayPreflight_NoWarningsWhenBudgetRespected(t *testing.T) {
 		t.Errorf("output should not contain 'Warnings:' when ignoreBudget=false\nGot:\n%s", output)
 	}
 }
+
+func TestExplainTopN(t *testing.T) {
+	lint, _ := tasks.GetDefinition(tasks.TaskLintFix)
+	dead, _ := tasks.GetDefinition(tasks.TaskDeadCode)
+	drift, _ := tasks.GetDefinition(tasks.TaskDocDrift)
+
+	off := explainTopN(tasks.TopNExplanation{Selected: []tasks.ScoredTask{{Definition: dead}}})
+	if len(off) != 2 || off[0] != "min score: off" || !strings.Contains(off[1], "off") {
+		t.Errorf("unbalanced notes = %v", off)
+	}
+
+	on := explainTopN(tasks.TopNExplanation{
+		Balanced:  true,
+		Selected:  []tasks.ScoredTask{{Definition: dead}, {Definition: lint}},
+		Displaced: []tasks.ScoredTask{{Definition: drift, Score: 9}},
+	})
+	want := []string{
+		"min score: off",
+		"category balancing: on (round-robin by category)",
+		"picked dead-code [Analysis]",
+		"picked lint-fix [PR]",
+		"displaced doc-drift [Analysis] (score=9.0)",
+	}
+	if strings.Join(on, "\n") != strings.Join(want, "\n") {
+		t.Errorf("balanced notes = %q, want %q", on, want)
+	}
+
+	thresh := explainTopN(tasks.TopNExplanation{
+		MinScore: 3,
+		BelowMin: []tasks.ScoredTask{{Definition: lint, Score: 1.5}},
+	})
+	want = []string{
+		"min score: 3.0",
+		"below min lint-fix (score=1.5)",
+		"category balancing: off (top by score)",
+	}
+	if strings.Join(thresh, "\n") != strings.Join(want, "\n") {
+		t.Errorf("threshold notes = %q, want %q", thresh, want)
+	}
+}
+
+func TestMaxFailuresReached(t *testing.T) {
+	tests := []struct {
+		failed, limit int
+		want          bool
+	}{
+		{failed: 0, limit: 0, want: false},
+		{failed: 10, limit: 0, want: false},
+		{failed: 2, limit: 3, want: false},
+		{failed: 3, limit: 3, want: true},
+		{failed: 4, limit: 3, want: true},
+	}
+	for _, tt := range tests {
+		if got := maxFailuresReached(tt.failed, tt.limit); got != tt.want {
+			t.Errorf("maxFailuresReached(%d, %d) = %v, want %v", tt.failed, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteRun_MaxFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxFailures int
+		wantRuns    int
+		wantNote    bool
+	}{
+		{name: "unlimited", maxFailures: 0, wantRuns: 4},
+		{name: "stops across projects", maxFailures: 3, wantRuns: 3, wantNote: true},
+		{name: "stops within project", maxFailures: 1, wantRuns: 1, wantNote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects := []string{t.TempDir(), t.TempDir()}
+			params := newPreflightParams(t, projects)
+
+			// Stub agents that always fail
+			tmp := t.TempDir()
+			for _, name := range []string{"claude", "codex"} {
+				path := filepath.Join(tmp, name)
+				if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+					t.Fatalf("write %s: %v", name, err)
+				}
+			}
+			t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			params.dryRun = false
+			params.yes = true
+			params.maxTasks = 2
+			params.maxFailures = tt.maxFailures
+			params.report = newRunReport(params.cfg, time.Now(), 0)
+
+			output := captureStdout(t, func() {
+				if err := executeRun(context.Background(), params); err != nil {
+					t.Fatalf("executeRun: %v", err)
+				}
+			})
+
+			if got := strings.Count(output, "--- Running:"); got != tt.wantRuns {
+				t.Errorf("ran %d tasks, want %d\nGot:\n%s", got, tt.wantRuns, output)
+			}
+			notes := params.report.results.Notes
+			if tt.wantNote {
+				if len(notes) != 1 || !strings.Contains(notes[0], "--max-failures") {
+					t.Errorf("report notes = %q, want a --max-failures note", notes)
+				}
+				if !strings.Contains(output, "stopped early") {
+					t.Errorf("output missing stop note\nGot:\n%s", output)
+				}
+			} else if len(notes) != 0 {
+				t.Errorf("report notes = %q, want none", notes)
+			}
+		})
+	}
+}
+
+func TestExecuteRun_ProjectTimeout(t *testing.T) {
+	projects := []string{t.TempDir(), t.TempDir()}
+	params := newPreflightParams(t, projects)
+
+	// Slow stub agents: each task outlasts the project timeout
+	tmp := t.TempDir()
+	for _, name := range []string{"claude", "codex"} {
+		path := filepath.Join(tmp, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\nsleep 0.2\nexit 1\n"), 0755); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	params.dryRun = false
+	params.yes = true
+	params.maxTasks = 2
+	params.projectTimeout = 100 * time.Millisecond
+	params.report = newRunReport(params.cfg, time.Now(), 0)
+
+	output := captureStdout(t, func() {
+		if err := executeRun(context.Background(), params); err != nil {
+			t.Fatalf("executeRun: %v", err)
+		}
+	})
+
+	// One task per project starts; the second is skipped, and the next
+	// project still gets its turn.
+	if got := strings.Count(output, "--- Running:"); got != 2 {
+		t.Errorf("ran %d tasks, want 2\nGot:\n%s", got, output)
+	}
+	skipped := 0
+	for _, task := range params.report.results.Tasks {
+		if task.Status == "skipped" && task.SkipReason == "project timeout" {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Errorf("skipped %d tasks for project timeout, want 2", skipped)
+	}
+	if !strings.Contains(output, "Project timeout (100ms) reached") {
+		t.Errorf("output missing timeout note\nGot:\n%s", output)
+	}
+}
+
+func TestResolveProjects_ScanRootsMergedAndDeduped(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"app", "lib"} {
+		if err := os.MkdirAll(filepath.Join(root, name, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	explicit := filepath.Join(root, "lib")
+
+	cfg := &config.Config{
+		Projects:  []config.ProjectConfig{{Path: explicit}},
+		ScanRoots: []string{root},
+	}
+	got, err := resolveProjects(cfg, "")
+	if err != nil {
+		t.Fatalf("resolveProjects: %v", err)
+	}
+	want := []string{explicit, filepath.Join(root, "app")}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("projects = %v, want %v", got, want)
+	}
+}
+
+func TestTaskModels(t *testing.T) {
+	cfg := &config.Config{Tasks: config.TasksConfig{Models: map[string]string{
+		string(tasks.TaskLintFix):      "haiku",
+		string(tasks.TaskDocsBackfill): "gpt-5",
+	}}}
+	lint, _ := tasks.GetDefinition(tasks.TaskLintFix)
+	docs, _ := tasks.GetDefinition(tasks.TaskDocsBackfill)
+	selected := []tasks.ScoredTask{{Definition: lint}, {Definition: docs}}
+
+	models, notes := taskModels(cfg, "claude", selected)
+	if models[tasks.TaskLintFix] != "haiku" || len(models) != 1 {
+		t.Errorf("models = %v, want only lint-fix=haiku", models)
+	}
+	if len(notes) != 1 || !strings.Contains(notes[0], "gpt-5") {
+		t.Errorf("notes = %v, want one note about gpt-5", notes)
+	}
+
+	var buf strings.Builder
+	displayPreflight(&buf, &preflightPlan{projects: []preflightProject{{
+		path:   "/home/user/app",
+		tasks:  selected,
+		models: models,
+	}}})
+	if !strings.Contains(buf.String(), "model=haiku") {
+		t.Errorf("preflight missing model=haiku\n%s", buf.String())
+	}
+}
+
+func TestReadProjectsFile(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app")
+	lib := filepath.Join(dir, "lib")
+	for _, p := range []string{app, lib} {
+		if err := os.Mkdir(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := filepath.Join(dir, "projects.txt")
+	content := "# after the dependency bump\n" + app + "\n\n  " + lib + "  \n" + app + "\n"
+	if err := os.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readProjectsFile(list)
+	if err != nil {
+		t.Fatalf("readProjectsFile: %v", err)
+	}
+	if strings.Join(got, ",") != app+","+lib {
+		t.Errorf("projects = %v, want [%s %s]", got, app, lib)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	if err := os.WriteFile(missing, []byte(app+"\n"+filepath.Join(dir, "gone")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readProjectsFile(missing); err == nil || !strings.Contains(err.Error(), "missing.txt:2") {
+		t.Errorf("readProjectsFile(missing) error = %v, want line 2 reported", err)
+	}
+}
+
+func TestEnsurePATH_ExtraDirs(t *testing.T) {
+	dir := t.TempDir()
+	shims := filepath.Join(dir, "shims")
+	if err := os.Mkdir(shims, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	absent := filepath.Join(dir, "absent")
+	t.Setenv("PATH", "/usr/bin")
+
+	missing := ensurePATH([]string{shims, absent}, logging.Component("test"))
+	if len(missing) != 1 || missing[0] != absent {
+		t.Errorf("missing = %v, want [%s]", missing, absent)
+	}
+	entries := filepath.SplitList(os.Getenv("PATH"))
+	if entries[0] != "/usr/bin" {
+		t.Errorf("PATH should keep existing entries first, got %v", entries)
+	}
+	if len(entries) < 2 || entries[1] != shims {
+		t.Errorf("PATH should append configured dirs before defaults, got %v", entries)
+	}
+	for _, e := range entries {
+		if e == absent {
+			t.Errorf("PATH should not contain missing dir %s", absent)
+		}
+	}
+
+	// A second call must not duplicate entries.
+	before := os.Getenv("PATH")
+	ensurePATH([]string{shims}, logging.Component("test"))
+	if got := os.Getenv("PATH"); got != before {
+		t.Errorf("PATH changed on second call:\n%s\n%s", before, got)
+	}
+}
+
+func TestSelectProvider_AuthExpired(t *testing.T) {
+	agents.ResetAuthCache()
+	t.Cleanup(agents.ResetAuthCache)
+	t.Setenv("HOME", t.TempDir())
+
+	tmp := t.TempDir()
+	makeExecutable(t, tmp, "claude")
+	script := "#!/bin/sh\nif [ \"$1\" = login ]; then echo 'Not logged in' >&2; exit 1; fi\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(tmp, "codex"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", tmp+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := &config.Config{
+		Providers: config.ProvidersConfig{
+			Preference: []string{"codex", "claude"},
+			Claude:     config.ProviderConfig{Enabled: true},
+			Codex:      config.ProviderConfig{Enabled: true},
+		},
+		Budget: config.BudgetConfig{
+			Mode:         "daily",
+			MaxPercent:   75,
+			WeeklyTokens: 700000,
+		},
+	}
+	claude := &mockUsage{name: "claude", pct: 0}
+	codex := &mockCodexUsage{mockUsage: mockUsage{name: "codex", pct: 0}}
+	copilot := &mockCopilotUsage{mockUsage: mockUsage{name: "copilot", pct: 0}}
+	budgetMgr := budget.NewManager(cfg, claude, codex, copilot)
+
+	choice, err := selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err != nil {
+		t.Fatalf("selectProvider error: %v", err)
+	}
+	if choice.name != "claude" {
+		t.Fatalf("provider = %s, want claude", choice.name)
+	}
+
+	cfg.Providers.Claude.Enabled = false
+	_, err = selectProvider(cfg, budgetMgr, logging.Component("test"), false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if got := providerSkipCode(err); got != SkipAuthExpired {
+		t.Errorf("skip code = %s, want %s", got, SkipAuthExpired)
+	}
+	if want := "codex: auth expired — run `codex login`"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestParseEnvFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "valid", values: []string{"ANTHROPIC_BASE_URL=https://staging.example.com", "EMPTY=", "_X1=a=b"}},
+		{name: "missing equals", values: []string{"TOKEN"}, wantErr: true},
+		{name: "empty key", values: []string{"=secret"}, wantErr: true},
+		{name: "bad key", values: []string{"1BAD=secret"}, wantErr: true},
+		{name: "space in key", values: []string{"MY VAR=secret"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnvFlags(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEnvFlags(%q) error = %v, wantErr %v", tt.values, err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret") {
+				t.Errorf("error leaks value: %v", err)
+			}
+			if err == nil && len(got) != len(tt.values) {
+				t.Errorf("parseEnvFlags(%q) = %q", tt.values, got)
+			}
+		})
+	}
+
+	if got := envKeys([]string{"A=1", "B=x=y"}); strings.Join(got, ",") != "A,B" {
+		t.Errorf("envKeys = %v, want [A B]", got)
+	}
+}
+
+func TestOverdraftReason(t *testing.T) {
+	cfg := &config.Config{
+		Budget: config.BudgetConfig{
+			Mode:                "weekly",
+			MaxPercent:          100,
+			WeeklyTokens:        700000,
+			AggressiveEndOfWeek: true,
+		},
+	}
+	// 90% used: 70000 tokens left this week.
+	claude := &mockUsage{name: "claude", pct: 90}
+	budgetMgr := budget.NewManager(cfg, claude, nil, nil)
+	log := logging.Component("test")
+
+	tests := []struct {
+		name     string
+		provider string
+		tokens   int64
+		want     string
+	}{
+		{"fits", "claude", 70000, ""},
+		{"overdraws", "claude", 100000, "weekly budget overdraft"},
+		{"usage unreadable", "codex", 100000, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overdraftReason(budgetMgr, tt.provider, tt.tokens, log); got != tt.want {
+				t.Errorf("overdraftReason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := overdraftReason(nil, "claude", 1<<40, log); got != "" {
+		t.Errorf("overdraftReason(nil manager) = %q, want empty", got)
+	}
+}
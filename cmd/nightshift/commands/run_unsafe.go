@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/security"
+)
+
+// unsafeProviderFlags returns the CLI flags nightshift passes to provider
+// because of a dangerously_* setting.
+func unsafeProviderFlags(cfg *config.Config, provider string) []string {
+	switch provider {
+	case "claude":
+		if cfg.Providers.Claude.DangerouslySkipPermissions {
+			return []string{"--dangerously-skip-permissions"}
+		}
+	case "codex":
+		if cfg.Providers.Codex.DangerouslyBypassApprovalsAndSandbox {
+			return []string{"--dangerously-bypass-approvals-and-sandbox"}
+		}
+	case "copilot":
+		if cfg.Providers.Copilot.DangerouslySkipPermissions {
+			return []string{"--allow-all-tools"}
+		}
+	}
+	return nil
+}
+
+// sensitiveProjectPaths returns the projects that resolve, after following
+// symlinks, to a directory the project-path guardrail refuses ($HOME, /,
+// /tmp and friends).
+func sensitiveProjectPaths(projects []string) []string {
+	var out []string
+	for _, path := range projects {
+		sensitive := security.ValidateProjectPath(path) != nil
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && security.ValidateProjectPath(resolved) != nil {
+			sensitive = true
+		}
+		if sensitive {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// collectUnsafe records the unsafe flags active for the plan's selected
+// providers and the scanned projects that sit in sensitive locations.
+func collectUnsafe(plan *preflightPlan, cfg *config.Config, projects []string) {
+	seen := map[string]bool{}
+	for _, pp := range plan.projects {
+		for _, choice := range pp.providers() {
+			if seen[choice.name] {
+				continue
+			}
+			seen[choice.name] = true
+			for _, flag := range unsafeProviderFlags(cfg, choice.name) {
+				plan.unsafeFlags = append(plan.unsafeFlags, choice.name+": "+flag)
+			}
+		}
+	}
+	if len(plan.unsafeFlags) > 0 {
+		plan.sensitivePaths = sensitiveProjectPaths(projects)
+	}
+}
+
+// needsUnsafeConfirm reports whether an unsafe flag is on while a project
+// resolves to a sensitive location.
+func (plan *preflightPlan) needsUnsafeConfirm() bool {
+	return len(plan.unsafeFlags) > 0 && len(plan.sensitivePaths) > 0
+}
+
+// warnings returns the preflight warning lines shared by both renderers.
+func (plan *preflightPlan) warnings() []string {
+	var out []string
+	if plan.ignoreBudget {
+		out = append(out, "--ignore-budget is set: budget limits bypassed")
+	}
+	if plan.warnUnsafe {
+		for _, flag := range plan.unsafeFlags {
+			out = append(out, "unsafe flag active: "+flag)
+		}
+	}
+	for _, path := range plan.sensitivePaths {
+		out = append(out, "sensitive project path with unsafe flags: "+path)
+	}
+	return out
+}
+
+// confirmUnsafeRun asks for an explicit "yes" before running with unsafe
+// flags in a sensitive project path. --yes does not skip it; only --force
+// does. Without a terminal there is no one to ask, so the run is refused.
+func confirmUnsafeRun(p executeRunParams, plan *preflightPlan, in io.Reader, out io.Writer) (bool, error) {
+	if p.force || !plan.needsUnsafeConfirm() {
+		return true, nil
+	}
+	if !isInteractive() {
+		return false, fmt.Errorf("unsafe provider flags (%s) with sensitive project path(s) %s: re-run with --force to proceed",
+			strings.Join(plan.unsafeFlags, ", "), strings.Join(plan.sensitivePaths, ", "))
+	}
+	_, _ = fmt.Fprintf(out, "Unsafe provider flags give the agent broad filesystem access in %s.\n", strings.Join(plan.sensitivePaths, ", "))
+	_, _ = fmt.Fprint(out, "Type 'yes' to continue: ")
+	scanner := bufio.NewScanner(in)
+	if scanner.Scan() {
+		if strings.EqualFold(strings.TrimSpace(scanner.Text()), "yes") {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("read stdin: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestCollectUnsafe(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+
+	tests := []struct {
+		name          string
+		skip          bool
+		projects      []string
+		wantFlags     []string
+		wantSensitive []string
+	}{
+		{
+			name:     "flag off",
+			projects: []string{home},
+		},
+		{
+			name:      "flag on, safe project",
+			skip:      true,
+			projects:  []string{project},
+			wantFlags: []string{"claude: --dangerously-skip-permissions"},
+		},
+		{
+			name:          "flag on, home project",
+			skip:          true,
+			projects:      []string{project, home},
+			wantFlags:     []string{"claude: --dangerously-skip-permissions"},
+			wantSensitive: []string{home},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Providers.Claude.DangerouslySkipPermissions = tt.skip
+			plan := &preflightPlan{projects: []preflightProject{
+				{path: tt.projects[0], provider: &providerChoice{name: "claude"}},
+			}}
+			collectUnsafe(plan, cfg, tt.projects)
+			if !slices.Equal(plan.unsafeFlags, tt.wantFlags) {
+				t.Errorf("unsafeFlags = %v, want %v", plan.unsafeFlags, tt.wantFlags)
+			}
+			if !slices.Equal(plan.sensitivePaths, tt.wantSensitive) {
+				t.Errorf("sensitivePaths = %v, want %v", plan.sensitivePaths, tt.wantSensitive)
+			}
+		})
+	}
+}
+
+func TestPreflightWarnings_Unsafe(t *testing.T) {
+	plan := &preflightPlan{
+		warnUnsafe:     true,
+		unsafeFlags:    []string{"codex: --dangerously-bypass-approvals-and-sandbox"},
+		sensitivePaths: []string{"/tmp"},
+	}
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	for _, want := range []string{
+		"unsafe flag active: codex: --dangerously-bypass-approvals-and-sandbox",
+		"sensitive project path with unsafe flags: /tmp",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("preflight missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	plan.warnUnsafe = false
+	if got := plan.warnings(); len(got) != 1 || strings.Contains(got[0], "unsafe flag active") {
+		t.Errorf("warnings with --warn-unsafe=false = %v", got)
+	}
+}
+
+func TestConfirmUnsafeRun(t *testing.T) {
+	orig := isInteractive
+	defer func() { isInteractive = orig }()
+
+	risky := &preflightPlan{
+		unsafeFlags:    []string{"claude: --dangerously-skip-permissions"},
+		sensitivePaths: []string{"/"},
+	}
+	tests := []struct {
+		name        string
+		plan        *preflightPlan
+		params      executeRunParams
+		interactive bool
+		input       string
+		want        bool
+		wantErr     bool
+	}{
+		{name: "no unsafe flags", plan: &preflightPlan{sensitivePaths: []string{"/"}}, want: true},
+		{name: "force", plan: risky, params: executeRunParams{force: true}, want: true},
+		{name: "yes does not skip", plan: risky, params: executeRunParams{yes: true}, interactive: true, input: "\n"},
+		{name: "typed yes", plan: risky, interactive: true, input: "yes\n", want: true},
+		{name: "typed y is not enough", plan: risky, interactive: true, input: "y\n"},
+		{name: "non-tty refuses", plan: risky, params: executeRunParams{yes: true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isInteractive = func() bool { return tt.interactive }
+			var out bytes.Buffer
+			got, err := confirmUnsafeRun(tt.params, tt.plan, strings.NewReader(tt.input), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("proceed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+)
+
+// validatePrompt is the no-op sent by `run --validate`. It exercises the
+// real agent path (binary, auth, flags) without touching the project.
+const validatePrompt = "This is a connectivity check from nightshift. Do not read or modify any files and do not run any commands. Reply with exactly: OK"
+
+// validateTimeout bounds each provider's validation call.
+const validateTimeout = 3 * time.Minute
+
+// providerValidation is the outcome of one provider's no-op call.
+type providerValidation struct {
+	provider string
+	workDir  string
+	duration time.Duration
+	reply    string // first line of the agent's output
+	err      error
+}
+
+// validateProviders sends validatePrompt once through each distinct provider
+// selected in the plan, from the first project that uses it, and prints
+// pass/fail per provider. It returns an error naming the failed providers.
+func validateProviders(ctx context.Context, w io.Writer, plan *preflightPlan) error {
+	var checks []providerValidation
+	seen := map[string]bool{}
+	for _, pp := range plan.projects {
+		for _, choice := range pp.providers() {
+			if choice.agent == nil || seen[choice.name] {
+				continue
+			}
+			seen[choice.name] = true
+			checks = append(checks, validateProvider(ctx, choice.name, choice.agent, pp.path))
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(checks) == 0 {
+		fmt.Fprintln(w, "[validate] No provider selected; nothing to validate.")
+		return nil
+	}
+	fmt.Fprintln(w, "Provider validation:")
+	var failed []string
+	for _, c := range checks {
+		elapsed := c.duration.Round(100 * time.Millisecond)
+		if c.err != nil {
+			failed = append(failed, c.provider)
+			fmt.Fprintf(w, "  %-8s FAIL (%s): %v\n", c.provider, elapsed, c.err)
+			continue
+		}
+		fmt.Fprintf(w, "  %-8s PASS (%s)", c.provider, elapsed)
+		if c.reply != "" {
+			fmt.Fprintf(w, " reply: %q", c.reply)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("provider validation failed: %s", strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(w, "[validate] All providers responded. No tasks executed.")
+	return nil
+}
+
+func validateProvider(ctx context.Context, name string, agent agents.Agent, workDir string) providerValidation {
+	v := providerValidation{provider: name, workDir: workDir}
+	ctx, cancel := context.WithTimeout(ctx, validateTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := agent.Execute(ctx, agents.ExecuteOptions{
+		Prompt:  validatePrompt,
+		WorkDir: workDir,
+		Timeout: validateTimeout,
+	})
+	v.duration = time.Since(start)
+	switch {
+	case err != nil && res != nil && strings.TrimSpace(res.Error) != "":
+		v.err = fmt.Errorf("%s", firstLine(res.Error))
+	case err != nil:
+		v.err = err
+	case !res.IsSuccess():
+		v.err = fmt.Errorf("exit %d: %s", res.ExitCode, firstLine(res.Error))
+	default:
+		v.reply = firstLine(res.Output)
+	}
+	return v
+}
+
+// firstLine returns the first line of s, trimmed and capped at 80 runes.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	line = strings.TrimSpace(line)
+	if r := []rune(line); len(r) > 80 {
+		line = string(r[:77]) + "..."
+	}
+	return line
+}
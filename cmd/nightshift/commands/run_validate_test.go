@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/agents"
+)
+
+// validateAgent answers Execute with a canned result.
+type validateAgent struct {
+	name  string
+	res   *agents.ExecuteResult
+	err   error
+	calls int
+	dirs  []string
+}
+
+func (a *validateAgent) Name() string { return a.name }
+
+func (a *validateAgent) Execute(_ context.Context, opts agents.ExecuteOptions) (*agents.ExecuteResult, error) {
+	a.calls++
+	a.dirs = append(a.dirs, opts.WorkDir)
+	if opts.Prompt != validatePrompt {
+		return nil, errors.New("unexpected prompt")
+	}
+	return a.res, a.err
+}
+
+func TestValidateProviders(t *testing.T) {
+	claude := &validateAgent{name: "claude", res: &agents.ExecuteResult{Output: "OK\n"}}
+	codex := &validateAgent{name: "codex", res: &agents.ExecuteResult{ExitCode: 1, Error: "Not logged in. Run codex login\nmore"}, err: errors.New("exit status 1")}
+	plan := &preflightPlan{projects: []preflightProject{
+		{path: "/code/a", provider: &providerChoice{name: "claude", agent: claude}},
+		{path: "/code/b", provider: &providerChoice{name: "claude", agent: claude}},
+		{path: "/code/c", skipReason: "no provider"},
+		{path: "/code/d", provider: &providerChoice{name: "codex", agent: codex}},
+	}}
+
+	var out bytes.Buffer
+	err := validateProviders(context.Background(), &out, plan)
+	if err == nil || !strings.Contains(err.Error(), "codex") || strings.Contains(err.Error(), "claude") {
+		t.Fatalf("err = %v, want failure naming codex only", err)
+	}
+	if claude.calls != 1 || claude.dirs[0] != "/code/a" || codex.calls != 1 {
+		t.Errorf("calls: claude=%d %v, codex=%d; want one each from the first project", claude.calls, claude.dirs, codex.calls)
+	}
+	got := out.String()
+	for _, want := range []string{`claude   PASS`, `reply: "OK"`, "codex    FAIL", "Not logged in. Run codex login"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "more") {
+		t.Errorf("output should only show the first error line:\n%s", got)
+	}
+
+	out.Reset()
+	if err := validateProviders(context.Background(), &out, &preflightPlan{projects: plan.projects[:2]}); err != nil {
+		t.Fatalf("all-pass err = %v", err)
+	}
+	if !strings.Contains(out.String(), "All providers responded") {
+		t.Errorf("all-pass output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := validateProviders(context.Background(), &out, &preflightPlan{}); err != nil || !strings.Contains(out.String(), "nothing to validate") {
+		t.Errorf("empty plan: err = %v, output = %q", err, out.String())
+	}
+}
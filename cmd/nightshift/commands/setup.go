@@ -35,18 +35,25 @@ var setupCmd = &cobra.Command{
 	Long: `Interactive onboarding wizard that configures Nightshift end-to-end.
 
 Creates/updates the global config, validates providers, runs a snapshot, previews the next run,
-and optionally installs/enables the daemon.`,
+and optionally installs/enables the daemon.
+
+Setup adds .nightshift-plan to each project's .gitignore. Use --no-gitignore
+(or manage_gitignore: false in the config) to leave .gitignore files alone.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		model, err := newSetupModel()
 		if err != nil {
 			return err
 		}
+		if noGitignore, _ := cmd.Flags().GetBool("no-gitignore"); noGitignore {
+			model.skipGitignore = true
+		}
 		_, err = tea.NewProgram(model).Run()
 		return err
 	},
 }
 
 func init() {
+	setupCmd.Flags().Bool("no-gitignore", false, "Don't add .nightshift-plan to each project's .gitignore")
 	rootCmd.AddCommand(setupCmd)
 }
 
@@ -86,6 +93,7 @@ type setupModel struct {
 	projectInput   textinput.Model
 	projectEditing bool
 	projectErr     string
+	skipGitignore  bool // --no-gitignore or manage_gitignore: false
 	gitignoreAdded int
 	gitignoreKept  int
 	gitignoreErrs  []string
@@ -246,6 +254,7 @@ func newSetupModel() (*setupModel, error) {
 		scheduleInput:    scheduleInput,
 		spinner:          spin,
 		nightshiftInPath: nightshiftInPath,
+		skipGitignore:    !cfg.ManageGitignore,
 	}
 
 	return model, nil
@@ -967,7 +976,9 @@ func (m *setupModel) applyProjects() {
 		}
 		m.cfg.Projects = append(m.cfg.Projects, config.ProjectConfig{Path: project})
 	}
-	m.updateProjectGitignores()
+	if !m.skipGitignore {
+		m.updateProjectGitignores()
+	}
 }
 
 func (m *setupModel) applyBudgetDefaults() {
@@ -1019,8 +1030,8 @@ func (m *setupModel) applyBudgetEdit() error {
 	value := strings.TrimSpace(m.budgetInput.Value())
 	switch m.budgetCursor {
 	case 0:
-		if value != "daily" && value != "weekly" {
-			return fmt.Errorf("mode must be daily or weekly")
+		if value != "daily" && value != "weekly" && value != "auto" {
+			return fmt.Errorf("mode must be daily, weekly, or auto")
 		}
 		m.cfg.Budget.Mode = value
 	case 1:
@@ -1220,10 +1231,13 @@ func (m *setupModel) finishDaemonStatus() string {
 func (m *setupModel) finishExpectations() []string {
 	lines := []string{
 		fmt.Sprintf("Summary report: %s", reporting.DefaultSummaryPath(time.Now())),
-		fmt.Sprintf("Run report: %s", reporting.DefaultRunReportPath(time.Now())),
+		fmt.Sprintf("Run report: %s", filepath.Join(reportsDir(m.cfg), filepath.Base(reporting.DefaultRunReportPath(time.Now())))),
 		"CLI status: `nightshift status --today` or `nightshift logs`",
 		"Safety: Nightshift never writes to your primary branch. Expect PRs or branches.",
 	}
+	if m.skipGitignore {
+		lines = append(lines, fmt.Sprintf("Gitignore: skipped (--no-gitignore or manage_gitignore: false). Add `%s` to each project's ignore rules yourself to keep plan artifacts out of version control.", nightshiftPlanIgnore))
+	}
 	if m.gitignoreAdded > 0 || m.gitignoreKept > 0 {
 		var parts []string
 		if m.gitignoreAdded > 0 {
@@ -1669,46 +1683,19 @@ func runSnapshot(cfg *config.Config) (string, error) {
 	}
 	defer func() { _ = database.Close() }()
 
-	scraper := snapshots.UsageScraper(nil)
-	if cfg.Budget.CalibrateEnabled && strings.ToLower(cfg.Budget.BillingMode) != "api" {
-		scraper = tmuxScraper{}
-	}
-
-	collector := snapshots.NewCollector(
+	collector := newSnapshotCollector(
+		cfg,
 		database,
-		providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude")),
-		providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex")),
+		newClaudeProvider(cfg),
+		newCodexProvider(cfg),
 		providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot")),
-		scraper,
-		weekStartDayFromConfig(cfg),
 	)
 
-	var lines []string
-	ctx := context.Background()
-	if cfg.Providers.Claude.Enabled {
-		snapshot, err := collector.TakeSnapshot(ctx, "claude")
-		if err != nil {
-			lines = append(lines, fmt.Sprintf("claude: error: %v", err))
-		} else {
-			lines = append(lines, formatSnapshotLine(snapshot))
-		}
-	}
-	if cfg.Providers.Codex.Enabled {
-		snapshot, err := collector.TakeSnapshot(ctx, "codex")
-		if err != nil {
-			lines = append(lines, fmt.Sprintf("codex: error: %v", err))
-		} else {
-			lines = append(lines, formatSnapshotLine(snapshot))
-		}
-	}
-	if cfg.Providers.Copilot.Enabled {
-		snapshot, err := collector.TakeSnapshot(ctx, "copilot")
-		if err != nil {
-			lines = append(lines, fmt.Sprintf("copilot: error: %v", err))
-		} else {
-			lines = append(lines, formatSnapshotLine(snapshot))
-		}
+	enabled, err := resolveProviderList(cfg, "")
+	if err != nil {
+		return "", err
 	}
+	lines := takeProviderSnapshots(context.Background(), collector, enabled)
 	return strings.Join(lines, "\n"), nil
 }
 
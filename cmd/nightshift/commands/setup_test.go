@@ -3,6 +3,7 @@ package commands
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -174,3 +175,27 @@ func TestMakeTaskItems_PreservesExplicitEnabledTasks(t *testing.T) {
 		t.Fatal("expected bug-finder task to exist in setup list")
 	}
 }
+
+func TestApplyProjects_Gitignore(t *testing.T) {
+	project := t.TempDir()
+	gitignore := filepath.Join(project, ".gitignore")
+
+	m := &setupModel{cfg: &config.Config{}, projects: []string{project}, skipGitignore: true}
+	m.applyProjects()
+	if _, err := os.Stat(gitignore); !os.IsNotExist(err) {
+		t.Fatalf("skipped gitignore management but .gitignore exists (err=%v)", err)
+	}
+	if lines := strings.Join(m.finishExpectations(), "\n"); !strings.Contains(lines, "Gitignore: skipped") || !strings.Contains(lines, nightshiftPlanIgnore) {
+		t.Errorf("finish summary missing skip reminder:\n%s", lines)
+	}
+
+	m.skipGitignore = false
+	m.applyProjects()
+	data, err := os.ReadFile(gitignore)
+	if err != nil || !strings.Contains(string(data), nightshiftPlanIgnore) {
+		t.Fatalf(".gitignore = %q (err=%v), want %s entry", data, err, nightshiftPlanIgnore)
+	}
+	if lines := strings.Join(m.finishExpectations(), "\n"); strings.Contains(lines, "Gitignore: skipped") || !strings.Contains(lines, "added to 1 project(s)") {
+		t.Errorf("finish summary:\n%s", lines)
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/marcus/nightshift/internal/logging"
+)
+
+// gracefulShutdown implements two-phase signal handling. The first SIGINT or
+// SIGTERM starts draining: no new projects or tasks start, and the in-flight
+// task may finish within the grace period. A second signal, or the grace
+// period expiring, cancels the context.
+type gracefulShutdown struct {
+	draining  atomic.Bool
+	drainCh   chan struct{}
+	drainOnce sync.Once
+	cancel    context.CancelFunc
+	grace     time.Duration
+	log       *logging.Logger
+}
+
+// newGracefulShutdown creates a shutdown controller that cancels via cancel.
+func newGracefulShutdown(cancel context.CancelFunc, grace time.Duration, log *logging.Logger) *gracefulShutdown {
+	return &gracefulShutdown{
+		drainCh: make(chan struct{}),
+		cancel:  cancel,
+		grace:   grace,
+		log:     log,
+	}
+}
+
+// listen installs the signal handler. It stops when ctx is done.
+func (g *gracefulShutdown) listen(ctx context.Context) {
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigCh:
+				if g.Draining() {
+					g.log.Warnf("received %v while draining, cancelling", sig)
+					g.cancel()
+					return
+				}
+				g.startDrain(sig)
+			}
+		}
+	}()
+}
+
+// startDrain enters drain mode and arms the grace timer.
+func (g *gracefulShutdown) startDrain(sig os.Signal) {
+	g.drainOnce.Do(func() {
+		g.draining.Store(true)
+		close(g.drainCh)
+		g.log.Infof("received %v, draining: finishing current task (grace %s, signal again to force)", sig, g.grace)
+		if g.grace <= 0 {
+			g.cancel()
+			return
+		}
+		time.AfterFunc(g.grace, func() {
+			g.log.Warnf("drain grace %s expired, cancelling", g.grace)
+			g.cancel()
+		})
+	})
+}
+
+// Draining reports whether a shutdown signal has been received.
+// Safe to call on a nil receiver.
+func (g *gracefulShutdown) Draining() bool {
+	return g != nil && g.draining.Load()
+}
+
+// Done returns a channel closed when draining starts. A nil receiver
+// returns a nil channel, which never fires.
+func (g *gracefulShutdown) Done() <-chan struct{} {
+	if g == nil {
+		return nil
+	}
+	return g.drainCh
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/logging"
+)
+
+func TestGracefulShutdown_DrainThenGraceExpiry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g := newGracefulShutdown(cancel, 50*time.Millisecond, logging.Component("test"))
+	if g.Draining() {
+		t.Fatal("should not be draining before a signal")
+	}
+
+	g.startDrain(syscall.SIGTERM)
+	if !g.Draining() {
+		t.Fatal("expected draining after first signal")
+	}
+	select {
+	case <-g.Done():
+	default:
+		t.Fatal("Done channel should be closed once draining")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context cancelled before grace period expired")
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("context not cancelled after grace period")
+	}
+}
+
+func TestGracefulShutdown_ZeroGraceCancelsImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g := newGracefulShutdown(cancel, 0, logging.Component("test"))
+	g.startDrain(syscall.SIGTERM)
+	if ctx.Err() == nil {
+		t.Fatal("expected immediate cancel with zero grace")
+	}
+}
+
+func TestGracefulShutdown_NilDraining(t *testing.T) {
+	var g *gracefulShutdown
+	if g.Draining() {
+		t.Error("nil shutdown should never report draining")
+	}
+}
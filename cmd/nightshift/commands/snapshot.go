@@ -111,12 +111,13 @@ func runBudgetSnapshot(cmd *cobra.Command, filterProvider string, localOnly bool
 
 	collector := snapshots.NewCollector(
 		database,
-		providers.NewClaudeWithPath(cfg.ExpandedProviderPath("claude")),
-		providers.NewCodexWithPath(cfg.ExpandedProviderPath("codex")),
+		newClaudeProvider(cfg),
+		newCodexProvider(cfg),
 		providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot")),
 		scraper,
 		weekStartDayFromConfig(cfg),
 	)
+	collector.SetUsageTimeout(cfg.GetUsageTimeout())
 
 	fmt.Println("Budget Snapshot")
 	fmt.Println("===============")
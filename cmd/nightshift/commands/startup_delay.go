@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/logging"
+)
+
+// startupDelayWait returns how long until tasks may start when now falls in
+// the schedule window's startup delay, or 0 if there is no window, no delay,
+// now is outside the window, or the delay has passed.
+func startupDelayWait(cfg *config.Config, now time.Time) time.Duration {
+	w := scheduleWindow(cfg)
+	if w == nil || w.StartupDelay <= 0 || !w.Contains(now) {
+		return 0
+	}
+	return w.EligibleAt(now).Sub(now)
+}
+
+// waitForStartupDelay sleeps out the rest of schedule.window.startup_delay.
+// It returns early if draining starts and returns the context error if ctx
+// is cancelled while waiting.
+func waitForStartupDelay(ctx context.Context, cfg *config.Config, shutdown *gracefulShutdown, log *logging.Logger) error {
+	now := time.Now()
+	wait := startupDelayWait(cfg, now)
+	if wait <= 0 {
+		return nil
+	}
+
+	log.Infof("schedule window startup delay: waiting %s until %s before starting tasks",
+		wait.Round(time.Second), now.Add(wait).Format("15:04"))
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-shutdown.Done():
+		log.Info("draining, abandoning startup delay wait")
+	case <-timer.C:
+	}
+	return nil
+}
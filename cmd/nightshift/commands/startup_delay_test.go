@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestStartupDelayWait(t *testing.T) {
+	withDelay := &config.Config{Schedule: config.ScheduleConfig{
+		Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC", StartupDelay: "20m"},
+	}}
+	noDelay := &config.Config{Schedule: config.ScheduleConfig{
+		Window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC"},
+	}}
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		now  time.Time
+		want time.Duration
+	}{
+		{"just opened", withDelay, time.Date(2026, 3, 4, 22, 5, 0, 0, time.UTC), 15 * time.Minute},
+		{"delay passed", withDelay, time.Date(2026, 3, 4, 22, 30, 0, 0, time.UTC), 0},
+		{"after midnight", withDelay, time.Date(2026, 3, 5, 1, 0, 0, 0, time.UTC), 0},
+		{"outside window", withDelay, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC), 0},
+		{"no delay", noDelay, time.Date(2026, 3, 4, 22, 5, 0, 0, time.UTC), 0},
+		{"no window", &config.Config{}, time.Date(2026, 3, 4, 22, 5, 0, 0, time.UTC), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startupDelayWait(tt.cfg, tt.now); got != tt.want {
+				t.Errorf("startupDelayWait() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
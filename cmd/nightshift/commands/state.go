@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/state"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and adjust persisted run state",
+}
+
+var stateResetCooldownCmd = &cobra.Command{
+	Use:   "reset-cooldown",
+	Short: "Clear a task's cooldown for a project",
+	Long: `Clear the last-run record for a task on a project so normal selection
+treats it as eligible again. Unlike run --task, every other filter (enabled,
+budget, categories) still applies.
+
+Use --all-tasks to clear every task's cooldown for the project. The project
+defaults to the current directory.
+
+Examples:
+  nightshift state reset-cooldown --project ~/code/app --task lint-fix
+  nightshift state reset-cooldown --all-tasks`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectPath, _ := cmd.Flags().GetString("project")
+		taskType, _ := cmd.Flags().GetString("task")
+		allTasks, _ := cmd.Flags().GetBool("all-tasks")
+
+		if (taskType == "") == !allTasks {
+			return fmt.Errorf("specify exactly one of --task or --all-tasks")
+		}
+		if projectPath == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			projectPath = wd
+		}
+
+		cfg, err := loadConfig(projectPath)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		database, err := db.Open(cfg.ExpandedDBPath())
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+
+		st, err := state.New(database)
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+
+		var taskTypes []string
+		if taskType != "" {
+			taskTypes = []string{taskType}
+		}
+		return runResetCooldown(os.Stdout, st, projectPath, taskTypes)
+	},
+}
+
+var stateHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recorded runs",
+	Long: `List the per-project run records kept in the state database (most
+recent first; the last 100 are kept).
+
+Use --label to show only runs tagged with run --label NAME.
+
+Examples:
+  nightshift state history
+  nightshift state history --label experiment -n 20
+  nightshift state history --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, _ := cmd.Flags().GetInt("runs")
+		label, _ := cmd.Flags().GetString("label")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if n < 0 {
+			return fmt.Errorf("--runs must be >= 0")
+		}
+
+		cfg, err := loadConfig("")
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		database, err := db.Open(cfg.ExpandedDBPath())
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+		defer func() { _ = database.Close() }()
+
+		st, err := state.New(database)
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+		return runStateHistory(os.Stdout, st, label, n, asJSON)
+	},
+}
+
+func init() {
+	stateHistoryCmd.Flags().IntP("runs", "n", 10, "Max records to show (0 = all)")
+	stateHistoryCmd.Flags().String("label", "", "Only show runs tagged with run --label NAME")
+	stateHistoryCmd.Flags().Bool("json", false, "Output as JSON")
+	stateCmd.AddCommand(stateHistoryCmd)
+
+	stateResetCooldownCmd.Flags().StringP("project", "p", "", "Project directory (default: current directory)")
+	stateResetCooldownCmd.Flags().StringP("task", "t", "", "Task type to reset")
+	stateResetCooldownCmd.Flags().Bool("all-tasks", false, "Reset every task's cooldown for the project")
+
+	stateCmd.AddCommand(stateResetCooldownCmd)
+	rootCmd.AddCommand(stateCmd)
+}
+
+// runResetCooldown clears cooldowns for taskTypes (all tasks when empty) on
+// projectPath and reports what was cleared.
+func runResetCooldown(w io.Writer, st *state.State, projectPath string, taskTypes []string) error {
+	reset, err := st.ResetCooldowns(projectPath, taskTypes...)
+	if err != nil {
+		return fmt.Errorf("reset cooldown: %w", err)
+	}
+	name := filepath.Base(projectPath)
+	if len(reset) == 0 {
+		if len(taskTypes) > 0 {
+			_, _ = fmt.Fprintf(w, "No cooldown recorded for %s on %s.\n", taskTypes[0], name)
+		} else {
+			_, _ = fmt.Fprintf(w, "No cooldowns recorded on %s.\n", name)
+		}
+		return nil
+	}
+	for _, r := range reset {
+		_, _ = fmt.Fprintf(w, "Reset cooldown: %s on %s (last run %s)\n", r.TaskType, name, r.LastRun.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// runStateHistory prints up to n run records (all when n is 0), keeping only
+// those tagged with label when it is set.
+func runStateHistory(w io.Writer, st *state.State, label string, n int, asJSON bool) error {
+	records := filterRunRecords(st.GetRunHistory(0), label, n)
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	if len(records) == 0 {
+		if label != "" {
+			_, _ = fmt.Fprintf(w, "No run history found with label %q.\n", label)
+		} else {
+			_, _ = fmt.Fprintln(w, "No run history found.")
+		}
+		return nil
+	}
+	for i, run := range records {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		printRunRecord(w, run)
+	}
+	return nil
+}
+
+// filterRunRecords keeps records tagged with label (all when empty), up to
+// n of them (all when n is 0).
+func filterRunRecords(records []state.RunRecord, label string, n int) []state.RunRecord {
+	out := make([]state.RunRecord, 0, len(records))
+	for _, r := range records {
+		if label != "" && r.Label != label {
+			continue
+		}
+		out = append(out, r)
+		if n > 0 && len(out) == n {
+			break
+		}
+	}
+	return out
+}
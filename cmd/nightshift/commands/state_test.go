@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/state"
+)
+
+func TestRunResetCooldown(t *testing.T) {
+	st := newTestRunState(t)
+	project := t.TempDir()
+	st.RecordTaskRun(project, "lint-fix")
+
+	var buf bytes.Buffer
+	if err := runResetCooldown(&buf, st, project, []string{"lint-fix"}); err != nil {
+		t.Fatalf("runResetCooldown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Reset cooldown: lint-fix on ") {
+		t.Errorf("output = %q, want a reset line for lint-fix", buf.String())
+	}
+
+	buf.Reset()
+	if err := runResetCooldown(&buf, st, project, []string{"lint-fix"}); err != nil {
+		t.Fatalf("runResetCooldown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No cooldown recorded for lint-fix") {
+		t.Errorf("output = %q, want no-cooldown message", buf.String())
+	}
+}
+
+func TestRunStateHistory_Label(t *testing.T) {
+	st := newTestRunState(t)
+	start := time.Now().Add(-time.Hour)
+	for i, label := range []string{"maintenance", "experiment", "", "experiment"} {
+		st.AddRunRecord(state.RunRecord{
+			StartTime: start.Add(time.Duration(i) * time.Minute),
+			EndTime:   start.Add(time.Duration(i)*time.Minute + 30*time.Second),
+			Project:   "/code/app",
+			Tasks:     []string{"lint-fix"},
+			Status:    "success",
+			Label:     label,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := runStateHistory(&buf, st, "experiment", 0, true); err != nil {
+		t.Fatalf("runStateHistory: %v", err)
+	}
+	var records []state.RunRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(records) != 2 || records[0].Label != "experiment" || records[1].Label != "experiment" {
+		t.Errorf("records = %+v, want the two experiment runs", records)
+	}
+
+	buf.Reset()
+	if err := runStateHistory(&buf, st, "", 1, false); err != nil {
+		t.Fatalf("runStateHistory: %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "Label:   experiment") || strings.Count(got, "Project:") != 1 {
+		t.Errorf("output = %q, want only the latest run", got)
+	}
+
+	buf.Reset()
+	if err := runStateHistory(&buf, st, "nightly", 0, false); err != nil {
+		t.Fatalf("runStateHistory: %v", err)
+	}
+	if !strings.Contains(buf.String(), `No run history found with label "nightly"`) {
+		t.Errorf("output = %q", buf.String())
+	}
+}
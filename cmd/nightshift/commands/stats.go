@@ -49,9 +49,9 @@ func runStats(jsonOutput bool, period string) error {
 	}
 	defer func() { _ = database.Close() }()
 
-	reportsDir := reporting.DefaultReportsDir()
+	dir := reportsDir(cfg)
 	cal := calibrator.New(database, cfg)
-	s := stats.NewWithBudgetSource(database, reportsDir, cal)
+	s := stats.NewWithBudgetSource(database, dir, cal)
 	result, err := s.Compute()
 	if err != nil {
 		return fmt.Errorf("computing stats: %w", err)
@@ -59,7 +59,7 @@ func runStats(jsonOutput bool, period string) error {
 
 	// Apply period filter if not "all"
 	if period != "all" {
-		result = filterStatsByPeriod(result, s, reportsDir, period)
+		result = filterStatsByPeriod(result, s, dir, period)
 	}
 
 	if jsonOutput {
@@ -160,6 +160,8 @@ func computeStatsFromRuns(runs []reportRun) *stats.StatsResult {
 			switch task.Status {
 			case "completed":
 				result.TasksCompleted++
+			case "partial":
+				result.TasksPartial++
 			case "failed":
 				result.TasksFailed++
 			case "skipped":
@@ -170,7 +172,7 @@ func computeStatsFromRuns(runs []reportRun) *stats.StatsResult {
 				result.TaskTypeBreakdown[task.TaskType]++
 			}
 
-			if strings.EqualFold(task.OutputType, "pr") && task.OutputRef != "" {
+			if reporting.IsReviewRequest(task.OutputType) && task.OutputRef != "" {
 				result.PRsCreated++
 				if strings.HasPrefix(task.OutputRef, "http") {
 					prURLSet[task.OutputRef] = struct{}{}
@@ -237,10 +239,8 @@ func computeStatsFromRuns(runs []reportRun) *stats.StatsResult {
 	}
 
 	// Success rate
-	totalTasks := result.TasksCompleted + result.TasksFailed + result.TasksSkipped
-	if totalTasks > 0 {
-		result.SuccessRate = float64(result.TasksCompleted) / float64(totalTasks) * 100
-	}
+	totalTasks := result.TasksCompleted + result.TasksPartial + result.TasksFailed + result.TasksSkipped
+	result.SuccessRate = reporting.SuccessRate(result.TasksCompleted, result.TasksPartial, totalTasks)
 
 	return result
 }
@@ -274,13 +274,16 @@ func renderStatsHuman(result *stats.StatsResult) error {
 	fmt.Println()
 
 	// Tasks section
-	totalTasks := result.TasksCompleted + result.TasksFailed + result.TasksSkipped
+	totalTasks := result.TasksCompleted + result.TasksPartial + result.TasksFailed + result.TasksSkipped
 	fmt.Println("Tasks")
 	fmt.Printf("  Completed:    %d", result.TasksCompleted)
 	if totalTasks > 0 {
 		fmt.Printf(" (%.0f%% success rate)", result.SuccessRate)
 	}
 	fmt.Println()
+	if result.TasksPartial > 0 {
+		fmt.Printf("  Partial:      %d (counted as half a success)\n", result.TasksPartial)
+	}
 	fmt.Printf("  Failed:       %d\n", result.TasksFailed)
 	fmt.Printf("  Skipped:      %d\n", result.TasksSkipped)
 	fmt.Printf("  PRs created:  %d\n", result.PRsCreated)
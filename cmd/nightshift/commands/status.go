@@ -1,15 +1,21 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/marcus/nightshift/internal/budget"
 	"github.com/marcus/nightshift/internal/config"
 	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/scheduler"
 	"github.com/marcus/nightshift/internal/state"
 )
 
@@ -18,16 +24,43 @@ var statusCmd = &cobra.Command{
 	Short: "Show run history",
 	Long: `Display nightshift run history and activity.
 
-Shows the last N runs (default: 5) or today's activity summary.`,
+Shows the schedule window (active or next opening) followed by the last
+N runs (default: 5), or with --today a summary and a chronological ledger
+of today's runs: project, provider, outcome, tokens and each task's result.
+Use --daemon to show daemon liveness from its heartbeat file.
+Use --json for a versioned snapshot of schedule, daemon, provider budget,
+and run history data for scripts and dashboards; with --today it prints
+today's summary and ledger instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		last, _ := cmd.Flags().GetInt("last")
 		today, _ := cmd.Flags().GetBool("today")
+		daemon, _ := cmd.Flags().GetBool("daemon")
+		asJSON, _ := cmd.Flags().GetBool("json")
 
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("loading config: %w", err)
 		}
 
+		if asJSON && today {
+			return writeTodayJSON(cmd.OutOrStdout(), cfg, time.Now())
+		}
+		if asJSON {
+			return writeStatusJSON(cmd.OutOrStdout(), cfg, last, time.Now())
+		}
+
+		if daemon {
+			return showDaemonStatus(cfg, time.Now())
+		}
+
+		for _, line := range scheduleStatusLines(cfg, time.Now()) {
+			fmt.Println(line)
+		}
+		for _, line := range staleDataLines(cfg) {
+			fmt.Println(line)
+		}
+		fmt.Println()
+
 		database, err := db.Open(cfg.ExpandedDBPath())
 		if err != nil {
 			return fmt.Errorf("opening db: %w", err)
@@ -40,7 +73,8 @@ Shows the last N runs (default: 5) or today's activity summary.`,
 		}
 
 		if today {
-			return showTodaySummary(st)
+			showTodayLedger(cmd.OutOrStdout(), st)
+			return nil
 		}
 		return showLastRuns(st, last)
 	},
@@ -48,10 +82,70 @@ Shows the last N runs (default: 5) or today's activity summary.`,
 
 func init() {
 	statusCmd.Flags().IntP("last", "n", 5, "Show last N runs")
-	statusCmd.Flags().Bool("today", false, "Show today's activity summary")
+	statusCmd.Flags().Bool("today", false, "Show today's activity summary and run ledger")
+	statusCmd.Flags().Bool("daemon", false, "Show daemon heartbeat (last tick, last run, next run)")
+	statusCmd.Flags().Bool("json", false, "Output a JSON snapshot for scripting")
+	addFreshFlag(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
 
+// scheduleStatusLines describes the configured run window relative to now:
+// whether it is open and when it closes, or when it next opens. Cron
+// schedules also show the next fire time.
+func scheduleStatusLines(cfg *config.Config, now time.Time) []string {
+	sched, err := scheduler.NewFromConfig(&cfg.Schedule)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrNoSchedule) {
+			return []string{"Schedule:  not configured"}
+		}
+		return []string{fmt.Sprintf("Schedule:  invalid: %v", err)}
+	}
+
+	var lines []string
+	if w := sched.Window(); w == nil {
+		lines = append(lines, "Window:    none (runs any time)")
+	} else if w.Contains(now) {
+		closes := w.NextEnd(now)
+		if starts := w.EligibleAt(now); starts.After(now) {
+			lines = append(lines, fmt.Sprintf("Window:    active (%s), tasks start in %s, closes in %s", w, formatDuration(starts.Sub(now)), formatDuration(closes.Sub(now))))
+		} else {
+			lines = append(lines, fmt.Sprintf("Window:    active (%s), closes in %s", w, formatDuration(closes.Sub(now))))
+		}
+	} else {
+		opens := w.NextStart(now)
+		line := fmt.Sprintf("Window:    closed (%s), opens in %s", w, formatDuration(opens.Sub(now)))
+		if w.StartupDelay > 0 {
+			line += fmt.Sprintf(", tasks start %s later", formatDuration(w.StartupDelay))
+		}
+		lines = append(lines, line)
+	}
+
+	if cfg.Schedule.Cron != "" {
+		if runs, err := sched.NextRuns(1); err == nil && len(runs) > 0 {
+			lines = append(lines, fmt.Sprintf("Next cron: %s (in %s)", runs[0].Format("2006-01-02 15:04 MST"), formatDuration(runs[0].Sub(now))))
+		}
+	} else {
+		lines = append(lines, fmt.Sprintf("Interval:  every %s", cfg.Schedule.Interval))
+	}
+	return lines
+}
+
+// staleDataLines warns about enabled providers whose usage data is older
+// than the budget window.
+func staleDataLines(cfg *config.Config) []string {
+	mgr := budget.NewManagerFromProviders(cfg, newClaudeProvider(cfg), newCodexProvider(cfg), providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot")))
+	var lines []string
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		if !providerEnabled(cfg, name) {
+			continue
+		}
+		if age, err := mgr.DataAge(name); err == nil && age.Stale {
+			lines = append(lines, "Warning:   "+staleDataWarning(name, age))
+		}
+	}
+	return lines
+}
+
 func showLastRuns(st *state.State, n int) error {
 	runs := st.GetRunHistory(n)
 
@@ -63,72 +157,43 @@ func showLastRuns(st *state.State, n int) error {
 	fmt.Printf("Last %d runs:\n\n", len(runs))
 
 	for _, run := range runs {
-		printRunRecord(run)
+		printRunRecord(os.Stdout, run)
 		fmt.Println()
 	}
 
 	return nil
 }
 
-func showTodaySummary(st *state.State) error {
-	summary := st.GetTodaySummary()
-
-	if summary.TotalRuns == 0 {
-		fmt.Println("No runs today.")
-		return nil
-	}
-
-	fmt.Printf("Today's Activity Summary\n")
-	fmt.Printf("========================\n\n")
-
-	fmt.Printf("Runs:    %d total (%d success, %d failed)\n",
-		summary.TotalRuns, summary.SuccessfulRuns, summary.FailedRuns)
-	fmt.Printf("Tokens:  %s\n", formatTokens(summary.TotalTokens))
-
-	if len(summary.Projects) > 0 {
-		fmt.Printf("\nProjects (%d):\n", len(summary.Projects))
-		for _, p := range summary.Projects {
-			fmt.Printf("  - %s\n", filepath.Base(p))
-		}
-	}
-
-	if len(summary.TaskCounts) > 0 {
-		fmt.Printf("\nTasks executed:\n")
-		for task, count := range summary.TaskCounts {
-			fmt.Printf("  - %s: %d\n", task, count)
-		}
-	}
-
-	return nil
-}
-
-func printRunRecord(run state.RunRecord) {
+func printRunRecord(w io.Writer, run state.RunRecord) {
 	status := formatStatus(run.Status)
 	duration := run.EndTime.Sub(run.StartTime)
 
-	fmt.Printf("[%s] %s\n", run.StartTime.Format("2006-01-02 15:04"), status)
+	_, _ = fmt.Fprintf(w, "[%s] %s\n", run.StartTime.Format("2006-01-02 15:04"), status)
 
 	if run.Project != "" {
-		fmt.Printf("  Project: %s\n", filepath.Base(run.Project))
+		_, _ = fmt.Fprintf(w, "  Project: %s\n", filepath.Base(run.Project))
+	}
+	if run.Label != "" {
+		_, _ = fmt.Fprintf(w, "  Label:   %s\n", run.Label)
 	}
 	if run.Provider != "" {
-		fmt.Printf("  Provider: %s\n", run.Provider)
+		_, _ = fmt.Fprintf(w, "  Provider: %s\n", run.Provider)
 	}
 
 	if len(run.Tasks) > 0 {
-		fmt.Printf("  Tasks:   %s\n", strings.Join(run.Tasks, ", "))
+		_, _ = fmt.Fprintf(w, "  Tasks:   %s\n", strings.Join(run.Tasks, ", "))
 	}
 
 	if run.TokensUsed > 0 {
-		fmt.Printf("  Tokens:  %s\n", formatTokens(run.TokensUsed))
+		_, _ = fmt.Fprintf(w, "  Tokens:  %s\n", formatTokens(run.TokensUsed))
 	}
 
 	if duration > 0 {
-		fmt.Printf("  Duration: %s\n", formatDuration(duration))
+		_, _ = fmt.Fprintf(w, "  Duration: %s\n", formatDuration(duration))
 	}
 
 	if run.Error != "" {
-		fmt.Printf("  Error:   %s\n", run.Error)
+		_, _ = fmt.Fprintf(w, "  Error:   %s\n", run.Error)
 	}
 }
 
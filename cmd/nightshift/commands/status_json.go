@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/calibrator"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/scheduler"
+	"github.com/marcus/nightshift/internal/state"
+	"github.com/marcus/nightshift/internal/trends"
+)
+
+// statusJSONSchemaVersion is bumped whenever a statusJSON field is removed,
+// renamed, or changes meaning. Adding fields does not bump it.
+const statusJSONSchemaVersion = 1
+
+// statusJSON is the status --json snapshot for dashboards and menubar apps.
+type statusJSON struct {
+	SchemaVersion int                  `json:"schema_version"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	Schedule      statusScheduleJSON   `json:"schedule"`
+	Daemon        statusDaemonJSON     `json:"daemon"`
+	Providers     []statusProviderJSON `json:"providers"`
+	Today         statusTodayJSON      `json:"today"`
+	RecentRuns    []state.RunRecord    `json:"recent_runs"`
+}
+
+type statusScheduleJSON struct {
+	Configured bool              `json:"configured"`
+	Error      string            `json:"error,omitempty"`
+	Cron       string            `json:"cron,omitempty"`
+	Interval   string            `json:"interval,omitempty"`
+	NextRun    *time.Time        `json:"next_run,omitempty"`
+	Window     *statusWindowJSON `json:"window,omitempty"`
+}
+
+type statusWindowJSON struct {
+	Description  string    `json:"description"`
+	Active       bool      `json:"active"`
+	OpensAt      time.Time `json:"opens_at,omitzero"`
+	ClosesAt     time.Time `json:"closes_at,omitzero"`
+	StartupDelay string    `json:"startup_delay,omitempty"`
+	TasksStartAt time.Time `json:"tasks_start_at,omitzero"` // opening plus startup_delay, while still ahead
+}
+
+type statusDaemonJSON struct {
+	Running       bool      `json:"running"`
+	PID           int       `json:"pid,omitempty"`
+	LastTick      time.Time `json:"last_tick,omitzero"`
+	Stale         bool      `json:"stale"`
+	LastRun       time.Time `json:"last_run,omitzero"`
+	LastRunStatus string    `json:"last_run_status,omitempty"`
+	LastRunError  string    `json:"last_run_error,omitempty"`
+	NextRun       time.Time `json:"next_run,omitzero"`
+}
+
+type statusProviderJSON struct {
+	Name              string    `json:"name"`
+	Mode              string    `json:"mode,omitempty"`
+	UsedPercent       float64   `json:"used_percent"`
+	UsedPercentSource string    `json:"used_percent_source,omitempty"`
+	WeeklyBudget      int64     `json:"weekly_budget"`
+	Allowance         int64     `json:"allowance"`
+	HeldTokens        int64     `json:"held_tokens,omitempty"` // budget reserve
+	ResetAt           time.Time `json:"reset_at,omitzero"`
+	DataUpdatedAt     time.Time `json:"data_updated_at,omitzero"` // when the usage data last changed
+	DataStale         bool      `json:"data_stale,omitempty"`     // data older than the budget window
+	Error             string    `json:"error,omitempty"`
+}
+
+type statusTodayJSON struct {
+	Runs       int            `json:"runs"`
+	Successful int            `json:"successful"`
+	Failed     int            `json:"failed"`
+	Tokens     int            `json:"tokens"`
+	Tasks      map[string]int `json:"tasks"`
+	Projects   []string       `json:"projects"`
+}
+
+// writeStatusJSON gathers the schedule, daemon, provider budget, and run
+// history data shown by status into one JSON document.
+func writeStatusJSON(w io.Writer, cfg *config.Config, last int, now time.Time) error {
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	out, err := collectStatus(cfg, database, last, now, budget.WithUsageCache(newUsageCache(cfg)))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// collectStatus builds the status --json document from database. opts are
+// added to the budget manager's options.
+func collectStatus(cfg *config.Config, database *db.DB, last int, now time.Time, opts ...budget.Option) (*statusJSON, error) {
+	st, err := state.New(database)
+	if err != nil {
+		return nil, fmt.Errorf("loading state: %w", err)
+	}
+
+	claudeProvider := newClaudeProvider(cfg)
+	codexProvider := newCodexProvider(cfg)
+	copilotProvider := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
+	cal := calibrator.New(database, cfg)
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+	opts = append([]budget.Option{budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budget.WithReservations(st)}, opts...)
+	budgetMgr := budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, opts...)
+	defer func() { _ = budgetMgr.SaveCache() }()
+
+	out := &statusJSON{
+		SchemaVersion: statusJSONSchemaVersion,
+		GeneratedAt:   now,
+		Schedule:      statusSchedule(cfg, now),
+		Daemon:        statusDaemon(cfg, now),
+		Providers:     statusProviders(cfg, budgetMgr),
+		Today:         statusToday(st.GetTodaySummary()),
+		RecentRuns:    st.GetRunHistory(last),
+	}
+	if out.RecentRuns == nil {
+		out.RecentRuns = []state.RunRecord{}
+	}
+	return out, nil
+}
+
+// statusSchedule mirrors scheduleStatusLines as structured data.
+func statusSchedule(cfg *config.Config, now time.Time) statusScheduleJSON {
+	sched, err := scheduler.NewFromConfig(&cfg.Schedule)
+	if err != nil {
+		if errors.Is(err, scheduler.ErrNoSchedule) {
+			return statusScheduleJSON{}
+		}
+		return statusScheduleJSON{Error: err.Error()}
+	}
+
+	out := statusScheduleJSON{
+		Configured: true,
+		Cron:       cfg.Schedule.Cron,
+		Interval:   cfg.Schedule.Interval,
+	}
+	if w := sched.Window(); w != nil {
+		out.Window = &statusWindowJSON{Description: w.String(), Active: w.Contains(now)}
+		if out.Window.Active {
+			out.Window.ClosesAt = w.NextEnd(now)
+		} else {
+			out.Window.OpensAt = w.NextStart(now)
+		}
+		if w.StartupDelay > 0 {
+			out.Window.StartupDelay = w.StartupDelay.String()
+			if starts := w.EligibleAt(now); starts.After(now) {
+				out.Window.TasksStartAt = starts
+			}
+		}
+	}
+	if cfg.Schedule.Cron != "" {
+		if runs, err := sched.NextRuns(1); err == nil && len(runs) > 0 {
+			out.NextRun = &runs[0]
+		}
+	}
+	return out
+}
+
+// statusDaemon mirrors showDaemonStatus as structured data.
+func statusDaemon(cfg *config.Config, now time.Time) statusDaemonJSON {
+	var out statusDaemonJSON
+	out.Running, out.PID = isDaemonRunning()
+	hb, err := readHeartbeat(heartbeatFilePath())
+	if err != nil {
+		return out
+	}
+	out.LastTick = hb.LastTick
+	out.Stale = now.Sub(hb.LastTick) > heartbeatStaleAfter(cfg)
+	out.LastRun = hb.LastRun
+	out.LastRunStatus = hb.LastRunStatus
+	out.LastRunError = hb.LastRunError
+	out.NextRun = hb.NextRun
+	return out
+}
+
+func statusProviders(cfg *config.Config, budgetMgr *budget.Manager) []statusProviderJSON {
+	out := []statusProviderJSON{}
+	for _, summary := range collectProviderBudgets(cfg, budgetMgr) {
+		p := statusProviderJSON{Name: summary.name}
+		if summary.err != nil {
+			p.Error = summary.err.Error()
+			out = append(out, p)
+			continue
+		}
+		p.Mode = summary.allowance.Mode
+		p.UsedPercent = summary.allowance.UsedPercent
+		p.UsedPercentSource = summary.allowance.UsedPercentSource
+		p.WeeklyBudget = summary.allowance.WeeklyBudget
+		p.Allowance = summary.allowance.Allowance
+		p.HeldTokens = summary.allowance.HeldTokens
+		if reset, err := budgetMgr.GetResetTime(summary.name); err == nil {
+			p.ResetAt = reset
+		}
+		if age, err := budgetMgr.DataAge(summary.name); err == nil {
+			p.DataUpdatedAt = age.ModTime
+			p.DataStale = age.Stale
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func statusToday(summary state.TodaySummary) statusTodayJSON {
+	out := statusTodayJSON{
+		Runs:       summary.TotalRuns,
+		Successful: summary.SuccessfulRuns,
+		Failed:     summary.FailedRuns,
+		Tokens:     summary.TotalTokens,
+		Tasks:      summary.TaskCounts,
+		Projects:   summary.Projects,
+	}
+	if out.Tasks == nil {
+		out.Tasks = map[string]int{}
+	}
+	if out.Projects == nil {
+		out.Projects = []string{}
+	}
+	return out
+}
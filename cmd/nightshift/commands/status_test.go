@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/state"
+)
+
+func TestScheduleStatusLines(t *testing.T) {
+	window := &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC"}
+
+	tests := []struct {
+		name     string
+		schedule config.ScheduleConfig
+		now      time.Time
+		want     []string
+	}{
+		{
+			name:     "no schedule",
+			schedule: config.ScheduleConfig{},
+			now:      time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC),
+			want:     []string{"Schedule:  not configured"},
+		},
+		{
+			name:     "inside overnight window",
+			schedule: config.ScheduleConfig{Interval: "1h", Window: window},
+			now:      time.Date(2026, 3, 4, 3, 30, 0, 0, time.UTC),
+			want: []string{
+				"Window:    active (22:00-06:00 UTC), closes in 2h 30m",
+				"Interval:  every 1h",
+			},
+		},
+		{
+			name:     "outside overnight window",
+			schedule: config.ScheduleConfig{Interval: "1h", Window: window},
+			now:      time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC),
+			want: []string{
+				"Window:    closed (22:00-06:00 UTC), opens in 10h 0m",
+				"Interval:  every 1h",
+			},
+		},
+		{
+			name: "inside startup delay",
+			schedule: config.ScheduleConfig{Interval: "1h", Window: &config.WindowConfig{
+				Start: "22:00", End: "06:00", Timezone: "UTC", StartupDelay: "30m",
+			}},
+			now: time.Date(2026, 3, 4, 22, 10, 0, 0, time.UTC),
+			want: []string{
+				"Window:    active (22:00-06:00 UTC), tasks start in 20m 0s, closes in 7h 50m",
+				"Interval:  every 1h",
+			},
+		},
+		{
+			name:     "no window",
+			schedule: config.ScheduleConfig{Interval: "30m"},
+			now:      time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC),
+			want: []string{
+				"Window:    none (runs any time)",
+				"Interval:  every 30m",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Schedule: tt.schedule}
+			got := scheduleStatusLines(cfg, tt.now)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("scheduleStatusLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleStatusLines_Cron(t *testing.T) {
+	cfg := &config.Config{Schedule: config.ScheduleConfig{Cron: "0 2 * * *"}}
+	got := scheduleStatusLines(cfg, time.Now())
+	if len(got) != 2 || !strings.HasPrefix(got[1], "Next cron: ") {
+		t.Errorf("scheduleStatusLines() = %q, want a Next cron line", got)
+	}
+}
+
+func TestStatusSchedule(t *testing.T) {
+	cfg := &config.Config{Schedule: config.ScheduleConfig{
+		Interval: "1h",
+		Window:   &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC"},
+	}}
+
+	inside := statusSchedule(cfg, time.Date(2026, 3, 4, 3, 30, 0, 0, time.UTC))
+	if !inside.Configured || inside.Window == nil || !inside.Window.Active {
+		t.Fatalf("statusSchedule() inside window = %+v, want active window", inside)
+	}
+	if want := time.Date(2026, 3, 4, 6, 0, 0, 0, time.UTC); !inside.Window.ClosesAt.Equal(want) {
+		t.Errorf("ClosesAt = %v, want %v", inside.Window.ClosesAt, want)
+	}
+
+	outside := statusSchedule(cfg, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	if outside.Window == nil || outside.Window.Active {
+		t.Fatalf("statusSchedule() outside window = %+v, want closed window", outside)
+	}
+	if want := time.Date(2026, 3, 4, 22, 0, 0, 0, time.UTC); !outside.Window.OpensAt.Equal(want) {
+		t.Errorf("OpensAt = %v, want %v", outside.Window.OpensAt, want)
+	}
+
+	if got := statusSchedule(&config.Config{}, time.Now()); got.Configured || got.Error != "" {
+		t.Errorf("statusSchedule() with no schedule = %+v, want unconfigured", got)
+	}
+}
+
+func TestWriteStatusJSON(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := &config.Config{Budget: config.BudgetConfig{DBPath: filepath.Join(home, "nightshift.db")}}
+
+	var buf bytes.Buffer
+	if err := writeStatusJSON(&buf, cfg, 5, time.Now()); err != nil {
+		t.Fatalf("writeStatusJSON: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if got["schema_version"] != float64(statusJSONSchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", got["schema_version"], statusJSONSchemaVersion)
+	}
+	for _, key := range []string{"schedule", "daemon", "providers", "today", "recent_runs"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("status JSON missing %q", key)
+		}
+	}
+}
+
+func TestTodayLedger(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	database, err := db.Open(filepath.Join(home, "nightshift.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	st, err := state.New(database)
+	if err != nil {
+		t.Fatalf("state.New: %v", err)
+	}
+
+	var buf bytes.Buffer
+	showTodayLedger(&buf, st)
+	if strings.TrimSpace(buf.String()) != "No runs today." {
+		t.Fatalf("empty ledger = %q", buf.String())
+	}
+
+	now := time.Now()
+	st.AddRunRecord(state.RunRecord{
+		StartTime: now.Add(-20 * time.Millisecond), EndTime: now.Add(-10 * time.Millisecond),
+		Provider: "codex", Project: "/src/api", Tasks: []string{"docs-backfill"}, TokensUsed: 12000, Status: "failed",
+	})
+	// Two runs of lint-fix on web today: each keeps its own attempt.
+	st.AddTaskRun(state.TaskRun{Project: "/src/web", TaskType: "lint-fix", Provider: "claude", Status: "failed", TokensUsed: 3000, StartTime: now.Add(-30 * time.Millisecond), EndTime: now.Add(-25 * time.Millisecond)})
+	st.AddRunRecord(state.RunRecord{
+		StartTime: now.Add(-30 * time.Millisecond), EndTime: now.Add(-25 * time.Millisecond),
+		Provider: "claude", Project: "/src/web", Tasks: []string{"lint-fix"}, TokensUsed: 3000, Status: "failed",
+	})
+	st.AddTaskRun(state.TaskRun{Project: "/src/web", TaskType: "lint-fix", Provider: "claude", Status: "completed", TokensUsed: 30000, StartTime: now.Add(-10 * time.Millisecond), EndTime: now.Add(-5 * time.Millisecond)})
+	st.AddTaskRun(state.TaskRun{Project: "/src/web", TaskType: "bug-finder", Provider: "codex", Status: "failed", TokensUsed: 15000, StartTime: now.Add(-5 * time.Millisecond), EndTime: now})
+	st.AddRunRecord(state.RunRecord{
+		StartTime: now.Add(-10 * time.Millisecond), EndTime: now.Add(time.Millisecond),
+		Provider: "claude", Project: "/src/web", Tasks: []string{"lint-fix", "bug-finder"}, TokensUsed: 45000, Status: "partial",
+	})
+	// A run on cli is still going: no run record yet.
+	st.AddTaskRun(state.TaskRun{Project: "/src/cli", TaskType: "docs-backfill", Provider: "claude", Status: "completed", TokensUsed: 8000, StartTime: now.Add(2 * time.Millisecond), EndTime: now.Add(3 * time.Millisecond)})
+
+	ledger := buildTodayLedger(st)
+	if len(ledger) != 4 || ledger[0].Project != "/src/web" || ledger[1].Project != "/src/api" || ledger[2].Project != "/src/web" || ledger[3].Project != "/src/cli" {
+		t.Fatalf("ledger = %+v, want web, api, web then cli", ledger)
+	}
+	if got := ledger[1].Tasks; len(got) != 1 || got[0].Type != "docs-backfill" || got[0].Status != "" {
+		t.Errorf("api tasks = %+v, want docs-backfill with no attempt recorded", got)
+	}
+	if got := ledger[0].Tasks; len(got) != 1 || got[0].Status != "failed" || got[0].Tokens != 3000 {
+		t.Errorf("first web run tasks = %+v, want the failed lint-fix", got)
+	}
+	if got := ledger[2].Tasks; len(got) != 2 || got[0].Status != "completed" || got[0].Tokens != 30000 ||
+		got[1].Status != "failed" || got[1].Provider != "codex" || got[1].Tokens != 15000 {
+		t.Errorf("second web run tasks = %+v, want completed lint-fix, failed bug-finder", got)
+	}
+	if got := ledger[3]; got.Status != "running" || got.Tokens != 8000 || len(got.Tasks) != 1 {
+		t.Errorf("cli entry = %+v, want a running entry with its finished task", got)
+	}
+
+	buf.Reset()
+	showTodayLedger(&buf, st)
+	for _, want := range []string{
+		"Runs:     3 total (0 success, 2 failed)",
+		"Tokens:   60.0K",
+		"FAILED   api (codex), 12.0K tokens",
+		"PARTIAL  web (claude), 45.0K tokens",
+		" lint-fix: completed, 30.0K tokens",
+		" bug-finder: failed (codex), 15.0K tokens",
+		"RUNNING  cli, 8.0K tokens",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ledger missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeTodayLedgerJSON(&buf, st, now); err != nil {
+		t.Fatalf("writeTodayLedgerJSON: %v", err)
+	}
+	var got todayLedgerJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if got.Date != now.Format("2006-01-02") || got.Summary.Runs != 3 || len(got.Ledger) != 4 || got.Ledger[2].Tasks[1].Tokens != 15000 {
+		t.Errorf("today JSON = %+v", got)
+	}
+}
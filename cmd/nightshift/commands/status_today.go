@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/state"
+)
+
+// todayLedgerEntry is one project run from today's run history, with each
+// task it attempted. A project whose run is still going has no run record
+// yet; its finished tasks are listed under a "running" entry.
+type todayLedgerEntry struct {
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end,omitzero"`
+	Project  string            `json:"project"`
+	Provider string            `json:"provider,omitempty"`
+	Status   string            `json:"status"` // success, failed, partial, running
+	Tokens   int               `json:"tokens"`
+	Label    string            `json:"label,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Tasks    []todayLedgerTask `json:"tasks"`
+}
+
+// todayLedgerTask is a task attempted in a run. Status is the orchestrator
+// outcome (completed, partial, failed, abandoned), or empty for runs
+// recorded before task attempts were stored.
+type todayLedgerTask struct {
+	Type       string    `json:"type"`
+	Provider   string    `json:"provider,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Tokens     int       `json:"tokens"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// todayLedgerJSON is the status --today --json document.
+type todayLedgerJSON struct {
+	SchemaVersion int                `json:"schema_version"`
+	Date          string             `json:"date"`
+	Summary       statusTodayJSON    `json:"summary"`
+	Ledger        []todayLedgerEntry `json:"ledger"`
+}
+
+// buildTodayLedger returns today's runs oldest first. Each run lists the
+// task attempts recorded on its project between its start and end.
+func buildTodayLedger(st *state.State) []todayLedgerEntry {
+	runs := st.GetTodayRuns()
+	slices.Reverse(runs)
+	taskRuns := st.GetTodayTaskRuns()
+	claimed := make([]bool, len(taskRuns))
+
+	ledger := make([]todayLedgerEntry, 0, len(runs))
+	for _, run := range runs {
+		entry := todayLedgerEntry{
+			Start:    run.StartTime,
+			End:      run.EndTime,
+			Project:  run.Project,
+			Provider: run.Provider,
+			Status:   run.Status,
+			Tokens:   run.TokensUsed,
+			Label:    run.Label,
+			Error:    run.Error,
+			Tasks:    make([]todayLedgerTask, 0, len(run.Tasks)),
+		}
+		for i, tr := range taskRuns {
+			if claimed[i] || tr.Project != run.Project || tr.StartTime.Before(run.StartTime) ||
+				(!run.EndTime.IsZero() && tr.StartTime.After(run.EndTime)) {
+				continue
+			}
+			claimed[i] = true
+			entry.Tasks = append(entry.Tasks, ledgerTask(tr))
+		}
+		if len(entry.Tasks) == 0 {
+			for _, taskType := range run.Tasks {
+				entry.Tasks = append(entry.Tasks, todayLedgerTask{Type: taskType})
+			}
+		}
+		ledger = append(ledger, entry)
+	}
+
+	// Whatever is left belongs to runs that haven't finished yet.
+	running := make(map[string]int)
+	for i, tr := range taskRuns {
+		if claimed[i] {
+			continue
+		}
+		idx, ok := running[tr.Project]
+		if !ok {
+			idx = len(ledger)
+			running[tr.Project] = idx
+			ledger = append(ledger, todayLedgerEntry{Start: tr.StartTime, Project: tr.Project, Status: "running"})
+		}
+		ledger[idx].Tokens += tr.TokensUsed
+		ledger[idx].Tasks = append(ledger[idx].Tasks, ledgerTask(tr))
+	}
+	slices.SortStableFunc(ledger, func(a, b todayLedgerEntry) int { return a.Start.Compare(b.Start) })
+	return ledger
+}
+
+// ledgerTask converts a recorded task attempt to a ledger task.
+func ledgerTask(tr state.TaskRun) todayLedgerTask {
+	return todayLedgerTask{
+		Type:       tr.TaskType,
+		Provider:   tr.Provider,
+		Status:     tr.Status,
+		Tokens:     tr.TokensUsed,
+		StartedAt:  tr.StartTime,
+		FinishedAt: tr.EndTime,
+	}
+}
+
+// showTodayLedger prints today's summary followed by the run ledger.
+func showTodayLedger(w io.Writer, st *state.State) {
+	summary := st.GetTodaySummary()
+	ledger := buildTodayLedger(st)
+	if len(ledger) == 0 {
+		_, _ = fmt.Fprintln(w, "No runs today.")
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "Today's Activity Summary\n")
+	_, _ = fmt.Fprintf(w, "========================\n\n")
+	_, _ = fmt.Fprintf(w, "Runs:     %d total (%d success, %d failed)\n",
+		summary.TotalRuns, summary.SuccessfulRuns, summary.FailedRuns)
+	_, _ = fmt.Fprintf(w, "Tokens:   %s\n", formatTokens(summary.TotalTokens))
+	_, _ = fmt.Fprintf(w, "Projects: %d\n", len(summary.Projects))
+
+	_, _ = fmt.Fprintf(w, "\nLedger:\n")
+	for _, entry := range ledger {
+		when := entry.Start.Format("15:04")
+		if !entry.End.IsZero() {
+			when += "-" + entry.End.Format("15:04")
+		}
+		line := fmt.Sprintf("  %-11s %-8s %s", when, formatStatus(entry.Status), filepath.Base(entry.Project))
+		if entry.Provider != "" {
+			line += " (" + entry.Provider + ")"
+		}
+		line += ", " + formatTokens(entry.Tokens) + " tokens"
+		if entry.Label != "" {
+			line += ", label " + entry.Label
+		}
+		_, _ = fmt.Fprintln(w, line)
+		for _, task := range entry.Tasks {
+			if task.Status == "" {
+				_, _ = fmt.Fprintf(w, "      - %s\n", task.Type)
+				continue
+			}
+			line := fmt.Sprintf("      - %s-%s %s: %s", task.StartedAt.Format("15:04"), task.FinishedAt.Format("15:04"), task.Type, task.Status)
+			if task.Provider != "" && task.Provider != entry.Provider {
+				line += " (" + task.Provider + ")"
+			}
+			_, _ = fmt.Fprintln(w, line+", "+formatTokens(task.Tokens)+" tokens")
+		}
+		if entry.Error != "" {
+			_, _ = fmt.Fprintf(w, "      error: %s\n", entry.Error)
+		}
+	}
+}
+
+// writeTodayJSON opens the state database and writes today's summary and
+// run ledger as JSON.
+func writeTodayJSON(w io.Writer, cfg *config.Config, now time.Time) error {
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return fmt.Errorf("opening db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	st, err := state.New(database)
+	if err != nil {
+		return fmt.Errorf("loading state: %w", err)
+	}
+	return writeTodayLedgerJSON(w, st, now)
+}
+
+// writeTodayLedgerJSON writes today's summary and run ledger as JSON.
+func writeTodayLedgerJSON(w io.Writer, st *state.State, now time.Time) error {
+	out := todayLedgerJSON{
+		SchemaVersion: statusJSONSchemaVersion,
+		Date:          now.Format("2006-01-02"),
+		Summary:       statusToday(st.GetTodaySummary()),
+		Ledger:        buildTodayLedger(st),
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
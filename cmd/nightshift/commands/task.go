@@ -4,24 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/calibrator"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
 	"github.com/marcus/nightshift/internal/logging"
 	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/reporting"
 	"github.com/marcus/nightshift/internal/security"
 	"github.com/marcus/nightshift/internal/tasks"
+	"github.com/marcus/nightshift/internal/trends"
 	"github.com/spf13/cobra"
 )
 
 var taskCmd = &cobra.Command{
-	Use:   "task",
-	Short: "Manage and run tasks",
-	Long:  `List available tasks, show their prompts, and run them against a provider.`,
+	Use:     "task",
+	Aliases: []string{"tasks"},
+	Short:   "Manage and run tasks",
+	Long:    `List available tasks, show their prompts, and run them against a provider.`,
 }
 
 var taskListCmd = &cobra.Command{
@@ -30,7 +41,8 @@ var taskListCmd = &cobra.Command{
 	Long: `List all available nightshift tasks with their category, cost tier,
 and estimated token range.
 
-Use --category to filter by category, --cost to filter by cost tier.
+Use --category to filter by category, --cost (or --cost-tier) to filter by
+cost tier, and --risk to filter by risk level.
 Use --json to output as JSON for scripting.`,
 	RunE: runTaskList,
 }
@@ -46,21 +58,62 @@ Use --json for structured output.`,
 	RunE: runTaskShow,
 }
 
+var taskDescribeCmd = &cobra.Command{
+	Use:   "describe <task-type>",
+	Short: "Describe a task's registry details",
+	Long: `Show a task's full description, category, cost tier, risk level,
+and default interval without rendering its prompt.
+
+Use --json for structured output.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTaskDescribe,
+}
+
 var taskRunCmd = &cobra.Command{
-	Use:   "run <task-type> --provider <claude|codex|copilot>",
+	Use:   "run [task-type] --provider <claude|codex|copilot>",
 	Short: "Run a task immediately",
 	Long: `Execute a task immediately against a specific provider.
 
 The --provider flag is required. Use --project to set the working directory.
-Use --dry-run to see what would happen without executing.`,
-	Args: cobra.ExactArgs(1),
+Use --dry-run to see what would happen without executing.
+Use --dump-prompt to print the rendered prompt and the exact agent command
+line, then exit; --dump-prompt=continue prints them and runs the task.
+Use --estimate-only to print the predicted token cost without running: the
+cost tier range plus the median and p90 observed in past run reports for
+this task on this project (--json for structured output).
+
+Use --capture-diff to save the uncommitted changes (git diff, --staged and
+new untracked files) after a completed or partial task. The .diff file goes
+to --output-dir (default: <project>/.nightshift-plan) and is listed as an
+artifact in the run report. Non-git projects are skipped.
+
+Use --diff-only to propose changes without applying them: the agent is told
+not to branch, commit or open a PR, and whatever it changes is saved as a
+.diff file in --output-dir, then stashed so the working tree is left as it
+was. The project must be a git repository with a clean working tree. The
+task is reported as partial with the diff as its artifact; apply it later
+with git apply.
+
+Use --prompt instead of a task type to run a one-off instruction. Ad-hoc
+prompts use default cost settings, are checked against the provider budget,
+and are recorded in a run report with task type "ad-hoc".
+
+Use --env KEY=VALUE (repeatable) to set a variable in the provider CLI's
+environment for this invocation only, e.g. to point it at a staging
+endpoint. It overrides the inherited environment; values are never logged.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runTaskRun,
 }
 
 func init() {
 	taskListCmd.Flags().String("category", "", "Filter by category (pr, analysis, options, safe, map, emergency)")
 	taskListCmd.Flags().String("cost", "", "Filter by cost tier (low, medium, high, veryhigh)")
+	taskListCmd.Flags().String("risk", "", "Filter by risk level (low, medium, high)")
 	taskListCmd.Flags().Bool("json", false, "Output as JSON")
+	taskListCmd.Flags().String("cost-tier", "", "Alias for --cost")
+	_ = taskListCmd.Flags().MarkHidden("cost-tier")
+
+	taskDescribeCmd.Flags().Bool("json", false, "Output as JSON")
 
 	taskShowCmd.Flags().Bool("prompt-only", false, "Output only the raw prompt text")
 	taskShowCmd.Flags().Bool("json", false, "Output as JSON")
@@ -71,10 +124,21 @@ func init() {
 	taskRunCmd.Flags().Bool("dry-run", false, "Show prompt without executing")
 	taskRunCmd.Flags().Duration("timeout", 30*time.Minute, "Execution timeout")
 	taskRunCmd.Flags().StringP("branch", "b", "", "Base branch for new feature branches (defaults to current branch)")
+	taskRunCmd.Flags().String("prompt", "", "Run a one-off instruction instead of a registered task type")
+	taskRunCmd.Flags().String("dump-prompt", "", "Print the rendered prompt and agent command, then exit (or =continue to run)")
+	taskRunCmd.Flags().Lookup("dump-prompt").NoOptDefVal = dumpPromptExit
+	taskRunCmd.Flags().Bool("estimate-only", false, "Print the predicted token cost from the cost tier and past runs, then exit")
+	taskRunCmd.Flags().Bool("json", false, "Output the --estimate-only prediction as JSON")
+	taskRunCmd.Flags().Bool("capture-diff", false, "Save the project's git diff after a completed or partial task and record it in the run report")
+	taskRunCmd.Flags().Bool("diff-only", false, "Save the proposed changes as a .diff file and leave the working tree untouched")
+	taskRunCmd.Flags().String("output-dir", "", "Directory for --capture-diff and --diff-only files (default: <project>/.nightshift-plan)")
+	taskRunCmd.Flags().StringArray("env", nil, "Set KEY=VALUE in the provider CLI's environment for this run (repeatable)")
+	taskRunCmd.Flags().Int64("max-tokens-per-task", 0, "Abandon the task once it uses this many tokens, below its cost tier max (0 = no cap)")
 	_ = taskRunCmd.MarkFlagRequired("provider")
 
 	taskCmd.AddCommand(taskListCmd)
 	taskCmd.AddCommand(taskShowCmd)
+	taskCmd.AddCommand(taskDescribeCmd)
 	taskCmd.AddCommand(taskRunCmd)
 	rootCmd.AddCommand(taskCmd)
 }
@@ -82,6 +146,10 @@ func init() {
 func runTaskList(cmd *cobra.Command, args []string) error {
 	categoryFilter, _ := cmd.Flags().GetString("category")
 	costFilter, _ := cmd.Flags().GetString("cost")
+	if costFilter == "" {
+		costFilter, _ = cmd.Flags().GetString("cost-tier")
+	}
+	riskFilter, _ := cmd.Flags().GetString("risk")
 	asJSON, _ := cmd.Flags().GetBool("json")
 
 	defs := tasks.AllDefinitionsSorted()
@@ -100,6 +168,13 @@ func runTaskList(cmd *cobra.Command, args []string) error {
 		}
 		defs = filterByCost(defs, tier)
 	}
+	if riskFilter != "" {
+		risk, err := parseRiskFilter(riskFilter)
+		if err != nil {
+			return err
+		}
+		defs = filterByRisk(defs, risk)
+	}
 
 	if len(defs) == 0 {
 		fmt.Println("No tasks match the given filters.")
@@ -176,18 +251,81 @@ func runTaskShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runTaskRun(cmd *cobra.Command, args []string) error {
+func runTaskDescribe(cmd *cobra.Command, args []string) error {
 	taskType := tasks.TaskType(args[0])
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	def, err := tasks.GetDefinition(taskType)
+	if err != nil {
+		return fmt.Errorf("unknown task: %s\nRun 'nightshift task list' to see available tasks", taskType)
+	}
+
+	if asJSON {
+		return printTaskDescribeJSON(def)
+	}
+
+	min, max := def.EstimatedTokens()
+	fmt.Printf("Task:        %s\n", def.Name)
+	fmt.Printf("Type:        %s\n", def.Type)
+	fmt.Printf("Category:    %s\n", def.Category)
+	fmt.Printf("Cost:        %s\n", def.CostTier)
+	fmt.Printf("Tokens:      %s - %s\n", formatK(min), formatK(max))
+	fmt.Printf("Risk:        %s\n", def.RiskLevel)
+	fmt.Printf("Interval:    %s\n", formatTaskInterval(def.DefaultInterval))
+	if def.DisabledByDefault {
+		fmt.Printf("Enabled:     no (opt-in)\n")
+	}
+	if tasks.IsCustom(def.Type) {
+		fmt.Printf("Custom:      yes\n")
+	}
+	fmt.Println()
+	fmt.Println(strings.TrimSpace(def.Description))
+
+	return nil
+}
+
+func runTaskRun(cmd *cobra.Command, args []string) error {
 	provider, _ := cmd.Flags().GetString("provider")
 	projectPath, _ := cmd.Flags().GetString("project")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	timeout, _ := cmd.Flags().GetDuration("timeout")
 	branch, _ := cmd.Flags().GetString("branch")
+	adHocPrompt, _ := cmd.Flags().GetString("prompt")
+	dumpPrompt, _ := cmd.Flags().GetString("dump-prompt")
+	if err := validateDumpPrompt(dumpPrompt); err != nil {
+		return err
+	}
+	estimateOnly, _ := cmd.Flags().GetBool("estimate-only")
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if asJSON && !estimateOnly {
+		return fmt.Errorf("--json requires --estimate-only")
+	}
+	captureDiff, _ := cmd.Flags().GetBool("capture-diff")
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	diffOnly, _ := cmd.Flags().GetBool("diff-only")
+	if captureDiff && diffOnly {
+		return fmt.Errorf("--capture-diff and --diff-only are mutually exclusive")
+	}
+	if outputDir != "" && !captureDiff && !diffOnly {
+		return fmt.Errorf("--output-dir requires --capture-diff or --diff-only")
+	}
+	maxTokens, _ := cmd.Flags().GetInt64("max-tokens-per-task")
+	if maxTokens < 0 {
+		return fmt.Errorf("--max-tokens-per-task must be >= 0")
+	}
+	envFlags, _ := cmd.Flags().GetStringArray("env")
+	env, err := parseEnvFlags(envFlags)
+	if err != nil {
+		return err
+	}
+	agentEnv = env
 
-	def, err := tasks.GetDefinition(taskType)
+	def, err := resolveTaskRunDefinition(args, adHocPrompt)
 	if err != nil {
-		return fmt.Errorf("unknown task: %s\nRun 'nightshift task list' to see available tasks", taskType)
+		return err
 	}
+	taskType := def.Type
+	adHoc := taskType == tasks.TaskAdHoc
 
 	// Resolve project path
 	if projectPath == "" {
@@ -203,6 +341,26 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if estimateOnly {
+		if abs, err := filepath.Abs(projectPath); err == nil {
+			projectPath = abs
+		}
+		cfg, err := loadConfig(projectPath)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		runs, err := loadRunReports(reportsDir(cfg))
+		if err != nil {
+			return err
+		}
+		est := buildTaskEstimate(def, projectPath, runs)
+		if asJSON {
+			return printTaskEstimateJSON(os.Stdout, est)
+		}
+		printTaskEstimate(os.Stdout, est)
+		return nil
+	}
+
 	// Resolve branch: use flag value or detect current branch
 	ctx := context.Background()
 	if branch == "" {
@@ -219,30 +377,72 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	readOnly := cfg.ReadOnlyProject(projectPath)
+	if readOnly && !adHoc && !def.ReadOnly() {
+		return fmt.Errorf("%s changes code; %s is a read-only project", taskType, projectPath)
+	}
 
 	agent, err := agentByName(cfg, provider)
 	if err != nil {
 		return err
 	}
 
-	orch := orchestrator.New(
+	orchOpts := []orchestrator.Option{
 		orchestrator.WithAgent(agent),
 		orchestrator.WithConfig(orchestrator.Config{
 			MaxIterations: 3,
 			AgentTimeout:  timeout,
+			AgentOutput:   orchestrator.AgentOutputMode(cfg.GetAgentOutput()),
 		}),
 		orchestrator.WithLogger(logging.Component("task-run")),
-	)
+	}
+	// --max-tokens-per-task is enforced by the token meter, which only
+	// providers that count tokens have.
+	metered := false
+	if maxTokens > 0 {
+		meter := newTokenMeters(newClaudeProvider(cfg), newCodexProvider(cfg))[strings.ToLower(provider)]
+		metered = meter != nil
+		orchOpts = append(orchOpts, orchestrator.WithTokenMeter(meter))
+	}
+	orch := orchestrator.New(orchOpts...)
+	if maxTokens > 0 {
+		orch.SetTokenCap(taskTokenCap(cfg, def, maxTokens))
+	}
 
 	// Inject run metadata with branch for prompt generation
+	model := cfg.GetTaskModel(string(taskType), strings.ToLower(provider))
+	workBranch, err := cfg.WorkBranchName(projectPath, string(taskType), strings.ToLower(provider), time.Now())
+	if err != nil {
+		return fmt.Errorf("branch template: %w", err)
+	}
 	orch.SetRunMetadata(&orchestrator.RunMetadata{
-		Provider: provider,
-		TaskType: string(taskType),
-		Branch:   branch,
+		Provider:   provider,
+		Model:      model,
+		TaskType:   string(taskType),
+		Branch:     branch,
+		WorkBranch: workBranch,
+		PRDraft:    cfg.Orchestrator.PR.Draft,
+		PRBase:     cfg.Orchestrator.PR.TargetBranch,
+		Forge:      cfg.GetForge(),
+		DenyPaths:  cfg.DenyPathsFor(projectPath),
+		DiffOnly:   diffOnly,
+		DiffDir:    outputDir,
+		ReadOnly:   readOnly,
 	})
 
 	prompt := orch.PlanPrompt(taskInstance)
 
+	if dumpPrompt != "" {
+		printPromptDump(os.Stdout, prompt, agentCommandLine(agent, agents.ExecuteOptions{
+			Prompt:  prompt,
+			WorkDir: projectPath,
+			Model:   model,
+		}), projectPath)
+		if dumpPrompt == dumpPromptExit {
+			return nil
+		}
+	}
+
 	fmt.Printf("Task:     %s (%s)\n", def.Name, def.Type)
 	fmt.Printf("Provider: %s\n", provider)
 	fmt.Printf("Project:  %s\n", projectPath)
@@ -253,6 +453,13 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 
 	min, max := def.EstimatedTokens()
 	fmt.Printf("Est:      %s-%s tokens\n", formatK(min), formatK(max))
+	if maxTokens > 0 {
+		note := "enforced"
+		if !metered {
+			note = "reserved only; " + provider + " can't count tokens"
+		}
+		fmt.Printf("Cap:      %s tokens (%s)\n", formatK(int(maxTokens)), note)
+	}
 
 	if dryRun {
 		fmt.Println("\n[dry-run] Would send this prompt:")
@@ -261,6 +468,21 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Ad-hoc prompts have no cooldown or selection, so account for them
+	// explicitly: check the provider budget and record a run report.
+	// --capture-diff and --diff-only also record a report so the diff shows
+	// up in `report`.
+	var report *runReport
+	if adHoc {
+		report, err = startAdHocReport(cfg, provider, def, maxTokens)
+		if err != nil {
+			return err
+		}
+	} else if captureDiff || diffOnly {
+		report = newRunReport(cfg, time.Now(), 0)
+		report.warnReportsDir(logging.Component("task-run"))
+	}
+
 	fmt.Println()
 	fmt.Println("Running...")
 
@@ -277,6 +499,25 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 	}()
 
 	result, err := orch.RunTask(ctx, taskInstance, projectPath)
+	diffPath := result.DiffPath
+	if captureDiff && err == nil && (result.Status == orchestrator.StatusCompleted || result.Status == orchestrator.StatusPartial) {
+		diffCtx, diffCancel := context.WithTimeout(context.Background(), time.Minute)
+		diffPath, err = captureTaskDiff(diffCtx, projectPath, outputDir, string(taskType), time.Now())
+		diffCancel()
+		if err != nil {
+			fmt.Printf("warning: capture diff: %v\n", err)
+			err = nil
+		}
+	}
+	if report != nil {
+		tr := taskRunResult(def, projectPath, result, err, maxTokens)
+		tr.Provider = strings.ToLower(provider)
+		if diffPath != "" {
+			tr.Artifacts = []string{diffPath}
+		}
+		report.addTask(tr)
+		report.finalize(cfg, logging.Component("task-run"))
+	}
 	if err != nil {
 		return fmt.Errorf("task failed: %w", err)
 	}
@@ -285,12 +526,32 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 	switch result.Status {
 	case orchestrator.StatusCompleted:
 		fmt.Printf("COMPLETED in %d iteration(s) (%s)\n", result.Iterations, result.Duration.Round(time.Second))
+	case orchestrator.StatusPartial:
+		if result.OutputType == "" {
+			fmt.Printf("PARTIAL after %d iteration(s): %s\n", result.Iterations, result.Error)
+		} else {
+			fmt.Printf("PARTIAL after %d iteration(s), %s %s: %s\n", result.Iterations, result.OutputType, result.OutputRef, result.Error)
+		}
 	case orchestrator.StatusAbandoned:
 		fmt.Printf("ABANDONED after %d iteration(s): %s\n", result.Iterations, result.Error)
 	default:
 		fmt.Printf("FAILED: %s\n", result.Error)
 	}
 
+	if diffOnly {
+		if diffPath != "" {
+			fmt.Printf("Diff:     %s (not applied; git apply to keep it)\n", diffPath)
+		} else if result.Status == orchestrator.StatusCompleted {
+			fmt.Println("Diff:     nothing proposed (the agent made no changes)")
+		}
+	} else if captureDiff && (result.Status == orchestrator.StatusCompleted || result.Status == orchestrator.StatusPartial) {
+		if diffPath != "" {
+			fmt.Printf("Diff:     %s\n", diffPath)
+		} else {
+			fmt.Println("Diff:     nothing captured (no uncommitted changes, or not a git repository)")
+		}
+	}
+
 	if result.Output != "" {
 		fmt.Println()
 		fmt.Println("--- Output ---")
@@ -300,6 +561,137 @@ func runTaskRun(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// --dump-prompt modes.
+const (
+	dumpPromptExit     = "exit"
+	dumpPromptContinue = "continue"
+)
+
+func validateDumpPrompt(mode string) error {
+	switch mode {
+	case "", dumpPromptExit, dumpPromptContinue:
+		return nil
+	default:
+		return fmt.Errorf("invalid --dump-prompt %q (valid: %s, %s)", mode, dumpPromptExit, dumpPromptContinue)
+	}
+}
+
+// printPromptDump writes the rendered plan prompt and the agent command line
+// that carries it, so unexpected agent behavior can be traced to its input.
+func printPromptDump(w io.Writer, prompt, commandLine, workDir string) {
+	_, _ = fmt.Fprintln(w, "--- Prompt ---")
+	_, _ = fmt.Fprintln(w, prompt)
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "--- Command ---")
+	if commandLine == "" {
+		_, _ = fmt.Fprintln(w, "(agent does not report its command line)")
+	} else {
+		_, _ = fmt.Fprintln(w, commandLine)
+	}
+	_, _ = fmt.Fprintf(w, "(in %s)\n", workDir)
+}
+
+// resolveTaskRunDefinition returns the definition for `task run`: either the
+// registered task named in args or an ad-hoc definition built from prompt.
+func resolveTaskRunDefinition(args []string, prompt string) (tasks.TaskDefinition, error) {
+	prompt = strings.TrimSpace(prompt)
+	switch {
+	case prompt != "" && len(args) > 0:
+		return tasks.TaskDefinition{}, fmt.Errorf("specify a task type or --prompt, not both")
+	case prompt != "":
+		return tasks.AdHocDefinition(prompt), nil
+	case len(args) == 0:
+		return tasks.TaskDefinition{}, fmt.Errorf("task type or --prompt required")
+	}
+	taskType := tasks.TaskType(args[0])
+	def, err := tasks.GetDefinition(taskType)
+	if err != nil {
+		return tasks.TaskDefinition{}, fmt.Errorf("unknown task: %s\nRun 'nightshift task list' to see available tasks", taskType)
+	}
+	return def, nil
+}
+
+// startAdHocReport checks that provider has budget left for an ad-hoc
+// prompt and returns a run report to record it in. maxTokens, when
+// positive, lowers the reservation below the cost tier max.
+func startAdHocReport(cfg *config.Config, provider string, def tasks.TaskDefinition, maxTokens int64) (*runReport, error) {
+	log := logging.Component("task-run")
+
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	budgetMgr := newTaskBudgetManager(cfg, database)
+	allowance, err := budgetMgr.CalculateAllowance(strings.ToLower(provider))
+	if err != nil {
+		return nil, fmt.Errorf("budget %s: %w", provider, err)
+	}
+	if allowance.Allowance <= 0 {
+		return nil, fmt.Errorf("budget exhausted for %s (%.1f%% used)", provider, allowance.UsedPercent)
+	}
+	if maxTok := def.CappedMaxTokens(maxTokens); maxTok > allowance.Allowance {
+		log.Warnf("ad-hoc estimate %d tokens exceeds remaining %s budget %d", maxTok, provider, allowance.Allowance)
+	}
+	fmt.Printf("Budget:   %s tokens available\n", formatK(int(allowance.Allowance)))
+
+	report := newRunReport(cfg, time.Now(), int(allowance.Allowance))
+	report.warnReportsDir(log)
+	report.results.ProviderSnapshots = captureProviderSnapshots(cfg, budgetMgr, log)
+	return report, nil
+}
+
+// newTaskBudgetManager builds the budget manager used by one-off task
+// commands (ad-hoc runs and grooming).
+func newTaskBudgetManager(cfg *config.Config, database *db.DB) *budget.Manager {
+	claudeProvider := newClaudeProvider(cfg)
+	codexProvider := newCodexProvider(cfg)
+	copilotProvider := providers.NewCopilotWithPath(cfg.ExpandedProviderPath("copilot"))
+	cal := calibrator.New(database, cfg)
+	trend := trends.NewAnalyzer(database, cfg.Budget.SnapshotRetentionDays)
+	return budget.NewManagerFromProviders(cfg, claudeProvider, codexProvider, copilotProvider, budget.WithBudgetSource(cal), budget.WithTrendAnalyzer(trend), budgetReservations(database))
+}
+
+// taskRunResult converts a task run's orchestrator result into a report
+// entry. Completed and partial tasks are charged their max token estimate,
+// lowered to maxTokens when positive, as in `run`.
+func taskRunResult(def tasks.TaskDefinition, projectPath string, result *orchestrator.TaskResult, runErr error, maxTokens int64) reporting.TaskResult {
+	tr := reporting.TaskResult{
+		Project:  projectPath,
+		TaskType: string(def.Type),
+		Title:    def.Name,
+		Status:   "failed",
+	}
+	if result != nil {
+		tr.Duration = result.Duration
+		tr.SkipReason = result.Error
+		tr.DeniedPaths = result.DeniedPaths
+		tr.Branch = result.Branch
+		tr.EventLog = result.EventLog
+	}
+	if runErr != nil {
+		if tr.SkipReason == "" {
+			tr.SkipReason = runErr.Error()
+		}
+		return tr
+	}
+	switch result.Status {
+	case orchestrator.StatusCompleted:
+		tr.Status = "completed"
+		tr.SkipReason = ""
+		tr.OutputType = result.OutputType
+		tr.OutputRef = result.OutputRef
+		tr.TokensUsed = int(def.CappedMaxTokens(maxTokens))
+	case orchestrator.StatusPartial:
+		tr.Status = "partial"
+		tr.OutputType = result.OutputType
+		tr.OutputRef = result.OutputRef
+		tr.TokensUsed = int(def.CappedMaxTokens(maxTokens))
+	}
+	return tr
+}
+
 // taskInstanceFromDef creates a tasks.Task from a TaskDefinition for prompt building.
 func taskInstanceFromDef(def tasks.TaskDefinition, projectPath string) *tasks.Task {
 	id := string(def.Type)
@@ -351,6 +743,19 @@ func parseCostFilter(s string) (tasks.CostTier, error) {
 	}
 }
 
+func parseRiskFilter(s string) (tasks.RiskLevel, error) {
+	switch strings.ToLower(s) {
+	case "low":
+		return tasks.RiskLow, nil
+	case "medium":
+		return tasks.RiskMedium, nil
+	case "high":
+		return tasks.RiskHigh, nil
+	default:
+		return 0, fmt.Errorf("unknown risk level: %s (valid: low, medium, high)", s)
+	}
+}
+
 func filterByCategory(defs []tasks.TaskDefinition, cat tasks.TaskCategory) []tasks.TaskDefinition {
 	var out []tasks.TaskDefinition
 	for _, d := range defs {
@@ -371,6 +776,16 @@ func filterByCost(defs []tasks.TaskDefinition, tier tasks.CostTier) []tasks.Task
 	return out
 }
 
+func filterByRisk(defs []tasks.TaskDefinition, risk tasks.RiskLevel) []tasks.TaskDefinition {
+	var out []tasks.TaskDefinition
+	for _, d := range defs {
+		if d.RiskLevel == risk {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
 // --- Formatters ---
 
 func categoryShort(c tasks.TaskCategory) string {
@@ -417,6 +832,20 @@ func formatK(tokens int) string {
 	return fmt.Sprintf("%d", tokens)
 }
 
+// formatTaskInterval renders a default interval in whole days or hours.
+func formatTaskInterval(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "none"
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
+	}
+}
+
 // --- JSON output ---
 
 type taskListEntry struct {
@@ -483,3 +912,37 @@ func printTaskShowJSON(def tasks.TaskDefinition, prompt string) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(entry)
 }
+
+type taskDescribeEntry struct {
+	Type              string `json:"type"`
+	Name              string `json:"name"`
+	Category          string `json:"category"`
+	Description       string `json:"description"`
+	Cost              string `json:"cost"`
+	MinTokens         int    `json:"min_tokens"`
+	MaxTokens         int    `json:"max_tokens"`
+	Risk              string `json:"risk"`
+	DefaultInterval   string `json:"default_interval"`
+	DisabledByDefault bool   `json:"disabled_by_default"`
+	Custom            bool   `json:"custom"`
+}
+
+func printTaskDescribeJSON(def tasks.TaskDefinition) error {
+	min, max := def.EstimatedTokens()
+	entry := taskDescribeEntry{
+		Type:              string(def.Type),
+		Name:              def.Name,
+		Category:          categoryShort(def.Category),
+		Description:       def.Description,
+		Cost:              costShort(def.CostTier),
+		MinTokens:         min,
+		MaxTokens:         max,
+		Risk:              def.RiskLevel.String(),
+		DefaultInterval:   def.DefaultInterval.String(),
+		DisabledByDefault: def.DisabledByDefault,
+		Custom:            tasks.IsCustom(def.Type),
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entry)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultDiffDir is where --capture-diff writes inside the project. Setup
+// adds it to .gitignore.
+const defaultDiffDir = nightshiftPlanIgnore
+
+// captureTaskDiff writes the project's unstaged, staged and untracked changes
+// to a .diff file in outDir (default: <project>/.nightshift-plan) and returns
+// its path. Non-git projects and clean trees return "" with no error.
+func captureTaskDiff(ctx context.Context, projectPath, outDir, taskType string, now time.Time) (string, error) {
+	if _, err := runGitDiff(ctx, projectPath, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return "", nil
+	}
+	if outDir == "" {
+		outDir = filepath.Join(projectPath, defaultDiffDir)
+	}
+	outDir, err := filepath.Abs(expandPath(outDir))
+	if err != nil {
+		return "", fmt.Errorf("output dir: %w", err)
+	}
+
+	var b strings.Builder
+	unstaged, err := runGitDiff(ctx, projectPath, "diff")
+	if err != nil {
+		return "", err
+	}
+	staged, err := runGitDiff(ctx, projectPath, "diff", "--staged")
+	if err != nil {
+		return "", err
+	}
+	untracked, err := untrackedDiff(ctx, projectPath, outDir)
+	if err != nil {
+		return "", err
+	}
+	for _, part := range []struct{ title, body string }{
+		{"git diff", unstaged},
+		{"git diff --staged", staged},
+		{"untracked files", untracked},
+	} {
+		if part.body == "" {
+			continue
+		}
+		b.WriteString("# " + part.title + "\n")
+		b.WriteString(part.body)
+		if !strings.HasSuffix(part.body, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", fmt.Errorf("creating output dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s.diff", filepath.Base(projectPath), taskType, now.Format("20060102-150405"))
+	path := filepath.Join(outDir, name)
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("writing diff: %w", err)
+	}
+	return path, nil
+}
+
+// untrackedDiff renders new, non-ignored files as additions, skipping
+// anything under outDir so earlier captures aren't included.
+func untrackedDiff(ctx context.Context, projectPath, outDir string) (string, error) {
+	list, err := runGitDiff(ctx, projectPath, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range strings.Split(list, "\x00") {
+		if file == "" {
+			continue
+		}
+		abs, _ := filepath.Abs(filepath.Join(projectPath, file))
+		if rel, err := filepath.Rel(outDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			continue
+		}
+		// --no-index exits 1 when the files differ, which they always do here.
+		out, err := runGitDiff(ctx, projectPath, "diff", "--no-index", "--", os.DevNull, file)
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", err
+		}
+		b.WriteString(out)
+	}
+	return b.String(), nil
+}
+
+func runGitDiff(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return out.String(), nil
+}
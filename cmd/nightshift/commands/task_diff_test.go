@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCaptureTaskDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	t.Run("non-git project", func(t *testing.T) {
+		dir := t.TempDir()
+		path, err := captureTaskDiff(ctx, dir, "", "lint-fix", now)
+		if err != nil || path != "" {
+			t.Fatalf("captureTaskDiff = %q, %v; want empty, nil", path, err)
+		}
+	})
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.email=t@example.com", "-c", "user.name=t"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "main.go")
+	git("commit", "-q", "-m", "init")
+
+	t.Run("clean tree", func(t *testing.T) {
+		path, err := captureTaskDiff(ctx, dir, "", "lint-fix", now)
+		if err != nil || path != "" {
+			t.Fatalf("captureTaskDiff = %q, %v; want empty, nil", path, err)
+		}
+	})
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main // new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("default dir", func(t *testing.T) {
+		path, err := captureTaskDiff(ctx, dir, "", "lint-fix", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := filepath.Join(dir, defaultDiffDir, filepath.Base(dir)+"-lint-fix-20260301-020000.diff")
+		if path != want {
+			t.Fatalf("path = %q, want %q", path, want)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{"# git diff\n", "+func main() {}", "# untracked files\n", "+package main // new"} {
+			if !strings.Contains(string(data), s) {
+				t.Errorf("diff missing %q:\n%s", s, data)
+			}
+		}
+
+		// A second capture must not include the first one.
+		again, err := captureTaskDiff(ctx, dir, "", "lint-fix", now.Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ = os.ReadFile(again)
+		if strings.Contains(string(data), ".diff") {
+			t.Errorf("second capture includes earlier diff file:\n%s", data)
+		}
+	})
+
+	t.Run("output dir", func(t *testing.T) {
+		out := t.TempDir()
+		path, err := captureTaskDiff(ctx, dir, out, "docs-backfill", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Dir(path) != out {
+			t.Errorf("path = %q, want it in %q", path, out)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/reporting"
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// taskEstimate is the task run --estimate-only prediction for one task on
+// one project.
+type taskEstimate struct {
+	Task      string               `json:"task"`
+	Project   string               `json:"project"`
+	CostTier  string               `json:"cost_tier"`
+	MinTokens int                  `json:"min_tokens"`
+	MaxTokens int                  `json:"max_tokens"`
+	History   *taskEstimateHistory `json:"history,omitempty"`
+}
+
+// taskEstimateHistory summarizes token usage observed in past run reports.
+type taskEstimateHistory struct {
+	Runs         int       `json:"runs"`
+	MedianTokens int       `json:"median_tokens"`
+	P90Tokens    int       `json:"p90_tokens"`
+	LastRun      time.Time `json:"last_run,omitzero"`
+}
+
+// buildTaskEstimate combines the cost-tier range for def with the tokens
+// recorded for the same task and project in runs. Skipped tasks and tasks
+// without a token count are ignored.
+func buildTaskEstimate(def tasks.TaskDefinition, projectPath string, runs []reportRun) taskEstimate {
+	min, max := def.EstimatedTokens()
+	est := taskEstimate{
+		Task:      string(def.Type),
+		Project:   projectPath,
+		CostTier:  def.CostTier.String(),
+		MinTokens: min,
+		MaxTokens: max,
+	}
+
+	project := filepath.Clean(projectPath)
+	var samples []int
+	var lastRun time.Time
+	for _, run := range runs {
+		if run.results == nil {
+			continue
+		}
+		for _, task := range run.results.Tasks {
+			if task.TaskType != string(def.Type) || task.Project == "" || filepath.Clean(task.Project) != project {
+				continue
+			}
+			if task.Status == "skipped" || task.TokensUsed <= 0 {
+				continue
+			}
+			samples = append(samples, task.TokensUsed)
+			if ts := reportRunTime(run.results); ts.After(lastRun) {
+				lastRun = ts
+			}
+		}
+	}
+	if len(samples) == 0 {
+		return est
+	}
+
+	sort.Ints(samples)
+	est.History = &taskEstimateHistory{
+		Runs:         len(samples),
+		MedianTokens: percentileInt(samples, 0.50),
+		P90Tokens:    percentileInt(samples, 0.90),
+		LastRun:      lastRun,
+	}
+	return est
+}
+
+// reportRunTime returns when a run started, falling back to its end or date.
+func reportRunTime(results *reporting.RunResults) time.Time {
+	switch {
+	case !results.StartTime.IsZero():
+		return results.StartTime
+	case !results.EndTime.IsZero():
+		return results.EndTime
+	}
+	return results.Date
+}
+
+// percentileInt returns the nearest-rank percentile p (0-1] of sorted.
+func percentileInt(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted)) + 0.999999)
+	rank = max(1, min(rank, len(sorted)))
+	return sorted[rank-1]
+}
+
+func printTaskEstimateJSON(w io.Writer, est taskEstimate) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(est)
+}
+
+func printTaskEstimate(w io.Writer, est taskEstimate) {
+	styles := newReportStyles()
+	var b strings.Builder
+
+	b.WriteString(styles.Title.Render(fmt.Sprintf("Estimate: %s", est.Task)))
+	b.WriteString("\n")
+	b.WriteString(styles.Subtitle.Render(est.Project))
+	b.WriteString("\n\n")
+
+	row := func(label, value string) {
+		b.WriteString("  " + styles.Label.Render(fmt.Sprintf("%-10s", label)) + " " + styles.Value.Render(value) + "\n")
+	}
+	row("Cost tier", est.CostTier)
+	row("Range", fmt.Sprintf("%s - %s tokens", formatK(est.MinTokens), formatK(est.MaxTokens)))
+
+	if est.History == nil {
+		b.WriteString("  " + styles.Muted.Render("No past runs of this task on this project; using the cost tier range.") + "\n")
+		_, _ = fmt.Fprint(w, b.String())
+		return
+	}
+
+	h := est.History
+	runWord := "runs"
+	if h.Runs == 1 {
+		runWord = "run"
+	}
+	row("Median", formatK(h.MedianTokens)+" tokens")
+	row("P90", formatK(h.P90Tokens)+" tokens")
+	history := fmt.Sprintf("%d %s", h.Runs, runWord)
+	if !h.LastRun.IsZero() {
+		history += ", last " + h.LastRun.Local().Format("2006-01-02")
+	}
+	row("History", history)
+	if h.P90Tokens > est.MaxTokens {
+		b.WriteString("  " + styles.Warn.Render("P90 exceeds the cost tier range; expect this task to run long here.") + "\n")
+	}
+	_, _ = fmt.Fprint(w, b.String())
+}
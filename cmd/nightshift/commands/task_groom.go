@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/security"
+	"github.com/marcus/nightshift/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+var taskGroomCmd = &cobra.Command{
+	Use:   "groom --provider <claude|codex|copilot>",
+	Short: "Suggest clearer prompts for custom tasks",
+	Long: `Run an agent pass over each tasks.custom description against a project
+and write proposed, clearer prompts to a markdown file for review.
+
+Nothing in the config is changed: copy the suggestions you like into
+tasks.custom yourself. The agent is asked to read the project but not
+modify it.
+
+The --provider flag is required. Use --project to set the target project
+(default: current directory) and --task to groom only some custom tasks.
+Suggestions go to --output (default:
+<project>/.nightshift-plan/task-groom-<timestamp>.md).
+Each pass is checked against the provider budget; tasks that no longer fit
+are left out of the file. Use --dry-run to print the prompts without
+calling the agent.`,
+	Args: cobra.NoArgs,
+	RunE: runTaskGroom,
+}
+
+func init() {
+	taskGroomCmd.Flags().String("provider", "", "Provider to run against (claude, codex, copilot)")
+	taskGroomCmd.Flags().StringP("project", "p", "", "Project the custom tasks run against")
+	taskGroomCmd.Flags().StringSlice("task", nil, "Only groom these custom task types (repeatable)")
+	taskGroomCmd.Flags().StringP("output", "o", "", "Suggestions file (default: <project>/.nightshift-plan/task-groom-<timestamp>.md)")
+	taskGroomCmd.Flags().Bool("dry-run", false, "Show the grooming prompts without executing")
+	taskGroomCmd.Flags().Duration("timeout", 10*time.Minute, "Timeout for each agent pass")
+	_ = taskGroomCmd.MarkFlagRequired("provider")
+
+	taskCmd.AddCommand(taskGroomCmd)
+}
+
+// groomSuggestion is the agent's proposal for one custom task.
+type groomSuggestion struct {
+	task       config.CustomTaskConfig
+	suggestion string
+	err        error
+}
+
+func runTaskGroom(cmd *cobra.Command, args []string) error {
+	provider, _ := cmd.Flags().GetString("provider")
+	projectPath, _ := cmd.Flags().GetString("project")
+	only, _ := cmd.Flags().GetStringSlice("task")
+	output, _ := cmd.Flags().GetString("output")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	if projectPath == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		projectPath = wd
+	}
+	projectPath, err := filepath.Abs(expandPath(projectPath))
+	if err != nil {
+		return fmt.Errorf("project path: %w", err)
+	}
+	if err := security.ValidateProjectPath(projectPath); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(projectPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	custom, err := selectCustomTasks(cfg.Tasks.Custom, only)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Provider: %s\n", provider)
+	fmt.Printf("Project:  %s\n", projectPath)
+	fmt.Printf("Tasks:    %d custom\n", len(custom))
+
+	if dryRun {
+		for _, ct := range custom {
+			fmt.Printf("\n[dry-run] Would send this prompt for %s:\n\n", ct.Type)
+			fmt.Println(groomPrompt(ct, projectPath))
+		}
+		return nil
+	}
+
+	agent, err := agentByName(cfg, provider)
+	if err != nil {
+		return err
+	}
+	passes, err := groomBudgetPasses(cfg, provider)
+	if err != nil {
+		return err
+	}
+	if passes < len(custom) {
+		fmt.Printf("Budget:   enough for %d of %d tasks; grooming the first %d\n", passes, len(custom), passes)
+		custom = custom[:passes]
+	}
+
+	if output == "" {
+		output = filepath.Join(projectPath, nightshiftPlanIgnore, fmt.Sprintf("task-groom-%s.md", time.Now().Format("20060102-150405")))
+	}
+
+	model := cfg.GetTaskModel(string(tasks.TaskGroomer), strings.ToLower(provider))
+	var suggestions []groomSuggestion
+	for _, ct := range custom {
+		fmt.Printf("Grooming %s...\n", ct.Type)
+		s := groomSuggestion{task: ct}
+		s.suggestion, s.err = groomTask(context.Background(), agent, agents.ExecuteOptions{
+			Prompt:    groomPrompt(ct, projectPath),
+			WorkDir:   projectPath,
+			Timeout:   timeout,
+			Model:     model,
+			DenyPaths: cfg.DenyPathsFor(projectPath),
+		})
+		if s.err != nil {
+			fmt.Printf("  failed: %v\n", s.err)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	if err := writeGroomSuggestions(output, projectPath, suggestions, time.Now()); err != nil {
+		return err
+	}
+	fmt.Printf("\nSuggestions written to %s (config not modified)\n", output)
+	return nil
+}
+
+// selectCustomTasks returns the configured custom tasks, limited to only
+// when given.
+func selectCustomTasks(custom []config.CustomTaskConfig, only []string) ([]config.CustomTaskConfig, error) {
+	if len(custom) == 0 {
+		return nil, fmt.Errorf("no custom tasks configured (tasks.custom)")
+	}
+	if len(only) == 0 {
+		return custom, nil
+	}
+	byType := make(map[string]config.CustomTaskConfig, len(custom))
+	for _, ct := range custom {
+		byType[ct.Type] = ct
+	}
+	var out []config.CustomTaskConfig
+	for _, t := range only {
+		ct, ok := byType[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown custom task: %s", t)
+		}
+		out = append(out, ct)
+	}
+	return out, nil
+}
+
+// groomBudgetPasses returns how many grooming passes fit in the provider's
+// remaining budget, charging each the task-groomer max estimate.
+func groomBudgetPasses(cfg *config.Config, provider string) (int, error) {
+	database, err := db.Open(cfg.ExpandedDBPath())
+	if err != nil {
+		return 0, fmt.Errorf("open db: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	allowance, err := newTaskBudgetManager(cfg, database).CalculateAllowance(strings.ToLower(provider))
+	if err != nil {
+		return 0, fmt.Errorf("budget %s: %w", provider, err)
+	}
+	if allowance.Allowance <= 0 {
+		return 0, fmt.Errorf("budget exhausted for %s (%.1f%% used)", provider, allowance.UsedPercent)
+	}
+	fmt.Printf("Budget:   %s tokens available\n", formatK(int(allowance.Allowance)))
+
+	def, err := tasks.GetDefinition(tasks.TaskGroomer)
+	if err != nil {
+		return 0, err
+	}
+	_, maxTok := def.EstimatedTokens()
+	passes := int(allowance.Allowance / int64(maxTok))
+	if passes == 0 {
+		return 0, fmt.Errorf("budget for %s (%s tokens) is below one grooming pass (%s)", provider, formatK(int(allowance.Allowance)), formatK(maxTok))
+	}
+	return passes, nil
+}
+
+// groomPrompt asks the agent to rewrite one custom task's description after
+// looking at the project it runs against.
+func groomPrompt(ct config.CustomTaskConfig, projectPath string) string {
+	var b strings.Builder
+	b.WriteString("You are grooming a Nightshift custom task definition. Nightshift sends the task's description to a coding agent as its prompt during unattended overnight runs.\n\n")
+	fmt.Fprintf(&b, "Project: %s\n", projectPath)
+	fmt.Fprintf(&b, "Task type: %s\n", ct.Type)
+	fmt.Fprintf(&b, "Task name: %s\n", ct.Name)
+	if ct.Category != "" {
+		fmt.Fprintf(&b, "Category: %s\n", ct.Category)
+	}
+	b.WriteString("\nCurrent description:\n")
+	b.WriteString(ct.Description)
+	if !strings.HasSuffix(ct.Description, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(`
+Read the project to check the description against it: stale paths, commands, or tool names; vague goals; missing scope limits or success criteria.
+Do not modify any files, create branches, or run commands that change state.
+
+Reply with:
+1. A rewritten description, ready to paste into tasks.custom, inside a single fenced code block.
+2. A short bullet list explaining each change.
+If the description is already clear and accurate, say so and repeat it unchanged.
+`)
+	return b.String()
+}
+
+// groomTask runs one grooming pass and returns the agent's reply.
+func groomTask(ctx context.Context, agent agents.Agent, opts agents.ExecuteOptions) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	res, err := agent.Execute(ctx, opts)
+	switch {
+	case err != nil && res != nil && strings.TrimSpace(res.Error) != "":
+		return "", fmt.Errorf("%s", firstLine(res.Error))
+	case err != nil:
+		return "", err
+	case !res.IsSuccess():
+		return "", fmt.Errorf("exit %d: %s", res.ExitCode, firstLine(res.Error))
+	}
+	return strings.TrimSpace(res.Output), nil
+}
+
+// writeGroomSuggestions writes the suggestions file, creating its directory.
+func writeGroomSuggestions(path, projectPath string, suggestions []groomSuggestion, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create suggestions file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	renderGroomSuggestions(f, projectPath, suggestions, now)
+	return f.Close()
+}
+
+// renderGroomSuggestions formats the suggestions as markdown for review.
+func renderGroomSuggestions(w io.Writer, projectPath string, suggestions []groomSuggestion, now time.Time) {
+	_, _ = fmt.Fprintf(w, "# Custom task grooming suggestions\n\n")
+	_, _ = fmt.Fprintf(w, "Project: %s\n", projectPath)
+	_, _ = fmt.Fprintf(w, "Generated: %s\n\n", now.Format(time.RFC3339))
+	_, _ = fmt.Fprintln(w, "Suggestions only: tasks.custom was not changed. Copy any rewrite you want into your config.")
+	for _, s := range suggestions {
+		_, _ = fmt.Fprintf(w, "\n## %s (`%s`)\n\n", s.task.Name, s.task.Type)
+		_, _ = fmt.Fprintln(w, "### Current")
+		_, _ = fmt.Fprintln(w)
+		for _, line := range strings.Split(strings.TrimRight(s.task.Description, "\n"), "\n") {
+			_, _ = fmt.Fprintf(w, "> %s\n", line)
+		}
+		_, _ = fmt.Fprintln(w)
+		_, _ = fmt.Fprintln(w, "### Suggested")
+		_, _ = fmt.Fprintln(w)
+		if s.err != nil {
+			_, _ = fmt.Fprintf(w, "_Grooming failed: %v_\n", s.err)
+			continue
+		}
+		_, _ = fmt.Fprintln(w, s.suggestion)
+	}
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/config"
+)
+
+// groomAgent echoes a canned reply and records the prompts it was sent.
+type groomAgent struct {
+	reply   string
+	prompts []string
+}
+
+func (a *groomAgent) Name() string { return "claude" }
+
+func (a *groomAgent) Execute(_ context.Context, opts agents.ExecuteOptions) (*agents.ExecuteResult, error) {
+	a.prompts = append(a.prompts, opts.Prompt)
+	return &agents.ExecuteResult{Output: a.reply}, nil
+}
+
+func TestSelectCustomTasks(t *testing.T) {
+	custom := []config.CustomTaskConfig{
+		{Type: "api-docs", Name: "API Docs", Description: "Update docs"},
+		{Type: "dep-audit", Name: "Dep Audit", Description: "Audit deps"},
+	}
+	tests := []struct {
+		name    string
+		custom  []config.CustomTaskConfig
+		only    []string
+		want    string
+		wantErr string
+	}{
+		{name: "all", custom: custom, want: "api-docs,dep-audit"},
+		{name: "filtered", custom: custom, only: []string{"dep-audit"}, want: "dep-audit"},
+		{name: "unknown", custom: custom, only: []string{"lint-fix"}, wantErr: "unknown custom task: lint-fix"},
+		{name: "none configured", wantErr: "no custom tasks configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectCustomTasks(tt.custom, tt.only)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var types []string
+			for _, ct := range got {
+				types = append(types, ct.Type)
+			}
+			if strings.Join(types, ",") != tt.want {
+				t.Errorf("types = %v, want %s", types, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroomTaskAndSuggestionsFile(t *testing.T) {
+	ct := config.CustomTaskConfig{Type: "api-docs", Name: "API Docs", Description: "Update docs in docs/api\nfor new endpoints"}
+	agent := &groomAgent{reply: "```\nUpdate docs/reference/api.md for endpoints added since the last tag.\n```\n- docs/api was moved\n"}
+
+	prompt := groomPrompt(ct, "/code/app")
+	reply, err := groomTask(context.Background(), agent, agents.ExecuteOptions{Prompt: prompt, WorkDir: "/code/app", Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Task type: api-docs", "Update docs in docs/api\nfor new endpoints", "Do not modify any files"} {
+		if !strings.Contains(agent.prompts[0], want) {
+			t.Errorf("prompt missing %q:\n%s", want, agent.prompts[0])
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), ".nightshift-plan", "task-groom.md")
+	suggestions := []groomSuggestion{
+		{task: ct, suggestion: reply},
+		{task: config.CustomTaskConfig{Type: "dep-audit", Name: "Dep Audit", Description: "Audit deps"}, err: context.DeadlineExceeded},
+	}
+	if err := writeGroomSuggestions(path, "/code/app", suggestions, time.Date(2026, 3, 4, 2, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"## API Docs (`api-docs`)",
+		"> Update docs in docs/api\n> for new endpoints",
+		"Update docs/reference/api.md",
+		"## Dep Audit (`dep-audit`)",
+		"_Grooming failed: context deadline exceeded_",
+		"tasks.custom was not changed",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("suggestions file missing %q:\n%s", want, got)
+		}
+	}
+
+	var buf bytes.Buffer
+	renderGroomSuggestions(&buf, "/code/app", nil, time.Now())
+	if !strings.Contains(buf.String(), "Project: /code/app") {
+		t.Errorf("empty render:\n%s", buf.String())
+	}
+}
@@ -1,8 +1,16 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/reporting"
 	"github.com/marcus/nightshift/internal/tasks"
 )
 
@@ -170,3 +178,228 @@ func TestAgentByNameUnknown(t *testing.T) {
 		t.Fatal("expected error for unknown provider")
 	}
 }
+
+func TestResolveTaskRunDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		prompt   string
+		wantType tasks.TaskType
+		wantErr  bool
+	}{
+		{"registered", []string{"lint-fix"}, "", tasks.TaskLintFix, false},
+		{"ad-hoc", nil, "update the CHANGELOG", tasks.TaskAdHoc, false},
+		{"both", []string{"lint-fix"}, "update the CHANGELOG", "", true},
+		{"neither", nil, "  ", "", true},
+		{"unknown", []string{"nope"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := resolveTaskRunDefinition(tt.args, tt.prompt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && def.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", def.Type, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestAdHocTaskResult(t *testing.T) {
+	def := tasks.AdHocDefinition("update the CHANGELOG")
+	_, maxTok := def.EstimatedTokens()
+
+	done := taskRunResult(def, "/p", &orchestrator.TaskResult{
+		Status:    orchestrator.StatusCompleted,
+		OutputRef: "https://example.com/pr/1",
+		Duration:  time.Minute,
+	}, nil, 0)
+	if done.TaskType != "ad-hoc" || done.Status != "completed" || done.TokensUsed != maxTok || done.OutputRef == "" {
+		t.Errorf("completed result = %+v", done)
+	}
+
+	failed := taskRunResult(def, "/p", &orchestrator.TaskResult{Status: orchestrator.StatusFailed}, errors.New("boom"), 0)
+	if failed.Status != "failed" || failed.SkipReason != "boom" || failed.TokensUsed != 0 {
+		t.Errorf("failed result = %+v", failed)
+	}
+
+	abandoned := taskRunResult(def, "/p", &orchestrator.TaskResult{Status: orchestrator.StatusAbandoned, Error: "gave up"}, nil, 0)
+	if abandoned.Status != "failed" || abandoned.SkipReason != "gave up" {
+		t.Errorf("abandoned result = %+v", abandoned)
+	}
+
+	partial := taskRunResult(def, "/p", &orchestrator.TaskResult{Status: orchestrator.StatusPartial, OutputRef: "https://example.com/pr/2", Error: "max iterations"}, nil, 0)
+	if partial.Status != "partial" || partial.OutputRef == "" || partial.SkipReason != "max iterations" || partial.TokensUsed != maxTok {
+		t.Errorf("partial result = %+v", partial)
+	}
+
+	capped := taskRunResult(def, "/p", &orchestrator.TaskResult{Status: orchestrator.StatusCompleted}, nil, 20_000)
+	if capped.TokensUsed != 20_000 {
+		t.Errorf("capped result tokens = %d, want --max-tokens-per-task 20000", capped.TokensUsed)
+	}
+}
+
+func TestParseRiskFilter(t *testing.T) {
+	tests := []struct {
+		input string
+		want  tasks.RiskLevel
+		err   bool
+	}{
+		{"low", tasks.RiskLow, false},
+		{"Medium", tasks.RiskMedium, false},
+		{"HIGH", tasks.RiskHigh, false},
+		{"severe", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRiskFilter(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseRiskFilter(%q): want error, got nil", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRiskFilter(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRiskFilter(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	low := filterByRisk(tasks.AllDefinitionsSorted(), tasks.RiskLow)
+	if len(low) == 0 {
+		t.Fatal("expected low-risk tasks, got none")
+	}
+	for _, d := range low {
+		if d.RiskLevel != tasks.RiskLow {
+			t.Errorf("got risk %v, want Low", d.RiskLevel)
+		}
+	}
+}
+
+func TestFormatTaskInterval(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "none"},
+		{24 * time.Hour, "1d"},
+		{168 * time.Hour, "7d"},
+		{12 * time.Hour, "12h"},
+		{90 * time.Minute, "1h30m0s"},
+	}
+	for _, tt := range tests {
+		if got := formatTaskInterval(tt.in); got != tt.want {
+			t.Errorf("formatTaskInterval(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDumpPrompt(t *testing.T) {
+	for _, mode := range []string{"", "exit", "continue"} {
+		if err := validateDumpPrompt(mode); err != nil {
+			t.Errorf("validateDumpPrompt(%q) = %v, want nil", mode, err)
+		}
+	}
+	if err := validateDumpPrompt("pause"); err == nil {
+		t.Error("validateDumpPrompt(\"pause\") = nil, want error")
+	}
+}
+
+func TestAgentCommandLine(t *testing.T) {
+	agent := agents.NewClaudeAgent(agents.WithBinaryPath("claude"))
+	got := agentCommandLine(agent, agents.ExecuteOptions{Prompt: "don't panic", Model: "haiku"})
+	want := `claude --print --dangerously-skip-permissions --model haiku 'don'\''t panic'`
+	if got != want {
+		t.Errorf("agentCommandLine() = %s, want %s", got, want)
+	}
+
+	var b strings.Builder
+	printPromptDump(&b, "plan it", got, "/work/app")
+	for _, part := range []string{"--- Prompt ---\nplan it\n", "--- Command ---\n" + want + "\n", "(in /work/app)"} {
+		if !strings.Contains(b.String(), part) {
+			t.Errorf("printPromptDump() missing %q in:\n%s", part, b.String())
+		}
+	}
+}
+
+func TestBuildTaskEstimate(t *testing.T) {
+	def, err := tasks.GetDefinition(tasks.TaskLintFix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	run := func(offset int, results ...reporting.TaskResult) reportRun {
+		return reportRun{results: &reporting.RunResults{StartTime: day.AddDate(0, 0, offset), Tasks: results}}
+	}
+	lint := func(project string, tokens int, status string) reporting.TaskResult {
+		return reporting.TaskResult{Project: project, TaskType: string(tasks.TaskLintFix), Status: status, TokensUsed: tokens}
+	}
+	runs := []reportRun{
+		run(0, lint("/code/app", 20_000, "completed"), lint("/code/other", 90_000, "completed")),
+		run(1, lint("/code/app/", 30_000, "completed"), lint("/code/app", 0, "skipped")),
+		run(2, lint("/code/app", 40_000, "failed")),
+		run(3, lint("/code/app", 60_000, "completed"), reporting.TaskResult{Project: "/code/app", TaskType: "docs-backfill", Status: "completed", TokensUsed: 1}),
+		{results: nil},
+	}
+
+	est := buildTaskEstimate(def, "/code/app", runs)
+	if est.MinTokens != 10_000 || est.MaxTokens != 50_000 {
+		t.Errorf("range = %d-%d, want 10000-50000", est.MinTokens, est.MaxTokens)
+	}
+	if est.History == nil {
+		t.Fatal("History = nil, want observed runs")
+	}
+	want := taskEstimateHistory{Runs: 4, MedianTokens: 30_000, P90Tokens: 60_000, LastRun: day.AddDate(0, 0, 3)}
+	if *est.History != want {
+		t.Errorf("History = %+v, want %+v", *est.History, want)
+	}
+
+	var buf bytes.Buffer
+	printTaskEstimate(&buf, est)
+	for _, s := range []string{"Median", "30k", "P90", "60k", "4 runs", "exceeds the cost tier"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("printTaskEstimate() missing %q\n%s", s, buf.String())
+		}
+	}
+
+	fresh := buildTaskEstimate(def, "/code/new", runs)
+	if fresh.History != nil {
+		t.Errorf("History = %+v, want nil without past runs", fresh.History)
+	}
+	buf.Reset()
+	if err := printTaskEstimateJSON(&buf, fresh); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded["history"]; ok {
+		t.Errorf("JSON includes history without past runs: %s", buf.String())
+	}
+}
+
+func TestPercentileInt(t *testing.T) {
+	sorted := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want int
+	}{
+		{0.5, 5},
+		{0.9, 9},
+		{1, 10},
+		{0.01, 1},
+	}
+	for _, tt := range tests {
+		if got := percentileInt(sorted, tt.p); got != tt.want {
+			t.Errorf("percentileInt(%v) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := percentileInt(nil, 0.5); got != 0 {
+		t.Errorf("percentileInt(nil) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// tokenMeters maps provider name to its token counter. Providers that can't
+// report tokens (copilot counts requests) are absent.
+type tokenMeters map[string]orchestrator.TokenMeter
+
+// newTokenMeters builds counters for the providers that expose today's
+// token usage. Claude is read from session JSONL rather than stats-cache,
+// which is only refreshed periodically.
+func newTokenMeters(claude *providers.Claude, codex *providers.Codex) tokenMeters {
+	m := tokenMeters{}
+	if claude != nil {
+		m["claude"] = claude.ScanTodayTokens
+	}
+	if codex != nil {
+		m["codex"] = codex.GetTodayTokens
+	}
+	return m
+}
+
+// taskTokenCap returns the token ceiling for a task: tasks.token_caps when
+// set, otherwise the top of the task's cost tier. A positive override
+// (--max-tokens-per-task) lowers it, and applies even when the configured
+// cap is disabled.
+func taskTokenCap(cfg *config.Config, def tasks.TaskDefinition, override int64) int64 {
+	limit, ok := cfg.GetTaskTokenCap(string(def.Type))
+	if !ok {
+		_, limit = def.EstimatedTokens()
+	}
+	if override > 0 && (limit <= 0 || override < int64(limit)) {
+		return override
+	}
+	return int64(limit)
+}
+
+// maxTokensNote explains how --max-tokens-per-task applies to the plan: the
+// reservation always, the runtime cap only for providers with a token meter.
+func (p *preflightPlan) maxTokensNote() string {
+	if len(p.unmetered) == 0 {
+		return "reserved and enforced per task"
+	}
+	return "reserved per task; not enforced for " + strings.Join(p.unmetered, ", ") + ", which can't count tokens"
+}
+
+// tokenSample is a provider's counter reading taken before a task.
+type tokenSample struct {
+	meter orchestrator.TokenMeter
+	start int64
+	ok    bool
+}
+
+// start reads provider's counter before a task runs.
+func (m tokenMeters) start(provider string) tokenSample {
+	meter := m[provider]
+	if meter == nil {
+		return tokenSample{}
+	}
+	start, err := meter()
+	return tokenSample{meter: meter, start: start, ok: err == nil}
+}
+
+// used returns the tokens consumed since the sample was taken. It falls back
+// to estimate, with measured false, when either reading fails or the counter
+// went backwards (e.g. the day rolled over mid-task).
+func (s tokenSample) used(estimate int) (tokens int, measured bool) {
+	if !s.ok {
+		return estimate, false
+	}
+	end, err := s.meter()
+	if err != nil || end < s.start {
+		return estimate, false
+	}
+	return int(end - s.start), true
+}
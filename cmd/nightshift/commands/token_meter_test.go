@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/orchestrator"
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// fakeMeter returns successive readings, failing where errs[i] is true.
+func fakeMeter(readings []int64, errs []bool) orchestrator.TokenMeter {
+	i := 0
+	return func() (int64, error) {
+		n := i
+		i++
+		if n < len(errs) && errs[n] {
+			return 0, errors.New("unavailable")
+		}
+		return readings[n], nil
+	}
+}
+
+func TestTokenSampleUsed(t *testing.T) {
+	tests := []struct {
+		name         string
+		meters       tokenMeters
+		provider     string
+		estimate     int
+		wantTokens   int
+		wantMeasured bool
+	}{
+		{
+			name:         "measured delta",
+			meters:       tokenMeters{"claude": fakeMeter([]int64{1000, 4500}, nil)},
+			provider:     "claude",
+			estimate:     50000,
+			wantTokens:   3500,
+			wantMeasured: true,
+		},
+		{
+			name:         "start reading fails",
+			meters:       tokenMeters{"codex": fakeMeter([]int64{0, 4500}, []bool{true})},
+			provider:     "codex",
+			estimate:     50000,
+			wantTokens:   50000,
+			wantMeasured: false,
+		},
+		{
+			name:         "end reading fails",
+			meters:       tokenMeters{"codex": fakeMeter([]int64{1000, 0}, []bool{false, true})},
+			provider:     "codex",
+			estimate:     50000,
+			wantTokens:   50000,
+			wantMeasured: false,
+		},
+		{
+			name:         "counter went backwards",
+			meters:       tokenMeters{"claude": fakeMeter([]int64{9000, 200}, nil)},
+			provider:     "claude",
+			estimate:     50000,
+			wantTokens:   50000,
+			wantMeasured: false,
+		},
+		{
+			name:         "provider without meter",
+			meters:       tokenMeters{"claude": fakeMeter([]int64{0, 0}, nil)},
+			provider:     "copilot",
+			estimate:     50000,
+			wantTokens:   50000,
+			wantMeasured: false,
+		},
+		{
+			name:         "failed task falls back to zero",
+			meters:       tokenMeters{},
+			provider:     "claude",
+			estimate:     0,
+			wantTokens:   0,
+			wantMeasured: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, measured := tt.meters.start(tt.provider).used(tt.estimate)
+			if got != tt.wantTokens || measured != tt.wantMeasured {
+				t.Errorf("used() = (%d, %t), want (%d, %t)", got, measured, tt.wantTokens, tt.wantMeasured)
+			}
+		})
+	}
+}
+
+func TestTaskTokenCap(t *testing.T) {
+	def := tasks.TaskDefinition{Type: tasks.TaskBugFinder, CostTier: tasks.CostHigh} // 150-500k
+	tests := []struct {
+		name     string
+		caps     map[string]int
+		override int64
+		want     int64
+	}{
+		{name: "tier max", want: 500_000},
+		{name: "configured cap", caps: map[string]int{"bug-finder": 300_000}, want: 300_000},
+		{name: "override below tier", override: 100_000, want: 100_000},
+		{name: "override above configured cap", caps: map[string]int{"bug-finder": 50_000}, override: 100_000, want: 50_000},
+		{name: "override with cap disabled", caps: map[string]int{"bug-finder": 0}, override: 100_000, want: 100_000},
+		{name: "cap disabled", caps: map[string]int{"bug-finder": 0}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Tasks: config.TasksConfig{TokenCaps: tt.caps}}
+			if got := taskTokenCap(cfg, def, tt.override); got != tt.want {
+				t.Errorf("taskTokenCap() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTokenMeters_Accounting(t *testing.T) {
+	dataPath := t.TempDir()
+	projDir := filepath.Join(dataPath, "projects", "myproj")
+	if err := os.MkdirAll(projDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Local()
+	morning := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 1, 0, now.Location())
+	line := `{"type":"assistant","message":{"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":1000,"cache_creation_input_tokens":200}},"timestamp":"` +
+		morning.Format(time.RFC3339) + `"}` + "\n"
+	if err := os.WriteFile(filepath.Join(projDir, "s1.jsonl"), []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		accounting string
+		want       int64
+	}{
+		{config.DefaultTokenAccounting, 150}, // input + output
+		{"raw", 1350},                        // + cache reads and writes
+	}
+	for _, tt := range tests {
+		t.Run(tt.accounting, func(t *testing.T) {
+			cfg := &config.Config{
+				Budget:    config.BudgetConfig{TokenAccounting: tt.accounting},
+				Providers: config.ProvidersConfig{Claude: config.ProviderConfig{DataPath: dataPath}},
+			}
+			got, err := newTokenMeters(newClaudeProvider(cfg), nil)["claude"]()
+			if err != nil {
+				t.Fatalf("claude meter: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("claude meter = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPreflight_MaxTokensPerTask(t *testing.T) {
+	params := newPreflightParams(t, []string{t.TempDir()})
+	params.taskFilters = []string{"bug-finder", "lint-fix"}
+
+	// Uncapped, bug-finder's 500k reservation leaves no room for lint-fix.
+	plan, err := buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if got := len(plan.projects[0].tasks); got != 1 {
+		t.Fatalf("uncapped: planned %d tasks, want 1", got)
+	}
+
+	params.maxTokensPerTask = 30_000
+	params.selector.SetMaxTokensPerTask(params.maxTokensPerTask)
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if got := len(plan.projects[0].tasks); got != 2 {
+		t.Errorf("capped at 30k: planned %d tasks, want both", got)
+	}
+	var buf bytes.Buffer
+	displayPreflight(&buf, plan)
+	if !strings.Contains(buf.String(), "Max tokens/task: 30k (reserved per task; not enforced for claude") {
+		t.Errorf("preflight missing cap line:\n%s", buf.String())
+	}
+
+	params.meters = tokenMeters{}
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		params.meters[name] = fakeMeter([]int64{0}, nil)
+	}
+	plan, err = buildPreflight(params)
+	if err != nil {
+		t.Fatalf("buildPreflight: %v", err)
+	}
+	if note := plan.maxTokensNote(); note != "reserved and enforced per task" {
+		t.Errorf("maxTokensNote() with meters = %q", note)
+	}
+}
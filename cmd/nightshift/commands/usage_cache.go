@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/budget"
+	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/providers"
+	"github.com/spf13/cobra"
+)
+
+// freshUsage is set by --fresh to skip the provider usage cache.
+var freshUsage bool
+
+// usageCachePath is where interactive commands cache provider usage values.
+// Override in tests.
+var usageCachePath = func() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "nightshift", "usage.json")
+}
+
+// addFreshFlag registers --fresh on an interactive command.
+func addFreshFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&freshUsage, "fresh", false, "Re-scan provider usage instead of reusing values cached by a recent command")
+}
+
+// newUsageCache returns the cross-command usage cache, or nil when --fresh
+// is set or providers.status_cache_ttl is 0. The daemon doesn't use it, so
+// scheduled runs always see live usage.
+func newUsageCache(cfg *config.Config) *budget.UsageCache {
+	ttl := cfg.GetStatusCacheTTL()
+	path := usageCachePath()
+	if freshUsage || ttl <= 0 || path == "" {
+		return nil
+	}
+	return budget.NewUsageCache(path, ttl, func(provider string) string {
+		root := cfg.ExpandedProviderPath(provider)
+		return root + "@" + usageModTime(cfg, provider, root, time.Now()).UTC().Format(time.RFC3339Nano)
+	})
+}
+
+// usageModTime returns the newest modification time of the files provider's
+// usage is read from, so new agent activity invalidates cached usage. Only
+// those files are checked, not the whole data dir:
+//   - claude: stats-cache.json and the project dirs (a new session adds a
+//     file); with raw token accounting, the session files instead.
+//   - codex: the session files of the last 7 days.
+//   - copilot: the request tracking file.
+func usageModTime(cfg *config.Config, provider, root string, now time.Time) time.Time {
+	var latest time.Time
+	if root == "" {
+		return latest
+	}
+	newer := func(path string) {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	switch provider {
+	case "claude":
+		newer(filepath.Join(root, "stats-cache.json"))
+		projectsDir := filepath.Join(root, "projects")
+		if strings.EqualFold(cfg.Budget.TokenAccounting, string(providers.AccountingRaw)) {
+			// Raw totals are scanned from every session file
+			_ = filepath.WalkDir(projectsDir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
+					newer(path)
+				}
+				return nil
+			})
+			break
+		}
+		newer(projectsDir)
+		dirs, _ := os.ReadDir(projectsDir)
+		for _, d := range dirs {
+			if d.IsDir() {
+				newer(filepath.Join(projectsDir, d.Name()))
+			}
+		}
+	case "codex":
+		for i := 0; i < 7; i++ {
+			day := now.AddDate(0, 0, -i)
+			dir := filepath.Join(root, "sessions", day.Format("2006"), day.Format("01"), day.Format("02"))
+			newer(dir)
+			for _, path := range filesWithSuffix(dir, ".jsonl") {
+				newer(path)
+			}
+		}
+	case "copilot":
+		newer(filepath.Join(root, "nightshift-usage.json"))
+	}
+	return latest
+}
+
+// filesWithSuffix lists the files directly in dir whose names end in suffix.
+func filesWithSuffix(dir, suffix string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), suffix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files
+}
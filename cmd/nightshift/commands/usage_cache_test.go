@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestUsageModTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	base := now.Add(-48 * time.Hour)
+	write := func(path string, mtime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch := func(path string, mtime time.Time) {
+		t.Helper()
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	claude := t.TempDir()
+	write(filepath.Join(claude, "stats-cache.json"), base)
+	write(filepath.Join(claude, "projects", "app", "s1.jsonl"), base)
+	write(filepath.Join(claude, "todos", "unrelated.json"), now) // not read for usage
+	touch(filepath.Join(claude, "projects", "app"), base)
+	touch(filepath.Join(claude, "projects"), base)
+	write(filepath.Join(claude, "projects", "app", "s1.jsonl"), base.Add(time.Hour)) // append to a session
+
+	cfg := &config.Config{}
+	if got := usageModTime(cfg, "claude", claude, now); !got.Equal(base) {
+		t.Errorf("claude billable = %s, want stats-cache and dir mtimes only (%s)", got, base)
+	}
+	cfg.Budget.TokenAccounting = "raw"
+	if got := usageModTime(cfg, "claude", claude, now); !got.Equal(base.Add(time.Hour)) {
+		t.Errorf("claude raw = %s, want the session file's mtime", got)
+	}
+
+	codex := t.TempDir()
+	write(filepath.Join(codex, "sessions", "2026", "03", "09", "r.jsonl"), base.Add(2*time.Hour))
+	write(filepath.Join(codex, "sessions", "2026", "02", "01", "old.jsonl"), now) // outside the 7 days
+	touch(filepath.Join(codex, "sessions", "2026", "03", "09"), base)
+	if got := usageModTime(cfg, "codex", codex, now); !got.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("codex = %s, want the recent session's mtime", got)
+	}
+
+	if got := usageModTime(cfg, "copilot", t.TempDir(), now); !got.IsZero() {
+		t.Errorf("copilot without a tracking file = %s, want zero", got)
+	}
+}
@@ -4,12 +4,25 @@ package agents
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 )
 
 // DefaultTimeout is the default agent execution timeout (30 minutes).
 const DefaultTimeout = 30 * time.Minute
 
+// Originator tags sessions started by nightshift's agents so usage tools
+// can tell them apart from interactive sessions.
+const Originator = "nightshift"
+
+// originatorEnv is added to every agent process. Codex records
+// CODEX_INTERNAL_ORIGINATOR_OVERRIDE as the session originator.
+var originatorEnv = []string{
+	"NIGHTSHIFT_ORIGINATOR=" + Originator,
+	"CODEX_INTERNAL_ORIGINATOR_OVERRIDE=" + Originator,
+}
+
 // Agent is the interface for AI agent execution.
 type Agent interface {
 	// Name returns the agent identifier.
@@ -19,12 +32,23 @@ type Agent interface {
 	Execute(ctx context.Context, opts ExecuteOptions) (*ExecuteResult, error)
 }
 
+// CommandBuilder is implemented by agents that can report the command line
+// Execute would run for a set of options without running it.
+type CommandBuilder interface {
+	Command(opts ExecuteOptions) (name string, args []string)
+}
+
 // ExecuteOptions configures an agent execution.
 type ExecuteOptions struct {
 	Prompt  string        // The prompt/task for the agent
 	WorkDir string        // Working directory for execution
 	Files   []string      // Optional file paths to include as context
 	Timeout time.Duration // Execution timeout (0 = default)
+	Model   string        // Model override passed via --model (empty = CLI default)
+
+	// DenyPaths are globs the agent must not modify. Agents whose CLI has a
+	// deny list pass them through; the orchestrator checks the rest.
+	DenyPaths []string
 }
 
 // ExecuteResult holds the outcome of an agent execution.
@@ -40,3 +64,30 @@ type ExecuteResult struct {
 func (r *ExecuteResult) IsSuccess() bool {
 	return r.ExitCode == 0 && r.Error == ""
 }
+
+// applyEnv hands env overrides to the exec or docker runner. Custom runners
+// (tests) are left alone.
+func applyEnv(r CommandRunner, env []string) {
+	if len(env) == 0 {
+		return
+	}
+	switch r := r.(type) {
+	case *ExecRunner:
+		r.Env = env
+	case *DockerRunner:
+		r.Env = env
+	}
+}
+
+// collidingArgs returns the extra args that re-specify a managed flag,
+// matching both "--flag" and "--flag=value" forms.
+func collidingArgs(extra, managed []string) []string {
+	var collisions []string
+	for _, arg := range extra {
+		name, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(managed, name) {
+			collisions = append(collisions, arg)
+		}
+	}
+	return collisions
+}
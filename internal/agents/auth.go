@@ -0,0 +1,188 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthState is the outcome of a provider login probe.
+type AuthState string
+
+const (
+	AuthUnknown AuthState = "unknown" // probe couldn't tell; assume usable
+	AuthOK      AuthState = "ok"
+	AuthExpired AuthState = "expired"
+)
+
+// AuthStatus reports whether an agent's CLI is logged in.
+type AuthStatus struct {
+	State AuthState
+	// Detail explains the state for doctor output.
+	Detail string
+	// LoginHint is the command that restores access, e.g. "claude login".
+	LoginHint string
+}
+
+// Expired reports whether the probe positively found the login unusable.
+func (s AuthStatus) Expired() bool {
+	return s.State == AuthExpired
+}
+
+// Reason returns the human-readable skip reason for an expired login.
+func (s AuthStatus) Reason() string {
+	if s.LoginHint == "" {
+		return "auth expired"
+	}
+	return "auth expired — run `" + s.LoginHint + "`"
+}
+
+// AuthChecker is implemented by agents that can cheaply tell whether their
+// CLI is logged in without running a task. Probes are best-effort: anything
+// they can't determine is reported as AuthUnknown rather than expired.
+type AuthChecker interface {
+	CheckAuth(ctx context.Context) AuthStatus
+}
+
+// AuthProbeTimeout bounds a single auth probe.
+const AuthProbeTimeout = 10 * time.Second
+
+// authCacheTTL is how long a probe result is reused, so the daemon doesn't
+// re-probe every provider for every project.
+const authCacheTTL = 10 * time.Minute
+
+type authCacheEntry struct {
+	status  AuthStatus
+	checked time.Time
+}
+
+var (
+	authCacheMu sync.Mutex
+	authCache   = map[string]authCacheEntry{}
+)
+
+// CheckAuth probes a's login state, reusing a recent result for the same
+// agent name. Agents that don't implement AuthChecker report AuthUnknown.
+func CheckAuth(ctx context.Context, a Agent) AuthStatus {
+	checker, ok := a.(AuthChecker)
+	if !ok {
+		return AuthStatus{State: AuthUnknown, Detail: "no auth probe"}
+	}
+
+	authCacheMu.Lock()
+	entry, hit := authCache[a.Name()]
+	authCacheMu.Unlock()
+	if hit && time.Since(entry.checked) < authCacheTTL {
+		return entry.status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, AuthProbeTimeout)
+	defer cancel()
+	status := checker.CheckAuth(ctx)
+
+	authCacheMu.Lock()
+	authCache[a.Name()] = authCacheEntry{status: status, checked: time.Now()}
+	authCacheMu.Unlock()
+	return status
+}
+
+// ResetAuthCache drops cached probe results.
+func ResetAuthCache() {
+	authCacheMu.Lock()
+	authCache = map[string]authCacheEntry{}
+	authCacheMu.Unlock()
+}
+
+// claudeCredentials is the subset of Claude Code's .credentials.json we read.
+type claudeCredentials struct {
+	OAuth *struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+		ExpiresAt    int64  `json:"expiresAt"` // unix milliseconds
+	} `json:"claudeAiOauth"`
+}
+
+// claudeCredentialsPath returns the credential file Claude Code writes on
+// Linux. macOS keeps credentials in the keychain, so the file may not exist.
+func claudeCredentialsPath() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude", ".credentials.json")
+}
+
+// checkClaudeCredentials inspects a Claude credential file. An expired access
+// token alone isn't fatal because the CLI refreshes it; the login is only
+// expired when there is no refresh token left to use.
+func checkClaudeCredentials(path string, now time.Time) AuthStatus {
+	status := AuthStatus{State: AuthUnknown, LoginHint: "claude login"}
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		status.State = AuthOK
+		status.Detail = "ANTHROPIC_API_KEY set"
+		return status
+	}
+	if path == "" {
+		status.Detail = "no credentials path"
+		return status
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		status.Detail = "no credentials file (may be in keychain)"
+		return status
+	}
+	var creds claudeCredentials
+	if err := json.Unmarshal(data, &creds); err != nil || creds.OAuth == nil {
+		status.Detail = "unrecognized credentials file"
+		return status
+	}
+	if creds.OAuth.AccessToken == "" && creds.OAuth.RefreshToken == "" {
+		status.State = AuthExpired
+		status.Detail = "not logged in"
+		return status
+	}
+	if creds.OAuth.RefreshToken == "" && creds.OAuth.ExpiresAt > 0 {
+		expires := time.UnixMilli(creds.OAuth.ExpiresAt)
+		if now.After(expires) {
+			status.State = AuthExpired
+			status.Detail = "token expired " + expires.Format("2006-01-02 15:04")
+			return status
+		}
+	}
+	status.State = AuthOK
+	status.Detail = "logged in"
+	return status
+}
+
+// codexAuthStatus interprets `codex login status` output.
+func codexAuthStatus(stdout, stderr string, exitCode int, err error) AuthStatus {
+	status := AuthStatus{State: AuthUnknown, LoginHint: "codex login"}
+	output := strings.TrimSpace(stdout + "\n" + stderr)
+	lower := strings.ToLower(output)
+	switch {
+	case exitCode == 0 && err == nil:
+		status.State = AuthOK
+		status.Detail = firstLine(output)
+		if status.Detail == "" {
+			status.Detail = "logged in"
+		}
+	case strings.Contains(lower, "not logged in") || strings.Contains(lower, "expired"):
+		status.State = AuthExpired
+		status.Detail = firstLine(output)
+	default:
+		status.Detail = "login status unavailable"
+	}
+	return status
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,101 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckClaudeCredentials(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour).UnixMilli()
+	future := now.Add(time.Hour).UnixMilli()
+
+	tests := []struct {
+		name string
+		json string // empty = no file
+		want AuthState
+	}{
+		{name: "no file", want: AuthUnknown},
+		{name: "garbage", json: "{", want: AuthUnknown},
+		{name: "valid token", json: `{"claudeAiOauth":{"accessToken":"a","expiresAt":` + strconv.FormatInt(future, 10) + `}}`, want: AuthOK},
+		{name: "expired with refresh", json: `{"claudeAiOauth":{"accessToken":"a","refreshToken":"r","expiresAt":` + strconv.FormatInt(past, 10) + `}}`, want: AuthOK},
+		{name: "expired no refresh", json: `{"claudeAiOauth":{"accessToken":"a","expiresAt":` + strconv.FormatInt(past, 10) + `}}`, want: AuthExpired},
+		{name: "logged out", json: `{"claudeAiOauth":{}}`, want: AuthExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".credentials.json")
+			if tt.json != "" {
+				if err := os.WriteFile(path, []byte(tt.json), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := checkClaudeCredentials(path, now)
+			if got.State != tt.want {
+				t.Errorf("state = %s (%s), want %s", got.State, got.Detail, tt.want)
+			}
+		})
+	}
+
+	t.Run("api key", func(t *testing.T) {
+		t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+		if got := checkClaudeCredentials("", now); got.State != AuthOK {
+			t.Errorf("state = %s, want ok", got.State)
+		}
+	})
+}
+
+func TestCodexCheckAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner *MockRunner
+		want   AuthState
+	}{
+		{name: "logged in", runner: &MockRunner{Stdout: "Logged in using ChatGPT\n"}, want: AuthOK},
+		{name: "logged out", runner: &MockRunner{Stderr: "Not logged in\n", ExitCode: 1, Err: errors.New("exit status 1")}, want: AuthExpired},
+		{name: "old cli", runner: &MockRunner{Stderr: "error: unrecognized subcommand 'login'\n", ExitCode: 2, Err: errors.New("exit status 2")}, want: AuthUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := NewCodexAgent(WithCodexRunner(tt.runner))
+			got := agent.CheckAuth(context.Background())
+			if got.State != tt.want {
+				t.Errorf("state = %s (%s), want %s", got.State, got.Detail, tt.want)
+			}
+			if tt.runner.CapturedName != "codex" || len(tt.runner.CapturedArgs) != 2 || tt.runner.CapturedArgs[0] != "login" {
+				t.Errorf("ran %s %v, want codex login status", tt.runner.CapturedName, tt.runner.CapturedArgs)
+			}
+		})
+	}
+}
+
+func TestCheckAuthCaches(t *testing.T) {
+	ResetAuthCache()
+	t.Cleanup(ResetAuthCache)
+
+	runner := &MockRunner{Stderr: "Not logged in", ExitCode: 1, Err: errors.New("exit status 1")}
+	agent := NewCodexAgent(WithCodexRunner(runner))
+	first := CheckAuth(context.Background(), agent)
+	if !first.Expired() {
+		t.Fatalf("state = %s, want expired", first.State)
+	}
+	if got, want := first.Reason(), "auth expired — run `codex login`"; got != want {
+		t.Errorf("Reason() = %q, want %q", got, want)
+	}
+
+	// A fresh login isn't seen until the cache entry ages out.
+	runner.Stderr, runner.ExitCode, runner.Err = "", 0, nil
+	if got := CheckAuth(context.Background(), agent); !got.Expired() {
+		t.Errorf("expected cached expired result, got %s", got.State)
+	}
+	ResetAuthCache()
+	if got := CheckAuth(context.Background(), agent); got.State != AuthOK {
+		t.Errorf("after reset state = %s, want ok", got.State)
+	}
+}
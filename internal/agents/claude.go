@@ -9,7 +9,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -19,14 +21,26 @@ type CommandRunner interface {
 }
 
 // ExecRunner is the default CommandRunner using os/exec.
-type ExecRunner struct{}
+type ExecRunner struct {
+	// Env holds KEY=VALUE entries applied over the inherited environment
+	// and the originator tags.
+	Env []string
+}
 
-// Run executes a command and returns output.
+// Run executes a command and returns output. The command gets its own
+// process group, so a terminal Ctrl-C or a service manager's stop signal
+// reaches only nightshift, which can let the task finish; cancelling ctx
+// kills the whole group.
 func (r *ExecRunner) Run(ctx context.Context, name string, args []string, dir string, stdin string) (string, string, int, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
 	if dir != "" {
 		cmd.Dir = dir
 	}
+	cmd.Env = append(append(os.Environ(), originatorEnv...), r.Env...)
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
@@ -49,11 +63,17 @@ func (r *ExecRunner) Run(ctx context.Context, name string, args []string, dir st
 // ClaudeAgent spawns Claude Code CLI for task execution.
 type ClaudeAgent struct {
 	binaryPath string        // Path to claude binary (default: "claude")
+	binaryArgs []string      // Passed to the binary before managed args
 	timeout    time.Duration // Default timeout
 	runner     CommandRunner // Command executor (for testing)
 	skipPerms  bool          // Pass --dangerously-skip-permissions
+	extraArgs  []string      // Appended verbatim after managed args
+	env        []string      // KEY=VALUE overrides for the CLI process
 }
 
+// claudeManagedFlags are the flags Nightshift sets itself.
+var claudeManagedFlags = []string{"--print", "-p", "--dangerously-skip-permissions"}
+
 // ClaudeOption configures a ClaudeAgent.
 type ClaudeOption func(*ClaudeAgent)
 
@@ -64,6 +84,14 @@ func WithBinaryPath(path string) ClaudeOption {
 	}
 }
 
+// WithBinaryArgs sets args passed to the binary before Nightshift's own,
+// for wrapper commands.
+func WithBinaryArgs(args []string) ClaudeOption {
+	return func(a *ClaudeAgent) {
+		a.binaryArgs = args
+	}
+}
+
 // WithDefaultTimeout sets the default execution timeout.
 func WithDefaultTimeout(d time.Duration) ClaudeOption {
 	return func(a *ClaudeAgent) {
@@ -78,6 +106,21 @@ func WithDangerouslySkipPermissions(enabled bool) ClaudeOption {
 	}
 }
 
+// WithExtraArgs sets extra CLI args appended verbatim to every invocation.
+func WithExtraArgs(args []string) ClaudeOption {
+	return func(a *ClaudeAgent) {
+		a.extraArgs = args
+	}
+}
+
+// WithEnv sets KEY=VALUE entries added to the CLI's environment, taking
+// precedence over inherited variables.
+func WithEnv(env []string) ClaudeOption {
+	return func(a *ClaudeAgent) {
+		a.env = env
+	}
+}
+
 // WithRunner sets a custom command runner (for testing).
 func WithRunner(r CommandRunner) ClaudeOption {
 	return func(a *ClaudeAgent) {
@@ -96,6 +139,7 @@ func NewClaudeAgent(opts ...ClaudeOption) *ClaudeAgent {
 	for _, opt := range opts {
 		opt(a)
 	}
+	applyEnv(a.runner, a.env)
 	return a
 }
 
@@ -104,6 +148,30 @@ func (a *ClaudeAgent) Name() string {
 	return "claude"
 }
 
+// Command returns the claude --print command line for opts.
+func (a *ClaudeAgent) Command(opts ExecuteOptions) (string, []string) {
+	args := append(slices.Clone(a.binaryArgs), "--print")
+	if a.skipPerms {
+		args = append(args, "--dangerously-skip-permissions")
+	}
+	if opts.Model != "" {
+		args = append(args, "--model", opts.Model)
+	}
+	if len(opts.DenyPaths) > 0 {
+		// One "=" argument so the variadic flag can't swallow the prompt
+		args = append(args, "--disallowedTools="+claudeDenyRules(opts.DenyPaths))
+	}
+
+	// Add prompt directly as argument
+	if opts.Prompt != "" {
+		args = append(args, opts.Prompt)
+	}
+
+	// Extra args go last so variadic flags (e.g. --add-dir) can't swallow the prompt
+	args = append(args, a.extraArgs...)
+	return a.binaryPath, args
+}
+
 // Execute runs claude --print with the given prompt.
 func (a *ClaudeAgent) Execute(ctx context.Context, opts ExecuteOptions) (*ExecuteResult, error) {
 	start := time.Now()
@@ -118,16 +186,7 @@ func (a *ClaudeAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execut
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Build command args
-	args := []string{"--print"}
-	if a.skipPerms {
-		args = append(args, "--dangerously-skip-permissions")
-	}
-
-	// Add prompt directly as argument
-	if opts.Prompt != "" {
-		args = append(args, opts.Prompt)
-	}
+	name, args := a.Command(opts)
 
 	// Build stdin content from files if provided
 	var stdinContent string
@@ -143,7 +202,7 @@ func (a *ClaudeAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execut
 	}
 
 	// Run command
-	stdout, stderr, exitCode, err := a.runner.Run(ctx, a.binaryPath, args, opts.WorkDir, stdinContent)
+	stdout, stderr, exitCode, err := a.runner.Run(ctx, name, args, opts.WorkDir, stdinContent)
 
 	result := &ExecuteResult{
 		Output:   stdout,
@@ -258,18 +317,48 @@ func (a *ClaudeAgent) extractJSON(output []byte) []byte {
 	return nil
 }
 
+// ExtraArgCollisions returns extra args that re-specify a managed flag.
+func (a *ClaudeAgent) ExtraArgCollisions() []string {
+	return collidingArgs(a.extraArgs, claudeManagedFlags)
+}
+
 // Available checks if the claude binary is available in PATH.
 func (a *ClaudeAgent) Available() bool {
 	_, err := exec.LookPath(a.binaryPath)
 	return err == nil
 }
 
+// CheckAuth reports whether the claude CLI is logged in by reading its
+// credential file.
+func (a *ClaudeAgent) CheckAuth(ctx context.Context) AuthStatus {
+	return checkClaudeCredentials(claudeCredentialsPath(), time.Now())
+}
+
 // Version returns the claude CLI version.
 func (a *ClaudeAgent) Version() (string, error) {
-	cmd := exec.Command(a.binaryPath, "--version")
+	cmd := exec.Command(a.binaryPath, append(slices.Clone(a.binaryArgs), "--version")...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("getting version: %w", err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// claudeDenyRules converts deny_paths globs into Claude permission rules.
+// Edit rules cover every built-in file editing tool. A pattern that may name
+// a directory also gets a "/**" rule for the files inside it.
+func claudeDenyRules(patterns []string) string {
+	rules := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(p), "./"), "/")
+		if p == "" {
+			continue
+		}
+		if !strings.HasSuffix(p, "**") {
+			rules = append(rules, "Edit("+p+")")
+			p += "/**"
+		}
+		rules = append(rules, "Edit("+p+")")
+	}
+	return strings.Join(rules, ",")
+}
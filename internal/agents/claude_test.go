@@ -1,11 +1,17 @@
 package agents
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -119,6 +125,138 @@ func TestClaudeAgent_Execute_Success(t *testing.T) {
 	}
 }
 
+func TestClaudeAgent_Execute_ExtraArgs(t *testing.T) {
+	mock := &MockRunner{}
+	agent := NewClaudeAgent(WithRunner(mock), WithExtraArgs([]string{"--add-dir", "/shared"}))
+
+	if _, err := agent.Execute(context.Background(), ExecuteOptions{Prompt: "fix the bug"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"--print", "--dangerously-skip-permissions", "fix the bug", "--add-dir", "/shared"}
+	if strings.Join(mock.CapturedArgs, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %v, want %v", mock.CapturedArgs, want)
+	}
+}
+
+func TestClaudeAgent_Execute_Model(t *testing.T) {
+	mock := &MockRunner{}
+	agent := NewClaudeAgent(WithRunner(mock))
+
+	if _, err := agent.Execute(context.Background(), ExecuteOptions{Prompt: "fix the bug", Model: "haiku"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"--print", "--dangerously-skip-permissions", "--model", "haiku", "fix the bug"}
+	if strings.Join(mock.CapturedArgs, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %v, want %v", mock.CapturedArgs, want)
+	}
+}
+
+func TestClaudeAgent_Execute_DenyPaths(t *testing.T) {
+	mock := &MockRunner{}
+	agent := NewClaudeAgent(WithRunner(mock))
+
+	opts := ExecuteOptions{Prompt: "fix the bug", DenyPaths: []string{"migrations/", "./vendor/**", "*.lock"}}
+	if _, err := agent.Execute(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"--print", "--dangerously-skip-permissions",
+		"--disallowedTools=Edit(migrations),Edit(migrations/**),Edit(vendor/**),Edit(*.lock),Edit(*.lock/**)",
+		"fix the bug",
+	}
+	if strings.Join(mock.CapturedArgs, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %v, want %v", mock.CapturedArgs, want)
+	}
+}
+
+func TestClaudeAgent_CommandMatchesExecute(t *testing.T) {
+	mock := &MockRunner{}
+	agent := NewClaudeAgent(WithRunner(mock), WithBinaryPath("/opt/bin/claude"), WithExtraArgs([]string{"--verbose"}))
+	opts := ExecuteOptions{Prompt: "fix the bug", Model: "haiku"}
+
+	name, args := agent.Command(opts)
+	if _, err := agent.Execute(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if name != mock.CapturedName {
+		t.Errorf("Command name = %q, Execute ran %q", name, mock.CapturedName)
+	}
+	if strings.Join(args, " ") != strings.Join(mock.CapturedArgs, " ") {
+		t.Errorf("Command args = %v, Execute ran %v", args, mock.CapturedArgs)
+	}
+}
+
+func TestBinaryArgs(t *testing.T) {
+	opts := ExecuteOptions{Prompt: "fix the bug"}
+	tests := []struct {
+		name  string
+		agent interface {
+			Command(ExecuteOptions) (string, []string)
+		}
+		wantName string
+		wantArgs []string
+	}{
+		{
+			"claude wrapper",
+			NewClaudeAgent(WithBinaryPath("/opt/bin/claude-wrapper"), WithBinaryArgs([]string{"--profile", "night"}), WithDangerouslySkipPermissions(false)),
+			"/opt/bin/claude-wrapper",
+			[]string{"--profile", "night", "--print", "fix the bug"},
+		},
+		{
+			"codex wrapper",
+			NewCodexAgent(WithCodexBinaryPath("npx"), WithCodexBinaryArgs([]string{"@openai/codex"}), WithDangerouslyBypassApprovalsAndSandbox(false)),
+			"npx",
+			[]string{"@openai/codex", "exec", "fix the bug"},
+		},
+		{
+			"copilot wrapper runs standalone",
+			NewCopilotAgent(WithCopilotBinaryPath("/opt/bin/copilot-wrapper"), WithCopilotBinaryArgs([]string{"--"})),
+			"/opt/bin/copilot-wrapper",
+			[]string{"--", "-p", "fix the bug", "--no-ask-user", "--allow-all-tools", "--silent"},
+		},
+		{
+			"copilot gh by path",
+			NewCopilotAgent(WithCopilotBinaryPath("/usr/local/bin/gh")),
+			"/usr/local/bin/gh",
+			[]string{"copilot", "suggest", "-t", "shell", "--no-ask-user", "fix the bug"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := tt.agent.Command(opts)
+			if name != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("Command() = %q %v, want %q %v", name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestExtraArgCollisions(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent interface{ ExtraArgCollisions() []string }
+		want  []string
+	}{
+		{"claude none", NewClaudeAgent(WithExtraArgs([]string{"--add-dir", "/x"})), nil},
+		{"claude print", NewClaudeAgent(WithExtraArgs([]string{"--print", "--model=opus"})), []string{"--print"}},
+		{"codex equals form", NewCodexAgent(WithCodexExtraArgs([]string{"--dangerously-bypass-approvals-and-sandbox=false"})), []string{"--dangerously-bypass-approvals-and-sandbox=false"}},
+		{"copilot gh", NewCopilotAgent(WithCopilotExtraArgs([]string{"--no-ask-user", "--silent"})), []string{"--no-ask-user"}},
+		{"copilot standalone", NewCopilotAgent(WithCopilotBinaryPath("copilot"), WithCopilotExtraArgs([]string{"--silent"})), []string{"--silent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.agent.ExtraArgCollisions()
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("ExtraArgCollisions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestClaudeAgent_Execute_JSONOutput(t *testing.T) {
 	mock := &MockRunner{
 		Stdout:   `{"status":"success","files_changed":3}`,
@@ -455,6 +593,31 @@ func TestDefaultTimeout(t *testing.T) {
 	}
 }
 
+func TestExecRunner_Run_SetsOriginator(t *testing.T) {
+	runner := &ExecRunner{}
+
+	stdout, _, _, err := runner.Run(context.Background(), "sh", []string{"-c", "echo $NIGHTSHIFT_ORIGINATOR $CODEX_INTERNAL_ORIGINATOR_OVERRIDE"}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(stdout); got != Originator+" "+Originator {
+		t.Errorf("originator env = %q, want %q", got, Originator+" "+Originator)
+	}
+}
+
+func TestExecRunner_Run_EnvOverrides(t *testing.T) {
+	t.Setenv("NIGHTSHIFT_TEST_ENDPOINT", "inherited")
+	agent := NewClaudeAgent(WithEnv([]string{"NIGHTSHIFT_TEST_ENDPOINT=https://staging.example.com", "NIGHTSHIFT_TEST_EXTRA=a=b"}))
+
+	stdout, _, _, err := agent.runner.Run(context.Background(), "sh", []string{"-c", "echo $NIGHTSHIFT_TEST_ENDPOINT $NIGHTSHIFT_TEST_EXTRA"}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := strings.TrimSpace(stdout), "https://staging.example.com a=b"; got != want {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+}
+
 func TestExecRunner_Run(t *testing.T) {
 	runner := &ExecRunner{}
 
@@ -523,3 +686,85 @@ func TestExecRunner_Run_WithStdin(t *testing.T) {
 		t.Errorf("stdout = %q, want %q", stdout, "hello from stdin")
 	}
 }
+
+// TestExecRunner_Run_OwnProcessGroup runs this test binary as a stand-in
+// for the daemon: it starts an agent through ExecRunner, then the whole
+// daemon process group gets SIGINT, as from a terminal Ctrl-C or systemd's
+// control-group stop. The agent must survive so the task can finish.
+func TestExecRunner_Run_OwnProcessGroup(t *testing.T) {
+	if pidFile := os.Getenv("NIGHTSHIFT_TEST_DAEMON_PIDFILE"); pidFile != "" {
+		runDaemonStandIn(pidFile)
+		return
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "agent.pid")
+	daemon := exec.Command(os.Args[0], "-test.run=^TestExecRunner_Run_OwnProcessGroup$")
+	daemon.Env = append(os.Environ(), "NIGHTSHIFT_TEST_DAEMON_PIDFILE="+pidFile)
+	daemon.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var out bytes.Buffer
+	daemon.Stdout = &out
+	daemon.Stderr = &out
+	if err := daemon.Start(); err != nil {
+		t.Fatalf("starting daemon stand-in: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if data, err := os.ReadFile(pidFile); err == nil && strings.HasSuffix(string(data), "\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = daemon.Process.Kill()
+			t.Fatalf("agent never started:\n%s", out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := syscall.Kill(-daemon.Process.Pid, syscall.SIGINT); err != nil {
+		t.Fatalf("signalling daemon group: %v", err)
+	}
+	if err := daemon.Wait(); err != nil {
+		t.Fatalf("daemon stand-in: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "agent alive after signal") {
+		t.Errorf("agent did not survive the daemon's signal:\n%s", out.String())
+	}
+}
+
+// runDaemonStandIn starts a long-running agent, waits for SIGINT like the
+// daemon's drain handler, and reports whether the agent is still running.
+// Cancelling the context must then stop it.
+func runDaemonStandIn(pidFile string) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_, _, _, _ = (&ExecRunner{}).Run(ctx, "sh", []string{"-c", `echo $$ > "$0"; sleep 30`, pidFile}, "", "")
+		close(done)
+	}()
+
+	select {
+	case <-sigs:
+	case <-time.After(10 * time.Second):
+		fmt.Println("no signal received")
+		os.Exit(1)
+	}
+	select {
+	case <-done:
+		fmt.Println("agent died with the daemon's signal")
+		os.Exit(1)
+	case <-time.After(300 * time.Millisecond):
+		fmt.Println("agent alive after signal")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		fmt.Println("cancel did not stop the agent")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
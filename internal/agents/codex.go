@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -15,11 +16,17 @@ import (
 // CodexAgent spawns Codex CLI for task execution.
 type CodexAgent struct {
 	binaryPath string        // Path to codex binary (default: "codex")
+	binaryArgs []string      // Passed to the binary before managed args
 	timeout    time.Duration // Default timeout
 	runner     CommandRunner // Command executor (for testing)
 	bypassPerm bool          // Pass --dangerously-bypass-approvals-and-sandbox
+	extraArgs  []string      // Appended verbatim after managed args
+	env        []string      // KEY=VALUE overrides for the CLI process
 }
 
+// codexManagedFlags are the flags Nightshift sets itself.
+var codexManagedFlags = []string{"--dangerously-bypass-approvals-and-sandbox"}
+
 // CodexOption configures a CodexAgent.
 type CodexOption func(*CodexAgent)
 
@@ -30,6 +37,14 @@ func WithCodexBinaryPath(path string) CodexOption {
 	}
 }
 
+// WithCodexBinaryArgs sets args passed to the binary before Nightshift's
+// own, for wrapper commands.
+func WithCodexBinaryArgs(args []string) CodexOption {
+	return func(a *CodexAgent) {
+		a.binaryArgs = args
+	}
+}
+
 // WithCodexDefaultTimeout sets the default execution timeout.
 func WithCodexDefaultTimeout(d time.Duration) CodexOption {
 	return func(a *CodexAgent) {
@@ -44,6 +59,21 @@ func WithDangerouslyBypassApprovalsAndSandbox(enabled bool) CodexOption {
 	}
 }
 
+// WithCodexExtraArgs sets extra CLI args appended verbatim to every invocation.
+func WithCodexExtraArgs(args []string) CodexOption {
+	return func(a *CodexAgent) {
+		a.extraArgs = args
+	}
+}
+
+// WithCodexEnv sets KEY=VALUE entries added to the CLI's environment,
+// taking precedence over inherited variables.
+func WithCodexEnv(env []string) CodexOption {
+	return func(a *CodexAgent) {
+		a.env = env
+	}
+}
+
 // WithCodexRunner sets a custom command runner (for testing).
 func WithCodexRunner(r CommandRunner) CodexOption {
 	return func(a *CodexAgent) {
@@ -62,6 +92,7 @@ func NewCodexAgent(opts ...CodexOption) *CodexAgent {
 	for _, opt := range opts {
 		opt(a)
 	}
+	applyEnv(a.runner, a.env)
 	return a
 }
 
@@ -70,6 +101,26 @@ func (a *CodexAgent) Name() string {
 	return "codex"
 }
 
+// Command returns the headless codex command line for opts.
+// Codex CLI uses the `exec` subcommand for non-interactive mode.
+func (a *CodexAgent) Command(opts ExecuteOptions) (string, []string) {
+	args := append(slices.Clone(a.binaryArgs), "exec")
+	if a.bypassPerm {
+		args = append(args, "--dangerously-bypass-approvals-and-sandbox")
+	}
+	if opts.Model != "" {
+		args = append(args, "--model", opts.Model)
+	}
+
+	// Add prompt directly as argument
+	if opts.Prompt != "" {
+		args = append(args, opts.Prompt)
+	}
+
+	args = append(args, a.extraArgs...)
+	return a.binaryPath, args
+}
+
 // Execute runs codex with the given prompt in non-interactive mode.
 func (a *CodexAgent) Execute(ctx context.Context, opts ExecuteOptions) (*ExecuteResult, error) {
 	start := time.Now()
@@ -84,17 +135,7 @@ func (a *CodexAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execute
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Build command args for headless/non-interactive execution.
-	// Codex CLI uses the `exec` subcommand for non-interactive mode.
-	args := []string{"exec"}
-	if a.bypassPerm {
-		args = append(args, "--dangerously-bypass-approvals-and-sandbox")
-	}
-
-	// Add prompt directly as argument
-	if opts.Prompt != "" {
-		args = append(args, opts.Prompt)
-	}
+	name, args := a.Command(opts)
 
 	// Build stdin content from files if provided
 	var stdinContent string
@@ -110,7 +151,7 @@ func (a *CodexAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execute
 	}
 
 	// Run command
-	stdout, stderr, exitCode, err := a.runner.Run(ctx, a.binaryPath, args, opts.WorkDir, stdinContent)
+	stdout, stderr, exitCode, err := a.runner.Run(ctx, name, args, opts.WorkDir, stdinContent)
 
 	result := &ExecuteResult{
 		Output:   stdout,
@@ -225,15 +266,27 @@ func (a *CodexAgent) extractJSON(output []byte) []byte {
 	return nil
 }
 
+// ExtraArgCollisions returns extra args that re-specify a managed flag.
+func (a *CodexAgent) ExtraArgCollisions() []string {
+	return collidingArgs(a.extraArgs, codexManagedFlags)
+}
+
 // Available checks if the codex binary is available in PATH.
 func (a *CodexAgent) Available() bool {
 	_, err := exec.LookPath(a.binaryPath)
 	return err == nil
 }
 
+// CheckAuth reports whether the codex CLI is logged in via
+// `codex login status`.
+func (a *CodexAgent) CheckAuth(ctx context.Context) AuthStatus {
+	stdout, stderr, exitCode, err := a.runner.Run(ctx, a.binaryPath, append(slices.Clone(a.binaryArgs), "login", "status"), "", "")
+	return codexAuthStatus(stdout, stderr, exitCode, err)
+}
+
 // Version returns the codex CLI version.
 func (a *CodexAgent) Version() (string, error) {
-	cmd := exec.Command(a.binaryPath, "--version")
+	cmd := exec.Command(a.binaryPath, append(slices.Clone(a.binaryArgs), "--version")...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("getting version: %w", err)
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -27,10 +28,19 @@ import (
 // - Types: shell, gh, git (for different command contexts)
 type CopilotAgent struct {
 	binaryPath string        // Path to binary: "gh" or "copilot" (default: "gh")
+	binaryArgs []string      // Passed to the binary before managed args
 	timeout    time.Duration // Default timeout
 	runner     CommandRunner // Command executor (for testing)
+	extraArgs  []string      // Appended verbatim after managed args
+	env        []string      // KEY=VALUE overrides for the CLI process
 }
 
+// Flags Nightshift sets itself, per invocation mode.
+var (
+	copilotGhManagedFlags         = []string{"-t", "--target", "--no-ask-user"}
+	copilotStandaloneManagedFlags = []string{"-p", "--prompt", "--no-ask-user", "--allow-all-tools", "--silent"}
+)
+
 // CopilotOption configures a CopilotAgent.
 type CopilotOption func(*CopilotAgent)
 
@@ -41,6 +51,14 @@ func WithCopilotBinaryPath(path string) CopilotOption {
 	}
 }
 
+// WithCopilotBinaryArgs sets args passed to the binary before Nightshift's
+// own, for wrapper commands.
+func WithCopilotBinaryArgs(args []string) CopilotOption {
+	return func(a *CopilotAgent) {
+		a.binaryArgs = args
+	}
+}
+
 // WithCopilotDefaultTimeout sets the default execution timeout.
 func WithCopilotDefaultTimeout(d time.Duration) CopilotOption {
 	return func(a *CopilotAgent) {
@@ -48,6 +66,21 @@ func WithCopilotDefaultTimeout(d time.Duration) CopilotOption {
 	}
 }
 
+// WithCopilotExtraArgs sets extra CLI args appended verbatim to every invocation.
+func WithCopilotExtraArgs(args []string) CopilotOption {
+	return func(a *CopilotAgent) {
+		a.extraArgs = args
+	}
+}
+
+// WithCopilotEnv sets KEY=VALUE entries added to the CLI's environment,
+// taking precedence over inherited variables.
+func WithCopilotEnv(env []string) CopilotOption {
+	return func(a *CopilotAgent) {
+		a.env = env
+	}
+}
+
 // WithCopilotRunner sets a custom command runner (for testing).
 func WithCopilotRunner(r CommandRunner) CopilotOption {
 	return func(a *CopilotAgent) {
@@ -65,6 +98,7 @@ func NewCopilotAgent(opts ...CopilotOption) *CopilotAgent {
 	for _, opt := range opts {
 		opt(a)
 	}
+	applyEnv(a.runner, a.env)
 	return a
 }
 
@@ -73,6 +107,40 @@ func (a *CopilotAgent) Name() string {
 	return "copilot"
 }
 
+// ghMode reports whether the binary is gh, which runs Copilot as the
+// `gh copilot` extension. Any other binary, including a wrapper command, is
+// invoked like the standalone copilot CLI.
+func (a *CopilotAgent) ghMode() bool {
+	return filepath.Base(a.binaryPath) == "gh"
+}
+
+// Command returns the copilot command line for opts.
+func (a *CopilotAgent) Command(opts ExecuteOptions) (string, []string) {
+	// Two modes:
+	// 1. gh copilot: gh copilot suggest -t <type> --no-ask-user <prompt>
+	// 2. standalone copilot: copilot -p <prompt> --no-ask-user --allow-all-tools --silent
+	args := slices.Clone(a.binaryArgs)
+	if a.ghMode() {
+		args = append(args, "copilot", "suggest", "-t", "shell")
+		// Add --no-ask-user for non-interactive execution (autonomous mode)
+		args = append(args, "--no-ask-user")
+		// Add prompt directly as argument
+		if opts.Prompt != "" {
+			args = append(args, opts.Prompt)
+		}
+	} else {
+		// Standalone copilot binary uses -p flag for non-interactive mode
+		// --silent outputs only the response (no stats), useful for scripting
+		args = append(args, "-p", opts.Prompt, "--no-ask-user", "--allow-all-tools", "--silent")
+		// gh copilot suggest has no model selection; only the standalone CLI does
+		if opts.Model != "" {
+			args = append(args, "--model", opts.Model)
+		}
+	}
+	args = append(args, a.extraArgs...)
+	return a.binaryPath, args
+}
+
 // Execute runs gh copilot with the given prompt.
 //
 // Implementation approach:
@@ -93,24 +161,7 @@ func (a *CopilotAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execu
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Build command args
-	// Two modes:
-	// 1. gh copilot: gh copilot suggest -t <type> --no-ask-user <prompt>
-	// 2. standalone copilot: copilot -p <prompt> --no-ask-user --allow-all-tools --silent
-	var args []string
-	if a.binaryPath == "gh" {
-		args = []string{"copilot", "suggest", "-t", "shell"}
-		// Add --no-ask-user for non-interactive execution (autonomous mode)
-		args = append(args, "--no-ask-user")
-		// Add prompt directly as argument
-		if opts.Prompt != "" {
-			args = append(args, opts.Prompt)
-		}
-	} else {
-		// Standalone copilot binary uses -p flag for non-interactive mode
-		// --silent outputs only the response (no stats), useful for scripting
-		args = []string{"-p", opts.Prompt, "--no-ask-user", "--allow-all-tools", "--silent"}
-	}
+	name, args := a.Command(opts)
 
 	// Build stdin content from files if provided
 	var stdinContent string
@@ -126,7 +177,7 @@ func (a *CopilotAgent) Execute(ctx context.Context, opts ExecuteOptions) (*Execu
 	}
 
 	// Run command
-	stdout, stderr, exitCode, err := a.runner.Run(ctx, a.binaryPath, args, opts.WorkDir, stdinContent)
+	stdout, stderr, exitCode, err := a.runner.Run(ctx, name, args, opts.WorkDir, stdinContent)
 
 	result := &ExecuteResult{
 		Output:   stdout,
@@ -241,6 +292,14 @@ func (a *CopilotAgent) extractJSON(output []byte) []byte {
 	return nil
 }
 
+// ExtraArgCollisions returns extra args that re-specify a managed flag.
+func (a *CopilotAgent) ExtraArgCollisions() []string {
+	if a.ghMode() {
+		return collidingArgs(a.extraArgs, copilotGhManagedFlags)
+	}
+	return collidingArgs(a.extraArgs, copilotStandaloneManagedFlags)
+}
+
 // Available checks if the gh binary is available in PATH and copilot extension is installed.
 func (a *CopilotAgent) Available() bool {
 	// Check if binary is available
@@ -249,13 +308,13 @@ func (a *CopilotAgent) Available() bool {
 	}
 
 	// If using standalone copilot binary, it's available
-	if a.binaryPath == "copilot" {
+	if !a.ghMode() {
 		return true
 	}
 
 	// If using gh, check if copilot extension is installed
 	// Run: gh extension list | grep copilot
-	cmd := exec.Command(a.binaryPath, "extension", "list")
+	cmd := exec.Command(a.binaryPath, append(slices.Clone(a.binaryArgs), "extension", "list")...)
 	output, err := cmd.Output()
 	if err != nil {
 		return false
@@ -270,7 +329,7 @@ func (a *CopilotAgent) Available() bool {
 func (a *CopilotAgent) Version() (string, error) {
 	// GitHub CLI doesn't have a direct version command for extensions
 	// We can get gh version instead
-	cmd := exec.Command(a.binaryPath, "--version")
+	cmd := exec.Command(a.binaryPath, append(slices.Clone(a.binaryArgs), "--version")...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("getting version: %w", err)
@@ -0,0 +1,99 @@
+package agents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// dockerRemoveTimeout bounds the cleanup of a container whose run was
+// cancelled.
+const dockerRemoveTimeout = 10 * time.Second
+
+// DockerRunner is a CommandRunner that runs each command inside a fresh
+// container. The working directory is bind-mounted read-write at the same
+// path, so the agent sees and edits the project as usual; the rest of the
+// host filesystem is only visible through Mounts.
+type DockerRunner struct {
+	// Image is the container image; it must have the agent CLI installed.
+	Image string
+	// Mounts are extra docker -v values ("host:container[:ro]").
+	Mounts []string
+	// Env holds KEY=VALUE entries passed into the container along with the
+	// originator tags.
+	Env []string
+	// Binary is the docker CLI to invoke (default "docker").
+	Binary string
+}
+
+// NewDockerRunner creates a runner for image with extra bind mounts.
+func NewDockerRunner(image string, mounts []string) *DockerRunner {
+	return &DockerRunner{Image: image, Mounts: mounts, Binary: "docker"}
+}
+
+// Available reports whether the docker CLI is in PATH.
+func (r *DockerRunner) Available() bool {
+	_, err := exec.LookPath(r.binary())
+	return err == nil
+}
+
+func (r *DockerRunner) binary() string {
+	if r.Binary == "" {
+		return "docker"
+	}
+	return r.Binary
+}
+
+// Args returns the docker run arguments that run name args in a container
+// called container, with dir mounted as the working directory.
+func (r *DockerRunner) Args(container, name string, args []string, dir string, stdin bool) []string {
+	out := []string{"run", "--rm", "--name", container}
+	if stdin {
+		out = append(out, "-i")
+	}
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		// Files the agent writes stay owned by the invoking user.
+		out = append(out, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	if dir != "" {
+		out = append(out, "-v", dir+":"+dir, "-w", dir)
+	}
+	for _, m := range r.Mounts {
+		out = append(out, "-v", m)
+	}
+	// Values stay out of the process list: docker reads -e KEY from its
+	// own environment, which Run sets.
+	for _, e := range append(append([]string{}, originatorEnv...), r.Env...) {
+		key, _, _ := strings.Cut(e, "=")
+		out = append(out, "-e", key)
+	}
+	out = append(out, r.Image, name)
+	return append(out, args...)
+}
+
+// Run executes name args in a new container and returns its output. If ctx
+// ends first, the container is removed.
+func (r *DockerRunner) Run(ctx context.Context, name string, args []string, dir string, stdin string) (string, string, int, error) {
+	container := "nightshift-" + randomSuffix()
+	runner := &ExecRunner{Env: r.Env}
+	stdout, stderr, exitCode, err := runner.Run(ctx, r.binary(), r.Args(container, name, args, dir, stdin != ""), dir, stdin)
+	if ctx.Err() != nil {
+		// Killing the docker client doesn't stop the container.
+		rmCtx, cancel := context.WithTimeout(context.Background(), dockerRemoveTimeout)
+		defer cancel()
+		_, _, _, _ = (&ExecRunner{}).Run(rmCtx, r.binary(), []string{"rm", "-f", container}, "", "")
+	}
+	return stdout, stderr, exitCode, err
+}
+
+// randomSuffix returns 8 random hex characters for container names.
+func randomSuffix() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package agents
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDockerRunnerArgs(t *testing.T) {
+	r := NewDockerRunner("agents:latest", []string{"/home/me/.claude:/home/agent/.claude:ro"})
+	r.Env = []string{"API_TOKEN=secret"}
+
+	got := r.Args("nightshift-test", "claude", []string{"--print", "fix it"}, "/src/app", true)
+	line := strings.Join(got, " ")
+
+	for _, want := range []string{
+		"run --rm --name nightshift-test -i ",
+		"-v /src/app:/src/app -w /src/app",
+		"-v /home/me/.claude:/home/agent/.claude:ro",
+		"-e NIGHTSHIFT_ORIGINATOR",
+		"-e API_TOKEN ",
+		"agents:latest claude --print fix it",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Args() = %q, missing %q", line, want)
+		}
+	}
+	if strings.Contains(line, "secret") {
+		t.Errorf("Args() = %q, leaks an env value", line)
+	}
+	if got[len(got)-1] != "fix it" {
+		t.Errorf("prompt not passed as the last argument: %q", got)
+	}
+
+	noStdin := r.Args("nightshift-test", "claude", nil, "", false)
+	if slices.Contains(noStdin, "-i") || slices.Contains(noStdin, "-w") {
+		t.Errorf("Args() without stdin or dir = %q, want no -i or -w", noStdin)
+	}
+}
+
+func TestDockerRunnerRun(t *testing.T) {
+	// A fake docker that prints its arguments and the env value -e passes
+	tmp := t.TempDir()
+	fake := filepath.Join(tmp, "docker")
+	script := "#!/bin/sh\necho \"$@\"\necho \"token=$API_TOKEN\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewDockerRunner("agents:latest", nil)
+	r.Binary = fake
+	agent := NewClaudeAgent(WithRunner(r), WithEnv([]string{"API_TOKEN=secret"}))
+	if len(r.Env) != 1 {
+		t.Fatalf("WithEnv did not reach the docker runner: Env = %q", r.Env)
+	}
+
+	result, err := agent.Execute(context.Background(), ExecuteOptions{Prompt: "hello", WorkDir: tmp})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(result.Output, "agents:latest claude --print") || !strings.Contains(result.Output, "-w "+tmp) {
+		t.Errorf("output = %q, want claude run in the image with the project as workdir", result.Output)
+	}
+	if !strings.Contains(result.Output, "token=secret") {
+		t.Errorf("output = %q, want API_TOKEN in docker's environment", result.Output)
+	}
+}
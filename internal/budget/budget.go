@@ -3,6 +3,8 @@
 package budget
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"time"
@@ -52,6 +54,19 @@ type BudgetSource interface {
 	GetBudget(provider string) (BudgetEstimate, error)
 }
 
+// TokenRatioSource reports the calibrated Codex/Claude token multiplier:
+// how many Codex tokens do the work of one Claude token. Implemented
+// optionally by a BudgetSource.
+type TokenRatioSource interface {
+	TokenRatio() (float64, bool, error)
+}
+
+// ReservationSource reports tokens manually held back from a provider's
+// allowance (nightshift budget reserve).
+type ReservationSource interface {
+	ReservedTokens(provider string, now time.Time) (int64, error)
+}
+
 // UsedPercentSourceProvider reports where the last used-percent value came from.
 // Implemented optionally by providers to improve CLI diagnostics.
 type UsedPercentSourceProvider interface {
@@ -74,17 +89,27 @@ type Manager struct {
 	copilot      CopilotUsageProvider
 	budgetSource BudgetSource
 	trend        TrendAnalyzer
-	nowFunc      func() time.Time // for testing
+	reservations ReservationSource
+	cache        *UsageCache
+	// cachedSources is the used-percent source of providers last answered
+	// from cache.
+	cachedSources map[string]string
+	usageTimeout  time.Duration    // bound on each provider usage read; 0 = none
+	nowFunc       func() time.Time // for testing
 }
 
 // NewManager creates a budget manager with the given configuration and providers.
 func NewManager(cfg *config.Config, claude ClaudeUsageProvider, codex CodexUsageProvider, copilot CopilotUsageProvider, opts ...Option) *Manager {
 	mgr := &Manager{
-		cfg:     cfg,
-		claude:  claude,
-		codex:   codex,
-		copilot: copilot,
-		nowFunc: time.Now,
+		cfg:           cfg,
+		claude:        claude,
+		codex:         codex,
+		copilot:       copilot,
+		cachedSources: map[string]string{},
+		nowFunc:       time.Now,
+	}
+	if cfg != nil {
+		mgr.usageTimeout = cfg.GetUsageTimeout()
 	}
 	for _, opt := range opts {
 		opt(mgr)
@@ -99,6 +124,14 @@ func WithBudgetSource(source BudgetSource) Option {
 	}
 }
 
+// WithReservations subtracts active budget reservations from every
+// allowance. A nil source holds nothing back.
+func WithReservations(source ReservationSource) Option {
+	return func(m *Manager) {
+		m.reservations = source
+	}
+}
+
 // WithTrendAnalyzer injects a trend analyzer for predicted daytime usage.
 func WithTrendAnalyzer(analyzer TrendAnalyzer) Option {
 	return func(m *Manager) {
@@ -106,6 +139,15 @@ func WithTrendAnalyzer(analyzer TrendAnalyzer) Option {
 	}
 }
 
+// WithUsageTimeout overrides providers.usage_timeout, the bound on each
+// provider usage read. A provider that times out returns an error wrapping
+// providers.ErrUsageTimeout, so callers skip it instead of blocking.
+func WithUsageTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.usageTimeout = d
+	}
+}
+
 // AllowanceResult contains the calculated budget allowance and metadata.
 type AllowanceResult struct {
 	Allowance          int64   // Final token allowance for this run
@@ -116,12 +158,18 @@ type AllowanceResult struct {
 	UsedPercentSource  string  // Source of used percentage (e.g., stats-cache, jsonl-fallback)
 	ReserveAmount      int64   // Tokens reserved
 	PredictedUsage     int64   // Predicted remaining usage today
-	Mode               string  // "daily" or "weekly"
+	HeldTokens         int64   // Held back by active budget reservations
+	Mode               string  // "daily" or "weekly" (in auto mode, the binding window)
+	BindingWindow      string  // Auto mode only: window that bound the allowance ("daily" or "weekly")
 	RemainingDays      int     // Days until reset (weekly mode only)
 	Multiplier         float64 // End-of-week multiplier (weekly mode only)
+	Pacing             string  // budget.pacing curve (weekly mode only)
+	PacingShare        float64 // Fraction of the remaining weekly budget tonight gets before max_percent (weekly mode only)
+	DailyShareCap      int64   // budget.max_daily_share cap, when it lowered the allowance (weekly mode only)
 	BudgetSource       string  // calibrated, api, config
 	BudgetConfidence   string  // none, low, medium, high
 	BudgetSampleCount  int     // number of samples used
+	Pooled             bool    // Pooled mode: budget figures are the shared pool's; Allowance is this provider's share
 }
 
 // CalculateAllowance determines how many tokens nightshift can use for this run.
@@ -131,12 +179,10 @@ func (m *Manager) CalculateAllowance(provider string) (*AllowanceResult, error)
 		return nil, err
 	}
 	weeklyBudget := estimate.WeeklyTokens
-
-	usedPercent, err := m.GetUsedPercent(provider)
-	if err != nil {
-		return nil, fmt.Errorf("getting used percent for %s: %w", provider, err)
+	pooled := m.pooled()
+	if pooled {
+		weeklyBudget = m.poolWeeklyTokens()
 	}
-	usedPercentSource := m.usedPercentSource(provider)
 
 	mode := m.cfg.Budget.Mode
 	if mode == "" {
@@ -156,23 +202,44 @@ func (m *Manager) CalculateAllowance(provider string) (*AllowanceResult, error)
 	var result *AllowanceResult
 
 	switch mode {
-	case "daily":
-		result = m.calculateDailyAllowance(weeklyBudget, usedPercent, maxPercent)
-	case "weekly":
-		remainingDays, err := m.DaysUntilWeeklyReset(provider)
+	case "daily", "weekly":
+		result, err = m.calculateWindowAllowance(provider, mode, weeklyBudget, maxPercent, reservePercent)
 		if err != nil {
-			return nil, fmt.Errorf("getting days until reset: %w", err)
+			return nil, err
 		}
-		result = m.calculateWeeklyAllowance(weeklyBudget, usedPercent, maxPercent, remainingDays)
+	case "auto":
+		// Evaluate both windows and gate on the one that leaves less room,
+		// so a run never over-commits against either.
+		daily, err := m.calculateWindowAllowance(provider, "daily", weeklyBudget, maxPercent, reservePercent)
+		if err != nil {
+			return nil, err
+		}
+		weekly, err := m.calculateWindowAllowance(provider, "weekly", weeklyBudget, maxPercent, reservePercent)
+		if err != nil {
+			return nil, err
+		}
+		result = daily
+		if weekly.Allowance < daily.Allowance {
+			result = weekly
+		}
+		result.BindingWindow = result.Mode
 	default:
 		return nil, fmt.Errorf("invalid budget mode: %s", mode)
 	}
+	usedPercentSource := m.usedPercentSource(provider)
+
+	if pooled {
+		share, err := m.poolShare(provider, result.Allowance)
+		if err != nil {
+			return nil, err
+		}
+		result.Allowance = share
+		result.Pooled = true
+	}
 
-	// Apply reserve enforcement
-	result = m.applyReserve(result, reservePercent)
 	result.AllowanceNoDaytime = result.Allowance
 	if m.trend != nil {
-		predicted, err := m.trend.PredictDaytimeUsage(provider, m.nowFunc(), weeklyBudget)
+		predicted, err := m.trend.PredictDaytimeUsage(provider, m.nowFunc(), estimate.WeeklyTokens)
 		if err != nil {
 			return nil, fmt.Errorf("predict daytime usage: %w", err)
 		}
@@ -185,6 +252,17 @@ func (m *Manager) CalculateAllowance(provider string) (*AllowanceResult, error)
 			}
 		}
 	}
+	if m.reservations != nil {
+		held, err := m.reservations.ReservedTokens(provider, m.nowFunc())
+		if err != nil {
+			return nil, fmt.Errorf("budget reservations: %w", err)
+		}
+		if held > 0 {
+			result.HeldTokens = held
+			result.Allowance = max(0, result.Allowance-held)
+			result.AllowanceNoDaytime = max(0, result.AllowanceNoDaytime-held)
+		}
+	}
 	result.BudgetSource = estimate.Source
 	result.BudgetConfidence = estimate.Confidence
 	result.BudgetSampleCount = estimate.SampleCount
@@ -194,6 +272,36 @@ func (m *Manager) CalculateAllowance(provider string) (*AllowanceResult, error)
 	return result, nil
 }
 
+// calculateWindowAllowance computes the reserve-adjusted allowance for a
+// single budget window ("daily" or "weekly").
+// In pooled mode weeklyBudget is the pool's and usage is the pool's combined
+// usage.
+func (m *Manager) calculateWindowAllowance(provider, window string, weeklyBudget int64, maxPercent, reservePercent int) (*AllowanceResult, error) {
+	var usedPercent float64
+	var err error
+	if m.pooled() {
+		if usedPercent, err = m.pooledUsedPercent(window); err != nil {
+			return nil, fmt.Errorf("pooled budget: %w", err)
+		}
+	} else if usedPercent, err = m.usedPercentForMode(provider, window, weeklyBudget); err != nil {
+		return nil, fmt.Errorf("getting used percent for %s: %w", provider, err)
+	}
+
+	var result *AllowanceResult
+	if window == "daily" {
+		result = m.calculateDailyAllowance(weeklyBudget, usedPercent, maxPercent)
+	} else {
+		remainingDays, err := m.DaysUntilWeeklyReset(provider)
+		if err != nil {
+			return nil, fmt.Errorf("getting days until reset: %w", err)
+		}
+		result = m.calculateWeeklyAllowance(weeklyBudget, usedPercent, maxPercent, remainingDays)
+	}
+
+	// Apply reserve enforcement
+	return m.applyReserve(result, reservePercent), nil
+}
+
 // calculateDailyAllowance implements the daily mode budget algorithm.
 // Daily mode: Each night uses up to max_percent of that day's budget (weekly/7).
 func (m *Manager) calculateDailyAllowance(weeklyBudget int64, usedPercent float64, maxPercent int) *AllowanceResult {
@@ -216,7 +324,8 @@ func (m *Manager) calculateDailyAllowance(weeklyBudget int64, usedPercent float6
 }
 
 // calculateWeeklyAllowance implements the weekly mode budget algorithm.
-// Weekly mode: Each night uses up to max_percent of REMAINING weekly budget.
+// Weekly mode: Each night uses up to max_percent of its share of the
+// REMAINING weekly budget, as set by budget.pacing.
 func (m *Manager) calculateWeeklyAllowance(weeklyBudget int64, usedPercent float64, maxPercent int, remainingDays int) *AllowanceResult {
 	if remainingDays <= 0 {
 		remainingDays = 1 // Avoid division by zero
@@ -224,14 +333,28 @@ func (m *Manager) calculateWeeklyAllowance(weeklyBudget int64, usedPercent float
 
 	remainingWeekly := float64(weeklyBudget) * (1 - usedPercent/100)
 
-	// Aggressive end-of-week multiplier
+	pacing := m.cfg.GetBudgetPacing()
+	tonight, share := pacedShare(pacing, remainingWeekly, remainingDays)
+
+	// Aggressive end-of-week multiplier. Only greedy pacing spends past its
+	// share; even and front-loaded stick to the curve.
 	multiplier := 1.0
-	if m.cfg.Budget.AggressiveEndOfWeek && remainingDays <= 2 {
+	if pacing == config.PacingGreedy && m.cfg.Budget.AggressiveEndOfWeek && remainingDays <= 2 {
 		// 2x on day before reset, 3x on last day
 		multiplier = float64(3 - remainingDays)
 	}
 
-	nightshiftAllowance := (remainingWeekly / float64(remainingDays)) * float64(maxPercent) / 100 * multiplier
+	nightshiftAllowance := tonight * float64(maxPercent) / 100 * multiplier
+
+	// Skipped nights leave more of the week for the rest, so without a cap
+	// one late night could spend nearly all of it.
+	var shareCap int64
+	if share := m.cfg.Budget.MaxDailyShare; share > 0 {
+		if limit := float64(weeklyBudget) * share; nightshiftAllowance > limit {
+			nightshiftAllowance = limit
+			shareCap = int64(limit)
+		}
+	}
 
 	return &AllowanceResult{
 		Allowance:     int64(math.Max(0, nightshiftAllowance)),
@@ -240,9 +363,26 @@ func (m *Manager) calculateWeeklyAllowance(weeklyBudget int64, usedPercent float
 		Mode:          "weekly",
 		RemainingDays: remainingDays,
 		Multiplier:    multiplier,
+		Pacing:        pacing,
+		PacingShare:   share,
+		DailyShareCap: shareCap,
 	}
 }
 
+// pacedShare returns tonight's part of the remaining weekly budget, and
+// that part as a fraction, with remainingNights nights (tonight included)
+// left before the reset. Greedy and even split it equally. Front-loaded
+// weights the nights n, n-1, ..., 1, so tonight gets n/(n(n+1)/2) = 2/(n+1);
+// as nights pass the curve stays the one the week started on, and the last
+// night gets all that is left.
+func pacedShare(pacing string, remainingWeekly float64, remainingNights int) (float64, float64) {
+	n := float64(remainingNights)
+	if pacing == config.PacingFrontLoaded {
+		return remainingWeekly * 2 / (n + 1), 2 / (n + 1)
+	}
+	return remainingWeekly / n, 1 / n
+}
+
 // applyReserve enforces the reserve percentage on the calculated allowance.
 func (m *Manager) applyReserve(result *AllowanceResult, reservePercent int) *AllowanceResult {
 	reserveAmount := float64(result.BudgetBase) * float64(reservePercent) / 100
@@ -281,8 +421,27 @@ func (m *Manager) resolveBudget(provider string) (BudgetEstimate, error) {
 	return estimate, nil
 }
 
+// TokenRatio returns the calibrated Codex/Claude token multiplier (Codex
+// tokens per Claude token of work) when the budget source provides one, and
+// false when none has been calibrated.
+func (m *Manager) TokenRatio() (float64, bool, error) {
+	source, ok := m.budgetSource.(TokenRatioSource)
+	if !ok {
+		return 0, false, nil
+	}
+	ratio, ok, err := source.TokenRatio()
+	if err != nil {
+		return 0, false, fmt.Errorf("token ratio: %w", err)
+	}
+	if !ok || ratio <= 0 {
+		return 0, false, nil
+	}
+	return ratio, true, nil
+}
+
 // GetUsedPercent retrieves the used percentage from the appropriate provider.
 // Uses the resolved (calibrated) budget so percentages match the displayed budget.
+// In auto mode it returns the higher of the daily and weekly percentages.
 func (m *Manager) GetUsedPercent(provider string) (float64, error) {
 	estimate, err := m.resolveBudget(provider)
 	if err != nil {
@@ -295,18 +454,39 @@ func (m *Manager) GetUsedPercent(provider string) (float64, error) {
 		mode = config.DefaultBudgetMode
 	}
 
+	if mode == "auto" {
+		daily, err := m.usedPercentForMode(provider, "daily", weeklyBudget)
+		if err != nil {
+			return 0, err
+		}
+		weekly, err := m.usedPercentForMode(provider, "weekly", weeklyBudget)
+		if err != nil {
+			return 0, err
+		}
+		return math.Max(daily, weekly), nil
+	}
+	return m.usedPercentForMode(provider, mode, weeklyBudget)
+}
+
+// usedPercentForMode asks the provider for its used percentage in the given
+// window ("daily" or "weekly").
+func (m *Manager) usedPercentForMode(provider, mode string, weeklyBudget int64) (float64, error) {
 	switch provider {
 	case "claude":
 		if m.claude == nil {
 			return 0, fmt.Errorf("claude provider not configured")
 		}
-		return m.claude.GetUsedPercent(mode, weeklyBudget)
+		return m.cachedUsedPercent(provider, mode, weeklyBudget, func() (float64, error) {
+			return m.claude.GetUsedPercent(mode, weeklyBudget)
+		})
 
 	case "codex":
 		if m.codex == nil {
 			return 0, fmt.Errorf("codex provider not configured")
 		}
-		return m.codex.GetUsedPercent(mode, weeklyBudget)
+		return m.cachedUsedPercent(provider, mode, weeklyBudget, func() (float64, error) {
+			return m.codex.GetUsedPercent(mode, weeklyBudget)
+		})
 
 	case "copilot":
 		if m.copilot == nil {
@@ -316,7 +496,9 @@ func (m *Manager) GetUsedPercent(provider string) (float64, error) {
 		// Convert weekly budget to monthly limit for consistency
 		// Note: This is a simplification; actual monthly limits should be configured separately
 		monthlyLimit := weeklyBudget * 4 // Approximate: 4 weeks per month
-		return m.copilot.GetUsedPercent(mode, monthlyLimit)
+		return m.cachedUsedPercent(provider, mode, weeklyBudget, func() (float64, error) {
+			return m.copilot.GetUsedPercent(mode, monthlyLimit)
+		})
 
 	default:
 		return 0, fmt.Errorf("unknown provider: %s", provider)
@@ -324,6 +506,9 @@ func (m *Manager) GetUsedPercent(provider string) (float64, error) {
 }
 
 func (m *Manager) usedPercentSource(provider string) string {
+	if source, ok := m.cachedSources[provider]; ok {
+		return source
+	}
 	switch provider {
 	case "claude":
 		if reporter, ok := m.claude.(UsedPercentSourceProvider); ok {
@@ -340,6 +525,40 @@ func (m *Manager) usedPercentSource(provider string) string {
 	return ""
 }
 
+// readUsage calls a provider usage read, giving up after the manager's usage
+// timeout with an error naming the provider.
+func readUsage[T any](m *Manager, provider string, read func() (T, error)) (T, error) {
+	v, err := providers.ReadWithTimeout(context.Background(), provider, m.usageTimeout, read)
+	if errors.Is(err, providers.ErrUsageTimeout) {
+		return v, fmt.Errorf("%s: %w", provider, err)
+	}
+	return v, err
+}
+
+// GetResetTime returns the provider-reported time of the next budget reset.
+// Returns the zero time when the provider does not report one (e.g. Claude).
+// In auto mode it reports the daily reset, the soonest window to free up.
+func (m *Manager) GetResetTime(provider string) (time.Time, error) {
+	mode := m.cfg.Budget.Mode
+	if mode == "" || mode == "auto" {
+		mode = "daily"
+	}
+	switch provider {
+	case "codex":
+		if m.codex == nil {
+			return time.Time{}, nil
+		}
+		return m.cachedResetTime(provider, mode, func() (time.Time, error) { return m.codex.GetResetTime(mode) })
+	case "copilot":
+		if m.copilot == nil {
+			return time.Time{}, nil
+		}
+		return m.cachedResetTime(provider, mode, func() (time.Time, error) { return m.copilot.GetResetTime(mode) })
+	default:
+		return time.Time{}, nil
+	}
+}
+
 // DaysUntilWeeklyReset calculates days remaining until the weekly budget resets.
 // For Claude: assumes weekly reset on Sunday (7 - current weekday, or 7 if Sunday).
 // For Codex: uses the secondary rate limit's resets_at timestamp.
@@ -360,7 +579,7 @@ func (m *Manager) DaysUntilWeeklyReset(provider string) (int, error) {
 		if m.codex == nil {
 			return 7, nil // Default fallback
 		}
-		resetTime, err := m.codex.GetResetTime("weekly")
+		resetTime, err := m.cachedResetTime(provider, "weekly", func() (time.Time, error) { return m.codex.GetResetTime("weekly") })
 		if err != nil {
 			return 7, nil // Fallback on error
 		}
@@ -381,7 +600,7 @@ func (m *Manager) DaysUntilWeeklyReset(provider string) (int, error) {
 		if m.copilot == nil {
 			return 30, nil // Default fallback (approximate month)
 		}
-		resetTime, err := m.copilot.GetResetTime("weekly")
+		resetTime, err := m.cachedResetTime(provider, "weekly", func() (time.Time, error) { return m.copilot.GetResetTime("weekly") })
 		if err != nil {
 			return 30, nil // Fallback on error
 		}
@@ -437,6 +656,38 @@ func (m *Manager) CanRun(provider string, estimatedTokens int64) (bool, error) {
 	return result.Allowance >= estimatedTokens, nil
 }
 
+// ErrOverdraft means a task's reservation would take weekly usage past 100%
+// of the weekly budget.
+var ErrOverdraft = errors.New("would overdraw the weekly budget")
+
+// CheckOverdraft returns an error wrapping ErrOverdraft when reserving
+// tokens for a task would push provider's weekly usage over its weekly
+// budget. In pooled mode the reservation must fit provider's share of what
+// is left of the pool, converted to its own tokens as poolShare does. It
+// holds in every mode, so neither carried-over allowance nor the
+// end-of-week multiplier can start a task the week can't pay for.
+func (m *Manager) CheckOverdraft(provider string, tokens int64) error {
+	if m.pooled() {
+		return m.checkPoolOverdraft(provider, tokens)
+	}
+	estimate, err := m.resolveBudget(provider)
+	if err != nil {
+		return err
+	}
+	weeklyBudget := estimate.WeeklyTokens
+	usedPercent, err := m.usedPercentForMode(provider, "weekly", weeklyBudget)
+	if err != nil {
+		return fmt.Errorf("getting used percent for %s: %w", provider, err)
+	}
+
+	used := int64(float64(weeklyBudget) * usedPercent / 100)
+	if used+tokens > weeklyBudget {
+		return fmt.Errorf("%s: reserving %d tokens with %d of %d used this week %w",
+			provider, tokens, used, weeklyBudget, ErrOverdraft)
+	}
+	return nil
+}
+
 // Tracker provides backward compatibility for tracking actual spend.
 // Deprecated: Use Manager for budget calculations.
 type Tracker struct {
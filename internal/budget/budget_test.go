@@ -1,21 +1,28 @@
 package budget
 
 import (
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/marcus/nightshift/internal/config"
+	"github.com/marcus/nightshift/internal/providers"
 )
 
 // mockClaudeProvider implements ClaudeUsageProvider for testing.
 type mockClaudeProvider struct {
 	usedPercent float64
+	byMode      map[string]float64 // per-window override of usedPercent
 	err         error
 	source      string
 }
 
 func (m *mockClaudeProvider) Name() string { return "claude" }
 func (m *mockClaudeProvider) GetUsedPercent(mode string, weeklyBudget int64) (float64, error) {
+	if pct, ok := m.byMode[mode]; ok {
+		return pct, m.err
+	}
 	return m.usedPercent, m.err
 }
 func (m *mockClaudeProvider) LastUsedPercentSource() string {
@@ -54,6 +61,7 @@ func (m *mockCopilotProvider) GetResetTime(mode string) (time.Time, error) {
 
 type mockBudgetSource struct {
 	estimate BudgetEstimate
+	ratio    float64
 	err      error
 }
 
@@ -61,6 +69,10 @@ func (m *mockBudgetSource) GetBudget(provider string) (BudgetEstimate, error) {
 	return m.estimate, m.err
 }
 
+func (m *mockBudgetSource) TokenRatio() (float64, bool, error) {
+	return m.ratio, m.ratio > 0, m.err
+}
+
 type mockTrendAnalyzer struct {
 	predicted int64
 	err       error
@@ -241,6 +253,164 @@ func TestCalculateAllowance_WeeklyMode(t *testing.T) {
 	}
 }
 
+func TestCalculateAllowance_Pacing(t *testing.T) {
+	// Claude's week is assumed to reset on Sunday, so Sunday has 7 nights
+	// left and Saturday 1. Each night spends its full allowance.
+	sunday := time.Date(2024, 1, 14, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		pacing     string
+		aggressive bool
+		want       []int64 // tokens spent Sunday through Saturday
+		wantSpent  int64
+	}{
+		{
+			name:      "default is greedy",
+			pacing:    "",
+			want:      []int64{100000, 100000, 100000, 100000, 100000, 100000, 100000},
+			wantSpent: 700000,
+		},
+		{
+			// The last night's 2x multiplier asks for more than is left
+			name:       "greedy spends past its share at the end of the week",
+			pacing:     config.PacingGreedy,
+			aggressive: true,
+			want:       []int64{100000, 100000, 100000, 100000, 100000, 100000, 200000},
+			wantSpent:  800000,
+		},
+		{
+			name:       "even never exceeds its share",
+			pacing:     config.PacingEven,
+			aggressive: true,
+			want:       []int64{100000, 100000, 100000, 100000, 100000, 100000, 100000},
+			wantSpent:  700000,
+		},
+		{
+			name:      "front-loaded weights earlier nights",
+			pacing:    config.PacingFrontLoaded,
+			want:      []int64{175000, 150000, 125000, 100000, 75000, 50000, 25000},
+			wantSpent: 700000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Budget: config.BudgetConfig{
+					Mode:                "weekly",
+					WeeklyTokens:        700000,
+					MaxPercent:          100,
+					ReservePercent:      0,
+					AggressiveEndOfWeek: tt.aggressive,
+					Pacing:              tt.pacing,
+				},
+			}
+			claude := &mockClaudeProvider{}
+			mgr := NewManager(cfg, claude, nil, &mockCopilotProvider{})
+
+			var spent int64
+			for night, want := range tt.want {
+				now := sunday.AddDate(0, 0, night)
+				mgr.nowFunc = func() time.Time { return now }
+				claude.usedPercent = float64(spent) / 700000 * 100
+
+				result, err := mgr.CalculateAllowance("claude")
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", now.Weekday(), err)
+				}
+				if diff := result.Allowance - want; diff < -1 || diff > 1 {
+					t.Errorf("%s (%d nights left): allowance = %d, want %d", now.Weekday(), result.RemainingDays, result.Allowance, want)
+				}
+				wantPacing := tt.pacing
+				if wantPacing == "" {
+					wantPacing = config.PacingGreedy
+				}
+				if result.Pacing != wantPacing {
+					t.Errorf("Pacing = %q, want %q", result.Pacing, wantPacing)
+				}
+				spent += result.Allowance
+			}
+			if diff := spent - tt.wantSpent; diff < -10 || diff > 0 {
+				t.Errorf("spent %d over the week, want %d", spent, tt.wantSpent)
+			}
+		})
+	}
+}
+
+func TestCalculateAllowance_AutoMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		now           time.Time
+		dailyUsed     float64
+		weeklyUsed    float64
+		wantBinding   string
+		wantAllowance int64
+		wantUsed      float64
+	}{
+		{
+			// Tuesday, 5 days left. Heavy daytime use today, light week.
+			// daily: 100000 * 0.5 = 50000; weekly: 560000 / 5 = 112000
+			name:          "daily binds",
+			now:           time.Date(2024, 1, 16, 2, 0, 0, 0, time.UTC),
+			dailyUsed:     50,
+			weeklyUsed:    20,
+			wantBinding:   "daily",
+			wantAllowance: 50000,
+			wantUsed:      50,
+		},
+		{
+			// Saturday, 1 day left. Quiet day, but the week is nearly spent.
+			// daily: 100000; weekly: 700000 * 0.05 / 1 = 35000
+			name:          "weekly binds",
+			now:           time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC),
+			dailyUsed:     0,
+			weeklyUsed:    95,
+			wantBinding:   "weekly",
+			wantAllowance: 35000,
+			wantUsed:      95,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Budget: config.BudgetConfig{
+					Mode:           "auto",
+					WeeklyTokens:   700000,
+					MaxPercent:     100,
+					ReservePercent: 0,
+				},
+			}
+			claude := &mockClaudeProvider{byMode: map[string]float64{"daily": tt.dailyUsed, "weekly": tt.weeklyUsed}}
+			mgr := NewManager(cfg, claude, nil, nil)
+			mgr.nowFunc = func() time.Time { return tt.now }
+
+			result, err := mgr.CalculateAllowance("claude")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.BindingWindow != tt.wantBinding {
+				t.Errorf("BindingWindow = %q, want %q", result.BindingWindow, tt.wantBinding)
+			}
+			if result.Mode != tt.wantBinding {
+				t.Errorf("Mode = %q, want %q", result.Mode, tt.wantBinding)
+			}
+			if result.Allowance != tt.wantAllowance {
+				t.Errorf("Allowance = %d, want %d", result.Allowance, tt.wantAllowance)
+			}
+
+			used, err := mgr.GetUsedPercent("claude")
+			if err != nil {
+				t.Fatalf("GetUsedPercent: %v", err)
+			}
+			if used != tt.wantUsed {
+				t.Errorf("GetUsedPercent = %.1f, want %.1f", used, tt.wantUsed)
+			}
+		})
+	}
+}
+
 func TestAggressiveEndOfWeek(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -334,6 +504,180 @@ func TestCalculateAllowance_PredictedUsage(t *testing.T) {
 	}
 }
 
+// mockReservations implements ReservationSource for testing.
+type mockReservations struct {
+	held map[string]int64
+}
+
+func (m *mockReservations) ReservedTokens(provider string, now time.Time) (int64, error) {
+	return m.held[provider], nil
+}
+
+func TestCalculateAllowance_Reservations(t *testing.T) {
+	cfg := &config.Config{
+		Budget: config.BudgetConfig{Mode: "daily", WeeklyTokens: 700000, MaxPercent: 10},
+	}
+	tests := []struct {
+		name string
+		held int64
+		want int64
+	}{
+		{"none", 0, 10000},
+		{"partial", 4000, 6000},
+		{"exceeds allowance", 50000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &mockReservations{held: map[string]int64{"claude": tt.held, "codex": 99999}}
+			mgr := NewManager(cfg, &mockClaudeProvider{}, nil, nil, WithReservations(res))
+			result, err := mgr.CalculateAllowance("claude")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Allowance != tt.want {
+				t.Errorf("allowance = %d, want %d", result.Allowance, tt.want)
+			}
+			if result.HeldTokens != tt.held {
+				t.Errorf("held = %d, want %d", result.HeldTokens, tt.held)
+			}
+		})
+	}
+}
+
+// agedClaudeProvider reports when its usage data last changed.
+type agedClaudeProvider struct {
+	mockClaudeProvider
+	modTime time.Time
+}
+
+func (m *agedClaudeProvider) DataModTime() (time.Time, error) { return m.modTime, nil }
+
+func TestDataAge(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		mode      string
+		modTime   time.Time
+		wantAge   time.Duration
+		wantStale bool
+	}{
+		{"fresh daily", "daily", now.Add(-3 * time.Hour), 3 * time.Hour, false},
+		{"stale daily", "daily", now.Add(-72 * time.Hour), 72 * time.Hour, true},
+		{"auto uses daily window", "auto", now.Add(-30 * time.Hour), 30 * time.Hour, true},
+		{"weekly window", "weekly", now.Add(-72 * time.Hour), 72 * time.Hour, false},
+		{"no data", "daily", time.Time{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Budget: config.BudgetConfig{Mode: tt.mode, WeeklyTokens: 700000}}
+			mgr := NewManager(cfg, &agedClaudeProvider{modTime: tt.modTime}, &mockCodexProvider{}, nil)
+			mgr.nowFunc = func() time.Time { return now }
+
+			age, err := mgr.DataAge("claude")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if age.Age != tt.wantAge || age.Stale != tt.wantStale {
+				t.Errorf("age = %v stale = %v, want %v stale = %v", age.Age, age.Stale, tt.wantAge, tt.wantStale)
+			}
+		})
+	}
+
+	// Providers that don't report a data time are never stale.
+	mgr := NewManager(&config.Config{}, nil, &mockCodexProvider{}, nil)
+	if age, err := mgr.DataAge("codex"); err != nil || age.Stale || !age.ModTime.IsZero() {
+		t.Errorf("codex DataAge = %+v, %v; want zero", age, err)
+	}
+}
+
+// slowClaudeProvider stands in for a session directory on a stale mount:
+// reads block until release is closed.
+type slowClaudeProvider struct {
+	mockClaudeProvider
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (m *slowClaudeProvider) GetUsedPercent(mode string, weeklyBudget int64) (float64, error) {
+	m.calls.Add(1)
+	<-m.release
+	return m.mockClaudeProvider.GetUsedPercent(mode, weeklyBudget)
+}
+
+func TestCalculateAllowance_UsageTimeout(t *testing.T) {
+	cfg := &config.Config{
+		Budget: config.BudgetConfig{Mode: "daily", WeeklyTokens: 700000, MaxPercent: 10},
+	}
+	slow := &slowClaudeProvider{release: make(chan struct{})}
+	codex := &mockCodexProvider{usedPercent: 20}
+	mgr := NewManager(cfg, slow, codex, nil, WithUsageTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := mgr.CalculateAllowance("claude")
+	if !errors.Is(err, providers.ErrUsageTimeout) {
+		t.Fatalf("err = %v, want ErrUsageTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("CalculateAllowance blocked for %v", elapsed)
+	}
+
+	// Other providers are unaffected.
+	if _, err := mgr.CalculateAllowance("codex"); err != nil {
+		t.Fatalf("codex: %v", err)
+	}
+
+	// While the hung read is outstanding, claude is skipped without
+	// starting another one.
+	if _, err := mgr.CalculateAllowance("claude"); !errors.Is(err, providers.ErrUsageTimeout) {
+		t.Fatalf("second read err = %v, want ErrUsageTimeout", err)
+	}
+	if calls := slow.calls.Load(); calls != 1 {
+		t.Errorf("reads started = %d, want 1 while the first is hung", calls)
+	}
+
+	// Once it finishes, claude is read again.
+	close(slow.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := mgr.CalculateAllowance("claude"); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("after release: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Without a timeout the read runs directly.
+	mgr = NewManager(cfg, slow, nil, nil, WithUsageTimeout(0))
+	if _, err := mgr.CalculateAllowance("claude"); err != nil {
+		t.Fatalf("no timeout: %v", err)
+	}
+}
+
+func TestGetResetTime(t *testing.T) {
+	reset := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{Budget: config.BudgetConfig{Mode: "weekly", WeeklyTokens: 700000}}
+	mgr := NewManager(cfg, &mockClaudeProvider{}, &mockCodexProvider{resetTime: reset}, nil)
+
+	got, err := mgr.GetResetTime("codex")
+	if err != nil {
+		t.Fatalf("GetResetTime(codex) error: %v", err)
+	}
+	if !got.Equal(reset) {
+		t.Errorf("GetResetTime(codex) = %v, want %v", got, reset)
+	}
+
+	got, err = mgr.GetResetTime("claude")
+	if err != nil || !got.IsZero() {
+		t.Errorf("GetResetTime(claude) = %v, %v; want zero, nil", got, err)
+	}
+
+	got, err = mgr.GetResetTime("copilot")
+	if err != nil || !got.IsZero() {
+		t.Errorf("GetResetTime(copilot) with nil provider = %v, %v; want zero, nil", got, err)
+	}
+}
+
 func TestDaysUntilWeeklyReset_Claude(t *testing.T) {
 	tests := []struct {
 		dayOfWeek time.Weekday
@@ -524,6 +868,88 @@ func TestBudgetSourceFallbacksToConfig(t *testing.T) {
 	}
 }
 
+func TestMaxDailyShare_SkippedDays(t *testing.T) {
+	// Nothing used yet: each skipped night leaves more of the week for the
+	// nights that remain. Claude's week ends Sunday.
+	tests := []struct {
+		name          string
+		now           time.Time
+		usedPercent   float64
+		share         float64
+		wantAllowance int64
+		wantCap       int64
+	}{
+		{"monday, first night", time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC), 0, 0.3, 116666, 0},
+		{"thursday after three skipped nights", time.Date(2024, 1, 18, 12, 0, 0, 0, time.UTC), 0, 0.3, 210000, 210000},
+		{"saturday after five skipped nights", time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC), 0, 0.3, 210000, 210000},
+		{"saturday after five skipped nights, no cap", time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC), 0, 0, 1400000, 0},
+		{"saturday, mostly spent", time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC), 80, 0.3, 210000, 210000},
+		{"saturday, under the cap", time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC), 87.5, 0.3, 175000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Budget: config.BudgetConfig{
+					Mode:                "weekly",
+					WeeklyTokens:        700000,
+					MaxPercent:          100,
+					ReservePercent:      0,
+					AggressiveEndOfWeek: true,
+					MaxDailyShare:       tt.share,
+				},
+			}
+			mgr := NewManager(cfg, &mockClaudeProvider{usedPercent: tt.usedPercent}, nil, nil)
+			mgr.nowFunc = func() time.Time { return tt.now }
+
+			result, err := mgr.CalculateAllowance("claude")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Allowance != tt.wantAllowance {
+				t.Errorf("allowance = %d, want %d", result.Allowance, tt.wantAllowance)
+			}
+			if result.DailyShareCap != tt.wantCap {
+				t.Errorf("DailyShareCap = %d, want %d", result.DailyShareCap, tt.wantCap)
+			}
+		})
+	}
+}
+
+func TestCheckOverdraft(t *testing.T) {
+	cfg := &config.Config{
+		Budget: config.BudgetConfig{
+			Mode:                "weekly",
+			WeeklyTokens:        700000,
+			MaxPercent:          100,
+			AggressiveEndOfWeek: true,
+		},
+	}
+	// 90% of the week is gone: 70000 tokens left.
+	mgr := NewManager(cfg, &mockClaudeProvider{usedPercent: 90}, nil, nil)
+	mgr.nowFunc = func() time.Time { return time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		tokens  int64
+		wantErr bool
+	}{
+		{0, false},
+		{70000, false},
+		{70001, true},
+		{140000, true}, // what the end-of-week multiplier alone would allow
+	}
+	for _, tt := range tests {
+		err := mgr.CheckOverdraft("claude", tt.tokens)
+		if tt.wantErr != errors.Is(err, ErrOverdraft) {
+			t.Errorf("CheckOverdraft(%d) = %v, wantErr %v", tt.tokens, err, tt.wantErr)
+		}
+	}
+
+	failing := NewManager(cfg, &mockClaudeProvider{err: errors.New("no data")}, nil, nil)
+	if err := failing.CheckOverdraft("claude", 1); err == nil || errors.Is(err, ErrOverdraft) {
+		t.Errorf("CheckOverdraft with unreadable usage = %v, want a usage error", err)
+	}
+}
+
 func TestCanRun(t *testing.T) {
 	cfg := &config.Config{
 		Budget: config.BudgetConfig{
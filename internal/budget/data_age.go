@@ -0,0 +1,55 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+// DataModTimeProvider reports when the data behind GetUsedPercent last
+// changed. Implemented optionally by providers.
+type DataModTimeProvider interface {
+	DataModTime() (time.Time, error)
+}
+
+// DataAge describes how old a provider's usage data is.
+type DataAge struct {
+	ModTime time.Time     // zero when the provider has no usage data yet
+	Age     time.Duration // time since ModTime
+	Window  time.Duration // budget window length the age is judged against
+	Stale   bool          // Age exceeds Window: used-percent no longer reflects the window
+}
+
+// DataAge reports the age of the provider's usage data relative to the
+// budget window: a day in daily and auto mode, a week in weekly mode. A
+// provider that doesn't report its data time returns a zero DataAge.
+func (m *Manager) DataAge(provider string) (DataAge, error) {
+	var src any
+	switch provider {
+	case "claude":
+		src = m.claude
+	case "codex":
+		src = m.codex
+	case "copilot":
+		src = m.copilot
+	}
+	reporter, ok := src.(DataModTimeProvider)
+	if !ok {
+		return DataAge{}, nil
+	}
+	modTime, err := readUsage(m, provider, reporter.DataModTime)
+	if err != nil || modTime.IsZero() {
+		return DataAge{}, err
+	}
+
+	window := 24 * time.Hour
+	mode := m.cfg.Budget.Mode
+	if mode == "" {
+		mode = config.DefaultBudgetMode
+	}
+	if mode == "weekly" {
+		window = 7 * 24 * time.Hour
+	}
+	age := max(0, m.nowFunc().Sub(modTime))
+	return DataAge{ModTime: modTime, Age: age, Window: window, Stale: age > window}, nil
+}
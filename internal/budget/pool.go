@@ -0,0 +1,145 @@
+package budget
+
+import (
+	"fmt"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+// poolMember is one provider's contribution to the pooled budget.
+type poolMember struct {
+	provider   string
+	configured int64   // budget.per_provider (or weekly_tokens) for the provider
+	estimate   int64   // resolved weekly budget, possibly calibrated
+	multiplier float64 // converts the provider's tokens to the pool's basis
+}
+
+// tokenMultiplier converts a provider's tokens to the pool's basis, Claude
+// tokens. ratio is the calibrated Codex/Claude token ratio (Codex tokens per
+// Claude token of work), or 0 when none has been calibrated. Providers other
+// than Codex, and Codex without a ratio, count one for one.
+func tokenMultiplier(provider string, ratio float64) float64 {
+	if provider != "codex" || ratio <= 0 {
+		return 1
+	}
+	return 1 / ratio
+}
+
+// normalizeUsage returns the tokens a provider has used in the window,
+// converted to the pool's common basis.
+func normalizeUsage(usedPercent float64, estimate int64, multiplier float64) float64 {
+	return usedPercent / 100 * float64(estimate) * multiplier
+}
+
+// pooled reports whether budget.pooling gates providers on one shared pool.
+func (m *Manager) pooled() bool {
+	return m.cfg.GetBudgetPooling() == config.PoolingPooled
+}
+
+// poolWeeklyTokens is the single weekly budget the pool gates on.
+func (m *Manager) poolWeeklyTokens() int64 {
+	if m.cfg.Budget.WeeklyTokens > 0 {
+		return int64(m.cfg.Budget.WeeklyTokens)
+	}
+	return config.DefaultWeeklyTokens
+}
+
+// poolMembers returns the enabled providers that have usage data.
+func (m *Manager) poolMembers() ([]poolMember, error) {
+	candidates := []struct {
+		name      string
+		enabled   bool
+		available bool
+	}{
+		{"claude", m.cfg.Providers.Claude.Enabled, m.claude != nil},
+		{"codex", m.cfg.Providers.Codex.Enabled, m.codex != nil},
+		{"copilot", m.cfg.Providers.Copilot.Enabled, m.copilot != nil},
+	}
+	ratio, _, err := m.TokenRatio()
+	if err != nil {
+		return nil, err
+	}
+	var members []poolMember
+	for _, c := range candidates {
+		if !c.enabled || !c.available {
+			continue
+		}
+		estimate, err := m.resolveBudget(c.name)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, poolMember{
+			provider:   c.name,
+			configured: int64(m.cfg.GetProviderBudget(c.name)),
+			estimate:   estimate.WeeklyTokens,
+			multiplier: tokenMultiplier(c.name, ratio),
+		})
+	}
+	return members, nil
+}
+
+// pooledUsedPercent sums every pool member's normalized usage in the window
+// and returns it as a percentage of the pool.
+func (m *Manager) pooledUsedPercent(window string) (float64, error) {
+	members, err := m.poolMembers()
+	if err != nil {
+		return 0, err
+	}
+	var used float64
+	for _, member := range members {
+		pct, err := m.usedPercentForMode(member.provider, window, member.estimate)
+		if err != nil {
+			return 0, fmt.Errorf("getting used percent for %s: %w", member.provider, err)
+		}
+		used += normalizeUsage(pct, member.estimate, member.multiplier)
+	}
+	return used / float64(m.poolWeeklyTokens()) * 100, nil
+}
+
+// poolShare converts a pool allowance to provider's share in its own tokens.
+// Each member's share is weighted by its configured budget in the pool's
+// basis, so a provider with twice the configured budget may spend twice as
+// much of the pool.
+func (m *Manager) poolShare(provider string, poolAllowance int64) (int64, error) {
+	members, err := m.poolMembers()
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	var self *poolMember
+	for i := range members {
+		total += float64(members[i].configured) * members[i].multiplier
+		if members[i].provider == provider {
+			self = &members[i]
+		}
+	}
+	if self == nil || total <= 0 {
+		// Not a pool member (e.g. disabled): it gets the whole pool.
+		return poolAllowance, nil
+	}
+	share := float64(poolAllowance) * float64(self.configured) * self.multiplier / total
+	return int64(share / self.multiplier), nil
+}
+
+// checkPoolOverdraft is CheckOverdraft in pooled mode: tokens, in
+// provider's own tokens, must fit its share of the pool's remaining week.
+func (m *Manager) checkPoolOverdraft(provider string, tokens int64) error {
+	usedPercent, err := m.pooledUsedPercent("weekly")
+	if err != nil {
+		return fmt.Errorf("pooled budget: %w", err)
+	}
+	weeklyBudget := m.poolWeeklyTokens()
+	remaining := weeklyBudget - int64(float64(weeklyBudget)*usedPercent/100)
+	if remaining < 0 {
+		remaining = 0
+	}
+	share, err := m.poolShare(provider, remaining)
+	if err != nil {
+		return fmt.Errorf("pooled budget: %w", err)
+	}
+	if tokens > share {
+		return fmt.Errorf("%s: reserving %d tokens with a %d-token share of the pool's %d left this week %w",
+			provider, tokens, share, remaining, ErrOverdraft)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package budget
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+func TestNormalizeUsage(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		ratio       float64
+		estimate    int64
+		usedPercent float64
+		want        float64
+	}{
+		{"claude", "claude", 2, 700000, 50, 350000},
+		{"codex uncalibrated", "codex", 0, 1400000, 50, 700000},
+		{"codex calibrated", "codex", 2, 1400000, 50, 350000},
+		{"codex calibrated below claude", "codex", 0.5, 500000, 20, 200000},
+		{"copilot ignores ratio", "copilot", 2, 500000, 20, 100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeUsage(tt.usedPercent, tt.estimate, tokenMultiplier(tt.provider, tt.ratio))
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("normalizeUsage = %.0f, want %.0f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateAllowance_Pooled(t *testing.T) {
+	newCfg := func(pooling string) *config.Config {
+		cfg := &config.Config{
+			Budget: config.BudgetConfig{
+				Mode:         "daily",
+				WeeklyTokens: 700000,
+				MaxPercent:   100,
+				Pooling:      pooling,
+				PerProvider:  map[string]int{"codex": 1400000},
+			},
+		}
+		cfg.Providers.Claude.Enabled = true
+		cfg.Providers.Codex.Enabled = true
+		return cfg
+	}
+	claude := &mockClaudeProvider{usedPercent: 50} // 350k of 700k
+	codex := &mockCodexProvider{usedPercent: 10}   // 140k of 1.4M
+
+	t.Run("per-provider", func(t *testing.T) {
+		mgr := NewManager(newCfg(""), claude, codex, nil)
+		result, err := mgr.CalculateAllowance("claude")
+		if err != nil {
+			t.Fatalf("CalculateAllowance: %v", err)
+		}
+		// 700000/7 * 50% available = 50000
+		if result.Allowance != 50000 || result.Pooled {
+			t.Errorf("Allowance = %d (pooled %v), want 50000 per-provider", result.Allowance, result.Pooled)
+		}
+	})
+
+	t.Run("pooled", func(t *testing.T) {
+		mgr := NewManager(newCfg("pooled"), claude, codex, nil)
+		// Pool: 490k of 700k used = 70%; daily 100000 * 30% = 30000.
+		// Shares weight configured budgets: claude 1/3, codex 2/3.
+		for provider, want := range map[string]int64{"claude": 10000, "codex": 20000} {
+			result, err := mgr.CalculateAllowance(provider)
+			if err != nil {
+				t.Fatalf("CalculateAllowance(%s): %v", provider, err)
+			}
+			if !result.Pooled {
+				t.Errorf("%s: Pooled = false", provider)
+			}
+			if math.Abs(result.UsedPercent-70) > 0.01 {
+				t.Errorf("%s: UsedPercent = %.2f, want 70", provider, result.UsedPercent)
+			}
+			if result.WeeklyBudget != 700000 {
+				t.Errorf("%s: WeeklyBudget = %d, want pool 700000", provider, result.WeeklyBudget)
+			}
+			if result.Allowance != want {
+				t.Errorf("%s: Allowance = %d, want %d", provider, result.Allowance, want)
+			}
+		}
+	})
+
+	t.Run("pooled exhausted", func(t *testing.T) {
+		busy := &mockCodexProvider{usedPercent: 30} // 420k + claude 350k > 700k pool
+		mgr := NewManager(newCfg("pooled"), claude, busy, nil)
+		result, err := mgr.CalculateAllowance("claude")
+		if err != nil {
+			t.Fatalf("CalculateAllowance: %v", err)
+		}
+		if result.Allowance != 0 {
+			t.Errorf("Allowance = %d, want 0 once the pool is spent", result.Allowance)
+		}
+	})
+
+	t.Run("pooled calibrated", func(t *testing.T) {
+		// Codex needs 2x the tokens for the same work: its 140k count as
+		// 70k Claude tokens, so the pool is 60% used instead of 70%.
+		source := &mockBudgetSource{ratio: 2}
+		mgr := NewManager(newCfg("pooled"), claude, codex, nil, WithBudgetSource(source))
+		// Daily 100000 * 40% = 40000. Codex's 1.4M budget weighs 700k in
+		// Claude tokens, so the pool splits evenly: 20000 each, which is
+		// 40000 of codex's own tokens.
+		for provider, want := range map[string]int64{"claude": 20000, "codex": 40000} {
+			result, err := mgr.CalculateAllowance(provider)
+			if err != nil {
+				t.Fatalf("CalculateAllowance(%s): %v", provider, err)
+			}
+			if math.Abs(result.UsedPercent-60) > 0.01 {
+				t.Errorf("%s: UsedPercent = %.2f, want 60", provider, result.UsedPercent)
+			}
+			if result.Allowance != want {
+				t.Errorf("%s: Allowance = %d, want %d", provider, result.Allowance, want)
+			}
+		}
+	})
+}
+
+func TestCheckOverdraft_Pooled(t *testing.T) {
+	cfg := &config.Config{
+		Budget: config.BudgetConfig{
+			Mode:         "weekly",
+			WeeklyTokens: 700000,
+			MaxPercent:   100,
+			Pooling:      "pooled",
+			PerProvider:  map[string]int{"codex": 1400000},
+		},
+	}
+	cfg.Providers.Claude.Enabled = true
+	cfg.Providers.Codex.Enabled = true
+	claude := &mockClaudeProvider{usedPercent: 50} // 350k of 700k
+	codex := &mockCodexProvider{usedPercent: 10}   // 140k of 1.4M
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		source   BudgetSource
+		provider string
+		tokens   int64
+		wantErr  bool
+	}{
+		// 210k of the pool is left: claude's share is 1/3, codex's 2/3
+		{name: "claude within share", cfg: cfg, provider: "claude", tokens: 70000},
+		{name: "claude over share", cfg: cfg, provider: "claude", tokens: 70001, wantErr: true},
+		{name: "codex within share", cfg: cfg, provider: "codex", tokens: 140000},
+		{name: "codex over share", cfg: cfg, provider: "codex", tokens: 140001, wantErr: true},
+		// With a 2x Codex/Claude ratio codex's usage counts as 70k: 280k is
+		// left, split evenly, which is 280k of codex's own tokens
+		{name: "calibrated claude within share", cfg: cfg, source: &mockBudgetSource{ratio: 2}, provider: "claude", tokens: 140000},
+		{name: "calibrated claude over share", cfg: cfg, source: &mockBudgetSource{ratio: 2}, provider: "claude", tokens: 140001, wantErr: true},
+		{name: "calibrated codex within share", cfg: cfg, source: &mockBudgetSource{ratio: 2}, provider: "codex", tokens: 280000},
+		{name: "calibrated codex over share", cfg: cfg, source: &mockBudgetSource{ratio: 2}, provider: "codex", tokens: 280001, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.source != nil {
+				opts = append(opts, WithBudgetSource(tt.source))
+			}
+			mgr := NewManager(tt.cfg, claude, codex, nil, opts...)
+			err := mgr.CheckOverdraft(tt.provider, tt.tokens)
+			if tt.wantErr != errors.Is(err, ErrOverdraft) {
+				t.Errorf("CheckOverdraft(%s, %d) = %v, wantErr %v", tt.provider, tt.tokens, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// UsageCache keeps computed used-percent and reset values on disk for a
+// short TTL, so back-to-back commands (status, preview, run) don't re-scan
+// provider session files. Entries are keyed by provider and a stamp of the
+// provider's data (the newest mtime of the files usage is read from): new
+// agent activity changes the stamp and invalidates them before the TTL
+// expires. The stamp is taken on every lookup, so a run sees the tokens its
+// own earlier tasks spent.
+type UsageCache struct {
+	path  string
+	ttl   time.Duration
+	stamp func(provider string) string
+	now   func() time.Time
+
+	mu      sync.Mutex
+	loaded  bool
+	dirty   bool
+	entries map[string]usageCacheEntry
+}
+
+type usageCacheEntry struct {
+	Stamp       string    `json:"stamp"`
+	Stored      time.Time `json:"stored"`
+	UsedPercent float64   `json:"used_percent,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	Reset       time.Time `json:"reset,omitempty"`
+}
+
+// NewUsageCache returns a cache stored at path. stamp identifies the state
+// of a provider's data; a nil stamp relies on the TTL alone.
+func NewUsageCache(path string, ttl time.Duration, stamp func(provider string) string) *UsageCache {
+	return &UsageCache{
+		path:  path,
+		ttl:   ttl,
+		stamp: stamp,
+		now:   time.Now,
+	}
+}
+
+// WithUsageCache reuses provider usage values from cache. A nil cache leaves
+// every lookup live.
+func WithUsageCache(cache *UsageCache) Option {
+	return func(m *Manager) {
+		m.cache = cache
+	}
+}
+
+func (c *UsageCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = map[string]usageCacheEntry{}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+	// A corrupt cache is treated as empty and rewritten on Save.
+	_ = json.Unmarshal(data, &c.entries)
+}
+
+func (c *UsageCache) providerStamp(provider string) string {
+	if c.stamp == nil {
+		return ""
+	}
+	return c.stamp(provider)
+}
+
+// get returns a fresh entry for key, and the provider's current stamp to
+// store with a recomputed value. The stamp is taken before the lock: it
+// reads the filesystem.
+func (c *UsageCache) get(provider, key string) (usageCacheEntry, string, bool) {
+	stamp := c.providerStamp(provider)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.Stored) > c.ttl || entry.Stamp != stamp {
+		return usageCacheEntry{}, stamp, false
+	}
+	return entry, stamp, true
+}
+
+// put stores entry under key with the stamp get returned before the value
+// was computed, so activity during the read invalidates it.
+func (c *UsageCache) put(key, stamp string, entry usageCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	entry.Stamp = stamp
+	entry.Stored = c.now()
+	c.entries[key] = entry
+	c.dirty = true
+}
+
+// Save writes new entries to disk, dropping expired ones.
+func (c *UsageCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	for key, entry := range c.entries {
+		if c.now().Sub(entry.Stored) > c.ttl {
+			delete(c.entries, key)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("encoding usage cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("creating usage cache dir: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("writing usage cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// SaveCache persists the manager's usage cache, if any.
+func (m *Manager) SaveCache() error {
+	return m.cache.Save()
+}
+
+// cachedUsedPercent returns the provider's used percent for the window,
+// from cache when fresh. Live reads are bounded by the usage timeout.
+func (m *Manager) cachedUsedPercent(provider, mode string, weeklyBudget int64, read func() (float64, error)) (float64, error) {
+	compute := func() (float64, error) { return readUsage(m, provider, read) }
+	if m.cache == nil {
+		return compute()
+	}
+	key := fmt.Sprintf("%s/used/%s/%d", provider, mode, weeklyBudget)
+	entry, stamp, ok := m.cache.get(provider, key)
+	if ok {
+		m.cachedSources[provider] = entry.Source
+		return entry.UsedPercent, nil
+	}
+	pct, err := compute()
+	if err != nil {
+		return pct, err
+	}
+	delete(m.cachedSources, provider)
+	m.cache.put(key, stamp, usageCacheEntry{UsedPercent: pct, Source: m.usedPercentSource(provider)})
+	return pct, nil
+}
+
+// cachedResetTime returns the provider's reset time for the window, from
+// cache when fresh. Live reads are bounded by the usage timeout.
+func (m *Manager) cachedResetTime(provider, mode string, read func() (time.Time, error)) (time.Time, error) {
+	compute := func() (time.Time, error) { return readUsage(m, provider, read) }
+	if m.cache == nil {
+		return compute()
+	}
+	key := fmt.Sprintf("%s/reset/%s", provider, mode)
+	entry, stamp, ok := m.cache.get(provider, key)
+	if ok {
+		return entry.Reset, nil
+	}
+	reset, err := compute()
+	if err != nil {
+		return reset, err
+	}
+	m.cache.put(key, stamp, usageCacheEntry{Reset: reset})
+	return reset, nil
+}
@@ -0,0 +1,87 @@
+package budget
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/config"
+)
+
+// countingClaude counts GetUsedPercent calls.
+type countingClaude struct {
+	mockClaudeProvider
+	calls int
+}
+
+func (c *countingClaude) GetUsedPercent(mode string, weeklyBudget int64) (float64, error) {
+	c.calls++
+	return c.mockClaudeProvider.GetUsedPercent(mode, weeklyBudget)
+}
+
+func TestUsageCache(t *testing.T) {
+	cfg := &config.Config{Budget: config.BudgetConfig{Mode: "daily", WeeklyTokens: 700000, MaxPercent: 75}}
+	path := filepath.Join(t.TempDir(), "usage.json")
+	stamp := "v1"
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	newCache := func() *UsageCache {
+		c := NewUsageCache(path, 3*time.Minute, func(string) string { return stamp })
+		c.now = func() time.Time { return now }
+		return c
+	}
+
+	// usedPercent reports the percent a fresh manager sees and how many
+	// times the provider was scanned.
+	claude := &countingClaude{mockClaudeProvider: mockClaudeProvider{usedPercent: 40, source: "stats-cache"}}
+	usedPercent := func(cache *UsageCache) (float64, string) {
+		t.Helper()
+		mgr := NewManager(cfg, claude, nil, nil, WithUsageCache(cache))
+		result, err := mgr.CalculateAllowance("claude")
+		if err != nil {
+			t.Fatalf("CalculateAllowance: %v", err)
+		}
+		if err := mgr.SaveCache(); err != nil {
+			t.Fatalf("SaveCache: %v", err)
+		}
+		return result.UsedPercent, result.UsedPercentSource
+	}
+
+	usedPercent(newCache())
+	if claude.calls != 1 {
+		t.Fatalf("first command: calls = %d, want 1", claude.calls)
+	}
+
+	// A second command within the TTL reuses the saved value and source.
+	claude.usedPercent = 90
+	if pct, source := usedPercent(newCache()); pct != 40 || source != "stats-cache" || claude.calls != 1 {
+		t.Errorf("cached: pct=%.0f source=%q calls=%d, want 40 stats-cache 1", pct, source, claude.calls)
+	}
+
+	// New provider activity changes the stamp and forces a re-scan.
+	stamp = "v2"
+	if pct, _ := usedPercent(newCache()); pct != 90 || claude.calls != 2 {
+		t.Errorf("new stamp: pct=%.0f calls=%d, want 90 2", pct, claude.calls)
+	}
+
+	// Activity within one process (a run's own tasks) is seen too.
+	cache := newCache()
+	usedPercent(cache)
+	claude.usedPercent = 95
+	stamp = "v3"
+	if pct, _ := usedPercent(cache); pct != 95 || claude.calls != 3 {
+		t.Errorf("same process, new stamp: pct=%.0f calls=%d, want 95 3", pct, claude.calls)
+	}
+
+	// Entries expire after the TTL.
+	claude.usedPercent = 10
+	now = now.Add(4 * time.Minute)
+	if pct, _ := usedPercent(newCache()); pct != 10 || claude.calls != 4 {
+		t.Errorf("expired: pct=%.0f calls=%d, want 10 4", pct, claude.calls)
+	}
+
+	// No cache (--fresh) always scans.
+	usedPercent(nil)
+	if claude.calls != 5 {
+		t.Errorf("no cache: calls = %d, want 5", claude.calls)
+	}
+}
@@ -0,0 +1,141 @@
+package calibrator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+)
+
+// RatioWindow is how far back RecalibrateRatio looks for session transcripts.
+const RatioWindow = 14 * 24 * time.Hour
+
+// MinRatioSessions is the fewest sessions each provider needs in the window
+// before RecalibrateRatio replaces the stored ratio, the same bar
+// provider-calibration warns below.
+const MinRatioSessions = 10
+
+// RatioChangeThreshold is the relative change above which a recalibrated
+// ratio is worth logging.
+const RatioChangeThreshold = 0.10
+
+// TokenRatioCalibration is the Codex/Claude token multiplier: how many Codex
+// tokens do the work of one Claude token. It is the median Codex primary
+// tokens per user turn over the median Claude raw tokens per user turn.
+type TokenRatioCalibration struct {
+	Ratio          float64
+	CodexSessions  int
+	ClaudeSessions int
+	UpdatedAt      time.Time
+}
+
+// RatioUpdate describes one RecalibrateRatio pass.
+type RatioUpdate struct {
+	Previous    float64               // Stored ratio before the pass, 0 if none
+	Current     TokenRatioCalibration // Ratio computed over the window
+	Updated     bool                  // The stored ratio was replaced
+	Significant bool                  // The ratio moved by more than RatioChangeThreshold
+}
+
+// PerTurnRatio returns the Codex/Claude token multiplier for the given
+// sessions, or 0 when either side has none.
+func PerTurnRatio(codex, claude []SessionMetrics) float64 {
+	return SafeRatio(CalcStats(PerUserTurn(codex, false)).Median, CalcStats(PerUserTurn(claude, true)).Median)
+}
+
+// RecalibrateRatio recomputes the Codex/Claude token multiplier from the
+// interactive sessions written in the RatioWindow before now and stores it
+// for TokenRatio. Nightshift's own agent sessions are left out. The stored
+// ratio is kept when either provider has fewer than MinRatioSessions
+// sessions, so a quiet stretch doesn't swing the multiplier.
+func (c *Calibrator) RecalibrateRatio(now time.Time) (RatioUpdate, error) {
+	if c == nil || c.db == nil || c.cfg == nil {
+		return RatioUpdate{}, errors.New("calibrator not initialized")
+	}
+
+	var update RatioUpdate
+	previous, ok, err := c.StoredTokenRatio()
+	if err != nil {
+		return update, err
+	}
+	if ok {
+		update.Previous = previous.Ratio
+	}
+
+	filter := SessionFilter{
+		ExcludeOriginator: agents.Originator,
+		MinUserTurns:      1,
+		Since:             now.Add(-RatioWindow),
+	}
+	codex, _, _, err := CollectCodexSessions(filepath.Join(c.cfg.ExpandedProviderPath("codex"), "sessions"), filter)
+	if err != nil {
+		return update, fmt.Errorf("codex sessions: %w", err)
+	}
+	claude, _, err := CollectClaudeSessions(filepath.Join(c.cfg.ExpandedProviderPath("claude"), "projects"), filter)
+	if err != nil {
+		return update, fmt.Errorf("claude sessions: %w", err)
+	}
+	update.Current = TokenRatioCalibration{
+		Ratio:          PerTurnRatio(codex, claude),
+		CodexSessions:  len(codex),
+		ClaudeSessions: len(claude),
+		UpdatedAt:      now,
+	}
+	if len(codex) < MinRatioSessions || len(claude) < MinRatioSessions || update.Current.Ratio <= 0 {
+		return update, nil
+	}
+
+	if _, err := c.db.SQL().Exec(
+		`INSERT INTO token_ratios (provider, ratio, sessions, claude_sessions, updated_at)
+		 VALUES ('codex', ?, ?, ?, ?)
+		 ON CONFLICT(provider) DO UPDATE SET
+		   ratio = excluded.ratio,
+		   sessions = excluded.sessions,
+		   claude_sessions = excluded.claude_sessions,
+		   updated_at = excluded.updated_at`,
+		update.Current.Ratio,
+		update.Current.CodexSessions,
+		update.Current.ClaudeSessions,
+		now,
+	); err != nil {
+		return update, fmt.Errorf("store token ratio: %w", err)
+	}
+	update.Updated = true
+	update.Significant = update.Previous == 0 ||
+		math.Abs(update.Current.Ratio-update.Previous)/update.Previous > RatioChangeThreshold
+	return update, nil
+}
+
+// StoredTokenRatio returns the ratio RecalibrateRatio last stored.
+func (c *Calibrator) StoredTokenRatio() (TokenRatioCalibration, bool, error) {
+	if c == nil || c.db == nil {
+		return TokenRatioCalibration{}, false, nil
+	}
+	var r TokenRatioCalibration
+	err := c.db.SQL().QueryRow(
+		`SELECT ratio, sessions, claude_sessions, updated_at
+		 FROM token_ratios
+		 WHERE provider = 'codex'`,
+	).Scan(&r.Ratio, &r.CodexSessions, &r.ClaudeSessions, &r.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return TokenRatioCalibration{}, false, nil
+	}
+	if err != nil {
+		return TokenRatioCalibration{}, false, fmt.Errorf("query token ratio: %w", err)
+	}
+	return r, true, nil
+}
+
+// TokenRatio returns the stored Codex/Claude token multiplier for the budget
+// manager, and false when none has been calibrated yet.
+func (c *Calibrator) TokenRatio() (float64, bool, error) {
+	r, ok, err := c.StoredTokenRatio()
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return r.Ratio, true, nil
+}
@@ -0,0 +1,107 @@
+package calibrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/config"
+)
+
+// writeSessions writes n Codex and n Claude transcripts of one user turn
+// each: codexTokens billable Codex tokens and claudeTokens raw Claude tokens.
+func writeSessions(t *testing.T, cfg *config.Config, prefix string, n int, codexTokens, claudeTokens int64, mtime time.Time) {
+	t.Helper()
+	codexDir := filepath.Join(cfg.Providers.Codex.DataPath, "sessions", "2026", "10", "17")
+	claudeDir := filepath.Join(cfg.Providers.Claude.DataPath, "projects", "app")
+	for i := range n {
+		codex := fmt.Sprintf(`{"type":"session_meta","payload":{"originator":"codex_cli_rs","cwd":"/src/app"}}
+{"type":"event_msg","payload":{"type":"user_message"}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":%d,"cached_input_tokens":0,"output_tokens":0,"reasoning_output_tokens":0}}}}
+`, codexTokens)
+		claude := fmt.Sprintf(`{"type":"user","cwd":"/src/app","message":{"role":"user","content":"fix the tests"}}
+{"type":"assistant","message":{"role":"assistant","usage":{"input_tokens":%d,"output_tokens":0,"cache_read_input_tokens":%d,"cache_creation_input_tokens":0}}}
+`, claudeTokens/2, claudeTokens-claudeTokens/2)
+		for path, body := range map[string]string{
+			filepath.Join(codexDir, fmt.Sprintf("%s-%d.jsonl", prefix, i)):  codex,
+			filepath.Join(claudeDir, fmt.Sprintf("%s-%d.jsonl", prefix, i)): claude,
+		} {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRecalibrateRatio(t *testing.T) {
+	data := t.TempDir()
+	cfg := &config.Config{Providers: config.ProvidersConfig{
+		Claude: config.ProviderConfig{Enabled: true, DataPath: filepath.Join(data, "claude")},
+		Codex:  config.ProviderConfig{Enabled: true, DataPath: filepath.Join(data, "codex")},
+	}}
+	cal, _ := newTestCalibrator(t, cfg)
+	now := time.Now()
+
+	// Below the sample minimum nothing is stored.
+	writeSessions(t, cfg, "a", MinRatioSessions-1, 2000, 1000, now.Add(-time.Hour))
+	update, err := cal.RecalibrateRatio(now)
+	if err != nil {
+		t.Fatalf("RecalibrateRatio: %v", err)
+	}
+	if update.Updated || update.Current.CodexSessions != MinRatioSessions-1 {
+		t.Fatalf("recalibrated below the minimum: %+v", update)
+	}
+	if _, ok, _ := cal.TokenRatio(); ok {
+		t.Fatal("ratio stored below the sample minimum")
+	}
+
+	// Reaching the minimum stores the per-turn median ratio.
+	writeSessions(t, cfg, "b", 1, 2000, 1000, now.Add(-time.Hour))
+	update, err = cal.RecalibrateRatio(now)
+	if err != nil {
+		t.Fatalf("RecalibrateRatio: %v", err)
+	}
+	if !update.Updated || !update.Significant || update.Previous != 0 || update.Current.Ratio != 2 {
+		t.Fatalf("first recalibration = %+v", update)
+	}
+	if ratio, ok, err := cal.TokenRatio(); err != nil || !ok || ratio != 2 {
+		t.Fatalf("TokenRatio = %v, %v, %v; want 2", ratio, ok, err)
+	}
+
+	// Nightshift's own sessions and sessions outside the window don't count.
+	writeSessions(t, cfg, "old", 30, 9000, 1000, now.Add(-RatioWindow-time.Hour))
+	nightshift := filepath.Join(cfg.Providers.Codex.DataPath, "sessions", "2026", "10", "17", "agent.jsonl")
+	body := `{"type":"session_meta","payload":{"originator":"` + agents.Originator + `","cwd":"/src/app"}}
+{"type":"event_msg","payload":{"type":"user_message"}}
+{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":90000,"cached_input_tokens":0,"output_tokens":0,"reasoning_output_tokens":0}}}}
+`
+	if err := os.WriteFile(nightshift, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	update, err = cal.RecalibrateRatio(now)
+	if err != nil {
+		t.Fatalf("RecalibrateRatio: %v", err)
+	}
+	if update.Current.CodexSessions != MinRatioSessions || update.Current.Ratio != 2 || update.Significant {
+		t.Fatalf("recalibration with old and agent sessions = %+v", update)
+	}
+
+	// A real shift is significant.
+	writeSessions(t, cfg, "c", 2*MinRatioSessions, 3000, 1000, now.Add(-time.Minute))
+	update, err = cal.RecalibrateRatio(now)
+	if err != nil {
+		t.Fatalf("RecalibrateRatio: %v", err)
+	}
+	if !update.Updated || !update.Significant || update.Previous != 2 || update.Current.Ratio != 3 {
+		t.Fatalf("shifted recalibration = %+v", update)
+	}
+}
@@ -0,0 +1,465 @@
+package calibrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/orchestrator"
+)
+
+// SessionMetrics is the token usage of one Codex or Claude session
+// transcript.
+type SessionMetrics struct {
+	Provider       string `json:"provider"`
+	File           string `json:"file"`
+	CWD            string `json:"cwd,omitempty"`
+	Originator     string `json:"originator,omitempty"`
+	TokensPrimary  int64  `json:"tokens_primary"`
+	TokensAlt      int64  `json:"tokens_alt"`
+	UserTurns      int64  `json:"user_turns"`
+	AssistantTurns int64  `json:"assistant_turns"`
+}
+
+// SessionFilter selects the session transcripts that are measured.
+type SessionFilter struct {
+	Repo            string // keep only sessions whose cwd is this path ("" keeps all)
+	CodexOriginator string // keep only Codex sessions with this originator ("" keeps all)
+	// ExcludeOriginator drops sessions with this originator, e.g.
+	// nightshift's own agent runs ("" keeps all).
+	ExcludeOriginator string
+	ExcludeCWD        []string  // drop sessions whose cwd is at or under one of these
+	MinUserTurns      int       // drop sessions with fewer user turns
+	Since             time.Time // drop transcripts last written before this (zero keeps all)
+}
+
+func (f SessionFilter) excludes(originator, cwd string) bool {
+	if f.ExcludeOriginator != "" && originator == f.ExcludeOriginator {
+		return true
+	}
+	for _, prefix := range f.ExcludeCWD {
+		if cwd == prefix || strings.HasPrefix(cwd, prefix+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// stale reports whether a transcript was last written before f.Since.
+func (f SessionFilter) stale(d os.DirEntry) bool {
+	if f.Since.IsZero() {
+		return false
+	}
+	info, err := d.Info()
+	return err != nil || info.ModTime().Before(f.Since)
+}
+
+type codexTokenUsage struct {
+	InputTokens           int64 `json:"input_tokens"`
+	CachedInputTokens     int64 `json:"cached_input_tokens"`
+	OutputTokens          int64 `json:"output_tokens"`
+	ReasoningOutputTokens int64 `json:"reasoning_output_tokens"`
+}
+
+// CollectCodexSessions measures the Codex transcripts under root (e.g.
+// ~/.codex/sessions). It also returns how many kept sessions each
+// originator started and how many sessions the filter's exclusions dropped.
+func CollectCodexSessions(root string, filter SessionFilter) ([]SessionMetrics, map[string]int, int, error) {
+	var sessions []SessionMetrics
+	originators := map[string]int{}
+	excluded := 0
+	originatorFilter := strings.TrimSpace(filter.CodexOriginator)
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") || filter.stale(d) {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer func() { _ = f.Close() }()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+
+		originator := ""
+		cwd := ""
+		userTurns := int64(0)
+		assistantTurns := int64(0)
+		var first, latest *codexTokenUsage
+		eventCount := 0
+
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			var base struct {
+				Type    string          `json:"type"`
+				Payload json.RawMessage `json:"payload"`
+			}
+			if err := json.Unmarshal(line, &base); err != nil {
+				continue
+			}
+
+			switch base.Type {
+			case "session_meta":
+				var p struct {
+					Originator string `json:"originator"`
+					CWD        string `json:"cwd"`
+				}
+				if err := json.Unmarshal(base.Payload, &p); err == nil {
+					originator = strings.TrimSpace(p.Originator)
+					cwd = normalizePath(p.CWD)
+				}
+			case "event_msg":
+				var p struct {
+					Type string `json:"type"`
+					Info *struct {
+						Total *codexTokenUsage `json:"total_token_usage"`
+					} `json:"info"`
+				}
+				if err := json.Unmarshal(base.Payload, &p); err != nil {
+					continue
+				}
+				if p.Type == "token_count" && p.Info != nil && p.Info.Total != nil {
+					u := *p.Info.Total
+					if first == nil {
+						first = &u
+					}
+					latest = &u
+					eventCount++
+				}
+				if p.Type == "user_message" {
+					userTurns++
+				}
+			case "response_item":
+				var p struct {
+					Type string `json:"type"`
+					Role string `json:"role"`
+				}
+				if err := json.Unmarshal(base.Payload, &p); err != nil {
+					continue
+				}
+				if p.Type == "message" && p.Role == "assistant" {
+					assistantTurns++
+				}
+			}
+		}
+		if scanErr := scanner.Err(); scanErr != nil && scanErr != io.EOF {
+			return nil
+		}
+
+		if latest == nil {
+			return nil
+		}
+		if filter.excludes(originator, cwd) {
+			excluded++
+			return nil
+		}
+		if originatorFilter != "" && originator != originatorFilter {
+			return nil
+		}
+		if filter.Repo != "" && cwd != filter.Repo {
+			return nil
+		}
+		if int(userTurns) < filter.MinUserTurns {
+			return nil
+		}
+
+		src := *latest
+		if eventCount > 1 && first != nil {
+			delta := codexTokenUsage{
+				InputTokens:           latest.InputTokens - first.InputTokens,
+				CachedInputTokens:     latest.CachedInputTokens - first.CachedInputTokens,
+				OutputTokens:          latest.OutputTokens - first.OutputTokens,
+				ReasoningOutputTokens: latest.ReasoningOutputTokens - first.ReasoningOutputTokens,
+			}
+			if delta.InputTokens >= 0 && delta.CachedInputTokens >= 0 && delta.OutputTokens >= 0 && delta.ReasoningOutputTokens >= 0 {
+				src = delta
+			}
+		}
+
+		input := nonNegative(src.InputTokens)
+		cached := nonNegative(src.CachedInputTokens)
+		output := nonNegative(src.OutputTokens)
+		reasoning := nonNegative(src.ReasoningOutputTokens)
+
+		primary := nonNegative(input-cached) + output + reasoning
+		alt := input + cached + output + reasoning
+		if primary <= 0 {
+			return nil
+		}
+
+		originators[originator]++
+		sessions = append(sessions, SessionMetrics{
+			Provider:       "codex",
+			File:           path,
+			CWD:            cwd,
+			Originator:     originator,
+			TokensPrimary:  primary,
+			TokensAlt:      alt,
+			UserTurns:      userTurns,
+			AssistantTurns: assistantTurns,
+		})
+		return nil
+	})
+
+	return sessions, originators, excluded, err
+}
+
+// CollectClaudeSessions measures the Claude transcripts under root (e.g.
+// ~/.claude/projects). It also returns how many sessions the filter's
+// exclusions dropped.
+func CollectClaudeSessions(root string, filter SessionFilter) ([]SessionMetrics, int, error) {
+	var sessions []SessionMetrics
+	excluded := 0
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".jsonl") || filter.stale(d) {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer func() { _ = f.Close() }()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 1024*1024), 16*1024*1024)
+
+		userTurns := int64(0)
+		assistantTurns := int64(0)
+		primary := int64(0)
+		alt := int64(0)
+		cwd := ""
+		originator := ""
+		sawPrompt := false
+
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			var entry struct {
+				Type    string `json:"type"`
+				CWD     string `json:"cwd"`
+				Message *struct {
+					Role    string          `json:"role"`
+					Content json.RawMessage `json:"content"`
+					Usage   *struct {
+						InputTokens              int64 `json:"input_tokens"`
+						OutputTokens             int64 `json:"output_tokens"`
+						CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+						CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+					} `json:"usage"`
+				} `json:"message"`
+			}
+			if err := json.Unmarshal(line, &entry); err != nil {
+				continue
+			}
+
+			if cwd == "" && entry.CWD != "" {
+				cwd = normalizePath(entry.CWD)
+			}
+			if entry.Type == "user" {
+				userTurns++
+				// Claude transcripts carry no originator; nightshift runs are
+				// recognised by the orchestrator prompt opening the session.
+				if !sawPrompt && entry.Message != nil {
+					sawPrompt = true
+					if orchestrator.IsAgentPrompt(messageText(entry.Message.Content)) {
+						originator = agents.Originator
+					}
+				}
+			}
+			if entry.Message != nil && entry.Message.Role == "assistant" {
+				assistantTurns++
+			}
+			if entry.Message == nil || entry.Message.Usage == nil {
+				continue
+			}
+
+			u := entry.Message.Usage
+			primary += u.InputTokens + u.OutputTokens
+			alt += u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+		}
+		if scanErr := scanner.Err(); scanErr != nil && scanErr != io.EOF {
+			return nil
+		}
+
+		if primary <= 0 {
+			return nil
+		}
+		if filter.excludes(originator, cwd) {
+			excluded++
+			return nil
+		}
+		if filter.Repo != "" && cwd != filter.Repo {
+			return nil
+		}
+		if int(userTurns) < filter.MinUserTurns {
+			return nil
+		}
+
+		sessions = append(sessions, SessionMetrics{
+			Provider:       "claude",
+			File:           path,
+			CWD:            cwd,
+			Originator:     originator,
+			TokensPrimary:  primary,
+			TokensAlt:      alt,
+			UserTurns:      userTurns,
+			AssistantTurns: assistantTurns,
+		})
+		return nil
+	})
+
+	return sessions, excluded, err
+}
+
+// messageText returns the text of a Claude message content field, which is
+// either a string or a list of typed blocks.
+func messageText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return ""
+	}
+	for _, b := range blocks {
+		if b.Type == "text" {
+			return b.Text
+		}
+	}
+	return ""
+}
+
+// PerUserTurn returns each session's primary (or, with alt, raw) tokens
+// divided by its user turns. Sessions without user turns are left out.
+func PerUserTurn(sessions []SessionMetrics, alt bool) []int64 {
+	vals := make([]int64, 0, len(sessions))
+	for _, m := range sessions {
+		if m.UserTurns <= 0 {
+			continue
+		}
+		tokens := m.TokensPrimary
+		if alt {
+			tokens = m.TokensAlt
+		}
+		vals = append(vals, tokens/m.UserTurns)
+	}
+	return vals
+}
+
+// SummaryStats summarizes a distribution of per-session values.
+type SummaryStats struct {
+	Count  int   `json:"count"`
+	Min    int64 `json:"min"`
+	Max    int64 `json:"max"`
+	Mean   int64 `json:"mean"`
+	Median int64 `json:"median"`
+	P75    int64 `json:"p75"`
+	P90    int64 `json:"p90"`
+}
+
+// CalcStats summarizes vals; the zero value when vals is empty.
+func CalcStats(vals []int64) SummaryStats {
+	if len(vals) == 0 {
+		return SummaryStats{}
+	}
+	sorted := append([]int64(nil), vals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum int64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	return SummaryStats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   sum / int64(len(sorted)),
+		Median: percentile(sorted, 0.50),
+		P75:    percentile(sorted, 0.75),
+		P90:    percentile(sorted, 0.90),
+	}
+}
+
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 1 {
+		return sorted[len(sorted)-1]
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// SafeRatio divides numerator by denominator, or returns 0 when the
+// denominator isn't positive.
+func SafeRatio(numerator, denominator int64) float64 {
+	if denominator <= 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+func nonNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+func normalizePath(path string) string {
+	if strings.TrimSpace(path) == "" {
+		return ""
+	}
+	return filepath.Clean(expandHome(path))
+}
+
+func expandHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	if path == "~" {
+		return home
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// BranchData is the data available to orchestrator.branch_template.
+type BranchData struct {
+	TaskType string // e.g. "lint-fix"
+	Project  string // project directory name
+	Provider string // e.g. "claude"
+	Date     string // run start, YYYYMMDD
+	Time     string // run start, HHMMSS
+}
+
+// NewBranchData fills BranchData for one task, reducing each value to
+// characters that are safe in a git ref.
+func NewBranchData(taskType, projectPath, provider string, start time.Time) BranchData {
+	return BranchData{
+		TaskType: branchSegment(taskType),
+		Project:  branchSegment(filepath.Base(projectPath)),
+		Provider: branchSegment(provider),
+		Date:     start.Format("20060102"),
+		Time:     start.Format("150405"),
+	}
+}
+
+var unsafeBranchChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// branchSegment replaces runs of characters outside [A-Za-z0-9._-] with
+// "-" and trims leading dots and dashes.
+func branchSegment(s string) string {
+	s = unsafeBranchChars.ReplaceAllString(s, "-")
+	return strings.Trim(s, ".-")
+}
+
+// RenderBranchName executes a branch template and checks that the result
+// is a valid git branch name.
+func RenderBranchName(tmpl string, data BranchData) (string, error) {
+	t, err := template.New("branch").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering template: %w", err)
+	}
+	name := strings.TrimSpace(buf.String())
+	if err := ValidBranchName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// ValidBranchName applies the rules of `git check-ref-format --branch`.
+func ValidBranchName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("empty branch name")
+	case name == "@":
+		return fmt.Errorf("branch name %q is reserved", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q starts with '-'", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return fmt.Errorf("branch name %q starts or ends with '/'", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q ends with '.'", name)
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return fmt.Errorf("branch name %q contains '..', '//' or '@{'", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("branch name %q contains invalid character %q", name, r)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("branch name %q has a component starting with '.' or ending in '.lock'", name)
+		}
+	}
+	return nil
+}
+
+// validateBranchTemplate renders tmpl with sample data so bad templates
+// fail at config load rather than mid-run.
+func validateBranchTemplate(key, tmpl string) error {
+	if tmpl == "" {
+		return nil
+	}
+	sample := NewBranchData("lint-fix", "/code/myapp", "claude", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if _, err := RenderBranchName(tmpl, sample); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// BranchTemplateFor returns the branch template for a project: its own
+// branch_template when set, otherwise orchestrator.branch_template.
+func (c *Config) BranchTemplateFor(projectPath string) string {
+	target := filepath.Clean(expandPath(projectPath))
+	for _, proj := range c.Projects {
+		if proj.BranchTemplate != "" && proj.Path != "" && filepath.Clean(expandPath(proj.Path)) == target {
+			return proj.BranchTemplate
+		}
+	}
+	if c.Orchestrator.BranchTemplate == "" {
+		return DefaultBranchTemplate
+	}
+	return c.Orchestrator.BranchTemplate
+}
+
+// WorkBranchName renders the feature branch name for one task on a project.
+func (c *Config) WorkBranchName(projectPath, taskType, provider string, start time.Time) (string, error) {
+	return RenderBranchName(c.BranchTemplateFor(projectPath), NewBranchData(taskType, projectPath, provider, start))
+}
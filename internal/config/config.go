@@ -5,7 +5,10 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
@@ -17,14 +20,23 @@ import (
 
 // Config holds all nightshift configuration.
 type Config struct {
-	Schedule     ScheduleConfig     `mapstructure:"schedule"`
-	Budget       BudgetConfig       `mapstructure:"budget"`
-	Providers    ProvidersConfig    `mapstructure:"providers"`
-	Projects     []ProjectConfig    `mapstructure:"projects"`
-	Tasks        TasksConfig        `mapstructure:"tasks"`
-	Integrations IntegrationsConfig `mapstructure:"integrations"`
-	Logging      LoggingConfig      `mapstructure:"logging"`
-	Reporting    ReportingConfig    `mapstructure:"reporting"`
+	Schedule  ScheduleConfig  `mapstructure:"schedule"`
+	Budget    BudgetConfig    `mapstructure:"budget"`
+	Providers ProvidersConfig `mapstructure:"providers"`
+	Projects  []ProjectConfig `mapstructure:"projects"`
+	ScanRoots []string        `mapstructure:"scan_roots"` // Parent dirs whose git repos are discovered as projects
+	// ManageGitignore lets setup add .nightshift-plan to each project's
+	// .gitignore. Turn off for repos with a centrally managed ignore policy.
+	ManageGitignore bool               `mapstructure:"manage_gitignore"`
+	Tasks           TasksConfig        `mapstructure:"tasks"`
+	Scoring         ScoringConfig      `mapstructure:"scoring"`
+	Integrations    IntegrationsConfig `mapstructure:"integrations"`
+	Logging         LoggingConfig      `mapstructure:"logging"`
+	Reporting       ReportingConfig    `mapstructure:"reporting"`
+	Orchestrator    OrchestratorConfig `mapstructure:"orchestrator"`
+	Safety          SafetyConfig       `mapstructure:"safety"`
+	Daemon          DaemonConfig       `mapstructure:"daemon"`
+	Sandbox         SandboxConfig      `mapstructure:"sandbox"`
 }
 
 // ScheduleConfig defines when nightshift runs.
@@ -32,6 +44,16 @@ type ScheduleConfig struct {
 	Cron     string        `mapstructure:"cron"`     // Cron expression (e.g., "0 2 * * *")
 	Interval string        `mapstructure:"interval"` // Alternative: duration (e.g., "1h")
 	Window   *WindowConfig `mapstructure:"window"`   // Optional time window constraint
+
+	// ProcessedWindow is how recently a project must have run to be skipped
+	// as already processed: a duration (e.g., "20h") or "calendar" for the
+	// current calendar day.
+	ProcessedWindow string `mapstructure:"processed_window"`
+
+	// Themes maps a weekday ("monday" ... "sunday") to the task categories
+	// and/or task types the selector may pick that day. Days without a
+	// theme allow every task.
+	Themes map[string][]string `mapstructure:"themes"`
 }
 
 // WindowConfig defines a time window for execution.
@@ -39,11 +61,52 @@ type WindowConfig struct {
 	Start    string `mapstructure:"start"`    // Start time (e.g., "22:00")
 	End      string `mapstructure:"end"`      // End time (e.g., "06:00")
 	Timezone string `mapstructure:"timezone"` // Timezone (e.g., "America/Denver")
+
+	// Categories limits scheduled task selection to these task categories
+	// while the window is open (e.g., ["analysis", "map"]). Empty allows all.
+	Categories []string `mapstructure:"categories"`
+
+	// StartupDelay holds scheduled tasks back for this long after the
+	// window opens (e.g., "20m"), as a buffer to finish your own work in
+	// the same repos. Manual runs ignore it unless --respect-startup-delay.
+	StartupDelay string `mapstructure:"startup_delay"`
+}
+
+// windowCategoryNames are the task category names accepted in
+// schedule.window.categories.
+var windowCategoryNames = []string{"pr", "analysis", "options", "safe", "map", "emergency"}
+
+// ThemeFor returns the weekday name and theme entries for the day now falls
+// on, in the schedule window's timezone when set. Entries are nil when the
+// day has no theme.
+func (c *Config) ThemeFor(now time.Time) (string, []string) {
+	if c.Schedule.Window != nil && c.Schedule.Window.Timezone != "" {
+		if loc, err := time.LoadLocation(c.Schedule.Window.Timezone); err == nil {
+			now = now.In(loc)
+		}
+	}
+	day := strings.ToLower(now.Weekday().String())
+	for key, entries := range c.Schedule.Themes {
+		if strings.ToLower(key) == day {
+			return day, entries
+		}
+	}
+	return day, nil
+}
+
+// isThemeDay reports whether name is a full weekday name.
+func isThemeDay(name string) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) {
+			return true
+		}
+	}
+	return false
 }
 
 // BudgetConfig controls token budget allocation.
 type BudgetConfig struct {
-	Mode                  string         `mapstructure:"mode"`                    // daily | weekly
+	Mode                  string         `mapstructure:"mode"`                    // daily | weekly | auto
 	MaxPercent            int            `mapstructure:"max_percent"`             // Max % of budget per run
 	AggressiveEndOfWeek   bool           `mapstructure:"aggressive_end_of_week"`  // Ramp up in last 2 days
 	ReservePercent        int            `mapstructure:"reserve_percent"`         // Always keep in reserve
@@ -55,8 +118,42 @@ type BudgetConfig struct {
 	SnapshotRetentionDays int            `mapstructure:"snapshot_retention_days"` // Snapshot retention in days
 	WeekStartDay          string         `mapstructure:"week_start_day"`          // monday | sunday
 	DBPath                string         `mapstructure:"db_path"`                 // Override DB path
+	MaxWaitForReset       string         `mapstructure:"max_wait_for_reset"`      // Sleep for a provider reset up to this long (0 = never)
+	TokenAccounting       string         `mapstructure:"token_accounting"`        // billable | raw
+	Pooling               string         `mapstructure:"pooling"`                 // per-provider | pooled
+	MaxDailyShare         float64        `mapstructure:"max_daily_share"`         // Cap on one night's weekly-mode allowance, as a fraction of the week (0 = no cap)
+	Pacing                string         `mapstructure:"pacing"`                  // greedy | even | front-loaded (weekly window)
 }
 
+// tokenAccountingModes are the values accepted in budget.token_accounting.
+var tokenAccountingModes = []string{"billable", "raw"}
+
+// Budget pooling modes accepted in budget.pooling.
+const (
+	PoolingPerProvider = "per-provider"
+	PoolingPooled      = "pooled"
+)
+
+var poolingModes = []string{PoolingPerProvider, PoolingPooled}
+
+// Weekly budget pacing curves accepted in budget.pacing.
+const (
+	PacingGreedy      = "greedy"
+	PacingEven        = "even"
+	PacingFrontLoaded = "front-loaded"
+)
+
+var pacingModes = []string{PacingGreedy, PacingEven, PacingFrontLoaded}
+
+// Provider selection strategies accepted in providers.strategy.
+const (
+	StrategyPreference = "preference"
+	StrategyLeastUsed  = "least-used"
+	StrategyMostBudget = "most-budget"
+)
+
+var providerStrategies = []string{StrategyPreference, StrategyLeastUsed, StrategyMostBudget}
+
 // ProvidersConfig defines AI provider settings.
 type ProvidersConfig struct {
 	Claude  ProviderConfig `mapstructure:"claude"`
@@ -64,6 +161,34 @@ type ProvidersConfig struct {
 	Copilot ProviderConfig `mapstructure:"copilot"`
 	// Preference sets provider order (e.g., ["claude", "codex", "copilot"]).
 	Preference []string `mapstructure:"preference"`
+	// TierPreference maps a task cost tier (low, medium, high, very-high)
+	// to the provider order used for tasks of that tier, e.g. cheap tasks
+	// on copilot and high-cost ones on claude. Tiers not listed, and
+	// providers left out of a tier's list, follow Preference.
+	TierPreference map[string][]string `mapstructure:"tier_preference"`
+	// Fallback controls whether a run may switch to the next provider in
+	// preference order when the first is unavailable: "on" (default) or "off".
+	Fallback string `mapstructure:"fallback"`
+	// Overflow providers are held back until every provider in preference
+	// order is budget-exhausted, then tried in this order. They are never
+	// considered while a preferred provider still has budget.
+	Overflow []string `mapstructure:"overflow"`
+	// Strategy picks among providers that have budget: "preference" (first
+	// in preference order, default), "least-used" (lowest used percent) or
+	// "most-budget" (largest remaining allowance).
+	Strategy string `mapstructure:"strategy"`
+	// ExtraPathDirs are appended to PATH before provider CLIs are looked up,
+	// for installs outside the built-in locations (asdf shims, pnpm, volta).
+	// ~ and $VAR are expanded.
+	ExtraPathDirs []string `mapstructure:"extra_path_dirs"`
+	// StatusCacheTTL is how long interactive commands reuse computed
+	// used-percent and reset values from an on-disk cache (e.g. "3m").
+	// "0" disables the cache. The daemon never uses it.
+	StatusCacheTTL string `mapstructure:"status_cache_ttl"`
+	// UsageTimeout bounds each read of a provider's local usage data (e.g.
+	// "30s"), so a session directory on a slow mount can't stall a run or
+	// snapshot. A provider that times out is skipped. "0" waits forever.
+	UsageTimeout string `mapstructure:"usage_timeout"`
 }
 
 // ProviderConfig defines settings for a single AI provider.
@@ -74,6 +199,14 @@ type ProviderConfig struct {
 	DangerouslySkipPermissions bool `mapstructure:"dangerously_skip_permissions"`
 	// DangerouslyBypassApprovalsAndSandbox tells the CLI to bypass approvals and sandboxing.
 	DangerouslyBypassApprovalsAndSandbox bool `mapstructure:"dangerously_bypass_approvals_and_sandbox"`
+	// ExtraArgs are appended verbatim to the spawned CLI command. Escape hatch
+	// for flags Nightshift does not manage yet.
+	ExtraArgs []string `mapstructure:"extra_args"`
+	// Command replaces the CLI binary (e.g. a wrapper script): an absolute
+	// path or a name looked up in PATH. Empty uses the provider's default.
+	Command string `mapstructure:"command"`
+	// Args are passed to Command before Nightshift's own args.
+	Args []string `mapstructure:"args"`
 }
 
 // ProjectConfig defines a project to manage.
@@ -84,6 +217,18 @@ type ProjectConfig struct {
 	Config   string   `mapstructure:"config"`  // Per-project config file
 	Pattern  string   `mapstructure:"pattern"` // Glob pattern for discovery
 	Exclude  []string `mapstructure:"exclude"` // Paths to exclude
+
+	// DenyPaths replaces safety.deny_paths for this project when set; an
+	// empty list allows every path.
+	DenyPaths []string `mapstructure:"deny_paths"`
+
+	// BranchTemplate replaces orchestrator.branch_template for this project.
+	BranchTemplate string `mapstructure:"branch_template"`
+
+	// ReadOnly limits the project to tasks that don't change code
+	// (analysis, options, map and emergency docs); agents are told not to
+	// modify files.
+	ReadOnly bool `mapstructure:"read_only"`
 }
 
 // TasksConfig defines task selection settings.
@@ -92,7 +237,27 @@ type TasksConfig struct {
 	Priorities map[string]int     `mapstructure:"priorities"` // Priority per task type
 	Disabled   []string           `mapstructure:"disabled"`   // Explicitly disabled tasks
 	Intervals  map[string]string  `mapstructure:"intervals"`  // Per-task interval overrides (duration strings)
+	Models     map[string]string  `mapstructure:"models"`     // Per-task model overrides (task type -> model)
+	TokenCaps  map[string]int     `mapstructure:"token_caps"` // Per-task token ceiling overrides (0 = no cap)
 	Custom     []CustomTaskConfig `mapstructure:"custom"`     // User-defined custom tasks
+	// SinceReport also runs task cooldowns from completions in the retained
+	// run reports, so machines sharing a reports dir don't repeat each
+	// other's work.
+	SinceReport bool `mapstructure:"since_report"`
+	// RecentFailureWindow skips a task on a project whose last run there
+	// failed or was abandoned within this duration (e.g. "48h"), even if
+	// its cooldown has passed. Empty or "0" disables.
+	RecentFailureWindow string `mapstructure:"recent_failure_window"`
+}
+
+// ScoringConfig tunes how scored tasks are picked.
+type ScoringConfig struct {
+	// BalanceCategories spreads multi-task runs across task categories
+	// (round-robin) instead of taking the top N by score.
+	BalanceCategories bool `mapstructure:"balance_categories"`
+	// MinScore excludes tasks scoring below it from selection. A project
+	// with no task at or above the threshold is skipped. 0 disables.
+	MinScore float64 `mapstructure:"min_score"`
 }
 
 // CustomTaskConfig defines a user-defined custom task.
@@ -131,13 +296,84 @@ type LoggingConfig struct {
 	Level  string `mapstructure:"level"`  // debug | info | warn | error
 	Path   string `mapstructure:"path"`   // Log directory
 	Format string `mapstructure:"format"` // json | text
+	// AgentOutput controls how much agent stdout is written to the log:
+	// none | summary | full. The tail is always kept when an agent fails.
+	AgentOutput string `mapstructure:"agent_output"`
 }
 
+// agentOutputModes are the values accepted in logging.agent_output.
+var agentOutputModes = []string{"none", "summary", "full"}
+
 // ReportingConfig defines reporting settings.
 type ReportingConfig struct {
 	MorningSummary bool    `mapstructure:"morning_summary"`
-	Email          *string `mapstructure:"email"`         // Optional email notification
-	SlackWebhook   *string `mapstructure:"slack_webhook"` // Optional Slack webhook
+	Email          *string `mapstructure:"email"`          // Optional email notification
+	SlackWebhook   *string `mapstructure:"slack_webhook"`  // Optional Slack webhook
+	RetentionDays  int     `mapstructure:"retention_days"` // Run report retention in days (0 = keep forever)
+	Dir            string  `mapstructure:"dir"`            // Run reports directory (default ~/.local/share/nightshift/reports)
+	// NumberFormat controls how token counts print in report views:
+	// compact (1.2m), grouped (1,234,567) or raw (1234567).
+	NumberFormat string `mapstructure:"number_format"`
+}
+
+// numberFormats are the values accepted in reporting.number_format.
+var numberFormats = []string{"compact", "grouped", "raw"}
+
+// OrchestratorConfig defines agent orchestration settings.
+type OrchestratorConfig struct {
+	PR            PRConfig `mapstructure:"pr"`
+	ShutdownGrace string   `mapstructure:"shutdown_grace"` // Time to let the current task finish after SIGTERM (e.g. "10m")
+	Forge         string   `mapstructure:"forge"`          // github | gitlab | gitea
+	// ProjectTimeout caps the wall-clock time spent on one project per run;
+	// once exceeded, remaining tasks for that project are skipped (0 = no cap).
+	ProjectTimeout string `mapstructure:"project_timeout"`
+	// BranchTemplate names each task's feature branch, as a Go template over
+	// BranchData (e.g. "nightshift/{{.TaskType}}/{{.Date}}").
+	BranchTemplate string `mapstructure:"branch_template"`
+}
+
+// SafetyConfig restricts what agents may change.
+type SafetyConfig struct {
+	// DenyPaths are globs, relative to the project root, that agents must not
+	// modify (e.g. "migrations/", "vendor/**", "*.lock"). A task whose
+	// changes touch one fails.
+	DenyPaths []string `mapstructure:"deny_paths"`
+}
+
+// DaemonConfig configures the background daemon.
+type DaemonConfig struct {
+	// HTTPAddr, when set, serves /healthz, /status and /next over HTTP at
+	// this host:port. A missing host binds to 127.0.0.1 (e.g. ":8787").
+	HTTPAddr string `mapstructure:"http_addr"`
+}
+
+// Sandbox types accepted in sandbox.type.
+const (
+	SandboxNone   = "none"
+	SandboxDocker = "docker"
+)
+
+// SandboxConfig runs agent CLIs inside a container so they only see the
+// project (and any extra mounts), not the rest of the filesystem.
+type SandboxConfig struct {
+	// Type is "none" (default) or "docker".
+	Type string `mapstructure:"type"`
+	// Image is the container image to run; it must have the provider CLIs
+	// installed.
+	Image string `mapstructure:"image"`
+	// Mounts are extra bind mounts as "host:container" or
+	// "host:container:ro", e.g. "~/.claude:/home/agent/.claude". The
+	// project is always mounted read-write at its host path.
+	Mounts []string `mapstructure:"mounts"`
+}
+
+// forgeNames are the code hosts accepted in orchestrator.forge.
+var forgeNames = []string{"github", "gitlab", "gitea"}
+
+// PRConfig controls how agents open pull requests.
+type PRConfig struct {
+	Draft        bool   `mapstructure:"draft"`         // Open PRs as drafts
+	TargetBranch string `mapstructure:"target_branch"` // PR base branch (empty = agent default)
 }
 
 // Default values for configuration.
@@ -152,11 +388,28 @@ const (
 	DefaultWeekStartDay      = "monday"
 	DefaultLogLevel          = "info"
 	DefaultLogFormat         = "json"
+	DefaultAgentOutput       = "summary"
 	DefaultClaudeDataPath    = "~/.claude"
 	DefaultCodexDataPath     = "~/.codex"
 	DefaultCopilotDataPath   = "~/.copilot"
+	DefaultShutdownGrace     = "10m"
+	DefaultStatusCacheTTL    = "3m"
+	DefaultUsageTimeout      = "30s"
+	DefaultForge             = "github"
+	DefaultMaxWaitForReset   = "0s"
+	DefaultProjectTimeout    = "0s"
+	DefaultBranchTemplate    = "nightshift/{{.TaskType}}/{{.Date}}-{{.Time}}"
+	DefaultTokenAccounting   = "billable"
+	DefaultPooling           = PoolingPerProvider
+	DefaultPacing            = PacingGreedy
+	DefaultProviderStrategy  = StrategyPreference
+	DefaultNumberFormat      = "compact"
+	DefaultProcessedWindow   = "20h" // under a day so daily schedules are not skipped
 )
 
+// ProcessedWindowCalendar selects calendar-day "processed today" checks.
+const ProcessedWindowCalendar = "calendar"
+
 // DefaultLogPath returns the default log path.
 func DefaultLogPath() string {
 	home, _ := os.UserHomeDir()
@@ -229,8 +482,24 @@ func LoadFromPaths(projectPath, globalPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// Defaults returns the built-in default configuration, ignoring any config
+// files and environment variables.
+func Defaults() (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unmarshaling default config: %w", err)
+	}
+	return &cfg, nil
+}
+
 // setDefaults configures default values.
 func setDefaults(v *viper.Viper) {
+	// Schedule defaults
+	v.SetDefault("schedule.processed_window", DefaultProcessedWindow)
+
 	// Budget defaults
 	v.SetDefault("budget.mode", DefaultBudgetMode)
 	v.SetDefault("budget.max_percent", DefaultMaxPercent)
@@ -243,9 +512,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("budget.snapshot_retention_days", DefaultSnapshotRetention)
 	v.SetDefault("budget.week_start_day", DefaultWeekStartDay)
 	v.SetDefault("budget.db_path", DefaultDBPath())
+	v.SetDefault("budget.max_wait_for_reset", DefaultMaxWaitForReset)
+	v.SetDefault("budget.token_accounting", DefaultTokenAccounting)
+	v.SetDefault("budget.pooling", DefaultPooling)
+	v.SetDefault("budget.pacing", DefaultPacing)
 
 	// Provider defaults
+	v.SetDefault("manage_gitignore", true)
+
 	v.SetDefault("providers.preference", []string{"claude", "codex", "copilot"})
+	v.SetDefault("providers.status_cache_ttl", DefaultStatusCacheTTL)
+	v.SetDefault("providers.usage_timeout", DefaultUsageTimeout)
+	v.SetDefault("providers.strategy", DefaultProviderStrategy)
 	v.SetDefault("providers.claude.enabled", true)
 	v.SetDefault("providers.claude.data_path", DefaultClaudeDataPath)
 	// SECURITY: Default to false to require explicit opt-in for permission bypassing
@@ -263,9 +541,21 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("logging.level", DefaultLogLevel)
 	v.SetDefault("logging.path", DefaultLogPath())
 	v.SetDefault("logging.format", DefaultLogFormat)
+	v.SetDefault("logging.agent_output", DefaultAgentOutput)
 
 	// Reporting defaults
 	v.SetDefault("reporting.morning_summary", true)
+	v.SetDefault("reporting.number_format", DefaultNumberFormat)
+
+	// Scoring defaults
+	v.SetDefault("scoring.balance_categories", false)
+	v.SetDefault("scoring.min_score", 0)
+
+	// Orchestrator defaults
+	v.SetDefault("orchestrator.shutdown_grace", DefaultShutdownGrace)
+	v.SetDefault("orchestrator.project_timeout", DefaultProjectTimeout)
+	v.SetDefault("orchestrator.forge", DefaultForge)
+	v.SetDefault("orchestrator.branch_template", DefaultBranchTemplate)
 
 	// Integration defaults
 	v.SetDefault("integrations.claude_md", true)
@@ -315,12 +605,15 @@ func expandPath(path string) string {
 // Validation errors
 var (
 	ErrCronAndInterval          = errors.New("cron and interval are mutually exclusive")
-	ErrInvalidBudgetMode        = errors.New("budget mode must be 'daily' or 'weekly'")
+	ErrInvalidBudgetMode        = errors.New("budget mode must be 'daily', 'weekly', or 'auto'")
 	ErrInvalidBillingMode       = errors.New("billing mode must be 'subscription' or 'api'")
 	ErrInvalidWeekStartDay      = errors.New("week_start_day must be 'monday' or 'sunday'")
 	ErrInvalidMaxPercent        = errors.New("max_percent must be between 1 and 100")
 	ErrInvalidReservePercent    = errors.New("reserve_percent must be between 0 and 100")
+	ErrInvalidMaxDailyShare     = errors.New("max_daily_share must be between 0 and 1")
 	ErrInvalidSnapshotRetention = errors.New("snapshot_retention_days must be >= 0")
+	ErrInvalidReportRetention   = errors.New("reporting retention_days must be >= 0")
+	ErrInvalidMinScore          = errors.New("scoring min_score must be >= 0")
 	ErrInvalidLogLevel          = errors.New("log level must be debug, info, warn, or error")
 	ErrInvalidLogFormat         = errors.New("log format must be json or text")
 	ErrNoSchedule               = errors.New("either cron or interval must be specified")
@@ -344,8 +637,67 @@ func Validate(cfg *Config) error {
 		return ErrCronAndInterval
 	}
 
+	// Window category validation
+	if cfg.Schedule.Window != nil {
+		for _, name := range cfg.Schedule.Window.Categories {
+			if !slices.Contains(windowCategoryNames, strings.ToLower(name)) {
+				return fmt.Errorf("schedule.window.categories: unknown category %q (valid: %s)", name, strings.Join(windowCategoryNames, ", "))
+			}
+		}
+		if delay := cfg.Schedule.Window.StartupDelay; delay != "" {
+			if d, err := time.ParseDuration(delay); err != nil || d < 0 {
+				return fmt.Errorf("schedule.window.startup_delay: invalid duration %q (e.g. 20m)", delay)
+			}
+		}
+	}
+
+	if addr := cfg.Daemon.HTTPAddr; addr != "" {
+		if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+			return fmt.Errorf("daemon.http_addr: %q must be host:port (e.g. 127.0.0.1:8787)", addr)
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Sandbox.Type)) {
+	case "", SandboxNone:
+	case SandboxDocker:
+		if strings.TrimSpace(cfg.Sandbox.Image) == "" {
+			return fmt.Errorf("sandbox.image: required when sandbox.type is docker")
+		}
+		for _, m := range cfg.Sandbox.Mounts {
+			if _, err := parseSandboxMount(m); err != nil {
+				return fmt.Errorf("sandbox.mounts: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("sandbox.type: unknown type %q (use none or docker)", cfg.Sandbox.Type)
+	}
+
+	// Theme validation: weekday keys; entries are categories or task types
+	// (task types are resolved against the registry when selecting).
+	for day, entries := range cfg.Schedule.Themes {
+		if !isThemeDay(day) {
+			return fmt.Errorf("schedule.themes: unknown day %q (use monday ... sunday)", day)
+		}
+		for _, entry := range entries {
+			if !slices.Contains(windowCategoryNames, strings.ToLower(entry)) && !customTaskTypeRe.MatchString(entry) {
+				return fmt.Errorf("schedule.themes.%s: %q is not a task category or task type", strings.ToLower(day), entry)
+			}
+		}
+	}
+
+	// Processed window validation
+	if w := cfg.Schedule.ProcessedWindow; w != "" && w != ProcessedWindowCalendar {
+		d, err := time.ParseDuration(w)
+		if err != nil {
+			return fmt.Errorf("schedule.processed_window: invalid duration %q (or use %q): %w", w, ProcessedWindowCalendar, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("schedule.processed_window: must be > 0, got %q", w)
+		}
+	}
+
 	// Budget mode validation
-	if cfg.Budget.Mode != "" && cfg.Budget.Mode != "daily" && cfg.Budget.Mode != "weekly" {
+	if cfg.Budget.Mode != "" && cfg.Budget.Mode != "daily" && cfg.Budget.Mode != "weekly" && cfg.Budget.Mode != "auto" {
 		return ErrInvalidBudgetMode
 	}
 
@@ -357,6 +709,17 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	// Token accounting validation
+	if cfg.Budget.TokenAccounting != "" && !slices.Contains(tokenAccountingModes, strings.ToLower(cfg.Budget.TokenAccounting)) {
+		return fmt.Errorf("budget.token_accounting: unknown mode %q (valid: %s)", cfg.Budget.TokenAccounting, strings.Join(tokenAccountingModes, ", "))
+	}
+	if cfg.Budget.Pooling != "" && !slices.Contains(poolingModes, strings.ToLower(cfg.Budget.Pooling)) {
+		return fmt.Errorf("budget.pooling: unknown mode %q (valid: %s)", cfg.Budget.Pooling, strings.Join(poolingModes, ", "))
+	}
+	if cfg.Budget.Pacing != "" && !slices.Contains(pacingModes, strings.ToLower(cfg.Budget.Pacing)) {
+		return fmt.Errorf("budget.pacing: unknown curve %q (valid: %s)", cfg.Budget.Pacing, strings.Join(pacingModes, ", "))
+	}
+
 	// Week start day validation
 	if cfg.Budget.WeekStartDay != "" {
 		day := strings.ToLower(cfg.Budget.WeekStartDay)
@@ -375,10 +738,25 @@ func Validate(cfg *Config) error {
 		return ErrInvalidReservePercent
 	}
 
+	if cfg.Budget.MaxDailyShare < 0 || cfg.Budget.MaxDailyShare > 1 {
+		return ErrInvalidMaxDailyShare
+	}
+
 	if cfg.Budget.SnapshotRetentionDays < 0 {
 		return ErrInvalidSnapshotRetention
 	}
 
+	if cfg.Reporting.RetentionDays < 0 {
+		return ErrInvalidReportRetention
+	}
+	if cfg.Reporting.NumberFormat != "" && !slices.Contains(numberFormats, strings.ToLower(cfg.Reporting.NumberFormat)) {
+		return fmt.Errorf("reporting.number_format: unknown format %q (valid: %s)", cfg.Reporting.NumberFormat, strings.Join(numberFormats, ", "))
+	}
+
+	if cfg.Scoring.MinScore < 0 {
+		return ErrInvalidMinScore
+	}
+
 	// Log level validation
 	if cfg.Logging.Level != "" {
 		validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
@@ -394,6 +772,91 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	// Agent output validation
+	if cfg.Logging.AgentOutput != "" && !slices.Contains(agentOutputModes, strings.ToLower(cfg.Logging.AgentOutput)) {
+		return fmt.Errorf("logging.agent_output: unknown mode %q (valid: %s)", cfg.Logging.AgentOutput, strings.Join(agentOutputModes, ", "))
+	}
+
+	// Max wait for reset validation
+	if cfg.Budget.MaxWaitForReset != "" {
+		d, err := time.ParseDuration(cfg.Budget.MaxWaitForReset)
+		if err != nil {
+			return fmt.Errorf("budget.max_wait_for_reset: invalid duration %q: %w", cfg.Budget.MaxWaitForReset, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("budget.max_wait_for_reset: must be >= 0, got %q", cfg.Budget.MaxWaitForReset)
+		}
+	}
+
+	// Status cache TTL validation
+	if cfg.Providers.StatusCacheTTL != "" {
+		d, err := time.ParseDuration(cfg.Providers.StatusCacheTTL)
+		if err != nil {
+			return fmt.Errorf("providers.status_cache_ttl: invalid duration %q: %w", cfg.Providers.StatusCacheTTL, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("providers.status_cache_ttl: must be >= 0, got %q", cfg.Providers.StatusCacheTTL)
+		}
+	}
+
+	// Usage timeout validation
+	if cfg.Providers.UsageTimeout != "" {
+		d, err := time.ParseDuration(cfg.Providers.UsageTimeout)
+		if err != nil {
+			return fmt.Errorf("providers.usage_timeout: invalid duration %q: %w", cfg.Providers.UsageTimeout, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("providers.usage_timeout: must be >= 0, got %q", cfg.Providers.UsageTimeout)
+		}
+	}
+
+	// Shutdown grace validation
+	if cfg.Orchestrator.ShutdownGrace != "" {
+		d, err := time.ParseDuration(cfg.Orchestrator.ShutdownGrace)
+		if err != nil {
+			return fmt.Errorf("orchestrator.shutdown_grace: invalid duration %q: %w", cfg.Orchestrator.ShutdownGrace, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("orchestrator.shutdown_grace: must be >= 0, got %q", cfg.Orchestrator.ShutdownGrace)
+		}
+	}
+
+	// Project timeout validation
+	if cfg.Orchestrator.ProjectTimeout != "" {
+		d, err := time.ParseDuration(cfg.Orchestrator.ProjectTimeout)
+		if err != nil {
+			return fmt.Errorf("orchestrator.project_timeout: invalid duration %q: %w", cfg.Orchestrator.ProjectTimeout, err)
+		}
+		if d < 0 {
+			return fmt.Errorf("orchestrator.project_timeout: must be >= 0, got %q", cfg.Orchestrator.ProjectTimeout)
+		}
+	}
+
+	// Forge validation
+	if cfg.Orchestrator.Forge != "" && !slices.Contains(forgeNames, strings.ToLower(cfg.Orchestrator.Forge)) {
+		return fmt.Errorf("orchestrator.forge: unknown forge %q (valid: %s)", cfg.Orchestrator.Forge, strings.Join(forgeNames, ", "))
+	}
+
+	// Branch template validation
+	if err := validateBranchTemplate("orchestrator.branch_template", cfg.Orchestrator.BranchTemplate); err != nil {
+		return err
+	}
+	for _, proj := range cfg.Projects {
+		if err := validateBranchTemplate(fmt.Sprintf("projects[%q].branch_template", proj.Path), proj.BranchTemplate); err != nil {
+			return err
+		}
+	}
+
+	// Deny paths validation
+	if err := validateDenyPaths("safety.deny_paths", cfg.Safety.DenyPaths); err != nil {
+		return err
+	}
+	for _, proj := range cfg.Projects {
+		if err := validateDenyPaths(fmt.Sprintf("projects[%q].deny_paths", proj.Path), proj.DenyPaths); err != nil {
+			return err
+		}
+	}
+
 	// Task intervals validation
 	for taskType, dur := range cfg.Tasks.Intervals {
 		if _, err := time.ParseDuration(dur); err != nil {
@@ -401,6 +864,25 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	if raw := strings.TrimSpace(cfg.Tasks.RecentFailureWindow); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < 0 {
+			return fmt.Errorf("tasks.recent_failure_window: invalid duration %q (e.g. 48h; 0 disables)", raw)
+		}
+	}
+
+	// Task model validation
+	for taskType, model := range cfg.Tasks.Models {
+		if ModelFamily(model) == "" {
+			return fmt.Errorf("tasks.models[%q]: unknown model %q (want a Claude model such as sonnet/opus/haiku/claude-*, or an OpenAI model such as gpt-*/o3/o4-mini)", taskType, model)
+		}
+	}
+
+	for taskType, limit := range cfg.Tasks.TokenCaps {
+		if limit < 0 {
+			return fmt.Errorf("tasks.token_caps[%q]: must be >= 0", taskType)
+		}
+	}
+
 	// Provider preference validation
 	if len(cfg.Providers.Preference) > 0 {
 		seen := map[string]bool{}
@@ -419,6 +901,55 @@ func Validate(cfg *Config) error {
 		}
 	}
 
+	for tier, prefs := range cfg.Providers.TierPreference {
+		if !validCostTiers[strings.ToLower(tier)] {
+			return fmt.Errorf("providers.tier_preference: unknown cost tier %q (valid: low, medium, high, very-high)", tier)
+		}
+		seen := map[string]bool{}
+		for _, pref := range prefs {
+			name := strings.ToLower(strings.TrimSpace(pref))
+			if name != "claude" && name != "codex" && name != "copilot" {
+				return fmt.Errorf("providers.tier_preference.%s contains unknown provider: %s", tier, pref)
+			}
+			if seen[name] {
+				return fmt.Errorf("providers.tier_preference.%s contains duplicate provider: %s", tier, pref)
+			}
+			seen[name] = true
+		}
+	}
+
+	seenOverflow := map[string]bool{}
+	for _, pref := range cfg.Providers.Overflow {
+		name := strings.ToLower(strings.TrimSpace(pref))
+		if name != "claude" && name != "codex" && name != "copilot" {
+			return fmt.Errorf("providers.overflow contains unknown provider: %s", pref)
+		}
+		if seenOverflow[name] {
+			return fmt.Errorf("providers.overflow contains duplicate provider: %s", pref)
+		}
+		seenOverflow[name] = true
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Providers.Fallback)) {
+	case "", "on", "off":
+	default:
+		return fmt.Errorf("providers.fallback: %q must be on or off", cfg.Providers.Fallback)
+	}
+	if cfg.Providers.Strategy != "" && !slices.Contains(providerStrategies, strings.ToLower(cfg.Providers.Strategy)) {
+		return fmt.Errorf("providers.strategy: unknown strategy %q (valid: %s)", cfg.Providers.Strategy, strings.Join(providerStrategies, ", "))
+	}
+
+	for _, dir := range cfg.ExpandedExtraPathDirs() {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("providers.extra_path_dirs: %q must be an absolute path", dir)
+		}
+	}
+	for _, name := range []string{"claude", "codex", "copilot"} {
+		if err := validateProviderCommand(cfg, name); err != nil {
+			return err
+		}
+	}
+
 	// Custom task validation
 	if err := validateCustomTasks(cfg.Tasks.Custom); err != nil {
 		return err
@@ -427,14 +958,16 @@ func Validate(cfg *Config) error {
 	return nil
 }
 
+// validCostTiers are the task cost tier names accepted in config.
+var validCostTiers = map[string]bool{
+	"low": true, "medium": true, "high": true, "very-high": true,
+}
+
 func validateCustomTasks(tasks []CustomTaskConfig) error {
 	validCategories := map[string]bool{
 		"pr": true, "analysis": true, "options": true,
 		"safe": true, "map": true, "emergency": true,
 	}
-	validCostTiers := map[string]bool{
-		"low": true, "medium": true, "high": true, "very-high": true,
-	}
 	validRiskLevels := map[string]bool{
 		"low": true, "medium": true, "high": true,
 	}
@@ -515,6 +1048,48 @@ func (c *Config) IsTaskExplicitlyEnabled(task string) bool {
 	return slices.Contains(c.Tasks.Enabled, task)
 }
 
+// claudeModelAliases are the short model names the claude CLI accepts.
+var claudeModelAliases = []string{"sonnet", "opus", "haiku", "opusplan"}
+
+// openAIModelPattern matches OpenAI model names accepted by codex and copilot.
+var openAIModelPattern = regexp.MustCompile(`^(gpt-[a-z0-9][a-z0-9.\-]*|o[0-9]+(-[a-z0-9.\-]+)?|codex-[a-z0-9.\-]+)$`)
+
+// ModelFamily returns "claude" or "openai" for a recognised model name, or
+// "" if the name matches neither known model list.
+func ModelFamily(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	switch {
+	case slices.Contains(claudeModelAliases, model), strings.HasPrefix(model, "claude-"):
+		return "claude"
+	case openAIModelPattern.MatchString(model):
+		return "openai"
+	}
+	return ""
+}
+
+// GetTaskModel returns the model override for a task type when provider can
+// run it: claude takes Claude models, codex takes OpenAI models, and copilot
+// takes either. Returns "" to use the provider's default model.
+func (c *Config) GetTaskModel(taskType, provider string) string {
+	model := strings.TrimSpace(c.Tasks.Models[taskType])
+	switch family := ModelFamily(model); {
+	case family == "":
+		return ""
+	case provider == "copilot",
+		provider == "claude" && family == "claude",
+		provider == "codex" && family == "openai":
+		return model
+	}
+	return ""
+}
+
+// GetTaskTokenCap returns the configured token ceiling for a task type and
+// whether one is set. A configured 0 disables the cap.
+func (c *Config) GetTaskTokenCap(taskType string) (int, bool) {
+	limit, ok := c.Tasks.TokenCaps[taskType]
+	return limit, ok
+}
+
 // GetTaskInterval returns the configured interval override for a task type.
 // Returns 0 if no override is set (caller should fall back to TaskDefinition.DefaultInterval).
 func (c *Config) GetTaskInterval(taskType string) time.Duration {
@@ -529,6 +1104,203 @@ func (c *Config) GetTaskInterval(taskType string) time.Duration {
 	return 0
 }
 
+// RecentFailureWindow returns tasks.recent_failure_window, or 0 when unset
+// or invalid (the filter is off).
+func (c *Config) RecentFailureWindow() time.Duration {
+	d, err := time.ParseDuration(strings.TrimSpace(c.Tasks.RecentFailureWindow))
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ProcessedSince returns the cutoff after which a project counts as already
+// processed: the start of now's day in calendar mode, otherwise now minus the
+// processed window.
+func (c *Config) ProcessedSince(now time.Time) time.Time {
+	if c.Schedule.ProcessedWindow == ProcessedWindowCalendar {
+		y, m, d := now.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	}
+	window, err := time.ParseDuration(c.Schedule.ProcessedWindow)
+	if err != nil || window <= 0 {
+		window, _ = time.ParseDuration(DefaultProcessedWindow)
+	}
+	return now.Add(-window)
+}
+
+// DaemonHTTPAddr returns daemon.http_addr with a missing host set to
+// 127.0.0.1, or "" when the status server is off.
+func (c *Config) DaemonHTTPAddr() string {
+	addr := strings.TrimSpace(c.Daemon.HTTPAddr)
+	if addr == "" {
+		return ""
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// GetShutdownGrace returns how long a draining run may keep working on the
+// current task after the first shutdown signal.
+func (c *Config) GetShutdownGrace() time.Duration {
+	if d, err := time.ParseDuration(c.Orchestrator.ShutdownGrace); err == nil && d >= 0 {
+		return d
+	}
+	d, _ := time.ParseDuration(DefaultShutdownGrace)
+	return d
+}
+
+// GetStatusCacheTTL returns how long provider usage values may be reused
+// across commands. 0 disables the cache.
+func (c *Config) GetStatusCacheTTL() time.Duration {
+	if c.Providers.StatusCacheTTL == "" {
+		d, _ := time.ParseDuration(DefaultStatusCacheTTL)
+		return d
+	}
+	if d, err := time.ParseDuration(c.Providers.StatusCacheTTL); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// GetUsageTimeout returns how long a provider usage read may take before the
+// provider is skipped. 0 means no limit.
+func (c *Config) GetUsageTimeout() time.Duration {
+	if c.Providers.UsageTimeout == "" {
+		d, _ := time.ParseDuration(DefaultUsageTimeout)
+		return d
+	}
+	if d, err := time.ParseDuration(c.Providers.UsageTimeout); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// GetProjectTimeout returns the per-project wall-clock cap for a run.
+// 0 means no cap.
+func (c *Config) GetProjectTimeout() time.Duration {
+	if d, err := time.ParseDuration(c.Orchestrator.ProjectTimeout); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// validateDenyPaths checks that each glob segment in patterns is well formed.
+func validateDenyPaths(key string, patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("%s: empty pattern", key)
+		}
+		for _, seg := range strings.Split(pattern, "/") {
+			if _, err := path.Match(seg, ""); err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %w", key, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DenyPathsFor returns the deny_paths globs for projectPath: the matching
+// project entry's list when it sets one, otherwise safety.deny_paths.
+func (c *Config) DenyPathsFor(projectPath string) []string {
+	target := filepath.Clean(expandPath(projectPath))
+	for _, proj := range c.Projects {
+		if proj.DenyPaths != nil && proj.Path != "" && filepath.Clean(expandPath(proj.Path)) == target {
+			return proj.DenyPaths
+		}
+	}
+	return c.Safety.DenyPaths
+}
+
+// ReadOnlyProject reports whether projectPath is configured read_only.
+func (c *Config) ReadOnlyProject(projectPath string) bool {
+	target := filepath.Clean(expandPath(projectPath))
+	for _, proj := range c.Projects {
+		if proj.ReadOnly && proj.Path != "" && filepath.Clean(expandPath(proj.Path)) == target {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAgentOutput returns how much agent stdout is logged, lowercased,
+// defaulting to summary.
+func (c *Config) GetAgentOutput() string {
+	if c.Logging.AgentOutput == "" {
+		return DefaultAgentOutput
+	}
+	return strings.ToLower(c.Logging.AgentOutput)
+}
+
+// GetNumberFormat returns how report views print token counts, lowercased,
+// defaulting to compact.
+func (c *Config) GetNumberFormat() string {
+	if c.Reporting.NumberFormat == "" {
+		return DefaultNumberFormat
+	}
+	return strings.ToLower(c.Reporting.NumberFormat)
+}
+
+// GetForge returns the configured code host, lowercased, defaulting to
+// GitHub.
+func (c *Config) GetForge() string {
+	if c.Orchestrator.Forge == "" {
+		return DefaultForge
+	}
+	return strings.ToLower(c.Orchestrator.Forge)
+}
+
+// GetMaxWaitForReset returns how long a run may sleep waiting for an
+// exhausted provider's budget window to reset. 0 disables waiting.
+func (c *Config) GetMaxWaitForReset() time.Duration {
+	if d, err := time.ParseDuration(c.Budget.MaxWaitForReset); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// GetTokenAccounting returns which token counters provider usage sums,
+// lowercased, defaulting to billable.
+func (c *Config) GetTokenAccounting() string {
+	if c.Budget.TokenAccounting == "" {
+		return DefaultTokenAccounting
+	}
+	return strings.ToLower(c.Budget.TokenAccounting)
+}
+
+// GetProviderStrategy returns how runs choose among providers with budget,
+// lowercased, defaulting to preference order.
+func (c *Config) GetProviderStrategy() string {
+	if c.Providers.Strategy == "" {
+		return DefaultProviderStrategy
+	}
+	return strings.ToLower(c.Providers.Strategy)
+}
+
+// GetBudgetPooling returns how provider budgets are gated, lowercased,
+// defaulting to per-provider.
+func (c *Config) GetBudgetPooling() string {
+	if c.Budget.Pooling == "" {
+		return DefaultPooling
+	}
+	return strings.ToLower(c.Budget.Pooling)
+}
+
+// GetBudgetPacing returns how the weekly budget is spread across the
+// remaining nights, lowercased, defaulting to greedy.
+func (c *Config) GetBudgetPacing() string {
+	if c.Budget.Pacing == "" {
+		return DefaultPacing
+	}
+	return strings.ToLower(c.Budget.Pacing)
+}
+
 // GetTaskPriority returns the priority for a task (higher = more important).
 func (c *Config) GetTaskPriority(task string) int {
 	if c.Tasks.Priorities != nil {
@@ -544,11 +1316,154 @@ func (c *Config) ExpandedLogPath() string {
 	return expandPath(c.Logging.Path)
 }
 
+// ExpandedReportsDir returns reporting.dir with ~ expanded, or "" when the
+// default reports dir is used.
+func (c *Config) ExpandedReportsDir() string {
+	return expandPath(c.Reporting.Dir)
+}
+
 // ExpandedDBPath returns the database path with ~ expanded.
 func (c *Config) ExpandedDBPath() string {
 	return expandPath(c.Budget.DBPath)
 }
 
+// SandboxEnabled reports whether agents run inside a container.
+func (c *Config) SandboxEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Sandbox.Type), SandboxDocker)
+}
+
+// SandboxMounts returns sandbox.mounts as docker -v values with ~ in the
+// host path expanded. Entries that fail validation are dropped.
+func (c *Config) SandboxMounts() []string {
+	var mounts []string
+	for _, m := range c.Sandbox.Mounts {
+		if mount, err := parseSandboxMount(m); err == nil {
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts
+}
+
+// parseSandboxMount checks a "host:container[:ro|rw]" mount and returns it
+// with ~ in the host path expanded. Both paths must be absolute.
+func parseSandboxMount(m string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(m), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("%q must be host:container or host:container:ro", m)
+	}
+	host := expandPath(parts[0])
+	if !filepath.IsAbs(host) || !strings.HasPrefix(parts[1], "/") {
+		return "", fmt.Errorf("%q: host and container paths must be absolute", m)
+	}
+	if len(parts) == 3 && parts[2] != "ro" && parts[2] != "rw" {
+		return "", fmt.Errorf("%q: mode must be ro or rw", m)
+	}
+	parts[0] = host
+	return strings.Join(parts, ":"), nil
+}
+
+// ProviderFallbackEnabled reports whether runs may fall through to the next
+// provider in preference order.
+func (c *Config) ProviderFallbackEnabled() bool {
+	return !strings.EqualFold(strings.TrimSpace(c.Providers.Fallback), "off")
+}
+
+// ExpandedExtraPathDirs returns providers.extra_path_dirs with environment
+// variables and ~ expanded. Blank entries are dropped.
+func (c *Config) ExpandedExtraPathDirs() []string {
+	var dirs []string
+	for _, dir := range c.Providers.ExtraPathDirs {
+		dir = strings.TrimSpace(os.ExpandEnv(dir))
+		if dir == "" {
+			continue
+		}
+		dirs = append(dirs, expandPath(dir))
+	}
+	return dirs
+}
+
+// ProviderCommand returns providers.<provider>.command with environment
+// variables and ~ expanded, or "" to use the provider's default CLI.
+func (c *Config) ProviderCommand(provider string) string {
+	pc := c.providerConfig(provider)
+	if pc == nil {
+		return ""
+	}
+	command := strings.TrimSpace(os.ExpandEnv(pc.Command))
+	if command == "" {
+		return ""
+	}
+	return expandPath(command)
+}
+
+// ProviderArgs returns the args passed to a provider's command before
+// Nightshift's own.
+func (c *Config) ProviderArgs(provider string) []string {
+	if pc := c.providerConfig(provider); pc != nil {
+		return pc.Args
+	}
+	return nil
+}
+
+func (c *Config) providerConfig(provider string) *ProviderConfig {
+	switch provider {
+	case "claude":
+		return &c.Providers.Claude
+	case "codex":
+		return &c.Providers.Codex
+	case "copilot":
+		return &c.Providers.Copilot
+	}
+	return nil
+}
+
+// validateProviderCommand checks the form of a provider's command: an
+// absolute path or a bare command name. Whether it exists is left to
+// CheckProviderCommand, so a missing wrapper doesn't stop the config from
+// loading (and doctor from reporting it).
+func validateProviderCommand(cfg *Config, provider string) error {
+	command := cfg.ProviderCommand(provider)
+	if strings.ContainsRune(command, filepath.Separator) && !filepath.IsAbs(command) {
+		return fmt.Errorf("providers.%s.command: %q must be an absolute path or a command name", provider, command)
+	}
+	return nil
+}
+
+// CheckProviderCommand checks that a provider's command exists: an
+// absolute path must be an executable file, and a bare name must be found
+// in PATH or providers.extra_path_dirs. Inside a docker sandbox the command
+// runs in the image, so it isn't looked up on the host.
+func (c *Config) CheckProviderCommand(provider string) error {
+	command := c.ProviderCommand(provider)
+	if command == "" || c.SandboxEnabled() {
+		return nil
+	}
+	key := fmt.Sprintf("providers.%s.command", provider)
+	if strings.ContainsRune(command, filepath.Separator) {
+		if !filepath.IsAbs(command) {
+			return fmt.Errorf("%s: %q must be an absolute path or a command name", key, command)
+		}
+		if !isExecutable(command) {
+			return fmt.Errorf("%s: %s is not an executable file", key, command)
+		}
+		return nil
+	}
+	if _, err := exec.LookPath(command); err == nil {
+		return nil
+	}
+	for _, dir := range c.ExpandedExtraPathDirs() {
+		if isExecutable(filepath.Join(dir, command)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %q not found in PATH or providers.extra_path_dirs", key, command)
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
 // ExpandedProviderPath returns the provider data path with ~ expanded.
 func (c *Config) ExpandedProviderPath(provider string) string {
 	switch provider {
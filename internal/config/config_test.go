@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -34,6 +35,17 @@ func TestValidate_InvalidBudgetMode(t *testing.T) {
 	}
 }
 
+func TestValidate_AutoBudgetMode(t *testing.T) {
+	cfg := &Config{
+		Budget: BudgetConfig{
+			Mode: "auto",
+		},
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("expected auto mode to be valid, got %v", err)
+	}
+}
+
 func TestValidate_InvalidBillingMode(t *testing.T) {
 	cfg := &Config{
 		Budget: BudgetConfig{
@@ -94,6 +106,887 @@ func TestValidate_InvalidLogFormat(t *testing.T) {
 	}
 }
 
+func TestValidate_InvalidReportRetention(t *testing.T) {
+	cfg := &Config{
+		Reporting: ReportingConfig{
+			RetentionDays: -1,
+		},
+	}
+	err := Validate(cfg)
+	if err != ErrInvalidReportRetention {
+		t.Errorf("expected ErrInvalidReportRetention, got %v", err)
+	}
+}
+
+func TestValidate_InvalidMinScore(t *testing.T) {
+	cfg := &Config{
+		Scoring: ScoringConfig{
+			MinScore: -1,
+		},
+	}
+	err := Validate(cfg)
+	if err != ErrInvalidMinScore {
+		t.Errorf("expected ErrInvalidMinScore, got %v", err)
+	}
+}
+
+func TestValidate_ShutdownGrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"minutes", "10m", false},
+		{"zero", "0s", false},
+		{"negative", "-1m", true},
+		{"garbage", "soon", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Orchestrator: OrchestratorConfig{ShutdownGrace: tt.grace}}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.grace, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_RecentFailureWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  string
+		wantErr bool
+		want    time.Duration
+	}{
+		{"empty", "", false, 0},
+		{"hours", "48h", false, 48 * time.Hour},
+		{"zero", "0", false, 0},
+		{"negative", "-1h", true, 0},
+		{"garbage", "two days", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Tasks: TasksConfig{RecentFailureWindow: tt.window}}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q) error = %v, wantErr %v", tt.window, err, tt.wantErr)
+			}
+			if err == nil && cfg.RecentFailureWindow() != tt.want {
+				t.Errorf("RecentFailureWindow() = %v, want %v", cfg.RecentFailureWindow(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ProjectTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"hours", "2h", false},
+		{"negative", "-1m", true},
+		{"garbage", "forever", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Orchestrator: OrchestratorConfig{ProjectTimeout: tt.timeout}}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.timeout, err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{Orchestrator: OrchestratorConfig{ProjectTimeout: "45m"}}
+	if got := cfg.GetProjectTimeout(); got != 45*time.Minute {
+		t.Errorf("GetProjectTimeout() = %v, want 45m", got)
+	}
+}
+
+func TestRenderBranchName(t *testing.T) {
+	start := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		tmpl    string
+		data    BranchData
+		want    string
+		wantErr bool
+	}{
+		{"default", DefaultBranchTemplate, NewBranchData("lint-fix", "/code/myapp", "claude", start), "nightshift/lint-fix/20260301-023000", false},
+		{"project and provider", "ns/{{.Project}}/{{.Provider}}-{{.TaskType}}", NewBranchData("docs-backfill", "/code/My App", "codex", start), "ns/My-App/codex-docs-backfill", false},
+		{"unknown field", "ns/{{.Nope}}", NewBranchData("lint-fix", "/code/myapp", "claude", start), "", true},
+		{"bad syntax", "ns/{{.TaskType", NewBranchData("lint-fix", "/code/myapp", "claude", start), "", true},
+		{"empty result", "{{.Provider}}", BranchData{}, "", true},
+		{"invalid ref", "ns..{{.TaskType}}", NewBranchData("lint-fix", "/code/myapp", "claude", start), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderBranchName(tt.tmpl, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderBranchName(%q) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderBranchName(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidBranchName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"nightshift/lint-fix/20260301", true},
+		{"feature.v2", true},
+		{"", false},
+		{"@", false},
+		{"-x", false},
+		{"a/", false},
+		{"a.", false},
+		{"a..b", false},
+		{"a//b", false},
+		{"a@{b", false},
+		{"a b", false},
+		{"a~1", false},
+		{"a:b", false},
+		{"a/.hidden", false},
+		{"a/b.lock", false},
+	}
+	for _, tt := range tests {
+		if err := ValidBranchName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("ValidBranchName(%q) = %v, want valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestValidate_BranchTemplate(t *testing.T) {
+	cfg := &Config{Orchestrator: OrchestratorConfig{BranchTemplate: "ns/{{.Missing}}"}}
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "orchestrator.branch_template") {
+		t.Errorf("Validate() error = %v, want orchestrator.branch_template error", err)
+	}
+
+	cfg = &Config{Projects: []ProjectConfig{{Path: "/code/app", BranchTemplate: "bad branch"}}}
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "branch_template") {
+		t.Errorf("Validate() error = %v, want project branch_template error", err)
+	}
+
+	cfg = &Config{
+		Orchestrator: OrchestratorConfig{BranchTemplate: "ns/{{.TaskType}}"},
+		Projects:     []ProjectConfig{{Path: "/code/app", BranchTemplate: "app/{{.TaskType}}-{{.Date}}"}},
+	}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got, _ := cfg.WorkBranchName("/code/app", "lint-fix", "claude", start); got != "app/lint-fix-20260301" {
+		t.Errorf("project WorkBranchName = %q", got)
+	}
+	if got, _ := cfg.WorkBranchName("/code/other", "lint-fix", "claude", start); got != "ns/lint-fix" {
+		t.Errorf("global WorkBranchName = %q", got)
+	}
+	if got := (&Config{}).BranchTemplateFor("/code/other"); got != DefaultBranchTemplate {
+		t.Errorf("BranchTemplateFor() = %q, want default", got)
+	}
+}
+
+func TestGetShutdownGrace(t *testing.T) {
+	cfg := &Config{Orchestrator: OrchestratorConfig{ShutdownGrace: "90s"}}
+	if got := cfg.GetShutdownGrace(); got != 90*time.Second {
+		t.Errorf("GetShutdownGrace() = %v, want 90s", got)
+	}
+	cfg.Orchestrator.ShutdownGrace = ""
+	if got := cfg.GetShutdownGrace(); got != 10*time.Minute {
+		t.Errorf("GetShutdownGrace() default = %v, want 10m", got)
+	}
+}
+
+func TestStatusCacheTTL(t *testing.T) {
+	tests := []struct {
+		ttl     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 3 * time.Minute, false},
+		{"30s", 30 * time.Second, false},
+		{"0", 0, false},
+		{"-1m", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Providers: ProvidersConfig{StatusCacheTTL: tt.ttl}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.ttl, err, tt.wantErr)
+		}
+		if !tt.wantErr {
+			if got := cfg.GetStatusCacheTTL(); got != tt.want {
+				t.Errorf("GetStatusCacheTTL(%q) = %v, want %v", tt.ttl, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestUsageTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 30 * time.Second, false},
+		{"5s", 5 * time.Second, false},
+		{"0", 0, false},
+		{"-1s", 0, true},
+		{"slow", 0, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Providers: ProvidersConfig{UsageTimeout: tt.timeout}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.timeout, err, tt.wantErr)
+		}
+		if !tt.wantErr {
+			if got := cfg.GetUsageTimeout(); got != tt.want {
+				t.Errorf("GetUsageTimeout(%q) = %v, want %v", tt.timeout, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestValidate_Forge(t *testing.T) {
+	tests := []struct {
+		forge   string
+		wantErr bool
+	}{
+		{"", false},
+		{"github", false},
+		{"GitLab", false},
+		{"gitea", false},
+		{"bitbucket", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Orchestrator: OrchestratorConfig{Forge: tt.forge}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(forge %q) error = %v, wantErr %v", tt.forge, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetForge(); got != "github" {
+		t.Errorf("GetForge() default = %q, want github", got)
+	}
+	if got := (&Config{Orchestrator: OrchestratorConfig{Forge: "GitLab"}}).GetForge(); got != "gitlab" {
+		t.Errorf("GetForge() = %q, want gitlab", got)
+	}
+}
+
+func TestValidate_TokenAccounting(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{"billable", false},
+		{"Raw", false},
+		{"cached", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Budget: BudgetConfig{TokenAccounting: tt.mode}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(token_accounting %q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetTokenAccounting(); got != "billable" {
+		t.Errorf("GetTokenAccounting() default = %q, want billable", got)
+	}
+	if got := (&Config{Budget: BudgetConfig{TokenAccounting: "RAW"}}).GetTokenAccounting(); got != "raw" {
+		t.Errorf("GetTokenAccounting() = %q, want raw", got)
+	}
+}
+
+func TestValidate_MaxDailyShare(t *testing.T) {
+	tests := []struct {
+		share   float64
+		wantErr bool
+	}{
+		{0, false},
+		{0.3, false},
+		{1, false},
+		{-0.1, true},
+		{1.5, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Budget: BudgetConfig{MaxDailyShare: tt.share}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(max_daily_share %v) error = %v, wantErr %v", tt.share, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_BudgetPooling(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{"per-provider", false},
+		{"Pooled", false},
+		{"shared", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Budget: BudgetConfig{Pooling: tt.mode}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(pooling %q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetBudgetPooling(); got != PoolingPerProvider {
+		t.Errorf("GetBudgetPooling() default = %q, want %s", got, PoolingPerProvider)
+	}
+}
+
+func TestValidate_BudgetPacing(t *testing.T) {
+	tests := []struct {
+		pacing  string
+		wantErr bool
+	}{
+		{"", false},
+		{"greedy", false},
+		{"Even", false},
+		{"front-loaded", false},
+		{"back-loaded", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Budget: BudgetConfig{Pacing: tt.pacing}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(pacing %q) error = %v, wantErr %v", tt.pacing, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{Budget: BudgetConfig{Pacing: "Even"}}).GetBudgetPacing(); got != PacingEven {
+		t.Errorf("GetBudgetPacing() = %q, want %s", got, PacingEven)
+	}
+	if got := (&Config{}).GetBudgetPacing(); got != PacingGreedy {
+		t.Errorf("GetBudgetPacing() default = %q, want %s", got, PacingGreedy)
+	}
+}
+
+func TestValidate_ProviderStrategy(t *testing.T) {
+	tests := []struct {
+		strategy string
+		wantErr  bool
+	}{
+		{"", false},
+		{"preference", false},
+		{"Least-Used", false},
+		{"most-budget", false},
+		{"round-robin", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Providers: ProvidersConfig{Strategy: tt.strategy}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(strategy %q) error = %v, wantErr %v", tt.strategy, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetProviderStrategy(); got != StrategyPreference {
+		t.Errorf("GetProviderStrategy() default = %q, want %s", got, StrategyPreference)
+	}
+	if got := (&Config{Providers: ProvidersConfig{Strategy: "Least-Used"}}).GetProviderStrategy(); got != StrategyLeastUsed {
+		t.Errorf("GetProviderStrategy() = %q, want %s", got, StrategyLeastUsed)
+	}
+}
+
+func TestValidate_NumberFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format  string
+		wantErr bool
+	}{
+		{"", false},
+		{"compact", false},
+		{"Grouped", false},
+		{"raw", false},
+		{"si", true},
+	} {
+		cfg := &Config{Reporting: ReportingConfig{NumberFormat: tt.format}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(number_format %q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetNumberFormat(); got != "compact" {
+		t.Errorf("GetNumberFormat() default = %q, want compact", got)
+	}
+}
+
+func TestValidate_AgentOutput(t *testing.T) {
+	for _, tt := range []struct {
+		mode    string
+		wantErr bool
+	}{
+		{"", false},
+		{"none", false},
+		{"Summary", false},
+		{"full", false},
+		{"verbose", true},
+	} {
+		cfg := &Config{Logging: LoggingConfig{AgentOutput: tt.mode}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(agent_output %q) error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+	}
+	if got := (&Config{}).GetAgentOutput(); got != "summary" {
+		t.Errorf("GetAgentOutput() default = %q, want summary", got)
+	}
+}
+
+func TestValidate_ProviderOverflow(t *testing.T) {
+	for _, tt := range []struct {
+		overflow []string
+		wantErr  bool
+	}{
+		{nil, false},
+		{[]string{"codex"}, false},
+		{[]string{"Codex", "copilot"}, false},
+		{[]string{"gemini"}, true},
+		{[]string{"codex", "codex"}, true},
+	} {
+		cfg := &Config{Providers: ProvidersConfig{Overflow: tt.overflow}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(overflow %v) error = %v, wantErr %v", tt.overflow, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_TierPreference(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		tiers   map[string][]string
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"valid", map[string][]string{"low": {"copilot", "codex"}, "very-high": {"Claude"}}, false},
+		{"unknown tier", map[string][]string{"cheap": {"copilot"}}, true},
+		{"unknown provider", map[string][]string{"low": {"gemini"}}, true},
+		{"duplicate provider", map[string][]string{"high": {"claude", "claude"}}, true},
+	} {
+		cfg := &Config{Providers: ProvidersConfig{TierPreference: tt.tiers}}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDaemonHTTPAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{":8787", "127.0.0.1:8787", false},
+		{"0.0.0.0:8787", "0.0.0.0:8787", false},
+		{"[::1]:9000", "[::1]:9000", false},
+		{"8787", "", true},
+		{"localhost:", "", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Daemon: DaemonConfig{HTTPAddr: tt.addr}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(http_addr %q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			if got := cfg.DaemonHTTPAddr(); got != tt.want {
+				t.Errorf("DaemonHTTPAddr(%q) = %q, want %q", tt.addr, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestValidate_Sandbox(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		sandbox SandboxConfig
+		wantErr bool
+	}{
+		{"unset", SandboxConfig{}, false},
+		{"none", SandboxConfig{Type: "none"}, false},
+		{"docker", SandboxConfig{Type: "docker", Image: "agents:latest", Mounts: []string{"~/.claude:/home/agent/.claude", "/opt/cache:/cache:ro"}}, false},
+		{"docker without image", SandboxConfig{Type: "docker"}, true},
+		{"unknown type", SandboxConfig{Type: "podman", Image: "agents:latest"}, true},
+		{"relative mount", SandboxConfig{Type: "docker", Image: "agents:latest", Mounts: []string{"cache:/cache"}}, true},
+		{"bad mount mode", SandboxConfig{Type: "docker", Image: "agents:latest", Mounts: []string{"/cache:/cache:rx"}}, true},
+		{"mount without target", SandboxConfig{Type: "docker", Image: "agents:latest", Mounts: []string{"/cache"}}, true},
+	} {
+		cfg := &Config{Sandbox: tt.sandbox}
+		if err := Validate(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSandboxMounts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg := &Config{Sandbox: SandboxConfig{Type: "Docker", Mounts: []string{"~/.claude:/home/agent/.claude:ro", "bad"}}}
+	if !cfg.SandboxEnabled() {
+		t.Error("SandboxEnabled() = false for type Docker")
+	}
+	got := cfg.SandboxMounts()
+	if want := filepath.Join(home, ".claude") + ":/home/agent/.claude:ro"; len(got) != 1 || got[0] != want {
+		t.Errorf("SandboxMounts() = %v, want [%s]", got, want)
+	}
+}
+
+func TestDenyPathsFor(t *testing.T) {
+	cfg := &Config{
+		Safety: SafetyConfig{DenyPaths: []string{"migrations/", "vendor/**"}},
+		Projects: []ProjectConfig{
+			{Path: "/code/app", DenyPaths: []string{"schema/*.sql"}},
+			{Path: "/code/open", DenyPaths: []string{}},
+			{Path: "/code/plain"},
+		},
+	}
+	tests := []struct {
+		project string
+		want    string
+	}{
+		{"/code/app/", "schema/*.sql"},
+		{"/code/open", ""},
+		{"/code/plain", "migrations/,vendor/**"},
+		{"/code/unlisted", "migrations/,vendor/**"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(cfg.DenyPathsFor(tt.project), ","); got != tt.want {
+			t.Errorf("DenyPathsFor(%q) = %q, want %q", tt.project, got, tt.want)
+		}
+	}
+
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	cfg.Safety.DenyPaths = []string{"migrations/[a-"}
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "safety.deny_paths") {
+		t.Errorf("Validate() = %v, want safety.deny_paths error", err)
+	}
+}
+
+func TestValidateWindowCategories(t *testing.T) {
+	cfg := &Config{Schedule: ScheduleConfig{Window: &WindowConfig{Categories: []string{"analysis", "Map"}}}}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	cfg.Schedule.Window.Categories = []string{"analysis", "refactor"}
+	if err := Validate(cfg); err == nil {
+		t.Error("Validate() = nil for unknown category, want error")
+	}
+}
+
+func TestValidateWindowStartupDelay(t *testing.T) {
+	tests := []struct {
+		delay   string
+		wantErr bool
+	}{
+		{"", false},
+		{"20m", false},
+		{"1h30m", false},
+		{"-5m", true},
+		{"20", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Schedule: ScheduleConfig{Window: &WindowConfig{StartupDelay: tt.delay}}}
+		err := Validate(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(startup_delay %q) error = %v, wantErr %v", tt.delay, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateThemes(t *testing.T) {
+	tests := []struct {
+		name    string
+		themes  map[string][]string
+		wantErr string
+	}{
+		{name: "categories and types", themes: map[string][]string{"monday": {"map", "docs-backfill"}, "Tuesday": {"analysis"}}},
+		{name: "unknown day", themes: map[string][]string{"mon": {"map"}}, wantErr: `unknown day "mon"`},
+		{name: "bad entry", themes: map[string][]string{"friday": {"Docs Backfill"}}, wantErr: "schedule.themes.friday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&Config{Schedule: ScheduleConfig{Themes: tt.themes}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	cfg := &Config{Schedule: ScheduleConfig{Themes: map[string][]string{"Monday": {"map"}}}}
+	monday := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+	if day, entries := cfg.ThemeFor(monday); day != "monday" || len(entries) != 1 || entries[0] != "map" {
+		t.Errorf("ThemeFor(monday) = %q, %v", day, entries)
+	}
+	if day, entries := cfg.ThemeFor(monday.Add(time.Hour)); day != "tuesday" || entries != nil {
+		t.Errorf("ThemeFor(tuesday) = %q, %v, want no theme", day, entries)
+	}
+	// The window timezone decides the day: 01:30 UTC Tuesday is Monday in Denver.
+	cfg.Schedule.Window = &WindowConfig{Timezone: "America/Denver"}
+	if day, _ := cfg.ThemeFor(monday.Add(2 * time.Hour)); day != "monday" {
+		t.Errorf("ThemeFor in Denver = %q, want monday", day)
+	}
+}
+
+func TestProcessedSince(t *testing.T) {
+	now := time.Date(2026, 3, 4, 1, 0, 0, 0, time.UTC)
+	tests := []struct {
+		window  string
+		want    time.Time
+		wantErr bool
+	}{
+		{window: "", want: now.Add(-20 * time.Hour)},
+		{window: "12h", want: now.Add(-12 * time.Hour)},
+		{window: "calendar", want: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{window: "0s", wantErr: true},
+		{window: "nightly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			cfg := &Config{Schedule: ScheduleConfig{ProcessedWindow: tt.window}}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := cfg.ProcessedSince(now); !got.Equal(tt.want) {
+				t.Errorf("ProcessedSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxWaitForReset(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0s", want: 0},
+		{value: "45m", want: 45 * time.Minute},
+		{value: "-5m", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			cfg := &Config{Budget: BudgetConfig{MaxWaitForReset: tt.value}}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := cfg.GetMaxWaitForReset(); got != tt.want {
+				t.Errorf("GetMaxWaitForReset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandedExtraPathDirs(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home dir")
+	}
+	t.Setenv("ASDF_DIR", "/opt/asdf")
+
+	cfg := &Config{Providers: ProvidersConfig{ExtraPathDirs: []string{
+		"~/.volta/bin",
+		"$ASDF_DIR/shims",
+		"  ",
+		"/usr/pnpm",
+	}}}
+	want := []string{filepath.Join(home, ".volta", "bin"), "/opt/asdf/shims", "/usr/pnpm"}
+	if got := cfg.ExpandedExtraPathDirs(); !slices.Equal(got, want) {
+		t.Errorf("ExpandedExtraPathDirs() = %v, want %v", got, want)
+	}
+	if err := Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Providers.ExtraPathDirs = []string{"node_modules/.bin"}
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), "providers.extra_path_dirs") {
+		t.Errorf("Validate() error = %v, want relative path rejected", err)
+	}
+}
+
+func TestCheckProviderCommand(t *testing.T) {
+	dir := t.TempDir()
+	wrapper := filepath.Join(dir, "claude-wrapper")
+	if err := os.WriteFile(wrapper, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(plain, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only a malformed command fails Validate; a missing one is left to
+	// CheckProviderCommand so the config still loads for doctor.
+	tests := []struct {
+		name    string
+		command string
+		extra   []string
+		sandbox string
+		wantErr string
+		invalid bool // Validate rejects it too
+	}{
+		{name: "unset"},
+		{name: "absolute wrapper", command: wrapper},
+		{name: "name in extra_path_dirs", command: "claude-wrapper", extra: []string{dir}},
+		{name: "missing file", command: filepath.Join(dir, "missing"), wantErr: "not an executable file"},
+		{name: "not executable", command: plain, wantErr: "not an executable file"},
+		{name: "directory", command: dir, wantErr: "not an executable file"},
+		{name: "relative path", command: "bin/claude-wrapper", wantErr: "must be an absolute path", invalid: true},
+		{name: "name not found", command: "nightshift-no-such-wrapper", wantErr: "not found in PATH"},
+		{name: "sandboxed skips host check", command: "/usr/local/bin/in-image-only", sandbox: SandboxDocker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Providers: ProvidersConfig{
+					ExtraPathDirs: tt.extra,
+					Codex:         ProviderConfig{Command: tt.command},
+				},
+				Sandbox: SandboxConfig{Type: tt.sandbox, Image: "nightshift-agent"},
+			}
+			if err := Validate(cfg); (err != nil) != tt.invalid {
+				t.Errorf("Validate() error = %v, want error %t", err, tt.invalid)
+			}
+			err := cfg.CheckProviderCommand("codex")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckProviderCommand() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "providers.codex.command") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckProviderCommand() error = %v, want providers.codex.command %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviderCommand(t *testing.T) {
+	t.Setenv("WRAPPERS", "/opt/wrappers")
+	cfg := &Config{Providers: ProvidersConfig{
+		Claude: ProviderConfig{Command: "$WRAPPERS/claude", Args: []string{"--profile", "night"}},
+		Codex:  ProviderConfig{Command: "  "},
+	}}
+	if got := cfg.ProviderCommand("claude"); got != "/opt/wrappers/claude" {
+		t.Errorf("ProviderCommand(claude) = %q", got)
+	}
+	if got := cfg.ProviderArgs("claude"); !slices.Equal(got, []string{"--profile", "night"}) {
+		t.Errorf("ProviderArgs(claude) = %v", got)
+	}
+	if got := cfg.ProviderCommand("codex"); got != "" {
+		t.Errorf("ProviderCommand(codex) = %q, want empty", got)
+	}
+	if got := cfg.ProviderCommand("unknown"); got != "" {
+		t.Errorf("ProviderCommand(unknown) = %q, want empty", got)
+	}
+}
+
+func TestTaskTokenCaps(t *testing.T) {
+	cfg := &Config{Tasks: TasksConfig{TokenCaps: map[string]int{
+		"migration-rehearsal": 300000,
+		"lint-fix":            0,
+	}}}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if limit, ok := cfg.GetTaskTokenCap("migration-rehearsal"); !ok || limit != 300000 {
+		t.Errorf("GetTaskTokenCap(migration-rehearsal) = %d, %t; want 300000, true", limit, ok)
+	}
+	if limit, ok := cfg.GetTaskTokenCap("lint-fix"); !ok || limit != 0 {
+		t.Errorf("GetTaskTokenCap(lint-fix) = %d, %t; want 0, true", limit, ok)
+	}
+	if _, ok := cfg.GetTaskTokenCap("dead-code"); ok {
+		t.Error("GetTaskTokenCap(dead-code) should be unset")
+	}
+
+	cfg.Tasks.TokenCaps["lint-fix"] = -1
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), `tasks.token_caps["lint-fix"]`) {
+		t.Errorf("Validate() error = %v, want negative cap rejected", err)
+	}
+}
+
+func TestTaskModels(t *testing.T) {
+	cfg := &Config{Tasks: TasksConfig{Models: map[string]string{
+		"lint-fix":      "haiku",
+		"bug-finder":    "claude-opus-4-1",
+		"docs-backfill": "gpt-5-mini",
+	}}}
+	if err := Validate(cfg); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		task, provider, want string
+	}{
+		{"lint-fix", "claude", "haiku"},
+		{"lint-fix", "codex", ""},
+		{"lint-fix", "copilot", "haiku"},
+		{"docs-backfill", "codex", "gpt-5-mini"},
+		{"docs-backfill", "claude", ""},
+		{"bug-finder", "claude", "claude-opus-4-1"},
+		{"dead-code", "claude", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.GetTaskModel(tt.task, tt.provider); got != tt.want {
+			t.Errorf("GetTaskModel(%q, %q) = %q, want %q", tt.task, tt.provider, got, tt.want)
+		}
+	}
+
+	cfg.Tasks.Models["lint-fix"] = "sonet"
+	if err := Validate(cfg); err == nil || !strings.Contains(err.Error(), `tasks.models["lint-fix"]`) {
+		t.Errorf("Validate() error = %v, want unknown model for lint-fix", err)
+	}
+}
+
+func TestModelFamily(t *testing.T) {
+	tests := map[string]string{
+		"sonnet":          "claude",
+		"Opus":            "claude",
+		"claude-sonnet-4": "claude",
+		"gpt-5":           "openai",
+		"gpt-5.1-codex":   "openai",
+		"o3":              "openai",
+		"o4-mini":         "openai",
+		"":                "",
+		"llama3":          "",
+	}
+	for model, want := range tests {
+		if got := ModelFamily(model); got != want {
+			t.Errorf("ModelFamily(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
 func TestValidate_ValidConfig(t *testing.T) {
 	cfg := &Config{
 		Schedule: ScheduleConfig{
@@ -416,6 +1309,25 @@ func TestValidate_ValidTaskInterval(t *testing.T) {
 	}
 }
 
+func TestDefaults(t *testing.T) {
+	cfg, err := Defaults()
+	if err != nil {
+		t.Fatalf("Defaults error: %v", err)
+	}
+	if cfg.Budget.Mode != DefaultBudgetMode {
+		t.Errorf("Budget.Mode = %q, want %q", cfg.Budget.Mode, DefaultBudgetMode)
+	}
+	if cfg.Budget.MaxPercent != DefaultMaxPercent {
+		t.Errorf("Budget.MaxPercent = %d, want %d", cfg.Budget.MaxPercent, DefaultMaxPercent)
+	}
+	if cfg.Logging.Format != DefaultLogFormat {
+		t.Errorf("Logging.Format = %q, want %q", cfg.Logging.Format, DefaultLogFormat)
+	}
+	if len(cfg.Projects) != 0 {
+		t.Errorf("Projects = %v, want empty", cfg.Projects)
+	}
+}
+
 func TestLoadFromPaths_Defaults(t *testing.T) {
 	tmpDir := t.TempDir()
 
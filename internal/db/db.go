@@ -4,6 +4,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +18,19 @@ type DB struct {
 	path string
 }
 
+// maxOpenConns caps the connection pool. WAL allows one writer at a time,
+// so a small pool keeps in-process writers from piling up on the lock.
+const maxOpenConns = 4
+
+// connPragmas are applied to every pooled connection through the DSN. A
+// PRAGMA run with Exec only reaches whichever connection served it. The
+// busy timeout comes first so the WAL switch also waits out other processes.
+var connPragmas = []string{
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+	"foreign_keys(1)",
+}
+
 // DefaultPath returns the default database path.
 func DefaultPath() string {
 	home, _ := os.UserHomeDir()
@@ -25,6 +39,20 @@ func DefaultPath() string {
 
 // Open opens or creates the database, applies pragmas, and runs migrations.
 func Open(dbPath string) (*DB, error) {
+	d, err := OpenUnmigrated(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Migrate(); err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// OpenUnmigrated opens or creates the database and applies pragmas but runs
+// no migrations, so the schema can be inspected or backed up first.
+func OpenUnmigrated(dbPath string) (*DB, error) {
 	if dbPath == "" {
 		dbPath = DefaultPath()
 	}
@@ -35,32 +63,32 @@ func Open(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("creating db dir: %w", err)
 	}
 
-	sqlDB, err := sql.Open("sqlite", resolved)
+	sqlDB, err := sql.Open("sqlite", dsn(resolved))
 	if err != nil {
 		return nil, fmt.Errorf("opening db: %w", err)
 	}
+	sqlDB.SetMaxOpenConns(maxOpenConns)
 
 	if err := sqlDB.Ping(); err != nil {
 		_ = sqlDB.Close()
 		return nil, fmt.Errorf("ping db: %w", err)
 	}
 
-	if err := applyPragmas(sqlDB); err != nil {
-		_ = sqlDB.Close()
-		return nil, err
-	}
+	return &DB{sql: sqlDB, path: resolved}, nil
+}
 
-	if err := Migrate(sqlDB); err != nil {
-		_ = sqlDB.Close()
-		return nil, err
+// Migrate runs pending migrations and the one-time legacy state import,
+// returning the migrations it applied. It is a no-op on an up-to-date
+// database.
+func (d *DB) Migrate() ([]Migration, error) {
+	applied, err := MigratePending(d.sql)
+	if err != nil {
+		return applied, err
 	}
-
-	if err := importLegacyState(sqlDB); err != nil {
-		_ = sqlDB.Close()
-		return nil, err
+	if err := importLegacyState(d.sql); err != nil {
+		return applied, err
 	}
-
-	return &DB{sql: sqlDB, path: resolved}, nil
+	return applied, nil
 }
 
 // Close closes the underlying database connection.
@@ -71,6 +99,14 @@ func (d *DB) Close() error {
 	return d.sql.Close()
 }
 
+// Path returns the resolved database file path.
+func (d *DB) Path() string {
+	if d == nil {
+		return ""
+	}
+	return d.path
+}
+
 // SQL returns the raw *sql.DB for advanced usage.
 func (d *DB) SQL() *sql.DB {
 	if d == nil {
@@ -79,18 +115,13 @@ func (d *DB) SQL() *sql.DB {
 	return d.sql
 }
 
-func applyPragmas(db *sql.DB) error {
-	pragmas := []string{
-		"PRAGMA journal_mode=WAL;",
-		"PRAGMA busy_timeout=5000;",
-		"PRAGMA foreign_keys=ON;",
-	}
-	for _, pragma := range pragmas {
-		if _, err := db.Exec(pragma); err != nil {
-			return fmt.Errorf("setting pragma %q: %w", pragma, err)
-		}
+// dsn returns the connection string for path with connPragmas attached.
+func dsn(path string) string {
+	q := url.Values{}
+	for _, pragma := range connPragmas {
+		q.Add("_pragma", pragma)
 	}
-	return nil
+	return path + "?" + q.Encode()
 }
 
 func expandPath(path string) string {
@@ -2,8 +2,11 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestOpenCreatesSchema(t *testing.T) {
@@ -36,6 +39,63 @@ func TestOpenCreatesSchema(t *testing.T) {
 	}
 }
 
+func TestConcurrentWritersAcrossConnections(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dbPath := filepath.Join(t.TempDir(), "nightshift.db")
+
+	first, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open first: %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	second, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open second: %v", err)
+	}
+	defer func() { _ = second.Close() }()
+
+	const writesPerWriter = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*writesPerWriter)
+	for w, database := range []*DB{first, second, first, second} {
+		wg.Add(1)
+		go func(w int, database *DB) {
+			defer wg.Done()
+			for i := 0; i < writesPerWriter; i++ {
+				_, err := database.SQL().Exec(
+					`INSERT INTO projects (path, last_run, run_count) VALUES (?, ?, 1)
+					 ON CONFLICT(path) DO UPDATE SET run_count = projects.run_count + 1`,
+					fmt.Sprintf("/project/%d", w%2), time.Now(),
+				)
+				if err != nil {
+					errs <- err
+				}
+			}
+		}(w, database)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent write: %v", err)
+	}
+
+	var total int
+	if err := first.SQL().QueryRow(`SELECT SUM(run_count) FROM projects`).Scan(&total); err != nil {
+		t.Fatalf("sum run_count: %v", err)
+	}
+	if total != 4*writesPerWriter {
+		t.Errorf("total run_count = %d, want %d", total, 4*writesPerWriter)
+	}
+
+	var timeout int
+	if err := second.SQL().QueryRow(`PRAGMA busy_timeout`).Scan(&timeout); err != nil {
+		t.Fatalf("query busy_timeout: %v", err)
+	}
+	if timeout != 5000 {
+		t.Errorf("busy_timeout = %d, want 5000", timeout)
+	}
+}
+
 func TestOpenIdempotent(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
 	dbPath := filepath.Join(t.TempDir(), "nightshift.db")
@@ -175,3 +235,89 @@ func columnExists(t *testing.T, db *sql.DB, table, column string) bool {
 	}
 	return false
 }
+
+func TestInfoBackupAndMigrate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	orig := make([]Migration, len(migrations))
+	copy(orig, migrations)
+	defer func() {
+		migrations = orig
+	}()
+
+	// Create a database as an older build would have left it.
+	dbPath := filepath.Join(t.TempDir(), "nightshift.db")
+	migrations = orig[:5]
+	database, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if _, err := database.SQL().Exec(`INSERT INTO projects (path, run_count) VALUES ('/p', 1)`); err != nil {
+		t.Fatalf("insert project: %v", err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("close db: %v", err)
+	}
+	migrations = orig
+
+	database, err = OpenUnmigrated(dbPath)
+	if err != nil {
+		t.Fatalf("open unmigrated: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	info, err := database.Info()
+	if err != nil {
+		t.Fatalf("info: %v", err)
+	}
+	if info.Version != 5 || info.Latest != LatestVersion() {
+		t.Fatalf("version = %d latest = %d, want 5 and %d", info.Version, info.Latest, LatestVersion())
+	}
+	if len(info.Applied) != 5 || info.Applied[0].Description == "" || info.Applied[0].AppliedAt.IsZero() {
+		t.Errorf("applied = %+v", info.Applied)
+	}
+	if len(info.Pending) != LatestVersion()-5 || info.Pending[0].Version != 6 {
+		t.Errorf("pending = %+v", info.Pending)
+	}
+	if info.Size == 0 {
+		t.Error("size = 0")
+	}
+	rows := map[string]int64{}
+	for _, tc := range info.Tables {
+		rows[tc.Name] = tc.Rows
+	}
+	if rows["projects"] != 1 || rows["schema_version"] != 5 {
+		t.Errorf("table rows = %v", rows)
+	}
+
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	if err := database.Backup(backup); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if err := database.Backup(backup); err == nil {
+		t.Error("second backup to the same path succeeded")
+	}
+
+	applied, err := database.Migrate()
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(applied) != LatestVersion()-5 {
+		t.Errorf("applied %d migrations, want %d", len(applied), LatestVersion()-5)
+	}
+	if applied, err := database.Migrate(); err != nil || len(applied) != 0 {
+		t.Errorf("second migrate = %v, %v; want nothing applied", applied, err)
+	}
+
+	saved, err := OpenUnmigrated(backup)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	defer func() { _ = saved.Close() }()
+	savedInfo, err := saved.Info()
+	if err != nil {
+		t.Fatalf("backup info: %v", err)
+	}
+	if savedInfo.Version != 5 {
+		t.Errorf("backup version = %d, want 5", savedInfo.Version)
+	}
+}
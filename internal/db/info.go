@@ -0,0 +1,131 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Info describes the database file and its schema.
+type Info struct {
+	Path    string             `json:"path"`
+	Size    int64              `json:"size_bytes"` // main file plus WAL
+	Version int                `json:"version"`
+	Latest  int                `json:"latest_version"`
+	Applied []AppliedMigration `json:"applied"`
+	Pending []Migration        `json:"pending"`
+	Tables  []TableCount       `json:"tables"`
+}
+
+// AppliedMigration is a row of schema_version.
+type AppliedMigration struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description,omitempty"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// TableCount is the row count of one table.
+type TableCount struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// Info reports the schema version, applied and pending migrations, file size
+// and per-table row counts. It changes nothing, so it works on a database
+// opened with OpenUnmigrated.
+func (d *DB) Info() (*Info, error) {
+	info := &Info{Path: d.path, Latest: LatestVersion()}
+	for _, p := range []string{d.path, d.path + "-wal"} {
+		if st, err := os.Stat(p); err == nil {
+			info.Size += st.Size()
+		}
+	}
+
+	tables, err := d.tableNames()
+	if err != nil {
+		return nil, err
+	}
+	hasVersions := false
+	for _, name := range tables {
+		var rows int64
+		if err := d.sql.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %q`, name)).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("count %s: %w", name, err)
+		}
+		info.Tables = append(info.Tables, TableCount{Name: name, Rows: rows})
+		if name == "schema_version" {
+			hasVersions = true
+		}
+	}
+
+	if hasVersions {
+		if info.Applied, err = d.appliedMigrations(); err != nil {
+			return nil, err
+		}
+		if info.Version, err = CurrentVersion(d.sql); err != nil {
+			return nil, err
+		}
+	}
+	info.Pending = PendingMigrations(info.Version)
+	return info, nil
+}
+
+func (d *DB) tableNames() ([]string, error) {
+	rows, err := d.sql.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("list tables: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (d *DB) appliedMigrations() ([]AppliedMigration, error) {
+	descriptions := make(map[int]string, len(migrations))
+	for _, m := range migrations {
+		descriptions[m.Version] = m.Description
+	}
+
+	rows, err := d.sql.Query(`SELECT version, applied_at FROM schema_version ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_version: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var applied []AppliedMigration
+	for rows.Next() {
+		var m AppliedMigration
+		var at sql.NullTime
+		if err := rows.Scan(&m.Version, &at); err != nil {
+			return nil, fmt.Errorf("scan schema_version: %w", err)
+		}
+		m.AppliedAt = at.Time
+		m.Description = descriptions[m.Version]
+		applied = append(applied, m)
+	}
+	return applied, rows.Err()
+}
+
+// Backup writes a consistent copy of the database, including changes still
+// in the WAL, to dest. dest must not exist.
+func (d *DB) Backup(dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup %s: file exists", dest)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("backup %s: %w", dest, err)
+	}
+	if _, err := d.sql.Exec(`VACUUM INTO ?`, dest); err != nil {
+		return fmt.Errorf("backup %s: %w", dest, err)
+	}
+	if err := os.Chmod(dest, 0600); err != nil {
+		return fmt.Errorf("backup %s: %w", dest, err)
+	}
+	return nil
+}
@@ -9,9 +9,9 @@ import (
 
 // Migration represents a single schema change.
 type Migration struct {
-	Version     int
-	Description string
-	SQL         string
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	SQL         string `json:"-"`
 }
 
 var migrations = []Migration{
@@ -40,6 +40,31 @@ var migrations = []Migration{
 		Description: "add branch column to run_history",
 		SQL:         migration005SQL,
 	},
+	{
+		Version:     6,
+		Description: "add label column to run_history",
+		SQL:         migration006SQL,
+	},
+	{
+		Version:     7,
+		Description: "add budget_reservations table for budget reserve",
+		SQL:         migration007SQL,
+	},
+	{
+		Version:     8,
+		Description: "add task_results table for recent failure tracking",
+		SQL:         migration008SQL,
+	},
+	{
+		Version:     9,
+		Description: "add token_ratios table for the calibrated provider token multiplier",
+		SQL:         migration009SQL,
+	},
+	{
+		Version:     10,
+		Description: "add task_runs table for the per-task ledger",
+		SQL:         migration010SQL,
+	},
 }
 
 const migration002SQL = `
@@ -121,21 +146,79 @@ const migration005SQL = `
 ALTER TABLE run_history ADD COLUMN branch TEXT NOT NULL DEFAULT '';
 `
 
+const migration006SQL = `
+ALTER TABLE run_history ADD COLUMN label TEXT NOT NULL DEFAULT '';
+`
+
+const migration007SQL = `
+CREATE TABLE IF NOT EXISTS budget_reservations (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider    TEXT NOT NULL,
+    tokens      INTEGER NOT NULL,
+    until       DATETIME NOT NULL,
+    created_at  DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_budget_reservations_provider ON budget_reservations(provider);
+`
+
+const migration008SQL = `
+CREATE TABLE IF NOT EXISTS task_results (
+    project_path TEXT NOT NULL,
+    task_type    TEXT NOT NULL,
+    status       TEXT NOT NULL,
+    finished_at  DATETIME NOT NULL,
+    PRIMARY KEY (project_path, task_type)
+);
+`
+
+const migration009SQL = `
+CREATE TABLE IF NOT EXISTS token_ratios (
+    provider        TEXT PRIMARY KEY,
+    ratio           REAL NOT NULL,
+    sessions        INTEGER NOT NULL,
+    claude_sessions INTEGER NOT NULL,
+    updated_at      DATETIME NOT NULL
+);
+`
+
+const migration010SQL = `
+CREATE TABLE IF NOT EXISTS task_runs (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    project      TEXT NOT NULL,
+    task_type    TEXT NOT NULL,
+    provider     TEXT NOT NULL DEFAULT '',
+    status       TEXT NOT NULL,
+    tokens_used  INTEGER NOT NULL DEFAULT 0,
+    start_time   DATETIME NOT NULL,
+    end_time     DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_task_runs_start ON task_runs(start_time);
+`
+
 // Migrate runs all pending migrations inside transactions.
 func Migrate(db *sql.DB) error {
+	_, err := MigratePending(db)
+	return err
+}
+
+// MigratePending runs all pending migrations, each in its own transaction,
+// and returns the ones it applied. A migration that fails is rolled back, so
+// running it again retries it.
+func MigratePending(db *sql.DB) ([]Migration, error) {
 	if db == nil {
-		return errors.New("db is nil")
+		return nil, errors.New("db is nil")
 	}
 
 	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (version INTEGER PRIMARY KEY, applied_at DATETIME)`); err != nil {
-		return fmt.Errorf("create schema_version: %w", err)
+		return nil, fmt.Errorf("create schema_version: %w", err)
 	}
 
 	currentVersion, err := CurrentVersion(db)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var applied []Migration
 	for _, migration := range migrations {
 		if migration.Version <= currentVersion {
 			continue
@@ -143,28 +226,48 @@ func Migrate(db *sql.DB) error {
 
 		tx, err := db.Begin()
 		if err != nil {
-			return fmt.Errorf("begin migration %d: %w", migration.Version, err)
+			return applied, fmt.Errorf("begin migration %d: %w", migration.Version, err)
 		}
 
 		if _, err := tx.Exec(migration.SQL); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("apply migration %d: %w", migration.Version, err)
+			return applied, fmt.Errorf("apply migration %d: %w", migration.Version, err)
 		}
 
 		if _, err := tx.Exec(`INSERT INTO schema_version (version, applied_at) VALUES (?, CURRENT_TIMESTAMP)`, migration.Version); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("record migration %d: %w", migration.Version, err)
+			return applied, fmt.Errorf("record migration %d: %w", migration.Version, err)
 		}
 
 		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("commit migration %d: %w", migration.Version, err)
+			return applied, fmt.Errorf("commit migration %d: %w", migration.Version, err)
 		}
 
 		log.Printf("db: applied migration %d: %s", migration.Version, migration.Description)
+		applied = append(applied, migration)
 		currentVersion = migration.Version
 	}
 
-	return nil
+	return applied, nil
+}
+
+// LatestVersion returns the schema version this build migrates to.
+func LatestVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// PendingMigrations returns the migrations above version, oldest first.
+func PendingMigrations(version int) []Migration {
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
 }
 
 // CurrentVersion returns the current schema version (0 if no migrations applied).
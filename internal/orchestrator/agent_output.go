@@ -0,0 +1,87 @@
+package orchestrator
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/marcus/nightshift/internal/agents"
+)
+
+// AgentOutputMode controls how much agent stdout is copied to the log.
+type AgentOutputMode string
+
+const (
+	AgentOutputNone    AgentOutputMode = "none"    // nothing, except the tail on failure
+	AgentOutputSummary AgentOutputMode = "summary" // progress/result lines (default)
+	AgentOutputFull    AgentOutputMode = "full"    // all of it
+)
+
+const (
+	// agentOutputTailLines is how much output is kept for failure
+	// diagnostics in every mode but full.
+	agentOutputTailLines = 20
+	// agentOutputSummaryMax caps the lines a summary keeps.
+	agentOutputSummaryMax = 40
+)
+
+// agentProgressPattern matches lines worth keeping in summary mode: PR/MR
+// links, pass/fail and error lines, git actions, and checklist bullets.
+var agentProgressPattern = regexp.MustCompile(`(?i)(https?://\S+/(pull|pulls|merge_requests)/\d+|\b(error|errors|failed|failure|fail|passed|pass|warning|created|opened|committed|pushed|merged|summary|done|completed?|tests?)\b|^\s*[✓✔✗✘]\s)`)
+
+// summarizeAgentOutput keeps the progress and result lines of output, up to
+// agentOutputSummaryMax of them, favouring the last ones.
+func summarizeAgentOutput(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r \t")
+		if line != "" && agentProgressPattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > agentOutputSummaryMax {
+		lines = lines[len(lines)-agentOutputSummaryMax:]
+	}
+	return lines
+}
+
+// tailLines returns the last n non-empty lines of output.
+func tailLines(output string, n int) []string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+// agentOutput returns the configured mode, defaulting to summary.
+func (c Config) agentOutput() AgentOutputMode {
+	if c.AgentOutput == "" {
+		return AgentOutputSummary
+	}
+	return c.AgentOutput
+}
+
+// logAgentOutput forwards an agent's stdout to the log according to
+// Config.AgentOutput. Failed runs always log the output tail, so the cause
+// isn't lost in none or summary mode.
+func (o *Orchestrator) logAgentOutput(phase TaskStatus, res *agents.ExecuteResult, failed bool) {
+	if res == nil || strings.TrimSpace(res.Output) == "" {
+		return
+	}
+	mode := o.config.agentOutput()
+	switch mode {
+	case AgentOutputFull:
+		o.logger.InfoCtx("agent output", map[string]any{"phase": string(phase), "output": res.Output})
+		return
+	case AgentOutputSummary:
+		if lines := summarizeAgentOutput(res.Output); len(lines) > 0 {
+			o.logger.InfoCtx("agent output summary", map[string]any{"phase": string(phase), "lines": lines})
+		}
+	}
+	if failed {
+		o.logger.WarnCtx("agent output tail", map[string]any{"phase": string(phase), "tail": tailLines(res.Output, agentOutputTailLines)})
+	}
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Commit is a commit the agent created during a task.
+type Commit struct {
+	SHA     string `json:"sha"`
+	Branch  string `json:"branch"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// newCommits lists commits created since snap on new or moved local
+// branches, newest first per branch. A commit reachable from several
+// changed branches is listed once, under the first branch by name.
+func (snap *repoSnapshot) newCommits(ctx context.Context, workDir string) ([]Commit, error) {
+	branches, err := gitBranches(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Everything reachable from the starting commit or a branch as it was
+	// at snapshot time already existed
+	exclude := []string{snap.head}
+	for _, commit := range snap.branches {
+		exclude = append(exclude, commit)
+	}
+
+	var commits []Commit
+	seen := make(map[string]bool)
+	for _, name := range names {
+		commit := branches[name]
+		if from, ok := snap.branches[name]; ok && from == commit {
+			continue
+		}
+		args := append([]string{"log", "--format=%H%x00%s", commit, "--not"}, exclude...)
+		out, err := gitOutput(ctx, workDir, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range nonEmptyLines(out) {
+			sha, subject, _ := strings.Cut(line, "\x00")
+			if seen[sha] {
+				continue
+			}
+			seen[sha] = true
+			commits = append(commits, Commit{SHA: sha, Branch: name, Subject: subject})
+		}
+	}
+	return commits, nil
+}
+
+// recordCommits fills in the commits the task created and, when no work
+// branch was named and they all landed on one branch, that branch.
+// Failures only cost the annotation, so they are logged and ignored.
+func (o *Orchestrator) recordCommits(ctx context.Context, result *TaskResult, snap *repoSnapshot, workDir string) {
+	if snap == nil {
+		return
+	}
+	// The task may have ended because ctx was cancelled (e.g. a token cap)
+	commits, err := snap.newCommits(context.WithoutCancel(ctx), workDir)
+	if err != nil {
+		o.log(result, "warn", "recording commits failed", map[string]any{"error": err.Error()})
+		return
+	}
+	result.Commits = commits
+	if result.Branch != "" || len(commits) == 0 {
+		return
+	}
+	for _, c := range commits {
+		if c.Branch != commits[0].Branch {
+			return
+		}
+	}
+	result.Branch = commits[0].Branch
+}
@@ -0,0 +1,194 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MatchDenyPath reports whether file, a slash-separated path relative to the
+// repository root, falls under a deny_paths glob. Patterns follow a subset of
+// gitignore: "**" spans directories, a trailing "/" or a matched directory
+// covers everything beneath it, and a pattern without a "/" matches at any
+// depth.
+func MatchDenyPath(pattern, file string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		pattern = "**/" + pattern
+	}
+	patSegs := strings.Split(strings.Trim(pattern, "/"), "/")
+	fileSegs := strings.Split(strings.TrimPrefix(path.Clean(file), "/"), "/")
+	// A pattern matching a leading directory denies everything inside it
+	for n := 1; n <= len(fileSegs); n++ {
+		if matchSegments(patSegs, fileSegs[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pat, segs []string) bool {
+	if len(pat) == 0 {
+		return len(segs) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pat[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pat[0], segs[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pat[1:], segs[1:])
+}
+
+// deniedFiles returns the files matching any of patterns, sorted.
+func deniedFiles(patterns, files []string) []string {
+	var denied []string
+	for _, file := range files {
+		for _, pattern := range patterns {
+			if MatchDenyPath(pattern, file) {
+				denied = append(denied, file)
+				break
+			}
+		}
+	}
+	sort.Strings(denied)
+	return slices.Compact(denied)
+}
+
+// repoSnapshot records a repository's state before an agent runs so changes
+// made during the run can be found afterwards, even once the agent has
+// switched back to the original branch.
+type repoSnapshot struct {
+	head     string            // commit checked out at the start
+	branches map[string]string // local branch -> commit
+	dirty    map[string]bool   // files already modified or untracked
+}
+
+// snapshotRepo captures workDir's branches and uncommitted files.
+func snapshotRepo(ctx context.Context, workDir string) (*repoSnapshot, error) {
+	head, err := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branches, err := gitBranches(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	dirty, err := gitDirtyFiles(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	snap := &repoSnapshot{head: strings.TrimSpace(head), branches: branches, dirty: make(map[string]bool, len(dirty))}
+	for _, file := range dirty {
+		snap.dirty[file] = true
+	}
+	return snap, nil
+}
+
+// changedFiles lists files changed since snap: commits on new or moved local
+// branches, plus uncommitted files that were clean at snapshot time.
+func (snap *repoSnapshot) changedFiles(ctx context.Context, workDir string) ([]string, error) {
+	branches, err := gitBranches(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for branch, commit := range branches {
+		from, seen := snap.branches[branch]
+		if seen && from == commit {
+			continue
+		}
+		rangeSpec := from + ".." + commit
+		if !seen {
+			// New branch: everything since it forked from the starting commit
+			rangeSpec = snap.head + "..." + commit
+		}
+		out, err := gitOutput(ctx, workDir, "diff", "--name-only", rangeSpec)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, nonEmptyLines(out)...)
+	}
+	dirty, err := gitDirtyFiles(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range dirty {
+		if !snap.dirty[file] {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return slices.Compact(files), nil
+}
+
+func gitBranches(ctx context.Context, workDir string) (map[string]string, error) {
+	out, err := gitOutput(ctx, workDir, "for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	branches := make(map[string]string)
+	for _, line := range nonEmptyLines(out) {
+		if name, commit, ok := strings.Cut(line, " "); ok {
+			branches[name] = commit
+		}
+	}
+	return branches, nil
+}
+
+// gitDirtyFiles lists modified, staged, and untracked files in workDir.
+func gitDirtyFiles(ctx context.Context, workDir string) ([]string, error) {
+	out, err := gitOutput(ctx, workDir, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, line := range nonEmptyLines(out) {
+		if len(line) < 4 {
+			continue
+		}
+		file := line[3:]
+		// Renames are reported as "old -> new"; both paths count
+		if from, to, ok := strings.Cut(file, " -> "); ok {
+			files = append(files, strings.Trim(from, `"`), strings.Trim(to, `"`))
+			continue
+		}
+		files = append(files, strings.Trim(file, `"`))
+	}
+	return files, nil
+}
+
+func gitOutput(ctx context.Context, workDir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
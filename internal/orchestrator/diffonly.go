@@ -0,0 +1,224 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/marcus/nightshift/internal/tasks"
+)
+
+// DefaultDiffDir is where DiffOnly patches go, relative to the project.
+const DefaultDiffDir = ".nightshift-plan"
+
+// diffOnly reports whether the run metadata asks for a patch instead of a
+// branch and PR.
+func (o *Orchestrator) diffOnly() bool {
+	return o.runMeta != nil && o.runMeta.DiffOnly
+}
+
+// runDiffOnly runs a task in propose-only mode. The prompts tell the agent
+// not to branch, commit or open a PR; no provider CLI can enforce that, so
+// afterwards everything it changed is saved as a patch and removed from the
+// working tree (kept in a git stash), leaving the project as it was. A task
+// that passed review is reported partial with the patch.
+func (o *Orchestrator) runDiffOnly(ctx context.Context, task *tasks.Task, workDir string) (*TaskResult, error) {
+	if workDir == "" && o.config.WorkDir != "" {
+		workDir = o.config.WorkDir
+	}
+	base, err := startDiffOnly(ctx, workDir)
+	if err != nil {
+		result := &TaskResult{
+			TaskID: task.ID,
+			Status: StatusFailed,
+			Error:  fmt.Sprintf("diff-only: %v", err),
+			Logs:   make([]LogEntry, 0),
+		}
+		o.log(result, "error", "diff-only unavailable", map[string]any{"error": err.Error()})
+		return result, fmt.Errorf("diff-only: %w", err)
+	}
+
+	result, runErr := o.runTask(ctx, task, workDir)
+	result.Branch = ""
+
+	// Restore the tree even when the task was cancelled
+	restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+	defer cancel()
+	patch, err := base.restore(restoreCtx, workDir, "nightshift diff-only: "+task.ID)
+	if err != nil {
+		o.log(result, "error", "diff-only restore failed", map[string]any{"error": err.Error()})
+		if runErr == nil {
+			result.Status = StatusFailed
+			result.Error = fmt.Sprintf("diff-only: restoring working tree: %v", err)
+		}
+		return result, runErr
+	}
+	if patch == "" {
+		o.log(result, "info", "diff-only: no changes proposed", nil)
+		return result, runErr
+	}
+
+	dir := DefaultDiffDir
+	if o.runMeta.DiffDir != "" {
+		dir = o.runMeta.DiffDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workDir, dir)
+	}
+	if err := excludeFromGit(restoreCtx, workDir, dir); err != nil {
+		o.log(result, "warn", "diff-only: could not add the diff dir to .git/info/exclude", map[string]any{"error": err.Error()})
+	}
+	path, err := writePatch(dir, workDir, string(task.Type), patch, time.Now())
+	if err != nil {
+		o.log(result, "error", "diff-only: saving patch failed; changes are in the git stash", map[string]any{"error": err.Error()})
+		if runErr == nil {
+			result.Status = StatusFailed
+			result.Error = fmt.Sprintf("diff-only: %v", err)
+		}
+		return result, runErr
+	}
+	result.DiffPath = path
+	if result.Status == StatusCompleted {
+		result.Status = StatusPartial
+		result.Error = "diff-only: changes saved as a patch, not applied"
+	}
+	o.log(result, "info", "diff-only: patch saved", map[string]any{"path": path})
+	return result, runErr
+}
+
+// diffOnlyBase is the commit and branch a DiffOnly run started from.
+type diffOnlyBase struct {
+	head   string
+	branch string // "HEAD" when detached
+}
+
+// startDiffOnly records where workDir starts. The tree must be clean so the
+// patch holds only the agent's changes.
+func startDiffOnly(ctx context.Context, workDir string) (*diffOnlyBase, error) {
+	if _, err := gitOutput(ctx, workDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, errors.New("project is not a git repository")
+	}
+	dirty, err := gitDirtyFiles(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(dirty) > 0 {
+		return nil, fmt.Errorf("working tree has %d uncommitted file(s); commit or stash them first", len(dirty))
+	}
+	head, err := gitOutput(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := gitOutput(ctx, workDir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return &diffOnlyBase{head: strings.TrimSpace(head), branch: strings.TrimSpace(branch)}, nil
+}
+
+// restore returns everything changed since b, committed or not, as a binary
+// patch, then stashes the changes and puts the starting branch and commit
+// back. Commits the agent made anyway stay reachable through the reflog.
+func (b *diffOnlyBase) restore(ctx context.Context, workDir, message string) (string, error) {
+	if _, err := gitOutput(ctx, workDir, "add", "-A"); err != nil {
+		return "", err
+	}
+	patch, err := gitOutput(ctx, workDir, "diff", "--cached", "--binary", b.head)
+	if err != nil {
+		return "", err
+	}
+	dirty, err := gitDirtyFiles(ctx, workDir)
+	if err != nil {
+		return "", err
+	}
+	if len(dirty) > 0 {
+		if _, err := gitOutput(ctx, workDir, "stash", "push", "-m", message); err != nil {
+			return "", err
+		}
+	}
+	branch, err := gitOutput(ctx, workDir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if b.branch == "HEAD" {
+		if _, err := gitOutput(ctx, workDir, "checkout", "--quiet", "--detach", b.head); err != nil {
+			return "", err
+		}
+	} else if strings.TrimSpace(branch) != b.branch {
+		if _, err := gitOutput(ctx, workDir, "checkout", "--quiet", b.branch); err != nil {
+			return "", err
+		}
+	}
+	if _, err := gitOutput(ctx, workDir, "reset", "--quiet", "--hard", b.head); err != nil {
+		return "", err
+	}
+	return patch, nil
+}
+
+// excludeFromGit adds dir to the repository's .git/info/exclude when it lies
+// inside workDir, so a saved patch doesn't leave the tree dirty for the next
+// DiffOnly run on a project whose .gitignore doesn't cover it.
+func excludeFromGit(ctx context.Context, workDir, dir string) error {
+	rel, err := filepath.Rel(workDir, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	prefix, err := gitOutput(ctx, workDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	entry := "/" + strings.TrimSpace(prefix) + filepath.ToSlash(rel) + "/"
+
+	excludePath, err := gitOutput(ctx, workDir, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return err
+	}
+	excludePath = strings.TrimSpace(excludePath)
+	if !filepath.IsAbs(excludePath) {
+		excludePath = filepath.Join(workDir, excludePath)
+	}
+	data, err := os.ReadFile(excludePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == entry {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		entry = "\n" + entry
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(entry + "\n"); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writePatch saves patch as <project>-<task>-<time>.diff in dir.
+func writePatch(dir, workDir, taskType, patch string, now time.Time) (string, error) {
+	if taskType == "" {
+		taskType = "task"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating diff dir: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s.diff", filepath.Base(workDir), taskType, now.Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(patch), 0644); err != nil {
+		return "", fmt.Errorf("writing diff: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,186 @@
+package orchestrator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultEventLogMaxBytes caps a task's event log before it is rotated.
+const DefaultEventLogMaxBytes int64 = 5 << 20
+
+// eventLogUnsafe matches characters replaced in event log file names.
+var eventLogUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// String returns the event type's name as written to event logs.
+func (t EventType) String() string {
+	switch t {
+	case EventTaskStart:
+		return "task_start"
+	case EventPhaseStart:
+		return "phase_start"
+	case EventPhaseEnd:
+		return "phase_end"
+	case EventIterationStart:
+		return "iteration_start"
+	case EventLog:
+		return "log"
+	case EventTaskEnd:
+		return "task_end"
+	}
+	return fmt.Sprintf("event_%d", int(t))
+}
+
+// EventRecord is one line of a task's event log.
+type EventRecord struct {
+	Type      string         `json:"type"`
+	Time      time.Time      `json:"time"`
+	TaskID    string         `json:"task_id,omitempty"`
+	TaskTitle string         `json:"task_title,omitempty"`
+	Phase     TaskStatus     `json:"phase,omitempty"`
+	Iteration int            `json:"iteration,omitempty"`
+	MaxIter   int            `json:"max_iter,omitempty"`
+	Status    TaskStatus     `json:"status,omitempty"`
+	Level     string         `json:"level,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Fields    map[string]any `json:"fields,omitempty"`
+	Duration  time.Duration  `json:"duration,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+func newEventRecord(e Event) EventRecord {
+	return EventRecord{
+		Type:      e.Type.String(),
+		Time:      e.Time,
+		TaskID:    e.TaskID,
+		TaskTitle: e.TaskTitle,
+		Phase:     e.Phase,
+		Iteration: e.Iteration,
+		MaxIter:   e.MaxIter,
+		Status:    e.Status,
+		Level:     e.Level,
+		Message:   e.Message,
+		Fields:    e.Fields,
+		Duration:  e.Duration,
+		Error:     e.Error,
+	}
+}
+
+// eventLog appends a task's events to a JSONL file. Once the file would grow
+// past maxBytes it is moved to path+".1", replacing any earlier rotation,
+// and a fresh file is started.
+type eventLog struct {
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// EventLogPath returns the event log file for a task started at start.
+func EventLogPath(dir, taskID string, start time.Time) string {
+	name := strings.Trim(eventLogUnsafe.ReplaceAllString(taskID, "_"), "_")
+	if name == "" {
+		name = "task"
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl", start.Format("2006-01-02-150405"), name))
+}
+
+func openEventLog(path string, maxBytes int64) (*eventLog, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultEventLogMaxBytes
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating event log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening event log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("stat event log: %w", err)
+	}
+	return &eventLog{path: path, maxBytes: maxBytes, file: f, size: info.Size()}, nil
+}
+
+func (l *eventLog) write(e Event) error {
+	line, err := json.Marshal(newEventRecord(e))
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+func (l *eventLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("rotating event log: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("opening event log: %w", err)
+	}
+	l.file = f
+	l.size = 0
+	return nil
+}
+
+func (l *eventLog) close() error {
+	return l.file.Close()
+}
+
+// ReadEventLog returns the events recorded at path, oldest first, including
+// those in a rotated path+".1" file.
+func ReadEventLog(path string) ([]EventRecord, error) {
+	var records []EventRecord
+	found := false
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading event log: %w", err)
+		}
+		found = true
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var rec EventRecord
+			if err := json.Unmarshal([]byte(line), &rec); err != nil {
+				continue // tolerate a line cut short by a crash
+			}
+			records = append(records, rec)
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading event log: %w", err)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("reading event log: %w", os.ErrNotExist)
+	}
+	return records, nil
+}
@@ -12,6 +12,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/marcus/nightshift/internal/agents"
@@ -22,10 +24,18 @@ import (
 
 // Constants for orchestration.
 const (
-	DefaultMaxIterations = 3
-	DefaultAgentTimeout  = 30 * time.Minute
+	DefaultMaxIterations     = 3
+	DefaultAgentTimeout      = 30 * time.Minute
+	DefaultTokenPollInterval = 30 * time.Second
 )
 
+// ErrTokenCapExceeded is the cancellation cause when a task's token usage
+// crosses its cap.
+var ErrTokenCapExceeded = errors.New("token cap exceeded")
+
+// TokenMeter returns a provider's running token total for today.
+type TokenMeter func() (int64, error)
+
 // TaskStatus represents the outcome of task execution.
 type TaskStatus string
 
@@ -37,20 +47,29 @@ const (
 	StatusCompleted TaskStatus = "completed"
 	StatusFailed    TaskStatus = "failed"
 	StatusAbandoned TaskStatus = "abandoned"
+	// StatusPartial marks a task that hit the iteration cap without passing
+	// review but still produced a PR/MR, so its work is not lost.
+	StatusPartial TaskStatus = "partial"
 )
 
 // TaskResult holds the outcome of orchestrating a task.
 type TaskResult struct {
-	TaskID     string        `json:"task_id"`
-	Status     TaskStatus    `json:"status"`
-	Iterations int           `json:"iterations"`
-	Plan       *PlanOutput   `json:"plan,omitempty"`
-	Output     string        `json:"output,omitempty"`
-	OutputType string        `json:"output_type,omitempty"` // e.g. "PR"
-	OutputRef  string        `json:"output_ref,omitempty"`  // e.g. PR URL
-	Error      string        `json:"error,omitempty"`
-	Duration   time.Duration `json:"duration"`
-	Logs       []LogEntry    `json:"logs"`
+	TaskID      string        `json:"task_id"`
+	Status      TaskStatus    `json:"status"`
+	Iterations  int           `json:"iterations"`
+	Plan        *PlanOutput   `json:"plan,omitempty"`
+	Output      string        `json:"output,omitempty"`
+	OutputType  string        `json:"output_type,omitempty"` // e.g. "PR", "MR"
+	OutputRef   string        `json:"output_ref,omitempty"`  // e.g. PR/MR URL
+	Error       string        `json:"error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	TokensUsed  int64         `json:"tokens_used,omitempty"`  // measured while the token cap was watched
+	DeniedPaths []string      `json:"denied_paths,omitempty"` // files changed under safety.deny_paths
+	Branch      string        `json:"branch,omitempty"`       // feature branch the agent was asked to use, or the one it committed to
+	Commits     []Commit      `json:"commits,omitempty"`      // commits made on local branches during the task
+	EventLog    string        `json:"event_log,omitempty"`    // JSONL event stream, when WithEventLog is set
+	DiffPath    string        `json:"diff_path,omitempty"`    // patch saved by a DiffOnly run
+	Logs        []LogEntry    `json:"logs"`
 }
 
 // PlanOutput represents structured plan from the plan agent.
@@ -87,19 +106,44 @@ type LogEntry struct {
 // RunMetadata holds provenance information about a nightshift run,
 // injected into PRs for traceability.
 type RunMetadata struct {
-	Provider  string
-	TaskType  string
-	TaskScore float64
-	CostTier  string
-	RunStart  time.Time
-	Branch    string // base branch for feature branches
+	Provider   string
+	Model      string // per-task model override (empty = provider default)
+	TaskType   string
+	TaskScore  float64
+	CostTier   string
+	RunStart   time.Time
+	Branch     string   // base branch for feature branches
+	WorkBranch string   // feature branch name to create (orchestrator.branch_template)
+	PRDraft    bool     // open PRs as drafts
+	PRBase     string   // PR target branch (empty = agent default)
+	Forge      string   // code host: ForgeGitHub (default), ForgeGitLab, ForgeGitea
+	DenyPaths  []string // globs the agent must not modify (safety.deny_paths)
+	DiffOnly   bool     // propose changes as a patch: no branch, commit or PR, tree restored
+	DiffDir    string   // where DiffOnly patches go (default: <workDir>/.nightshift-plan)
+	ReadOnly   bool     // project is read-only: report findings only, any file change fails the task
 }
 
+// Code hosts recognised in RunMetadata.Forge.
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+)
+
+// Output types set on TaskResult for review requests.
+const (
+	OutputTypePR = "PR" // GitHub and Gitea pull requests
+	OutputTypeMR = "MR" // GitLab merge requests
+)
+
 // Config holds orchestrator configuration.
 type Config struct {
-	MaxIterations int           // Max review iterations (default: 3)
-	AgentTimeout  time.Duration // Per-agent timeout (default: 30min)
-	WorkDir       string        // Working directory for agents
+	MaxIterations     int             // Max review iterations (default: 3)
+	AgentTimeout      time.Duration   // Per-agent timeout (default: 30min)
+	WorkDir           string          // Working directory for agents
+	TokenPollInterval time.Duration   // How often the token meter is read (default: 30s)
+	AgentOutput       AgentOutputMode // How much agent stdout goes to the log (default: summary)
+	EventLogMaxBytes  int64           // Event log size before rotation (default: 5 MiB)
 }
 
 // DefaultConfig returns default orchestrator config.
@@ -119,6 +163,10 @@ type Orchestrator struct {
 	logger       *logging.Logger
 	eventHandler EventHandler // optional callback for real-time events
 	runMeta      *RunMetadata
+	tokenMeter   TokenMeter // optional; required for the token cap
+	tokenCap     int64      // per-task token ceiling; 0 = none
+	eventLogDir  string     // where per-task event logs go; "" = none
+	eventLog     *eventLog  // open while a task runs
 }
 
 // Option configures an Orchestrator.
@@ -166,14 +214,62 @@ func WithEventHandler(h EventHandler) Option {
 	}
 }
 
-// emit sends an event to the registered handler, if any.
+// WithTokenMeter sets the counter used to enforce the per-task token cap.
+func WithTokenMeter(m TokenMeter) Option {
+	return func(o *Orchestrator) {
+		o.tokenMeter = m
+	}
+}
+
+// WithEventLog writes each task's events to a JSONL file in dir, so runs
+// without a live renderer can be replayed later.
+func WithEventLog(dir string) Option {
+	return func(o *Orchestrator) {
+		o.eventLogDir = dir
+	}
+}
+
+// emit sends an event to the registered handler and the task's event log,
+// if any.
 func (o *Orchestrator) emit(e Event) {
+	if o.eventHandler == nil && o.eventLog == nil {
+		return
+	}
+	e.Time = time.Now()
+	if o.eventLog != nil {
+		if err := o.eventLog.write(e); err != nil {
+			o.logger.WarnCtx("event log write failed", map[string]any{"path": o.eventLog.path, "error": err.Error()})
+			_ = o.eventLog.close()
+			o.eventLog = nil
+		}
+	}
 	if o.eventHandler != nil {
-		e.Time = time.Now()
 		o.eventHandler(e)
 	}
 }
 
+// openEventLog starts the task's event log when WithEventLog is set. The
+// returned func closes it.
+func (o *Orchestrator) openEventLog(task *tasks.Task, result *TaskResult, start time.Time) func() {
+	if o.eventLogDir == "" {
+		return func() {}
+	}
+	path := EventLogPath(o.eventLogDir, task.ID, start)
+	l, err := openEventLog(path, o.config.EventLogMaxBytes)
+	if err != nil {
+		o.log(result, "warn", "event log disabled", map[string]any{"error": err.Error()})
+		return func() {}
+	}
+	o.eventLog = l
+	result.EventLog = path
+	return func() {
+		if o.eventLog != nil {
+			_ = o.eventLog.close()
+			o.eventLog = nil
+		}
+	}
+}
+
 // New creates an orchestrator with the given options.
 func New(opts ...Option) *Orchestrator {
 	o := &Orchestrator{
@@ -188,12 +284,22 @@ func New(opts ...Option) *Orchestrator {
 
 // RunTask executes a single task through the plan-implement-review loop.
 func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir string) (*TaskResult, error) {
+	if o.diffOnly() {
+		return o.runDiffOnly(ctx, task, workDir)
+	}
+	return o.runTask(ctx, task, workDir)
+}
+
+func (o *Orchestrator) runTask(ctx context.Context, task *tasks.Task, workDir string) (*TaskResult, error) {
 	start := time.Now()
 	result := &TaskResult{
 		TaskID: task.ID,
 		Status: StatusPending,
+		Branch: o.workBranch(),
 		Logs:   make([]LogEntry, 0),
 	}
+	closeEventLog := o.openEventLog(task, result, start)
+	defer closeEventLog()
 
 	o.log(result, "info", "starting task", map[string]any{"task_id": task.ID, "title": task.Title})
 
@@ -217,6 +323,21 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 		workDir = o.config.WorkDir
 	}
 
+	ctx, stopWatch := o.watchTokens(ctx, result)
+	defer stopWatch()
+
+	// Snapshot the repo so changes under deny paths can be caught after
+	// each implement pass, whatever the agent CLI enforced itself, and the
+	// commits the task made can be recorded. Outside a git repo both are
+	// skipped.
+	repoSnap, err := snapshotRepo(ctx, workDir)
+	if err != nil {
+		if len(o.denyPaths()) > 0 {
+			o.log(result, "warn", "deny path check disabled", map[string]any{"error": err.Error()})
+		}
+	}
+	defer o.recordCommits(ctx, result, repoSnap, workDir)
+
 	// Step 1: Plan
 	result.Status = StatusPlanning
 	o.log(result, "info", "planning", nil)
@@ -226,6 +347,9 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 
 	plan, err := o.plan(ctx, task, workDir)
 	if err != nil {
+		if o.abandonIfCapped(ctx, task, result, start) {
+			return result, nil
+		}
 		result.Status = StatusFailed
 		result.Error = fmt.Sprintf("planning failed: %v", err)
 		result.Duration = time.Since(start)
@@ -252,6 +376,9 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 
 		impl, err := o.implement(ctx, task, plan, workDir, iteration)
 		if err != nil {
+			if o.abandonIfCapped(ctx, task, result, start) {
+				return result, nil
+			}
 			result.Status = StatusFailed
 			result.Error = fmt.Sprintf("implement failed (iteration %d): %v", iteration, err)
 			result.Duration = time.Since(start)
@@ -264,6 +391,24 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 		o.log(result, "info", "implementation complete", map[string]any{"files_modified": len(impl.FilesModified)})
 		o.emit(Event{Type: EventPhaseEnd, Phase: StatusExecuting, TaskID: task.ID, Duration: time.Since(phaseStart), Iteration: iteration})
 
+		if repoSnap != nil && len(o.denyPaths()) > 0 {
+			changed, err := repoSnap.changedFiles(ctx, workDir)
+			if err != nil {
+				o.log(result, "warn", "deny path check failed", map[string]any{"error": err.Error()})
+			} else if denied := deniedFiles(o.denyPaths(), changed); len(denied) > 0 {
+				result.Status = StatusFailed
+				result.DeniedPaths = denied
+				result.Error = fmt.Sprintf("changed denied paths: %s", strings.Join(denied, ", "))
+				if o.readOnly() {
+					result.Error = fmt.Sprintf("changed files in a read-only project: %s", strings.Join(denied, ", "))
+				}
+				result.Duration = time.Since(start)
+				o.log(result, "error", "denied paths changed", map[string]any{"iteration": iteration, "files": denied})
+				o.emit(Event{Type: EventTaskEnd, TaskID: task.ID, Status: StatusFailed, Duration: result.Duration, Error: result.Error})
+				return result, nil
+			}
+		}
+
 		// Review
 		result.Status = StatusReviewing
 		o.emit(Event{Type: EventPhaseStart, Phase: StatusReviewing, TaskID: task.ID, Iteration: iteration})
@@ -271,6 +416,9 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 
 		review, err := o.review(ctx, task, impl, workDir)
 		if err != nil {
+			if o.abandonIfCapped(ctx, task, result, start) {
+				return result, nil
+			}
 			result.Status = StatusFailed
 			result.Error = fmt.Sprintf("review failed (iteration %d): %v", iteration, err)
 			result.Duration = time.Since(start)
@@ -291,17 +439,20 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 			result.Status = StatusCompleted
 			result.Duration = time.Since(start)
 
-			// Extract PR URL from agent output
-			url := ExtractPRURL(impl.Raw)
+			// Extract PR/MR URL from agent output
+			outputType, url := o.extractReviewURL(impl.Raw)
 			if url == "" {
-				url = ExtractPRURL(impl.Summary)
+				outputType, url = o.extractReviewURL(impl.Summary)
 			}
 			if url != "" {
-				result.OutputType = "PR"
+				result.OutputType = outputType
 				result.OutputRef = url
-				o.log(result, "info", "PR found", map[string]any{"url": url})
-				if err := o.annotatePR(ctx, url, task, result, workDir); err != nil {
-					o.log(result, "warn", "annotate PR failed", map[string]any{"error": err.Error()})
+				o.log(result, "info", outputType+" found", map[string]any{"url": url})
+				// Annotation goes through gh, so it only works on GitHub
+				if o.forge() == ForgeGitHub {
+					if err := o.annotatePR(ctx, url, task, result, workDir); err != nil {
+						o.log(result, "warn", "annotate PR failed", map[string]any{"error": err.Error()})
+					}
 				}
 			}
 
@@ -317,8 +468,23 @@ func (o *Orchestrator) RunTask(ctx context.Context, task *tasks.Task, workDir st
 			"issues":    review.Issues,
 		})
 
-		// If max iterations reached, abandon
+		// If max iterations reached, keep a PR/MR the agent opened as partial
+		// credit; otherwise abandon
 		if iteration >= o.config.MaxIterations {
+			outputType, url := o.extractReviewURL(impl.Raw)
+			if url == "" {
+				outputType, url = o.extractReviewURL(impl.Summary)
+			}
+			if url != "" {
+				result.Status = StatusPartial
+				result.OutputType = outputType
+				result.OutputRef = url
+				result.Error = fmt.Sprintf("max iterations (%d) reached: %s", o.config.MaxIterations, review.Feedback)
+				result.Duration = time.Since(start)
+				o.log(result, "warn", "task partially completed", map[string]any{"reason": "max iterations", "url": url})
+				o.emit(Event{Type: EventTaskEnd, TaskID: task.ID, Status: StatusPartial, Duration: result.Duration, Error: result.Error})
+				return result, nil
+			}
 			result.Status = StatusAbandoned
 			result.Error = fmt.Sprintf("max iterations (%d) reached: %s", o.config.MaxIterations, review.Feedback)
 			result.Duration = time.Since(start)
@@ -341,6 +507,92 @@ func (o *Orchestrator) SetRunMetadata(m *RunMetadata) {
 	o.runMeta = m
 }
 
+// SetTokenCap sets the token ceiling for the next task; 0 disables it.
+// The cap is only enforced when a token meter is configured.
+func (o *Orchestrator) SetTokenCap(limit int64) {
+	o.tokenCap = limit
+}
+
+// watchTokens polls the token meter while a task runs and cancels ctx with
+// ErrTokenCapExceeded once usage since the task started crosses the cap.
+// The returned stop func ends polling and records tokens spent on result.
+func (o *Orchestrator) watchTokens(ctx context.Context, result *TaskResult) (context.Context, func()) {
+	if o.tokenMeter == nil || o.tokenCap <= 0 {
+		return ctx, func() {}
+	}
+	startTokens, err := o.tokenMeter()
+	if err != nil {
+		o.log(result, "warn", "token meter unavailable, cap not enforced", map[string]any{"error": err.Error()})
+		return ctx, func() {}
+	}
+
+	interval := o.config.TokenPollInterval
+	if interval <= 0 {
+		interval = DefaultTokenPollInterval
+	}
+	limit := o.tokenCap
+	ctx, cancel := context.WithCancelCause(ctx)
+	var spent atomic.Int64
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				now, err := o.tokenMeter()
+				if err != nil || now < startTokens {
+					continue
+				}
+				spent.Store(now - startTokens)
+				if now-startTokens > limit {
+					cancel(ErrTokenCapExceeded)
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		wg.Wait()
+		if now, err := o.tokenMeter(); err == nil && now-startTokens > spent.Load() {
+			spent.Store(now - startTokens)
+		}
+		result.TokensUsed = spent.Load()
+		cancel(nil)
+	}
+}
+
+// abandonIfCapped marks the task abandoned when its context was cancelled
+// for crossing the token cap, reporting whether it did.
+func (o *Orchestrator) abandonIfCapped(ctx context.Context, task *tasks.Task, result *TaskResult, start time.Time) bool {
+	if !errors.Is(context.Cause(ctx), ErrTokenCapExceeded) {
+		return false
+	}
+	result.Status = StatusAbandoned
+	result.Error = fmt.Sprintf("%v (cap %d tokens)", ErrTokenCapExceeded, o.tokenCap)
+	result.Duration = time.Since(start)
+	o.log(result, "error", "task abandoned", map[string]any{"reason": "token cap", "cap": o.tokenCap})
+	o.emit(Event{Type: EventTaskEnd, TaskID: task.ID, Status: StatusAbandoned, Duration: result.Duration, Error: result.Error})
+	return true
+}
+
+// model returns the model override for the current task, if any.
+func (o *Orchestrator) model() string {
+	if o.runMeta == nil {
+		return ""
+	}
+	return o.runMeta.Model
+}
+
 // buildMetadataBlock produces the metadata footer appended to PR bodies.
 func (o *Orchestrator) buildMetadataBlock(task *tasks.Task, result *TaskResult) string {
 	var b strings.Builder
@@ -352,11 +604,17 @@ func (o *Orchestrator) buildMetadataBlock(task *tasks.Task, result *TaskResult)
 	fmt.Fprintf(&b, "task-title: %s\n", task.Title)
 	if o.runMeta != nil {
 		fmt.Fprintf(&b, "provider: %s\n", o.runMeta.Provider)
+		if o.runMeta.Model != "" {
+			fmt.Fprintf(&b, "model: %s\n", o.runMeta.Model)
+		}
 		fmt.Fprintf(&b, "score: %.1f\n", o.runMeta.TaskScore)
 		fmt.Fprintf(&b, "cost-tier: %s\n", o.runMeta.CostTier)
 		if o.runMeta.Branch != "" {
 			fmt.Fprintf(&b, "branch: %s\n", o.runMeta.Branch)
 		}
+		if o.runMeta.PRBase != "" {
+			fmt.Fprintf(&b, "pr-base: %s\n", o.runMeta.PRBase)
+		}
 	}
 	fmt.Fprintf(&b, "iterations: %d\n", result.Iterations)
 	fmt.Fprintf(&b, "duration: %s\n", result.Duration.Round(time.Second))
@@ -443,7 +701,9 @@ func (o *Orchestrator) plan(ctx context.Context, task *tasks.Task, workDir strin
 		Prompt:  prompt,
 		WorkDir: workDir,
 		Timeout: o.config.AgentTimeout,
+		Model:   o.model(),
 	})
+	o.logAgentOutput(StatusPlanning, execResult, err != nil || (execResult != nil && !execResult.IsSuccess()))
 	if err != nil {
 		return nil, fmt.Errorf("agent execution: %w", err)
 	}
@@ -486,11 +746,14 @@ func (o *Orchestrator) implement(ctx context.Context, task *tasks.Task, plan *Pl
 	}
 
 	execResult, err := o.agent.Execute(ctx, agents.ExecuteOptions{
-		Prompt:  prompt,
-		WorkDir: workDir,
-		Files:   files,
-		Timeout: o.config.AgentTimeout,
+		Prompt:    prompt,
+		WorkDir:   workDir,
+		Files:     files,
+		Timeout:   o.config.AgentTimeout,
+		Model:     o.model(),
+		DenyPaths: o.denyPaths(),
 	})
+	o.logAgentOutput(StatusExecuting, execResult, err != nil || (execResult != nil && !execResult.IsSuccess()))
 	if err != nil {
 		return nil, fmt.Errorf("agent execution: %w", err)
 	}
@@ -588,7 +851,9 @@ func (o *Orchestrator) review(ctx context.Context, task *tasks.Task, impl *Imple
 		WorkDir: workDir,
 		Files:   files,
 		Timeout: o.config.AgentTimeout,
+		Model:   o.model(),
 	})
+	o.logAgentOutput(StatusReviewing, execResult, err != nil || (execResult != nil && !execResult.IsSuccess()))
 	if err != nil {
 		return nil, fmt.Errorf("agent execution: %w", err)
 	}
@@ -660,15 +925,43 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 
 // Prompt builders
 
+// agentPromptPrefixes open every plan, implement and review prompt.
+var agentPromptPrefixes = []string{
+	"You are a planning agent.",
+	"You are an implementation agent.",
+	"You are a code review agent.",
+}
+
+// IsAgentPrompt reports whether prompt was built by the orchestrator. Usage
+// tools use it to recognise nightshift sessions in provider transcripts.
+func IsAgentPrompt(prompt string) bool {
+	prompt = strings.TrimSpace(prompt)
+	for _, prefix := range agentPromptPrefixes {
+		if strings.HasPrefix(prompt, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // PlanPrompt returns the planning prompt for a task.
 func (o *Orchestrator) PlanPrompt(task *tasks.Task) string {
 	return o.buildPlanPrompt(task)
 }
 
 func (o *Orchestrator) buildPlanPrompt(task *tasks.Task) string {
-	branchInstruction := ""
-	if o.runMeta != nil && o.runMeta.Branch != "" {
-		branchInstruction = fmt.Sprintf("\n   Create your feature branch from `%s`.", o.runMeta.Branch)
+	gitInstructions := fmt.Sprintf(`1. Work on a new branch and plan to submit a PR. Never work directly on the primary branch.%s
+2. Before creating your branch, record the current branch name and plan to switch back after the PR is opened.
+3. If you create commits, include a concise message with these git trailers:
+   Nightshift-Task: %s
+   Nightshift-Ref: https://github.com/marcus/nightshift`, o.planBranchInstruction(), task.Type)
+	if o.diffOnly() {
+		gitInstructions = `1. Propose changes only: plan to edit files in the working tree without creating branches, commits or PRs. The changes are saved as a patch for human review.
+2. Stay on the current branch.
+3. Do not run git commands that change history or the index (commit, stash, reset, checkout).`
+	}
+	if o.readOnly() {
+		gitInstructions = readOnlyInstructions
 	}
 
 	return fmt.Sprintf(`You are a planning agent. Create a detailed execution plan for this task.
@@ -680,11 +973,7 @@ Description: %s
 
 ## Instructions
 0. You are running autonomously. If the task is broad or ambiguous, choose a concrete, minimal scope that delivers value and state any assumptions in the description.
-1. Work on a new branch and plan to submit a PR. Never work directly on the primary branch.%s
-2. Before creating your branch, record the current branch name and plan to switch back after the PR is opened.
-3. If you create commits, include a concise message with these git trailers:
-   Nightshift-Task: %s
-   Nightshift-Ref: https://github.com/marcus/nightshift
+%s
 4. Analyze the task requirements
 5. Identify files that need to be modified
 6. Create step-by-step implementation plan
@@ -695,7 +984,20 @@ Description: %s
   "files": ["file1.go", "file2.go", ...],
   "description": "overall approach"
 }
-`, task.ID, task.Title, task.Description, branchInstruction, task.Type)
+`, task.ID, task.Title, task.Description, gitInstructions)
+}
+
+// planBranchInstruction names the base and feature branch for the plan
+// prompt, from the run metadata.
+func (o *Orchestrator) planBranchInstruction() string {
+	branchInstruction := ""
+	if o.runMeta != nil && o.runMeta.Branch != "" {
+		branchInstruction = fmt.Sprintf("\n   Create your feature branch from `%s`.", o.runMeta.Branch)
+	}
+	if wb := o.workBranch(); wb != "" {
+		branchInstruction += fmt.Sprintf("\n   Name the feature branch `%s`.", wb)
+	}
+	return branchInstruction
 }
 
 func (o *Orchestrator) buildImplementPrompt(task *tasks.Task, plan *PlanOutput, iteration int) string {
@@ -708,6 +1010,27 @@ func (o *Orchestrator) buildImplementPrompt(task *tasks.Task, plan *PlanOutput,
 	if o.runMeta != nil && o.runMeta.Branch != "" {
 		branchInstruction = fmt.Sprintf("\n   Checkout `%s` before creating your feature branch.", o.runMeta.Branch)
 	}
+	if wb := o.workBranch(); wb != "" {
+		branchInstruction += fmt.Sprintf("\n   Name the feature branch `%s`; if it already exists from an earlier iteration, check it out and continue on it.", wb)
+	}
+	prInstruction := o.prCreateInstruction()
+	denyInstruction := ""
+	if denyPaths := o.denyPaths(); len(denyPaths) > 0 {
+		denyInstruction = fmt.Sprintf("\n   Do not create, modify, or delete files matching: `%s`. The task fails if any are changed.", strings.Join(denyPaths, "`, `"))
+	}
+
+	gitInstructions := fmt.Sprintf(`0. Before creating your branch, record the current branch name. Create and work on a new branch. Never modify or commit directly to the primary branch.%s
+   When finished, open a PR.%s After the PR is submitted, switch back to the original branch. If you cannot open a PR, leave the branch and explain next steps.
+1. If you create commits, include a concise message with these git trailers:
+   Nightshift-Task: %s
+   Nightshift-Ref: https://github.com/marcus/nightshift`, branchInstruction, prInstruction, task.Type)
+	if o.diffOnly() {
+		gitInstructions = `0. Propose changes only. Edit files in the working tree on the current branch; do not create branches, commits or PRs. Your uncommitted changes are saved as a patch for human review, and the working tree is restored afterwards.
+1. Do not run git commands that change history or the index (commit, stash, reset, checkout).`
+	}
+	if o.readOnly() {
+		gitInstructions, denyInstruction = readOnlyInstructions, ""
+	}
 
 	return fmt.Sprintf(`You are an implementation agent. Execute the plan for this task.
 
@@ -723,13 +1046,9 @@ Description: %s
 %v
 %s
 ## Instructions
-0. Before creating your branch, record the current branch name. Create and work on a new branch. Never modify or commit directly to the primary branch.%s
-   When finished, open a PR. After the PR is submitted, switch back to the original branch. If you cannot open a PR, leave the branch and explain next steps.
-1. If you create commits, include a concise message with these git trailers:
-   Nightshift-Task: %s
-   Nightshift-Ref: https://github.com/marcus/nightshift
+%s
 2. Implement the plan step by step
-3. Make all necessary code changes
+3. Make all necessary code changes%s
 4. Ensure tests pass
 5. Output a summary as JSON:
 
@@ -737,10 +1056,94 @@ Description: %s
   "files_modified": ["file1.go", ...],
   "summary": "what was done"
 }
-`, task.ID, task.Title, task.Description, plan.Description, plan.Steps, iterationNote, branchInstruction, task.Type)
+`, task.ID, task.Title, task.Description, plan.Description, plan.Steps, iterationNote, gitInstructions, denyInstruction)
+}
+
+// workBranch returns the feature branch name from the run metadata, or ""
+// to let the agent choose.
+func (o *Orchestrator) workBranch() string {
+	if o.runMeta == nil {
+		return ""
+	}
+	return o.runMeta.WorkBranch
+}
+
+// prCreateInstruction returns the PR creation command hint derived from
+// run metadata, or "" when neither draft nor a target branch is set.
+func (o *Orchestrator) prCreateInstruction() string {
+	if o.runMeta == nil || (!o.runMeta.PRDraft && o.runMeta.PRBase == "") {
+		return ""
+	}
+	switch o.forge() {
+	case ForgeGitLab:
+		cmd := "glab mr create"
+		if o.runMeta.PRDraft {
+			cmd += " --draft"
+		}
+		if o.runMeta.PRBase != "" {
+			cmd += " --target-branch " + o.runMeta.PRBase
+		}
+		return fmt.Sprintf(" Use `%s` to open it.", cmd)
+	case ForgeGitea:
+		// tea has no draft flag; Gitea treats a "WIP:" title prefix as draft
+		cmd := "tea pulls create"
+		if o.runMeta.PRBase != "" {
+			cmd += " --base " + o.runMeta.PRBase
+		}
+		hint := fmt.Sprintf(" Use `%s` to open it.", cmd)
+		if o.runMeta.PRDraft {
+			hint += " Prefix the title with `WIP:` to mark it as a draft."
+		}
+		return hint
+	}
+	cmd := "gh pr create"
+	if o.runMeta.PRDraft {
+		cmd += " --draft"
+	}
+	if o.runMeta.PRBase != "" {
+		cmd += " --base " + o.runMeta.PRBase
+	}
+	return fmt.Sprintf(" Use `%s` to open it.", cmd)
+}
+
+// readOnlyInstructions replace the git steps in prompts for read-only
+// projects.
+const readOnlyInstructions = `0. This project is read-only. Do not create, modify, or delete any files, and do not create branches, commits or PRs. The task fails if anything changes.
+1. Report your findings in the summary instead.`
+
+// denyPaths returns the globs the agent must not modify. A read-only
+// project denies every path.
+func (o *Orchestrator) denyPaths() []string {
+	if o.runMeta == nil {
+		return nil
+	}
+	if o.runMeta.ReadOnly {
+		return []string{"**"}
+	}
+	return o.runMeta.DenyPaths
+}
+
+// readOnly reports whether the project must be left unchanged.
+func (o *Orchestrator) readOnly() bool {
+	return o.runMeta != nil && o.runMeta.ReadOnly
+}
+
+// forge returns the configured code host, defaulting to GitHub.
+func (o *Orchestrator) forge() string {
+	if o.runMeta == nil || o.runMeta.Forge == "" {
+		return ForgeGitHub
+	}
+	return o.runMeta.Forge
 }
 
 func (o *Orchestrator) buildReviewPrompt(task *tasks.Task, impl *ImplementOutput) string {
+	branchCheck := "Confirm work was done on a branch (not primary) and is ready for a PR"
+	if o.diffOnly() {
+		branchCheck = "Confirm the changes are left uncommitted in the working tree (no branch, commit or PR); they are reviewed as a patch"
+	}
+	if o.readOnly() {
+		branchCheck = "Confirm no files were created, modified or deleted and no branch, commit or PR was made; the project is read-only"
+	}
 	return fmt.Sprintf(`You are a code review agent. Review this implementation.
 
 ## Task
@@ -755,7 +1158,7 @@ Description: %s
 %v
 
 ## Instructions
-1. Confirm work was done on a branch (not primary) and is ready for a PR
+1. %s
 2. Check if implementation meets task requirements
 3. Verify code quality and correctness
 4. Check for bugs or issues
@@ -768,16 +1171,45 @@ Description: %s
 }
 
 Set "passed" to true ONLY if the implementation is correct and complete.
-`, task.ID, task.Title, task.Description, impl.Summary, impl.FilesModified)
+`, task.ID, task.Title, task.Description, impl.Summary, impl.FilesModified, branchCheck)
 }
 
 // prURLPattern matches standard GitHub pull request URLs.
 var prURLPattern = regexp.MustCompile(`https://github\.com/[^/\s]+/[^/\s]+/pull/\d+`)
 
+// mrURLPattern matches GitLab merge request URLs on any host, including
+// nested group paths.
+var mrURLPattern = regexp.MustCompile(`https?://[^/\s]+/(?:[^/\s]+/)+-/merge_requests/\d+`)
+
+// giteaPRURLPattern matches Gitea pull request URLs on any host.
+var giteaPRURLPattern = regexp.MustCompile(`https?://[^/\s]+/[^/\s]+/[^/\s]+/pulls/\d+`)
+
 // ExtractPRURL scans text for GitHub PR URLs and returns the last match.
 // Returns empty string if no PR URL is found.
 func ExtractPRURL(text string) string {
-	matches := prURLPattern.FindAllString(text, -1)
+	return lastMatch(prURLPattern, text)
+}
+
+// ExtractMRURL scans text for GitLab merge request URLs and returns the last
+// match. Returns empty string if no MR URL is found.
+func ExtractMRURL(text string) string {
+	return lastMatch(mrURLPattern, text)
+}
+
+// extractReviewURL scans text for a review request URL on the configured
+// forge and returns its output type and the last match.
+func (o *Orchestrator) extractReviewURL(text string) (outputType, url string) {
+	switch o.forge() {
+	case ForgeGitLab:
+		return OutputTypeMR, ExtractMRURL(text)
+	case ForgeGitea:
+		return OutputTypePR, lastMatch(giteaPRURLPattern, text)
+	}
+	return OutputTypePR, ExtractPRURL(text)
+}
+
+func lastMatch(re *regexp.Regexp, text string) string {
+	matches := re.FindAllString(text, -1)
 	if len(matches) == 0 {
 		return ""
 	}
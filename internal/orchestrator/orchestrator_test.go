@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/marcus/nightshift/internal/agents"
+	"github.com/marcus/nightshift/internal/logging"
 	"github.com/marcus/nightshift/internal/tasks"
 )
 
@@ -249,6 +254,35 @@ func TestRunTaskMaxIterationsAbandoned(t *testing.T) {
 	}
 }
 
+func TestRunTaskMaxIterationsPartial(t *testing.T) {
+	// Reviews keep failing, but the last implement pass opened a PR
+	planResp := jsonResponse(PlanOutput{Steps: []string{"step1"}})
+	implResp := jsonResponse(ImplementOutput{Summary: "fixed 3 of 5 bugs"})
+	implPR := jsonResponse(ImplementOutput{Summary: "fixed 3 of 5 bugs, opened https://github.com/acme/app/pull/42"})
+	reviewFail := jsonResponse(ReviewOutput{Passed: false, Feedback: "two bugs remain"})
+
+	agent := newMockAgent(
+		planResp,
+		implResp, reviewFail,
+		implPR, reviewFail,
+	)
+	o := New(WithAgent(agent), WithConfig(Config{MaxIterations: 2}))
+
+	result, err := o.RunTask(context.Background(), &tasks.Task{ID: "test-partial", Title: "Bug finder"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != StatusPartial {
+		t.Errorf("status = %s, want %s", result.Status, StatusPartial)
+	}
+	if result.OutputType != OutputTypePR || result.OutputRef != "https://github.com/acme/app/pull/42" {
+		t.Errorf("output = %s %q, want PR link", result.OutputType, result.OutputRef)
+	}
+	if !strings.Contains(result.Error, "two bugs remain") {
+		t.Errorf("error = %q, want review feedback", result.Error)
+	}
+}
+
 func TestRunTaskPlanFails(t *testing.T) {
 	// Agent returns error during planning
 	agent := newMockAgent(agents.ExecuteResult{
@@ -466,6 +500,16 @@ func TestBuildPrompts(t *testing.T) {
 	if !containsIgnoreCase(reviewPrompt, "review") {
 		t.Error("review prompt should mention review")
 	}
+
+	// Usage tools recognise nightshift sessions by these prompts
+	for name, prompt := range map[string]string{"plan": planPrompt, "implement": implPrompt, "review": reviewPrompt} {
+		if !IsAgentPrompt(prompt) {
+			t.Errorf("IsAgentPrompt(%s prompt) = false, want true", name)
+		}
+	}
+	if IsAgentPrompt("fix the flaky test in server.go") {
+		t.Error("IsAgentPrompt(interactive prompt) = true, want false")
+	}
 }
 
 func TestExtractPRURL(t *testing.T) {
@@ -526,6 +570,49 @@ func TestExtractPRURL(t *testing.T) {
 	}
 }
 
+func TestExtractMRURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "gitlab.com", input: "Opened https://gitlab.com/acme/app/-/merge_requests/7", want: "https://gitlab.com/acme/app/-/merge_requests/7"},
+		{name: "nested groups on self-hosted", input: "MR: https://git.example.com/a/b/c/-/merge_requests/42 done", want: "https://git.example.com/a/b/c/-/merge_requests/42"},
+		{name: "multiple returns last", input: "https://gitlab.com/o/r/-/merge_requests/1 https://gitlab.com/o/r/-/merge_requests/2", want: "https://gitlab.com/o/r/-/merge_requests/2"},
+		{name: "github PR ignored", input: "https://github.com/o/r/pull/3", want: ""},
+		{name: "issue ignored", input: "https://gitlab.com/o/r/-/issues/3", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractMRURL(tt.input); got != tt.want {
+				t.Errorf("ExtractMRURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractReviewURLByForge(t *testing.T) {
+	text := "PR https://github.com/o/r/pull/1, MR https://gitlab.com/o/r/-/merge_requests/2, Gitea https://git.example.com/o/r/pulls/3"
+	tests := []struct {
+		forge    string
+		wantType string
+		wantURL  string
+	}{
+		{forge: "", wantType: "PR", wantURL: "https://github.com/o/r/pull/1"},
+		{forge: ForgeGitHub, wantType: "PR", wantURL: "https://github.com/o/r/pull/1"},
+		{forge: ForgeGitLab, wantType: "MR", wantURL: "https://gitlab.com/o/r/-/merge_requests/2"},
+		{forge: ForgeGitea, wantType: "PR", wantURL: "https://git.example.com/o/r/pulls/3"},
+	}
+	for _, tt := range tests {
+		o := New()
+		o.SetRunMetadata(&RunMetadata{Forge: tt.forge})
+		gotType, gotURL := o.extractReviewURL(text)
+		if gotType != tt.wantType || gotURL != tt.wantURL {
+			t.Errorf("forge %q: extractReviewURL() = (%q, %q), want (%q, %q)", tt.forge, gotType, gotURL, tt.wantType, tt.wantURL)
+		}
+	}
+}
+
 func TestRunTaskExtractsPRURL(t *testing.T) {
 	// Setup mock: plan, implement (with PR URL in raw output), review (pass)
 	planResp := jsonResponse(PlanOutput{
@@ -770,6 +857,30 @@ func TestBuildPlanPrompt_EmptyBranch(t *testing.T) {
 	}
 }
 
+func TestBuildPrompts_WorkBranch(t *testing.T) {
+	o := New()
+	o.SetRunMetadata(&RunMetadata{WorkBranch: "nightshift/lint-fix/20260102-030405"})
+
+	task := &tasks.Task{
+		ID:          "work-branch-test",
+		Title:       "Work Branch Test",
+		Description: "Test work branch naming",
+	}
+	plan := &PlanOutput{Steps: []string{"step1"}, Description: "test plan"}
+
+	if prompt := o.buildPlanPrompt(task); !strings.Contains(prompt, "Name the feature branch `nightshift/lint-fix/20260102-030405`.") {
+		t.Errorf("plan prompt missing work branch\nGot:\n%s", prompt)
+	}
+	if prompt := o.buildImplementPrompt(task, plan, 2); !strings.Contains(prompt, "Name the feature branch `nightshift/lint-fix/20260102-030405`;") {
+		t.Errorf("implement prompt missing work branch\nGot:\n%s", prompt)
+	}
+
+	o.SetRunMetadata(&RunMetadata{})
+	if prompt := o.buildPlanPrompt(task); strings.Contains(prompt, "Name the feature branch") {
+		t.Errorf("plan prompt should not name a branch without WorkBranch\nGot:\n%s", prompt)
+	}
+}
+
 func TestBuildImplementPrompt_WithBranch(t *testing.T) {
 	o := New()
 	o.SetRunMetadata(&RunMetadata{Branch: "staging"})
@@ -790,6 +901,48 @@ func TestBuildImplementPrompt_WithBranch(t *testing.T) {
 	}
 }
 
+func TestBuildImplementPrompt_PRSettings(t *testing.T) {
+	task := &tasks.Task{
+		ID:          "impl-pr-test",
+		Title:       "Impl PR Test",
+		Description: "Test PR flags in implement prompt",
+	}
+	plan := &PlanOutput{
+		Steps:       []string{"step1"},
+		Description: "test plan",
+	}
+
+	tests := []struct {
+		name string
+		meta *RunMetadata
+		want string
+	}{
+		{name: "draft and base", meta: &RunMetadata{PRDraft: true, PRBase: "nightly"}, want: "`gh pr create --draft --base nightly`"},
+		{name: "base only", meta: &RunMetadata{PRBase: "nightly"}, want: "`gh pr create --base nightly`"},
+		{name: "draft only", meta: &RunMetadata{PRDraft: true}, want: "`gh pr create --draft`"},
+		{name: "unset", meta: &RunMetadata{}, want: ""},
+		{name: "gitlab", meta: &RunMetadata{PRDraft: true, PRBase: "nightly", Forge: ForgeGitLab}, want: "`glab mr create --draft --target-branch nightly`"},
+		{name: "gitea", meta: &RunMetadata{PRDraft: true, PRBase: "nightly", Forge: ForgeGitea}, want: "`tea pulls create --base nightly` to open it. Prefix the title with `WIP:`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := New()
+			o.SetRunMetadata(tt.meta)
+			prompt := o.buildImplementPrompt(task, plan, 1)
+			if tt.want == "" {
+				if strings.Contains(prompt, "gh pr create") {
+					t.Errorf("implement prompt should not contain PR command when unset\nGot:\n%s", prompt)
+				}
+				return
+			}
+			if !strings.Contains(prompt, tt.want) {
+				t.Errorf("implement prompt missing %s\nGot:\n%s", tt.want, prompt)
+			}
+		})
+	}
+}
+
 func TestBuildImplementPrompt_WithoutBranch(t *testing.T) {
 	o := New() // no runMeta
 
@@ -886,6 +1039,280 @@ func TestCurrentBranch(t *testing.T) {
 	}
 }
 
+func TestMatchDenyPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"migrations/", "migrations/001_init.sql", true},
+		{"migrations/", "db/migrations/001_init.sql", true},
+		{"migrations", "migrations/001_init.sql", true},
+		{"vendor/**", "vendor/github.com/x/y.go", true},
+		{"./vendor/**", "vendor/a.go", true},
+		{"vendor/**", "internal/vendor.go", false},
+		{"*.lock", "Cargo.lock", true},
+		{"*.lock", "web/yarn.lock", true},
+		{"*.lock", "lockfile.go", false},
+		{"db/migrations/*.sql", "db/migrations/002.sql", true},
+		{"db/migrations/*.sql", "migrations/002.sql", false},
+		{"**/testdata/**", "pkg/a/testdata/golden.txt", true},
+		{"docs/*.md", "docs/guide/intro.md", false},
+		{"", "anything", false},
+	}
+	for _, tt := range tests {
+		if got := MatchDenyPath(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("MatchDenyPath(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+// denyPathAgent is a mock agent that writes files into the repo during the
+// implement call, as a real agent would.
+type denyPathAgent struct {
+	*mockAgent
+	dir    string
+	writes map[string]string
+}
+
+func (a *denyPathAgent) Execute(ctx context.Context, opts agents.ExecuteOptions) (*agents.ExecuteResult, error) {
+	if strings.HasPrefix(opts.Prompt, "You are an implementation agent") {
+		for name, content := range a.writes {
+			path := filepath.Join(a.dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return a.mockAgent.Execute(ctx, opts)
+}
+
+func initDenyPathRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("dirty before the run\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "test"},
+		{"commit", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	return dir
+}
+
+func TestRunTaskDenyPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		writes     map[string]string
+		wantStatus TaskStatus
+		wantDenied []string
+	}{
+		{
+			name:       "denied change fails the task",
+			writes:     map[string]string{"migrations/002.sql": "DROP TABLE users;", "main.go": "package main"},
+			wantStatus: StatusFailed,
+			wantDenied: []string{"migrations/002.sql"},
+		},
+		{
+			name:       "allowed change completes",
+			writes:     map[string]string{"main.go": "package main", "notes.txt": "edited"},
+			wantStatus: StatusCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initDenyPathRepo(t)
+			agent := &denyPathAgent{
+				mockAgent: newMockAgent(
+					jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+					jsonResponse(ImplementOutput{Summary: "done"}),
+					jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+				),
+				dir:    dir,
+				writes: tt.writes,
+			}
+			o := New(WithAgent(agent))
+			o.SetRunMetadata(&RunMetadata{DenyPaths: []string{"migrations/", "*.lock"}})
+
+			result, err := o.RunTask(context.Background(), &tasks.Task{ID: "deny", Title: "Deny"}, dir)
+			if err != nil {
+				t.Fatalf("RunTask: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+			if strings.Join(result.DeniedPaths, ",") != strings.Join(tt.wantDenied, ",") {
+				t.Errorf("DeniedPaths = %v, want %v", result.DeniedPaths, tt.wantDenied)
+			}
+			implCall := agent.calls[1]
+			if strings.Join(implCall.DenyPaths, ",") != "migrations/,*.lock" {
+				t.Errorf("implement DenyPaths = %v", implCall.DenyPaths)
+			}
+			if !strings.Contains(implCall.Prompt, "Do not create, modify, or delete files matching: `migrations/`, `*.lock`") {
+				t.Errorf("implement prompt missing deny instruction:\n%s", implCall.Prompt)
+			}
+		})
+	}
+}
+
+func TestRunTaskReadOnly(t *testing.T) {
+	dir := initDenyPathRepo(t)
+	agent := &denyPathAgent{
+		mockAgent: newMockAgent(
+			jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+			jsonResponse(ImplementOutput{Summary: "done"}),
+			jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+		),
+		dir:    dir,
+		writes: map[string]string{"main.go": "package main"},
+	}
+	o := New(WithAgent(agent))
+	o.SetRunMetadata(&RunMetadata{WorkBranch: "nightshift/doc-drift", DenyPaths: []string{"*.lock"}, ReadOnly: true})
+
+	result, err := o.RunTask(context.Background(), &tasks.Task{ID: "ro", Title: "Doc drift"}, dir)
+	if err != nil {
+		t.Fatalf("RunTask: %v", err)
+	}
+	if result.Status != StatusFailed || !strings.Contains(result.Error, "read-only project: main.go") {
+		t.Fatalf("status = %s, error %q; want failed for changing main.go", result.Status, result.Error)
+	}
+	plan, impl := agent.calls[0].Prompt, agent.calls[1].Prompt
+	for name, prompt := range map[string]string{"plan": plan, "implement": impl} {
+		if !strings.Contains(prompt, "This project is read-only") {
+			t.Errorf("%s prompt missing read-only instruction:\n%s", name, prompt)
+		}
+		if strings.Contains(prompt, "nightshift/doc-drift") || strings.Contains(prompt, "open a PR") {
+			t.Errorf("%s prompt mentions the work branch or a PR:\n%s", name, prompt)
+		}
+	}
+	if strings.Join(agent.calls[1].DenyPaths, ",") != "**" {
+		t.Errorf("implement DenyPaths = %v, want [**]", agent.calls[1].DenyPaths)
+	}
+}
+
+// commitAgent is a mock agent that commits on a feature branch during the
+// implement call and switches back, as the implement prompt asks.
+type commitAgent struct {
+	*mockAgent
+	dir    string
+	branch string
+}
+
+func (a *commitAgent) Execute(ctx context.Context, opts agents.ExecuteOptions) (*agents.ExecuteResult, error) {
+	if strings.HasPrefix(opts.Prompt, "You are an implementation agent") {
+		for _, args := range [][]string{
+			{"checkout", "-q", "-b", a.branch},
+			{"commit", "-q", "--allow-empty", "-m", "lint: fix parser warnings"},
+			{"commit", "-q", "--allow-empty", "-m", "lint: fix cli warnings"},
+			{"checkout", "-q", "-"},
+		} {
+			cmd := exec.CommandContext(ctx, "git", args...)
+			cmd.Dir = a.dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("git %v: %s: %w", args, out, err)
+			}
+		}
+	}
+	return a.mockAgent.Execute(ctx, opts)
+}
+
+func TestRunTaskRecordsCommits(t *testing.T) {
+	dir := initDenyPathRepo(t)
+	agent := &commitAgent{
+		mockAgent: newMockAgent(
+			jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+			jsonResponse(ImplementOutput{Summary: "done"}),
+			jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+		),
+		dir:    dir,
+		branch: "nightshift/lint-fix",
+	}
+	o := New(WithAgent(agent))
+
+	result, err := o.RunTask(context.Background(), &tasks.Task{ID: "lint", Title: "Lint"}, dir)
+	if err != nil {
+		t.Fatalf("RunTask: %v", err)
+	}
+	if result.Branch != "nightshift/lint-fix" {
+		t.Errorf("Branch = %q, want the branch the agent committed to", result.Branch)
+	}
+	if len(result.Commits) != 2 {
+		t.Fatalf("Commits = %+v, want 2", result.Commits)
+	}
+	for i, subject := range []string{"lint: fix cli warnings", "lint: fix parser warnings"} {
+		c := result.Commits[i]
+		if c.Subject != subject || c.Branch != "nightshift/lint-fix" || len(c.SHA) != 40 {
+			t.Errorf("Commits[%d] = %+v, want %q on nightshift/lint-fix", i, c, subject)
+		}
+	}
+
+	// Outside a git repo there is nothing to record
+	o = New(WithAgent(newMockAgent(
+		jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+		jsonResponse(ImplementOutput{Summary: "done"}),
+		jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+	)))
+	result, err = o.RunTask(context.Background(), &tasks.Task{ID: "lint", Title: "Lint"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("RunTask outside git: %v", err)
+	}
+	if result.Status != StatusCompleted || result.Branch != "" || len(result.Commits) != 0 {
+		t.Errorf("outside git: status %s, branch %q, commits %v", result.Status, result.Branch, result.Commits)
+	}
+}
+
+func TestRepoSnapshotChangedFilesOnBranch(t *testing.T) {
+	dir := initDenyPathRepo(t)
+	ctx := context.Background()
+	snap, err := snapshotRepo(ctx, dir)
+	if err != nil {
+		t.Fatalf("snapshotRepo: %v", err)
+	}
+	start, err := CurrentBranch(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commit on a feature branch, then switch back as agents are told to
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vendor", "lib.go"), []byte("package lib"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"checkout", "-b", "feature"},
+		{"add", "vendor"},
+		{"commit", "-m", "vendor change"},
+		{"checkout", start},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+
+	changed, err := snap.changedFiles(ctx, dir)
+	if err != nil {
+		t.Fatalf("changedFiles: %v", err)
+	}
+	if strings.Join(changed, ",") != "vendor/lib.go" {
+		t.Errorf("changedFiles = %v, want [vendor/lib.go]", changed)
+	}
+}
+
 func TestCurrentBranch_InvalidDir(t *testing.T) {
 	_, err := CurrentBranch(context.Background(), t.TempDir())
 	if err == nil {
@@ -930,3 +1357,390 @@ func TestRunTaskNoPRURL(t *testing.T) {
 		t.Errorf("OutputRef = %q, want empty", result.OutputRef)
 	}
 }
+
+// spendingAgent burns perCall tokens on a shared counter for each call, then
+// works for a while unless cancelled.
+type spendingAgent struct {
+	*mockAgent
+	used    atomic.Int64
+	perCall int64
+	work    time.Duration
+}
+
+func (a *spendingAgent) Execute(ctx context.Context, opts agents.ExecuteOptions) (*agents.ExecuteResult, error) {
+	a.used.Add(a.perCall)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(a.work):
+	}
+	return a.mockAgent.Execute(ctx, opts)
+}
+
+func (a *spendingAgent) meter() (int64, error) {
+	return a.used.Load(), nil
+}
+
+func TestRunTaskTokenCap(t *testing.T) {
+	tests := []struct {
+		name       string
+		cap        int64
+		wantStatus TaskStatus
+		wantTokens int64
+	}{
+		// plan 60k, implement 120k: crosses the cap while implementing
+		{"exceeded", 100_000, StatusAbandoned, 120_000},
+		// plan, implement, review: 180k, under the cap
+		{"under cap", 500_000, StatusCompleted, 180_000},
+		{"no cap", 0, StatusCompleted, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &spendingAgent{
+				mockAgent: newMockAgent(
+					jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+					jsonResponse(ImplementOutput{Summary: "done"}),
+					jsonResponse(ReviewOutput{Passed: true}),
+				),
+				perCall: 60_000,
+				work:    50 * time.Millisecond,
+			}
+			o := New(
+				WithAgent(agent),
+				WithConfig(Config{MaxIterations: 1, AgentTimeout: time.Minute, TokenPollInterval: 5 * time.Millisecond}),
+				WithTokenMeter(agent.meter),
+			)
+			o.SetTokenCap(tt.cap)
+
+			result, err := o.RunTask(context.Background(), &tasks.Task{ID: "t", Title: "Runaway"}, "/work")
+			if err != nil {
+				t.Fatalf("RunTask: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+			if result.TokensUsed != tt.wantTokens {
+				t.Errorf("TokensUsed = %d, want %d", result.TokensUsed, tt.wantTokens)
+			}
+			if tt.wantStatus == StatusAbandoned {
+				if !strings.Contains(result.Error, "token cap exceeded") {
+					t.Errorf("error = %q, want token cap exceeded", result.Error)
+				}
+				if len(agent.calls) != 1 {
+					t.Errorf("agent calls completed = %d, want 1 (review never started)", len(agent.calls))
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeAgentOutput(t *testing.T) {
+	output := strings.Join([]string{
+		"Reading files...",
+		"thinking about the plan",
+		"Running tests: 12 passed",
+		"Created PR https://github.com/o/r/pull/9",
+		"✓ lint clean",
+		"",
+		"random chatter",
+	}, "\n")
+	got := summarizeAgentOutput(output)
+	want := []string{"Running tests: 12 passed", "Created PR https://github.com/o/r/pull/9", "✓ lint clean"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("summarizeAgentOutput = %q, want %q", got, want)
+	}
+
+	var many []string
+	for i := range agentOutputSummaryMax + 5 {
+		many = append(many, fmt.Sprintf("test %d passed", i))
+	}
+	got = summarizeAgentOutput(strings.Join(many, "\n"))
+	if len(got) != agentOutputSummaryMax || got[len(got)-1] != many[len(many)-1] {
+		t.Errorf("summary kept %d lines ending %q, want the last %d", len(got), got[len(got)-1], agentOutputSummaryMax)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := tailLines("a\nb\nc\n", 2); strings.Join(got, ",") != "b,c" {
+		t.Errorf("tailLines = %q", got)
+	}
+	if got := tailLines("", 5); got != nil {
+		t.Errorf("tailLines(empty) = %q, want nil", got)
+	}
+}
+
+func TestLogAgentOutput(t *testing.T) {
+	output := "chatter\nRunning tests: 3 failed\nstack trace line\n"
+	tests := []struct {
+		mode    AgentOutputMode
+		failed  bool
+		want    []string
+		notWant []string
+	}{
+		{AgentOutputNone, false, nil, []string{"agent output"}},
+		{AgentOutputNone, true, []string{"agent output tail", "stack trace line"}, []string{"agent output summary"}},
+		{"", false, []string{"agent output summary", "3 failed"}, []string{"chatter", "agent output tail"}},
+		{AgentOutputSummary, true, []string{"agent output summary", "agent output tail", "chatter"}, nil},
+		{AgentOutputFull, true, []string{`"message":"agent output"`, "chatter"}, []string{"agent output tail"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/failed=%v", tt.mode, tt.failed), func(t *testing.T) {
+			dir := t.TempDir()
+			logger, err := logging.New(logging.Config{Path: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			o := New(WithLogger(logger), WithConfig(Config{AgentOutput: tt.mode}))
+			o.logAgentOutput(StatusExecuting, &agents.ExecuteResult{Output: output}, tt.failed)
+			_ = logger.Close()
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+			var logged string
+			for _, f := range files {
+				data, _ := os.ReadFile(f)
+				logged += string(data)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(logged, s) {
+					t.Errorf("log missing %q:\n%s", s, logged)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(logged, s) {
+					t.Errorf("log should not contain %q:\n%s", s, logged)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTaskEventLog(t *testing.T) {
+	agent := newMockAgent(agents.ExecuteResult{
+		Output:   "failed to plan",
+		ExitCode: 1,
+		Error:    "planning error",
+	})
+	dir := t.TempDir()
+	var handled int
+	o := New(WithAgent(agent), WithEventLog(dir), WithEventHandler(func(Event) { handled++ }))
+
+	task := &tasks.Task{ID: "lint-fix:/src/app", Title: "Lint Fix"}
+	result, _ := o.RunTask(context.Background(), task, "")
+
+	if result.EventLog == "" || filepath.Dir(result.EventLog) != dir {
+		t.Fatalf("EventLog = %q, want a file in %s", result.EventLog, dir)
+	}
+	if !strings.HasSuffix(result.EventLog, "-lint-fix_src_app.jsonl") {
+		t.Errorf("EventLog = %q, want task id in the file name", result.EventLog)
+	}
+	records, err := ReadEventLog(result.EventLog)
+	if err != nil {
+		t.Fatalf("ReadEventLog: %v", err)
+	}
+	if len(records) != handled {
+		t.Errorf("logged %d events, handler saw %d", len(records), handled)
+	}
+	var started bool
+	for _, rec := range records {
+		if rec.Type == "task_start" && rec.TaskTitle == "Lint Fix" {
+			started = true
+		}
+	}
+	if !started {
+		t.Errorf("records = %+v, want a task_start", records)
+	}
+	last := records[len(records)-1]
+	if last.Type != "task_end" || last.Status != StatusFailed || last.Error == "" {
+		t.Errorf("last record = %+v, want failed task_end", last)
+	}
+}
+
+func TestEventLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.jsonl")
+	l, err := openEventLog(path, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 10; i++ {
+		if err := l.write(Event{Type: EventIterationStart, Time: time.Now(), TaskID: "t", Iteration: i}); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := l.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{path, path + ".1"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("stat %s: %v", p, err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, want <= 300", p, info.Size())
+		}
+	}
+
+	records, err := ReadEventLog(path)
+	if err != nil {
+		t.Fatalf("ReadEventLog: %v", err)
+	}
+	if len(records) == 0 || records[len(records)-1].Iteration != 10 {
+		t.Fatalf("records = %+v, want the newest iteration last", records)
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Iteration != records[i-1].Iteration+1 {
+			t.Errorf("records out of order at %d: %d after %d", i, records[i].Iteration, records[i-1].Iteration)
+		}
+	}
+
+	if _, err := ReadEventLog(filepath.Join(t.TempDir(), "missing.jsonl")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing log err = %v, want ErrNotExist", err)
+	}
+}
+
+func TestBuildPrompts_DiffOnly(t *testing.T) {
+	o := New()
+	o.SetRunMetadata(&RunMetadata{Branch: "main", WorkBranch: "nightshift/lint", DiffOnly: true})
+	task := &tasks.Task{ID: "t", Title: "Lint", Description: "fix lint"}
+	plan := &PlanOutput{Steps: []string{"s"}, Description: "p"}
+
+	prompts := map[string]string{
+		"plan":      o.buildPlanPrompt(task),
+		"implement": o.buildImplementPrompt(task, plan, 1),
+		"review":    o.buildReviewPrompt(task, &ImplementOutput{Summary: "done"}),
+	}
+	for name, prompt := range prompts {
+		if strings.Contains(prompt, "nightshift/lint") || strings.Contains(prompt, "open a PR") {
+			t.Errorf("%s prompt mentions the work branch or a PR:\n%s", name, prompt)
+		}
+	}
+	if !strings.Contains(prompts["plan"], "Propose changes only") {
+		t.Errorf("plan prompt missing diff-only instruction:\n%s", prompts["plan"])
+	}
+}
+
+func TestRunTaskDiffOnly(t *testing.T) {
+	dir := initDenyPathRepo(t)
+	for _, args := range [][]string{
+		{"add", "notes.txt"},
+		{"commit", "-m", "notes"},
+	} {
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s: %v", args, out, err)
+		}
+	}
+	agent := &denyPathAgent{
+		mockAgent: newMockAgent(
+			jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+			jsonResponse(ImplementOutput{Summary: "done"}),
+			jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+		),
+		dir:    dir,
+		writes: map[string]string{"main.go": "package main\n", "notes.txt": "edited\n"},
+	}
+	diffDir := t.TempDir()
+	o := New(WithAgent(agent))
+	o.SetRunMetadata(&RunMetadata{WorkBranch: "nightshift/lint", DiffOnly: true, DiffDir: diffDir})
+
+	result, err := o.RunTask(context.Background(), &tasks.Task{ID: "diff", Title: "Diff", Type: "lint-fix"}, dir)
+	if err != nil {
+		t.Fatalf("RunTask: %v", err)
+	}
+	if result.Status != StatusPartial {
+		t.Fatalf("status = %s, want partial (error %q)", result.Status, result.Error)
+	}
+	if result.Branch != "" {
+		t.Errorf("Branch = %q, want empty", result.Branch)
+	}
+	if filepath.Dir(result.DiffPath) != diffDir || !strings.HasSuffix(result.DiffPath, ".diff") {
+		t.Fatalf("DiffPath = %q, want a .diff in %s", result.DiffPath, diffDir)
+	}
+	patch, err := os.ReadFile(result.DiffPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"+package main", "-dirty before the run", "+edited"} {
+		if !strings.Contains(string(patch), want) {
+			t.Errorf("patch missing %q:\n%s", want, patch)
+		}
+	}
+
+	dirty, err := gitDirtyFiles(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirty) > 0 {
+		t.Errorf("working tree not restored, dirty: %v", dirty)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "notes.txt")); string(data) != "dirty before the run\n" {
+		t.Errorf("notes.txt = %q, want original content", data)
+	}
+}
+
+func TestRunTaskDiffOnlyDefaultDirExcluded(t *testing.T) {
+	dir := initDenyPathRepo(t)
+	if err := os.Remove(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	newAgent := func() *denyPathAgent {
+		return &denyPathAgent{
+			mockAgent: newMockAgent(
+				jsonResponse(PlanOutput{Steps: []string{"step1"}, Description: "plan"}),
+				jsonResponse(ImplementOutput{Summary: "done"}),
+				jsonResponse(ReviewOutput{Passed: true, Feedback: "ok"}),
+			),
+			dir:    dir,
+			writes: map[string]string{"main.go": "package main\n"},
+		}
+	}
+
+	// No .gitignore covers the default dir; the second run must still find
+	// a clean tree.
+	for run := 1; run <= 2; run++ {
+		o := New(WithAgent(newAgent()))
+		o.SetRunMetadata(&RunMetadata{DiffOnly: true})
+		result, err := o.RunTask(context.Background(), &tasks.Task{ID: "diff", Title: "Diff", Type: "lint-fix"}, dir)
+		if err != nil {
+			t.Fatalf("run %d: RunTask: %v", run, err)
+		}
+		if result.Status != StatusPartial || filepath.Dir(result.DiffPath) != filepath.Join(dir, DefaultDiffDir) {
+			t.Fatalf("run %d: status = %s, DiffPath = %q (error %q)", run, result.Status, result.DiffPath, result.Error)
+		}
+		dirty, err := gitDirtyFiles(context.Background(), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dirty) > 0 {
+			t.Fatalf("run %d: tree dirty after saving the patch: %v", run, dirty)
+		}
+	}
+
+	exclude, err := os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(exclude), "/"+DefaultDiffDir+"/\n"); n != 1 {
+		t.Errorf("exclude lists %s %d times, want once:\n%s", DefaultDiffDir, n, exclude)
+	}
+}
+
+func TestRunTaskDiffOnlyDirtyTree(t *testing.T) {
+	dir := initDenyPathRepo(t) // leaves notes.txt untracked
+	agent := newMockAgent()
+	o := New(WithAgent(agent))
+	o.SetRunMetadata(&RunMetadata{DiffOnly: true})
+
+	result, err := o.RunTask(context.Background(), &tasks.Task{ID: "diff", Title: "Diff"}, dir)
+	if err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Fatalf("err = %v, want uncommitted changes error", err)
+	}
+	if result.Status != StatusFailed {
+		t.Errorf("status = %s, want failed", result.Status)
+	}
+	if len(agent.calls) != 0 {
+		t.Errorf("agent calls = %d, want 0", len(agent.calls))
+	}
+}
@@ -412,3 +412,82 @@ func DiscoverProjectsInDir(dir string) ([]string, error) {
 	}
 	return projects, nil
 }
+
+// IgnoreFileName marks directories scan roots should skip. In a scan root it
+// lists subdirectory names or glob patterns to skip, one per line; inside a
+// repo its presence opts that repo out.
+const IgnoreFileName = ".nightshiftignore"
+
+// ScanRoot returns the immediate subdirectories of root that contain a .git
+// entry, in name order. ignored lists repos skipped via IgnoreFileName.
+func ScanRoot(root string) (found, ignored []string, err error) {
+	root, err = filepath.Abs(expandPath(root))
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	patterns := readIgnorePatterns(filepath.Join(root, IgnoreFileName))
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+			continue
+		}
+		if ignoredName(entry.Name(), patterns) {
+			ignored = append(ignored, path)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(path, IgnoreFileName)); err == nil {
+			ignored = append(ignored, path)
+			continue
+		}
+		found = append(found, path)
+	}
+	return found, ignored, nil
+}
+
+// ScanRoots scans each root in order and returns the discovered repos.
+// Roots that cannot be read are skipped.
+func ScanRoots(roots []string) []string {
+	var out []string
+	for _, root := range roots {
+		found, _, err := ScanRoot(root)
+		if err != nil {
+			continue
+		}
+		out = append(out, found...)
+	}
+	return out
+}
+
+// readIgnorePatterns reads an ignore file, dropping blank lines and # comments.
+func readIgnorePatterns(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(strings.TrimSpace(line), "/")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+func ignoredName(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
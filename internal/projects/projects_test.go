@@ -3,6 +3,7 @@ package projects
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -394,3 +395,37 @@ func newTestState(t *testing.T) *state.State {
 	}
 	return st
 }
+
+func TestScanRoot(t *testing.T) {
+	root := t.TempDir()
+	mkRepo := func(name string) string {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Join(path, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	app := mkRepo("app")
+	lib := mkRepo("lib")
+	optOut := mkRepo("opt-out")
+	archived := mkRepo("archive-2020")
+	_ = os.Mkdir(filepath.Join(root, "not-a-repo"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "file.txt"), []byte("x"), 0644)
+	_ = os.WriteFile(filepath.Join(optOut, IgnoreFileName), nil, 0644)
+	_ = os.WriteFile(filepath.Join(root, IgnoreFileName), []byte("# old stuff\narchive-*/\n\n"), 0644)
+
+	found, ignored, err := ScanRoot(root)
+	if err != nil {
+		t.Fatalf("ScanRoot() error = %v", err)
+	}
+	if strings.Join(found, ",") != app+","+lib {
+		t.Errorf("found = %v, want [%s %s]", found, app, lib)
+	}
+	if strings.Join(ignored, ",") != archived+","+optOut {
+		t.Errorf("ignored = %v, want [%s %s]", ignored, archived, optOut)
+	}
+
+	if got := ScanRoots([]string{filepath.Join(root, "missing"), root}); len(got) != 2 {
+		t.Errorf("ScanRoots() = %v, want 2 repos (missing root skipped)", got)
+	}
+}
@@ -73,10 +73,20 @@ func (u *TokenUsage) TotalTokens() int64 {
 	return u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
 }
 
+// Total returns the usage summed under mode: input + output when billable,
+// every field (cache reads and writes included) when raw.
+func (u *TokenUsage) Total(mode TokenAccounting) int64 {
+	if mode == AccountingRaw {
+		return u.TotalTokens()
+	}
+	return u.InputTokens + u.OutputTokens
+}
+
 // Claude wraps the Claude Code CLI as a provider.
 type Claude struct {
-	dataPath              string      // Path to ~/.claude
-	statsCache            *StatsCache // Cached stats data
+	dataPath              string          // Path to ~/.claude
+	statsCache            *StatsCache     // Cached stats data
+	accounting            TokenAccounting // Which counters token totals sum (default billable)
 	mu                    sync.RWMutex
 	lastUsedPercentSource string
 }
@@ -96,6 +106,13 @@ func NewClaudeWithPath(dataPath string) *Claude {
 	}
 }
 
+// SetTokenAccounting selects which counters GetTodayUsage, GetWeeklyUsage and
+// GetUsedPercent sum. Raw accounting reads session JSONL directly because
+// stats-cache.json carries no cache counters.
+func (c *Claude) SetTokenAccounting(mode TokenAccounting) {
+	c.accounting = mode
+}
+
 // Name returns "claude".
 func (c *Claude) Name() string {
 	return "claude"
@@ -223,9 +240,14 @@ func (c *Claude) GetTodayUsage() (int64, error) {
 }
 
 func (c *Claude) getTodayUsageWithSource() (int64, string, error) {
+	if c.accounting == AccountingRaw {
+		tokens, err := c.ScanTodayTokens()
+		return tokens, "jsonl-raw", err
+	}
+
 	stats, err := c.ParseStatsCache()
 	if err == nil {
-		c.statsCache = stats
+		c.setStatsCache(stats)
 		today := time.Now().Format("2006-01-02")
 		byDate := stats.TokensByDate()
 		if t, ok := byDate[today]; ok && t > 0 {
@@ -252,9 +274,14 @@ func (c *Claude) GetWeeklyUsage() (int64, error) {
 }
 
 func (c *Claude) getWeeklyUsageWithSource() (int64, string, error) {
+	if c.accounting == AccountingRaw {
+		tokens, err := c.ScanWeeklyTokens()
+		return tokens, "jsonl-raw", err
+	}
+
 	stats, err := c.ParseStatsCache()
 	if err == nil {
-		c.statsCache = stats
+		c.setStatsCache(stats)
 		byDate := stats.TokensByDate()
 		var total int64
 		now := time.Now()
@@ -323,6 +350,14 @@ func (c *Claude) LastUsedPercentSource() string {
 	return c.lastUsedPercentSource
 }
 
+// setStatsCache keeps the last parsed stats-cache.json. A read abandoned by
+// ReadWithTimeout may still finish and call this, so it is locked.
+func (c *Claude) setStatsCache(stats *StatsCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statsCache = stats
+}
+
 func (c *Claude) setLastUsedPercentSource(source string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -364,26 +399,27 @@ func (c *Claude) ListSessionFiles() ([]string, error) {
 	return sessions, err
 }
 
-// ScanTodayTokens walks JSONL session files and sums input+output tokens
-// for assistant messages timestamped today (local time). Files not modified
-// today are skipped via mtime check for performance.
+// ScanTodayTokens walks JSONL session files and sums tokens under the
+// provider's accounting for assistant messages timestamped today (local
+// time). Files not modified today are skipped via mtime check for performance.
 func (c *Claude) ScanTodayTokens() (int64, error) {
 	today := time.Now().Local().Format("2006-01-02")
-	return c.scanTokensSince(today, 0)
+	return c.scanTokensSince(today, 0, c.accounting)
 }
 
-// ScanWeeklyTokens walks JSONL session files and sums input+output tokens
-// for assistant messages timestamped within the last 7 days (local time).
+// ScanWeeklyTokens walks JSONL session files and sums tokens under the
+// provider's accounting for assistant messages timestamped within the last
+// 7 days (local time).
 func (c *Claude) ScanWeeklyTokens() (int64, error) {
 	oldest := time.Now().Local().AddDate(0, 0, -6).Format("2006-01-02")
-	return c.scanTokensSince(oldest, 6)
+	return c.scanTokensSince(oldest, 6, c.accounting)
 }
 
 // scanTokensSince walks projects/ for .jsonl files, skipping files whose
 // mtime is before cutoffDate minus extraDays. For each qualifying file it
-// parses lines and sums input_tokens+output_tokens for assistant messages
-// whose timestamp falls on or after cutoffDate.
-func (c *Claude) scanTokensSince(cutoffDate string, extraMtimeDays int) (int64, error) {
+// parses lines and sums tokens under mode for assistant messages whose
+// timestamp falls on or after cutoffDate.
+func (c *Claude) scanTokensSince(cutoffDate string, extraMtimeDays int, mode TokenAccounting) (int64, error) {
 	projectsDir := filepath.Join(c.dataPath, "projects")
 
 	// mtime threshold: start of cutoff day (local) minus extra buffer
@@ -415,7 +451,7 @@ func (c *Claude) scanTokensSince(cutoffDate string, extraMtimeDays int) (int64,
 			return nil
 		}
 
-		tokens, err := scanFileTokens(path, cutoffDate)
+		tokens, err := scanFileTokens(path, cutoffDate, mode)
 		if err != nil {
 			return nil // skip corrupt files
 		}
@@ -429,9 +465,9 @@ func (c *Claude) scanTokensSince(cutoffDate string, extraMtimeDays int) (int64,
 	return total, walkErr
 }
 
-// scanFileTokens reads a single JSONL file and sums input_tokens+output_tokens
-// for assistant messages whose timestamp (local) is on or after cutoffDate.
-func scanFileTokens(path string, cutoffDate string) (int64, error) {
+// scanFileTokens reads a single JSONL file and sums tokens under mode for
+// assistant messages whose timestamp (local) is on or after cutoffDate.
+func scanFileTokens(path string, cutoffDate string, mode TokenAccounting) (int64, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return 0, err
@@ -452,7 +488,7 @@ func scanFileTokens(path string, cutoffDate string) (int64, error) {
 					msg.Message.Usage != nil {
 					msgDate := msg.Timestamp.Local().Format("2006-01-02")
 					if msgDate >= cutoffDate {
-						total += msg.Message.Usage.InputTokens + msg.Message.Usage.OutputTokens
+						total += msg.Message.Usage.Total(mode)
 					}
 				}
 			}
@@ -480,3 +516,23 @@ func sumTokensByModel(tokensByModel map[string]int64) int64 {
 func (c *Claude) DataPath() string {
 	return c.dataPath
 }
+
+// DataModTime returns when the data behind GetUsedPercent last changed: the
+// newer of stats-cache.json and the latest session JSONL file. It is zero
+// when there is no data yet.
+func (c *Claude) DataModTime() (time.Time, error) {
+	var latest time.Time
+	if info, err := os.Stat(filepath.Join(c.dataPath, "stats-cache.json")); err == nil {
+		latest = info.ModTime()
+	}
+	sessions, err := c.ListSessionFiles()
+	if err != nil {
+		return latest, err
+	}
+	for _, path := range sessions {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
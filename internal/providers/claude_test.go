@@ -744,3 +744,92 @@ func TestClaudeProvider_ScanWeeklyTokens(t *testing.T) {
 		t.Errorf("ScanWeeklyTokens = %d, want %d", tokens, expected)
 	}
 }
+
+func TestClaudeProvider_TokenAccounting(t *testing.T) {
+	tmpDir := t.TempDir()
+	projDir := filepath.Join(tmpDir, "projects", "myproj")
+	if err := os.MkdirAll(projDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Local()
+	todayMorning := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location())
+	writeJSONLFile(t, filepath.Join(projDir, "s1.jsonl"), []string{
+		`{"type":"assistant","message":{"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":1000,"cache_creation_input_tokens":200}},"timestamp":"` +
+			todayMorning.Format(time.RFC3339) + `"}`,
+	})
+	stats := `{"version":1,"dailyModelTokens":[{"date":"` + now.Format("2006-01-02") + `","tokensByModel":{"claude-opus-4":150}}]}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "stats-cache.json"), []byte(stats), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode       TokenAccounting
+		want       int64
+		wantSource string
+	}{
+		{"", 150, "stats-cache"},                 // unset behaves as billable
+		{AccountingBillable, 150, "stats-cache"}, // input + output
+		{AccountingRaw, 1350, "jsonl-raw"},       // + cache reads and writes
+	}
+	for _, tt := range tests {
+		provider := NewClaudeWithPath(tmpDir)
+		provider.SetTokenAccounting(tt.mode)
+
+		today, err := provider.GetTodayUsage()
+		if err != nil {
+			t.Fatalf("GetTodayUsage(%q) error: %v", tt.mode, err)
+		}
+		weekly, err := provider.GetWeeklyUsage()
+		if err != nil {
+			t.Fatalf("GetWeeklyUsage(%q) error: %v", tt.mode, err)
+		}
+		if today != tt.want || weekly != tt.want {
+			t.Errorf("mode %q: today = %d, weekly = %d, want %d", tt.mode, today, weekly, tt.want)
+		}
+		if _, err := provider.GetUsedPercent("weekly", 700000); err != nil {
+			t.Fatalf("GetUsedPercent(%q) error: %v", tt.mode, err)
+		}
+		if got := provider.LastUsedPercentSource(); got != tt.wantSource {
+			t.Errorf("mode %q: source = %q, want %q", tt.mode, got, tt.wantSource)
+		}
+	}
+}
+
+func TestClaudeProvider_DataModTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	provider := NewClaudeWithPath(tmpDir)
+
+	if mod, err := provider.DataModTime(); err != nil || !mod.IsZero() {
+		t.Fatalf("DataModTime with no data = %v, %v; want zero", mod, err)
+	}
+
+	statsPath := filepath.Join(tmpDir, "stats-cache.json")
+	sessionDir := filepath.Join(tmpDir, "projects", "app")
+	sessionPath := filepath.Join(sessionDir, "session.jsonl")
+	if err := os.WriteFile(statsPath, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sessionPath, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	statsTime := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	sessionTime := time.Now().Add(-3 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(statsPath, statsTime, statsTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(sessionPath, sessionTime, sessionTime); err != nil {
+		t.Fatal(err)
+	}
+
+	mod, err := provider.DataModTime()
+	if err != nil {
+		t.Fatalf("DataModTime error: %v", err)
+	}
+	if !mod.Equal(sessionTime) {
+		t.Errorf("DataModTime = %v, want newest file %v", mod, sessionTime)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,6 +38,16 @@ type CodexTokenUsage struct {
 	TotalTokens           int64 `json:"total_tokens"`
 }
 
+// Total returns the usage summed under mode. Billable is TotalTokens
+// (non-cached input + output + reasoning); raw adds cached input on top of
+// all input, matching the calibration tool's alt figure.
+func (u *CodexTokenUsage) Total(mode TokenAccounting) int64 {
+	if mode == AccountingRaw {
+		return u.InputTokens + u.CachedInputTokens + u.OutputTokens + u.ReasoningOutputTokens
+	}
+	return u.TotalTokens
+}
+
 // CodexTokenCountInfo holds per-event and cumulative token usage.
 type CodexTokenCountInfo struct {
 	TotalTokenUsage *CodexTokenUsage `json:"total_token_usage"`
@@ -59,7 +70,12 @@ type CodexSessionEntry struct {
 
 // Codex wraps the Codex CLI as a provider.
 type Codex struct {
-	dataPath   string           // Path to ~/.codex
+	dataPath   string          // Path to ~/.codex
+	accounting TokenAccounting // Which counters token totals sum (default billable)
+
+	// mu guards rateLimits: a read abandoned by ReadWithTimeout may still
+	// finish and store it while a later read runs
+	mu         sync.Mutex
 	rateLimits *CodexRateLimits // Cached rate limits
 }
 
@@ -78,6 +94,12 @@ func NewCodexWithPath(dataPath string) *Codex {
 	}
 }
 
+// SetTokenAccounting selects which counters GetTodayTokens, GetWeeklyTokens
+// and the token-based used percentages sum.
+func (c *Codex) SetTokenAccounting(mode TokenAccounting) {
+	c.accounting = mode
+}
+
 // Name returns "codex".
 func (c *Codex) Name() string {
 	return "codex"
@@ -173,10 +195,27 @@ func (c *Codex) FindMostRecentSession() (string, error) {
 	return sessions[0], nil
 }
 
+// DataModTime returns the modification time of the most recent session
+// file, which backs GetUsedPercent. It is zero when there are no sessions.
+func (c *Codex) DataModTime() (time.Time, error) {
+	path, err := c.FindMostRecentSession()
+	if err != nil || path == "" {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // GetRateLimits retrieves the latest rate limits from the most recent session.
 func (c *Codex) GetRateLimits() (*CodexRateLimits, error) {
-	if c.rateLimits != nil {
-		return c.rateLimits, nil
+	c.mu.Lock()
+	cached := c.rateLimits
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
 	}
 
 	sessions, err := c.sessionFilesByModTime()
@@ -199,7 +238,9 @@ func (c *Codex) GetRateLimits() (*CodexRateLimits, error) {
 		if limits == nil || (limits.Primary == nil && limits.Secondary == nil) {
 			continue
 		}
+		c.mu.Lock()
 		c.rateLimits = limits
+		c.mu.Unlock()
 		return limits, nil
 	}
 
@@ -224,10 +265,10 @@ func (c *Codex) GetUsedPercent(mode string, weeklyBudget int64) (float64, error)
 		// unlike the 5h rolling window from rate limits.
 		if weeklyBudget > 0 {
 			usage, err := c.GetTodayTokenUsage()
-			if err == nil && usage != nil && usage.TotalTokens > 0 {
+			if err == nil && usage != nil && usage.Total(c.accounting) > 0 {
 				dailyBudget := weeklyBudget / 7
 				if dailyBudget > 0 {
-					return float64(usage.TotalTokens) / float64(dailyBudget) * 100, nil
+					return float64(usage.Total(c.accounting)) / float64(dailyBudget) * 100, nil
 				}
 			}
 		}
@@ -251,8 +292,8 @@ func (c *Codex) GetUsedPercent(mode string, weeklyBudget int64) (float64, error)
 		// Fall back to token-based if no rate limit data
 		if weeklyBudget > 0 {
 			usage, err := c.GetWeeklyTokenUsage()
-			if err == nil && usage != nil && usage.TotalTokens > 0 {
-				return float64(usage.TotalTokens) / float64(weeklyBudget) * 100, nil
+			if err == nil && usage != nil && usage.Total(c.accounting) > 0 {
+				return float64(usage.Total(c.accounting)) / float64(weeklyBudget) * 100, nil
 			}
 		}
 		return 0, nil
@@ -367,7 +408,9 @@ func (c *Codex) GetWindowMinutes(mode string) (int64, error) {
 
 // RefreshRateLimits clears cached rate limits and re-reads from disk.
 func (c *Codex) RefreshRateLimits() (*CodexRateLimits, error) {
+	c.mu.Lock()
 	c.rateLimits = nil
+	c.mu.Unlock()
 	return c.GetRateLimits()
 }
 
@@ -550,7 +593,8 @@ func (c *Codex) ListTodaySessionFiles() ([]string, error) {
 	return files, nil
 }
 
-// GetTodayTokens returns total tokens used across all sessions today.
+// GetTodayTokens returns total tokens used across all sessions today, summed
+// per the provider's token accounting.
 // Satisfies the snapshots.CodexUsage interface.
 func (c *Codex) GetTodayTokens() (int64, error) {
 	usage, err := c.GetTodayTokenUsage()
@@ -560,10 +604,11 @@ func (c *Codex) GetTodayTokens() (int64, error) {
 	if usage == nil {
 		return 0, nil
 	}
-	return usage.TotalTokens, nil
+	return usage.Total(c.accounting), nil
 }
 
-// GetWeeklyTokens returns total tokens used across all sessions in the last 7 days.
+// GetWeeklyTokens returns total tokens used across all sessions in the last 7
+// days, summed per the provider's token accounting.
 // Satisfies the snapshots.CodexUsage interface.
 func (c *Codex) GetWeeklyTokens() (int64, error) {
 	usage, err := c.GetWeeklyTokenUsage()
@@ -573,7 +618,7 @@ func (c *Codex) GetWeeklyTokens() (int64, error) {
 	if usage == nil {
 		return 0, nil
 	}
-	return usage.TotalTokens, nil
+	return usage.Total(c.accounting), nil
 }
 
 // ListSessionFilesForDate returns session files for a specific date.
@@ -1417,3 +1417,46 @@ func TestCodexParseSessionJSONL_LargeLines(t *testing.T) {
 		t.Errorf("Primary.UsedPercent = %.1f, want 34.0", limits.Primary.UsedPercent)
 	}
 }
+
+func TestCodexTokenAccounting(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	todayDir := filepath.Join(
+		tmpDir, "sessions",
+		fmt.Sprintf("%04d", now.Year()),
+		fmt.Sprintf("%02d", int(now.Month())),
+		fmt.Sprintf("%02d", now.Day()),
+	)
+	if err := os.MkdirAll(todayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := codexTokenCountJSON(5000, 4000, 1000, 200, 6200) + "\n"
+	if err := os.WriteFile(filepath.Join(todayDir, "session.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode TokenAccounting
+		want int64
+	}{
+		{"", 2200},                 // unset behaves as billable
+		{AccountingBillable, 2200}, // (5000-4000) + 1000 + 200
+		{AccountingRaw, 10200},     // 5000 + 4000 + 1000 + 200
+	}
+	for _, tt := range tests {
+		provider := NewCodexWithPath(tmpDir)
+		provider.SetTokenAccounting(tt.mode)
+
+		today, err := provider.GetTodayTokens()
+		if err != nil {
+			t.Fatalf("GetTodayTokens(%q) error: %v", tt.mode, err)
+		}
+		weekly, err := provider.GetWeeklyTokens()
+		if err != nil {
+			t.Fatalf("GetWeeklyTokens(%q) error: %v", tt.mode, err)
+		}
+		if today != tt.want || weekly != tt.want {
+			t.Errorf("mode %q: today = %d, weekly = %d, want %d", tt.mode, today, weekly, tt.want)
+		}
+	}
+}
@@ -110,6 +110,19 @@ func (c *Copilot) LoadUsageData() (*CopilotUsageData, error) {
 	return &usage, nil
 }
 
+// DataModTime returns when the usage tracking file was last written. It is
+// zero when nightshift has not tracked any Copilot requests yet.
+func (c *Copilot) DataModTime() (time.Time, error) {
+	info, err := os.Stat(c.usageFilePath())
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // SaveUsageData writes the usage tracking file to disk.
 func (c *Copilot) SaveUsageData(data *CopilotUsageData) error {
 	// Ensure directory exists
@@ -25,3 +25,15 @@ type Task struct {
 type Result struct {
 	// TODO: Add result fields (output, tokens used, etc.)
 }
+
+// TokenAccounting selects which token counters provider usage totals sum.
+type TokenAccounting string
+
+const (
+	// AccountingBillable counts tokens billed at full rate: Codex non-cached
+	// input + output + reasoning, Claude input + output.
+	AccountingBillable TokenAccounting = "billable"
+	// AccountingRaw also counts cached input: Codex input + cached input +
+	// output + reasoning, Claude input + output + cache reads and writes.
+	AccountingRaw TokenAccounting = "raw"
+)
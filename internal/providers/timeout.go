@@ -0,0 +1,79 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUsageTimeout is returned when reading a provider's local usage data
+// takes longer than the configured providers.usage_timeout, e.g. because its
+// data directory is on a slow or stale network mount.
+var ErrUsageTimeout = errors.New("usage read timed out")
+
+// hungReads counts, per key, reads that timed out and are still running.
+var (
+	hungMu    sync.Mutex
+	hungReads = map[string]int{}
+)
+
+// ReadWithTimeout calls read and returns its result, or ErrUsageTimeout once
+// timeout elapses or ctx is done. File reads can't be interrupted, so a slow
+// read keeps running in the background and its result is discarded. Until
+// it finishes, further reads for the same key (a provider name) fail at
+// once, so a hung mount costs one goroutine rather than one per call. A
+// timeout <= 0 calls read directly.
+func ReadWithTimeout[T any](ctx context.Context, key string, timeout time.Duration, read func() (T, error)) (T, error) {
+	if timeout <= 0 {
+		return read()
+	}
+	var zero T
+	hungMu.Lock()
+	hung := hungReads[key] > 0
+	hungMu.Unlock()
+	if hung {
+		return zero, fmt.Errorf("%w: an earlier read is still running", ErrUsageTimeout)
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	var finished, abandoned bool // guarded by hungMu
+	go func() {
+		v, err := read()
+		done <- result{v, err}
+		hungMu.Lock()
+		defer hungMu.Unlock()
+		finished = true
+		if abandoned {
+			if hungReads[key]--; hungReads[key] <= 0 {
+				delete(hungReads, key)
+			}
+		}
+	}()
+	abandon := func() {
+		hungMu.Lock()
+		defer hungMu.Unlock()
+		if !finished {
+			abandoned = true
+			hungReads[key]++
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		abandon()
+		return zero, fmt.Errorf("%w after %s", ErrUsageTimeout, timeout)
+	case <-ctx.Done():
+		abandon()
+		return zero, fmt.Errorf("%w: %w", ErrUsageTimeout, ctx.Err())
+	}
+}
@@ -0,0 +1,71 @@
+package reporting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat selects how token counts are printed in report views.
+type NumberFormat string
+
+const (
+	NumberCompact NumberFormat = "compact" // 1.2m, 45k, 1.5k
+	NumberGrouped NumberFormat = "grouped" // 1,234,567
+	NumberRaw     NumberFormat = "raw"     // 1234567
+)
+
+// ParseNumberFormat returns the format named by s, case-insensitively.
+// An empty string selects compact.
+func ParseNumberFormat(s string) (NumberFormat, error) {
+	switch f := NumberFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return NumberCompact, nil
+	case NumberCompact, NumberGrouped, NumberRaw:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown number format %q (valid: compact, grouped, raw)", s)
+	}
+}
+
+// Tokens formats a token count. Unknown formats fall back to compact.
+func (f NumberFormat) Tokens(n int) string {
+	switch f {
+	case NumberGrouped:
+		return groupDigits(n)
+	case NumberRaw:
+		return strconv.Itoa(n)
+	default:
+		return compactTokens(n)
+	}
+}
+
+func compactTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fm", float64(n)/1_000_000)
+	case n >= 10_000:
+		return fmt.Sprintf("%.0fk", float64(n)/1_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// groupDigits inserts a comma between each group of three digits.
+func groupDigits(n int) string {
+	s := strconv.Itoa(n)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
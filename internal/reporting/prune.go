@@ -0,0 +1,76 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runTimestampLayout is the timestamp format embedded in run report filenames.
+const runTimestampLayout = "2006-01-02-150405"
+
+// ParseRunTimestamp extracts the run timestamp from a report file base name
+// such as "run-2024-01-15-020000".
+func ParseRunTimestamp(base string) (time.Time, error) {
+	ts := strings.TrimPrefix(base, "run-")
+	return time.ParseInLocation(runTimestampLayout, ts, time.Local)
+}
+
+// PruneResult describes the report files removed (or that would be removed) by PruneReports.
+type PruneResult struct {
+	Files []string
+	Bytes int64
+}
+
+// PruneReports deletes run-*.json and run-*.md files in dir whose embedded
+// timestamp is before cutoff. File mtimes are ignored. When dryRun is true,
+// matching files are reported but not removed.
+func PruneReports(dir string, cutoff time.Time, dryRun bool) (PruneResult, error) {
+	var result PruneResult
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return result, nil
+		}
+		return result, fmt.Errorf("reading reports dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if !strings.HasPrefix(name, "run-") {
+			continue
+		}
+		ext := filepath.Ext(name)
+		if ext != ".json" && ext != ".md" {
+			continue
+		}
+		ts, err := ParseRunTimestamp(strings.TrimSuffix(name, ext))
+		if err != nil || !ts.Before(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		info, err := entry.Info()
+		if err != nil {
+			return result, fmt.Errorf("stat %s: %w", name, err)
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return result, fmt.Errorf("removing %s: %w", name, err)
+			}
+		}
+		result.Files = append(result.Files, path)
+		result.Bytes += info.Size()
+	}
+
+	sort.Strings(result.Files)
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneReports(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("run-2024-01-01-020000.json", "old-json")
+	write("run-2024-01-01-020000.md", "old-md")
+	write("run-2024-03-01-020000.json", "new")
+	write("summary-2024-01-01.md", "not a run report")
+	write("run-garbage.json", "unparseable")
+
+	// Fresh mtimes must not protect old reports.
+	cutoff := time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		dryRun    bool
+		wantFiles int
+		wantBytes int64
+		wantLeft  int
+	}{
+		{name: "dry run", dryRun: true, wantFiles: 2, wantBytes: 14, wantLeft: 5},
+		{name: "delete", dryRun: false, wantFiles: 2, wantBytes: 14, wantLeft: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := PruneReports(dir, cutoff, tt.dryRun)
+			if err != nil {
+				t.Fatalf("PruneReports: %v", err)
+			}
+			if len(res.Files) != tt.wantFiles {
+				t.Errorf("files = %d, want %d (%v)", len(res.Files), tt.wantFiles, res.Files)
+			}
+			if res.Bytes != tt.wantBytes {
+				t.Errorf("bytes = %d, want %d", res.Bytes, tt.wantBytes)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != tt.wantLeft {
+				t.Errorf("remaining = %d, want %d", len(entries), tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestPruneReportsMissingDir(t *testing.T) {
+	res, err := PruneReports(filepath.Join(t.TempDir(), "missing"), time.Now(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Files) != 0 {
+		t.Errorf("expected no files, got %v", res.Files)
+	}
+}
@@ -5,9 +5,53 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"go.yaml.in/yaml/v3"
 )
 
+const frontMatterDelim = "---"
+
+// RunReportMeta is the YAML front-matter block at the top of a markdown run
+// report. It carries the summary fields in machine-readable form so reports
+// can be parsed without scraping the markdown body.
+type RunReportMeta struct {
+	Start           time.Time `yaml:"start"`
+	End             time.Time `yaml:"end"`
+	StartBudget     int       `yaml:"start_budget"`
+	UsedBudget      int       `yaml:"used_budget"`
+	RemainingBudget int       `yaml:"remaining_budget"`
+	Tasks           int       `yaml:"tasks"`
+	Completed       int       `yaml:"completed"`
+	Partial         int       `yaml:"partial,omitempty"`
+	Failed          int       `yaml:"failed"`
+	Skipped         int       `yaml:"skipped"`
+	PRTargetBranch  string    `yaml:"pr_target_branch,omitempty"`
+	Label           string    `yaml:"label,omitempty"`
+	LogPath         string    `yaml:"log_path,omitempty"`
+	Notes           []string  `yaml:"notes,omitempty"`
+}
+
+// SplitFrontMatter separates a leading YAML front-matter block from a
+// markdown report. It returns nil meta and the full content when the report
+// has no front-matter (reports written before it was added).
+func SplitFrontMatter(content string) (*RunReportMeta, string, error) {
+	if !strings.HasPrefix(content, frontMatterDelim+"\n") {
+		return nil, content, nil
+	}
+	rest := content[len(frontMatterDelim)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelim+"\n")
+	if end == -1 {
+		return nil, content, fmt.Errorf("unterminated front-matter")
+	}
+	var meta RunReportMeta
+	if err := yaml.Unmarshal([]byte(rest[:end]), &meta); err != nil {
+		return nil, content, fmt.Errorf("parsing front-matter: %w", err)
+	}
+	return &meta, rest[end+len(frontMatterDelim)+2:], nil
+}
+
 // DefaultRunReportPath returns the default path for a run report file.
 func DefaultRunReportPath(ts time.Time) string {
 	home, _ := os.UserHomeDir()
@@ -21,11 +65,13 @@ func RenderRunReport(results *RunResults, logPath string) (string, error) {
 		return "", fmt.Errorf("results cannot be nil")
 	}
 
-	var completed, failed, skipped []TaskResult
+	var completed, partial, failed, skipped []TaskResult
 	for _, task := range results.Tasks {
 		switch task.Status {
 		case "completed":
 			completed = append(completed, task)
+		case "partial":
+			partial = append(partial, task)
 		case "failed":
 			failed = append(failed, task)
 		case "skipped":
@@ -33,7 +79,30 @@ func RenderRunReport(results *RunResults, logPath string) (string, error) {
 		}
 	}
 
+	meta, err := yaml.Marshal(RunReportMeta{
+		Start:           results.StartTime,
+		End:             results.EndTime,
+		StartBudget:     results.StartBudget,
+		UsedBudget:      results.UsedBudget,
+		RemainingBudget: results.RemainingBudget,
+		Tasks:           len(results.Tasks),
+		Completed:       len(completed),
+		Partial:         len(partial),
+		Failed:          len(failed),
+		Skipped:         len(skipped),
+		PRTargetBranch:  results.PRTargetBranch,
+		Label:           results.Label,
+		LogPath:         logPath,
+		Notes:           results.Notes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding front-matter: %w", err)
+	}
+
 	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelim + "\n")
+	buf.Write(meta)
+	buf.WriteString(frontMatterDelim + "\n")
 	buf.WriteString(fmt.Sprintf("# Nightshift Run - %s\n\n", results.StartTime.Format("2006-01-02 15:04")))
 
 	buf.WriteString("## Summary\n")
@@ -46,14 +115,32 @@ func RenderRunReport(results *RunResults, logPath string) (string, error) {
 			formatTokens(results.RemainingBudget),
 		))
 	}
-	buf.WriteString(fmt.Sprintf("- Tasks: %d completed, %d failed, %d skipped\n",
-		len(completed), len(failed), len(skipped)))
+	for _, snap := range results.ProviderSnapshots {
+		buf.WriteString(fmt.Sprintf("- Provider %s: %s\n", snap.Provider, FormatProviderSnapshot(snap)))
+	}
+	if len(partial) > 0 {
+		buf.WriteString(fmt.Sprintf("- Tasks: %d completed, %d partial, %d failed, %d skipped\n",
+			len(completed), len(partial), len(failed), len(skipped)))
+	} else {
+		buf.WriteString(fmt.Sprintf("- Tasks: %d completed, %d failed, %d skipped\n",
+			len(completed), len(failed), len(skipped)))
+	}
+	if results.PRTargetBranch != "" {
+		buf.WriteString(fmt.Sprintf("- PR target: %s\n", results.PRTargetBranch))
+	}
+	if results.Label != "" {
+		buf.WriteString(fmt.Sprintf("- Label: %s\n", results.Label))
+	}
 	if logPath != "" {
 		buf.WriteString(fmt.Sprintf("- Logs: %s\n", logPath))
 	}
+	for _, note := range results.Notes {
+		buf.WriteString(fmt.Sprintf("- Note: %s\n", note))
+	}
 	buf.WriteString("\n")
 
 	writeTaskSection(&buf, "Tasks Completed", completed, "")
+	writeTaskSection(&buf, "Tasks Partially Completed", partial, "Reason: ")
 	writeTaskSection(&buf, "Tasks Failed", failed, "")
 	writeTaskSection(&buf, "Tasks Skipped", skipped, "Skip reason: ")
 
@@ -75,6 +162,18 @@ func SaveRunReport(results *RunResults, path string, logPath string) error {
 	return nil
 }
 
+// FormatProviderSnapshot renders a snapshot as "42.0% used, resets 2024-01-15 08:00".
+func FormatProviderSnapshot(snap ProviderSnapshot) string {
+	if snap.Error != "" {
+		return "unavailable (" + snap.Error + ")"
+	}
+	line := fmt.Sprintf("%.1f%% used at start", snap.UsedPercent)
+	if !snap.ResetTime.IsZero() {
+		line += ", resets " + snap.ResetTime.Local().Format("2006-01-02 15:04")
+	}
+	return line
+}
+
 func writeTaskSection(buf *bytes.Buffer, title string, tasks []TaskResult, reasonPrefix string) {
 	if len(tasks) == 0 {
 		return
@@ -82,6 +181,9 @@ func writeTaskSection(buf *bytes.Buffer, title string, tasks []TaskResult, reaso
 	buf.WriteString("## " + title + "\n")
 	for _, task := range tasks {
 		line := fmt.Sprintf("- %s: %s (%s)", task.Project, task.Title, task.TaskType)
+		if task.Provider != "" {
+			line += fmt.Sprintf(" — provider: %s", task.Provider)
+		}
 		if task.TokensUsed > 0 {
 			line += fmt.Sprintf(" — %s tokens", formatTokens(task.TokensUsed))
 		}
@@ -91,6 +193,18 @@ func writeTaskSection(buf *bytes.Buffer, title string, tasks []TaskResult, reaso
 		if task.OutputRef != "" {
 			line += fmt.Sprintf(" — output: %s", task.OutputRef)
 		}
+		if task.Branch != "" {
+			line += fmt.Sprintf(" — branch: %s", task.Branch)
+		}
+		if len(task.DeniedPaths) > 0 {
+			line += fmt.Sprintf(" — denied paths: %s", strings.Join(task.DeniedPaths, ", "))
+		}
+		for _, artifact := range task.Artifacts {
+			line += fmt.Sprintf(" — artifact: %s", artifact)
+		}
+		if task.EventLog != "" {
+			line += fmt.Sprintf(" — events: %s", task.EventLog)
+		}
 		if reasonPrefix != "" && task.SkipReason != "" {
 			line += fmt.Sprintf(" — %s%s", reasonPrefix, task.SkipReason)
 		}
@@ -0,0 +1,43 @@
+package reporting
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunResultsProviderSnapshotsRoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+	results := &RunResults{
+		Date:      start,
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Tasks:     []TaskResult{},
+		ProviderSnapshots: []ProviderSnapshot{
+			{Provider: "claude", UsedPercent: 91.5},
+			{Provider: "codex", UsedPercent: 12, ResetTime: start.Add(72 * time.Hour)},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := SaveRunResults(results, path); err != nil {
+		t.Fatalf("SaveRunResults: %v", err)
+	}
+	loaded, err := LoadRunResults(path)
+	if err != nil {
+		t.Fatalf("LoadRunResults: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.ProviderSnapshots, results.ProviderSnapshots) {
+		t.Errorf("snapshots = %+v, want %+v", loaded.ProviderSnapshots, results.ProviderSnapshots)
+	}
+
+	content, err := RenderRunReport(results, "")
+	if err != nil {
+		t.Fatalf("RenderRunReport: %v", err)
+	}
+	if !strings.Contains(content, "- Provider claude: 91.5% used at start") {
+		t.Errorf("report missing claude snapshot:\n%s", content)
+	}
+}
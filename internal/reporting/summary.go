@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"net/smtp"
 	"os"
 	"path/filepath"
@@ -20,27 +21,86 @@ import (
 
 // TaskResult represents a completed or skipped task in the run.
 type TaskResult struct {
-	Project    string        `json:"project"`
-	TaskType   string        `json:"task_type"`
-	Title      string        `json:"title"`
-	Status     string        `json:"status"`                // completed, failed, skipped
-	OutputType string        `json:"output_type,omitempty"` // PR, Report, Analysis, etc.
-	OutputRef  string        `json:"output_ref,omitempty"`  // PR number, report path, etc.
-	TokensUsed int           `json:"tokens_used"`
-	SkipReason string        `json:"skip_reason,omitempty"` // e.g., "insufficient budget"
-	Duration   time.Duration `json:"duration,omitempty"`
+	Project     string        `json:"project"`
+	ProjectID   string        `json:"project_id,omitempty"` // Project identity across machines: origin remote URL, or the repo name
+	TaskType    string        `json:"task_type"`
+	Title       string        `json:"title"`
+	Status      string        `json:"status"`                // completed, partial, failed, skipped
+	OutputType  string        `json:"output_type,omitempty"` // PR, MR, Report, Analysis, etc.
+	OutputRef   string        `json:"output_ref,omitempty"`  // PR number, report path, etc.
+	TokensUsed  int           `json:"tokens_used"`
+	SkipReason  string        `json:"skip_reason,omitempty"` // e.g., "insufficient budget"
+	Duration    time.Duration `json:"duration,omitempty"`
+	DeniedPaths []string      `json:"denied_paths,omitempty"` // safety.deny_paths files the task changed
+	Artifacts   []string      `json:"artifacts,omitempty"`    // Files saved for review, e.g. a captured diff
+	Branch      string        `json:"branch,omitempty"`       // Feature branch the agent was asked to use or committed to
+	Commits     []TaskCommit  `json:"commits,omitempty"`      // Commits the task created, newest first per branch
+	Provider    string        `json:"provider,omitempty"`     // Provider that ran the task, e.g. "claude"
+	EventLog    string        `json:"event_log,omitempty"`    // JSONL orchestrator event stream for the task
+}
+
+// TaskCommit is a commit a task created on a local branch.
+type TaskCommit struct {
+	SHA     string `json:"sha"`
+	Branch  string `json:"branch"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// IsReviewRequest reports whether outputType marks a pull or merge request:
+// "PR", "MR", or "merge-request", in any case.
+func IsReviewRequest(outputType string) bool {
+	switch strings.ToLower(strings.TrimSpace(outputType)) {
+	case "pr", "mr", "merge-request":
+		return true
+	}
+	return false
+}
+
+// ReviewRequestLabel returns "MR" for merge request output types and "PR"
+// for everything else.
+func ReviewRequestLabel(outputType string) string {
+	switch strings.ToLower(strings.TrimSpace(outputType)) {
+	case "mr", "merge-request":
+		return "MR"
+	}
+	return "PR"
+}
+
+// PartialCredit is the fraction of a success a partial task counts for in
+// success rates: it left useful work behind but still needs finishing.
+const PartialCredit = 0.5
+
+// SuccessRate returns the percentage of total tasks that succeeded, counting
+// partial tasks at PartialCredit. It returns 0 when total is 0.
+func SuccessRate(completed, partial, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return (float64(completed) + float64(partial)*PartialCredit) / float64(total) * 100
 }
 
 // RunResults holds all results from a nightshift run.
 type RunResults struct {
-	Date            time.Time    `json:"date"`
-	StartBudget     int          `json:"start_budget"`
-	UsedBudget      int          `json:"used_budget"`
-	RemainingBudget int          `json:"remaining_budget"`
-	Tasks           []TaskResult `json:"tasks"`
-	StartTime       time.Time    `json:"start_time"`
-	EndTime         time.Time    `json:"end_time"`
-	LogPath         string       `json:"log_path,omitempty"`
+	Date              time.Time          `json:"date"`
+	StartBudget       int                `json:"start_budget"`
+	UsedBudget        int                `json:"used_budget"`
+	RemainingBudget   int                `json:"remaining_budget"`
+	Tasks             []TaskResult       `json:"tasks"`
+	StartTime         time.Time          `json:"start_time"`
+	EndTime           time.Time          `json:"end_time"`
+	LogPath           string             `json:"log_path,omitempty"`
+	PRTargetBranch    string             `json:"pr_target_branch,omitempty"`
+	Label             string             `json:"label,omitempty"`              // run --label, for filtering reports
+	ProviderSnapshots []ProviderSnapshot `json:"provider_snapshots,omitempty"` // Provider usage at run start
+	Notes             []string           `json:"notes,omitempty"`              // Run-level notes, e.g. why it stopped early
+}
+
+// ProviderSnapshot captures a provider's budget state at the start of a run.
+type ProviderSnapshot struct {
+	Provider    string    `json:"provider"`
+	UsedPercent float64   `json:"used_percent"`
+	ResetTime   time.Time `json:"reset_time"`      // zero if the provider doesn't report one
+	Error       string    `json:"error,omitempty"` // set when usage could not be read
 }
 
 // Summary represents a generated morning summary.
@@ -49,6 +109,7 @@ type Summary struct {
 	Content         string
 	ProjectCounts   map[string]int
 	CompletedTasks  []TaskResult
+	PartialTasks    []TaskResult // ran out of iterations but left a PR/MR
 	SkippedTasks    []TaskResult
 	FailedTasks     []TaskResult
 	BudgetStart     int
@@ -83,6 +144,7 @@ func (g *Generator) Generate(results *RunResults) (*Summary, error) {
 		BudgetRemaining: results.RemainingBudget,
 		ProjectCounts:   make(map[string]int),
 		CompletedTasks:  make([]TaskResult, 0),
+		PartialTasks:    make([]TaskResult, 0),
 		SkippedTasks:    make([]TaskResult, 0),
 		FailedTasks:     make([]TaskResult, 0),
 	}
@@ -93,6 +155,9 @@ func (g *Generator) Generate(results *RunResults) (*Summary, error) {
 		case "completed":
 			summary.CompletedTasks = append(summary.CompletedTasks, task)
 			summary.ProjectCounts[task.Project]++
+		case "partial":
+			summary.PartialTasks = append(summary.PartialTasks, task)
+			summary.ProjectCounts[task.Project]++
 		case "skipped":
 			summary.SkippedTasks = append(summary.SkippedTasks, task)
 		case "failed":
@@ -159,6 +224,15 @@ func (g *Generator) renderMarkdown(summary *Summary, results *RunResults) string
 		buf.WriteString("\n")
 	}
 
+	// Partially completed tasks section
+	if len(summary.PartialTasks) > 0 {
+		buf.WriteString("## Tasks Partially Completed\n")
+		for _, task := range summary.PartialTasks {
+			buf.WriteString(g.formatTaskLine(task))
+		}
+		buf.WriteString("\n")
+	}
+
 	// Failed tasks section
 	if len(summary.FailedTasks) > 0 {
 		buf.WriteString("## Tasks Failed\n")
@@ -221,16 +295,23 @@ func (g *Generator) generateWhatsNext(summary *Summary) []string {
 	var items []string
 
 	for _, task := range summary.CompletedTasks {
-		switch task.OutputType {
-		case "PR":
+		switch {
+		case IsReviewRequest(task.OutputType):
 			items = append(items, fmt.Sprintf("Review %s in %s", task.OutputRef, filepath.Base(task.Project)))
-		case "Report":
+		case task.OutputType == "Report":
 			items = append(items, fmt.Sprintf("Review %s report (see %s)", task.TaskType, task.OutputRef))
-		case "Analysis":
+		case task.OutputType == "Analysis":
 			items = append(items, fmt.Sprintf("Consider %s findings (see report)", task.Title))
 		}
 	}
 
+	// Partial work needs finishing by hand
+	for _, task := range summary.PartialTasks {
+		if task.OutputRef != "" {
+			items = append(items, fmt.Sprintf("Finish %s in %s (see %s)", task.Title, filepath.Base(task.Project), task.OutputRef))
+		}
+	}
+
 	// Add suggestions for skipped high-priority tasks
 	for _, task := range summary.SkippedTasks {
 		if strings.Contains(task.SkipReason, "budget") {
@@ -310,6 +391,25 @@ func (g *Generator) SendNotifications(summary *Summary) error {
 
 // sendEmail sends the summary via email.
 func (g *Generator) sendEmail(summary *Summary, to string) error {
+	addr, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid email address %q: %w", to, err)
+	}
+	subject := fmt.Sprintf("Nightshift Summary - %s", summary.Date.Format("2006-01-02"))
+	if err := SendEmail(addr, subject, "text/plain", summary.Content); err != nil {
+		return err
+	}
+
+	g.logger.Infof("email sent to %s", to)
+	return nil
+}
+
+// SendEmail sends body to to through the SMTP server configured by the
+// NIGHTSHIFT_SMTP_HOST, _PORT (default 587), _USER, _PASS and _FROM
+// environment variables. contentType is the body's MIME type, e.g.
+// "text/plain". The envelope recipient is to's bare address; the To
+// header keeps its display name.
+func SendEmail(to *mail.Address, subject, contentType, body string) error {
 	// Get SMTP settings from environment
 	smtpHost := os.Getenv("NIGHTSHIFT_SMTP_HOST")
 	smtpPort := os.Getenv("NIGHTSHIFT_SMTP_PORT")
@@ -327,11 +427,9 @@ func (g *Generator) sendEmail(summary *Summary, to string) error {
 		smtpFrom = "nightshift@localhost"
 	}
 
-	subject := fmt.Sprintf("Nightshift Summary - %s", summary.Date.Format("2006-01-02"))
-
 	// Build email message
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
-		smtpFrom, to, subject, summary.Content)
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: %s; charset=UTF-8\r\n\r\n%s",
+		smtpFrom, to.String(), subject, contentType, body)
 
 	var auth smtp.Auth
 	if smtpUser != "" && smtpPass != "" {
@@ -339,11 +437,9 @@ func (g *Generator) sendEmail(summary *Summary, to string) error {
 	}
 
 	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	if err := smtp.SendMail(addr, auth, smtpFrom, []string{to}, []byte(msg)); err != nil {
+	if err := smtp.SendMail(addr, auth, smtpFrom, []string{to.Address}, []byte(msg)); err != nil {
 		return fmt.Errorf("sending email: %w", err)
 	}
-
-	g.logger.Infof("email sent to %s", to)
 	return nil
 }
 
@@ -410,6 +506,14 @@ func (g *Generator) formatSlackSummary(summary *Summary) string {
 		buf.WriteString("\n")
 	}
 
+	if len(summary.PartialTasks) > 0 {
+		buf.WriteString(fmt.Sprintf("*Partially Completed:* %d\n", len(summary.PartialTasks)))
+		for _, task := range summary.PartialTasks {
+			buf.WriteString(fmt.Sprintf("  - %s\n", task.Title))
+		}
+		buf.WriteString("\n")
+	}
+
 	// Projects
 	if len(summary.ProjectCounts) > 0 {
 		buf.WriteString(fmt.Sprintf("*Projects:* %d processed\n", len(summary.ProjectCounts)))
@@ -425,12 +529,11 @@ func (g *Generator) formatSlackSummary(summary *Summary) string {
 
 // Helper functions
 
-// formatTokens formats a token count with commas for readability.
+// formatTokens formats a token count with commas for readability. Saved
+// reports always use this form so they parse back regardless of the
+// reporting.number_format used for display.
 func formatTokens(tokens int) string {
-	if tokens < 1000 {
-		return fmt.Sprintf("%d", tokens)
-	}
-	return fmt.Sprintf("%d,%03d", tokens/1000, tokens%1000)
+	return NumberGrouped.Tokens(tokens)
 }
 
 // formatDuration formats a duration in a human-readable way.
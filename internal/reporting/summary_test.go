@@ -1,6 +1,9 @@
 package reporting
 
 import (
+	"net"
+	"net/mail"
+	"net/textproto"
 	"os"
 	"path/filepath"
 	"strings"
@@ -245,7 +248,8 @@ func TestFormatTokens(t *testing.T) {
 		{1000, "1,000"},
 		{10000, "10,000"},
 		{100000, "100,000"},
-		{1000000, "1000,000"},
+		{1000000, "1,000,000"},
+		{1234567, "1,234,567"},
 	}
 
 	for _, tt := range tests {
@@ -256,6 +260,35 @@ func TestFormatTokens(t *testing.T) {
 	}
 }
 
+func TestNumberFormatTokens(t *testing.T) {
+	tests := []struct {
+		format NumberFormat
+		input  int
+		want   string
+	}{
+		{NumberCompact, 999, "999"},
+		{NumberCompact, 1234, "1.2k"},
+		{NumberCompact, 45000, "45k"},
+		{NumberCompact, 1234567, "1.2m"},
+		{NumberGrouped, 1234567, "1,234,567"},
+		{NumberGrouped, -1234, "-1,234"},
+		{NumberRaw, 1234567, "1234567"},
+		{"", 1234567, "1.2m"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.Tokens(tt.input); got != tt.want {
+			t.Errorf("%q.Tokens(%d) = %q, want %q", tt.format, tt.input, got, tt.want)
+		}
+	}
+
+	if f, err := ParseNumberFormat("Grouped"); err != nil || f != NumberGrouped {
+		t.Errorf("ParseNumberFormat(Grouped) = %q, %v", f, err)
+	}
+	if _, err := ParseNumberFormat("si"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
 		input time.Duration
@@ -474,3 +507,135 @@ func TestGenerateWithFailedTasks(t *testing.T) {
 		t.Error("Content missing Tasks Failed section")
 	}
 }
+
+func TestGenerateWithPartialTasks(t *testing.T) {
+	gen := NewGenerator(&config.Config{})
+
+	results := &RunResults{
+		Date: time.Now(),
+		Tasks: []TaskResult{
+			{Project: "/home/user/project", Title: "Lint fix", Status: "completed"},
+			{
+				Project:    "/home/user/project",
+				Title:      "Bug finder",
+				Status:     "partial",
+				OutputType: "PR",
+				OutputRef:  "https://github.com/acme/app/pull/42",
+				SkipReason: "max iterations (3) reached",
+			},
+		},
+	}
+
+	summary, err := gen.Generate(results)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(summary.PartialTasks) != 1 || len(summary.CompletedTasks) != 1 || len(summary.FailedTasks) != 0 {
+		t.Errorf("counts = %d completed, %d partial, %d failed; want 1, 1, 0",
+			len(summary.CompletedTasks), len(summary.PartialTasks), len(summary.FailedTasks))
+	}
+	for _, want := range []string{
+		"## Tasks Partially Completed",
+		"[PR https://github.com/acme/app/pull/42] Bug finder in project",
+		"Finish Bug finder in project (see https://github.com/acme/app/pull/42)",
+	} {
+		if !strings.Contains(summary.Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, summary.Content)
+		}
+	}
+}
+
+func TestIsReviewRequest(t *testing.T) {
+	tests := []struct {
+		outputType string
+		want       bool
+		label      string
+	}{
+		{"PR", true, "PR"},
+		{"pr", true, "PR"},
+		{"MR", true, "MR"},
+		{" merge-request ", true, "MR"},
+		{"Report", false, "PR"},
+		{"", false, "PR"},
+	}
+	for _, tt := range tests {
+		if got := IsReviewRequest(tt.outputType); got != tt.want {
+			t.Errorf("IsReviewRequest(%q) = %v, want %v", tt.outputType, got, tt.want)
+		}
+		if got := ReviewRequestLabel(tt.outputType); got != tt.label {
+			t.Errorf("ReviewRequestLabel(%q) = %q, want %q", tt.outputType, got, tt.label)
+		}
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	t.Setenv("NIGHTSHIFT_SMTP_HOST", "")
+	if err := SendEmail(&mail.Address{Address: "me@example.com"}, "s", "text/plain", "body"); err == nil || !strings.Contains(err.Error(), "NIGHTSHIFT_SMTP_HOST") {
+		t.Errorf("SendEmail without a host = %v, want NIGHTSHIFT_SMTP_HOST error", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	received := make(chan string, 1)
+	go serveOneSMTP(ln, received)
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	t.Setenv("NIGHTSHIFT_SMTP_HOST", host)
+	t.Setenv("NIGHTSHIFT_SMTP_PORT", port)
+	t.Setenv("NIGHTSHIFT_SMTP_FROM", "nightshift@example.com")
+
+	if err := SendEmail(&mail.Address{Name: "Night Owl", Address: "me@example.com"}, "Nightshift Report", "application/json", "{\"runs\": []}\n"); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	msg := <-received
+	for _, want := range []string{
+		"RCPT TO:<me@example.com>",
+		`To: "Night Owl" <me@example.com>`,
+		"Subject: Nightshift Report",
+		"Content-Type: application/json; charset=UTF-8",
+		`{"runs": []}`,
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+// serveOneSMTP accepts one connection, speaks just enough SMTP for
+// smtp.SendMail, and sends the RCPT command followed by the DATA it received.
+func serveOneSMTP(ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.Close() }()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost")
+	var rcpt string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch verb, _, _ := strings.Cut(line, " "); strings.ToUpper(verb) {
+		case "RCPT":
+			rcpt = line
+			_ = tp.PrintfLine("250 OK")
+		case "EHLO", "HELO", "MAIL":
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, _ := tp.ReadDotBytes()
+			received <- rcpt + "\n" + string(data)
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 unsupported")
+		}
+	}
+}
@@ -52,6 +52,9 @@ type Window struct {
 	Start    TimeOfDay // Start time (e.g., 22:00)
 	End      TimeOfDay // End time (e.g., 06:00)
 	Location *time.Location
+
+	// StartupDelay holds tasks back for this long after the window opens.
+	StartupDelay time.Duration
 }
 
 // TimeOfDay represents a time within a day (hour and minute).
@@ -174,8 +177,20 @@ func (s *Scheduler) SetWindow(cfg *config.WindowConfig) error {
 		}
 	}
 
+	var delay time.Duration
+	if cfg.StartupDelay != "" {
+		delay, err = time.ParseDuration(cfg.StartupDelay)
+		if err != nil || delay < 0 {
+			return fmt.Errorf("%w: startup_delay: must be a non-negative duration like 20m", ErrInvalidWindow)
+		}
+	}
+	w := &Window{Start: start, End: end, Location: loc, StartupDelay: delay}
+	if delay > 0 && delay >= w.Length() {
+		return fmt.Errorf("%w: startup_delay %s must be shorter than the window (%s)", ErrInvalidWindow, delay, w.Length())
+	}
+
 	s.mu.Lock()
-	s.window = &Window{Start: start, End: end, Location: loc}
+	s.window = w
 	s.location = loc
 	s.mu.Unlock()
 	return nil
@@ -305,8 +320,20 @@ func (s *Scheduler) NextRun() time.Time {
 	return s.nextRun
 }
 
+// cronSearchHorizon bounds how far ahead NextRuns looks for cron fire times
+// inside the window, so a cron that never lands in it cannot loop forever.
+const cronSearchHorizon = 366 * 24 * time.Hour
+
 // NextRuns returns the next N scheduled run times without starting the scheduler.
+// Cron fire times outside the window are skipped, since runJobs ignores them;
+// interval runs that would land outside it move to the next window start. Fewer
+// than n runs are returned if no cron time falls inside the window within a year.
 func (s *Scheduler) NextRuns(n int) ([]time.Time, error) {
+	return s.NextRunsFrom(time.Now(), n)
+}
+
+// NextRunsFrom is NextRuns as of now.
+func (s *Scheduler) NextRunsFrom(now time.Time, n int) ([]time.Time, error) {
 	if n <= 0 {
 		return []time.Time{}, nil
 	}
@@ -322,7 +349,7 @@ func (s *Scheduler) NextRuns(n int) ([]time.Time, error) {
 		return nil, ErrNoSchedule
 	}
 
-	now := time.Now().In(location)
+	now = now.In(location)
 	runs := make([]time.Time, 0, n)
 
 	if cronExpr != "" {
@@ -331,14 +358,12 @@ func (s *Scheduler) NextRuns(n int) ([]time.Time, error) {
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrInvalidCron, err)
 		}
-		current := now
-		for i := 0; i < n; i++ {
-			next := schedule.Next(current)
+		horizon := now.Add(cronSearchHorizon)
+		for next := schedule.Next(now); len(runs) < n && !next.IsZero() && next.Before(horizon); next = schedule.Next(next) {
 			if window != nil && !window.Contains(next) {
-				next = nextWindowStartForWindow(window, next)
+				continue
 			}
 			runs = append(runs, next)
-			current = next
 		}
 		return runs, nil
 	}
@@ -346,8 +371,8 @@ func (s *Scheduler) NextRuns(n int) ([]time.Time, error) {
 	current := now
 	for i := 0; i < n; i++ {
 		next := current.Add(interval)
-		if window != nil && !window.Contains(next) {
-			next = nextWindowStartForWindow(window, next)
+		if window != nil && !window.Eligible(next) {
+			next = window.EligibleAt(next)
 		}
 		runs = append(runs, next)
 		current = next
@@ -379,6 +404,9 @@ func (s *Scheduler) updateNextRunLocked() {
 	if s.window != nil && !s.window.Contains(s.nextRun) {
 		s.nextRun = s.nextWindowStartLocked(s.nextRun)
 	}
+	if s.interval > 0 && s.window != nil && !s.window.Eligible(s.nextRun) {
+		s.nextRun = s.window.EligibleAt(s.nextRun)
+	}
 }
 
 // IsInWindow checks if the given time is within the allowed execution window.
@@ -415,6 +443,75 @@ func (w *Window) Contains(t time.Time) bool {
 	return currentMins >= startMins && currentMins < endMins
 }
 
+// NextStart returns the next time the window opens after t.
+func (w *Window) NextStart(t time.Time) time.Time {
+	return nextWindowStartForWindow(w, t)
+}
+
+// LastStart returns the most recent time at or before t that the window
+// opened.
+func (w *Window) LastStart(t time.Time) time.Time {
+	t = t.In(w.Location)
+	start := time.Date(t.Year(), t.Month(), t.Day(), w.Start.Hour, w.Start.Minute, 0, 0, w.Location)
+	if start.After(t) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// Length returns how long the window stays open.
+func (w *Window) Length() time.Duration {
+	mins := w.End.Minutes() - w.Start.Minutes()
+	if mins <= 0 {
+		mins += 24 * 60
+	}
+	return time.Duration(mins) * time.Minute
+}
+
+// EligibleAt returns when tasks may start in the window that contains t,
+// or in the next one if t is outside the window: its opening plus the
+// startup delay. It is t itself once the delay has passed.
+func (w *Window) EligibleAt(t time.Time) time.Time {
+	var eligible time.Time
+	if w.Contains(t) {
+		eligible = w.LastStart(t).Add(w.StartupDelay)
+	} else {
+		eligible = w.NextStart(t).Add(w.StartupDelay)
+	}
+	if eligible.Before(t) {
+		return t
+	}
+	return eligible
+}
+
+// Eligible reports whether t is inside the window and past its startup
+// delay.
+func (w *Window) Eligible(t time.Time) bool {
+	return w.Contains(t) && !w.EligibleAt(t).After(t)
+}
+
+// NextEnd returns the next time the window closes after t.
+func (w *Window) NextEnd(t time.Time) time.Time {
+	t = t.In(w.Location)
+	end := time.Date(t.Year(), t.Month(), t.Day(), w.End.Hour, w.End.Minute, 0, 0, w.Location)
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// String returns the window as "HH:MM-HH:MM Zone".
+func (w *Window) String() string {
+	return fmt.Sprintf("%s-%s %s", w.Start, w.End, w.Location)
+}
+
+// Window returns the configured time window, or nil if none is set.
+func (s *Scheduler) Window() *Window {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.window
+}
+
 // nextWindowStartLocked returns the next time the window starts after t.
 // Must be called while holding the lock.
 func (s *Scheduler) nextWindowStartLocked(t time.Time) time.Time {
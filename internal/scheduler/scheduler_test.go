@@ -151,6 +151,138 @@ func TestWindow_Contains(t *testing.T) {
 	}
 }
 
+func TestWindow_NextStartEnd(t *testing.T) {
+	loc := time.UTC
+	overnight := Window{Start: TimeOfDay{22, 0}, End: TimeOfDay{6, 0}, Location: loc}
+	daytime := Window{Start: TimeOfDay{9, 0}, End: TimeOfDay{17, 0}, Location: loc}
+
+	tests := []struct {
+		name      string
+		window    Window
+		time      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "overnight - inside after midnight",
+			window:    overnight,
+			time:      time.Date(2024, 1, 2, 3, 0, 0, 0, loc),
+			wantStart: time.Date(2024, 1, 2, 22, 0, 0, 0, loc),
+			wantEnd:   time.Date(2024, 1, 2, 6, 0, 0, 0, loc),
+		},
+		{
+			name:      "overnight - inside before midnight",
+			window:    overnight,
+			time:      time.Date(2024, 1, 1, 23, 0, 0, 0, loc),
+			wantStart: time.Date(2024, 1, 2, 22, 0, 0, 0, loc),
+			wantEnd:   time.Date(2024, 1, 2, 6, 0, 0, 0, loc),
+		},
+		{
+			name:      "daytime - at end",
+			window:    daytime,
+			time:      time.Date(2024, 1, 1, 17, 0, 0, 0, loc),
+			wantStart: time.Date(2024, 1, 2, 9, 0, 0, 0, loc),
+			wantEnd:   time.Date(2024, 1, 2, 17, 0, 0, 0, loc),
+		},
+		{
+			name:      "daytime - before start",
+			window:    daytime,
+			time:      time.Date(2024, 1, 1, 8, 0, 0, 0, loc),
+			wantStart: time.Date(2024, 1, 1, 9, 0, 0, 0, loc),
+			wantEnd:   time.Date(2024, 1, 1, 17, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.NextStart(tt.time); !got.Equal(tt.wantStart) {
+				t.Errorf("NextStart(%v) = %v, want %v", tt.time, got, tt.wantStart)
+			}
+			if got := tt.window.NextEnd(tt.time); !got.Equal(tt.wantEnd) {
+				t.Errorf("NextEnd(%v) = %v, want %v", tt.time, got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestWindow_StartupDelay(t *testing.T) {
+	loc := time.UTC
+	w := Window{Start: TimeOfDay{22, 0}, End: TimeOfDay{6, 0}, Location: loc, StartupDelay: 20 * time.Minute}
+
+	tests := []struct {
+		name         string
+		time         time.Time
+		wantEligible bool
+		wantAt       time.Time
+	}{
+		{
+			name:   "before the window",
+			time:   time.Date(2024, 1, 1, 21, 0, 0, 0, loc),
+			wantAt: time.Date(2024, 1, 1, 22, 20, 0, 0, loc),
+		},
+		{
+			name:   "at window start",
+			time:   time.Date(2024, 1, 1, 22, 0, 0, 0, loc),
+			wantAt: time.Date(2024, 1, 1, 22, 20, 0, 0, loc),
+		},
+		{
+			name:   "inside the delay",
+			time:   time.Date(2024, 1, 1, 22, 19, 0, 0, loc),
+			wantAt: time.Date(2024, 1, 1, 22, 20, 0, 0, loc),
+		},
+		{
+			name:         "delay over",
+			time:         time.Date(2024, 1, 1, 22, 20, 0, 0, loc),
+			wantEligible: true,
+			wantAt:       time.Date(2024, 1, 1, 22, 20, 0, 0, loc),
+		},
+		{
+			name:         "after midnight",
+			time:         time.Date(2024, 1, 2, 3, 0, 0, 0, loc),
+			wantEligible: true,
+			wantAt:       time.Date(2024, 1, 2, 3, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.Eligible(tt.time); got != tt.wantEligible {
+				t.Errorf("Eligible(%v) = %v, want %v", tt.time, got, tt.wantEligible)
+			}
+			if got := w.EligibleAt(tt.time); !got.Equal(tt.wantAt) {
+				t.Errorf("EligibleAt(%v) = %v, want %v", tt.time, got, tt.wantAt)
+			}
+		})
+	}
+
+	if got := w.Length(); got != 8*time.Hour {
+		t.Errorf("Length() = %v, want 8h", got)
+	}
+}
+
+func TestScheduler_NextRuns_StartupDelay(t *testing.T) {
+	s := New()
+	if err := s.SetInterval(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetWindow(&config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "UTC", StartupDelay: "30m"}); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := s.NextRunsFrom(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 1, 1, 22, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC),
+	}
+	for i := range want {
+		if !runs[i].Equal(want[i]) {
+			t.Errorf("run %d = %v, want %v", i, runs[i], want[i])
+		}
+	}
+}
+
 func TestNewFromConfig_Cron(t *testing.T) {
 	cfg := &config.ScheduleConfig{
 		Cron: "0 2 * * *",
@@ -252,6 +384,14 @@ func TestNewFromConfig_InvalidWindow(t *testing.T) {
 			name:   "invalid timezone",
 			window: &config.WindowConfig{Start: "22:00", End: "06:00", Timezone: "Fake/Zone"},
 		},
+		{
+			name:   "invalid startup delay",
+			window: &config.WindowConfig{Start: "22:00", End: "06:00", StartupDelay: "soon"},
+		},
+		{
+			name:   "startup delay as long as the window",
+			window: &config.WindowConfig{Start: "22:00", End: "06:00", StartupDelay: "8h"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -419,6 +559,69 @@ func TestScheduler_NextRun_Interval(t *testing.T) {
 	}
 }
 
+func TestScheduler_NextRuns(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, loc) // Monday noon
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 3, day, hour, min, 0, 0, loc)
+	}
+	window := &config.WindowConfig{Start: "22:00", End: "02:00", Timezone: "UTC"}
+
+	tests := []struct {
+		name string
+		cfg  config.ScheduleConfig
+		n    int
+		want []time.Time
+	}{
+		{
+			name: "cron",
+			cfg:  config.ScheduleConfig{Cron: "0 2 * * *"},
+			n:    3,
+			want: []time.Time{at(3, 2, 0), at(4, 2, 0), at(5, 2, 0)},
+		},
+		{
+			name: "cron skips times outside window",
+			cfg:  config.ScheduleConfig{Cron: "0 */3 * * *", Window: window},
+			n:    4,
+			want: []time.Time{at(3, 0, 0), at(4, 0, 0), at(5, 0, 0), at(6, 0, 0)},
+		},
+		{
+			name: "interval spans window openings",
+			cfg:  config.ScheduleConfig{Interval: "90m", Window: window},
+			n:    5,
+			want: []time.Time{at(2, 22, 0), at(2, 23, 30), at(3, 1, 0), at(3, 22, 0), at(3, 23, 30)},
+		},
+		{
+			name: "cron never inside window",
+			cfg:  config.ScheduleConfig{Cron: "0 12 * * *", Window: window},
+			n:    2,
+			want: []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewFromConfig(&tt.cfg)
+			if err != nil {
+				t.Fatalf("NewFromConfig: %v", err)
+			}
+			s.location = loc // cron without a window runs in local time
+			got, err := s.NextRunsFrom(now, tt.n)
+			if err != nil {
+				t.Fatalf("NextRunsFrom: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d runs %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("run %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
 func TestScheduler_IsInWindow(t *testing.T) {
 	s := New()
 	_ = s.SetCron("0 2 * * *")
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
 	"github.com/marcus/nightshift/internal/tmux"
 )
 
@@ -71,6 +72,7 @@ type Collector struct {
 	copilot      CopilotUsage
 	scraper      UsageScraper
 	weekStartDay time.Weekday
+	usageTimeout time.Duration // bound on each provider's local usage read; 0 = none
 }
 
 // NewCollector creates a snapshot collector.
@@ -88,7 +90,29 @@ func NewCollector(database *db.DB, claude ClaudeUsage, codex CodexUsage, copilot
 	}
 }
 
-// TakeSnapshot collects and stores a snapshot for the provider.
+// SetUsageTimeout bounds each provider's local usage read in TakeSnapshot
+// (providers.usage_timeout). 0 waits as long as the read takes.
+func (c *Collector) SetUsageTimeout(d time.Duration) {
+	c.usageTimeout = d
+}
+
+// localTotals reads a provider's weekly and daily token totals, giving up
+// after the usage timeout.
+func (c *Collector) localTotals(ctx context.Context, provider string, read func() (int64, int64, error)) (int64, int64, error) {
+	type totals struct{ weekly, daily int64 }
+	t, err := providers.ReadWithTimeout(ctx, provider, c.usageTimeout, func() (totals, error) {
+		weekly, daily, err := read()
+		return totals{weekly, daily}, err
+	})
+	if errors.Is(err, providers.ErrUsageTimeout) {
+		return 0, 0, fmt.Errorf("%s local usage: %w", provider, err)
+	}
+	return t.weekly, t.daily, err
+}
+
+// TakeSnapshot collects and stores a snapshot for the provider. A local
+// usage read that exceeds the usage timeout fails the snapshot with an
+// error wrapping providers.ErrUsageTimeout.
 func (c *Collector) TakeSnapshot(ctx context.Context, provider string) (Snapshot, error) {
 	if c == nil || c.db == nil {
 		return Snapshot{}, errors.New("db is nil")
@@ -108,11 +132,14 @@ func (c *Collector) TakeSnapshot(ctx context.Context, provider string) (Snapshot
 		if c.claude == nil {
 			return Snapshot{}, errors.New("claude provider is nil")
 		}
-		localWeekly, err = c.claude.GetWeeklyUsage()
-		if err != nil {
-			return Snapshot{}, err
-		}
-		localDaily, err = c.claude.GetTodayUsage()
+		localWeekly, localDaily, err = c.localTotals(ctx, provider, func() (int64, int64, error) {
+			weekly, err := c.claude.GetWeeklyUsage()
+			if err != nil {
+				return 0, 0, err
+			}
+			daily, err := c.claude.GetTodayUsage()
+			return weekly, daily, err
+		})
 		if err != nil {
 			return Snapshot{}, err
 		}
@@ -133,7 +160,7 @@ func (c *Collector) TakeSnapshot(ctx context.Context, provider string) (Snapshot
 		if c.codex == nil {
 			return Snapshot{}, errors.New("codex provider is nil")
 		}
-		localWeekly, localDaily, err = codexTokenTotals(c.codex)
+		localWeekly, localDaily, err = c.localTotals(ctx, provider, func() (int64, int64, error) { return codexTokenTotals(c.codex) })
 		if err != nil {
 			return Snapshot{}, err
 		}
@@ -154,7 +181,7 @@ func (c *Collector) TakeSnapshot(ctx context.Context, provider string) (Snapshot
 		if c.copilot == nil {
 			return Snapshot{}, errors.New("copilot provider is nil")
 		}
-		localWeekly, localDaily, err = copilotTokenTotals(c.copilot)
+		localWeekly, localDaily, err = c.localTotals(ctx, provider, func() (int64, int64, error) { return copilotTokenTotals(c.copilot) })
 		if err != nil {
 			return Snapshot{}, err
 		}
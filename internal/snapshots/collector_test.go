@@ -2,11 +2,13 @@ package snapshots
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/marcus/nightshift/internal/db"
+	"github.com/marcus/nightshift/internal/providers"
 	"github.com/marcus/nightshift/internal/tmux"
 )
 
@@ -311,6 +313,49 @@ func TestTakeSnapshotEmptyResetTimes(t *testing.T) {
 	}
 }
 
+// slowClaude stands in for a session directory on a stale mount.
+type slowClaude struct {
+	fakeClaude
+	delay time.Duration
+}
+
+func (f slowClaude) GetWeeklyUsage() (int64, error) {
+	time.Sleep(f.delay)
+	return f.fakeClaude.GetWeeklyUsage()
+}
+
+func TestTakeSnapshotUsageTimeout(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dbPath := filepath.Join(home, "nightshift.db")
+	database, err := db.Open(dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	slow := slowClaude{fakeClaude: fakeClaude{weekly: 700, daily: 120}, delay: time.Second}
+	collector := NewCollector(database, slow, fakeCodex{}, nil, nil, time.Monday)
+	collector.SetUsageTimeout(20 * time.Millisecond)
+
+	start := time.Now()
+	_, err = collector.TakeSnapshot(context.Background(), "claude")
+	if !errors.Is(err, providers.ErrUsageTimeout) {
+		t.Fatalf("err = %v, want ErrUsageTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("TakeSnapshot blocked for %v", elapsed)
+	}
+	if latest, _ := collector.GetLatest("claude", 1); len(latest) != 0 {
+		t.Fatalf("stored %d snapshots for a timed-out provider", len(latest))
+	}
+
+	if _, err := collector.TakeSnapshot(context.Background(), "codex"); err != nil {
+		t.Fatalf("codex snapshot: %v", err)
+	}
+}
+
 func TestPruneSnapshots(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
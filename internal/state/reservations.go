@@ -0,0 +1,105 @@
+package state
+
+import (
+	"fmt"
+	"time"
+)
+
+// BudgetReservation holds back tokens from a provider's allowance until it
+// expires (nightshift budget reserve).
+type BudgetReservation struct {
+	ID        int64     `json:"id"`
+	Provider  string    `json:"provider"`
+	Tokens    int64     `json:"tokens"`
+	Until     time.Time `json:"until"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddReservation records a reservation of tokens for provider until the
+// given time. Expired reservations are pruned on the way.
+func (s *State) AddReservation(provider string, tokens int64, until time.Time) (BudgetReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if _, err := s.db.SQL().Exec(`DELETE FROM budget_reservations WHERE until <= ?`, now); err != nil {
+		return BudgetReservation{}, fmt.Errorf("prune reservations: %w", err)
+	}
+	res, err := s.db.SQL().Exec(
+		`INSERT INTO budget_reservations (provider, tokens, until, created_at) VALUES (?, ?, ?, ?)`,
+		provider, tokens, until, now,
+	)
+	if err != nil {
+		return BudgetReservation{}, fmt.Errorf("insert reservation: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return BudgetReservation{}, fmt.Errorf("reservation id: %w", err)
+	}
+	return BudgetReservation{ID: id, Provider: provider, Tokens: tokens, Until: until, CreatedAt: now}, nil
+}
+
+// ActiveReservations returns the reservations that have not expired at now,
+// for provider or for every provider when it is empty, soonest expiry first.
+func (s *State) ActiveReservations(provider string, now time.Time) ([]BudgetReservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.SQL().Query(`SELECT id, provider, tokens, until, created_at FROM budget_reservations ORDER BY until, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query reservations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []BudgetReservation
+	for rows.Next() {
+		var r BudgetReservation
+		if err := rows.Scan(&r.ID, &r.Provider, &r.Tokens, &r.Until, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reservation: %w", err)
+		}
+		if !r.Until.After(now) || (provider != "" && r.Provider != provider) {
+			continue
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reservation rows: %w", err)
+	}
+	return out, nil
+}
+
+// ReservedTokens sums provider's active reservations at now. It satisfies
+// budget.ReservationSource.
+func (s *State) ReservedTokens(provider string, now time.Time) (int64, error) {
+	active, err := s.ActiveReservations(provider, now)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, r := range active {
+		total += r.Tokens
+	}
+	return total, nil
+}
+
+// ClearReservations deletes the reservations for provider, or every
+// reservation when it is empty, and returns how many were removed.
+func (s *State) ClearReservations(provider string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query, args := `DELETE FROM budget_reservations`, []any{}
+	if provider != "" {
+		query += ` WHERE provider = ?`
+		args = append(args, provider)
+	}
+	res, err := s.db.SQL().Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("clear reservations: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("clear reservations: %w", err)
+	}
+	return int(n), nil
+}
@@ -36,6 +36,18 @@ type RunRecord struct {
 	Status     string    `json:"status"` // success, failed, partial
 	Error      string    `json:"error,omitempty"`
 	Branch     string    `json:"branch,omitempty"`
+	Label      string    `json:"label,omitempty"` // run --label
+}
+
+// TaskRun is one task attempted during a run.
+type TaskRun struct {
+	Project    string    `json:"project"`
+	TaskType   string    `json:"task_type"`
+	Provider   string    `json:"provider,omitempty"`
+	Status     string    `json:"status"` // completed, partial, failed, abandoned
+	TokensUsed int       `json:"tokens_used"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
 }
 
 // ProjectState tracks state for a single project.
@@ -99,6 +111,46 @@ func (s *State) RecordTaskRun(projectPath, taskType string) {
 	}
 }
 
+// RecordTaskResult stores the outcome of a task run on a project (the
+// orchestrator status: completed, partial, failed or abandoned), replacing
+// the previous one.
+func (s *State) RecordTaskResult(projectPath, taskType, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projectPath = normalizePath(projectPath)
+	_, err := s.db.SQL().Exec(
+		`INSERT INTO task_results (project_path, task_type, status, finished_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(project_path, task_type) DO UPDATE SET status = excluded.status, finished_at = excluded.finished_at`,
+		projectPath,
+		taskType,
+		status,
+		time.Now(),
+	)
+	if err != nil {
+		log.Printf("state: record task result: %v", err)
+	}
+}
+
+// LastTaskResult returns the most recent outcome recorded for a task type on
+// a project and when it finished, or "" and the zero time if none.
+func (s *State) LastTaskResult(projectPath, taskType string) (string, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projectPath = normalizePath(projectPath)
+	row := s.db.SQL().QueryRow(`SELECT status, finished_at FROM task_results WHERE project_path = ? AND task_type = ?`, projectPath, taskType)
+	var status string
+	var finishedAt time.Time
+	if err := row.Scan(&status, &finishedAt); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("state: query task result: %v", err)
+		}
+		return "", time.Time{}
+	}
+	return status, finishedAt
+}
+
 // WasProcessedToday returns true if the project was already processed today.
 func (s *State) WasProcessedToday(projectPath string) bool {
 	s.mu.RLock()
@@ -119,6 +171,24 @@ func (s *State) WasProcessedToday(projectPath string) bool {
 	return isSameDay(lastRun.Time, time.Now())
 }
 
+// WasProcessedSince returns true if the project was last processed at or
+// after since.
+func (s *State) WasProcessedSince(projectPath string, since time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projectPath = normalizePath(projectPath)
+	row := s.db.SQL().QueryRow(`SELECT last_run FROM projects WHERE path = ?`, projectPath)
+	var lastRun sql.NullTime
+	if err := row.Scan(&lastRun); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("state: query last_run: %v", err)
+		}
+		return false
+	}
+	return lastRun.Valid && !lastRun.Time.Before(since)
+}
+
 // LastProjectRun returns when a project was last processed.
 func (s *State) LastProjectRun(projectPath string) time.Time {
 	s.mu.RLock()
@@ -156,6 +226,62 @@ func (s *State) LastTaskRun(projectPath, taskType string) time.Time {
 	return lastRun
 }
 
+// CooldownReset describes a task_history record cleared by ResetCooldowns.
+type CooldownReset struct {
+	TaskType string
+	LastRun  time.Time
+}
+
+// ResetCooldowns clears the last-run records for taskTypes on a project so
+// their cooldowns no longer apply. With no taskTypes, every task on the
+// project is cleared. It returns the records removed.
+func (s *State) ResetCooldowns(projectPath string, taskTypes ...string) ([]CooldownReset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projectPath = normalizePath(projectPath)
+	want := make(map[string]bool, len(taskTypes))
+	for _, t := range taskTypes {
+		want[t] = true
+	}
+
+	tx, err := s.db.SQL().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query(`SELECT task_type, last_run FROM task_history WHERE project_path = ? ORDER BY task_type`, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("query task history: %w", err)
+	}
+	var reset []CooldownReset
+	for rows.Next() {
+		var r CooldownReset
+		if err := rows.Scan(&r.TaskType, &r.LastRun); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan task history: %w", err)
+		}
+		if len(want) == 0 || want[r.TaskType] {
+			reset = append(reset, r)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("task history rows: %w", err)
+	}
+
+	for _, r := range reset {
+		if _, err := tx.Exec(`DELETE FROM task_history WHERE project_path = ? AND task_type = ?`, projectPath, r.TaskType); err != nil {
+			return nil, fmt.Errorf("delete task history: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return reset, nil
+}
+
 // DaysSinceLastRun returns days since a task type was last run for a project.
 // Returns -1 if the task has never run (treated as maximally stale).
 func (s *State) DaysSinceLastRun(projectPath, taskType string) int {
@@ -423,8 +549,8 @@ func (s *State) AddRunRecord(record RunRecord) {
 	}
 
 	_, err = tx.Exec(
-		`INSERT INTO run_history (id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO run_history (id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch, label)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		record.ID,
 		record.StartTime,
 		endTime,
@@ -435,6 +561,7 @@ func (s *State) AddRunRecord(record RunRecord) {
 		record.Status,
 		record.Error,
 		record.Branch,
+		record.Label,
 	)
 	if err != nil {
 		_ = tx.Rollback()
@@ -464,7 +591,7 @@ func (s *State) GetRunHistory(n int) []RunRecord {
 	}
 
 	rows, err := s.db.SQL().Query(
-		`SELECT id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch
+		`SELECT id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch, label
 		 FROM run_history
 		 ORDER BY start_time DESC
 		 LIMIT ?`,
@@ -481,7 +608,7 @@ func (s *State) GetRunHistory(n int) []RunRecord {
 		var record RunRecord
 		var tasksJSON string
 		var endTime sql.NullTime
-		if err := rows.Scan(&record.ID, &record.StartTime, &endTime, &record.Provider, &record.Project, &tasksJSON, &record.TokensUsed, &record.Status, &record.Error, &record.Branch); err != nil {
+		if err := rows.Scan(&record.ID, &record.StartTime, &endTime, &record.Provider, &record.Project, &tasksJSON, &record.TokensUsed, &record.Status, &record.Error, &record.Branch, &record.Label); err != nil {
 			log.Printf("state: scan run history: %v", err)
 			return result
 		}
@@ -512,7 +639,7 @@ func (s *State) GetTodayRuns() []RunRecord {
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	rows, err := s.db.SQL().Query(
-		`SELECT id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch
+		`SELECT id, start_time, end_time, provider, project, tasks, tokens_used, status, error, branch, label
 		 FROM run_history
 		 WHERE start_time >= ? AND start_time < ?
 		 ORDER BY start_time DESC`,
@@ -530,7 +657,7 @@ func (s *State) GetTodayRuns() []RunRecord {
 		var record RunRecord
 		var tasksJSON string
 		var endTime sql.NullTime
-		if err := rows.Scan(&record.ID, &record.StartTime, &endTime, &record.Provider, &record.Project, &tasksJSON, &record.TokensUsed, &record.Status, &record.Error, &record.Branch); err != nil {
+		if err := rows.Scan(&record.ID, &record.StartTime, &endTime, &record.Provider, &record.Project, &tasksJSON, &record.TokensUsed, &record.Status, &record.Error, &record.Branch, &record.Label); err != nil {
 			log.Printf("state: scan today runs: %v", err)
 			return result
 		}
@@ -551,6 +678,90 @@ func (s *State) GetTodayRuns() []RunRecord {
 	return result
 }
 
+// AddTaskRun adds a task attempt to the task ledger.
+func (s *State) AddTaskRun(run TaskRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if run.EndTime.IsZero() {
+		run.EndTime = time.Now()
+	}
+	if run.StartTime.IsZero() {
+		run.StartTime = run.EndTime
+	}
+
+	tx, err := s.db.SQL().Begin()
+	if err != nil {
+		log.Printf("state: begin task run insert: %v", err)
+		return
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO task_runs (project, task_type, provider, status, tokens_used, start_time, end_time)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.Project,
+		run.TaskType,
+		run.Provider,
+		run.Status,
+		run.TokensUsed,
+		run.StartTime,
+		run.EndTime,
+	)
+	if err != nil {
+		_ = tx.Rollback()
+		log.Printf("state: insert task_runs: %v", err)
+		return
+	}
+
+	if _, err := tx.Exec(`DELETE FROM task_runs WHERE id NOT IN (SELECT id FROM task_runs ORDER BY start_time DESC LIMIT 1000)`); err != nil {
+		_ = tx.Rollback()
+		log.Printf("state: prune task_runs: %v", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("state: commit task_runs: %v", err)
+	}
+}
+
+// GetTodayTaskRuns returns the task attempts started today, oldest first.
+func (s *State) GetTodayTaskRuns() []TaskRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	rows, err := s.db.SQL().Query(
+		`SELECT project, task_type, provider, status, tokens_used, start_time, end_time
+		 FROM task_runs
+		 WHERE start_time >= ? AND start_time < ?
+		 ORDER BY start_time, id`,
+		startOfDay,
+		endOfDay,
+	)
+	if err != nil {
+		log.Printf("state: get today task runs: %v", err)
+		return nil
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := make([]TaskRun, 0)
+	for rows.Next() {
+		var run TaskRun
+		if err := rows.Scan(&run.Project, &run.TaskType, &run.Provider, &run.Status, &run.TokensUsed, &run.StartTime, &run.EndTime); err != nil {
+			log.Printf("state: scan today task runs: %v", err)
+			return result
+		}
+		result = append(result, run)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("state: today task runs rows: %v", err)
+	}
+	return result
+}
+
 // TodaySummary returns a summary of today's activity.
 type TodaySummary struct {
 	TotalRuns      int
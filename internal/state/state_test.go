@@ -33,6 +33,16 @@ func TestProjectRunTracking(t *testing.T) {
 	if time.Since(lastRun) > time.Second {
 		t.Errorf("LastProjectRun() = %v, expected recent time", lastRun)
 	}
+
+	if !s.WasProcessedSince(project, time.Now().Add(-time.Hour)) {
+		t.Error("WasProcessedSince(1h ago) = false after recording run, want true")
+	}
+	if s.WasProcessedSince(project, time.Now().Add(time.Hour)) {
+		t.Error("WasProcessedSince(future) = true, want false")
+	}
+	if s.WasProcessedSince("/never/run", time.Time{}) {
+		t.Error("WasProcessedSince() = true for new project, want false")
+	}
 }
 
 func TestTaskRunTracking(t *testing.T) {
@@ -54,6 +64,43 @@ func TestTaskRunTracking(t *testing.T) {
 	}
 }
 
+func TestResetCooldowns(t *testing.T) {
+	s := newTestState(t)
+
+	project := "/path/to/project"
+	other := "/path/to/other"
+	s.RecordTaskRun(project, "lint")
+	s.RecordTaskRun(project, "docs")
+	s.RecordTaskRun(other, "lint")
+
+	reset, err := s.ResetCooldowns(project, "lint")
+	if err != nil {
+		t.Fatalf("ResetCooldowns(lint): %v", err)
+	}
+	if len(reset) != 1 || reset[0].TaskType != "lint" || reset[0].LastRun.IsZero() {
+		t.Errorf("ResetCooldowns(lint) = %+v, want one lint record", reset)
+	}
+	if !s.LastTaskRun(project, "lint").IsZero() {
+		t.Error("lint last run still recorded after reset")
+	}
+	if s.LastTaskRun(project, "docs").IsZero() || s.LastTaskRun(other, "lint").IsZero() {
+		t.Error("reset cleared records for other tasks or projects")
+	}
+
+	reset, err = s.ResetCooldowns(project)
+	if err != nil {
+		t.Fatalf("ResetCooldowns(all): %v", err)
+	}
+	if len(reset) != 1 || reset[0].TaskType != "docs" {
+		t.Errorf("ResetCooldowns(all) = %+v, want the docs record", reset)
+	}
+
+	reset, err = s.ResetCooldowns(project, "lint")
+	if err != nil || len(reset) != 0 {
+		t.Errorf("ResetCooldowns() on cleared task = %+v, %v; want none", reset, err)
+	}
+}
+
 func TestDaysSinceLastRun(t *testing.T) {
 	s := newTestState(t)
 
@@ -373,6 +420,118 @@ func TestRunHistoryBranchEmpty(t *testing.T) {
 	}
 }
 
+func TestRunHistoryLabelPersisted(t *testing.T) {
+	s := newTestState(t)
+
+	start := time.Now().Add(-2 * time.Minute)
+	s.AddRunRecord(RunRecord{
+		ID:        "run-label-test",
+		StartTime: start,
+		EndTime:   start.Add(time.Minute),
+		Project:   "/tmp/project",
+		Tasks:     []string{"lint-fix"},
+		Status:    "success",
+		Label:     "experiment",
+	})
+
+	runs := s.GetRunHistory(1)
+	if len(runs) != 1 {
+		t.Fatalf("GetRunHistory() returned %d runs, want 1", len(runs))
+	}
+	if runs[0].Label != "experiment" {
+		t.Fatalf("run label = %q, want %q", runs[0].Label, "experiment")
+	}
+}
+
+func TestBudgetReservations(t *testing.T) {
+	s := newTestState(t)
+	now := time.Now()
+
+	if _, err := s.AddReservation("claude", 100000, now.Add(12*time.Hour)); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+	if _, err := s.AddReservation("claude", 50000, now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+	if _, err := s.AddReservation("codex", 20000, now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddReservation: %v", err)
+	}
+
+	if held, err := s.ReservedTokens("claude", now); err != nil || held != 150000 {
+		t.Fatalf("ReservedTokens(claude) = %d, %v; want 150000", held, err)
+	}
+	// After the short reservations expire only the long one remains.
+	if held, _ := s.ReservedTokens("claude", now.Add(2*time.Hour)); held != 100000 {
+		t.Errorf("ReservedTokens(claude) after expiry = %d, want 100000", held)
+	}
+	active, err := s.ActiveReservations("", now)
+	if err != nil || len(active) != 3 || active[2].Provider != "claude" {
+		t.Fatalf("ActiveReservations = %+v, %v; want 3 ordered by expiry", active, err)
+	}
+
+	if n, err := s.ClearReservations("codex"); err != nil || n != 1 {
+		t.Fatalf("ClearReservations(codex) = %d, %v; want 1", n, err)
+	}
+	if n, err := s.ClearReservations(""); err != nil || n != 2 {
+		t.Fatalf("ClearReservations() = %d, %v; want 2", n, err)
+	}
+	if held, _ := s.ReservedTokens("claude", now); held != 0 {
+		t.Errorf("ReservedTokens after clear = %d, want 0", held)
+	}
+}
+
+func TestTaskResults(t *testing.T) {
+	s := newTestState(t)
+	project := "/test/project"
+
+	if status, at := s.LastTaskResult(project, "lint-fix"); status != "" || !at.IsZero() {
+		t.Fatalf("LastTaskResult before any run = %q, %v; want empty", status, at)
+	}
+
+	s.RecordTaskResult(project, "lint-fix", "failed")
+	s.RecordTaskResult(project, "lint-fix", "completed")
+	s.RecordTaskResult(project, "docs-backfill", "abandoned")
+
+	status, at := s.LastTaskResult(project, "lint-fix")
+	if status != "completed" {
+		t.Errorf("LastTaskResult(lint-fix) = %q, want completed", status)
+	}
+	if time.Since(at) > time.Minute {
+		t.Errorf("LastTaskResult(lint-fix) finished at %v, want recent", at)
+	}
+	if status, _ := s.LastTaskResult(project, "docs-backfill"); status != "abandoned" {
+		t.Errorf("LastTaskResult(docs-backfill) = %q, want abandoned", status)
+	}
+	if status, _ := s.LastTaskResult("/other/project", "lint-fix"); status != "" {
+		t.Errorf("LastTaskResult on another project = %q, want empty", status)
+	}
+}
+
+func TestTodayTaskRuns(t *testing.T) {
+	s := newTestState(t)
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if runs := s.GetTodayTaskRuns(); len(runs) != 0 {
+		t.Fatalf("GetTodayTaskRuns before any run = %+v, want none", runs)
+	}
+
+	s.AddTaskRun(TaskRun{Project: "/src/web", TaskType: "bug-finder", Provider: "codex", Status: "failed", StartTime: day.Add(3 * time.Hour), EndTime: day.Add(3*time.Hour + 5*time.Minute)})
+	s.AddTaskRun(TaskRun{Project: "/src/web", TaskType: "lint-fix", Provider: "claude", Status: "completed", TokensUsed: 42000, StartTime: day.Add(2 * time.Hour), EndTime: day.Add(2*time.Hour + 10*time.Minute)})
+	s.AddTaskRun(TaskRun{Project: "/src/web", TaskType: "lint-fix", Status: "completed", StartTime: day.Add(-time.Hour), EndTime: day.Add(-50 * time.Minute)})
+
+	runs := s.GetTodayTaskRuns()
+	if len(runs) != 2 {
+		t.Fatalf("GetTodayTaskRuns = %+v, want 2 runs from today", runs)
+	}
+	if runs[0].TaskType != "lint-fix" || runs[0].Provider != "claude" || runs[0].TokensUsed != 42000 || runs[0].Status != "completed" {
+		t.Errorf("first task run = %+v, want lint-fix via claude", runs[0])
+	}
+	if runs[1].TaskType != "bug-finder" || runs[1].Status != "failed" || !runs[1].EndTime.Equal(day.Add(3*time.Hour+5*time.Minute)) {
+		t.Errorf("second task run = %+v, want failed bug-finder", runs[1])
+	}
+}
+
 func newTestState(t *testing.T) *State {
 	t.Helper()
 
@@ -64,6 +64,7 @@ type StatsResult struct {
 
 	// Task outcomes
 	TasksCompleted int     `json:"tasks_completed"`
+	TasksPartial   int     `json:"tasks_partial"`
 	TasksFailed    int     `json:"tasks_failed"`
 	TasksSkipped   int     `json:"tasks_skipped"`
 	SuccessRate    float64 `json:"success_rate"`
@@ -166,10 +167,8 @@ func (s *Stats) Compute() (*StatsResult, error) {
 	}
 
 	// Success rate
-	totalTasks := result.TasksCompleted + result.TasksFailed + result.TasksSkipped
-	if totalTasks > 0 {
-		result.SuccessRate = float64(result.TasksCompleted) / float64(totalTasks) * 100
-	}
+	totalTasks := result.TasksCompleted + result.TasksPartial + result.TasksFailed + result.TasksSkipped
+	result.SuccessRate = reporting.SuccessRate(result.TasksCompleted, result.TasksPartial, totalTasks)
 
 	return result, nil
 }
@@ -252,6 +251,8 @@ func (s *Stats) computeFromReports(result *StatsResult, reports []*reporting.Run
 			switch task.Status {
 			case "completed":
 				result.TasksCompleted++
+			case "partial":
+				result.TasksPartial++
 			case "failed":
 				result.TasksFailed++
 			case "skipped":
@@ -264,7 +265,7 @@ func (s *Stats) computeFromReports(result *StatsResult, reports []*reporting.Run
 			}
 
 			// PR detection
-			if strings.EqualFold(task.OutputType, "pr") && task.OutputRef != "" {
+			if reporting.IsReviewRequest(task.OutputType) && task.OutputRef != "" {
 				result.PRsCreated++
 				if strings.HasPrefix(task.OutputRef, "http") {
 					prURLSet[task.OutputRef] = struct{}{}
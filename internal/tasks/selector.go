@@ -14,9 +14,15 @@ import (
 type Selector struct {
 	cfg                *config.Config
 	state              *state.State
-	contextMentions    map[string]bool // Tasks mentioned in claude.md/agents.md
-	taskSources        map[string]bool // Tasks from td/github issues
-	simulatedCooldowns map[string]bool // task:project keys simulated as on cooldown (for preview)
+	contextMentions    map[string]bool       // Tasks mentioned in claude.md/agents.md
+	taskSources        map[string]bool       // Tasks from td/github issues
+	simulatedCooldowns map[string]bool       // task:project keys simulated as on cooldown (for preview)
+	reportedRuns       map[string]time.Time  // task:project completions found in run reports
+	rng                *rand.Rand            // Optional seeded RNG for SelectRandom (nil = time-seeded global)
+	categories         map[TaskCategory]bool // Allowed categories (nil = all)
+	themeCategories    map[TaskCategory]bool // Day theme categories (see SetTheme)
+	themeTypes         map[TaskType]bool     // Day theme task types (see SetTheme)
+	maxTokensPerTask   int64                 // Per-task reservation ceiling; 0 = cost tier max
 }
 
 // NewSelector creates a new task selector.
@@ -54,52 +60,151 @@ func (s *Selector) SetTaskSources(sources []string) {
 	}
 }
 
+// SetSeed makes random selection deterministic: the same seed yields the
+// same sequence of picks for the same eligible pool. Intended for testing
+// and reproducing --random-task runs.
+func (s *Selector) SetSeed(seed uint64) {
+	s.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// SetCategories restricts selection to tasks in the given categories.
+// An empty list allows every category.
+func (s *Selector) SetCategories(cats []TaskCategory) {
+	if len(cats) == 0 {
+		s.categories = nil
+		return
+	}
+	s.categories = make(map[TaskCategory]bool, len(cats))
+	for _, c := range cats {
+		s.categories[c] = true
+	}
+}
+
+// SetTheme restricts selection to the day's theme: tasks in one of cats or
+// whose type is one of types. Both empty clears the theme. The theme
+// applies on top of SetCategories.
+func (s *Selector) SetTheme(cats []TaskCategory, types []TaskType) {
+	s.themeCategories, s.themeTypes = nil, nil
+	if len(cats) == 0 && len(types) == 0 {
+		return
+	}
+	s.themeCategories = make(map[TaskCategory]bool, len(cats))
+	for _, c := range cats {
+		s.themeCategories[c] = true
+	}
+	s.themeTypes = make(map[TaskType]bool, len(types))
+	for _, t := range types {
+		s.themeTypes[t] = true
+	}
+}
+
+// inTheme reports whether t is allowed by the day's theme.
+func (s *Selector) inTheme(t TaskDefinition) bool {
+	if s.themeCategories == nil {
+		return true
+	}
+	return s.themeCategories[t.Category] || s.themeTypes[t.Type]
+}
+
+// SetMaxTokensPerTask caps the tokens reserved for each task below its cost
+// tier max, so budget checks plan for at most n per task. 0 clears the cap.
+func (s *Selector) SetMaxTokensPerTask(n int64) {
+	s.maxTokensPerTask = n
+}
+
+// ReservedTokens returns the tokens budget checks reserve for t: the top of
+// its cost tier, lowered to the per-task cap when one is set.
+func (s *Selector) ReservedTokens(t TaskDefinition) int64 {
+	return t.CappedMaxTokens(s.maxTokensPerTask)
+}
+
+// intN returns a random int in [0, n) using the seeded RNG if set.
+func (s *Selector) intN(n int) int {
+	if s.rng != nil {
+		return s.rng.IntN(n)
+	}
+	return rand.IntN(n)
+}
+
+// ScoreBreakdown is a task's score split into its components.
+type ScoreBreakdown struct {
+	Base      float64 // Configured priority
+	Staleness float64 // Days since last run * 0.1 (3 if never run)
+	Context   float64 // +2 if mentioned in claude.md/agents.md
+	Source    float64 // +3 if from td/github issues
+}
+
+// Total returns the task's score.
+func (b ScoreBreakdown) Total() float64 {
+	return b.Base + b.Staleness + b.Context + b.Source
+}
+
 // ScoreTask calculates the priority score for a task.
 // Formula: base_priority + staleness_bonus + context_bonus + task_source_bonus
 func (s *Selector) ScoreTask(taskType TaskType, project string) float64 {
-	var score float64
+	return s.ScoreBreakdown(taskType, project).Total()
+}
+
+// ScoreBreakdown returns the components ScoreTask sums for a task.
+func (s *Selector) ScoreBreakdown(taskType TaskType, project string) ScoreBreakdown {
+	var b ScoreBreakdown
 
 	// Base priority from config
-	score += float64(s.cfg.GetTaskPriority(string(taskType)))
+	b.Base = float64(s.cfg.GetTaskPriority(string(taskType)))
 
 	// Staleness bonus: days since last run * 0.1
-	score += s.state.StalenessBonus(project, string(taskType))
+	b.Staleness = s.state.StalenessBonus(project, string(taskType))
 
 	// Context bonus: +2 if mentioned in claude.md/agents.md
 	if s.contextMentions[string(taskType)] {
-		score += 2.0
+		b.Context = 2.0
 	}
 
 	// Task source bonus: +3 if from td/github issues
 	if s.taskSources[string(taskType)] {
-		score += 3.0
+		b.Source = 3.0
 	}
 
-	return score
+	return b
 }
 
 // FilterEnabled returns only enabled tasks from the given list.
-// Tasks with DisabledByDefault require explicit inclusion in tasks.enabled.
+// Tasks with DisabledByDefault require explicit inclusion in tasks.enabled,
+// and tasks outside the allowed categories (see SetCategories) or the day's
+// theme (see SetTheme) are dropped.
 func (s *Selector) FilterEnabled(tasks []TaskDefinition) []TaskDefinition {
 	filtered := make([]TaskDefinition, 0, len(tasks))
 	for _, t := range tasks {
-		if t.DisabledByDefault && !s.cfg.IsTaskExplicitlyEnabled(string(t.Type)) {
-			continue
-		}
-		if s.cfg.IsTaskEnabled(string(t.Type)) {
+		if s.enabledVerdict(t) == "" {
 			filtered = append(filtered, t)
 		}
 	}
 	return filtered
 }
 
-// FilterByBudget returns tasks that fit within the given budget.
-// Budget is in tokens.
+// enabledVerdict returns why FilterEnabled drops t, or "" if it keeps it.
+func (s *Selector) enabledVerdict(t TaskDefinition) Verdict {
+	if s.categories != nil && !s.categories[t.Category] {
+		return VerdictCategory
+	}
+	if !s.inTheme(t) {
+		return VerdictTheme
+	}
+	if t.DisabledByDefault && !s.cfg.IsTaskExplicitlyEnabled(string(t.Type)) {
+		return VerdictDisabled
+	}
+	if !s.cfg.IsTaskEnabled(string(t.Type)) {
+		return VerdictDisabled
+	}
+	return ""
+}
+
+// FilterByBudget returns tasks whose reservation (see ReservedTokens) fits
+// within the given budget. Budget is in tokens.
 func (s *Selector) FilterByBudget(tasks []TaskDefinition, budget int64) []TaskDefinition {
 	filtered := make([]TaskDefinition, 0, len(tasks))
 	for _, t := range tasks {
-		_, max := t.EstimatedTokens()
-		if int64(max) <= budget {
+		if s.ReservedTokens(t) <= budget {
 			filtered = append(filtered, t)
 		}
 	}
@@ -152,7 +257,7 @@ func (s *Selector) FilterByCooldown(tasks []TaskDefinition, project string) []Ta
 			filtered = append(filtered, t)
 			continue
 		}
-		lastRun := s.state.LastTaskRun(project, string(t.Type))
+		lastRun := s.lastTaskRun(project, string(t.Type))
 		if lastRun.IsZero() || time.Since(lastRun) >= interval {
 			filtered = append(filtered, t)
 		}
@@ -160,6 +265,71 @@ func (s *Selector) FilterByCooldown(tasks []TaskDefinition, project string) []Ta
 	return filtered
 }
 
+// FilterReadOnly keeps only tasks that leave code untouched when
+// project is configured read-only.
+func (s *Selector) FilterReadOnly(tasks []TaskDefinition, project string) []TaskDefinition {
+	if !s.cfg.ReadOnlyProject(project) {
+		return tasks
+	}
+	filtered := make([]TaskDefinition, 0, len(tasks))
+	for _, t := range tasks {
+		if t.ReadOnly() {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// FilterByRecentFailure drops tasks whose most recent result on project
+// was failed or abandoned within tasks.recent_failure_window, so a task
+// that just broke isn't retried right away. A zero window keeps every task.
+func (s *Selector) FilterByRecentFailure(tasks []TaskDefinition, project string) []TaskDefinition {
+	filtered := make([]TaskDefinition, 0, len(tasks))
+	for _, t := range tasks {
+		if !s.recentlyFailed(t.Type, project) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// recentlyFailed reports whether taskType's last result on project was a
+// failure inside tasks.recent_failure_window.
+func (s *Selector) recentlyFailed(taskType TaskType, project string) bool {
+	window := s.cfg.RecentFailureWindow()
+	if window <= 0 || s.state == nil {
+		return false
+	}
+	status, at := s.state.LastTaskResult(project, string(taskType))
+	if status != "failed" && status != "abandoned" {
+		return false
+	}
+	return time.Since(at) < window
+}
+
+// RecordReportedRun notes that a task was completed for a project at at,
+// e.g. by another machine writing to a shared reports dir. Cooldowns then
+// run from the later of this and the local state's last run.
+func (s *Selector) RecordReportedRun(taskType, project string, at time.Time) {
+	if s.reportedRuns == nil {
+		s.reportedRuns = make(map[string]time.Time)
+	}
+	key := makeTaskID(taskType, project)
+	if at.After(s.reportedRuns[key]) {
+		s.reportedRuns[key] = at
+	}
+}
+
+// lastTaskRun returns when a task last ran for a project, in local state or
+// in a recorded report.
+func (s *Selector) lastTaskRun(project, taskType string) time.Time {
+	lastRun := s.state.LastTaskRun(project, taskType)
+	if reported := s.reportedRuns[makeTaskID(taskType, project)]; reported.After(lastRun) {
+		return reported
+	}
+	return lastRun
+}
+
 // AddSimulatedCooldown marks a task+project as on cooldown for preview simulation.
 // Subsequent calls to FilterByCooldown will exclude this combination.
 func (s *Selector) AddSimulatedCooldown(taskType string, project string) {
@@ -193,7 +363,7 @@ func (s *Selector) IsOnCooldown(taskType TaskType, project string) (bool, time.D
 	if interval <= 0 {
 		return false, 0, 0
 	}
-	lastRun := s.state.LastTaskRun(project, string(taskType))
+	lastRun := s.lastTaskRun(project, string(taskType))
 	if lastRun.IsZero() {
 		return false, 0, interval
 	}
@@ -213,6 +383,9 @@ func (s *Selector) SelectNext(budget int64, project string) *ScoredTask {
 	// Filter: enabled tasks only
 	tasks = s.FilterEnabled(tasks)
 
+	// Filter: non-mutating tasks on read-only projects
+	tasks = s.FilterReadOnly(tasks, project)
+
 	// Filter: tasks within budget estimate
 	tasks = s.FilterByBudget(tasks, budget)
 
@@ -222,6 +395,9 @@ func (s *Selector) SelectNext(budget int64, project string) *ScoredTask {
 	// Filter: tasks not on cooldown
 	tasks = s.FilterByCooldown(tasks, project)
 
+	// Filter: tasks that didn't just fail here
+	tasks = s.FilterByRecentFailure(tasks, project)
+
 	if len(tasks) == 0 {
 		return nil
 	}
@@ -236,15 +412,11 @@ func (s *Selector) SelectNext(budget int64, project string) *ScoredTask {
 		}
 	}
 
-	// Sort by score descending
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].Score > scored[j].Score
-	})
+	sortByScore(scored)
 
 	// Select top task that fits remaining budget
 	for _, st := range scored {
-		_, max := st.Definition.EstimatedTokens()
-		if int64(max) <= budget {
+		if s.ReservedTokens(st.Definition) <= budget {
 			return &st
 		}
 	}
@@ -273,13 +445,203 @@ func makeTaskID(taskType, project string) string {
 }
 
 // SelectTopN returns the top N tasks by score that fit within budget.
+// Tasks scoring below scoring.min_score are excluded. When
+// scoring.balance_categories is set, picks are spread across task
+// categories (see BalanceByCategory).
 func (s *Selector) SelectTopN(budget int64, project string, n int) []ScoredTask {
+	return s.ExplainTopN(budget, project, n).Selected
+}
+
+// TopNExplanation describes how SelectTopN arrived at its picks.
+type TopNExplanation struct {
+	Balanced  bool         // Category balancing was applied
+	MinScore  float64      // Score threshold in effect (0 = none)
+	Selected  []ScoredTask // Tasks chosen, in run order
+	Displaced []ScoredTask // Tasks pure score order would have chosen instead
+	BelowMin  []ScoredTask // Eligible tasks excluded by MinScore
+}
+
+// NoneAboveMin reports whether tasks were eligible but none cleared MinScore.
+func (e TopNExplanation) NoneAboveMin() bool {
+	return len(e.Selected) == 0 && len(e.BelowMin) > 0
+}
+
+// ExplainTopN runs the SelectTopN pipeline and reports which tasks the score
+// threshold excluded and category balancing displaced, if any.
+func (s *Selector) ExplainTopN(budget int64, project string, n int) TopNExplanation {
+	scored := s.rankEligible(budget, project)
+
+	var ex TopNExplanation
+	if s.cfg != nil && s.cfg.Scoring.MinScore > 0 {
+		ex.MinScore = s.cfg.Scoring.MinScore
+		cut := len(scored)
+		for i, st := range scored {
+			if st.Score < ex.MinScore {
+				cut = i
+				break
+			}
+		}
+		ex.BelowMin = scored[cut:]
+		scored = scored[:cut]
+	}
+
+	if n > len(scored) {
+		n = len(scored)
+	}
+	if s.cfg == nil || !s.cfg.Scoring.BalanceCategories {
+		ex.Selected = scored[:n]
+		return ex
+	}
+
+	selected := BalanceByCategory(scored, n)
+	picked := make(map[TaskType]bool, len(selected))
+	for _, st := range selected {
+		picked[st.Definition.Type] = true
+	}
+	var displaced []ScoredTask
+	for _, st := range scored[:n] {
+		if !picked[st.Definition.Type] {
+			displaced = append(displaced, st)
+		}
+	}
+	ex.Balanced = true
+	ex.Selected = selected
+	ex.Displaced = displaced
+	return ex
+}
+
+// Verdict says how the selection pipeline treated a task.
+type Verdict string
+
+// Verdicts reported by ExplainTasks. The first four are for tasks that passed
+// every filter; the rest name the filter that dropped the task.
+const (
+	VerdictSelected  Verdict = "selected"        // Picked for this run
+	VerdictNotPicked Verdict = "not-picked"      // Eligible, ranked below the top N
+	VerdictDisplaced Verdict = "displaced"       // In the top N, moved out by category balancing
+	VerdictBelowMin  Verdict = "below-min"       // Scored below scoring.min_score
+	VerdictCategory  Verdict = "category"        // Outside the schedule window's categories
+	VerdictTheme     Verdict = "theme"           // Outside the day's theme
+	VerdictDisabled  Verdict = "disabled"        // Not enabled in tasks config
+	VerdictReadOnly  Verdict = "read-only"       // Changes code on a read-only project
+	VerdictBudget    Verdict = "budget"          // Reservation exceeds the remaining budget
+	VerdictAssigned  Verdict = "assigned"        // Already assigned to a run
+	VerdictCooldown  Verdict = "cooldown"        // Ran within its interval
+	VerdictFailed    Verdict = "recently-failed" // Failed here within tasks.recent_failure_window
+)
+
+// Eligible reports whether the task passed every selection filter.
+func (v Verdict) Eligible() bool {
+	switch v {
+	case VerdictSelected, VerdictNotPicked, VerdictDisplaced, VerdictBelowMin:
+		return true
+	}
+	return false
+}
+
+// TaskVerdict is one task's score breakdown and selection outcome.
+type TaskVerdict struct {
+	Definition TaskDefinition
+	Scores     ScoreBreakdown
+	Verdict    Verdict
+	// CooldownLeft is the time until a VerdictCooldown task may run again
+	// (0 for a simulated cooldown).
+	CooldownLeft time.Duration
+}
+
+// ExplainTasks runs the SelectTopN pipeline and reports the outcome for
+// every task definition, not just the picks. Selected tasks come first in
+// run order, the rest by score descending.
+func (s *Selector) ExplainTasks(budget int64, project string, n int) []TaskVerdict {
+	ex := s.ExplainTopN(budget, project, n)
+	ranked := make(map[TaskType]Verdict)
+	for _, st := range ex.BelowMin {
+		ranked[st.Definition.Type] = VerdictBelowMin
+	}
+	for _, st := range ex.Displaced {
+		ranked[st.Definition.Type] = VerdictDisplaced
+	}
+	for _, st := range ex.Selected {
+		ranked[st.Definition.Type] = VerdictSelected
+	}
+
+	var selected, rest []TaskVerdict
+	for _, t := range AllDefinitions() {
+		tv := TaskVerdict{Definition: t, Scores: s.ScoreBreakdown(t.Type, project)}
+		tv.Verdict, tv.CooldownLeft = s.filterVerdict(t, budget, project)
+		if tv.Verdict == "" {
+			tv.Verdict = VerdictNotPicked
+			if v, ok := ranked[t.Type]; ok {
+				tv.Verdict = v
+			}
+		}
+		if tv.Verdict == VerdictSelected {
+			selected = append(selected, tv)
+		} else {
+			rest = append(rest, tv)
+		}
+	}
+
+	order := make(map[TaskType]int, len(ex.Selected))
+	for i, st := range ex.Selected {
+		order[st.Definition.Type] = i
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return order[selected[i].Definition.Type] < order[selected[j].Definition.Type]
+	})
+	sort.Slice(rest, func(i, j int) bool {
+		si, sj := rest[i].Scores.Total(), rest[j].Scores.Total()
+		if si != sj {
+			return si > sj
+		}
+		return rest[i].Definition.Type < rest[j].Definition.Type
+	})
+	return append(selected, rest...)
+}
+
+// filterVerdict returns the first rankEligible filter that drops t, or ""
+// if t passes them all. For a cooldown it also returns the time left.
+func (s *Selector) filterVerdict(t TaskDefinition, budget int64, project string) (Verdict, time.Duration) {
+	if v := s.enabledVerdict(t); v != "" {
+		return v, 0
+	}
+	if !t.ReadOnly() && s.cfg.ReadOnlyProject(project) {
+		return VerdictReadOnly, 0
+	}
+	if s.ReservedTokens(t) > budget {
+		return VerdictBudget, 0
+	}
+	if s.IsAssigned(makeTaskID(string(t.Type), project)) {
+		return VerdictAssigned, 0
+	}
+	if s.HasSimulatedCooldown(string(t.Type), project) {
+		return VerdictCooldown, 0
+	}
+	if interval := s.effectiveInterval(t); interval > 0 {
+		if lastRun := s.lastTaskRun(project, string(t.Type)); !lastRun.IsZero() {
+			if elapsed := time.Since(lastRun); elapsed < interval {
+				return VerdictCooldown, interval - elapsed
+			}
+		}
+	}
+	if s.recentlyFailed(t.Type, project) {
+		return VerdictFailed, 0
+	}
+	return "", 0
+}
+
+// rankEligible applies the selection filters and returns eligible tasks
+// sorted by score descending.
+func (s *Selector) rankEligible(budget int64, project string) []ScoredTask {
 	// Start with all task definitions
 	tasks := AllDefinitions()
 
 	// Filter: enabled tasks only
 	tasks = s.FilterEnabled(tasks)
 
+	// Filter: non-mutating tasks on read-only projects
+	tasks = s.FilterReadOnly(tasks, project)
+
 	// Filter: tasks within budget estimate
 	tasks = s.FilterByBudget(tasks, budget)
 
@@ -289,9 +651,8 @@ func (s *Selector) SelectTopN(budget int64, project string, n int) []ScoredTask
 	// Filter: tasks not on cooldown
 	tasks = s.FilterByCooldown(tasks, project)
 
-	if len(tasks) == 0 {
-		return nil
-	}
+	// Filter: tasks that didn't just fail here
+	tasks = s.FilterByRecentFailure(tasks, project)
 
 	// Score each task
 	scored := make([]ScoredTask, len(tasks))
@@ -303,16 +664,51 @@ func (s *Selector) SelectTopN(budget int64, project string, n int) []ScoredTask
 		}
 	}
 
-	// Sort by score descending
+	sortByScore(scored)
+	return scored
+}
+
+// sortByScore orders tasks by score descending, breaking ties by task type
+// ascending. AllDefinitions iterates a map, so without the tie-break equal
+// scores would come out in a different order from run to run.
+func sortByScore(scored []ScoredTask) {
 	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].Score > scored[j].Score
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Definition.Type < scored[j].Definition.Type
 	})
+}
 
-	// Return top N
-	if n > len(scored) {
-		n = len(scored)
+// BalanceByCategory picks up to n tasks from ranked (sorted by score
+// descending) in round-robin passes: each pass takes the best remaining task
+// from every category, visiting categories in order of their best score.
+func BalanceByCategory(ranked []ScoredTask, n int) []ScoredTask {
+	var order []TaskCategory
+	byCategory := make(map[TaskCategory][]ScoredTask)
+	for _, st := range ranked {
+		c := st.Definition.Category
+		if _, ok := byCategory[c]; !ok {
+			order = append(order, c)
+		}
+		byCategory[c] = append(byCategory[c], st)
+	}
+
+	if n > len(ranked) {
+		n = len(ranked)
 	}
-	return scored[:n]
+	selected := make([]ScoredTask, 0, n)
+	for pass := 0; len(selected) < n; pass++ {
+		for _, c := range order {
+			if len(selected) == n {
+				break
+			}
+			if pass < len(byCategory[c]) {
+				selected = append(selected, byCategory[c][pass])
+			}
+		}
+	}
+	return selected
 }
 
 // SelectRandom returns a random task from the eligible pool.
@@ -326,6 +722,9 @@ func (s *Selector) SelectRandom(budget int64, project string) *ScoredTask {
 	// Filter: enabled tasks only
 	tasks = s.FilterEnabled(tasks)
 
+	// Filter: non-mutating tasks on read-only projects
+	tasks = s.FilterReadOnly(tasks, project)
+
 	// Filter: tasks within budget estimate
 	tasks = s.FilterByBudget(tasks, budget)
 
@@ -335,6 +734,9 @@ func (s *Selector) SelectRandom(budget int64, project string) *ScoredTask {
 	// Filter: tasks not on cooldown
 	tasks = s.FilterByCooldown(tasks, project)
 
+	// Filter: tasks that didn't just fail here
+	tasks = s.FilterByRecentFailure(tasks, project)
+
 	if len(tasks) == 0 {
 		return nil
 	}
@@ -349,7 +751,13 @@ func (s *Selector) SelectRandom(budget int64, project string) *ScoredTask {
 		}
 	}
 
+	// Order the pool by type so a seeded RNG picks reproducibly
+	// (AllDefinitions iterates a map).
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].Definition.Type < scored[j].Definition.Type
+	})
+
 	// Pick a random task from the eligible pool
-	pick := scored[rand.IntN(len(scored))]
+	pick := scored[s.intN(len(scored))]
 	return &pick
 }
@@ -2,6 +2,7 @@ package tasks
 
 import (
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -184,6 +185,91 @@ func TestFilterEnabled_DisabledByDefault(t *testing.T) {
 	}
 }
 
+func TestFilterEnabled_Categories(t *testing.T) {
+	sel, _ := setupTestSelector(t)
+
+	defs := []TaskDefinition{
+		{Type: TaskLintFix, Category: CategoryPR},
+		{Type: TaskBugFinder, Category: CategoryAnalysis},
+		{Type: TaskDocsBackfill, Category: CategoryMap},
+	}
+
+	sel.SetCategories([]TaskCategory{CategoryAnalysis, CategoryMap})
+	got := sel.FilterEnabled(defs)
+	if len(got) != 2 {
+		t.Fatalf("FilterEnabled() with categories: len = %d, want 2", len(got))
+	}
+	for _, d := range got {
+		if d.Category == CategoryPR {
+			t.Errorf("FilterEnabled() kept %s outside allowed categories", d.Type)
+		}
+	}
+
+	sel.SetCategories(nil)
+	if got := sel.FilterEnabled(defs); len(got) != 3 {
+		t.Errorf("FilterEnabled() after clearing categories: len = %d, want 3", len(got))
+	}
+}
+
+func TestFilterEnabled_Theme(t *testing.T) {
+	sel, _ := setupTestSelector(t)
+
+	defs := []TaskDefinition{
+		{Type: TaskLintFix, Category: CategoryPR},
+		{Type: TaskBugFinder, Category: CategoryAnalysis},
+		{Type: TaskDocsBackfill, Category: CategoryMap},
+	}
+
+	sel.SetTheme([]TaskCategory{CategoryAnalysis}, []TaskType{TaskDocsBackfill})
+	got := sel.FilterEnabled(defs)
+	if len(got) != 2 || got[0].Type != TaskBugFinder || got[1].Type != TaskDocsBackfill {
+		t.Fatalf("FilterEnabled() with theme = %v, want bug-finder and docs-backfill", got)
+	}
+
+	// The theme narrows the window categories further.
+	sel.SetCategories([]TaskCategory{CategoryMap, CategoryPR})
+	if got := sel.FilterEnabled(defs); len(got) != 1 || got[0].Type != TaskDocsBackfill {
+		t.Errorf("FilterEnabled() with theme and categories = %v, want docs-backfill", got)
+	}
+
+	sel.SetCategories(nil)
+	sel.SetTheme(nil, nil)
+	if got := sel.FilterEnabled(defs); len(got) != 3 {
+		t.Errorf("FilterEnabled() after clearing theme: len = %d, want 3", len(got))
+	}
+}
+
+func TestFilterReadOnly(t *testing.T) {
+	sel, _ := setupTestSelector(t)
+	sel.cfg.Projects = []config.ProjectConfig{{Path: "/test/readonly", ReadOnly: true}}
+
+	defs := []TaskDefinition{
+		{Type: TaskLintFix, Category: CategoryPR},
+		{Type: TaskDocDrift, Category: CategoryAnalysis},
+		{Type: "sandbox-try", Category: CategorySafe},
+		{Type: TaskRunbookGen, Category: CategoryEmergency},
+		{Type: TaskVisibilityInstrument, Category: CategoryMap, EditsCode: true},
+	}
+
+	tests := []struct {
+		project string
+		want    []TaskType
+	}{
+		{"/test/readonly", []TaskType{TaskDocDrift, TaskRunbookGen}},
+		{"/test/readonly/", []TaskType{TaskDocDrift, TaskRunbookGen}},
+		{"/test/other", []TaskType{TaskLintFix, TaskDocDrift, "sandbox-try", TaskRunbookGen, TaskVisibilityInstrument}},
+	}
+	for _, tt := range tests {
+		var got []TaskType
+		for _, d := range sel.FilterReadOnly(defs, tt.project) {
+			got = append(got, d.Type)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FilterReadOnly(%q) = %v, want %v", tt.project, got, tt.want)
+		}
+	}
+}
+
 func TestFilterByBudget(t *testing.T) {
 	sel, _ := setupTestSelector(t)
 
@@ -211,6 +297,28 @@ func TestFilterByBudget(t *testing.T) {
 	}
 }
 
+func TestMaxTokensPerTask(t *testing.T) {
+	sel, _ := setupTestSelector(t)
+	low := TaskDefinition{Type: TaskLintFix, CostTier: CostLow}     // 10-50k
+	high := TaskDefinition{Type: TaskBugFinder, CostTier: CostHigh} // 150-500k
+
+	sel.SetMaxTokensPerTask(100_000)
+	if got := sel.ReservedTokens(high); got != 100_000 {
+		t.Errorf("ReservedTokens(high) = %d, want cap 100000", got)
+	}
+	if got := sel.ReservedTokens(low); got != 50_000 {
+		t.Errorf("ReservedTokens(low) = %d, want tier max 50000 below the cap", got)
+	}
+	if got := sel.FilterByBudget([]TaskDefinition{low, high}, 100_000); len(got) != 2 {
+		t.Errorf("FilterByBudget with cap: len = %d, want 2", len(got))
+	}
+
+	sel.SetMaxTokensPerTask(0)
+	if got := sel.ReservedTokens(high); got != 500_000 {
+		t.Errorf("ReservedTokens(high) without cap = %d, want 500000", got)
+	}
+}
+
 func TestFilterUnassigned(t *testing.T) {
 	sel, st := setupTestSelector(t)
 
@@ -631,6 +739,42 @@ func TestSelectRandom(t *testing.T) {
 	}
 }
 
+func TestSelectRandomSeeded(t *testing.T) {
+	st := newTestState(t)
+
+	cfg := &config.Config{
+		Tasks: config.TasksConfig{
+			Enabled: []string{
+				string(TaskLintFix),
+				string(TaskDocsBackfill),
+				string(TaskDeadCode),
+			},
+		},
+	}
+	project := "/test/project"
+
+	picks := func(seed uint64) []TaskType {
+		sel := NewSelector(cfg, st)
+		sel.SetSeed(seed)
+		var out []TaskType
+		for i := 0; i < 20; i++ {
+			task := sel.SelectRandom(1_000_000, project)
+			if task == nil {
+				t.Fatal("SelectRandom() returned nil")
+			}
+			out = append(out, task.Definition.Type)
+		}
+		return out
+	}
+
+	a, b := picks(42), picks(42)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("pick %d differs for identical seeds: %s vs %s", i, a[i], b[i])
+		}
+	}
+}
+
 func TestSelectRandomNoBudget(t *testing.T) {
 	sel, _ := setupTestSelector(t)
 
@@ -734,3 +878,281 @@ func TestSelectNextRespectssCooldown(t *testing.T) {
 		t.Errorf("SelectNext() = %s, want %s (lint-fix on cooldown)", task.Definition.Type, TaskDocsBackfill)
 	}
 }
+
+func TestBalanceByCategory(t *testing.T) {
+	mk := func(tt TaskType, cat TaskCategory, score float64) ScoredTask {
+		return ScoredTask{Definition: TaskDefinition{Type: tt, Category: cat}, Score: score}
+	}
+	// Ranked by score: three analysis tasks outrank both PR tasks.
+	ranked := []ScoredTask{
+		mk(TaskDeadCode, CategoryAnalysis, 9),
+		mk(TaskDocDrift, CategoryAnalysis, 8),
+		mk(TaskTestGap, CategoryAnalysis, 7),
+		mk(TaskLintFix, CategoryPR, 6),
+		mk(TaskDocsBackfill, CategoryPR, 5),
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []TaskType
+	}{
+		{"one", 1, []TaskType{TaskDeadCode}},
+		{"two spans categories", 2, []TaskType{TaskDeadCode, TaskLintFix}},
+		{"second pass", 4, []TaskType{TaskDeadCode, TaskLintFix, TaskDocDrift, TaskDocsBackfill}},
+		{"exhausted category", 5, []TaskType{TaskDeadCode, TaskLintFix, TaskDocDrift, TaskDocsBackfill, TaskTestGap}},
+		{"n exceeds pool", 10, []TaskType{TaskDeadCode, TaskLintFix, TaskDocDrift, TaskDocsBackfill, TaskTestGap}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BalanceByCategory(ranked, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.want))
+			}
+			for i, st := range got {
+				if st.Definition.Type != tt.want[i] {
+					t.Errorf("pick %d = %s, want %s", i, st.Definition.Type, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectTopN_StableTies(t *testing.T) {
+	st := newTestState(t)
+	tied := []TaskType{TaskSkillGroom, TaskLintFix, TaskDocsBackfill, TaskDeadCode, TaskBugFinder}
+	cfg := &config.Config{Tasks: config.TasksConfig{
+		Priorities: map[string]int{},
+		Intervals:  map[string]string{},
+	}}
+	for _, tt := range tied {
+		cfg.Tasks.Enabled = append(cfg.Tasks.Enabled, string(tt))
+		cfg.Tasks.Priorities[string(tt)] = 3
+		cfg.Tasks.Intervals[string(tt)] = "1ns"
+	}
+	sel := NewSelector(cfg, st)
+	project := "/test/project"
+
+	want := append([]TaskType(nil), tied...)
+	slices.Sort(want)
+	want = want[:3]
+
+	for i := range 20 {
+		got := sel.SelectTopN(1_000_000, project, 3)
+		if len(got) != 3 {
+			t.Fatalf("SelectTopN(3) len = %d, want 3", len(got))
+		}
+		for j, task := range got {
+			if task.Definition.Type != want[j] {
+				t.Fatalf("invocation %d: order = %v, want %v", i, taskTypes(got), want)
+			}
+		}
+		if next := sel.SelectNext(1_000_000, project); next == nil || next.Definition.Type != want[0] {
+			t.Fatalf("invocation %d: SelectNext = %v, want %s", i, next, want[0])
+		}
+	}
+}
+
+func TestExplainTopN_BalanceCategories(t *testing.T) {
+	st := newTestState(t)
+
+	cfg := &config.Config{
+		Tasks: config.TasksConfig{
+			Enabled: []string{
+				string(TaskDeadCode),
+				string(TaskDocDrift),
+				string(TaskLintFix),
+			},
+			Priorities: map[string]int{
+				string(TaskDeadCode): 10,
+				string(TaskDocDrift): 9,
+				string(TaskLintFix):  1,
+			},
+		},
+		Scoring: config.ScoringConfig{BalanceCategories: true},
+	}
+	sel := NewSelector(cfg, st)
+	project := "/test/project"
+
+	ex := sel.ExplainTopN(1_000_000, project, 2)
+	if !ex.Balanced {
+		t.Fatal("expected Balanced")
+	}
+	if len(ex.Selected) != 2 || ex.Selected[0].Definition.Type != TaskDeadCode || ex.Selected[1].Definition.Type != TaskLintFix {
+		t.Errorf("Selected = %v, want [dead-code lint-fix]", taskTypes(ex.Selected))
+	}
+	if len(ex.Displaced) != 1 || ex.Displaced[0].Definition.Type != TaskDocDrift {
+		t.Errorf("Displaced = %v, want [doc-drift]", taskTypes(ex.Displaced))
+	}
+
+	// Balancing off: pure score order
+	cfg.Scoring.BalanceCategories = false
+	got := sel.SelectTopN(1_000_000, project, 2)
+	if len(got) != 2 || got[1].Definition.Type != TaskDocDrift {
+		t.Errorf("SelectTopN without balancing = %v, want [dead-code doc-drift]", taskTypes(got))
+	}
+}
+
+func TestExplainTopN_MinScore(t *testing.T) {
+	st := newTestState(t)
+
+	cfg := &config.Config{
+		Tasks: config.TasksConfig{
+			Enabled: []string{
+				string(TaskDeadCode),
+				string(TaskDocDrift),
+				string(TaskLintFix),
+			},
+			Priorities: map[string]int{
+				string(TaskDeadCode): 10,
+				string(TaskDocDrift): 9,
+				string(TaskLintFix):  1,
+			},
+		},
+	}
+	sel := NewSelector(cfg, st)
+	project := "/test/project"
+
+	// Threshold at doc-drift's score: it clears (>=), lint-fix does not
+	cfg.Scoring.MinScore = sel.ScoreTask(TaskDocDrift, project)
+	ex := sel.ExplainTopN(1_000_000, project, 3)
+	if len(ex.Selected) != 2 || ex.Selected[0].Definition.Type != TaskDeadCode || ex.Selected[1].Definition.Type != TaskDocDrift {
+		t.Errorf("Selected = %v, want [dead-code doc-drift]", taskTypes(ex.Selected))
+	}
+	if len(ex.BelowMin) != 1 || ex.BelowMin[0].Definition.Type != TaskLintFix {
+		t.Errorf("BelowMin = %v, want [lint-fix]", taskTypes(ex.BelowMin))
+	}
+	if ex.NoneAboveMin() {
+		t.Error("NoneAboveMin() = true with tasks selected")
+	}
+
+	// Nothing clears the bar
+	cfg.Scoring.MinScore = sel.ScoreTask(TaskDeadCode, project) + 1
+	ex = sel.ExplainTopN(1_000_000, project, 3)
+	if len(ex.Selected) != 0 || len(ex.BelowMin) != 3 {
+		t.Errorf("Selected = %v, BelowMin = %v, want none selected", taskTypes(ex.Selected), taskTypes(ex.BelowMin))
+	}
+	if !ex.NoneAboveMin() {
+		t.Error("NoneAboveMin() = false, want true")
+	}
+
+	// Default 0 keeps every eligible task
+	cfg.Scoring.MinScore = 0
+	if got := sel.SelectTopN(1_000_000, project, 3); len(got) != 3 {
+		t.Errorf("SelectTopN with min_score 0 = %v, want 3 tasks", taskTypes(got))
+	}
+}
+
+func TestExplainTasks(t *testing.T) {
+	st := newTestState(t)
+
+	cfg := &config.Config{
+		Tasks: config.TasksConfig{
+			Enabled: []string{
+				string(TaskDeadCode),
+				string(TaskDocDrift),
+				string(TaskLintFix),
+			},
+			Priorities: map[string]int{
+				string(TaskDeadCode): 10,
+				string(TaskDocDrift): 9,
+				string(TaskLintFix):  8,
+			},
+		},
+	}
+	sel := NewSelector(cfg, st)
+	project := "/test/project"
+	sel.SetContextMentions([]string{string(TaskDocDrift)})
+	st.RecordTaskRun(project, string(TaskLintFix))
+
+	got := sel.ExplainTasks(1_000_000, project, 1)
+	if len(got) != len(AllDefinitions()) {
+		t.Fatalf("ExplainTasks() returned %d tasks, want all %d", len(got), len(AllDefinitions()))
+	}
+
+	want := []struct {
+		taskType TaskType
+		verdict  Verdict
+	}{
+		{TaskDocDrift, VerdictSelected},
+		{TaskDeadCode, VerdictNotPicked},
+		{TaskLintFix, VerdictCooldown},
+	}
+	for i, w := range want {
+		if got[i].Definition.Type != w.taskType || got[i].Verdict != w.verdict {
+			t.Errorf("ExplainTasks()[%d] = %s %s, want %s %s", i, got[i].Definition.Type, got[i].Verdict, w.taskType, w.verdict)
+		}
+	}
+	if b := got[0].Scores; b.Base != 9 || b.Context != 2 || b.Source != 0 || b.Total() != sel.ScoreTask(TaskDocDrift, project) {
+		t.Errorf("doc-drift scores = %+v, want base 9 + context 2 matching ScoreTask", b)
+	}
+	if got[2].CooldownLeft <= 0 {
+		t.Errorf("lint-fix CooldownLeft = %v, want > 0", got[2].CooldownLeft)
+	}
+	for _, tv := range got[3:] {
+		if tv.Verdict != VerdictDisabled {
+			t.Errorf("%s verdict = %s, want disabled", tv.Definition.Type, tv.Verdict)
+		}
+		if tv.Verdict.Eligible() {
+			t.Errorf("%s: disabled verdict reported eligible", tv.Definition.Type)
+		}
+	}
+
+	// A reservation over the budget is reported before cooldown
+	got = sel.ExplainTasks(0, project, 1)
+	for _, tv := range got[:3] {
+		if tv.Verdict != VerdictBudget {
+			t.Errorf("%s verdict with no budget = %s, want budget", tv.Definition.Type, tv.Verdict)
+		}
+	}
+}
+
+func taskTypes(scored []ScoredTask) []TaskType {
+	types := make([]TaskType, len(scored))
+	for i, st := range scored {
+		types[i] = st.Definition.Type
+	}
+	return types
+}
+
+func TestFilterByRecentFailure(t *testing.T) {
+	project := "/test/project"
+	tasks := []TaskDefinition{
+		{Type: TaskLintFix},
+		{Type: TaskDocsBackfill},
+		{Type: TaskDeadCode},
+		{Type: TaskBugFinder},
+	}
+
+	tests := []struct {
+		name   string
+		window string
+		want   []TaskType
+	}{
+		{"window set", "24h", []TaskType{TaskLintFix, TaskBugFinder}},
+		{"window disabled", "", []TaskType{TaskLintFix, TaskDocsBackfill, TaskDeadCode, TaskBugFinder}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, st := setupTestSelector(t)
+			sel.cfg.Tasks.RecentFailureWindow = tt.window
+
+			st.RecordTaskResult(project, string(TaskLintFix), "completed")
+			st.RecordTaskResult(project, string(TaskDocsBackfill), "failed")
+			st.RecordTaskResult(project, string(TaskDeadCode), "abandoned")
+			// A failure on another project doesn't count here.
+			st.RecordTaskResult("/other/project", string(TaskBugFinder), "failed")
+			// Only the latest result counts.
+			st.RecordTaskResult(project, string(TaskLintFix), "failed")
+			st.RecordTaskResult(project, string(TaskLintFix), "completed")
+
+			var got []TaskType
+			for _, d := range sel.FilterByRecentFailure(tasks, project) {
+				got = append(got, d.Type)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilterByRecentFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"cmp"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 )
 
@@ -35,6 +36,22 @@ func (c CostTier) String() string {
 	}
 }
 
+// Name returns the tier's config name: low, medium, high or very-high.
+func (c CostTier) Name() string {
+	switch c {
+	case CostLow:
+		return "low"
+	case CostMedium:
+		return "medium"
+	case CostHigh:
+		return "high"
+	case CostVeryHigh:
+		return "very-high"
+	default:
+		return ""
+	}
+}
+
 // TokenRange returns the min and max estimated tokens for this tier.
 func (c CostTier) TokenRange() (min, max int) {
 	switch c {
@@ -51,6 +68,37 @@ func (c CostTier) TokenRange() (min, max int) {
 	}
 }
 
+// EstimatedDuration returns the default time an agent is assumed to spend
+// on a task in this tier. run --fill-window uses it for tiers that past run
+// reports don't yet cover; see DurationEstimates.
+func (c CostTier) EstimatedDuration() time.Duration {
+	switch c {
+	case CostLow:
+		return 10 * time.Minute
+	case CostMedium:
+		return 20 * time.Minute
+	case CostHigh:
+		return 45 * time.Minute
+	case CostVeryHigh:
+		return 90 * time.Minute
+	default:
+		return 0
+	}
+}
+
+// DurationEstimates maps cost tiers to the median duration of their tasks
+// in past runs. Tiers without an entry fall back to
+// CostTier.EstimatedDuration.
+type DurationEstimates map[CostTier]time.Duration
+
+// For returns the estimated run time of def, and whether it was measured.
+func (e DurationEstimates) For(def TaskDefinition) (time.Duration, bool) {
+	if d, ok := e[def.CostTier]; ok {
+		return d, true
+	}
+	return def.EstimatedDuration(), false
+}
+
 // RiskLevel represents the risk associated with a task.
 type RiskLevel int
 
@@ -123,6 +171,16 @@ func (c TaskCategory) String() string {
 	}
 }
 
+// ReadOnly reports whether tasks in the category leave the code untouched,
+// so they may run on read-only projects.
+func (c TaskCategory) ReadOnly() bool {
+	switch c {
+	case CategoryAnalysis, CategoryOptions, CategoryMap, CategoryEmergency:
+		return true
+	}
+	return false
+}
+
 // TaskType represents a specific type of task.
 type TaskType string
 
@@ -219,6 +277,7 @@ type TaskDefinition struct {
 	RiskLevel         RiskLevel
 	DefaultInterval   time.Duration
 	DisabledByDefault bool // Requires explicit opt-in via tasks.enabled
+	EditsCode         bool // Changes code although its category is read-only
 }
 
 // DefaultIntervalForCategory returns the default re-run interval for a task category.
@@ -241,11 +300,32 @@ func DefaultIntervalForCategory(cat TaskCategory) time.Duration {
 	}
 }
 
+// ReadOnly reports whether the task leaves the code untouched, so it may run
+// on read-only projects.
+func (d TaskDefinition) ReadOnly() bool {
+	return d.Category.ReadOnly() && !d.EditsCode
+}
+
 // EstimatedTokens returns the token range for this task definition.
 func (d TaskDefinition) EstimatedTokens() (min, max int) {
 	return d.CostTier.TokenRange()
 }
 
+// EstimatedDuration returns the estimated run time for this task definition.
+func (d TaskDefinition) EstimatedDuration() time.Duration {
+	return d.CostTier.EstimatedDuration()
+}
+
+// CappedMaxTokens returns the top of the task's token range, lowered to limit
+// when limit is positive and smaller.
+func (d TaskDefinition) CappedMaxTokens(limit int64) int64 {
+	_, max := d.EstimatedTokens()
+	if limit > 0 && limit < int64(max) {
+		return limit
+	}
+	return int64(max)
+}
+
 // customTypes tracks which task types were registered via RegisterCustom.
 var customTypes = map[TaskType]bool{}
 
@@ -720,6 +800,7 @@ Apply safe updates directly, and leave concise follow-ups for anything uncertain
 		CostTier:        CostHigh,
 		RiskLevel:       RiskMedium,
 		DefaultInterval: 168 * time.Hour,
+		EditsCode:       true,
 	},
 	TaskRepoTopology: {
 		Type:            TaskRepoTopology,
@@ -905,6 +986,9 @@ func AllDefinitionsSorted() []TaskDefinition {
 // RegisterCustom registers a custom task definition. Returns an error if the
 // type is already registered (built-in or custom).
 func RegisterCustom(def TaskDefinition) error {
+	if def.Type == TaskAdHoc {
+		return fmt.Errorf("task type %q is reserved", def.Type)
+	}
 	if _, exists := registry[def.Type]; exists {
 		return fmt.Errorf("task type %q already registered", def.Type)
 	}
@@ -934,6 +1018,28 @@ func ClearCustom() {
 	customTypes = map[TaskType]bool{}
 }
 
+// TaskAdHoc is the synthetic type for one-off prompts (`task run --prompt`).
+// It is never registered, so it cannot be selected or configured.
+const TaskAdHoc TaskType = "ad-hoc"
+
+// AdHocDefinition returns an unregistered definition wrapping a one-off
+// prompt, with default cost and risk settings. The name is the prompt's
+// first line, truncated for display.
+func AdHocDefinition(prompt string) TaskDefinition {
+	name, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	if r := []rune(name); len(r) > 60 {
+		name = string(r[:57]) + "..."
+	}
+	return TaskDefinition{
+		Type:        TaskAdHoc,
+		Category:    CategoryPR,
+		Name:        "Ad-hoc: " + name,
+		Description: prompt,
+		CostTier:    CostMedium,
+		RiskLevel:   RiskMedium,
+	}
+}
+
 // Task represents a unit of work for an AI agent.
 type Task struct {
 	ID          string
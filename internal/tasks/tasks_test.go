@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,6 +24,17 @@ func TestCostTierString(t *testing.T) {
 	}
 }
 
+func TestCostTierName(t *testing.T) {
+	for _, tier := range []CostTier{CostLow, CostMedium, CostHigh, CostVeryHigh} {
+		if got := parseCostTierString(tier.Name()); got != tier {
+			t.Errorf("parseCostTierString(%q) = %v, want %v", tier.Name(), got, tier)
+		}
+	}
+	if got := CostTier(99).Name(); got != "" {
+		t.Errorf("CostTier(99).Name() = %q, want empty", got)
+	}
+}
+
 func TestCostTierTokenRange(t *testing.T) {
 	tests := []struct {
 		tier    CostTier
@@ -44,6 +56,37 @@ func TestCostTierTokenRange(t *testing.T) {
 	}
 }
 
+func TestCostTierEstimatedDuration(t *testing.T) {
+	tests := []struct {
+		tier CostTier
+		want time.Duration
+	}{
+		{CostLow, 10 * time.Minute},
+		{CostMedium, 20 * time.Minute},
+		{CostHigh, 45 * time.Minute},
+		{CostVeryHigh, 90 * time.Minute},
+		{CostTier(99), 0},
+	}
+	for _, tt := range tests {
+		if got := tt.tier.EstimatedDuration(); got != tt.want {
+			t.Errorf("CostTier(%d).EstimatedDuration() = %s, want %s", tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestDurationEstimatesFor(t *testing.T) {
+	estimates := DurationEstimates{CostMedium: 7 * time.Minute}
+	if d, measured := estimates.For(TaskDefinition{CostTier: CostMedium}); d != 7*time.Minute || !measured {
+		t.Errorf("For(medium) = %s, %v; want the measured 7m", d, measured)
+	}
+	if d, measured := estimates.For(TaskDefinition{CostTier: CostHigh}); d != 45*time.Minute || measured {
+		t.Errorf("For(high) = %s, %v; want the 45m default", d, measured)
+	}
+	if d, measured := DurationEstimates(nil).For(TaskDefinition{CostTier: CostLow}); d != 10*time.Minute || measured {
+		t.Errorf("nil For(low) = %s, %v; want the 10m default", d, measured)
+	}
+}
+
 func TestRiskLevelString(t *testing.T) {
 	tests := []struct {
 		risk RiskLevel
@@ -392,6 +435,38 @@ func TestRegisterCustom_BuiltInCollision(t *testing.T) {
 	}
 }
 
+func TestRegisterCustom_ReservedAdHoc(t *testing.T) {
+	def := AdHocDefinition("do something")
+	if err := RegisterCustom(def); err == nil {
+		UnregisterCustom(TaskAdHoc)
+		t.Error("expected error registering reserved ad-hoc type")
+	}
+}
+
+func TestAdHocDefinition(t *testing.T) {
+	def := AdHocDefinition("  update the CHANGELOG\nfor the last 10 commits ")
+	if def.Type != TaskAdHoc {
+		t.Errorf("Type = %q, want %q", def.Type, TaskAdHoc)
+	}
+	if def.Name != "Ad-hoc: update the CHANGELOG" {
+		t.Errorf("Name = %q", def.Name)
+	}
+	if def.Description != "  update the CHANGELOG\nfor the last 10 commits " {
+		t.Errorf("Description should be the prompt verbatim, got %q", def.Description)
+	}
+	if def.CostTier != CostMedium {
+		t.Errorf("CostTier = %v, want CostMedium", def.CostTier)
+	}
+	if _, err := GetDefinition(TaskAdHoc); err == nil {
+		t.Error("ad-hoc type should not be registered")
+	}
+
+	long := AdHocDefinition(strings.Repeat("x", 100))
+	if got := len([]rune(long.Name)); got != len("Ad-hoc: ")+60 {
+		t.Errorf("long name length = %d, want %d", got, len("Ad-hoc: ")+60)
+	}
+}
+
 func TestRegisterCustom_DuplicateCustom(t *testing.T) {
 	t.Cleanup(func() { UnregisterCustom("dup-test") })
 	_ = RegisterCustom(TaskDefinition{